@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultAcksFile keeps the SLA breaches acknowledged in server mode:
+const defaultAcksFile = "jira-acks.json"
+
+// alertAck acknowledges an issue's SLA breach, e.g. a known-stuck item
+// waiting on a vendor. It holds while the issue stays in the status it was
+// acknowledged in.
+type alertAck struct {
+	Time   time.Time `json:"time"`
+	User   string    `json:"user"`
+	Note   string    `json:"note,omitempty"`
+	Status string    `json:"status"`
+}
+
+func acksFilename() string {
+	if config.AcksFile != "" {
+		return config.AcksFile
+	}
+	return defaultAcksFile
+}
+
+// loadAcks reads the latest acknowledgment by issue key; a missing file has
+// none. Like notes, they're part of a report's snapshot.
+func loadAcks(filename string) (map[string]alertAck, error) {
+	acks := make(map[string]alertAck)
+	b, err := readCacheFile(filename)
+	if os.IsNotExist(err) {
+		return acks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	recordSnapshot(filename, b)
+	if err = json.Unmarshal(b, &acks); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return acks, nil
+}
+
+func saveAcks(filename string, acks map[string]alertAck) error {
+	b, err := json.MarshalIndent(acks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeCacheFile(filename, b)
+}
+
+// reportAcks are the acknowledgments loaded for the report running, nil
+// until a report asks for them:
+var reportAcks map[string]alertAck
+
+// acknowledged returns the acknowledgment of an issue's breach, if it still
+// holds: once the issue's status changes, it alerts again.
+func acknowledged(issue *Issue) (*alertAck, bool) {
+	if reportAcks == nil {
+		acks, err := loadAcks(acksFilename())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: acknowledgments not applied: %v\n", err)
+			acks = make(map[string]alertAck)
+		}
+		reportAcks = acks
+	}
+	ack, ok := reportAcks[issue.Key]
+	if !ok || !strings.EqualFold(ack.Status, issue.Fields.Status.Name) {
+		return nil, false
+	}
+	return &ack, true
+}
+
+// ackSuffix notes who acknowledged an issue's breach, and when:
+func ackSuffix(issue *Issue) string {
+	ack, ok := acknowledged(issue)
+	if !ok {
+		return ""
+	}
+	s := fmt.Sprintf(" (acknowledged by %s %s", ack.User, displayTime(ack.Time).Format("Jan 02"))
+	if ack.Note != "" {
+		s += ": " + redact(ack.Note)
+	}
+	return s + ")"
+}
+
+// acknowledge records an acknowledgment of an issue's breach in the status it
+// has now in JIRA:
+func acknowledge(cl *http.Client, key string, user string, note string) (*alertAck, error) {
+	var issue Issue
+	u := fmt.Sprintf("%s/rest/api/2/issue/%s?fields=status", setting(cl, "JIRA_URL"), url.PathEscape(key))
+	if err := doJSON(cl, http.MethodGet, u, nil, &issue); err != nil {
+		return nil, err
+	}
+
+	filename := acksFilename()
+	acks, err := loadAcks(filename)
+	if err != nil {
+		return nil, err
+	}
+	ack := alertAck{Time: time.Now(), User: user, Note: note, Status: issue.Fields.Status.Name}
+	acks[key] = ack
+	if err = saveAcks(filename, acks); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	reportAcks = nil
+	return &ack, nil
+}
+
+// sameOrigin reports whether a browser sent a request from a page of this
+// server; requests without the headers browsers add come from API clients.
+func sameOrigin(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "", "same-origin", "none":
+	default:
+		return false
+	}
+	for _, header := range []string{"Origin", "Referer"} {
+		value := r.Header.Get(header)
+		if value == "" {
+			continue
+		}
+		u, err := url.Parse(value)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+	return true
+}
+
+// serveAck acknowledges a breach posted as key and an optional note, by the
+// user the request authenticated as. Forms from the gadget name the page to
+// return to; API clients get the acknowledgment as JSON.
+func serveAck(cl *http.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "POST key and note", http.StatusMethodNotAllowed)
+			return
+		}
+		// Pages elsewhere mustn't acknowledge in a signed-in user's name:
+		if !sameOrigin(r) {
+			http.Error(w, "cross-origin acknowledgments are not allowed", http.StatusForbidden)
+			return
+		}
+		user := requestIdentity(r)
+		if user == "" {
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+			return
+		}
+		key := strings.ToUpper(strings.TrimSpace(r.FormValue("key")))
+		if !issueKeyPattern.MatchString(key) {
+			http.Error(w, "key must be an issue key", http.StatusBadRequest)
+			return
+		}
+
+		serveMu.Lock()
+		ack, err := acknowledge(cl, key, user, strings.TrimSpace(r.FormValue("note")))
+		serveMu.Unlock()
+		if err != nil {
+			log.Printf("serve: %s: %v\n", r.URL, err)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		log.Printf("serve: %s acknowledged by %s\n", key, user)
+
+		// Only local paths, so the form can't redirect elsewhere:
+		if back := r.FormValue("return"); strings.HasPrefix(back, "/") && !strings.HasPrefix(back, "//") {
+			http.Redirect(w, r, back, http.StatusSeeOther)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ack)
+	}
+}
+
+// serveAcks lists the acknowledgments recorded, by issue key:
+func serveAcks(w http.ResponseWriter, r *http.Request) {
+	serveMu.Lock()
+	acks, err := loadAcks(acksFilename())
+	serveMu.Unlock()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(acks)
+}
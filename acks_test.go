@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeAck(t *testing.T) {
+	jira := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue/ABC-3" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"key": "ABC-3", "fields": {"status": {"name": "In Testing"}}}`))
+	}))
+	defer jira.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	for key, value := range map[string]string{"JIRA_URL": jira.URL, "JIRA_NOCACHE": "1"} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+	config = &Config{
+		Slack:  SlackConfig{Mention: []string{"breach"}},
+		Server: ServerConfig{Tokens: map[string]string{"alice-token-0123456": "alice", "bob-token-0123456789": "bob"}},
+	}
+	defer func() { config = &Config{}; reportAcks = nil }()
+
+	srv := httptest.NewServer(requireIdentity(newServeMux(jira.Client())))
+	defer srv.Close()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	post := func(token string, form url.Values, header ...string) (*http.Response, error) {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/alerts/ack", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Authorization", "Bearer "+token)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		return client.Do(req)
+	}
+
+	// The user is whoever signed in, whatever the form says:
+	rsp, err := post("alice-token-0123456", url.Values{"key": {"abc-3"}, "user": {"mallory"}, "note": {"waiting on vendor"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ack alertAck
+	json.NewDecoder(rsp.Body).Decode(&ack)
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK || ack.User != "alice" || ack.Status != "In Testing" {
+		t.Fatalf("expected the breach acknowledged in its status, got %d %+v", rsp.StatusCode, ack)
+	}
+
+	// Gadget forms return to the gadget, but only on this server:
+	rsp, err = post("bob-token-0123456789", url.Values{"key": {"ABC-3"}, "return": {"/gadget/aging.html?board=1"}}, "Origin", srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsp.Body.Close()
+	if rsp.StatusCode != http.StatusSeeOther || rsp.Header.Get("Location") != "/gadget/aging.html?board=1" {
+		t.Fatalf("expected a redirect back to the gadget, got %d %s", rsp.StatusCode, rsp.Header.Get("Location"))
+	}
+	if rsp, err = post("bob-token-0123456789", url.Values{"key": {"nope"}}); err != nil || rsp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a bad key rejected, got %v %v", rsp.StatusCode, err)
+	}
+	if rsp, err = post("", url.Values{"key": {"ABC-3"}}); err != nil || rsp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected an anonymous acknowledgment rejected, got %v %v", rsp.StatusCode, err)
+	}
+	for _, header := range [][]string{{"Origin", "https://evil.example"}, {"Referer", "https://evil.example/page"}, {"Sec-Fetch-Site", "cross-site"}} {
+		if rsp, err = post("alice-token-0123456", url.Values{"key": {"ABC-3"}}, header...); err != nil || rsp.StatusCode != http.StatusForbidden {
+			t.Fatalf("expected a cross-origin post (%v) rejected, got %v %v", header, rsp.StatusCode, err)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/alerts/acks", nil)
+	req.Header.Set("Authorization", "Bearer alice-token-0123456")
+	rsp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var acks map[string]alertAck
+	json.NewDecoder(rsp.Body).Decode(&acks)
+	rsp.Body.Close()
+	if acks["ABC-3"].User != "bob" {
+		t.Fatalf("expected the latest acknowledgment listed, got %+v", acks)
+	}
+
+	// The breach is quiet while the issue stays in the status, and alerts
+	// again once it moves:
+	issue := &Issue{Key: "ABC-3"}
+	issue.Fields.Status.Name = "In Testing"
+	now := time.Now()
+	reportAcks = nil
+	if alerts := issueAlerts(issue, true, now); len(alerts) != 0 {
+		t.Errorf("expected the acknowledged breach suppressed, got %+v", alerts)
+	}
+	if suffix := ackSuffix(issue); !strings.Contains(suffix, "acknowledged by bob") {
+		t.Errorf("expected the acknowledgment shown, got %q", suffix)
+	}
+	issue.Fields.Status.Name = "Blocked"
+	if alerts := issueAlerts(issue, true, now); len(alerts) != 1 || alerts[0].Rule != "breach" {
+		t.Errorf("expected the breach to alert again after moving, got %+v", alerts)
+	}
+}
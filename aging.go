@@ -0,0 +1,312 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/JamesDunne/jira-analysis/analysis"
+)
+
+// computeAging determines each issue's latest status and its age in business
+// days as of today, grouped by status:
+func computeAging(issues []Issue, today Date) map[string][]*Issue {
+	// Discover latest status per issue:
+	aging := make(map[string][]*Issue)
+	for i := range issues {
+		issue := &issues[i]
+
+		if issue.IsEpic() {
+			continue
+		}
+
+		issue.StatusTime = time.Unix(0, 0)
+
+		for _, ev := range issue.StatusTransitions() {
+			issue.Status = ev.ToString
+			if issue.isStartTransition(ev.HistoryItem) {
+				issue.StatusTime = ev.Time
+			}
+			if !ev.Bot {
+				issue.Assigned = ev.Author
+			}
+		}
+
+		if issue.Status == "" {
+			continue
+		}
+		//if issue.Status == "Open" || issue.Status == "Reopened" || issue.Status == "Closed" {
+		//	continue
+		//}
+
+		// Determine age in business days:
+		issue.StatusBusinessDays = businessDaysUntil(issue.StatusTime, today.Time)
+
+		// Add to status map:
+		aging[issue.Status] = append(aging[issue.Status], issue)
+	}
+
+	return aging
+}
+
+// ageText describes an age in business days, or in hours and minutes for
+// issues that entered their status today, so fresh items are told apart:
+func ageText(days int, since time.Time, now time.Time) string {
+	if days > 0 {
+		return fmt.Sprintf("%2d days old", days)
+	}
+
+	elapsed := now.Sub(since)
+	if elapsed < time.Minute {
+		return "just now"
+	}
+	hours := int(elapsed.Hours())
+	minutes := int(elapsed.Minutes()) % 60
+	if hours == 0 {
+		return fmt.Sprintf("%dm old", minutes)
+	}
+	return fmt.Sprintf("%dh %02dm old", hours, minutes)
+}
+
+// agingStages are the friendly names of the development statuses:
+var agingStages = map[string]string{
+	"In Progress":     "In Development",
+	"In Progress - 1": "PR",
+	"In Progress - 2": "Ready for QA",
+	"In Testing":      "In Testing",
+}
+
+// statusMetric summarizes one status for JSON output:
+type statusMetric struct {
+	Items      int `json:"items"`
+	OldestDays int `json:"oldestDays"`
+}
+
+func recordAgingMetrics(items []AgingItem) {
+	statuses := make(map[string]*statusMetric)
+	for _, item := range items {
+		m, ok := statuses[item.Status]
+		if !ok {
+			m = &statusMetric{}
+			statuses[item.Status] = m
+		}
+		m.Items++
+		if item.Age > m.OldestDays {
+			m.OldestDays = item.Age
+		}
+	}
+	recordMetric("items", len(items))
+	recordMetric("statuses", statuses)
+}
+
+func runAging(args []string) error {
+	fs := flag.NewFlagSet("aging", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting in-flight issues")
+	sla := fs.Int("sla", 0, "mark issues older than this many business days")
+	histogram := fs.Bool("histogram", false, "count items per status by age bucket instead of listing them")
+	watchers := fs.Bool("watchers", false, "fetch the watchers of items over SLA to list and notify them")
+	benchmarks := fs.Bool("benchmarks", false, "compare ages with the time completed work spent in each status")
+	benchmarkJQL := fs.String("benchmark-jql", "", "JQL filter selecting completed issues to benchmark against; default='statusCategory = Done AND resolved >= -90d'")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	now := reportNow()
+	cl := httpClient()
+	a := newAnalyzer(cl, analysis.WithStatusMap(agingStages), analysis.WithSLA(*sla), analysis.WithNow(now))
+	boardId := boardArg(fs.Args())
+	items, err := agingItems(a, boardId, reportJQL("aging", *jql, defaultJQL()))
+	if err != nil {
+		return err
+	}
+	var stageBenchmarks map[string]analysis.StageBenchmark
+	var percentiles []float64
+	if *benchmarks {
+		if percentiles, err = config.ReportPercentiles(); err != nil {
+			return err
+		}
+		if stageBenchmarks, err = a.StageBenchmarks(boardId, reportJQL("aging-benchmarks", *benchmarkJQL, "statusCategory = Done AND resolved >= -90d"), percentiles); err != nil {
+			return err
+		}
+	}
+
+	recordAgingMetrics(items)
+	var breaching []*Issue
+	var alerts []ruleAlert
+	for _, item := range items {
+		if item.OverSLA {
+			breaching = append(breaching, item.Issue)
+		}
+		alerts = append(alerts, issueAlerts(item.Issue, item.OverSLA, now)...)
+	}
+	var watching map[string][]string
+	if *watchers {
+		if watching, err = issueWatchers(cl, breaching); err != nil {
+			return err
+		}
+		recordMetric("watchers", watching)
+	}
+	recordAlerts(alerts, watching)
+
+	timeLayout := "Mon Jan 02"
+	fmt.Printf("Now: %s\n", displayTime(now).Format(timeLayout))
+	if *histogram {
+		histogram := ageHistogram(items)
+		buckets := make(map[string]map[string]int, len(histogram))
+		for _, h := range histogram {
+			buckets[h.Status] = make(map[string]int, len(ageBucketLabels))
+			for i, label := range ageBucketLabels {
+				buckets[h.Status][label] = h.Counts[i]
+			}
+		}
+		recordMetric("ageBuckets", buckets)
+		printAgeHistogram(histogram)
+		if *benchmarks {
+			printStageBenchmarks(items, stageBenchmarks, percentiles)
+		}
+		printWatchers(breaching, watching)
+		return nil
+	}
+	for start := 0; start < len(items); {
+		// Items are sorted by status, oldest first:
+		end := start + 1
+		for end < len(items) && items[end].Status == items[start].Status {
+			end++
+		}
+		group := items[start:end]
+		start = end
+
+		stage := ""
+		if group[0].Stage != "" {
+			stage = fmt.Sprintf(" (%s)", group[0].Stage)
+		}
+		if reportVerbosity == execView {
+			fmt.Printf("%s%s: %s, oldest %s\n", group[0].Status, stage, plural(len(group), "item"), ageText(group[0].Age, group[0].Since, now))
+			continue
+		}
+
+		fmt.Printf("%s%s: [\n", group[0].Status, stage)
+		shown := detailLines(len(group))
+		for _, item := range group[:shown] {
+			sinceLayout := timeLayout
+			if item.Age == 0 {
+				sinceLayout = "Mon Jan 02 15:04"
+			}
+			overSLA := ""
+			if item.OverSLA {
+				overSLA = " !" + ackSuffix(item.Issue)
+			}
+			badges := issueBadges(item.Issue, item.OverSLA, now)
+			if badges != "" {
+				badges = " " + badges
+			}
+			fmt.Printf(
+				"  %s: %s%s (%s since %s)%s; %s%s%s\n",
+				padLeft(item.Assignee, nameWidth(20)),
+				item.Key,
+				badges,
+				ageText(item.Age, item.Since, now),
+				displayTime(item.Since).Format(sinceLayout),
+				overSLA,
+				item.Issue.DisplaySummary(),
+				tagSuffix(item.Issue),
+				noteSuffix(item.Issue),
+			)
+		}
+		printOmitted("  ", shown, len(group))
+		fmt.Printf("]\n")
+	}
+	if *benchmarks {
+		printStageBenchmarks(items, stageBenchmarks, percentiles)
+	}
+	printWatchers(breaching, watching)
+
+	return nil
+}
+
+// ageBucketLimits are the oldest age in business days of each histogram
+// bucket but the last, which holds everything older:
+var ageBucketLimits = []int{2, 5, 10}
+
+var ageBucketLabels = []string{"0–2d", "3–5d", "6–10d", ">10d"}
+
+// ageBucketGlyphs draw each bucket's share of a histogram bar, older darker:
+var ageBucketGlyphs = []string{"░", "▒", "▓", "█"}
+
+// ageHistogramBarWidth is the width of the longest bar:
+const ageHistogramBarWidth = 40
+
+func ageBucket(days int) int {
+	for i, limit := range ageBucketLimits {
+		if days <= limit {
+			return i
+		}
+	}
+	return len(ageBucketLimits)
+}
+
+// statusAges counts one status's items per age bucket:
+type statusAges struct {
+	Status string
+	Stage  string
+	Counts []int
+	Total  int
+}
+
+// ageHistogram counts the items per status by age bucket, keeping the
+// statuses in the order the items are:
+func ageHistogram(items []AgingItem) []*statusAges {
+	var histogram []*statusAges
+	for _, item := range items {
+		if len(histogram) == 0 || histogram[len(histogram)-1].Status != item.Status {
+			histogram = append(histogram, &statusAges{Status: item.Status, Stage: item.Stage, Counts: make([]int, len(ageBucketLabels))})
+		}
+		h := histogram[len(histogram)-1]
+		h.Counts[ageBucket(item.Age)]++
+		h.Total++
+	}
+	return histogram
+}
+
+// printAgeHistogram prints the counts as a table, each row with a bar whose
+// segments show the buckets:
+func printAgeHistogram(histogram []*statusAges) {
+	names := make([]string, len(histogram))
+	width := len("status")
+	most := 0
+	for i, h := range histogram {
+		names[i] = h.Status
+		if h.Stage != "" && h.Stage != h.Status {
+			names[i] += " (" + h.Stage + ")"
+		}
+		if w := displayWidth(names[i]); w > width {
+			width = w
+		}
+		if h.Total > most {
+			most = h.Total
+		}
+	}
+
+	fmt.Printf("Items by business days in status:\n")
+	fmt.Printf("  %s", padRight("status", width))
+	for _, label := range ageBucketLabels {
+		fmt.Printf(" %6s", label)
+	}
+	fmt.Printf(" %6s\n", "total")
+	for i, h := range histogram {
+		fmt.Printf("  %s", padRight(names[i], width))
+		var bar strings.Builder
+		for i, n := range h.Counts {
+			fmt.Printf(" %6d", n)
+			bar.WriteString(strings.Repeat(ageBucketGlyphs[i], (n*ageHistogramBarWidth+most-1)/most))
+		}
+		fmt.Printf(" %6d  %s\n", h.Total, bar.String())
+	}
+
+	var legend []string
+	for i, label := range ageBucketLabels {
+		legend = append(legend, ageBucketGlyphs[i]+" "+label)
+	}
+	fmt.Printf("%s\n", strings.Join(legend, "  "))
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JamesDunne/jira-analysis/analysis"
+)
+
+func TestAgeText_HoursForFreshItems(t *testing.T) {
+	now := time.Date(2018, 11, 6, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		days     int
+		since    time.Time
+		expected string
+	}{
+		{3, now.AddDate(0, 0, -3), " 3 days old"},
+		{0, now.Add(-150 * time.Minute), "2h 30m old"},
+		{0, now.Add(-20 * time.Minute), "20m old"},
+		{0, now.Add(-10 * time.Second), "just now"},
+	}
+	for _, c := range cases {
+		if text := ageText(c.days, c.since, now); text != c.expected {
+			t.Fatalf("expected %q, got %q", c.expected, text)
+		}
+	}
+}
+
+func TestAgeHistogram(t *testing.T) {
+	items := []AgingItem{
+		{AgingItem: analysis.AgingItem{Status: "In Progress", Age: 0}},
+		{AgingItem: analysis.AgingItem{Status: "In Progress", Age: 2}},
+		{AgingItem: analysis.AgingItem{Status: "In Progress", Age: 3}},
+		{AgingItem: analysis.AgingItem{Status: "In Progress", Age: 11}},
+		{AgingItem: analysis.AgingItem{Status: "In Testing", Age: 10}},
+	}
+	histogram := ageHistogram(items)
+	if len(histogram) != 2 {
+		t.Fatalf("expected 2 statuses, got %d", len(histogram))
+	}
+	if c := histogram[0].Counts; c[0] != 2 || c[1] != 1 || c[2] != 0 || c[3] != 1 || histogram[0].Total != 4 {
+		t.Fatalf("expected In Progress counts [2 1 0 1], got %v", c)
+	}
+	if c := histogram[1].Counts; c[2] != 1 {
+		t.Fatalf("expected 10 days in the 6–10d bucket, got %v", c)
+	}
+}
+
+func TestExcludeBacklog(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/board/1/backlog") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"total": 1, "issues": [{"key": "A-1", "fields": {"status": {"name": "Open", "statusCategory": {"key": "new"}}}}]}`))
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+
+	backlog := Issue{Key: "A-1"}
+	inFlight := Issue{Key: "A-2"}
+	inFlight.Fields.Status = IssueStatus{Name: inProgressStatus, StatusCategory: StatusCategory{Key: "indeterminate"}}
+	done := Issue{Key: "A-3"}
+	done.Fields.Status = IssueStatus{Name: "Closed", StatusCategory: StatusCategory{Key: "done"}}
+	issues := []Issue{backlog, inFlight, done}
+
+	var keys []string
+	for _, issue := range excludeBacklog(srv.Client(), 1, issues) {
+		keys = append(keys, issue.Key)
+	}
+	if strings.Join(keys, " ") != "A-2 A-3" {
+		t.Fatalf("expected the backlog item dropped and the in-flight and done ones kept, got %v", keys)
+	}
+
+	// Without the backlog, nothing is dropped:
+	if onBoard := excludeBacklog(srv.Client(), 2, issues); len(onBoard) != 3 {
+		t.Fatalf("expected every issue kept when the backlog can't be fetched, got %d", len(onBoard))
+	}
+}
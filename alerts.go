@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SlackConfig routes rule breaches in Slack output to the people
+// responsible.
+type SlackConfig struct {
+	// Users maps any identity (username, accountId or email) to a Slack
+	// member ID, e.g. {"jdoe": "U024BE7LH"}:
+	Users map[string]string `json:"users"`
+	// Mention lists the badge rules (breach, blocked, stale) whose matches
+	// @-mention the responsible person; none by default.
+	Mention []string `json:"mention"`
+}
+
+// ruleAlert is an issue matching a rule that notifies its responsible
+// person, recorded with a report's metrics so memoized reports keep them:
+type ruleAlert struct {
+	Key    string `json:"key"`
+	Rule   string `json:"rule"`
+	Person string `json:"person"`
+	// Watchers of breaching issues are copied in, when fetched:
+	Watchers []string `json:"watchers,omitempty"`
+}
+
+// alertText says what each rule means for the issue:
+var alertText = map[string]string{
+	"breach":     "is over its SLA",
+	"blocked":    "is blocked",
+	"unassigned": "is unassigned",
+	"stale":      "has not changed in a while",
+	"truncated":  "has a truncated changelog",
+}
+
+func mentionsRule(rule string) bool {
+	for _, r := range config.Slack.Mention {
+		if strings.EqualFold(r, rule) {
+			return true
+		}
+	}
+	return false
+}
+
+// issueAlerts returns the alerts due for the rules an in-flight issue
+// matches, for the ones opted in to mentions:
+func issueAlerts(issue *Issue, overSLA bool, now time.Time) []ruleAlert {
+	if len(config.Slack.Mention) == 0 {
+		return nil
+	}
+	person := users.Name(issue.responsible())
+	var alerts []ruleAlert
+	for _, rule := range issueRules(issue, overSLA, now) {
+		// Acknowledged breaches stay quiet until the issue moves:
+		if rule == "breach" {
+			if _, ok := acknowledged(issue); ok {
+				continue
+			}
+		}
+		if mentionsRule(rule) {
+			alerts = append(alerts, ruleAlert{Key: issue.Key, Rule: rule, Person: person})
+		}
+	}
+	return alerts
+}
+
+// recordAlerts records the alerts, copying in the watchers of breaching issues
+// if they were fetched:
+func recordAlerts(alerts []ruleAlert, watchers map[string][]string) {
+	for i := range alerts {
+		if alerts[i].Rule == "breach" {
+			alerts[i].Watchers = watchers[alerts[i].Key]
+		}
+	}
+	if len(alerts) > 0 {
+		recordMetric("alerts", alerts)
+	}
+}
+
+// slackAddress mentions a person by Slack member ID, or names them without
+// one:
+func slackAddress(person string) string {
+	if id := slackMemberId(person); id != "" {
+		return "<@" + id + ">"
+	}
+	return slackText(person)
+}
+
+// slackMemberId finds the Slack member ID configured for a person, by any of
+// their identities:
+func slackMemberId(person string) string {
+	for identity, id := range config.Slack.Users {
+		if users.NameOf(identity) == person {
+			return id
+		}
+	}
+	return ""
+}
+
+// slackMentions renders a report's alerts as lines mentioning each responsible
+// person; people without a Slack ID are named instead.
+func slackMentions(metrics map[string]interface{}) []string {
+	value, ok := metrics["alerts"]
+	if !ok {
+		return nil
+	}
+	// Replayed from a memo, alerts come back as plain JSON values:
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var alerts []ruleAlert
+	if json.Unmarshal(b, &alerts) != nil {
+		return nil
+	}
+
+	var lines []string
+	for _, a := range alerts {
+		who := "(nobody)"
+		if a.Person != "" {
+			who = slackAddress(a.Person)
+		}
+		line := fmt.Sprintf("%s: %s %s", who, a.Key, alertText[a.Rule])
+		if len(a.Watchers) > 0 {
+			cc := make([]string, len(a.Watchers))
+			for i, watcher := range a.Watchers {
+				cc[i] = slackAddress(watcher)
+			}
+			line += " (cc " + strings.Join(cc, ", ") + ")"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlackMentions(t *testing.T) {
+	config = &Config{Slack: SlackConfig{Users: map[string]string{"alice": "U0ALICE"}, Mention: []string{"breach"}}}
+	users = newUserRegistry()
+	defer func() {
+		config = &Config{}
+		users = newUserRegistry()
+	}()
+
+	now := time.Date(2018, 11, 30, 9, 0, 0, 0, cst)
+	inFlight := func(key string, assignee string) *Issue {
+		issue := &Issue{Key: key}
+		issue.Fields.Assignee = &User{UserName: assignee}
+		issue.Fields.Created.Time = now
+		return issue
+	}
+
+	var alerts []ruleAlert
+	alerts = append(alerts, issueAlerts(inFlight("A-1", "alice"), true, now)...)
+	alerts = append(alerts, issueAlerts(inFlight("A-2", "bob"), true, now)...)
+	// Not over the SLA, and blocked isn't opted in:
+	alerts = append(alerts, issueAlerts(inFlight("A-3", "alice"), false, now)...)
+	if len(alerts) != 2 {
+		t.Fatalf("expected 2 breach alerts, got %v", alerts)
+	}
+
+	var posted map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&posted)
+	}))
+	defer srv.Close()
+	slack, _ := newSink(SinkConfig{Type: "slack", URL: srv.URL})
+
+	// Metrics replayed from a memo are plain JSON values:
+	b, _ := json.Marshal(alerts)
+	var replayed interface{}
+	json.Unmarshal(b, &replayed)
+	for _, metrics := range []map[string]interface{}{{"alerts": alerts}, {"alerts": replayed}} {
+		if err := slack.Write(&reportOutput{Command: "aging", Text: "In Progress:\n", Metrics: metrics}); err != nil {
+			t.Fatal(err)
+		}
+		want := "```\n<@U0ALICE>: A-1 is over its SLA\nbob: A-2 is over its SLA"
+		if !strings.HasSuffix(posted["text"], want) {
+			t.Fatalf("expected mentions after the report, got %q", posted["text"])
+		}
+	}
+}
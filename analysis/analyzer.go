@@ -0,0 +1,243 @@
+// Package analysis computes flow metrics from issues' status histories: how
+// long in-flight work has aged, completed work's cycle times and the time it
+// spent in each status. Metrics are typed values, independent of where the
+// issues come from or how the results are presented.
+package analysis
+
+import (
+	"sort"
+	"time"
+)
+
+// Calendar counts working days between two times.
+type Calendar interface {
+	BusinessDays(from, until time.Time) int
+}
+
+// Weekdays counts Monday to Friday:
+type Weekdays struct{}
+
+func (Weekdays) BusinessDays(from, until time.Time) int {
+	return DateOf(from).BusinessDaysUntil(DateOf(until))
+}
+
+// Holidays counts Monday to Friday except the dates ("2006-01-02") off:
+type Holidays map[string]bool
+
+func (h Holidays) BusinessDays(from, until time.Time) int {
+	days := Weekdays{}.BusinessDays(from, until)
+	if len(h) == 0 {
+		return days
+	}
+	end := DateOf(until)
+	for d := DateOf(from); d.Time.Before(end.Time); d = d.NextDate() {
+		if weekday := d.Weekday(); weekday != time.Saturday && weekday != time.Sunday && h[d.Format("2006-01-02")] {
+			days--
+		}
+	}
+	return days
+}
+
+// Issue is what metrics are computed from: an issue's current status and the
+// statuses it passed through.
+type Issue struct {
+	Key      string
+	Status   string
+	Assignee string
+	// StatusSince is when the issue entered its current status:
+	StatusSince time.Time
+	// Started and Completed are zero until the issue starts or completes:
+	Started   time.Time
+	Completed time.Time
+	// Intervals are the periods spent in each status, in order:
+	Intervals []Interval
+
+	// Ref is the source's own representation of the issue, for callers to
+	// get back from the metrics:
+	Ref interface{}
+}
+
+// Interval is a period an issue spent in a status; End is zero while the
+// issue is still in it.
+type Interval struct {
+	Status string
+	Start  time.Time
+	End    time.Time
+}
+
+// Source fetches a board's issues for the Analyzer.
+type Source interface {
+	// InFlight lists the board's issues matching jql that are in a status,
+	// excluding its backlog and epics.
+	InFlight(boardId int, jql string) ([]Issue, error)
+	// Issues lists the board's issues matching jql whose histories can be
+	// measured, excluding epics.
+	Issues(boardId int, jql string) ([]Issue, error)
+}
+
+// SLAPolicy picks an in-flight issue's SLA in business days given the
+// stage its status maps to and the Analyzer's default, 0 for none.
+type SLAPolicy func(issue *Issue, stage string, fallback int) int
+
+// Analyzer computes board metrics as typed values, independent of how they
+// are presented.
+type Analyzer struct {
+	source    Source
+	calendar  Calendar
+	statusMap map[string]string
+	sla       int
+	slaPolicy SLAPolicy
+	now       time.Time
+}
+
+type Option func(a *Analyzer)
+
+// WithCalendar sets the calendar ages and cycle times are counted in; default
+// is Weekdays.
+func WithCalendar(c Calendar) Option {
+	return func(a *Analyzer) { a.calendar = c }
+}
+
+// WithStatusMap gives statuses the stage names they are reported under:
+func WithStatusMap(m map[string]string) Option {
+	return func(a *Analyzer) { a.statusMap = m }
+}
+
+// WithSLA flags in-flight items older than the given business days, unless
+// the SLA policy sets their threshold:
+func WithSLA(days int) Option {
+	return func(a *Analyzer) { a.sla = days }
+}
+
+// WithSLAPolicy sets thresholds per issue, e.g. shorter for bugs; default is
+// the WithSLA days for every issue.
+func WithSLAPolicy(policy SLAPolicy) Option {
+	return func(a *Analyzer) { a.slaPolicy = policy }
+}
+
+// WithNow sets the time metrics are computed as of; default is the time the
+// Analyzer was made.
+func WithNow(now time.Time) Option {
+	return func(a *Analyzer) { a.now = now }
+}
+
+// NewAnalyzer computes metrics of the issues source fetches; the *Of methods
+// take issues directly and need no source.
+func NewAnalyzer(source Source, opts ...Option) *Analyzer {
+	a := &Analyzer{
+		source:   source,
+		calendar: Weekdays{},
+		now:      time.Now(),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// AgingItem is one in-flight issue with its age in its current status.
+type AgingItem struct {
+	Key      string
+	Status   string
+	Stage    string
+	Assignee string
+	Since    time.Time
+	Age      int
+	// SLA is the business days the item may age, 0 for none:
+	SLA     int
+	OverSLA bool
+
+	Issue *Issue
+}
+
+// Aging lists in-flight issues on the board, excluding its backlog, ordered by
+// status then oldest first.
+func (a *Analyzer) Aging(boardId int, jql string) ([]AgingItem, error) {
+	issues, err := a.source.InFlight(boardId, jql)
+	if err != nil {
+		return nil, err
+	}
+	return a.AgingOf(issues), nil
+}
+
+// AgingOf ages in-flight issues in their current status.
+func (a *Analyzer) AgingOf(issues []Issue) []AgingItem {
+	items := make([]AgingItem, 0, len(issues))
+	for i := range issues {
+		issue := &issues[i]
+		age := a.calendar.BusinessDays(issue.StatusSince, a.now)
+		stage := a.statusMap[issue.Status]
+		sla := a.sla
+		if a.slaPolicy != nil {
+			sla = a.slaPolicy(issue, stage, a.sla)
+		}
+		items = append(items, AgingItem{
+			Key:      issue.Key,
+			Status:   issue.Status,
+			Stage:    stage,
+			Assignee: issue.Assignee,
+			Since:    issue.StatusSince,
+			Age:      age,
+			SLA:      sla,
+			OverSLA:  sla > 0 && age > sla,
+			Issue:    issue,
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Status != items[j].Status {
+			return items[i].Status < items[j].Status
+		}
+		if items[i].Age != items[j].Age {
+			return items[i].Age > items[j].Age
+		}
+		// Within the same day, oldest first:
+		if !items[i].Since.Equal(items[j].Since) {
+			return items[i].Since.Before(items[j].Since)
+		}
+		return LessKey(items[i].Key, items[j].Key)
+	})
+	return items
+}
+
+// CycleTimeMetrics summarizes completed issues' cycle times in business days.
+type CycleTimeMetrics struct {
+	Count       int
+	Mean        float64
+	Percentiles map[float64]int
+	// Days holds each cycle time, sorted:
+	Days []int
+}
+
+// CycleTimes measures start-to-completion time of the board's completed
+// issues at the given percentiles.
+func (a *Analyzer) CycleTimes(boardId int, jql string, percentiles []float64) (*CycleTimeMetrics, error) {
+	issues, err := a.source.Issues(boardId, jql)
+	if err != nil {
+		return nil, err
+	}
+	return a.CycleTimesOf(issues, percentiles), nil
+}
+
+// CycleTimesOf measures the cycle times of the issues started and completed.
+func (a *Analyzer) CycleTimesOf(issues []Issue, percentiles []float64) *CycleTimeMetrics {
+	var days []int
+	for i := range issues {
+		issue := &issues[i]
+		if issue.Started.IsZero() || issue.Completed.IsZero() {
+			continue
+		}
+		days = append(days, a.calendar.BusinessDays(issue.Started, issue.Completed))
+	}
+
+	m := &CycleTimeMetrics{
+		Count:       len(days),
+		Mean:        Mean(days),
+		Percentiles: make(map[float64]int, len(percentiles)),
+		Days:        sortedCopy(days),
+	}
+	for _, p := range percentiles {
+		m.Percentiles[p] = Percentile(m.Days, p)
+	}
+	return m
+}
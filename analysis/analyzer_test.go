@@ -0,0 +1,142 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+)
+
+func day(d int) time.Time {
+	return time.Date(2018, 11, d, 9, 0, 0, 0, time.UTC)
+}
+
+// calendarDays counts every day, weekends included:
+type calendarDays struct{}
+
+func (calendarDays) BusinessDays(from, until time.Time) int {
+	return int(until.Sub(from).Hours() / 24)
+}
+
+// staticSource serves the same issues for every board and query:
+type staticSource struct {
+	inFlight, issues []Issue
+}
+
+func (s staticSource) InFlight(boardId int, jql string) ([]Issue, error) {
+	return s.inFlight, nil
+}
+
+func (s staticSource) Issues(boardId int, jql string) ([]Issue, error) {
+	return s.issues, nil
+}
+
+func TestAnalyzer_Aging(t *testing.T) {
+	source := staticSource{inFlight: []Issue{
+		{Key: "A-10", Status: "Dev", StatusSince: day(1), Assignee: "alice"},
+		{Key: "A-9", Status: "Dev", StatusSince: day(1)},
+		{Key: "A-3", Status: "QA", StatusSince: day(5), Ref: "bug"},
+		{Key: "A-4", Status: "Dev", StatusSince: day(5)},
+	}}
+	// Bugs get 2 days wherever they are:
+	policy := func(issue *Issue, stage string, fallback int) int {
+		if issue.Ref == "bug" {
+			return 2
+		}
+		return fallback
+	}
+
+	a := NewAnalyzer(source, WithNow(day(8)), WithStatusMap(map[string]string{"Dev": "Build"}), WithSLA(5), WithSLAPolicy(policy))
+	items, err := a.Aging(1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	for _, item := range items {
+		keys = append(keys, item.Key)
+	}
+	if len(keys) != 4 || keys[0] != "A-9" || keys[1] != "A-10" || keys[2] != "A-4" || keys[3] != "A-3" {
+		t.Fatalf("expected Dev oldest first by key, then QA, got %v", keys)
+	}
+	if oldest := items[1]; oldest.Stage != "Build" || oldest.Age != 5 || oldest.OverSLA || oldest.Assignee != "alice" {
+		t.Fatalf("expected A-10 in Build aged 5 days within its SLA, got %+v", oldest)
+	}
+	if bug := items[3]; bug.SLA != 2 || !bug.OverSLA || bug.Issue.Ref != "bug" {
+		t.Fatalf("expected the bug over its 2 day SLA, got %+v", bug)
+	}
+}
+
+func TestAnalyzer_CycleTimesOf_WithCalendar(t *testing.T) {
+	issues := []Issue{
+		// Friday to Monday:
+		{Key: "A-1", Started: day(2), Completed: day(5)},
+		// Not completed:
+		{Key: "A-2", Started: day(2)},
+	}
+
+	m := NewAnalyzer(nil).CycleTimesOf(issues, []float64{50})
+	if m.Count != 1 || m.Percentiles[50] != 1 {
+		t.Fatalf("expected 1 business day by default, got %+v", m)
+	}
+
+	m = NewAnalyzer(nil, WithCalendar(calendarDays{})).CycleTimesOf(issues, []float64{50})
+	if m.Percentiles[50] != 3 {
+		t.Fatalf("expected 3 calendar days, got %+v", m)
+	}
+}
+
+func TestHolidays_BusinessDays(t *testing.T) {
+	// Thursday 22nd to Tuesday 27th, with Thanksgiving and a Saturday off:
+	h := Holidays{"2018-11-22": true, "2018-11-24": true}
+	if days := h.BusinessDays(day(22), day(27)); days != 2 {
+		t.Errorf("expected Friday and Monday, got %d", days)
+	}
+	if days := (Holidays{}).BusinessDays(day(22), day(27)); days != 3 {
+		t.Errorf("expected weekdays without holidays, got %d", days)
+	}
+}
+
+func TestAnalyzer_StageBenchmarksOf(t *testing.T) {
+	inQA := func(key string, started, qa, completed time.Time) Issue {
+		return Issue{Key: key, Started: started, Completed: completed, Intervals: []Interval{
+			{Status: "To Do", Start: day(1), End: started},
+			{Status: "Dev", Start: started, End: qa},
+			{Status: "QA", Start: qa},
+		}}
+	}
+	issues := []Issue{
+		// Monday to Wednesday in Dev, then QA to Friday:
+		inQA("A-1", day(5), day(7), day(9)),
+		inQA("A-2", day(5), day(6), day(7)),
+		inQA("A-3", day(5), day(8), day(12)),
+		// Not completed:
+		{Key: "A-4", Started: day(5)},
+	}
+
+	benchmarks := NewAnalyzer(nil, WithStatusMap(map[string]string{"QA": "Testing"})).StageBenchmarksOf(issues, []float64{50, 85})
+	qa := benchmarks["QA"]
+	if qa.Stage != "Testing" || qa.Count != 3 || qa.Percentiles[50] != 2 || qa.Percentiles[85] != 2 {
+		t.Errorf("expected QA over 3 issues at p50 2 and p85 2 days, got %+v", qa)
+	}
+	if dev := benchmarks["Dev"]; dev.Count != 3 || dev.Percentiles[50] != 2 || dev.Percentiles[85] != 3 {
+		t.Errorf("expected Dev at p50 2 and p85 3 days, got %+v", dev)
+	}
+	if todo := benchmarks["To Do"]; todo.Percentiles[85] != 0 {
+		t.Errorf("expected no time counted before starting, got %+v", todo)
+	}
+}
+
+func TestLessKey(t *testing.T) {
+	for _, c := range []struct {
+		a, b string
+		less bool
+	}{
+		{"ABC-9", "ABC-10", true},
+		{"ABC-10", "ABC-9", false},
+		{"ABC-10", "ABD-1", true},
+		{"ABC-x", "ABC-1", false},
+	} {
+		if got := LessKey(c.a, c.b); got != c.less {
+			t.Errorf("LessKey(%s, %s) = %v, want %v", c.a, c.b, got, c.less)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package analysis
+
+// StageBenchmark is how long completed work spent in a status, in business
+// days, to compare in-flight items' ages with.
+type StageBenchmark struct {
+	Status string
+	Stage  string
+	// Count is the completed issues that passed through the status:
+	Count int
+	// Percentiles are the days spent, by percentile:
+	Percentiles map[float64]int
+}
+
+// StageBenchmarks measures the time the board's completed issues spent in
+// each status between starting and completing, at the given percentiles.
+func (a *Analyzer) StageBenchmarks(boardId int, jql string, percentiles []float64) (map[string]StageBenchmark, error) {
+	issues, err := a.source.Issues(boardId, jql)
+	if err != nil {
+		return nil, err
+	}
+	return a.StageBenchmarksOf(issues, percentiles), nil
+}
+
+// StageBenchmarksOf measures the time the completed issues spent in each
+// status between starting and completing.
+func (a *Analyzer) StageBenchmarksOf(issues []Issue, percentiles []float64) map[string]StageBenchmark {
+	days := make(map[string][]int)
+	for i := range issues {
+		issue := &issues[i]
+		started, completed := issue.Started, issue.Completed
+		if started.IsZero() || completed.IsZero() {
+			continue
+		}
+		// Returning to a status adds to the time spent in it:
+		spent := make(map[string]int)
+		for _, in := range issue.Intervals {
+			start, end := in.Start, in.End
+			if end.IsZero() || end.After(completed) {
+				end = completed
+			}
+			if start.Before(started) {
+				start = started
+			}
+			if end.Before(start) {
+				continue
+			}
+			spent[in.Status] += a.calendar.BusinessDays(start, end)
+		}
+		for status, d := range spent {
+			days[status] = append(days[status], d)
+		}
+	}
+
+	benchmarks := make(map[string]StageBenchmark, len(days))
+	for status, d := range days {
+		sorted := sortedCopy(d)
+		b := StageBenchmark{
+			Status:      status,
+			Stage:       a.statusMap[status],
+			Count:       len(sorted),
+			Percentiles: make(map[float64]int, len(percentiles)),
+		}
+		for _, p := range percentiles {
+			b.Percentiles[p] = Percentile(sorted, p)
+		}
+		benchmarks[status] = b
+	}
+	return benchmarks
+}
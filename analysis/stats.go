@@ -0,0 +1,62 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Percentile picks the nearest-rank percentile p (0-100] of sorted values,
+// 0 when there are none.
+func Percentile(sorted []int, p float64) int {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// Mean is the average of values, 0 when there are none.
+func Mean(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sum := 0
+	for _, v := range values {
+		sum += v
+	}
+	return float64(sum) / float64(len(values))
+}
+
+func sortedCopy(values []int) []int {
+	sorted := make([]int, len(values))
+	copy(sorted, values)
+	sort.Ints(sorted)
+	return sorted
+}
+
+// LessKey orders issue keys by project, then numerically, so ABC-9 comes
+// before ABC-10.
+func LessKey(a, b string) bool {
+	ai := strings.LastIndex(a, "-")
+	bi := strings.LastIndex(b, "-")
+	if ai < 0 || bi < 0 || a[:ai] != b[:bi] {
+		return a < b
+	}
+
+	an, aerr := strconv.Atoi(a[ai+1:])
+	bn, berr := strconv.Atoi(b[bi+1:])
+	if aerr != nil || berr != nil || an == bn {
+		return a < b
+	}
+	return an < bn
+}
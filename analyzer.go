@@ -0,0 +1,101 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/JamesDunne/jira-analysis/analysis"
+)
+
+// boardSource serves the analysis package a board's issues from JIRA:
+type boardSource struct {
+	cl *http.Client
+}
+
+func (s boardSource) InFlight(boardId int, jql string) ([]analysis.Issue, error) {
+	issues, err := fetchBoardIssues(s.cl, boardId, jql)
+	if err != nil {
+		return nil, err
+	}
+	return inFlightIssues(excludeBacklog(s.cl, boardId, issues)), nil
+}
+
+func (s boardSource) Issues(boardId int, jql string) ([]analysis.Issue, error) {
+	issues, err := fetchBoardIssues(s.cl, boardId, jql)
+	if err != nil {
+		return nil, err
+	}
+	return measuredIssues(issues), nil
+}
+
+// inFlightIssues maps issues in a status onto the analysis package's, with
+// who moved each there as its assignee:
+func inFlightIssues(issues []Issue) []analysis.Issue {
+	var inFlight []analysis.Issue
+	for status, statusIssues := range computeAging(issues, DateOf(reportNow())) {
+		for _, issue := range statusIssues {
+			inFlight = append(inFlight, analysis.Issue{
+				Key:         issue.Key,
+				Status:      status,
+				Assignee:    users.Name(issue.Assigned),
+				StatusSince: issue.StatusTime,
+				Ref:         issue,
+			})
+		}
+	}
+	return inFlight
+}
+
+// measuredIssues maps issues whose histories can be trusted onto the analysis
+// package's, leaving out epics:
+func measuredIssues(issues []Issue) []analysis.Issue {
+	var measured []analysis.Issue
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() || !issue.trustedForPercentiles() {
+			continue
+		}
+
+		m := analysis.Issue{Key: issue.Key, Status: issue.Fields.Status.Name, Ref: issue}
+		m.Started, _ = issue.StartedTime()
+		m.Completed, _ = issue.CompletedTime()
+		for _, in := range issue.statusIntervals() {
+			m.Intervals = append(m.Intervals, analysis.Interval{Status: in.Status, Start: in.Start, End: in.End})
+		}
+		measured = append(measured, m)
+	}
+	return measured
+}
+
+// newAnalyzer analyzes the board's issues in JIRA as of the report's time,
+// in the work calendar and with the configured SLA policies:
+func newAnalyzer(cl *http.Client, opts ...analysis.Option) *analysis.Analyzer {
+	defaults := []analysis.Option{analysis.WithNow(reportNow()), analysis.WithCalendar(workCalendar()), analysis.WithSLAPolicy(configuredSLA)}
+	return analysis.NewAnalyzer(boardSource{cl}, append(defaults, opts...)...)
+}
+
+func configuredSLA(issue *analysis.Issue, stage string, fallback int) int {
+	return slaThreshold(issue.Ref.(*Issue), issue.Status, stage, fallback)
+}
+
+// AgingItem is an in-flight issue's age, with the issue it's for:
+type AgingItem struct {
+	analysis.AgingItem
+	Issue *Issue
+}
+
+// agingItems lists the board's in-flight issues with their ages:
+func agingItems(a *analysis.Analyzer, boardId int, jql string) ([]AgingItem, error) {
+	aged, err := a.Aging(boardId, jql)
+	if err != nil {
+		return nil, err
+	}
+	return agingItemsOf(aged), nil
+}
+
+func agingItemsOf(aged []analysis.AgingItem) []AgingItem {
+	items := make([]AgingItem, len(aged))
+	for i, item := range aged {
+		items[i] = AgingItem{AgingItem: item, Issue: item.Issue.Ref.(*Issue)}
+	}
+	return items
+}
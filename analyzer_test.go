@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JamesDunne/jira-analysis/analysis"
+)
+
+// calendarDays counts every day, weekends included:
+type calendarDays struct{}
+
+func (calendarDays) BusinessDays(from, until time.Time) int {
+	return int(until.Sub(from).Hours() / 24)
+}
+
+func TestAnalyzer_CycleTimes_WithCalendar(t *testing.T) {
+	issues := []Issue{
+		// Friday to Monday:
+		completedIssue("A-1", time.Date(2018, 11, 2, 9, 0, 0, 0, time.UTC), time.Date(2018, 11, 5, 9, 0, 0, 0, time.UTC)),
+	}
+
+	m := newAnalyzer(nil).CycleTimesOf(measuredIssues(issues), []float64{50})
+	if m.Count != 1 || m.Percentiles[50] != 1 {
+		t.Fatalf("expected 1 business day by default, got %+v", m)
+	}
+
+	m = newAnalyzer(nil, analysis.WithCalendar(calendarDays{})).CycleTimesOf(measuredIssues(issues), []float64{50})
+	if m.Percentiles[50] != 3 {
+		t.Fatalf("expected 3 calendar days, got %+v", m)
+	}
+}
+
+func TestAnalyzer_Aging_WithStatusMapAndSLA(t *testing.T) {
+	issue := Issue{Key: "A-1"}
+	issue.Changelog.Histories = []History{
+		{
+			Created: zonedTimestamp{time.Date(2018, 10, 29, 9, 0, 0, 0, time.UTC)},
+			Items:   []HistoryItem{{Field: "status", ToString: inProgressStatus}},
+		},
+	}
+
+	now := time.Date(2018, 11, 6, 12, 0, 0, 0, time.UTC)
+	a := newAnalyzer(nil, analysis.WithNow(now), analysis.WithStatusMap(map[string]string{inProgressStatus: "Dev"}), analysis.WithSLA(5))
+	items := agingItemsOf(a.AgingOf(inFlightIssues([]Issue{issue})))
+	if len(items) != 1 {
+		t.Fatalf("expected 1 aging item, got %d", len(items))
+	}
+	if items[0].Stage != "Dev" || items[0].Age != 6 || !items[0].OverSLA || items[0].Issue.Key != "A-1" {
+		t.Fatalf("expected A-1 in the Dev stage aged 6 days over SLA, got %+v", items[0])
+	}
+}
+
+func TestAnalyzer_StageBenchmarks(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2018, 11, d, 9, 0, 0, 0, time.UTC) }
+	inQA := func(key string, started, qa, resolved time.Time) Issue {
+		issue := completedIssue(key, started, resolved)
+		issue.Changelog.Histories = append(issue.Changelog.Histories, History{
+			Created: zonedTimestamp{qa},
+			Items:   []HistoryItem{{Field: "status", FromString: inProgressStatus, ToString: "QA"}},
+		})
+		return issue
+	}
+	issues := []Issue{
+		// Monday to Wednesday in progress, then QA to Friday:
+		inQA("A-1", day(5), day(7), day(9)),
+		inQA("A-2", day(5), day(6), day(7)),
+		inQA("A-3", day(5), day(8), day(12)),
+		// Not completed:
+		{Key: "A-4"},
+	}
+
+	benchmarks := newAnalyzer(nil, analysis.WithStatusMap(map[string]string{"QA": "Testing"})).StageBenchmarksOf(measuredIssues(issues), []float64{50, 85})
+	qa := benchmarks["QA"]
+	if qa.Stage != "Testing" || qa.Count != 3 || qa.Percentiles[50] != 2 || qa.Percentiles[85] != 2 {
+		t.Errorf("expected QA over 3 issues at p50 2 and p85 2 days, got %+v", qa)
+	}
+	if dev := benchmarks[inProgressStatus]; dev.Count != 3 || dev.Percentiles[50] != 2 || dev.Percentiles[85] != 3 {
+		t.Errorf("expected development at p50 2 and p85 3 days, got %+v", dev)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// metricSeries is one daily metric over consecutive business days:
+type metricSeries struct {
+	Name   string
+	Days   []time.Time
+	Values []float64
+}
+
+type anomaly struct {
+	Metric string
+	Day    time.Time
+	Value  float64
+	Mean   float64
+	Z      float64
+}
+
+func (a anomaly) String() string {
+	direction := "above"
+	if a.Z < 0 {
+		direction = "below"
+	}
+	return fmt.Sprintf("%s %.0fσ %s normal on %s (%.1f vs %.1f)", a.Metric, math.Abs(a.Z), direction, a.Day.Format("Mon Jan 02"), a.Value, a.Mean)
+}
+
+// businessDays lists the end of each weekday in the days up to now:
+func businessDays(now time.Time, days int) []time.Time {
+	var ends []time.Time
+	y, m, d := now.Date()
+	end := time.Date(y, m, d, 23, 59, 59, 0, now.Location())
+	for i := days - 1; i >= 0; i-- {
+		day := end.AddDate(0, 0, -i)
+		if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			continue
+		}
+		ends = append(ends, day)
+	}
+	return ends
+}
+
+// dailySeries measures daily WIP, throughput and, per status, the mean age of
+// the items sitting in it at the end of each business day.
+func dailySeries(issues []Issue, ends []time.Time) []metricSeries {
+	wip := metricSeries{Name: "WIP", Days: ends, Values: make([]float64, len(ends))}
+	throughput := metricSeries{Name: "throughput", Days: ends, Values: make([]float64, len(ends))}
+	ageSums := make(map[string][]float64)
+	ageCounts := make(map[string][]int)
+
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		started, isStarted := issue.StartedTime()
+		completed, isCompleted := issue.CompletedTime()
+		intervals := issue.statusIntervals()
+
+		for d, end := range ends {
+			start := end.Add(-24 * time.Hour)
+			if isCompleted && completed.After(start) && !completed.After(end) {
+				throughput.Values[d]++
+			}
+			if !isStarted || started.After(end) || isCompleted && !completed.After(end) {
+				continue
+			}
+			wip.Values[d]++
+
+			// Age in the status held at the end of the day:
+			for _, in := range intervals {
+				if in.Start.After(end) || !in.End.IsZero() && !in.End.After(end) {
+					continue
+				}
+				if _, ok := ageSums[in.Status]; !ok {
+					ageSums[in.Status] = make([]float64, len(ends))
+					ageCounts[in.Status] = make([]int, len(ends))
+				}
+				ageSums[in.Status][d] += days(end.Sub(in.Start))
+				ageCounts[in.Status][d]++
+				break
+			}
+		}
+	}
+
+	series := []metricSeries{wip, throughput}
+	statuses := make([]string, 0, len(ageSums))
+	for status := range ageSums {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		s := metricSeries{Name: stageName(status) + " age", Days: ends, Values: make([]float64, len(ends))}
+		for d := range ends {
+			if n := ageCounts[status][d]; n > 0 {
+				s.Values[d] = ageSums[status][d] / float64(n)
+			}
+		}
+		series = append(series, s)
+	}
+	return series
+}
+
+// rollingAnomalies flags the days in the last recent days whose value is at
+// least threshold standard deviations from the window days before it.
+func rollingAnomalies(s metricSeries, window int, recent int, threshold float64) []anomaly {
+	var found []anomaly
+	for d := len(s.Values) - recent; d < len(s.Values); d++ {
+		if d < window {
+			continue
+		}
+		history := s.Values[d-window : d]
+		z := zScore(history, s.Values[d])
+		if math.Abs(z) >= threshold {
+			found = append(found, anomaly{Metric: s.Name, Day: s.Days[d], Value: s.Values[d], Mean: meanFloat(history), Z: z})
+		}
+	}
+	return found
+}
+
+// detectAnomalies checks every daily metric over the last recent business
+// days against a rolling window of the window business days before each.
+func detectAnomalies(issues []Issue, now time.Time, window int, recent int, threshold float64) []anomaly {
+	// Calendar days enough to cover window+recent business days:
+	days := businessDays(now, (window+recent)*7/5+7)
+
+	var found []anomaly
+	for _, s := range dailySeries(issues, days) {
+		found = append(found, rollingAnomalies(s, window, recent, threshold)...)
+	}
+	return found
+}
+
+func runAnomalies(args []string) error {
+	fs := flag.NewFlagSet("anomalies", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting issues; default='resolved >= -90d OR resolution is EMPTY'")
+	window := fs.Int("window", 20, "business days of history each day is compared with")
+	recent := fs.Int("days", 5, "business days to check for anomalies")
+	threshold := fs.Float64("threshold", 3, "standard deviations from the rolling mean that count as anomalous")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("anomalies", *jql, "resolved >= -90d OR resolution is EMPTY"))
+	if err != nil {
+		return err
+	}
+
+	found := detectAnomalies(issues, reportNow(), *window, *recent, *threshold)
+	fmt.Printf("Anomalies in the last %s (%.1fσ from the previous %d business days):\n", plural(*recent, "business day"), *threshold, *window)
+	if len(found) == 0 {
+		fmt.Printf("  none\n")
+	}
+	for _, a := range found {
+		fmt.Printf("  %s\n", a)
+	}
+	return nil
+}
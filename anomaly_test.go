@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRollingAnomalies(t *testing.T) {
+	s := metricSeries{Name: "PR age"}
+	day := time.Date(2018, 11, 1, 23, 59, 59, 0, time.UTC)
+	for i, v := range []float64{2, 3, 2, 3, 2, 3, 2, 3, 2.5, 9} {
+		s.Days = append(s.Days, day.AddDate(0, 0, i))
+		s.Values = append(s.Values, v)
+	}
+
+	found := rollingAnomalies(s, 8, 2, 3)
+	if len(found) != 1 {
+		t.Fatalf("expected 1 anomaly, got %v", found)
+	}
+	if found[0].Value != 9 || found[0].Z < 3 {
+		t.Fatalf("expected the jump to 9 flagged above 3σ, got %+v", found[0])
+	}
+	if found[0].String() != "PR age 14σ above normal on Sat Nov 10 (9.0 vs 2.6)" {
+		t.Fatalf("unexpected description %q", found[0].String())
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// asOf is the past time -as-of reports on, zero for now. Issues are rewound
+// to their state then from their changelogs, so WIP and aging can be looked
+// back on without snapshots having been taken.
+var asOf time.Time
+
+// parseAsOf reads a -as-of date as the end of that day, which must be past:
+func parseAsOf(s string) (time.Time, error) {
+	d, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("-as-of: %v", err)
+	}
+	t := d.AddDate(0, 0, 1).Add(-time.Second)
+	if !d.Before(time.Now()) {
+		return time.Time{}, fmt.Errorf("-as-of: %s is not in the past", s)
+	}
+	return t, nil
+}
+
+// asOfJQL bounds a query to the issues created by the -as-of date:
+func asOfJQL() string {
+	return fmt.Sprintf(`created < "%s"`, asOf.AddDate(0, 0, 1).Format("2006-01-02"))
+}
+
+// rewindIssues returns the issues as they were at t, leaving out those not
+// yet created. Changes since are undone in status, resolution and assignee,
+// the fields reports place issues by.
+func rewindIssues(issues []Issue, t time.Time) []Issue {
+	// Statuses keep their category; those no issue is in any longer are
+	// done when resolved, else in progress:
+	categories := make(map[string]StatusCategory)
+	for i := range issues {
+		categories[issues[i].Fields.Status.Name] = issues[i].Fields.Status.StatusCategory
+	}
+
+	rewound := issues[:0]
+	for _, issue := range issues {
+		if issue.Fields.Created.After(t) {
+			continue
+		}
+		rewindIssue(&issue, t, categories)
+		rewound = append(rewound, issue)
+	}
+	return rewound
+}
+
+func rewindIssue(issue *Issue, t time.Time, categories map[string]StatusCategory) {
+	histories := issue.Changelog.Histories
+	kept := len(histories)
+	for kept > 0 && histories[kept-1].Created.After(t) {
+		kept--
+	}
+	// Changelogs are oldest first, so undoing the later changes newest first
+	// leaves each field as it was at t:
+	statusChanged, resolutionChanged := false, false
+	for i := len(histories) - 1; i >= kept; i-- {
+		for _, item := range histories[i].Items {
+			switch item.Field {
+			case "status":
+				issue.Fields.Status.Name = item.FromString
+				statusChanged = true
+			case "resolution":
+				if item.FromString == "" {
+					issue.Fields.Resolution = nil
+				} else {
+					issue.Fields.Resolution = &Resolution{Name: item.FromString}
+				}
+				resolutionChanged = true
+			case "assignee":
+				if item.From == "" {
+					issue.Fields.Assignee = nil
+				} else {
+					issue.Fields.Assignee = &User{UserName: item.From, AccountId: item.From, DisplayName: item.FromString}
+				}
+			}
+		}
+	}
+	issue.Changelog.Total -= len(histories) - kept
+	issue.Changelog.Histories = histories[:kept]
+
+	if issue.Fields.ResolutionDate.After(t) || resolutionChanged {
+		if !resolutionChanged {
+			issue.Fields.Resolution = nil
+		}
+		issue.Fields.ResolutionDate = zonedTimestamp{}
+		if issue.Fields.Resolution != nil {
+			issue.Fields.ResolutionDate = lastResolved(histories[:kept])
+		}
+	}
+
+	if statusChanged {
+		category, ok := categories[issue.Fields.Status.Name]
+		if !ok {
+			category = StatusCategory{Key: "indeterminate"}
+			if issue.Fields.Resolution != nil {
+				category = StatusCategory{Key: "done"}
+			}
+		}
+		issue.Fields.Status.StatusCategory = category
+	}
+}
+
+// lastResolved is when an issue was last resolved in its history:
+func lastResolved(histories []History) zonedTimestamp {
+	for i := len(histories) - 1; i >= 0; i-- {
+		for _, item := range histories[i].Items {
+			if item.Field == "resolution" && item.ToString != "" {
+				return histories[i].Created
+			}
+		}
+	}
+	return zonedTimestamp{}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRewindIssues(t *testing.T) {
+	at := func(day int) zonedTimestamp { return zonedTimestamp{time.Date(2024, 3, day, 9, 0, 0, 0, time.UTC)} }
+	done := IssueStatus{Name: "Done", StatusCategory: StatusCategory{Key: "done"}}
+
+	resolved := Issue{Key: "A-1"}
+	resolved.Fields.Created = at(1)
+	resolved.Fields.Status = done
+	resolved.Fields.Resolution = &Resolution{Name: "Fixed"}
+	resolved.Fields.ResolutionDate = at(20)
+	resolved.Fields.Assignee = &User{UserName: "carol"}
+	resolved.Changelog.Histories = []History{
+		{Created: at(4), Items: []HistoryItem{{Field: "status", FromString: "To Do", ToString: "In Progress"}}},
+		{Created: at(20), Items: []HistoryItem{
+			{Field: "status", FromString: "In Progress", ToString: "Done"},
+			{Field: "resolution", ToString: "Fixed"},
+			{Field: "assignee", From: "alice", FromString: "Alice", To: "carol", ToString: "Carol"},
+		}},
+	}
+	resolved.Changelog.Total = 2
+
+	// Resolved before, reopened since:
+	reopened := Issue{Key: "A-2"}
+	reopened.Fields.Created = at(1)
+	reopened.Fields.Status = IssueStatus{Name: "In Progress", StatusCategory: StatusCategory{Key: "indeterminate"}}
+	reopened.Changelog.Histories = []History{
+		{Created: at(5), Items: []HistoryItem{{Field: "status", FromString: "In Progress", ToString: "Done"}, {Field: "resolution", ToString: "Fixed"}}},
+		{Created: at(25), Items: []HistoryItem{{Field: "status", FromString: "Done", ToString: "In Progress"}, {Field: "resolution", FromString: "Fixed"}}},
+	}
+
+	later := Issue{Key: "A-3"}
+	later.Fields.Created = at(15)
+
+	issues := rewindIssues([]Issue{resolved, reopened, later, {Key: "A-4", Fields: IssueFields{Status: done}}}, at(10).Time)
+	if len(issues) != 3 || issues[0].Key != "A-1" || issues[1].Key != "A-2" || issues[2].Key != "A-4" {
+		t.Fatalf("expected A-3, created since, left out; got %d issues", len(issues))
+	}
+
+	a1 := issues[0]
+	if _, ok := a1.CompletedTime(); ok || a1.Fields.Status.Name != "In Progress" || a1.Fields.Resolution != nil {
+		t.Errorf("expected A-1 in progress and unresolved, got %+v", a1.Fields.Status)
+	}
+	if a1.Fields.Status.StatusCategory.Key != "indeterminate" || a1.Fields.Assignee.UserName != "alice" {
+		t.Errorf("expected A-1 in progress with alice, got %+v %+v", a1.Fields.Status, a1.Fields.Assignee)
+	}
+	if len(a1.Changelog.Histories) != 1 || a1.Changelog.Total != 1 || a1.ChangelogTruncated() {
+		t.Errorf("expected the later change dropped, got %+v", a1.Changelog)
+	}
+
+	a2 := issues[1]
+	if completed, ok := a2.CompletedTime(); !ok || !completed.Equal(at(5).Time) {
+		t.Errorf("expected A-2 done on March 5, got %v %v (%+v)", completed, ok, a2.Fields.Status)
+	}
+}
+
+func TestCachedGet_FetchTimeIgnoresAsOf(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	asOf = time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	fetched = fetchStats{}
+	defer func() { asOf, fetched = time.Time{}, fetchStats{} }()
+
+	body, err := cachedGet("serverInfo.json", srv.URL+"/rest/api/2/serverInfo", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	body.Close()
+	if fetched.Newest.Equal(asOf) || time.Since(fetched.Newest) > time.Minute {
+		t.Fatalf("expected the fetch stamped with the time it was made, got %v", fetched.Newest)
+	}
+}
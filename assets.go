@@ -0,0 +1,18 @@
+package main
+
+import (
+	"embed"
+	"html/template"
+)
+
+// assets are the files HTML reports and server mode render from, compiled in
+// so a release is a single binary:
+//
+//go:embed assets
+var assets embed.FS
+
+// assetTemplate parses an embedded HTML template, panicking at startup if it
+// doesn't parse like template.Must:
+func assetTemplate(name string, funcs template.FuncMap) *template.Template {
+	return template.Must(template.New(name).Funcs(funcs).ParseFS(assets, "assets/"+name))
+}
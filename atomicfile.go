@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	// lockTimeout is how long to wait for another run holding a lock:
+	lockTimeout = 10 * time.Second
+	// lockStale is the age after which a lock is assumed left behind by a
+	// run that died:
+	lockStale = time.Minute
+	lockPoll  = 20 * time.Millisecond
+
+	writeRetries = 3
+)
+
+// lockFile takes an exclusive lock on filename by creating filename.lock,
+// which works the same on every platform; call unlock when done.
+func lockFile(filename string) (unlock func(), err error) {
+	lockname := filename + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(lockname, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.WriteString(strconv.Itoa(os.Getpid()))
+			f.Close()
+			return func() { os.Remove(lockname) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if stat, statErr := os.Stat(lockname); statErr == nil && time.Since(stat.ModTime()) > lockStale {
+			os.Remove(lockname)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s: locked by another run", filename)
+		}
+		time.Sleep(lockPoll)
+	}
+}
+
+// writeFileAtomic replaces filename with b so readers see either the old or
+// the new contents, never a partial write. Renames are retried since virus
+// scanners and indexers briefly hold files open on Windows.
+func writeFileAtomic(filename string, b []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(b)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), perm)
+	}
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err = os.Rename(tmp.Name(), filename)
+		if err == nil || attempt == writeRetries {
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * 50 * time.Millisecond)
+	}
+}
+
+// writeFileLocked writes filename atomically while holding its lock, so
+// concurrent runs don't interleave writes of the same file:
+func writeFileLocked(filename string, b []byte, perm os.FileMode) error {
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeFileAtomic(filename, b, perm)
+}
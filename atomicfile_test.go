@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWriteFileLocked_ConcurrentWritersNeverInterleave(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "board.1.json")
+	contents := [][]byte{bytes.Repeat([]byte("a"), 1<<16), bytes.Repeat([]byte("b"), 1<<16)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(b []byte) {
+			defer wg.Done()
+			if err := writeFileLocked(filename, b, 0600); err != nil {
+				t.Error(err)
+			}
+		}(contents[i%2])
+	}
+	wg.Wait()
+
+	b, _ := ioutil.ReadFile(filename)
+	if !bytes.Equal(b, contents[0]) && !bytes.Equal(b, contents[1]) {
+		t.Fatalf("expected one writer's complete contents, got %d mixed bytes", len(b))
+	}
+	if leftovers, _ := filepath.Glob(filename + ".*"); len(leftovers) != 0 {
+		t.Fatalf("expected no temp or lock files left, got %v", leftovers)
+	}
+}
+
+func TestLockFile_BreaksStaleLock(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "jira-forecasts.jsonl")
+	ioutil.WriteFile(filename+".lock", []byte("1"), 0600)
+	old := time.Now().Add(-2 * lockStale)
+	os.Chtimes(filename+".lock", old, old)
+
+	unlock, err := lockFile(filename)
+	if err != nil {
+		t.Fatalf("expected stale lock to be broken, got %v", err)
+	}
+	unlock()
+	if _, err := os.Stat(filename + ".lock"); !os.IsNotExist(err) {
+		t.Fatalf("expected lock removed on unlock")
+	}
+}
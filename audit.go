@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultAuditLog is where write-back actions are recorded, without AuditLog:
+const defaultAuditLog = "jira-audit.jsonl"
+
+// Audited actions:
+const (
+	auditComment    = "comment"
+	auditLabel      = "label"
+	auditTransition = "transition"
+	auditUnlabel    = "unlabel"
+)
+
+// auditEntry is one line of the audit log: a write to JIRA, made or failed.
+type auditEntry struct {
+	Time   time.Time `json:"time"`
+	User   string    `json:"user,omitempty"`
+	Action string    `json:"action"`
+	Key    string    `json:"key"`
+	// Rule is the badge rule that called for the action, or "nudge":
+	Rule       string `json:"rule,omitempty"`
+	Label      string `json:"label,omitempty"`
+	Transition string `json:"transition,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+func (e auditEntry) String() string {
+	var what string
+	switch e.Action {
+	case auditComment:
+		what = "commented"
+	case auditLabel:
+		what = "added label " + e.Label
+	case auditUnlabel:
+		what = "removed label " + e.Label
+	case auditTransition:
+		what = fmt.Sprintf("transition '%s'", e.Transition)
+	default:
+		what = e.Action
+	}
+	s := fmt.Sprintf("%s %-10s %s", displayTime(e.Time).Format("2006-01-02 15:04"), e.Key, what)
+	if e.Rule != "" {
+		s += " (" + e.Rule + ")"
+	}
+	if e.User != "" {
+		s += " by " + e.User
+	}
+	if e.Error != "" {
+		s += ": failed: " + e.Error
+	}
+	return s
+}
+
+func auditLogFilename() string {
+	if config.AuditLog != "" {
+		return config.AuditLog
+	}
+	return defaultAuditLog
+}
+
+// audit records a write to JIRA and its outcome in the audit log, passing the
+// write's error through. The write is made already, so a log that can't be
+// appended to is a warning rather than a failure.
+func audit(entry auditEntry, err error) error {
+	entry.Time = time.Now()
+	entry.User = os.Getenv("JIRA_USERNAME")
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if rerendering {
+		return err
+	}
+	if logErr := appendAudit(auditLogFilename(), entry); logErr != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s %s not recorded in the audit log: %v\n", entry.Key, entry.Action, logErr)
+	}
+	return err
+}
+
+func appendAudit(filename string, entry auditEntry) error {
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if b, err = encryptLine(b); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadAudit reads the audit log, oldest first; a missing log is empty.
+func loadAudit(filename string) ([]auditEntry, error) {
+	b, err := readBeforeWrites(filename, ioutil.ReadFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []auditEntry
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		b, err := decryptLine([]byte(line))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		var e auditEntry
+		if err := json.Unmarshal(b, &e); err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// auditFilter selects audit entries by time, issue and rule:
+type auditFilter struct {
+	Since time.Time
+	Key   string
+	Rule  string
+}
+
+func (f auditFilter) match(e auditEntry) bool {
+	return !e.Time.Before(f.Since) &&
+		(f.Key == "" || strings.EqualFold(e.Key, f.Key)) &&
+		(f.Rule == "" || e.Rule == f.Rule)
+}
+
+// labelsToRevert are the labels added by entries the filter selects and not
+// since removed, oldest first, one per issue and label:
+func labelsToRevert(entries []auditEntry, f auditFilter) []auditEntry {
+	type added struct{ key, label string }
+	pending := make(map[added]int)
+	var labels []auditEntry
+	for _, e := range entries {
+		if e.Error != "" {
+			continue
+		}
+		a := added{e.Key, e.Label}
+		switch e.Action {
+		case auditLabel:
+			if _, ok := pending[a]; !ok && f.match(e) {
+				pending[a] = len(labels)
+				labels = append(labels, e)
+			}
+		case auditUnlabel:
+			if i, ok := pending[a]; ok {
+				labels[i].Action = ""
+				delete(pending, a)
+			}
+		}
+	}
+	var revert []auditEntry
+	for _, e := range labels {
+		if e.Action == auditLabel {
+			revert = append(revert, e)
+		}
+	}
+	return revert
+}
+
+// parseAuditFilter adds the flags selecting audit entries to a flag set:
+func parseAuditFilter(fs *flag.FlagSet, args []string) (auditFilter, error) {
+	since := fs.String("since", "", "only entries since this date, e.g. 2024-05-01")
+	key := fs.String("key", "", "only entries for this issue")
+	rule := fs.String("rule", "", "only entries for this rule, e.g. breach or nudge")
+	if err := fs.Parse(args); err != nil {
+		return auditFilter{}, err
+	}
+	f := auditFilter{Key: *key, Rule: *rule}
+	if *since != "" {
+		t, err := time.ParseInLocation("2006-01-02", *since, displayTime(reportNow()).Location())
+		if err != nil {
+			return f, fmt.Errorf("-since: %v", err)
+		}
+		f.Since = t
+	}
+	return f, nil
+}
+
+var auditSubcommands = map[string]func(args []string) error{
+	"list":          runAuditList,
+	"revert-labels": runAuditRevertLabels,
+}
+
+func runAudit(args []string) error {
+	return runSubcommand("audit", auditSubcommands, args)
+}
+
+func runAuditList(args []string) error {
+	f, err := parseAuditFilter(flag.NewFlagSet("audit list", flag.ContinueOnError), args)
+	if err != nil {
+		return err
+	}
+	entries, err := loadAudit(auditLogFilename())
+	if err != nil {
+		return err
+	}
+	shown := 0
+	for _, e := range entries {
+		if f.match(e) {
+			fmt.Println(e)
+			shown++
+		}
+	}
+	if shown == 0 {
+		fmt.Printf("No write-back actions recorded in %s.\n", auditLogFilename())
+	}
+	return nil
+}
+
+func runAuditRevertLabels(args []string) error {
+	fs := flag.NewFlagSet("audit revert-labels", flag.ContinueOnError)
+	post := fs.Bool("post", false, "remove the labels; without it, only preview them")
+	f, err := parseAuditFilter(fs, args)
+	if err != nil {
+		return err
+	}
+	entries, err := loadAudit(auditLogFilename())
+	if err != nil {
+		return err
+	}
+	revert := labelsToRevert(entries, f)
+	if len(revert) == 0 {
+		fmt.Printf("No labels to revert.\n")
+		return nil
+	}
+	if !*post {
+		fmt.Printf("Dry run; %s would be removed (rerun with -post to send):\n", plural(len(revert), "label"))
+	}
+
+	cl := httpClient()
+	var failures []string
+	for _, e := range revert {
+		if !*post {
+			fmt.Printf("  %s: remove label %s\n", e.Key, e.Label)
+			continue
+		}
+		undo := auditEntry{Action: auditUnlabel, Key: e.Key, Rule: e.Rule, Label: e.Label}
+		if err := audit(undo, removeLabel(cl, e.Key, e.Label)); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", e.Key, err))
+			continue
+		}
+		fmt.Printf("  %s: removed label %s\n", e.Key, e.Label)
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("audit revert-labels: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLabelsToRevert(t *testing.T) {
+	at := func(day int) time.Time { return time.Date(2024, 5, day, 9, 0, 0, 0, time.UTC) }
+	entries := []auditEntry{
+		{Time: at(1), Action: auditLabel, Key: "A-1", Rule: "breach", Label: "flow-risk"},
+		{Time: at(1), Action: auditLabel, Key: "A-2", Rule: "breach", Label: "flow-risk", Error: "403 Forbidden"},
+		{Time: at(2), Action: auditTransition, Key: "A-3", Rule: "stale", Transition: "Back to To Do"},
+		{Time: at(3), Action: auditLabel, Key: "A-4", Rule: "blocked", Label: "blocked"},
+		{Time: at(4), Action: auditUnlabel, Key: "A-4", Rule: "blocked", Label: "blocked"},
+		{Time: at(5), Action: auditLabel, Key: "A-5", Rule: "breach", Label: "flow-risk"},
+	}
+
+	var keys []string
+	for _, e := range labelsToRevert(entries, auditFilter{}) {
+		keys = append(keys, e.Key)
+	}
+	if strings.Join(keys, ",") != "A-1,A-5" {
+		t.Fatalf("expected A-1 and A-5 to revert, got %v", keys)
+	}
+
+	revert := labelsToRevert(entries, auditFilter{Since: at(2)})
+	if len(revert) != 1 || revert[0].Key != "A-5" {
+		t.Fatalf("expected only A-5 since May 2, got %+v", revert)
+	}
+	if revert := labelsToRevert(entries, auditFilter{Rule: "blocked"}); len(revert) != 0 {
+		t.Fatalf("expected the removed blocked label not to revert, got %+v", revert)
+	}
+}
+
+func TestAuditRevertLabels(t *testing.T) {
+	var mu sync.Mutex
+	var updates []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Update struct {
+				Labels []map[string]string `json:"labels"`
+			} `json:"update"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		for _, l := range body.Update.Labels {
+			for op, label := range l {
+				updates = append(updates, r.URL.Path+" "+op+" "+label)
+			}
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+
+	change := writeBackChange{Key: "ABC-1", Rule: "breach", Label: "flow-risk"}
+	if err := change.apply(srv.Client()); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := loadAudit(defaultAuditLog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Action != auditLabel || entries[0].Key != "ABC-1" || entries[0].Rule != "breach" {
+		t.Fatalf("expected the label addition logged, got %+v", entries)
+	}
+
+	// A dry run changes nothing:
+	captureStdout(t, func() error { return runAuditRevertLabels(nil) })
+	if len(updates) != 1 {
+		t.Fatalf("expected no removal without -post, got %v", updates)
+	}
+
+	out := captureStdout(t, func() error { return runAuditRevertLabels([]string{"-post"}) })
+	if !strings.Contains(string(out), "removed label flow-risk") {
+		t.Fatalf("expected the removal reported, got %q", out)
+	}
+	if len(updates) != 2 || updates[1] != "/rest/api/2/issue/ABC-1 remove flow-risk" {
+		t.Fatalf("expected flow-risk removed from ABC-1, got %v", updates)
+	}
+
+	// Reverted labels aren't reverted again:
+	captureStdout(t, func() error { return runAuditRevertLabels([]string{"-post"}) })
+	if len(updates) != 2 {
+		t.Fatalf("expected nothing more to revert, got %v", updates)
+	}
+	if entries, _ = loadAudit(defaultAuditLog); len(entries) != 2 || entries[1].Action != auditUnlabel {
+		t.Fatalf("expected the removal logged, got %+v", entries)
+	}
+}
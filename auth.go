@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuthProvider authenticates the requests made to JIRA. Providers are picked
+// by name with JIRA_AUTH (or a profile's auth), "basic" by default; a gateway
+// with a scheme of its own can use the command provider, or register a
+// provider with registerAuthProvider from the init func of a file added to
+// the build.
+//
+// Providers read their settings from s, which are a merged profile's own
+// rather than the environment's while its issues are fetched.
+type AuthProvider interface {
+	Authenticate(req *http.Request, s settings) error
+}
+
+// TLSAuthProvider authenticates the connection itself, as client
+// certificates do, when the API client is created:
+type TLSAuthProvider interface {
+	AuthProvider
+	ConfigureTLS(c *tls.Config, s settings) error
+}
+
+var authProviders = map[string]AuthProvider{
+	"basic":   basicAuth{},
+	"token":   tokenAuth{},
+	"oauth":   &oauthAuth{tokens: make(map[string]*oauthToken)},
+	"cookie":  cookieAuth{},
+	"mtls":    mtlsAuth{},
+	"command": &commandAuth{headers: make(map[string]*commandHeaders)},
+}
+
+func registerAuthProvider(name string, provider AuthProvider) {
+	authProviders[name] = provider
+}
+
+// authProvider returns the provider s selects:
+func authProvider(s settings) (AuthProvider, error) {
+	name := s.get("JIRA_AUTH")
+	if name == "" {
+		name = "basic"
+	}
+	provider, ok := authProviders[name]
+	if !ok {
+		var names []string
+		for name := range authProviders {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown JIRA_AUTH '%s'; expected %s", name, strings.Join(names, ", "))
+	}
+	return provider, nil
+}
+
+// authenticate applies the provider selected for cl's requests to a request
+// to JIRA. When the provider couldn't set up the client's TLS, every request
+// fails rather than being sent unauthenticated.
+func authenticate(cl *http.Client, req *http.Request) error {
+	var s settings
+	if api, ok := cl.Transport.(*apiTransport); ok {
+		if api.tlsErr != nil {
+			return api.tlsErr
+		}
+		s = api.settings
+	}
+	provider, err := authProvider(s)
+	if err != nil {
+		return err
+	}
+	return provider.Authenticate(req, s)
+}
+
+// basicAuth sends JIRA_USERNAME and JIRA_PASSWORD, an API token on Cloud:
+type basicAuth struct{}
+
+func (basicAuth) Authenticate(req *http.Request, s settings) error {
+	req.SetBasicAuth(s.get("JIRA_USERNAME"), s.get("JIRA_PASSWORD"))
+	return nil
+}
+
+// tokenAuth sends a bearer token, such as a Data Center personal access
+// token: JIRA_TOKEN, else JIRA_PASSWORD.
+type tokenAuth struct{}
+
+func (tokenAuth) Authenticate(req *http.Request, s settings) error {
+	token := s.get("JIRA_TOKEN")
+	if token == "" {
+		token = s.get("JIRA_PASSWORD")
+	}
+	if token == "" {
+		return fmt.Errorf("JIRA_AUTH=token: set JIRA_TOKEN")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// cookieAuth sends the session cookies in JIRA_COOKIE, e.g. copied from a
+// browser signed in through SSO:
+type cookieAuth struct{}
+
+func (cookieAuth) Authenticate(req *http.Request, s settings) error {
+	cookie := s.get("JIRA_COOKIE")
+	if cookie == "" {
+		return fmt.Errorf("JIRA_AUTH=cookie: set JIRA_COOKIE to the session cookies, e.g. JSESSIONID=...")
+	}
+	req.Header.Set("Cookie", cookie)
+	return nil
+}
+
+// mtlsAuth presents the client certificate in JIRA_CLIENT_CERT, with its key
+// in JIRA_CLIENT_KEY, sending basic credentials too if JIRA_USERNAME is set.
+type mtlsAuth struct{}
+
+func (mtlsAuth) ConfigureTLS(c *tls.Config, s settings) error {
+	cert, err := tls.LoadX509KeyPair(s.get("JIRA_CLIENT_CERT"), s.get("JIRA_CLIENT_KEY"))
+	if err != nil {
+		return fmt.Errorf("JIRA_AUTH=mtls: JIRA_CLIENT_CERT and JIRA_CLIENT_KEY: %v", err)
+	}
+	c.Certificates = append(c.Certificates, cert)
+	return nil
+}
+
+func (mtlsAuth) Authenticate(req *http.Request, s settings) error {
+	if s.get("JIRA_USERNAME") != "" {
+		return basicAuth{}.Authenticate(req, s)
+	}
+	return nil
+}
+
+// oauthToken is an access token and when it's due for renewal:
+type oauthToken struct {
+	AccessToken string
+	Renew       time.Time
+}
+
+// oauthAuth sends OAuth 2.0 access tokens from the client credentials grant:
+// JIRA_OAUTH_CLIENT_ID and JIRA_OAUTH_CLIENT_SECRET exchanged at
+// JIRA_OAUTH_TOKEN_URL, for JIRA_OAUTH_SCOPE if set. Tokens are reused until
+// shortly before they expire.
+type oauthAuth struct {
+	mu     sync.Mutex
+	tokens map[string]*oauthToken
+}
+
+func (a *oauthAuth) Authenticate(req *http.Request, s settings) error {
+	tokenURL, clientId := s.get("JIRA_OAUTH_TOKEN_URL"), s.get("JIRA_OAUTH_CLIENT_ID")
+	if tokenURL == "" || clientId == "" {
+		return fmt.Errorf("JIRA_AUTH=oauth: set JIRA_OAUTH_TOKEN_URL, JIRA_OAUTH_CLIENT_ID and JIRA_OAUTH_CLIENT_SECRET")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := tokenURL + " " + clientId
+	token := a.tokens[key]
+	if token == nil || time.Now().After(token.Renew) {
+		form := url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {clientId},
+			"client_secret": {s.get("JIRA_OAUTH_CLIENT_SECRET")},
+		}
+		if scope := s.get("JIRA_OAUTH_SCOPE"); scope != "" {
+			form.Set("scope", scope)
+		}
+		var rsp struct {
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int    `json:"expires_in"`
+		}
+		if err := postForm(httpClient(), tokenURL, form, &rsp); err != nil {
+			return fmt.Errorf("JIRA_AUTH=oauth: %v", err)
+		}
+		if rsp.AccessToken == "" {
+			return fmt.Errorf("JIRA_AUTH=oauth: %s gave no access_token", tokenURL)
+		}
+		expires := time.Duration(rsp.ExpiresIn) * time.Second
+		if expires <= 0 {
+			expires = time.Hour
+		}
+		token = &oauthToken{AccessToken: rsp.AccessToken, Renew: time.Now().Add(expires * 9 / 10)}
+		a.tokens[key] = token
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	return nil
+}
+
+// postForm posts a form, decoding the JSON response:
+func postForm(cl *http.Client, url string, form url.Values, v interface{}) error {
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rsp, err := cl.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode >= 300 {
+		return newAPIError(req, rsp)
+	}
+	return decodeResponse(rsp.Body, url, v)
+}
+
+// authCommandTTL is how long a command's headers are reused before it's run
+// again, for long-running server mode:
+const authCommandTTL = 10 * time.Minute
+
+type commandHeaders struct {
+	Header http.Header
+	Ran    time.Time
+}
+
+// commandAuth runs JIRA_AUTH_COMMAND for the headers to send, one "Name:
+// value" per line, so any SSO gateway's helper can sign requests in.
+type commandAuth struct {
+	mu      sync.Mutex
+	headers map[string]*commandHeaders
+}
+
+func (a *commandAuth) Authenticate(req *http.Request, s settings) error {
+	command := s.get("JIRA_AUTH_COMMAND")
+	if command == "" {
+		return fmt.Errorf("JIRA_AUTH=command: set JIRA_AUTH_COMMAND")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	h := a.headers[command]
+	if h == nil || time.Since(h.Ran) > authCommandTTL {
+		out, err := exec.Command("sh", "-c", command).Output()
+		if err != nil {
+			return fmt.Errorf("JIRA_AUTH_COMMAND: %v", err)
+		}
+		header, err := parseAuthHeaders(out)
+		if err != nil {
+			return fmt.Errorf("JIRA_AUTH_COMMAND: %v", err)
+		}
+		h = &commandHeaders{Header: header, Ran: time.Now()}
+		a.headers[command] = h
+	}
+	for name, values := range h.Header {
+		req.Header[name] = values
+	}
+	return nil
+}
+
+func parseAuthHeaders(out []byte) (http.Header, error) {
+	header := make(http.Header)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		i := strings.Index(line, ":")
+		if i <= 0 {
+			// The line isn't repeated, since it may well be a secret:
+			return nil, fmt.Errorf("expected 'Name: value' header lines")
+		}
+		header.Add(strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]))
+	}
+	if len(header) == 0 {
+		return nil, fmt.Errorf("printed no headers")
+	}
+	return header, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAuthProviders(t *testing.T) {
+	for key, value := range map[string]string{
+		"JIRA_USERNAME":     "svc",
+		"JIRA_PASSWORD":     "secret",
+		"JIRA_COOKIE":       "JSESSIONID=abc",
+		"JIRA_AUTH_COMMAND": "printf 'X-Gateway-Token: t1\\nX-Gateway-User: svc\\n'",
+	} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+	defer os.Unsetenv("JIRA_AUTH")
+
+	for _, c := range []struct {
+		auth, header, expected string
+	}{
+		{"", "Authorization", "Basic c3ZjOnNlY3JldA=="},
+		{"token", "Authorization", "Bearer secret"},
+		{"cookie", "Cookie", "JSESSIONID=abc"},
+		{"command", "X-Gateway-Token", "t1"},
+	} {
+		os.Setenv("JIRA_AUTH", c.auth)
+		req, _ := http.NewRequest(http.MethodGet, "https://jira.example.com/rest/api/2/myself", nil)
+		if err := authenticate(httpClient(), req); err != nil {
+			t.Fatalf("JIRA_AUTH=%s: %v", c.auth, err)
+		}
+		if got := req.Header.Get(c.header); got != c.expected {
+			t.Errorf("JIRA_AUTH=%s: expected %s %q, got %q", c.auth, c.header, c.expected, got)
+		}
+	}
+
+	os.Setenv("JIRA_AUTH", "kerberos")
+	req, _ := http.NewRequest(http.MethodGet, "https://jira.example.com/", nil)
+	if err := authenticate(httpClient(), req); err == nil || !strings.Contains(err.Error(), "expected basic, command, cookie") {
+		t.Fatalf("expected an unknown provider to fail listing the known ones, got %v", err)
+	}
+
+	os.Setenv("JIRA_CLIENT_CERT", "missing.pem")
+	defer os.Unsetenv("JIRA_CLIENT_CERT")
+	if err := (mtlsAuth{}).ConfigureTLS(&tls.Config{}, nil); err == nil {
+		t.Fatalf("expected a missing client certificate to fail")
+	}
+}
+
+func TestOAuthAuth_ReusesToken(t *testing.T) {
+	issued := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("client_secret") != "s3" {
+			http.Error(w, "bad client", http.StatusUnauthorized)
+			return
+		}
+		issued++
+		w.Write([]byte(`{"access_token": "at-1", "expires_in": 3600}`))
+	}))
+	defer srv.Close()
+
+	for key, value := range map[string]string{
+		"JIRA_OAUTH_TOKEN_URL":     srv.URL,
+		"JIRA_OAUTH_CLIENT_ID":     "reports",
+		"JIRA_OAUTH_CLIENT_SECRET": "s3",
+	} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	a := &oauthAuth{tokens: make(map[string]*oauthToken)}
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "https://jira.example.com/", nil)
+		if err := a.Authenticate(req, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got := req.Header.Get("Authorization"); got != "Bearer at-1" {
+			t.Fatalf("expected the access token sent, got %q", got)
+		}
+	}
+	if issued != 1 {
+		t.Fatalf("expected the token reused, got %d issued", issued)
+	}
+}
+
+func TestParseAuthHeaders(t *testing.T) {
+	if _, err := parseAuthHeaders([]byte("raw-token\n")); err == nil || strings.Contains(err.Error(), "raw-token") {
+		t.Fatalf("expected a line without a header name to fail without repeating it, got %v", err)
+	}
+	if _, err := parseAuthHeaders([]byte("\n")); err == nil {
+		t.Fatalf("expected no headers to fail")
+	}
+}
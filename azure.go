@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// azureSource reads work items from Azure DevOps Boards. JIRA_URL is the
+// project URL (https://dev.azure.com/org/project) and JIRA_PASSWORD a personal
+// access token. Queries are WIQL; JQL defaults of the reports are ignored.
+type azureSource struct{}
+
+const azureAPIVersion = "6.0"
+
+// azureDefaultWIQL selects every work item in the project:
+const azureDefaultWIQL = "SELECT [System.Id] FROM WorkItems WHERE [System.TeamProject] = @project ORDER BY [System.ChangedDate] DESC"
+
+type azureIdentity struct {
+	Id          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	UniqueName  string `json:"uniqueName"`
+}
+
+func (id *azureIdentity) user() User {
+	u := User{AccountId: id.Id, DisplayName: id.DisplayName}
+	if strings.Contains(id.UniqueName, "@") {
+		u.EmailAddress = id.UniqueName
+	} else {
+		u.UserName = id.UniqueName
+	}
+	return u
+}
+
+type azureWorkItem struct {
+	Id     int `json:"id"`
+	Fields struct {
+		TeamProject  string         `json:"System.TeamProject"`
+		WorkItemType string         `json:"System.WorkItemType"`
+		State        string         `json:"System.State"`
+		Title        string         `json:"System.Title"`
+		AssignedTo   *azureIdentity `json:"System.AssignedTo"`
+		CreatedDate  zonedTimestamp `json:"System.CreatedDate"`
+		ClosedDate   zonedTimestamp `json:"Microsoft.VSTS.Common.ClosedDate"`
+		Priority     int            `json:"Microsoft.VSTS.Common.Priority"`
+		Parent       int            `json:"System.Parent"`
+	} `json:"fields"`
+}
+
+type azureFieldChange struct {
+	OldValue json.RawMessage `json:"oldValue"`
+	NewValue json.RawMessage `json:"newValue"`
+}
+
+func rawString(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	return strings.Trim(string(raw), `"`)
+}
+
+type azureUpdate struct {
+	RevisedBy azureIdentity               `json:"revisedBy"`
+	Fields    map[string]azureFieldChange `json:"fields"`
+}
+
+// azureCategories maps Azure state categories onto JIRA status categories:
+var azureCategories = map[string]string{
+	"Proposed":   "new",
+	"InProgress": "indeterminate",
+	"Resolved":   "indeterminate",
+	"Completed":  "done",
+	"Removed":    "done",
+}
+
+func (azureSource) Describe(cl *http.Client) string {
+	return "Azure DevOps, REST API " + azureAPIVersion
+}
+
+func (azureSource) FetchIssues(cl *http.Client, boardId int, resource string, query string) ([]Issue, error) {
+	if !strings.HasPrefix(strings.ToUpper(strings.TrimSpace(query)), "SELECT") {
+		if query != "" {
+			log.Printf("azure: ignoring non-WIQL query: %s\n", query)
+		}
+		query = azureDefaultWIQL
+	}
+
+	ids, err := azureQuery(cl, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Work items are fetched in batches of at most 200:
+	var issues []Issue
+	states := make(map[string]map[string]string)
+	for start := 0; start < len(ids); start += 200 {
+		end := start + 200
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		items, err := azureWorkItems(cl, ids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		fetched.Pages++
+
+		for i := range items {
+			item := &items[i]
+			categories, ok := states[item.Fields.WorkItemType]
+			if !ok {
+				categories, err = azureStates(cl, item.Fields.WorkItemType)
+				if err != nil {
+					return nil, err
+				}
+				states[item.Fields.WorkItemType] = categories
+			}
+
+			updates, err := azureUpdates(cl, item.Id)
+			if err != nil {
+				return nil, err
+			}
+
+			issue := azureIssue(item, updates, categories)
+			if resource == "backlog" && issue.Fields.Status.StatusCategory.Key != "new" {
+				continue
+			}
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// azureIssue maps a work item and its revisions onto an issue:
+func azureIssue(item *azureWorkItem, updates []azureUpdate, categories map[string]string) Issue {
+	const prefix = "azure:"
+	for state, category := range categories {
+		registerStatusCategory(prefix+state, category)
+	}
+
+	f := &item.Fields
+	issue := Issue{
+		Id:  strconv.Itoa(item.Id),
+		Key: fmt.Sprintf("%s-%d", strings.ReplaceAll(f.TeamProject, " ", ""), item.Id),
+	}
+	issue.Fields.Summary = f.Title
+	issue.Fields.Status = externalStatus(prefix, f.State, categories[f.State])
+	issue.Fields.Created = f.CreatedDate
+	issue.Fields.ResolutionDate = f.ClosedDate
+	issue.Fields.Project = Project{Key: f.TeamProject, Name: f.TeamProject, Style: externalStyle}
+	issue.Fields.IssueType = IssueType{Name: f.WorkItemType}
+	if f.WorkItemType == "Epic" {
+		issue.Fields.IssueType.HierarchyLevel = 1
+	}
+	if f.AssignedTo != nil {
+		assignee := f.AssignedTo.user()
+		issue.Fields.Assignee = &assignee
+	}
+	if f.Priority != 0 {
+		issue.Fields.Priority = &Priority{Id: strconv.Itoa(f.Priority), Name: strconv.Itoa(f.Priority)}
+	}
+	if f.Parent != 0 {
+		issue.Fields.Parent = &ParentIssue{Key: fmt.Sprintf("%s-%d", strings.ReplaceAll(f.TeamProject, " ", ""), f.Parent)}
+	}
+
+	for _, update := range updates {
+		changed, ok := update.Fields["System.ChangedDate"]
+		if !ok {
+			continue
+		}
+		var at zonedTimestamp
+		at.UnmarshalJSON(changed.NewValue)
+
+		history := History{Author: update.RevisedBy.user(), Created: at}
+		if state, ok := update.Fields["System.State"]; ok {
+			history.Items = append(history.Items, statusChange(prefix, rawString(state.OldValue), rawString(state.NewValue)))
+		}
+		if points, ok := update.Fields["Microsoft.VSTS.Scheduling.StoryPoints"]; ok {
+			history.Items = append(history.Items, HistoryItem{
+				Field:      "Story Points",
+				FromString: rawString(points.OldValue),
+				ToString:   rawString(points.NewValue),
+			})
+		}
+		if len(history.Items) > 0 {
+			issue.Changelog.Histories = append(issue.Changelog.Histories, history)
+		}
+	}
+	issue.Changelog.Total = len(issue.Changelog.Histories)
+	return issue
+}
+
+func azureURL(cl *http.Client, path string, query string) string {
+	url := strings.TrimSuffix(setting(cl, "JIRA_URL"), "/") + "/_apis/" + path + "?api-version=" + azureAPIVersion
+	if query != "" {
+		url += "&" + query
+	}
+	return url
+}
+
+// azureQuery runs a WIQL query for work item IDs; queries are POSTed so they
+// are never cached, but count toward the API budget all the same.
+func azureQuery(cl *http.Client, wiql string) ([]int, error) {
+	body, err := json.Marshal(map[string]string{"query": wiql})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, azureURL(cl, "wit/wiql", ""), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(setting(cl, "JIRA_USERNAME"), setting(cl, "JIRA_PASSWORD"))
+
+	b, err := doAccounted(req, cl)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		WorkItems []struct {
+			Id int `json:"id"`
+		} `json:"workItems"`
+	}
+	if err = decodeResponse(bytes.NewReader(b), req.URL.String(), &result); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(result.WorkItems))
+	for _, item := range result.WorkItems {
+		ids = append(ids, item.Id)
+	}
+	return ids, nil
+}
+
+// azureHash keys cached responses by project URL as well, so merged projects
+// don't share them:
+func azureHash(cl *http.Client, s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(setting(cl, "JIRA_URL") + " " + s))
+	return h.Sum32()
+}
+
+func azureWorkItems(cl *http.Client, ids []int) ([]azureWorkItem, error) {
+	list := make([]string, 0, len(ids))
+	for _, id := range ids {
+		list = append(list, strconv.Itoa(id))
+	}
+	joined := strings.Join(list, ",")
+
+	url := azureURL(cl, "wit/workitems", "ids="+joined)
+	body, err := cachedGet(fmt.Sprintf("azure.items.%08x.json", azureHash(cl, joined)), url, cl)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var result struct {
+		Value []azureWorkItem `json:"value"`
+	}
+	err = decodeResponse(body, url, &result)
+	return result.Value, err
+}
+
+// azureUpdatesPage is the most revisions the updates API returns at once:
+const azureUpdatesPage = 200
+
+// azureUpdates pages through a work item's revisions until a short page:
+func azureUpdates(cl *http.Client, id int) ([]azureUpdate, error) {
+	hash := azureHash(cl, strconv.Itoa(id))
+	var updates []azureUpdate
+	for skip := 0; ; skip += azureUpdatesPage {
+		url := azureURL(cl, fmt.Sprintf("wit/workItems/%d/updates", id), fmt.Sprintf("$top=%d&$skip=%d", azureUpdatesPage, skip))
+		body, err := cachedGet(fmt.Sprintf("azure.item.%d.%08x.updates.%d.json", id, hash, skip), url, cl)
+		if err != nil {
+			return nil, err
+		}
+
+		var result struct {
+			Value []azureUpdate `json:"value"`
+		}
+		err = decodeResponse(body, url, &result)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		updates = append(updates, result.Value...)
+		if len(result.Value) < azureUpdatesPage {
+			return updates, nil
+		}
+	}
+}
+
+// azureStates maps each state of a work item type to its status category:
+func azureStates(cl *http.Client, workItemType string) (map[string]string, error) {
+	url := azureURL(cl, "wit/workitemtypes/"+strings.ReplaceAll(workItemType, " ", "%20")+"/states", "")
+	body, err := cachedGet(fmt.Sprintf("azure.states.%08x.%s.json", azureHash(cl, workItemType), strings.ReplaceAll(workItemType, " ", "_")), url, cl)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var result struct {
+		Value []struct {
+			Name     string `json:"name"`
+			Category string `json:"category"`
+		} `json:"value"`
+	}
+	if err = decodeResponse(body, url, &result); err != nil {
+		return nil, err
+	}
+
+	categories := make(map[string]string, len(result.Value))
+	for _, state := range result.Value {
+		categories[state.Name] = azureCategories[state.Category]
+	}
+	return categories, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func azureServer(t *testing.T) *httptest.Server {
+	responses := map[string]string{
+		"/_apis/wit/wiql": `{"workItems": [{"id": 7}, {"id": 8}]}`,
+		"/_apis/wit/workitems": `{"value": [
+			{"id": 7, "fields": {"System.TeamProject": "Fabrikam", "System.WorkItemType": "Bug", "System.State": "Closed", "System.Title": "Crash on save",
+				"System.AssignedTo": {"id": "a1", "displayName": "Alice", "uniqueName": "alice@example.com"},
+				"System.CreatedDate": "2018-10-29T09:00:00Z", "Microsoft.VSTS.Common.ClosedDate": "2018-11-05T09:00:00Z", "Microsoft.VSTS.Common.Priority": 1}},
+			{"id": 8, "fields": {"System.TeamProject": "Fabrikam", "System.WorkItemType": "Bug", "System.State": "New", "System.Title": "Typo",
+				"System.CreatedDate": "2018-11-01T09:00:00Z"}}
+		]}`,
+		"/_apis/wit/workitemtypes/Bug/states": `{"value": [
+			{"name": "New", "category": "Proposed"}, {"name": "Active", "category": "InProgress"}, {"name": "Closed", "category": "Completed"}
+		]}`,
+		"/_apis/wit/workItems/7/updates": `{"value": [
+			{"revisedBy": {"id": "a1", "displayName": "Alice", "uniqueName": "alice@example.com"},
+				"fields": {"System.State": {"newValue": "New"}, "System.ChangedDate": {"newValue": "2018-10-29T09:00:00Z"}}},
+			{"revisedBy": {"id": "a1", "displayName": "Alice", "uniqueName": "alice@example.com"},
+				"fields": {"System.State": {"oldValue": "New", "newValue": "Active"}, "System.ChangedDate": {"newValue": "2018-10-31T09:00:00Z"}}},
+			{"revisedBy": {"id": "a1", "displayName": "Alice", "uniqueName": "alice@example.com"},
+				"fields": {"System.State": {"oldValue": "Active", "newValue": "Closed"}, "System.ChangedDate": {"newValue": "2018-11-05T09:00:00Z"}}}
+		]}`,
+		"/_apis/wit/workItems/8/updates": `{"value": []}`,
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+}
+
+func TestAzureSource_FetchIssues(t *testing.T) {
+	srv := azureServer(t)
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+	defer func() { statusCategories = nil }()
+
+	issues, err := azureSource{}.FetchIssues(srv.Client(), 0, "issue", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d", len(issues))
+	}
+
+	closed := &issues[0]
+	if closed.Key != "Fabrikam-7" || !closed.IsBug() || closed.Fields.Assignee.EmailAddress != "alice@example.com" {
+		t.Fatalf("expected Fabrikam-7 bug assigned to alice, got %+v", closed.Fields)
+	}
+	if days, ok := closed.CycleTime(); !ok || days != 3 {
+		t.Fatalf("expected cycle time of 3 days from Active to Closed, got %d %v", days, ok)
+	}
+
+	backlog, err := azureSource{}.FetchIssues(srv.Client(), 0, "backlog", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backlog) != 1 || !strings.HasSuffix(backlog[0].Key, "-8") {
+		t.Fatalf("expected only the New item in the backlog, got %v", backlog)
+	}
+}
+
+func TestAzureSource_PagesUpdates(t *testing.T) {
+	srv := azureServer(t)
+	defer srv.Close()
+
+	// Item 7 starts in its first update and closes in its 201st, on the
+	// second page:
+	var skips []string
+	fixtures := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_apis/wit/workItems/7/updates" {
+			fixtures.ServeHTTP(w, r)
+			return
+		}
+		skips = append(skips, r.URL.Query().Get("$skip"))
+		var updates []string
+		for i := 0; i < 201; i++ {
+			state, changed := `{"oldValue": "Active", "newValue": "Active"}`, "2018-10-31T09:00:00Z"
+			switch i {
+			case 0:
+				state = `{"oldValue": "New", "newValue": "Active"}`
+			case 200:
+				state, changed = `{"oldValue": "Active", "newValue": "Closed"}`, "2018-11-05T09:00:00Z"
+			}
+			updates = append(updates, fmt.Sprintf(`{"fields": {"System.State": %s, "System.ChangedDate": {"newValue": %q}}}`, state, changed))
+		}
+		skip, _ := strconv.Atoi(r.URL.Query().Get("$skip"))
+		top, _ := strconv.Atoi(r.URL.Query().Get("$top"))
+		end := skip + top
+		if end > len(updates) {
+			end = len(updates)
+		}
+		fmt.Fprintf(w, `{"value": [%s]}`, strings.Join(updates[skip:end], ","))
+	})
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+	defer func() { statusCategories = nil }()
+
+	issues, err := azureSource{}.FetchIssues(srv.Client(), 0, "issue", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skips) != 2 || skips[0] != "0" || skips[1] != "200" {
+		t.Fatalf("expected two pages of updates, got skips %v", skips)
+	}
+	if days, ok := issues[0].CycleTime(); !ok || days != 3 {
+		t.Fatalf("expected the close on the second page to end the cycle time, got %d %v", days, ok)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+)
+
+// fetchBacklogIssues lists issues in the board's backlog rather than on the
+// board itself:
+func fetchBacklogIssues(cl *http.Client, boardId int, jql string) ([]Issue, error) {
+	return fetchPagedIssues(cl, boardId, "backlog", jql)
+}
+
+// excludeBacklog drops issues that sit in the board's backlog so they don't
+// count towards work in progress. Boards without a backlog keep all issues.
+func excludeBacklog(cl *http.Client, boardId int, issues []Issue) []Issue {
+	backlog, err := fetchBacklogIssues(cl, boardId, "")
+	if err != nil {
+		log.Printf("backlog: %v\n", err)
+		return issues
+	}
+
+	inBacklog := make(map[string]bool, len(backlog))
+	for _, issue := range backlog {
+		inBacklog[issue.Key] = true
+	}
+
+	onBoard := make([]Issue, 0, len(issues))
+	for _, issue := range issues {
+		if !inBacklog[issue.Key] {
+			onBoard = append(onBoard, issue)
+		}
+	}
+	return onBoard
+}
+
+func runBacklog(args []string) error {
+	fs := flag.NewFlagSet("backlog", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter applied to backlog issues")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	backlog, err := fetchBacklogIssues(httpClient(), boardArg(fs.Args()), reportJQL("backlog", *jql, ""))
+	if err != nil {
+		return err
+	}
+
+	now := reportNow()
+	today := DateOf(now)
+
+	// Backlog items age from creation:
+	byStatus := make(map[string][]*Issue)
+	ages := make([]int, 0, len(backlog))
+	for i := range backlog {
+		issue := &backlog[i]
+		if issue.IsEpic() {
+			continue
+		}
+
+		issue.Status = issue.Fields.Status.Name
+		issue.StatusTime = issue.Fields.Created.Time
+		issue.StatusBusinessDays = businessDaysUntil(issue.StatusTime, today.Time)
+		byStatus[issue.Status] = append(byStatus[issue.Status], issue)
+		ages = append(ages, issue.StatusBusinessDays)
+	}
+
+	timeLayout := "Mon Jan 02 2006"
+	fmt.Printf("Backlog as of %s: %d issues\n", displayTime(now).Format(timeLayout), len(ages))
+	if len(ages) == 0 {
+		return nil
+	}
+
+	sorted := sortedCopy(ages)
+	fmt.Printf("Age in business days since created:")
+	for _, p := range percentiles {
+		fmt.Printf(" %s=%d", percentileLabel(p), percentile(sorted, p))
+	}
+	fmt.Printf(" max=%d\n", sorted[len(sorted)-1])
+
+	statuses := make([]string, 0, len(byStatus))
+	for status := range byStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	for _, status := range statuses {
+		statusIssues := IssueList(byStatus[status])
+		sort.Sort(statusIssues)
+
+		if reportVerbosity == execView {
+			fmt.Printf("%s: %s\n", status, plural(len(statusIssues), "issue"))
+			continue
+		}
+
+		fmt.Printf("%s: [\n", status)
+		shown := detailLines(len(statusIssues))
+		for _, issue := range statusIssues[:shown] {
+			fmt.Printf(
+				"  %s (%3d days old since %s); %s\n",
+				issue.Key,
+				issue.StatusBusinessDays,
+				displayTime(issue.StatusTime).Format(timeLayout),
+				issue.DisplaySummary(),
+			)
+		}
+		printOmitted("  ", shown, len(statusIssues))
+		fmt.Printf("]\n")
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+	"time"
+)
+
+// BadgeConfig controls the badges marking in-flight issues in aging and digest
+// output for quick scanning.
+type BadgeConfig struct {
+	// Off disables badges altogether:
+	Off bool `json:"off"`
+	// Rules maps a rule (breach, blocked, unassigned, stale or truncated) to
+	// its badge, "" to turn it off:
+	Rules map[string]string `json:"rules"`
+
+	// BlockedStatuses and FlaggedField (the custom field ID of Jira's
+	// "Flagged" impediment) mark an issue blocked, as does an unresolved
+	// "is blocked by" link:
+	BlockedStatuses []string `json:"blockedStatuses"`
+	FlaggedField    string   `json:"flaggedField"`
+	// StaleDays is how many business days without any change make an issue
+	// stale; default 10.
+	StaleDays int `json:"staleDays"`
+}
+
+// badgeRules are the rules in the order their badges are shown:
+var badgeRules = []string{"breach", "blocked", "unassigned", "stale", "truncated"}
+
+var defaultBadges = map[string]string{
+	"breach":     "🔥",
+	"blocked":    "⛔",
+	"unassigned": "👻",
+	"stale":      "🧊",
+	"truncated":  "✂️",
+}
+
+const defaultStaleDays = 10
+
+// lastChanged is when the issue last changed, or was created if never:
+func (issue *Issue) lastChanged() time.Time {
+	last := issue.Fields.Created.Time
+	for _, h := range issue.Changelog.Histories {
+		if h.Created.After(last) {
+			last = h.Created.Time
+		}
+	}
+	return last
+}
+
+// Blocked reports whether the issue is in a blocked status, flagged as an
+// impediment or linked as blocked by an issue that isn't done.
+func (issue *Issue) Blocked() bool {
+	for _, status := range config.Badges.BlockedStatuses {
+		if strings.EqualFold(status, issue.Fields.Status.Name) {
+			return true
+		}
+	}
+	if field := config.Badges.FlaggedField; field != "" && len(issue.Fields.Custom[field]) > 0 {
+		return true
+	}
+	for _, link := range issue.Fields.IssueLinks {
+		if link.InwardIssue != nil && strings.EqualFold(link.Type.Inward, "is blocked by") &&
+			link.InwardIssue.Fields.Status.StatusCategory.Key != "done" {
+			return true
+		}
+	}
+	return false
+}
+
+// issueRules returns the rules an in-flight issue matches, in badgeRules
+// order; done issues match none.
+func issueRules(issue *Issue, overSLA bool, now time.Time) []string {
+	if _, done := issue.CompletedTime(); done {
+		return nil
+	}
+
+	staleDays := config.Badges.StaleDays
+	if staleDays <= 0 {
+		staleDays = defaultStaleDays
+	}
+	matches := map[string]bool{
+		"breach":     overSLA,
+		"blocked":    issue.Blocked(),
+		"unassigned": issue.Fields.Assignee == nil,
+		"stale":      DateOf(issue.lastChanged()).BusinessDaysUntil(DateOf(now)) >= staleDays,
+		"truncated":  issue.ChangelogTruncated(),
+	}
+
+	var rules []string
+	for _, rule := range badgeRules {
+		if matches[rule] {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// issueBadges returns the badges of the rules an in-flight issue matches, or
+// "" for none.
+func issueBadges(issue *Issue, overSLA bool, now time.Time) string {
+	if config.Badges.Off {
+		return ""
+	}
+
+	var badges strings.Builder
+	for _, rule := range issueRules(issue, overSLA, now) {
+		badge, ok := config.Badges.Rules[rule]
+		if !ok {
+			badge = defaultBadges[rule]
+		}
+		badges.WriteString(badge)
+	}
+	return badges.String()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIssueBadges(t *testing.T) {
+	config = &Config{Badges: BadgeConfig{BlockedStatuses: []string{"Blocked"}}}
+	defer func() { config = &Config{} }()
+
+	now := time.Date(2018, 11, 30, 9, 0, 0, 0, cst)
+	started := func(at time.Time) *Issue {
+		issue := &Issue{Key: "A-1"}
+		issue.Fields.Assignee = &User{UserName: "alice"}
+		issue.Fields.Status.Name = inProgressStatus
+		issue.Changelog.Histories = []History{
+			{Created: zonedTimestamp{at}, Items: []HistoryItem{{Field: "status", ToString: inProgressStatus}}},
+		}
+		return issue
+	}
+
+	fresh := started(now.AddDate(0, 0, -1))
+	if badges := issueBadges(fresh, false, now); badges != "" {
+		t.Fatalf("expected no badges, got %q", badges)
+	}
+
+	stale := started(now.AddDate(0, 0, -20))
+	stale.Fields.Assignee = nil
+	if badges := issueBadges(stale, true, now); badges != "🔥👻🧊" {
+		t.Fatalf("expected breach, unassigned and stale, got %q", badges)
+	}
+
+	blocked := started(now.AddDate(0, 0, -1))
+	blocked.Fields.Status.Name = "Blocked"
+	if badges := issueBadges(blocked, false, now); badges != "⛔" {
+		t.Fatalf("expected blocked, got %q", badges)
+	}
+
+	linked := started(now.AddDate(0, 0, -1))
+	linked.Fields.IssueLinks = []IssueLink{
+		{Type: IssueLinkType{Name: "Blocks", Inward: "is blocked by", Outward: "blocks"}, InwardIssue: &ParentIssue{Key: "A-2"}},
+	}
+	if badges := issueBadges(linked, false, now); badges != "⛔" {
+		t.Fatalf("expected blocked by link, got %q", badges)
+	}
+
+	config.Badges.Rules = map[string]string{"stale": "", "unassigned": "[?]"}
+	if badges := issueBadges(stale, false, now); badges != "[?]" {
+		t.Fatalf("expected configured badges, got %q", badges)
+	}
+
+	done := completedIssue("A-3", now.AddDate(0, 0, -30), now.AddDate(0, 0, -20))
+	if badges := issueBadges(&done, true, now); badges != "" {
+		t.Fatalf("expected no badges on done issues, got %q", badges)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/JamesDunne/jira-analysis/analysis"
+)
+
+// printStageBenchmarks compares each status's in-flight items with the time
+// completed work spent there at the percentiles, e.g. the oldest 6 days in QA
+// against its p85 of 4 days, in the order the items are. Items are over when
+// older than the highest percentile.
+func printStageBenchmarks(items []AgingItem, benchmarks map[string]analysis.StageBenchmark, percentiles []float64) {
+	highest := percentiles[len(percentiles)-1]
+	overLabel := "over " + percentileLabel(highest)
+	overKey := percentileMetric("over", highest)
+
+	type row struct {
+		status, name  string
+		items, oldest int
+		over          int
+		benchmark     analysis.StageBenchmark
+		hasBenchmark  bool
+	}
+	var rows []*row
+	width := len("status")
+	for _, item := range items {
+		if len(rows) == 0 || rows[len(rows)-1].status != item.Status {
+			b, ok := benchmarks[item.Status]
+			rows = append(rows, &row{status: item.Status, name: item.Status, benchmark: b, hasBenchmark: ok})
+			if item.Stage != "" && item.Stage != item.Status {
+				rows[len(rows)-1].name += " (" + item.Stage + ")"
+			}
+			if w := displayWidth(rows[len(rows)-1].name); w > width {
+				width = w
+			}
+		}
+		r := rows[len(rows)-1]
+		r.items++
+		if item.Age > r.oldest {
+			r.oldest = item.Age
+		}
+		if r.hasBenchmark && item.Age > r.benchmark.Percentiles[highest] {
+			r.over++
+		}
+	}
+
+	fmt.Printf("\nBusiness days in status, in flight vs completed work:\n")
+	fmt.Printf("  %s %6s %6s", padRight("status", width), "items", "oldest")
+	for _, p := range percentiles {
+		fmt.Printf(" %6s", percentileLabel(p))
+	}
+	fmt.Printf(" %9s %9s\n", overLabel, "completed")
+	metrics := make(map[string]map[string]int)
+	for _, r := range rows {
+		fmt.Printf("  %s %6d %6d", padRight(r.name, width), r.items, r.oldest)
+		if !r.hasBenchmark {
+			fmt.Printf("%s %9s %9s\n", strings.Repeat(fmt.Sprintf(" %6s", "-"), len(percentiles)), "-", "-")
+			continue
+		}
+		m := map[string]int{"completed": r.benchmark.Count, overKey: r.over}
+		for _, p := range percentiles {
+			fmt.Printf(" %6d", r.benchmark.Percentiles[p])
+			m[percentileLabel(p)] = r.benchmark.Percentiles[p]
+		}
+		fmt.Printf(" %9d %9d\n", r.over, r.benchmark.Count)
+		metrics[r.benchmark.Status] = m
+	}
+	recordMetric("stageBenchmarks", metrics)
+}
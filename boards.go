@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+)
+
+type Board struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Location struct {
+		ProjectKey  string `json:"projectKey"`
+		DisplayName string `json:"displayName"`
+	} `json:"location"`
+}
+
+type PagedBoards struct {
+	StartAt    int     `json:"startAt"`
+	MaxResults int     `json:"maxResults"`
+	IsLast     bool    `json:"isLast"`
+	Values     []Board `json:"values"`
+}
+
+// fetchBoards lists the boards visible to the user, optionally only those
+// whose name contains name, on the given project or of the given type:
+func fetchBoards(cl *http.Client, name string, project string, boardType string) ([]Board, error) {
+	query := url.Values{}
+	for key, value := range map[string]string{"name": name, "projectKeyOrId": project, "type": boardType} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	h := fnv.New32a()
+	h.Write([]byte(setting(cl, "JIRA_URL") + " " + query.Encode()))
+	queryHash := h.Sum32()
+
+	var boards []Board
+	startAt := 0
+	for {
+		query.Set("startAt", fmt.Sprint(startAt))
+		cacheFilename := fmt.Sprintf("boards.%08x.%d.json", queryHash, startAt)
+		url := setting(cl, "JIRA_URL") + "/rest/agile/1.0/board?" + query.Encode()
+
+		body, err := cachedGet(cacheFilename, url, cl)
+		if err != nil {
+			return nil, err
+		}
+
+		paged := &PagedBoards{}
+		err = decodeResponse(body, url, paged)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		boards = append(boards, paged.Values...)
+		if paged.IsLast || len(paged.Values) == 0 {
+			break
+		}
+		startAt = paged.StartAt + len(paged.Values)
+	}
+	return boards, nil
+}
+
+var boardsSubcommands = map[string]func(args []string) error{
+	"list": runBoardsList,
+}
+
+func runBoards(args []string) error {
+	return runSubcommand("boards", boardsSubcommands, args)
+}
+
+func runBoardsList(args []string) error {
+	fs := flag.NewFlagSet("boards list", flag.ContinueOnError)
+	name := fs.String("name", "", "only boards whose name contains this text")
+	project := fs.String("project", "", "only boards of this project key or ID")
+	boardType := fs.String("type", "", "only scrum, kanban or simple boards")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	boards, err := fetchBoards(httpClient(), *name, *project, *boardType)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%6s  %-7s %-10s %s\n", "id", "type", "project", "name")
+	for _, b := range boards {
+		fmt.Printf("%6d  %-7s %-10s %s\n", b.Id, b.Type, b.Location.ProjectKey, b.Name)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestFetchBoards_Pages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/agile/1.0/board" || r.URL.Query().Get("projectKeyOrId") != "ABC" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("startAt") == "0" {
+			w.Write([]byte(`{"startAt": 0, "maxResults": 1, "isLast": false, "values": [{"id": 7, "name": "ABC board", "type": "scrum", "location": {"projectKey": "ABC"}}]}`))
+			return
+		}
+		w.Write([]byte(`{"startAt": 1, "maxResults": 1, "isLast": true, "values": [{"id": 9, "name": "ABC kanban", "type": "kanban", "location": {"projectKey": "ABC"}}]}`))
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+
+	boards, err := fetchBoards(srv.Client(), "", "ABC", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(boards) != 2 || boards[0].Id != 7 || boards[1].Id != 9 || boards[1].Location.ProjectKey != "ABC" {
+		t.Fatalf("expected both pages of boards, got %+v", boards)
+	}
+}
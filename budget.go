@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// APIBudget caps API usage per run so a misconfigured report can't hammer a
+// shared server; zero means unlimited.
+type APIBudget struct {
+	Requests int   `json:"requests"`
+	Bytes    int64 `json:"bytes"`
+}
+
+// apiBudget returns the configured budget, with $JIRA_MAX_REQUESTS overriding
+// the request limit:
+func apiBudget() APIBudget {
+	var budget APIBudget
+	if config.Budget != nil {
+		budget = *config.Budget
+	}
+	if os.Getenv("JIRA_MAX_REQUESTS") != "" {
+		budget.Requests = getEnvInt("JIRA_MAX_REQUESTS", 0)
+	}
+	return budget
+}
+
+// checkBudget fails once the next API call would exceed the budget:
+func (s *fetchStats) checkBudget(budget APIBudget) error {
+	if budget.Requests > 0 && s.Calls >= budget.Requests {
+		return fmt.Errorf("API budget of %s exhausted", plural(budget.Requests, "request"))
+	}
+	if budget.Bytes > 0 && s.Bytes >= budget.Bytes {
+		return fmt.Errorf("API budget of %s exhausted", formatBytes(budget.Bytes))
+	}
+	return nil
+}
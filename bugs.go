@@ -0,0 +1,276 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var defaultCriticalSeverities = []string{"Sev1", "Sev2"}
+
+func (issue *Issue) IsBug() bool {
+	return strings.EqualFold(issue.Fields.IssueType.Name, "Bug")
+}
+
+// Severity reads the configured severity field, or "(none)":
+func (issue *Issue) Severity() string {
+	if config.SeverityField != "" {
+		if severity := issue.Fields.CustomString(config.SeverityField); severity != "" {
+			return severity
+		}
+	}
+	return "(none)"
+}
+
+func isCriticalSeverity(severity string) bool {
+	critical := config.CriticalSeverities
+	if len(critical) == 0 {
+		critical = defaultCriticalSeverities
+	}
+	for _, s := range critical {
+		if strings.EqualFold(s, severity) {
+			return true
+		}
+	}
+	return false
+}
+
+type severityStats struct {
+	Open    int
+	Oldest  *Issue
+	Age     int
+	Repairs []time.Duration
+}
+
+func (s *severityStats) MTTR() time.Duration {
+	if len(s.Repairs) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, d := range s.Repairs {
+		total += d
+	}
+	return total / time.Duration(len(s.Repairs))
+}
+
+// bugsBySeverity tallies open bugs, the oldest open bug and time to resolve
+// per severity:
+func bugsBySeverity(issues []Issue, today Date) map[string]*severityStats {
+	bySeverity := make(map[string]*severityStats)
+	for i := range issues {
+		issue := &issues[i]
+		if !issue.IsBug() {
+			continue
+		}
+
+		severity := issue.Severity()
+		stats, ok := bySeverity[severity]
+		if !ok {
+			stats = &severityStats{}
+			bySeverity[severity] = stats
+		}
+
+		if resolved, ok := issue.CompletedTime(); ok {
+			stats.Repairs = append(stats.Repairs, resolved.Sub(issue.Fields.Created.Time))
+			continue
+		}
+
+		stats.Open++
+		age := DateOf(issue.Fields.Created.Time).BusinessDaysUntil(today)
+		if stats.Oldest == nil || age > stats.Age {
+			stats.Oldest = issue
+			stats.Age = age
+		}
+	}
+	return bySeverity
+}
+
+// bugLoad is how many bugs name one affected version or environment:
+type bugLoad struct {
+	Name     string
+	Bugs     int
+	Open     int
+	Critical int
+	// Heavy marks a disproportionate share of the bugs:
+	Heavy bool
+}
+
+// heavyBugLoad is the multiple of the even share of bugs that marks a
+// version or environment as disproportionate:
+const heavyBugLoad = 2
+
+// minHeavyBugs is the fewest bugs a version needs before it's called out:
+const minHeavyBugs = 3
+
+// environmentOf names a bug's environment by the first line of its free-text
+// field, or "(none)":
+func (issue *Issue) environmentOf() string {
+	env := strings.TrimSpace(issue.Fields.Environment)
+	if i := strings.IndexAny(env, "\r\n"); i >= 0 {
+		env = strings.TrimSpace(env[:i])
+	}
+	if env == "" {
+		return "(none)"
+	}
+	return env
+}
+
+// bugsByLoad tallies bugs by the names each bug is filed under, most bugs
+// first, marking those with more than heavyBugLoad times an even share:
+func bugsByLoad(issues []Issue, namesOf func(issue *Issue) []string) []*bugLoad {
+	byName := make(map[string]*bugLoad)
+	var loads []*bugLoad
+	total := 0
+	for i := range issues {
+		issue := &issues[i]
+		if !issue.IsBug() {
+			continue
+		}
+		_, resolved := issue.CompletedTime()
+		for _, name := range namesOf(issue) {
+			load, ok := byName[name]
+			if !ok {
+				load = &bugLoad{Name: name}
+				byName[name] = load
+				loads = append(loads, load)
+			}
+			load.Bugs++
+			total++
+			if !resolved {
+				load.Open++
+			}
+			if isCriticalSeverity(issue.Severity()) {
+				load.Critical++
+			}
+		}
+	}
+
+	for _, load := range loads {
+		load.Heavy = len(loads) > 1 && load.Bugs >= minHeavyBugs &&
+			share(load.Bugs, total) > heavyBugLoad/float64(len(loads))
+	}
+	sort.SliceStable(loads, func(i, j int) bool {
+		if loads[i].Bugs != loads[j].Bugs {
+			return loads[i].Bugs > loads[j].Bugs
+		}
+		return loads[i].Name < loads[j].Name
+	})
+	return loads
+}
+
+// affectedVersions names the versions a bug affects, or "(none)":
+func affectedVersions(issue *Issue) []string {
+	if len(issue.Fields.Versions) == 0 {
+		return []string{"(none)"}
+	}
+	names := make([]string, 0, len(issue.Fields.Versions))
+	for _, v := range issue.Fields.Versions {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func bugEnvironment(issue *Issue) []string {
+	return []string{issue.environmentOf()}
+}
+
+func printBugLoads(title string, loads []*bugLoad) {
+	fmt.Printf("\nBugs by %s:\n", title)
+	width := displayWidth(title)
+	for _, load := range loads {
+		if w := displayWidth(load.Name); w > width {
+			width = w
+		}
+	}
+	if width > 40 {
+		width = 40
+	}
+	fmt.Printf("  %s %5s %5s %8s\n", padRight(title, width), "bugs", "open", "critical")
+	shown := detailLines(len(loads))
+	for _, load := range loads[:shown] {
+		note := ""
+		if load.Heavy {
+			note = "  disproportionate"
+		}
+		fmt.Printf("  %s %5d %5d %8d%s\n", padRight(load.Name, width), load.Bugs, load.Open, load.Critical, note)
+	}
+	printOmitted("  ", shown, len(loads))
+}
+
+func formatDays(d time.Duration) string {
+	return fmt.Sprintf("%.1fd", d.Hours()/24)
+}
+
+func runBugs(args []string) error {
+	fs := flag.NewFlagSet("bugs", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting bugs; default='issuetype = Bug AND (resolved >= -90d OR resolution is EMPTY)'")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if config.SeverityField == "" {
+		fmt.Printf("note: severityField is not configured; all bugs are reported without severity\n")
+	}
+
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("bugs", *jql, "issuetype = Bug AND (resolved >= -90d OR resolution is EMPTY)"))
+	if err != nil {
+		return err
+	}
+
+	bySeverity := bugsBySeverity(issues, DateOf(reportNow()))
+	severities := make([]string, 0, len(bySeverity))
+	for severity := range bySeverity {
+		severities = append(severities, severity)
+	}
+	sort.Strings(severities)
+
+	openCritical := 0
+	var oldestCritical *severityStats
+	for _, severity := range severities {
+		stats := bySeverity[severity]
+		if !isCriticalSeverity(severity) {
+			continue
+		}
+		openCritical += stats.Open
+		if stats.Oldest != nil && (oldestCritical == nil || stats.Age > oldestCritical.Age) {
+			oldestCritical = stats
+		}
+	}
+
+	fmt.Printf("Open critical bugs: %d\n", openCritical)
+	if oldestCritical != nil {
+		fmt.Printf(
+			"Oldest critical bug: %s (%s, %d business days old); %s\n",
+			oldestCritical.Oldest.Key,
+			oldestCritical.Oldest.Severity(),
+			oldestCritical.Age,
+			oldestCritical.Oldest.DisplaySummary(),
+		)
+	}
+
+	fmt.Printf("\nBugs by severity:\n")
+	fmt.Printf("  %-12s %5s %8s %8s %8s\n", "severity", "open", "max age", "resolved", "MTTR")
+	for _, severity := range severities {
+		stats := bySeverity[severity]
+		mttr := "-"
+		if len(stats.Repairs) > 0 {
+			mttr = formatDays(stats.MTTR())
+		}
+		fmt.Printf("  %-12s %5d %8d %8d %8s\n", severity, stats.Open, stats.Age, len(stats.Repairs), mttr)
+	}
+
+	versions := bugsByLoad(issues, affectedVersions)
+	printBugLoads("affected version", versions)
+	printBugLoads("environment", bugsByLoad(issues, bugEnvironment))
+
+	heavy := 0
+	for _, load := range versions {
+		if load.Heavy {
+			heavy++
+		}
+	}
+	recordMetric("openCriticalBugs", openCritical)
+	recordMetric("heavyBugVersions", heavy)
+	return nil
+}
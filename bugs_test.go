@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestBugsBySeverity(t *testing.T) {
+	config = &Config{SeverityField: "customfield_1"}
+	defer func() { config = &Config{} }()
+
+	var issues []Issue
+	err := json.Unmarshal([]byte(`[
+		{"key": "B-1", "fields": {"issuetype": {"name": "Bug"}, "created": "2018-11-01T09:00:00.000-0500", "customfield_1": {"value": "Sev1"}}},
+		{"key": "B-2", "fields": {"issuetype": {"name": "Bug"}, "created": "2018-10-29T09:00:00.000-0500", "customfield_1": {"value": "Sev1"}}},
+		{"key": "B-3", "fields": {"issuetype": {"name": "Bug"}, "created": "2018-11-01T09:00:00.000-0500", "customfield_1": "Sev3",
+			"status": {"statusCategory": {"key": "done"}}, "resolutiondate": "2018-11-03T09:00:00.000-0500"}},
+		{"key": "S-1", "fields": {"issuetype": {"name": "Story"}, "created": "2018-11-01T09:00:00.000-0500"}}
+	]`), &issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bySeverity := bugsBySeverity(issues, DateOf(time.Date(2018, 11, 6, 9, 0, 0, 0, cst)))
+	if len(bySeverity) != 2 {
+		t.Fatalf("expected 2 for severities, got %d", len(bySeverity))
+	}
+
+	sev1 := bySeverity["Sev1"]
+	if sev1.Open != 2 || sev1.Oldest.Key != "B-2" {
+		t.Fatalf("expected 2 open Sev1 with B-2 oldest, got %d and %s", sev1.Open, sev1.Oldest.Key)
+	}
+
+	sev3 := bySeverity["Sev3"]
+	if len(sev3.Repairs) != 1 || sev3.MTTR() != 48*time.Hour {
+		t.Fatalf("expected 48h MTTR for Sev3, got %v", sev3.MTTR())
+	}
+}
+
+func TestBugsByLoad(t *testing.T) {
+	var issues []Issue
+	err := json.Unmarshal([]byte(`[
+		{"key": "B-1", "fields": {"issuetype": {"name": "Bug"}, "versions": [{"name": "2.0"}], "environment": "Android 9\nPixel 3"}},
+		{"key": "B-2", "fields": {"issuetype": {"name": "Bug"}, "versions": [{"name": "2.0"}, {"name": "2.1"}], "environment": "Android 9"}},
+		{"key": "B-3", "fields": {"issuetype": {"name": "Bug"}, "versions": [{"name": "2.0"}],
+			"status": {"statusCategory": {"key": "done"}}, "resolutiondate": "2018-11-03T09:00:00.000-0500"}},
+		{"key": "B-4", "fields": {"issuetype": {"name": "Bug"}, "versions": [{"name": "1.9"}]}},
+		{"key": "B-5", "fields": {"issuetype": {"name": "Bug"}}},
+		{"key": "B-6", "fields": {"issuetype": {"name": "Bug"}, "versions": [{"name": "2.0"}]}},
+		{"key": "S-1", "fields": {"issuetype": {"name": "Story"}, "versions": [{"name": "1.9"}]}}
+	]`), &issues)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	versions := bugsByLoad(issues, affectedVersions)
+	if len(versions) != 4 {
+		t.Fatalf("expected 4 versions, got %d", len(versions))
+	}
+	top := versions[0]
+	if top.Name != "2.0" || top.Bugs != 4 || top.Open != 3 || !top.Heavy {
+		t.Fatalf("expected 2.0 first with 4 bugs, 3 open and disproportionate, got %+v", top)
+	}
+	for _, load := range versions[1:] {
+		if load.Bugs != 1 || load.Heavy {
+			t.Errorf("expected one bug and no call out for %s, got %+v", load.Name, load)
+		}
+	}
+
+	environments := bugsByLoad(issues, bugEnvironment)
+	if environments[0].Name != "(none)" || environments[0].Bugs != 4 || environments[1].Name != "Android 9" || environments[1].Bugs != 2 {
+		t.Fatalf("expected 4 without an environment and 2 on Android 9, got %+v %+v", environments[0], environments[1])
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheTTL is how long a cached response is used before it is refetched:
+const cacheTTL = time.Hour
+
+const cacheIndexFilename = "cache.index.json"
+
+// cacheEntry describes one cached response or memoized report:
+type cacheEntry struct {
+	// Kind is what the file caches, e.g. board, changelog or report:
+	Kind string `json:"kind"`
+	// Board and Query identify board issue pages; Query is the hash of the
+	// JQL or query in the filename:
+	Board int    `json:"board,omitempty"`
+	Query string `json:"query,omitempty"`
+	// Source is the URL fetched, or the report memoized:
+	Source  string    `json:"source"`
+	Written time.Time `json:"written"`
+	Size    int64     `json:"size"`
+}
+
+// pendingCacheEntries are written this run and not yet in the index, which is
+// updated once at the end rather than once per response:
+var pendingCacheEntries map[string]*cacheEntry
+
+var queryHashPattern = regexp.MustCompile(`^[0-9a-f]{8}$`)
+
+// parseCacheFilename recovers what a cache file holds from its name, e.g.
+// board.<id>.<jql hash>.<resource>.<startAt>.json:
+func parseCacheFilename(filename string) *cacheEntry {
+	parts := strings.Split(strings.TrimSuffix(filepath.Base(filename), ".json"), ".")
+	entry := &cacheEntry{Kind: parts[0]}
+	for i, part := range parts[1:] {
+		switch {
+		case i == 0 && entry.Kind == "board":
+			entry.Board, _ = strconv.Atoi(part)
+		case part == "sprints" || part == "changelog" || part == "updates":
+			entry.Kind = part
+		case entry.Query == "" && queryHashPattern.MatchString(part):
+			entry.Query = part
+		}
+	}
+	return entry
+}
+
+// indexCacheFile notes a file just written to the cache:
+func indexCacheFile(filename string, source string, size int) {
+	entry := parseCacheFilename(filename)
+	entry.Source = source
+	entry.Written = time.Now()
+	entry.Size = int64(size)
+	if pendingCacheEntries == nil {
+		pendingCacheEntries = make(map[string]*cacheEntry)
+	}
+	pendingCacheEntries[filename] = entry
+}
+
+func loadCacheIndex() (map[string]*cacheEntry, error) {
+	index := make(map[string]*cacheEntry)
+	b, err := readCacheFile(cacheIndexFilename)
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(b, &index); err != nil {
+		return nil, fmt.Errorf("%s: %v", cacheIndexFilename, err)
+	}
+	return index, nil
+}
+
+// flushCacheIndex merges this run's entries into the index, dropping entries
+// whose files are gone; runs sharing the cache take turns via its lock.
+func flushCacheIndex() {
+	if len(pendingCacheEntries) == 0 {
+		return
+	}
+	err := func() error {
+		unlock, err := lockFile(cacheIndexFilename)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+
+		index, err := loadCacheIndex()
+		if err != nil {
+			return err
+		}
+		for filename, entry := range pendingCacheEntries {
+			index[filename] = entry
+		}
+		for filename := range index {
+			if _, err := os.Stat(filename); os.IsNotExist(err) {
+				delete(index, filename)
+			}
+		}
+
+		b, err := json.MarshalIndent(index, "", "  ")
+		if err != nil {
+			return err
+		}
+		if b, err = encryptAtRest(b); err != nil {
+			return err
+		}
+		return writeFileAtomic(cacheIndexFilename, b, 0600)
+	}()
+	if err != nil {
+		log.Printf("cache: %s: %v\n", cacheIndexFilename, err)
+		return
+	}
+	pendingCacheEntries = nil
+}
+
+// cacheFilePatterns find cache files written before the index existed:
+var cacheFilePatterns = []string{"board.*.json", "boards.*.json", "filter.*.json", "issue.*.json", "report.*.json"}
+
+// cachedFiles lists every cache file present, indexed or not, with its size
+// and modification time from the file itself.
+func cachedFiles() (map[string]*cacheEntry, error) {
+	index, err := loadCacheIndex()
+	if err != nil {
+		return nil, err
+	}
+	for _, pattern := range cacheFilePatterns {
+		matches, _ := filepath.Glob(pattern)
+		for _, filename := range matches {
+			if index[filename] == nil {
+				index[filename] = parseCacheFilename(filename)
+			}
+		}
+	}
+
+	files := make(map[string]*cacheEntry, len(index))
+	for filename, entry := range index {
+		stat, err := os.Stat(filename)
+		if err != nil {
+			continue
+		}
+		entry.Size = stat.Size()
+		entry.Written = stat.ModTime()
+		files[filename] = entry
+	}
+	return files, nil
+}
+
+// formatAge shortens an age to its largest unit, e.g. 40m, 5h or 3d:
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return fmt.Sprintf("%dm", d/time.Minute)
+}
+
+var cacheSubcommands = map[string]func(args []string) error{
+	"ls":    runCacheList,
+	"stats": runCacheStats,
+}
+
+func runCacheCommand(args []string) error {
+	return runSubcommand("cache", cacheSubcommands, args)
+}
+
+// runCacheList lists cached files, optionally only one board's or the stale:
+func runCacheList(args []string) error {
+	fs := flag.NewFlagSet("cache ls", flag.ContinueOnError)
+	board := fs.Int("board", 0, "only list entries for this board")
+	kind := fs.String("kind", "", "only list entries of this kind, e.g. board, changelog or report")
+	stale := fs.Bool("stale", false, "only list entries older than the cache lifetime")
+	long := fs.Bool("l", false, "also show the URL or report each entry caches")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	files, err := cachedFiles()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(files))
+	for filename, entry := range files {
+		if *board != 0 && entry.Board != *board || *kind != "" && entry.Kind != *kind {
+			continue
+		}
+		if *stale && time.Since(entry.Written) < cacheTTL {
+			continue
+		}
+		names = append(names, filename)
+	}
+	sort.Strings(names)
+
+	width := 0
+	for _, filename := range names {
+		if len(filename) > width {
+			width = len(filename)
+		}
+	}
+	fmt.Printf("%s %-10s %6s %-8s %5s %10s\n", padRight("file", width), "kind", "board", "query", "age", "size")
+	for _, filename := range names {
+		entry := files[filename]
+		board := ""
+		if entry.Board != 0 {
+			board = strconv.Itoa(entry.Board)
+		}
+		age := time.Since(entry.Written)
+		mark := ""
+		if age >= cacheTTL {
+			mark = "  stale"
+		}
+		fmt.Printf("%s %-10s %6s %-8s %5s %10s%s\n", padRight(filename, width), entry.Kind, board, entry.Query, formatAge(age), formatBytes(entry.Size), mark)
+		if *long && entry.Source != "" {
+			fmt.Printf("  %s\n", entry.Source)
+		}
+	}
+	fmt.Printf("%s\n", plural(len(names), "file"))
+	return nil
+}
+
+// cacheGroup summarizes the cache entries of one kind and board:
+type cacheGroup struct {
+	Kind    string
+	Board   int
+	Entries int
+	Stale   int
+	Size    int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+// runCacheStats summarizes the cache by kind and board:
+func runCacheStats(args []string) error {
+	fs := flag.NewFlagSet("cache stats", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	files, err := cachedFiles()
+	if err != nil {
+		return err
+	}
+	byGroup := make(map[string]*cacheGroup)
+	total := &cacheGroup{}
+	unindexed := 0
+	for _, entry := range files {
+		key := fmt.Sprintf("%s.%d", entry.Kind, entry.Board)
+		g, ok := byGroup[key]
+		if !ok {
+			g = &cacheGroup{Kind: entry.Kind, Board: entry.Board}
+			byGroup[key] = g
+		}
+		for _, g := range []*cacheGroup{g, total} {
+			g.Entries++
+			g.Size += entry.Size
+			if time.Since(entry.Written) >= cacheTTL {
+				g.Stale++
+			}
+			if g.Oldest.IsZero() || entry.Written.Before(g.Oldest) {
+				g.Oldest = entry.Written
+			}
+			if entry.Written.After(g.Newest) {
+				g.Newest = entry.Written
+			}
+		}
+		if entry.Source == "" {
+			unindexed++
+		}
+	}
+
+	groups := make([]*cacheGroup, 0, len(byGroup))
+	for _, g := range byGroup {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Kind != groups[j].Kind {
+			return groups[i].Kind < groups[j].Kind
+		}
+		return groups[i].Board < groups[j].Board
+	})
+
+	fmt.Printf("%-10s %6s %7s %7s %10s %7s %7s\n", "kind", "board", "entries", "stale", "size", "newest", "oldest")
+	row := func(kind string, board string, g *cacheGroup) {
+		fmt.Printf("%-10s %6s %7d %7d %10s %7s %7s\n", kind, board, g.Entries, g.Stale, formatBytes(g.Size),
+			formatAge(time.Since(g.Newest)), formatAge(time.Since(g.Oldest)))
+	}
+	for _, g := range groups {
+		board := ""
+		if g.Board != 0 {
+			board = strconv.Itoa(g.Board)
+		}
+		row(g.Kind, board, g)
+	}
+	if total.Entries > 0 {
+		row("total", "", total)
+	}
+	fmt.Printf("Entries older than %s are stale and refetched when next used.\n", formatAge(cacheTTL))
+	if unindexed > 0 {
+		fmt.Printf("%s predate the cache index, so their sources are unknown.\n", plural(unindexed, "file"))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCacheFilename(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     cacheEntry
+	}{
+		{"board.4454.0a1b2c3d.issue.100.json", cacheEntry{Kind: "board", Board: 4454, Query: "0a1b2c3d"}},
+		{"board.4454.sprints.active.0.json", cacheEntry{Kind: "sprints", Board: 4454}},
+		{"filter.123.deadbeef.0.json", cacheEntry{Kind: "filter", Query: "deadbeef"}},
+		{"issue.ABC-1.changelog.100.json", cacheEntry{Kind: "changelog"}},
+		{"serverInfo.json", cacheEntry{Kind: "serverInfo"}},
+	}
+	for _, test := range tests {
+		if got := parseCacheFilename(test.filename); *got != test.want {
+			t.Errorf("%s: expected %+v, got %+v", test.filename, test.want, *got)
+		}
+	}
+}
+
+func TestCacheIndex(t *testing.T) {
+	dir, _ := os.Getwd()
+	defer os.Chdir(dir)
+	os.Chdir(t.TempDir())
+	defer func() { pendingCacheEntries = nil }()
+
+	for _, filename := range []string{"board.7.0a1b2c3d.issue.0.json", "board.8.0a1b2c3d.issue.0.json", "issue.A-1.json"} {
+		ioutil.WriteFile(filename, []byte("{}"), 0600)
+	}
+	indexCacheFile("board.7.0a1b2c3d.issue.0.json", "https://jira.example.com/rest/agile/1.0/board/7/issue", 2)
+	indexCacheFile("gone.json", "https://jira.example.com/gone", 2)
+	flushCacheIndex()
+	if pendingCacheEntries != nil {
+		t.Fatalf("expected pending entries flushed, got %v", pendingCacheEntries)
+	}
+	index, err := loadCacheIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index) != 1 || index["board.7.0a1b2c3d.issue.0.json"] == nil {
+		t.Fatalf("expected only the existing board 7 file indexed, got %v", index)
+	}
+
+	// Files from before the index show up too:
+	old := time.Now().Add(-3 * time.Hour)
+	os.Chtimes("board.8.0a1b2c3d.issue.0.json", old, old)
+	out, err := captureOutput(func() error { return runCacheList([]string{"-stale"}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "board.8.0a1b2c3d.issue.0.json board") || !strings.Contains(out, "3h") ||
+		strings.Contains(out, "board.7.") || !strings.Contains(out, "1 file") {
+		t.Fatalf("expected only the stale board 8 file, got:\n%s", out)
+	}
+
+	out, err = captureOutput(func() error { return runCacheStats(nil) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "total") || !strings.Contains(out, "2 files predate the cache index") {
+		t.Fatalf("expected totals and unindexed files noted, got:\n%s", out)
+	}
+}
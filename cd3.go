@@ -0,0 +1,160 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+)
+
+// costOfDelay is an issue's cost of delay per week, from the config or its
+// cost of delay field:
+func (issue *Issue) costOfDelay() (float64, bool) {
+	if cod, ok := config.CostOfDelay[issue.Key]; ok {
+		return cod, true
+	}
+	if config.CostOfDelayField == "" {
+		return 0, false
+	}
+	cod, err := strconv.ParseFloat(issue.Fields.CustomString(config.CostOfDelayField), 64)
+	return cod, err == nil
+}
+
+// cd3Item is an open epic's cost of delay divided by its forecast duration:
+type cd3Item struct {
+	Epic        *Issue
+	CostOfDelay float64
+	Scored      bool
+	Remaining   int
+	// Weeks is the forecast duration of the remaining children at the team's
+	// throughput, 0 when it can't be forecast:
+	Weeks float64
+	CD3   float64
+}
+
+// rankCD3 scores epics by CD3, highest first, and then the epics that can't
+// be scored, without a cost of delay or forecast, by key. Durations are the
+// percentile of simulated business days to complete each epic's remaining
+// children at the team's daily throughput.
+func rankCD3(epics []*Issue, children map[string][]*Issue, throughput []int, p float64) []cd3Item {
+	completed := 0
+	for _, n := range throughput {
+		completed += n
+	}
+	var items []cd3Item
+	for _, epic := range epics {
+		item := cd3Item{Epic: epic}
+		item.CostOfDelay, item.Scored = epic.costOfDelay()
+		for _, child := range children[epic.Key] {
+			if _, done := child.CompletedTime(); !done {
+				item.Remaining++
+			}
+		}
+		if item.Remaining > 0 && completed > 0 {
+			days := percentile(monteCarlo(throughput, item.Remaining, 1000, rand.New(rand.NewSource(1))), p)
+			item.Weeks = float64(days) / 5
+		}
+		if item.Scored && item.Weeks > 0 {
+			item.CD3 = item.CostOfDelay / item.Weeks
+		} else {
+			item.Scored = false
+		}
+		items = append(items, item)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Scored != items[j].Scored {
+			return items[i].Scored
+		}
+		if items[i].CD3 != items[j].CD3 {
+			return items[i].CD3 > items[j].CD3
+		}
+		return lessIssueKey(items[i].Epic.Key, items[j].Epic.Key)
+	})
+	return items
+}
+
+// delayCost is the cost of delay incurred doing the epics one after another
+// in the order given: each waits for those before it and its own duration.
+func delayCost(items []cd3Item) float64 {
+	cost, elapsed := 0.0, 0.0
+	for _, item := range items {
+		if !item.Scored {
+			continue
+		}
+		elapsed += item.Weeks
+		cost += item.CostOfDelay * elapsed
+	}
+	return cost
+}
+
+func runCD3(args []string) error {
+	fs := flag.NewFlagSet("cd3", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting epics; default='issuetype = Epic AND statusCategory != Done'")
+	weeks := fs.Int("weeks", 6, "weeks of completions to forecast durations from")
+	p := fs.Float64("percentile", 50, "percentile of simulated durations to divide by")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *weeks < 1 {
+		return fmt.Errorf("-weeks must be at least 1")
+	}
+	if *p <= 0 || *p > 100 {
+		return fmt.Errorf("-percentile must be between 0 and 100")
+	}
+
+	cl := httpClient()
+	boardId := boardArg(fs.Args())
+	epics, children, err := openEpicsByJQL(cl, boardId, reportJQL("cd3", *jql, "issuetype = Epic AND statusCategory != Done"))
+	if err != nil {
+		return err
+	}
+	if len(epics) == 0 {
+		fmt.Printf("No open epics.\n")
+		return nil
+	}
+	completed, err := fetchBoardIssues(cl, boardId, fmt.Sprintf("resolved >= -%dd", 7**weeks))
+	if err != nil {
+		return err
+	}
+
+	throughput := dailyThroughput(completed, reportNow(), 7**weeks)
+	items := rankCD3(epics, children, throughput, *p)
+	fmt.Printf("Open epics by cost of delay per week divided by weeks to complete (CD3), highest first:\n")
+	fmt.Printf("  %-12s %10s %9s %6s %10s summary\n", "epic", "CoD/week", "remaining", "weeks", "CD3")
+	var unscored []cd3Item
+	for _, item := range items {
+		if !item.Scored {
+			unscored = append(unscored, item)
+			continue
+		}
+		fmt.Printf("  %-12s %10.0f %9d %6.1f %10.1f %s\n", item.Epic.Key, item.CostOfDelay, item.Remaining, item.Weeks, item.CD3, item.Epic.DisplaySummary())
+	}
+
+	if len(unscored) == len(items) {
+		fmt.Printf("  none\n")
+	}
+
+	// Compared with doing the epics in key order, roughly the order they
+	// were raised in:
+	byKey := append([]cd3Item(nil), items...)
+	sort.SliceStable(byKey, func(i, j int) bool { return lessIssueKey(byKey[i].Epic.Key, byKey[j].Epic.Key) })
+	if cost := delayCost(items); cost > 0 {
+		fmt.Printf("\nCost of delay in this order: %.0f, vs %.0f in key order.\n", cost, delayCost(byKey))
+	}
+	recordMetric("cd3Scored", len(items)-len(unscored))
+
+	if len(unscored) > 0 {
+		fmt.Printf("\nNot scored, without a cost of delay or a forecast:\n")
+		shown := detailLines(len(unscored))
+		for _, item := range unscored[:shown] {
+			reason := "no cost of delay"
+			if _, ok := item.Epic.costOfDelay(); ok {
+				reason = "no forecast"
+			}
+			fmt.Printf("  %-12s %s; %s\n", item.Epic.Key, reason, item.Epic.DisplaySummary())
+		}
+		printOmitted("  ", shown, len(unscored))
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRankCD3(t *testing.T) {
+	config = &Config{CostOfDelayField: "customfield_11200", CostOfDelay: map[string]float64{"E-2": 900}}
+	defer func() { config = &Config{} }()
+
+	epic := func(key string, cod string) *Issue {
+		e := &Issue{Key: key}
+		e.Fields.IssueType.Name = "Epic"
+		if cod != "" {
+			e.Fields.Custom = map[string]json.RawMessage{"customfield_11200": json.RawMessage(cod)}
+		}
+		return e
+	}
+	open := func(n int) []*Issue {
+		var children []*Issue
+		for i := 0; i < n; i++ {
+			children = append(children, &Issue{Key: "C-1"})
+		}
+		return children
+	}
+	done := completedIssue("C-2", time.Now().AddDate(0, 0, -3), time.Now())
+
+	// One completion a day is 5 a week:
+	throughput := []int{1}
+	epics := []*Issue{epic("E-1", "1000"), epic("E-2", `"100"`), epic("E-3", ""), epic("E-4", "50")}
+	children := map[string][]*Issue{
+		"E-1": open(10),
+		"E-2": open(5),
+		"E-3": open(5),
+		"E-4": {&done},
+	}
+	items := rankCD3(epics, children, throughput, 50)
+
+	var order []string
+	for _, item := range items {
+		order = append(order, item.Epic.Key)
+	}
+	// E-2's configured cost of delay outranks its field, and E-4 has nothing
+	// left to forecast:
+	want := []string{"E-2", "E-1", "E-3", "E-4"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, order)
+		}
+	}
+	if items[0].Weeks != 1 || items[0].CD3 != 900 || items[1].Weeks != 2 || items[1].CD3 != 500 {
+		t.Errorf("expected CD3 900 over 1 week and 500 over 2, got %+v %+v", items[0], items[1])
+	}
+	if items[2].Scored || items[3].Scored {
+		t.Errorf("expected E-3 and E-4 unscored, got %+v %+v", items[2], items[3])
+	}
+
+	// E-2 then E-1 costs 900×1 + 1000×3; the other way round, 1000×2 + 900×3:
+	if got := delayCost(items); got != 3900 {
+		t.Errorf("expected a delay cost of 3900, got %.0f", got)
+	}
+	if got := delayCost([]cd3Item{items[1], items[0]}); got != 4700 {
+		t.Errorf("expected a delay cost of 4700, got %.0f", got)
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ganttChart is rows of status intervals over time, such as an epic's
+// children from start of work to completion. Intervals with no end are still
+// going at Now.
+type ganttChart struct {
+	Title  string
+	Rows   []timelineRow
+	Now    time.Time
+	Footer string
+}
+
+// chartBackend renders charts in one format. All are pure Go, so headless
+// servers can render charts without cgo or a browser.
+type chartBackend interface {
+	RenderGantt(w io.Writer, c *ganttChart) error
+}
+
+var chartBackends = map[string]chartBackend{
+	"mermaid":   mermaidChart{},
+	"svg":       svgChart{},
+	"png":       pngChart{},
+	"vega-lite": vegaLiteChart{},
+}
+
+// chartExtensions pick the format of a chart written to a file by its name:
+var chartExtensions = map[string]string{
+	".mmd":     "mermaid",
+	".mermaid": "mermaid",
+	".svg":     "svg",
+	".png":     "png",
+	".json":    "vega-lite",
+}
+
+func chartFormats() []string {
+	formats := make([]string, 0, len(chartBackends))
+	for format := range chartBackends {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// chartBackendFor selects the backend named by -format, else the one the
+// output file's extension implies, else Mermaid:
+func chartBackendFor(format string, output string) (chartBackend, error) {
+	if format == "" {
+		format = chartExtensions[strings.ToLower(filepath.Ext(output))]
+	}
+	if format == "" {
+		format = "mermaid"
+	}
+	backend, ok := chartBackends[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format '%s'; expected %s", format, strings.Join(chartFormats(), ", "))
+	}
+	return backend, nil
+}
+
+// statusColors gives each status in the chart a color, spread around the hue
+// circle in status name order:
+func (c *ganttChart) statusColors() ([]string, map[string]chartColor) {
+	var statuses []string
+	seen := make(map[string]bool)
+	for _, row := range c.Rows {
+		for _, in := range row.Intervals {
+			if !seen[in.Status] {
+				seen[in.Status] = true
+				statuses = append(statuses, in.Status)
+			}
+		}
+	}
+	sort.Strings(statuses)
+	colors := make(map[string]chartColor, len(statuses))
+	for i, status := range statuses {
+		colors[status] = chartColor{Hue: 360 * i / len(statuses)}
+	}
+	return statuses, colors
+}
+
+// span is the time from the earliest interval's start to the latest's end:
+func (c *ganttChart) span() (from time.Time, to time.Time) {
+	for _, row := range c.Rows {
+		for _, in := range row.Intervals {
+			end := in.End
+			if end.IsZero() {
+				end = c.Now
+			}
+			if from.IsZero() || in.Start.Before(from) {
+				from = in.Start
+			}
+			if end.After(to) {
+				to = end
+			}
+		}
+	}
+	return from, to
+}
+
+// mondays lists the starts of the weeks in the span, for gridlines:
+func mondays(from time.Time, to time.Time) []time.Time {
+	var weeks []time.Time
+	year, month, day := from.Date()
+	for t := time.Date(year, month, day, 0, 0, 0, 0, from.Location()); !t.After(to); t = t.AddDate(0, 0, 1) {
+		if t.Weekday() == time.Monday && !t.Before(from) {
+			weeks = append(weeks, t)
+		}
+	}
+	return weeks
+}
+
+// chartColor is a status's color, at 60% saturation and 65% lightness:
+type chartColor struct {
+	Hue int
+}
+
+func (c chartColor) String() string {
+	return fmt.Sprintf("hsl(%d, 60%%, 65%%)", c.Hue)
+}
+
+// mermaidText strips characters that end a Mermaid gantt task or section name:
+var mermaidText = strings.NewReplacer(":", " ", ";", " ", "#", "", "\n", " ")
+
+const mermaidTimeLayout = "2006-01-02T15:04"
+
+// mermaidChart writes a Mermaid gantt chart, for any Markdown renderer that
+// supports Mermaid:
+type mermaidChart struct{}
+
+func (mermaidChart) RenderGantt(w io.Writer, c *ganttChart) error {
+	fmt.Fprintf(w, "gantt\n")
+	fmt.Fprintf(w, "    title %s\n", mermaidText.Replace(c.Title))
+	fmt.Fprintf(w, "    dateFormat YYYY-MM-DDTHH:mm\n")
+	fmt.Fprintf(w, "    axisFormat %%b %%d\n")
+	for _, row := range c.Rows {
+		fmt.Fprintf(w, "    section %s %s\n", row.Key, mermaidText.Replace(row.Summary))
+		for _, in := range row.Intervals {
+			// Still in this status; Mermaid shades active tasks:
+			tag := ""
+			end := in.End
+			if end.IsZero() {
+				tag = "active, "
+				end = c.Now
+			}
+			fmt.Fprintf(w, "    %s :%s%s, %s\n", mermaidText.Replace(in.Status), tag, displayTime(in.Start).Format(mermaidTimeLayout), displayTime(end).Format(mermaidTimeLayout))
+		}
+	}
+	if c.Footer != "" {
+		fmt.Fprintf(w, "%%%% %s\n", c.Footer)
+	}
+	return nil
+}
+
+// Layout of the SVG and PNG gantt charts, in pixels:
+const (
+	timelineLabelWidth = 260
+	timelineChartWidth = 640
+	timelineRowHeight  = 22
+	timelineTop        = 40
+)
+
+// svgChart writes a standalone SVG:
+type svgChart struct{}
+
+func (svgChart) RenderGantt(w io.Writer, c *ganttChart) error {
+	esc := template.HTMLEscapeString
+	statuses, colors := c.statusColors()
+	from, to := c.span()
+	span := to.Sub(from)
+	if span <= 0 {
+		span = time.Hour
+	}
+	x := func(t time.Time) float64 {
+		return timelineLabelWidth + float64(timelineChartWidth)*float64(t.Sub(from))/float64(span)
+	}
+
+	chartBottom := timelineTop + len(c.Rows)*timelineRowHeight
+	height := chartBottom + 30 + 16*len(statuses) + 20
+	width := timelineLabelWidth + timelineChartWidth + 20
+
+	fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" font-family=\"sans-serif\" font-size=\"12\">\n", width, height)
+	fmt.Fprintf(w, "<text x=\"4\" y=\"20\" font-size=\"16\">%s</text>\n", esc(c.Title))
+
+	// Week gridlines on Mondays:
+	for _, t := range mondays(from, to) {
+		fmt.Fprintf(w, "<line x1=\"%.1f\" y1=\"%d\" x2=\"%.1f\" y2=\"%d\" stroke=\"#ddd\"/>\n", x(t), timelineTop-4, x(t), chartBottom)
+		fmt.Fprintf(w, "<text x=\"%.1f\" y=\"%d\" fill=\"#666\">%s</text>\n", x(t)+2, chartBottom+14, t.Format("Jan 02"))
+	}
+
+	for r, row := range c.Rows {
+		y := timelineTop + r*timelineRowHeight
+		fmt.Fprintf(w, "<text x=\"4\" y=\"%d\">%s %s</text>\n", y+15, esc(row.Key), esc(truncateWidth(row.Summary, 30)))
+		for _, in := range row.Intervals {
+			end := in.End
+			if end.IsZero() {
+				end = c.Now
+			}
+			barWidth := x(end) - x(in.Start)
+			if barWidth < 1 {
+				barWidth = 1
+			}
+			fmt.Fprintf(w, "<rect x=\"%.1f\" y=\"%d\" width=\"%.1f\" height=\"%d\" fill=\"%s\"><title>%s %s: %s to %s</title></rect>\n",
+				x(in.Start), y+3, barWidth, timelineRowHeight-6, colors[in.Status],
+				esc(row.Key), esc(in.Status), displayTime(in.Start).Format("Mon Jan 02 15:04"), displayTime(end).Format("Mon Jan 02 15:04"))
+		}
+	}
+
+	for i, status := range statuses {
+		y := chartBottom + 30 + 16*i
+		fmt.Fprintf(w, "<rect x=\"%d\" y=\"%d\" width=\"12\" height=\"12\" fill=\"%s\"/>\n", timelineLabelWidth, y, colors[status])
+		fmt.Fprintf(w, "<text x=\"%d\" y=\"%d\">%s</text>\n", timelineLabelWidth+18, y+10, esc(status))
+	}
+	if c.Footer != "" {
+		fmt.Fprintf(w, "<text x=\"4\" y=\"%d\" font-size=\"10\" fill=\"#666\">%s</text>\n", height-6, esc(c.Footer))
+	}
+	_, err := fmt.Fprintf(w, "</svg>\n")
+	return err
+}
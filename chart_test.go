@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testGantt() *ganttChart {
+	at := func(day int) time.Time { return time.Date(2018, 11, day, 9, 0, 0, 0, time.UTC) }
+	return &ganttChart{
+		Title: "EPIC-1 timeline",
+		Rows: []timelineRow{
+			{Key: "A-1", Summary: "First", Intervals: []statusInterval{{Status: "In Progress", Start: at(1), End: at(5)}, {Status: "Code Review", Start: at(5), End: at(7)}}},
+			{Key: "A-2", Summary: "Second", Intervals: []statusInterval{{Status: "In Progress", Start: at(6)}}},
+		},
+		Now:    at(12),
+		Footer: "generated for tests",
+	}
+}
+
+func TestChartBackends_Render(t *testing.T) {
+	for _, format := range chartFormats() {
+		var out bytes.Buffer
+		if err := chartBackends[format].RenderGantt(&out, testGantt()); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		switch format {
+		case "png":
+			cfg, _, err := image.DecodeConfig(&out)
+			if err != nil || cfg.Width == 0 || cfg.Height == 0 {
+				t.Fatalf("png: expected a decodable image, got %+v, %v", cfg, err)
+			}
+		case "vega-lite":
+			var spec struct {
+				Data struct {
+					Values []map[string]interface{}
+				}
+			}
+			if err := json.Unmarshal(out.Bytes(), &spec); err != nil || len(spec.Data.Values) != 3 {
+				t.Fatalf("vega-lite: expected three bars, got %+v, %v", spec, err)
+			}
+		default:
+			if !strings.Contains(out.String(), "A-2") {
+				t.Fatalf("%s: expected row A-2 in output:\n%s", format, out.String())
+			}
+		}
+	}
+}
+
+func TestChartBackendFor(t *testing.T) {
+	cases := []struct {
+		format, output string
+		want           chartBackend
+	}{
+		{"", "", mermaidChart{}},
+		{"", "out.PNG", pngChart{}},
+		{"", "out.json", vegaLiteChart{}},
+		{"svg", "out.png", svgChart{}},
+	}
+	for _, c := range cases {
+		got, err := chartBackendFor(c.format, c.output)
+		if err != nil || got != c.want {
+			t.Errorf("chartBackendFor(%q, %q) = %T, %v; want %T", c.format, c.output, got, err, c.want)
+		}
+	}
+	if _, err := chartBackendFor("gif", ""); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// pngChart rasterizes the chart as a PNG with the standard library alone,
+// labelled in a built-in 5×7 pixel font, for chat tools and email that don't
+// show SVG.
+type pngChart struct{}
+
+// pixelFont draws uppercase letters, digits and a little punctuation, each
+// glyph seven rows of five pixels; anything else is drawn as a space.
+var pixelFont = map[rune]string{
+	'0': "01110 10001 10011 10101 11001 10001 01110",
+	'1': "00100 01100 00100 00100 00100 00100 01110",
+	'2': "01110 10001 00001 00010 00100 01000 11111",
+	'3': "11111 00010 00100 00010 00001 10001 01110",
+	'4': "00010 00110 01010 10010 11111 00010 00010",
+	'5': "11111 10000 11110 00001 00001 10001 01110",
+	'6': "00110 01000 10000 11110 10001 10001 01110",
+	'7': "11111 00001 00010 00100 01000 01000 01000",
+	'8': "01110 10001 10001 01110 10001 10001 01110",
+	'9': "01110 10001 10001 01111 00001 00010 01100",
+	'A': "01110 10001 10001 11111 10001 10001 10001",
+	'B': "11110 10001 10001 11110 10001 10001 11110",
+	'C': "01110 10001 10000 10000 10000 10001 01110",
+	'D': "11100 10010 10001 10001 10001 10010 11100",
+	'E': "11111 10000 10000 11110 10000 10000 11111",
+	'F': "11111 10000 10000 11110 10000 10000 10000",
+	'G': "01110 10001 10000 10111 10001 10001 01111",
+	'H': "10001 10001 10001 11111 10001 10001 10001",
+	'I': "01110 00100 00100 00100 00100 00100 01110",
+	'J': "00111 00010 00010 00010 00010 10010 01100",
+	'K': "10001 10010 10100 11000 10100 10010 10001",
+	'L': "10000 10000 10000 10000 10000 10000 11111",
+	'M': "10001 11011 10101 10101 10001 10001 10001",
+	'N': "10001 10001 11001 10101 10011 10001 10001",
+	'O': "01110 10001 10001 10001 10001 10001 01110",
+	'P': "11110 10001 10001 11110 10000 10000 10000",
+	'Q': "01110 10001 10001 10001 10101 10010 01101",
+	'R': "11110 10001 10001 11110 10100 10010 10001",
+	'S': "01111 10000 10000 01110 00001 00001 11110",
+	'T': "11111 00100 00100 00100 00100 00100 00100",
+	'U': "10001 10001 10001 10001 10001 10001 01110",
+	'V': "10001 10001 10001 10001 10001 01010 00100",
+	'W': "10001 10001 10001 10101 10101 10101 01010",
+	'X': "10001 10001 01010 00100 01010 10001 10001",
+	'Y': "10001 10001 10001 01010 00100 00100 00100",
+	'Z': "11111 00001 00010 00100 01000 10000 11111",
+	'-': "00000 00000 00000 11111 00000 00000 00000",
+	':': "00000 01100 01100 00000 01100 01100 00000",
+	'.': "00000 00000 00000 00000 00000 01100 01100",
+	',': "00000 00000 00000 00000 01100 00100 01000",
+	'/': "00000 00001 00010 00100 01000 10000 00000",
+	'(': "00010 00100 01000 01000 01000 00100 00010",
+	')': "01000 00100 00010 00010 00010 00100 01000",
+	'%': "11000 11001 00010 00100 01000 10011 00011",
+}
+
+// pixelAdvance is the width of a glyph and the space after it:
+const pixelAdvance = 6
+
+// drawText draws s from x, y at its top left, in the pixel font:
+func drawText(img *image.RGBA, x int, y int, s string, c color.Color) {
+	for _, r := range strings.ToUpper(s) {
+		rows := strings.Fields(pixelFont[unicode.ToUpper(r)])
+		for dy, row := range rows {
+			for dx, bit := range row {
+				if bit == '1' {
+					img.Set(x+dx, y+dy, c)
+				}
+			}
+		}
+		x += pixelAdvance
+	}
+}
+
+func fillRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+// rgb converts a chart color to RGB:
+func (c chartColor) rgb() color.RGBA {
+	const s, l = 0.6, 0.65
+	h := float64(c.Hue) / 60
+	chroma := (1 - math.Abs(2*l-1)) * s
+	x := chroma * (1 - math.Abs(math.Mod(h, 2)-1))
+	var r, g, b float64
+	switch {
+	case h < 1:
+		r, g, b = chroma, x, 0
+	case h < 2:
+		r, g, b = x, chroma, 0
+	case h < 3:
+		r, g, b = 0, chroma, x
+	case h < 4:
+		r, g, b = 0, x, chroma
+	case h < 5:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	m := l - chroma/2
+	channel := func(v float64) uint8 { return uint8(math.Round((v + m) * 255)) }
+	return color.RGBA{channel(r), channel(g), channel(b), 255}
+}
+
+func (pngChart) RenderGantt(w io.Writer, c *ganttChart) error {
+	statuses, colors := c.statusColors()
+	from, to := c.span()
+	span := to.Sub(from)
+	if span <= 0 {
+		span = time.Hour
+	}
+	x := func(t time.Time) int {
+		return timelineLabelWidth + int(float64(timelineChartWidth)*float64(t.Sub(from))/float64(span))
+	}
+
+	chartBottom := timelineTop + len(c.Rows)*timelineRowHeight
+	height := chartBottom + 30 + 16*len(statuses) + 20
+	width := timelineLabelWidth + timelineChartWidth + 20
+	labelChars := (timelineLabelWidth - 8) / pixelAdvance
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fillRect(img, img.Bounds(), color.White)
+	black := color.RGBA{0x17, 0x2b, 0x4d, 255}
+	grey := color.RGBA{0x66, 0x66, 0x66, 255}
+	drawText(img, 4, 10, c.Title, black)
+
+	// Week gridlines on Mondays:
+	for _, t := range mondays(from, to) {
+		fillRect(img, image.Rect(x(t), timelineTop-4, x(t)+1, chartBottom), color.RGBA{0xdd, 0xdd, 0xdd, 255})
+		drawText(img, x(t)+2, chartBottom+6, t.Format("Jan 02"), grey)
+	}
+
+	for r, row := range c.Rows {
+		y := timelineTop + r*timelineRowHeight
+		drawText(img, 4, y+8, truncateWidth(row.Key+" "+row.Summary, labelChars), black)
+		for _, in := range row.Intervals {
+			end := in.End
+			if end.IsZero() {
+				end = c.Now
+			}
+			right := x(end)
+			if right <= x(in.Start) {
+				right = x(in.Start) + 1
+			}
+			fillRect(img, image.Rect(x(in.Start), y+3, right, y+timelineRowHeight-3), colors[in.Status].rgb())
+		}
+	}
+
+	for i, status := range statuses {
+		y := chartBottom + 30 + 16*i
+		fillRect(img, image.Rect(timelineLabelWidth, y, timelineLabelWidth+12, y+12), colors[status].rgb())
+		drawText(img, timelineLabelWidth+18, y+3, status, black)
+	}
+	if c.Footer != "" {
+		drawText(img, 4, height-12, truncateWidth(c.Footer, (width-8)/pixelAdvance), grey)
+	}
+	return png.Encode(w, img)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// vegaLiteChart writes a Vega-Lite spec with the data inline, for notebooks,
+// dashboards and anything else that renders Vega-Lite:
+type vegaLiteChart struct{}
+
+// vegaLiteInterval is one bar of the gantt chart:
+type vegaLiteInterval struct {
+	Row     string `json:"row"`
+	Key     string `json:"key"`
+	Summary string `json:"summary"`
+	Status  string `json:"status"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+	Active  bool   `json:"active"`
+}
+
+func (vegaLiteChart) RenderGantt(w io.Writer, c *ganttChart) error {
+	values := []vegaLiteInterval{}
+	for _, row := range c.Rows {
+		for _, in := range row.Intervals {
+			end := in.End
+			if end.IsZero() {
+				end = c.Now
+			}
+			values = append(values, vegaLiteInterval{
+				Row:     row.Key + " " + truncateWidth(row.Summary, 30),
+				Key:     row.Key,
+				Summary: row.Summary,
+				Status:  in.Status,
+				Start:   displayTime(in.Start).Format(time.RFC3339),
+				End:     displayTime(end).Format(time.RFC3339),
+				Active:  in.End.IsZero(),
+			})
+		}
+	}
+
+	spec := map[string]interface{}{
+		"$schema": "https://vega.github.io/schema/vega-lite/v5.json",
+		"title":   c.Title,
+		"width":   timelineChartWidth,
+		"data":    map[string]interface{}{"values": values},
+		"mark":    "bar",
+		"encoding": map[string]interface{}{
+			// Rows keep their order, by start of work:
+			"y":     map[string]interface{}{"field": "row", "type": "nominal", "sort": nil, "title": nil},
+			"x":     map[string]interface{}{"field": "start", "type": "temporal", "title": nil},
+			"x2":    map[string]interface{}{"field": "end"},
+			"color": map[string]interface{}{"field": "status", "type": "nominal", "title": "status"},
+			"tooltip": []map[string]interface{}{
+				{"field": "key"},
+				{"field": "status"},
+				{"field": "start", "type": "temporal", "format": "%a %b %d %H:%M"},
+				{"field": "end", "type": "temporal", "format": "%a %b %d %H:%M"},
+			},
+		},
+	}
+	if c.Footer != "" {
+		spec["description"] = c.Footer
+	}
+	b, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,735 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type command struct {
+	Name string
+	Args string
+	Help string
+	Run  func(args []string) error
+
+	// NoFooter omits the data freshness footer, for commands that fetch
+	// nothing or embed it in their own output:
+	NoFooter bool
+
+	// Memoize replays the previous output when the report is run again on
+	// unchanged data; only for reports without side effects whose output
+	// changes by the day, not the hour (aging shows hours for fresh items):
+	Memoize bool
+
+	// Complete returns candidates for the next positional argument given the
+	// arguments already typed:
+	Complete func(args []string) []string
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{
+			Name:     "aging",
+			Args:     "[-jql filter] [-sla days] [-watchers] [-histogram] [-benchmarks [-benchmark-jql filter]] [boardId]",
+			Help:     "list in-flight issues per status with their age in business days (default)",
+			Run:      runAging,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "anomalies",
+			Args:     "[-days n] [-window n] [-threshold sigma] [-jql filter] [boardId]",
+			Help:     "flag unusual daily WIP, throughput and status ages by rolling z-score",
+			Run:      runAnomalies,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "audit",
+			Args:     "list [-since date] [-key KEY] [-rule r] | revert-labels [-since date] [-key KEY] [-rule r] [-post]",
+			Help:     "review the comments, labels and transitions made in JIRA, or remove the labels added",
+			Run:      runAudit,
+			NoFooter: true,
+			Complete: subcommandCompleter(auditSubcommands),
+		},
+		{
+			Name:     "backfill",
+			Args:     "-since yyyy-mm-dd [-store file] [-pause duration] [boardId]",
+			Help:     "store the board's resolved issues month by month since a date, so forecast and predictability have deep history",
+			Run:      runBackfill,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "backlog",
+			Args:     "[-jql filter] [boardId]",
+			Help:     "list backlog issues with their age since created",
+			Run:      runBacklog,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "boards",
+			Args:     "list [-name text] [-project key] [-type scrum|kanban]",
+			Help:     "list the boards you can see, to find a board ID",
+			Run:      runBoards,
+			NoFooter: true,
+			Complete: subcommandCompleter(boardsSubcommands),
+		},
+		{
+			Name:     "bugs",
+			Args:     "[-jql filter] [boardId]",
+			Help:     "report open bugs, oldest critical bug and mean time to resolve by severity",
+			Run:      runBugs,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "cache",
+			Args:     "ls [-board id] [-kind kind] [-stale] [-l] | stats",
+			Help:     "list cached responses with their board, query hash, age and size, or summarize the cache",
+			Run:      runCacheCommand,
+			NoFooter: true,
+			Complete: subcommandCompleter(cacheSubcommands),
+		},
+		{
+			Name:     "cd3",
+			Args:     "[-weeks n] [-percentile p] [-jql filter] [boardId]",
+			Help:     "order open epics by cost of delay divided by forecast duration (CD3) to suggest sequencing",
+			Run:      runCD3,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "cohorts",
+			Args:     "[-jql filter] [boardId]",
+			Help:     "compare cycle times of completed issues grouped by month started",
+			Run:      runCohorts,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "compare-boards",
+			Args:     "[-days n] boardId boardId...",
+			Help:     "compare WIP, aging, cycle time and throughput across boards side by side",
+			Run:      runCompareBoards,
+			Memoize:  true,
+			Complete: completeAllBoardIds,
+		},
+		{
+			Name:     "compare-periods",
+			Args:     "-before from..to -after from..to [-alpha level] [-jql filter] [boardId]",
+			Help:     "test whether cycle times changed significantly between two periods, e.g. before and after a process change",
+			Run:      runComparePeriods,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "completion",
+			Args:     "bash|zsh|fish",
+			Help:     "print a shell completion script",
+			Run:      runCompletion,
+			NoFooter: true,
+			Complete: completeShells,
+		},
+		{
+			Name:     "config",
+			Args:     "validate [boardId]",
+			Help:     "check credentials, board access, JQL, custom fields and status names in the config against the instance",
+			Run:      runConfigCommand,
+			NoFooter: true,
+			Complete: subcommandCompleter(configSubcommands),
+		},
+		{
+			Name:     "context-switching",
+			Args:     "[-weeks n] [-jql filter] [boardId]",
+			Help:     "estimate how many distinct issues each person touches per day and week",
+			Run:      runContextSwitching,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "digest",
+			Args:     "[-format markdown|html|slack] [-weeks n] [-sla days] [-watchers] [-jql filter] [boardId]",
+			Help:     "summarize the past week against previous weeks with written highlights",
+			Run:      runDigest,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "epic",
+			Args:     "rollup [-weeks n] [-jql filter] [boardId] | sizing [-weeks n] [-sprint-weeks n] [-max sprints] [-jql filter] [boardId] | timeline [-format mermaid|svg|png|vega-lite] [-o file] epicKey [boardId]",
+			Help:     "project open epics' completion from their burn rate, size epics in sprints of velocity, or chart how an epic unfolded as a Gantt of its children's status intervals",
+			Run:      runEpic,
+			NoFooter: true,
+			Complete: subcommandCompleter(epicSubcommands),
+		},
+		{
+			Name:     "fixtures",
+			Args:     "capture [-dir path] [-max n] [-jql filter] [boardId]",
+			Help:     "record anonymized responses from the instance as fixtures for tests and bug reports",
+			Run:      runFixtures,
+			NoFooter: true,
+			Complete: subcommandCompleter(fixturesSubcommands),
+		},
+		{
+			Name:     "forecast",
+			Args:     "[-jql remaining] [-name label] [-weeks n] [-trials n] [-log file] [boardId]",
+			Help:     "forecast when remaining work completes by Monte Carlo simulation, recording the forecast",
+			Run:      runForecast,
+			Complete: completeBoardIds,
+		},
+		{
+			Name: "forecast-accuracy",
+			Args: "[-log file]",
+			Help: "compare recorded forecasts with actual delivery dates",
+			Run:  runForecastAccuracy,
+		},
+		{
+			Name:     "handoffs",
+			Args:     "[-min n] [-jql filter] [boardId]",
+			Help:     "list issues reassigned from person to person at least -min times and the average handoffs per completed issue",
+			Run:      runHandoffs,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "heatmap",
+			Args:     "[-o file.html] [-jql filter] [boardId]",
+			Help:     "render an HTML heatmap of max issue age by assignee and status",
+			Run:      runHeatmap,
+			NoFooter: true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "hierarchy",
+			Args:     "[-depth n] [-jql filter] [boardId]",
+			Help:     "roll up initiatives, epics, stories and subtasks as an indented tree with completion and age per item and per level",
+			Run:      runHierarchy,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "incidents",
+			Args:     "[-jql filter] [boardId]",
+			Help:     "compare cycle time of work driven by incidents or customer tickets with planned work",
+			Run:      runIncidents,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "init",
+			Args:     "[-o file] [-name profile] [-force]",
+			Help:     "ask for the instance, credentials and board, then write a starter config with discovered fields and status mappings",
+			Run:      runInit,
+			NoFooter: true,
+		},
+		{
+			Name:     "managers",
+			Args:     "[-weeks n] [-jql filter] [boardId]",
+			Help:     "roll up WIP, oldest item and throughput of each manager's reports",
+			Run:      runManagers,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "meta",
+			Args:     "statuses | fields [-all] | issuetypes",
+			Help:     "list the instance's statuses with categories, custom fields or issue types, for writing config",
+			Run:      runMeta,
+			NoFooter: true,
+			Complete: subcommandCompleter(metaSubcommands),
+		},
+		{
+			Name:     "notes",
+			Args:     "add KEY text | list [KEY] | remove KEY [n]",
+			Help:     "attach local notes to issues, shown in reports without putting them in JIRA",
+			Run:      runNotes,
+			NoFooter: true,
+			Complete: subcommandCompleter(notesSubcommands),
+		},
+		{
+			Name:     "nudge",
+			Args:     "[-post] [-labels] [-transitions] [-days n] [-jql filter] [boardId]",
+			Help:     "comment on items over their status threshold and write back labels or transitions; previews unless -post",
+			Run:      runNudge,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "overload",
+			Args:     "[-weeks n] [-jql filter] [boardId]",
+			Help:     "opt-in team aggregates of WIP over limit, after-hours and weekend activity as overload early warnings",
+			Run:      runOverload,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "pace",
+			Args:     "[-weeks n] [-jql filter] [boardId]",
+			Help:     "team aggregates of transitions and comments made after hours, on weekends and on holidays, for sustainable-pace discussions",
+			Run:      runPace,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "predictability",
+			Args:     "[-jql filter] [-weeks n] [-window n] [boardId]",
+			Help:     "score delivery predictability from cycle time spread, with weekly trend",
+			Run:      runPredictability,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "priority",
+			Args:     "[-jql filter] [boardId]",
+			Help:     "compare cycle time by priority and list lower-priority work that overtook higher",
+			Run:      runPriority,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "rank",
+			Args:     "[-min n] [-jql filter] [boardId]",
+			Help:     "check the board is worked top-down, listing in-flight items ranked below un-started higher-ranked items",
+			Run:      runRank,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "reestimates",
+			Args:     "[-jql filter] [boardId]",
+			Help:     "report story point changes made after work started, per assignee and epic",
+			Run:      runReestimates,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "resolutions",
+			Args:     "[-weeks n] [-monthly] [-jql filter] [boardId]",
+			Help:     "break completed work down by resolution per week or month, separating delivered work from Won't Do and duplicates",
+			Run:      runResolutions,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "retro",
+			Args:     "[-sprint id] [-format markdown|html] [boardId]",
+			Help:     "bundle a sprint's metrics, churn, carry-over, longest cycle times and most blocked items into a retro pack",
+			Run:      runRetro,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "review-wait",
+			Args:     "[-jql filter] [boardId]",
+			Help:     "report time from entering review to the next transition, per week",
+			Run:      runReviewWait,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "rolling",
+			Args:     "[-windows 7,30,90] [-jql filter] [boardId]",
+			Help:     "compare throughput, cycle time and bug ratio over the last 7, 30 and 90 days side by side",
+			Run:      runRolling,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "run",
+			Args:     "[-list] [job...]",
+			Help:     "run report jobs defined in config, all of them by default",
+			Run:      runJobs,
+			NoFooter: true,
+			Complete: completeJobs,
+		},
+		{
+			Name:     "self-update",
+			Args:     "[-check]",
+			Help:     "replace this binary with the latest GitHub release, verified against its checksums",
+			Run:      runSelfUpdate,
+			NoFooter: true,
+		},
+		{
+			Name:     "serve",
+			Args:     "[-addr host:port] [-reload interval] [-jobs]",
+			Help:     "serve reports over HTTP, including aging feeds for Jira dashboard gadgets",
+			Run:      runServe,
+			NoFooter: true,
+		},
+		{
+			Name:     "sprint",
+			Args:     "health [-weeks n] [boardId] | goals [-sprints n] [boardId] | starts [-sprints n] [-days n] [boardId]",
+			Help:     "check the active sprint: progress vs time elapsed, unstarted, added and at-risk items; recent sprint goals and how much of each was done; or how far into recent sprints committed items actually started",
+			Run:      runSprint,
+			Memoize:  true,
+			Complete: subcommandCompleter(sprintSubcommands),
+		},
+		{
+			Name:     "tags",
+			Args:     "[-jql filter] [boardId]",
+			Help:     "summarize open age and cycle time per summary tag",
+			Run:      runTags,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "teams",
+			Args:     "[-jql filter] [boardId]",
+			Help:     "summarize WIP, oldest item and cycle time per configured team",
+			Run:      runTeams,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "verify-cache",
+			Help:     "check cached responses and stored history decode, with sane timestamps and complete changelogs, without fetching",
+			Run:      runVerifyCache,
+			NoFooter: true,
+		},
+		{
+			Name:     "version",
+			Help:     "print the version and build details",
+			Run:      runVersion,
+			NoFooter: true,
+		},
+		{
+			Name:     "wait-time",
+			Args:     "[-jql filter] [boardId]",
+			Help:     "report time spent in queue vs work statuses as a share of lead time",
+			Run:      runWaitTime,
+			Memoize:  true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name:     "whoami",
+			Args:     "[boardId]",
+			Help:     "check the account can browse the board's projects, read changelogs and use the Agile API, naming any permission missing",
+			Run:      runWhoami,
+			NoFooter: true,
+			Complete: completeBoardIds,
+		},
+		{
+			Name: "help",
+			Help: "show this help",
+			Run: func(args []string) error {
+				usage()
+				return nil
+			},
+			NoFooter: true,
+		},
+	}
+}
+
+// config is the loaded config file, available to all commands:
+var config = &Config{}
+
+var profileName string
+
+// groupBy is the -group-by dimension, "team" or "tag":
+var groupBy string
+
+var globalFlagSet *flag.FlagSet
+
+// activeProfile is the selected config profile, if any:
+var activeProfile *Profile
+
+func programName() string {
+	return filepath.Base(os.Args[0])
+}
+
+func findCommand(name string) *command {
+	for _, cmd := range commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// boardArg parses the optional board ID positional argument, defaulting to
+// JIRA_BOARDID:
+func boardArg(args []string) int {
+	if len(args) >= 1 {
+		intValue, err := strconv.Atoi(args[0])
+		if err == nil {
+			return intValue
+		}
+	}
+	return defaultBoardId()
+}
+
+func usage() {
+	out := os.Stderr
+	fmt.Fprintf(out, "usage: %s [flags] <command> [args]\n", programName())
+	if globalFlagSet != nil {
+		fmt.Fprintf(out, "\nflags:\n")
+		globalFlagSet.SetOutput(out)
+		globalFlagSet.PrintDefaults()
+	}
+	fmt.Fprintf(out, "\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %s %s\n        %s\n", cmd.Name, cmd.Args, cmd.Help)
+	}
+	fmt.Fprint(out, `
+environment variables:
+JIRA_URL      = base URL of JIRA website without trailing slash
+JIRA_USERNAME = username to authenticate with
+JIRA_PASSWORD = password to authenticate with
+
+JIRA_BOARDID  = board ID to query status of
+JIRA_JQL      = custom JQL filter to apply; default='status not in (closed, canceled, open, reopened, Analysis, "Analysis - 1")'
+JIRA_PERCENTILES = comma-separated percentiles to report; default=50,85,95
+JIRA_NOW      = fixed report time (RFC 3339) for reproducible output
+JIRA_PROFILE  = config profile to use when -profile is not given
+JIRA_BACKEND  = tracker to read from: jira (default), azure, github or linear
+JIRA_FILE     = JSON or CSV export to read issues from instead of the API
+JIRA_FILTER   = saved filter ID whose issues to analyze instead of the board's
+JIRA_VIEW_AS  = user whose visibility to check reports against when running as a service account
+JIRA_CACHE_KEY = passphrase to encrypt cached responses and forecasts with (or JIRA_CACHE_KEY_FILE, or JIRA_CACHE_KEYRING naming a keyring entry)
+JIRA_DEBUG_DIR = where responses that fail to decode are saved; default=./jira-debug
+JIRA_MAX_REQUESTS = API calls allowed per run before aborting; default=unlimited
+CONFLUENCE_URL = Confluence base URL for confluence output; default=$JIRA_URL/wiki
+CONFLUENCE_USERNAME, CONFLUENCE_TOKEN = Confluence credentials for confluence output
+JIRA_CONFIG   = path to config file; default=./jira-analysis.json or <user config dir>/jira-analysis/config.json
+`)
+}
+
+func runCLI(args []string) error {
+	fs := flag.NewFlagSet(programName(), flag.ContinueOnError)
+	fs.StringVar(&profileName, "profile", os.Getenv("JIRA_PROFILE"), "config profile to use")
+	fs.Var(&includeTags, "tag", "only include issues with these summary tags (comma-separated)")
+	fs.Var(&excludeTags, "exclude-tag", "exclude issues with these summary tags (comma-separated)")
+	fs.StringVar(&teamFilter, "team", "", "only include issues assigned to members of this team")
+	fs.StringVar(&groupBy, "group-by", "", "run the report once per 'team' or 'tag'")
+	fs.StringVar(&inputFile, "file", os.Getenv("JIRA_FILE"), "read issues from a JSON, CSV or XML export (- for stdin) instead of the JIRA API")
+	fs.IntVar(&filterFlag, "filter", 0, "analyze the issues of this saved filter ID instead of a board")
+	fs.Var(&issueKeys, "keys", "analyze just these issue keys (comma-separated, or - to read from stdin) instead of a board")
+	fs.StringVar(&verbosityFlag, "verbosity", "", "report detail: developer, lead or exec")
+	fs.IntVar(&limitFlag, "limit", 0, "list at most this many items per report section, noting how many more there are")
+	fs.BoolVar(&includeMigrated, "include-migrated", false, "count issues whose history looks migrated, with gaps, in percentiles")
+	fs.IntVar(&widthFlag, "width", 0, "fit report lines to this many columns; default the terminal's, -1 for no limit")
+	asOfFlag := fs.String("as-of", "", "report as of the end of this past date (yyyy-mm-dd), rewinding issues from their changelogs")
+	fs.Var(&outputFlags, "output", "send output to terminal, json (stdout), file:<path>, slack:<webhook url>, sheets:<spreadsheet id>[/<sheet>] or confluence:<space key>; repeatable")
+	fs.Usage = usage
+	globalFlagSet = fs
+	err := fs.Parse(args)
+	if err == flag.ErrHelp {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	args = fs.Args()
+
+	// Completion scripts call back into the binary; never fail loudly there:
+	if len(args) >= 1 && args[0] == "__complete" {
+		if c, err := loadConfig(); err == nil {
+			config = c
+		}
+		for _, candidate := range completeWords(args[1:]) {
+			fmt.Println(candidate)
+		}
+		return nil
+	}
+
+	config, err = loadConfig()
+	if err != nil {
+		return err
+	}
+	if *asOfFlag != "" {
+		if asOf, err = parseAsOf(*asOfFlag); err != nil {
+			return err
+		}
+		defer func() { asOf = time.Time{} }()
+	}
+
+	enableProgress()
+	if pipeMode() {
+		defer quietLogs()()
+	}
+
+	users.AddAliases(config.UserAliases)
+
+	activeProfile, err = config.Profile(profileName)
+	if err != nil {
+		return err
+	}
+	if activeProfile != nil {
+		activeProfile.apply()
+	}
+
+	reportVerbosity, err = resolveVerbosity()
+	if err != nil {
+		return err
+	}
+	detailLimit, err = resolveLimit()
+	if err != nil {
+		return err
+	}
+
+	if os.Getenv("JIRA_URL") == "" {
+		os.Setenv("JIRA_URL", "https://ultidev")
+	}
+
+	// Default to the aging report so `jira-analysis [boardId]` keeps working:
+	cmd := findCommand("aging")
+	if len(args) > 0 {
+		if c := findCommand(args[0]); c != nil {
+			cmd = c
+			args = args[1:]
+		} else if _, err := strconv.Atoi(args[0]); err != nil {
+			usage()
+			return fmt.Errorf("unknown command '%s'", args[0])
+		}
+	}
+
+	groups, err := outputSinks()
+	if err != nil {
+		return err
+	}
+
+	defer flushCacheIndex()
+	defer func() {
+		if fetched.Calls > 0 {
+			log.Printf("api: %s\n", fetched.usage())
+		}
+		if c := connections.Load(); c.New+c.Reused > 0 {
+			log.Printf("http: %s\n", &connections)
+		}
+	}()
+
+	run := func() error {
+		// Notes and acknowledgments are read afresh each report, as part of
+		// its snapshot:
+		reportNotes, reportAcks = nil, nil
+		err := runGrouped(cmd, args)
+		if err != nil {
+			return err
+		}
+
+		if !cmd.NoFooter {
+			if footer := reportFooter(); footer != "" {
+				fmt.Printf("\n%s\n", footer)
+			}
+		}
+		return nil
+	}
+	if len(groups) == 0 {
+		fit, err := fitted(cmd, memoized(cmd, args, run))
+		if err != nil {
+			return err
+		}
+		return fit()
+	}
+
+	// The report is rendered once per recipient timezone, making its changes
+	// only the first time:
+	firstReads = make(map[string]stateRead)
+	defer func() { reportLocation, rerendering, firstReads = nil, false, nil }()
+	start := fetched
+	var failures []string
+	for i, group := range groups {
+		if i > 0 {
+			// Each rendering's footer describes one fetch; API usage adds up:
+			usage := fetched
+			fetched = start
+			fetched.Calls, fetched.Bytes, fetched.Elapsed = usage.Calls, usage.Bytes, usage.Elapsed
+		}
+		reportLocation = group.location
+		rerendering = i > 0
+
+		reportMetrics = make(map[string]interface{})
+		text, err := captureOutput(memoized(cmd, args, run))
+		if err != nil {
+			reportMetrics = nil
+			return err
+		}
+		out := &reportOutput{
+			Command:   cmd.Name,
+			Args:      args,
+			Generated: displayTime(reportNow()),
+			Text:      text,
+		}
+		if len(reportMetrics) > 0 {
+			out.Metrics = reportMetrics
+		}
+		reportMetrics = nil
+		if err = fanOut(group.sinks, out); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// runSubcommand dispatches the first argument to one of a command's
+// subcommands:
+func runSubcommand(name string, subcommands map[string]func(args []string) error, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s %s %s", programName(), name, strings.Join(sortedKeys(subcommands), "|"))
+	}
+	run, ok := subcommands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown %s subcommand '%s'; expected %s", name, args[0], strings.Join(sortedKeys(subcommands), ", "))
+	}
+	return run(args[1:])
+}
+
+func sortedKeys(subcommands map[string]func(args []string) error) []string {
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runGrouped runs the command once per team or tag when -group-by is given,
+// filtering issues to each group in turn:
+func runGrouped(cmd *command, args []string) error {
+	var groups []string
+	var filter *string
+	switch groupBy {
+	case "":
+		return cmd.Run(args)
+	case "team":
+		groups = config.TeamNames()
+		filter = &teamFilter
+	case "tag":
+		for _, rule := range config.TagRules {
+			groups = append(groups, rule.Name)
+		}
+		includeTags = nil
+	default:
+		return fmt.Errorf("unknown -group-by '%s'; expected team or tag", groupBy)
+	}
+
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("== %s: %s ==\n", groupBy, group)
+
+		if filter != nil {
+			*filter = group
+		} else {
+			includeTags = tagList{group}
+		}
+		metricPrefix = groupBy + ":" + group + "/"
+		err := cmd.Run(args)
+		metricPrefix = ""
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", groupBy, group, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type cohort struct {
+	Month      string
+	CycleTimes []int
+}
+
+// groupCohorts groups completed issues by the month they were started in,
+// ordered by month ascending:
+func groupCohorts(issues []Issue) []*cohort {
+	byMonth := make(map[string]*cohort)
+	for i := range issues {
+		issue := &issues[i]
+
+		if issue.IsEpic() {
+			continue
+		}
+
+		days, ok := issue.CycleTime()
+		if !ok {
+			continue
+		}
+
+		started, _ := issue.StartedTime()
+		month := started.Format("2006-01")
+		c, ok := byMonth[month]
+		if !ok {
+			c = &cohort{Month: month}
+			byMonth[month] = c
+		}
+		c.CycleTimes = append(c.CycleTimes, days)
+	}
+
+	cohorts := make([]*cohort, 0, len(byMonth))
+	for _, c := range byMonth {
+		cohorts = append(cohorts, c)
+	}
+	sort.Slice(cohorts, func(i, j int) bool {
+		return cohorts[i].Month < cohorts[j].Month
+	})
+	return cohorts
+}
+
+func runCohorts(args []string) error {
+	fs := flag.NewFlagSet("cohorts", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting completed issues; default='statusCategory = Done AND resolved >= -365d'")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("cohorts", *jql, "statusCategory = Done AND resolved >= -365d"))
+	if err != nil {
+		return err
+	}
+
+	cohorts := groupCohorts(issues)
+	changes := detectWorkflowChanges(issues)
+
+	fmt.Printf("Cycle time in business days by month started:\n")
+	fmt.Printf("  %-7s %5s %4s", "month", "count", "min")
+	for _, p := range percentiles {
+		fmt.Printf(" %5s", percentileLabel(p))
+	}
+	fmt.Printf(" %4s %6s %10s %10s\n", "max", "mean", "p50 95% CI", "p50 change")
+
+	prevMedian := -1
+	small := false
+	for _, c := range cohorts {
+		sorted := sortedCopy(c.CycleTimes)
+		median := percentile(sorted, 50)
+
+		delta := ""
+		if prevMedian >= 0 {
+			delta = fmt.Sprintf("%+d", median-prevMedian)
+		}
+		prevMedian = median
+
+		if len(sorted) < smallSample {
+			small = true
+		}
+		fmt.Printf("  %-7s %5s %4d", c.Month, sampleSize(len(sorted)), sorted[0])
+		for _, p := range percentiles {
+			fmt.Printf(" %5d", percentile(sorted, p))
+		}
+		fmt.Printf(" %4d %6.1f %10s %10s", sorted[len(sorted)-1], mean(sorted), formatInterval(percentileInterval(sorted, 50)), delta)
+		if month, err := time.ParseInLocation("2006-01", c.Month, time.Local); err == nil && workflowChangedDuring(changes, month, month.AddDate(0, 1, 0)) {
+			fmt.Print(workflowChangeMark)
+		}
+		fmt.Println()
+	}
+	if small {
+		fmt.Println(smallSampleNote())
+	}
+	printWorkflowChanges(changes)
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// completedIssue builds a done issue that entered In Progress at started and
+// was resolved at resolved:
+func completedIssue(key string, started, resolved time.Time) Issue {
+	issue := Issue{Key: key}
+	issue.Fields.Status.StatusCategory.Key = "done"
+	issue.Fields.ResolutionDate.Time = resolved
+	issue.Changelog.Histories = []History{
+		{
+			Created: zonedTimestamp{started},
+			Items:   []HistoryItem{{Field: "status", ToString: inProgressStatus}},
+		},
+	}
+	return issue
+}
+
+func TestGroupCohorts_ByMonthStarted(t *testing.T) {
+	issues := []Issue{
+		completedIssue("A-1", time.Date(2018, 11, 5, 9, 0, 0, 0, cst), time.Date(2018, 11, 7, 9, 0, 0, 0, cst)),
+		completedIssue("A-2", time.Date(2018, 10, 1, 9, 0, 0, 0, cst), time.Date(2018, 10, 8, 9, 0, 0, 0, cst)),
+		completedIssue("A-3", time.Date(2018, 11, 30, 9, 0, 0, 0, cst), time.Date(2018, 12, 3, 9, 0, 0, 0, cst)),
+		// not started:
+		{Key: "A-4"},
+	}
+
+	cohorts := groupCohorts(issues)
+	if len(cohorts) != 2 {
+		t.Fatalf("expected 2 for cohorts, got %d", len(cohorts))
+	}
+	if cohorts[0].Month != "2018-10" || len(cohorts[0].CycleTimes) != 1 || cohorts[0].CycleTimes[0] != 5 {
+		t.Fatalf("expected 2018-10 cohort with [5], got %s %v", cohorts[0].Month, cohorts[0].CycleTimes)
+	}
+	if cohorts[1].Month != "2018-11" || len(cohorts[1].CycleTimes) != 2 {
+		t.Fatalf("expected 2018-11 cohort with 2 issues, got %s %v", cohorts[1].Month, cohorts[1].CycleTimes)
+	}
+}
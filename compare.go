@@ -0,0 +1,151 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type boardMetrics struct {
+	BoardId    int
+	WIP        int
+	Ages       []int
+	CycleTimes []int
+	Completed  int
+	Weeks      float64
+}
+
+func (m *boardMetrics) Throughput() float64 {
+	if m.Weeks == 0 {
+		return 0
+	}
+	return float64(m.Completed) / m.Weeks
+}
+
+// measureBoard gathers WIP, aging, cycle time and throughput for a board over
+// the trailing window of days:
+func measureBoard(cl *http.Client, boardId int, days int, now time.Time) (*boardMetrics, error) {
+	m := &boardMetrics{BoardId: boardId, Weeks: float64(days) / 7}
+
+	issues, err := fetchBoardIssues(cl, boardId, reportJQL("aging", "", defaultJQL()))
+	if err != nil {
+		return nil, err
+	}
+	issues = excludeBacklog(cl, boardId, issues)
+
+	for _, statusIssues := range computeAging(issues, DateOf(now)) {
+		for _, issue := range statusIssues {
+			if issue.Fields.Status.StatusCategory.Key == "done" {
+				continue
+			}
+			m.WIP++
+			m.Ages = append(m.Ages, issue.StatusBusinessDays)
+		}
+	}
+
+	completed, err := fetchBoardIssues(cl, boardId, reportJQL("compare-boards", "", fmt.Sprintf("statusCategory = Done AND resolved >= -%dd", days)))
+	if err != nil {
+		return nil, err
+	}
+	for i := range completed {
+		issue := &completed[i]
+		if issue.IsEpic() {
+			continue
+		}
+		if _, ok := issue.CompletedTime(); !ok {
+			continue
+		}
+		m.Completed++
+
+		if days, ok := issue.CycleTime(); ok {
+			m.CycleTimes = append(m.CycleTimes, days)
+		}
+	}
+
+	return m, nil
+}
+
+func runCompareBoards(args []string) error {
+	fs := flag.NewFlagSet("compare-boards", flag.ContinueOnError)
+	days := fs.Int("days", 90, "trailing window in days for cycle time and throughput")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 2 {
+		return fmt.Errorf("usage: %s compare-boards [-days n] boardId boardId...", programName())
+	}
+
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	cl := httpClient()
+	now := reportNow()
+	var boards []*boardMetrics
+	for _, arg := range fs.Args() {
+		boardId, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("invalid board ID '%s'", arg)
+		}
+
+		m, err := measureBoard(cl, boardId, *days, now)
+		if err != nil {
+			return fmt.Errorf("board %d: %w", boardId, err)
+		}
+		boards = append(boards, m)
+	}
+
+	// Columns are by argument position, so a board may be listed twice:
+	row := func(label string, value func(i int) string) {
+		fmt.Printf("  %-22s", label)
+		for i := range boards {
+			fmt.Printf(" %10s", value(i))
+		}
+		fmt.Println()
+	}
+	sortedAges := make([][]int, len(boards))
+	sortedCycleTimes := make([][]int, len(boards))
+	for i, m := range boards {
+		sortedAges[i] = sortedCopy(m.Ages)
+		sortedCycleTimes[i] = sortedCopy(m.CycleTimes)
+	}
+
+	fmt.Printf("Board comparison as of %s (cycle time and throughput over %d days):\n", displayTime(now).Format("Mon Jan 02 2006"), *days)
+	row("board", func(i int) string { return strconv.Itoa(boards[i].BoardId) })
+	row("WIP", func(i int) string { return strconv.Itoa(boards[i].WIP) })
+	for _, p := range percentiles {
+		p := p
+		row("WIP age "+percentileLabel(p), func(i int) string {
+			return strconv.Itoa(percentile(sortedAges[i], p))
+		})
+	}
+	row("completed", func(i int) string { return strconv.Itoa(boards[i].Completed) })
+	row("throughput / week", func(i int) string { return fmt.Sprintf("%.1f", boards[i].Throughput()) })
+	row("  95% CI", func(i int) string {
+		lo, hi := rateInterval(boards[i].Completed, boards[i].Weeks)
+		return fmt.Sprintf("%.1f–%.1f", lo, hi)
+	})
+	for _, p := range percentiles {
+		p := p
+		row("cycle time "+percentileLabel(p), func(i int) string {
+			return strconv.Itoa(percentile(sortedCycleTimes[i], p))
+		})
+	}
+	row("cycle time p50 95% CI", func(i int) string {
+		return formatInterval(percentileInterval(sortedCycleTimes[i], 50))
+	})
+	small := false
+	for _, m := range boards {
+		if len(m.CycleTimes) < smallSample {
+			small = true
+		}
+	}
+	if small {
+		fmt.Printf("Boards completing fewer than %d items have intervals too wide to compare.\n", smallSample)
+	}
+
+	return nil
+}
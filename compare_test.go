@@ -0,0 +1,65 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunCompareBoards_ColumnsByPosition(t *testing.T) {
+	// Board 1 has the fixture issues, board 2 none:
+	srv := fixtureServer(t)
+	defer srv.Close()
+	fixtures := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/board/2/") {
+			w.Write([]byte(`{"total": 0, "issues": []}`))
+			return
+		}
+		fixtures.ServeHTTP(w, r)
+	})
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	for key, value := range map[string]string{
+		"JIRA_URL":     srv.URL,
+		"JIRA_NOCACHE": "1",
+		"JIRA_NOW":     "2018-11-06T12:00:00-06:00",
+	} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+	config = &Config{}
+
+	for _, c := range []struct {
+		boards     []string
+		board, wip []string
+	}{
+		{[]string{"1", "2"}, []string{"1", "2"}, []string{"3", "0"}},
+		{[]string{"2", "1"}, []string{"2", "1"}, []string{"0", "3"}},
+		{[]string{"1", "1"}, []string{"1", "1"}, []string{"3", "3"}},
+		{[]string{"1", "2", "1"}, []string{"1", "2", "1"}, []string{"3", "0", "3"}},
+	} {
+		fetched = fetchStats{}
+		out, err := captureOutput(func() error { return runCompareBoards(c.boards) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Rows are labelled in their first 24 columns:
+		rows := make(map[string][]string)
+		for _, line := range strings.Split(out, "\n") {
+			if len(line) > 24 {
+				rows[strings.TrimSpace(line[:24])] = strings.Fields(line[24:])
+			}
+		}
+		if strings.Join(rows["board"], " ") != strings.Join(c.board, " ") || strings.Join(rows["WIP"], " ") != strings.Join(c.wip, " ") {
+			t.Errorf("%v: expected boards %v with WIP %v, got:\n%s", c.boards, c.board, c.wip, out)
+		}
+	}
+}
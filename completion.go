@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const bashCompletion = `# bash completion for %[1]s
+_%[2]s() {
+	local cur="${COMP_WORDS[COMP_CWORD]}"
+	COMPREPLY=( $(compgen -W "$(%[1]s __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null)" -- "$cur") )
+}
+complete -F _%[2]s %[1]s
+`
+
+const zshCompletion = `#compdef %[1]s
+_%[2]s() {
+	local -a candidates
+	candidates=(${(f)"$(%[1]s __complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+	compadd -a candidates
+}
+compdef _%[2]s %[1]s
+`
+
+const fishCompletion = `function __%[2]s_complete
+	set -l words (commandline -opc)
+	set -e words[1]
+	set -l cur (commandline -ct)
+	%[1]s __complete $words "$cur" 2>/dev/null
+end
+complete -c %[1]s -f -a '(__%[2]s_complete)'
+`
+
+var completionScripts = map[string]string{
+	"bash": bashCompletion,
+	"zsh":  zshCompletion,
+	"fish": fishCompletion,
+}
+
+func runCompletion(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s completion bash|zsh|fish", programName())
+	}
+
+	script, ok := completionScripts[args[0]]
+	if !ok {
+		return fmt.Errorf("unsupported shell '%s'", args[0])
+	}
+
+	name := programName()
+	funcName := strings.NewReplacer("-", "_", ".", "_").Replace(name)
+	fmt.Printf(script, name, funcName)
+	return nil
+}
+
+// subcommandCompleter offers a command's subcommands:
+func subcommandCompleter(subcommands map[string]func(args []string) error) func(args []string) []string {
+	return func(args []string) []string {
+		if len(args) == 0 {
+			return sortedKeys(subcommands)
+		}
+		return nil
+	}
+}
+
+func completeShells(args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	return []string{"bash", "fish", "zsh"}
+}
+
+// completeBoardIds offers board IDs for commands taking a single board:
+func completeBoardIds(args []string) []string {
+	if len(args) > 0 {
+		return nil
+	}
+	return completeAllBoardIds(args)
+}
+
+// completeAllBoardIds offers board IDs from config profiles, JIRA_BOARDID and
+// any cached board responses in the working directory.
+func completeAllBoardIds(args []string) []string {
+	ids := map[int]bool{defaultBoardId(): true}
+	for _, profile := range config.Profiles {
+		if profile.BoardId != 0 {
+			ids[profile.BoardId] = true
+		}
+	}
+
+	cached, _ := filepath.Glob("board.*.json")
+	for _, filename := range cached {
+		parts := strings.Split(filename, ".")
+		if id, err := strconv.Atoi(parts[1]); err == nil {
+			ids[id] = true
+		}
+	}
+
+	sorted := make([]int, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Ints(sorted)
+
+	candidates := make([]string, 0, len(sorted))
+	for _, id := range sorted {
+		candidates = append(candidates, strconv.Itoa(id))
+	}
+	return candidates
+}
+
+// globalFlags are the flags accepted before the command; all take a value:
+var globalFlags = []string{"-exclude-tag", "-file", "-filter", "-group-by", "-keys", "-output", "-profile", "-tag", "-team", "-verbosity"}
+
+func isGlobalFlag(arg string) bool {
+	for _, flag := range globalFlags {
+		if arg == flag || arg == "-"+flag {
+			return true
+		}
+	}
+	return false
+}
+
+// completeWords returns completion candidates for the last word given the words
+// typed so far (excluding the program name).
+func completeWords(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	cur := words[len(words)-1]
+	prev := words[:len(words)-1]
+
+	if len(prev) > 0 {
+		switch strings.TrimPrefix(prev[len(prev)-1], "-") {
+		case "-profile", "profile":
+			return filterPrefix(config.ProfileNames(), cur)
+		case "-team", "team":
+			return filterPrefix(config.TeamNames(), cur)
+		case "-group-by", "group-by":
+			return filterPrefix([]string{"tag", "team"}, cur)
+		case "-verbosity", "verbosity":
+			return filterPrefix([]string{"developer", "exec", "lead"}, cur)
+		case "-output", "output":
+			return filterPrefix([]string{"confluence:", "file:", "sheets:", "slack:", "terminal"}, cur)
+		}
+	}
+
+	// Skip global flags preceding the command:
+	i := 0
+	for i < len(prev) && strings.HasPrefix(prev[i], "-") {
+		if isGlobalFlag(prev[i]) {
+			i++
+		}
+		i++
+	}
+
+	if i >= len(prev) {
+		if strings.HasPrefix(cur, "-") {
+			return filterPrefix(globalFlags, cur)
+		}
+
+		names := make([]string, 0, len(commands))
+		for _, cmd := range commands {
+			names = append(names, cmd.Name)
+		}
+		return filterPrefix(names, cur)
+	}
+
+	cmd := findCommand(prev[i])
+	if cmd == nil || cmd.Complete == nil {
+		return nil
+	}
+	return filterPrefix(cmd.Complete(prev[i+1:]), cur)
+}
+
+func filterPrefix(candidates []string, prefix string) []string {
+	filtered := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			filtered = append(filtered, candidate)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompleteWords_Commands(t *testing.T) {
+	candidates := completeWords([]string{"compl"})
+	expected := []string{"completion"}
+	if !reflect.DeepEqual(candidates, expected) {
+		t.Fatalf("expected %v for candidates, got %v", expected, candidates)
+	}
+}
+
+func TestCompleteWords_Profiles(t *testing.T) {
+	config = &Config{Profiles: map[string]*Profile{"work": {}, "home": {}, "other": {}}}
+	defer func() { config = &Config{} }()
+
+	candidates := completeWords([]string{"-profile", ""})
+	expected := []string{"home", "other", "work"}
+	if !reflect.DeepEqual(candidates, expected) {
+		t.Fatalf("expected %v for candidates, got %v", expected, candidates)
+	}
+}
+
+func TestCompleteWords_BoardIds(t *testing.T) {
+	config = &Config{Profiles: map[string]*Profile{"work": {BoardId: 1234}}}
+	defer func() { config = &Config{} }()
+
+	candidates := completeWords([]string{"-profile", "work", "aging", "12"})
+	expected := []string{"1234"}
+	if !reflect.DeepEqual(candidates, expected) {
+		t.Fatalf("expected %v for candidates, got %v", expected, candidates)
+	}
+}
+
+func TestCompleteWords_Shells(t *testing.T) {
+	candidates := completeWords([]string{"completion", ""})
+	expected := []string{"bash", "fish", "zsh"}
+	if !reflect.DeepEqual(candidates, expected) {
+		t.Fatalf("expected %v for candidates, got %v", expected, candidates)
+	}
+}
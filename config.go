@@ -0,0 +1,407 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Profile holds the settings for one JIRA instance and board; any field left
+// blank falls back to the corresponding environment variable.
+type Profile struct {
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	BoardId  int    `json:"boardId"`
+	JQL      string `json:"jql"`
+	// Filter is a saved filter ID whose issues are analyzed instead of the
+	// board's:
+	Filter int `json:"filter"`
+	// ViewAs is the user whose visibility reports should match when the
+	// credentials are a service account's; reports warn of issues they can't
+	// see:
+	ViewAs string `json:"viewAs"`
+	// Merge names other profiles whose issues are analyzed together, each
+	// tagged "source:<profile>"; the other settings are then unused:
+	Merge []string `json:"merge"`
+
+	// Backend is the tracker to read from: "jira" (default), "azure",
+	// "github" or "linear":
+	Backend string `json:"backend"`
+	// Auth is how requests to JIRA authenticate: "basic" (default), "token",
+	// "oauth", "cookie", "mtls" or "command":
+	Auth string `json:"auth"`
+	// ClientCert and ClientKey are the client certificate and its key for
+	// "mtls", when they differ from JIRA_CLIENT_CERT and JIRA_CLIENT_KEY:
+	ClientCert string `json:"clientCert"`
+	ClientKey  string `json:"clientKey"`
+
+	// ReportJQL overrides the JQL filter per report (command name):
+	ReportJQL map[string]string `json:"reportJql"`
+
+	// Verbosity overrides the config file's verbosity for this profile:
+	Verbosity string `json:"verbosity"`
+	// Limit overrides the config file's limit for this profile:
+	Limit int `json:"limit"`
+}
+
+type Config struct {
+	DefaultProfile string              `json:"defaultProfile"`
+	Profiles       map[string]*Profile `json:"profiles"`
+
+	// Verbosity is the default report detail: "developer" (every issue),
+	// "lead" (top items per list) or "exec" (aggregated metrics only):
+	Verbosity string `json:"verbosity"`
+	// Limit caps the items listed per report section, 0 for no limit:
+	Limit int `json:"limit"`
+
+	// Percentiles reported by all metric reports; default 50, 85, 95:
+	Percentiles []float64 `json:"percentiles"`
+
+	// ReportJQL sets the JQL filter per report (command name) for all profiles:
+	ReportJQL map[string]string `json:"reportJql"`
+
+	// Changelog field name of story points; default matches both "Story Points"
+	// (classic) and "Story point estimate" (team-managed):
+	StoryPointsField string `json:"storyPointsField"`
+	// StoryPointsFieldId is the custom field ID holding story points, e.g.
+	// "customfield_10016", to read estimates the changelog doesn't record:
+	StoryPointsFieldId string `json:"storyPointsFieldId"`
+
+	// SeverityField is the custom field ID holding bug severity, e.g.
+	// "customfield_10500"; CriticalSeverities lists the values counted as
+	// critical (default Sev1, Sev2):
+	SeverityField      string   `json:"severityField"`
+	CriticalSeverities []string `json:"criticalSeverities"`
+
+	// ParentLinkField is the custom field ID of Advanced Roadmaps' "Parent
+	// Link", which links epics to initiatives on JIRA Server, for the
+	// hierarchy report:
+	ParentLinkField string `json:"parentLinkField"`
+
+	// CostOfDelayField is the custom field ID holding an issue's cost of delay
+	// per week, e.g. "customfield_11200"; CostOfDelay sets it per issue key
+	// instead, e.g. {"ABC-10": 5000}, taking precedence. Both are in any
+	// currency or unit, as long as it's the same throughout.
+	CostOfDelayField string             `json:"costOfDelayField"`
+	CostOfDelay      map[string]float64 `json:"costOfDelay"`
+
+	// ResultCap is how many results JIRA pages through for one query; larger
+	// queries are split into creation date windows. Default 0 splits only
+	// queries the server stops paging short of their total.
+	ResultCap int `json:"resultCap"`
+
+	// Holidays are the dates ("2006-01-02") nobody is expected to work, in
+	// addition to HolidayCalendar's: a bundled national calendar such as "US"
+	// or, with a region's own holidays, "DE-BY". Ages, cycle times and the
+	// digest's SLA skip them like weekends, and the pace report counts work
+	// on them as off hours.
+	Holidays        []string `json:"holidays"`
+	HolidayCalendar string   `json:"holidayCalendar"`
+
+	// SprintGoalLabels are the labels marking issues that work toward their
+	// sprint's goal (default "sprint-goal"); issues the goal names by key, and
+	// their children and linked issues, count too:
+	SprintGoalLabels []string `json:"sprintGoalLabels"`
+
+	// RankField is the custom field ID holding the board rank (LexoRank);
+	// default "customfield_10019", JIRA Cloud's Rank:
+	RankField string `json:"rankField"`
+
+	// UndeliveredResolutions lists the resolutions of work closed without
+	// delivering it (default Won't Do, Won't Fix, Duplicate, Cannot
+	// Reproduce, Incomplete):
+	UndeliveredResolutions []string `json:"undeliveredResolutions"`
+
+	// WorkStarted defines when work on an issue starts: "status" (default) on
+	// entering one of StartStatuses (default "In Progress"), or "category" on
+	// the first move out of the To Do status category, whatever the workflow.
+	WorkStarted   string   `json:"workStarted"`
+	StartStatuses []string `json:"startStatuses"`
+
+	// StatusAliases maps translated or former status names to the name to
+	// report them under, e.g. {"In Arbeit": "In Progress"}, so history from
+	// before a workflow edit aggregates with today's statuses:
+	StatusAliases map[string]string `json:"statusAliases"`
+	// StatusBuckets reports equivalent statuses under one bucket name, e.g.
+	// {"Code Review": ["PR", "Peer Review"]}, for boards spanning projects
+	// with their own workflows. "OPS:Review" lists a status of one project.
+	StatusBuckets map[string][]string `json:"statusBuckets"`
+
+	// IncidentField is the custom field ID referencing an external incident or
+	// customer ticket; IncidentLinkTypes names issue link types (or their
+	// inward/outward descriptions) that mark an issue as incident-driven:
+	IncidentField     string   `json:"incidentField"`
+	IncidentLinkTypes []string `json:"incidentLinkTypes"`
+
+	// Jobs are named reports for `run`, so one cron line or timer can send
+	// many differently targeted reports:
+	Jobs map[string]*Job `json:"jobs"`
+
+	Badges   BadgeConfig    `json:"badges"`
+	Slack    SlackConfig    `json:"slack"`
+	Overload OverloadConfig `json:"overload"`
+	Nudge    NudgeConfig    `json:"nudge"`
+	Server   ServerConfig   `json:"server"`
+	// SLAPolicies set SLA thresholds by issue type, priority and status,
+	// overriding -sla and nudge thresholds; the tightest matching applies:
+	SLAPolicies []SLAPolicy `json:"slaPolicies"`
+	// WriteBack maps badge rules (breach, blocked, unassigned, stale) to the
+	// label to add or transition to make on matching issues, when nudge runs
+	// with -labels or -transitions:
+	WriteBack map[string]WriteBackAction `json:"writeBack"`
+	// AuditLog is where comments, labels and transitions made in JIRA are
+	// recorded; default jira-audit.jsonl:
+	AuditLog string `json:"auditLog"`
+	// NotesFile keeps the local notes attached to issues; default
+	// jira-notes.json:
+	NotesFile string `json:"notesFile"`
+	// AcksFile keeps the SLA breaches acknowledged in server mode; default
+	// jira-acks.json:
+	AcksFile string `json:"acksFile"`
+
+	// ReviewStatuses are the statuses where work waits for review; default
+	// "In Progress - 1", "Code Review", "In Review":
+	ReviewStatuses []string `json:"reviewStatuses"`
+	// QAStatuses are the statuses where work is tested, for the QA age
+	// trend; default "In Progress - 2", "In Testing":
+	QAStatuses []string `json:"qaStatuses"`
+
+	// StatusClasses classifies statuses as "work" or "queue" for wait-time
+	// analysis:
+	StatusClasses map[string]string `json:"statusClasses"`
+
+	// StatusCategories assigns statuses from trackers without status categories
+	// (e.g. GitHub Projects) to "new", "indeterminate" or "done":
+	StatusCategories map[string]string `json:"statusCategories"`
+
+	// UserAliases maps any identity (username, accountId or email) to the one
+	// name a person is reported under:
+	UserAliases map[string]string `json:"userAliases"`
+
+	// Managers maps each person to their manager, both by username,
+	// accountId or email, for rollups per manager:
+	Managers map[string]string `json:"managers"`
+
+	// Bots lists automation accounts whose changes don't attribute work to
+	// anyone; Cloud app accounts are recognized without listing:
+	Bots []string `json:"bots"`
+
+	// Teams lists each team's members by username, accountId or email:
+	Teams map[string][]string `json:"teams"`
+
+	// Budget caps API calls and bytes fetched per run:
+	Budget *APIBudget `json:"budget"`
+
+	// Outputs send every report to several destinations at once; default is
+	// the terminal only:
+	Outputs []SinkConfig `json:"outputs"`
+
+	// SummaryWidth truncates summaries to this many terminal columns (default
+	// 100, -1 for no limit); NameWidth sets the width of name columns such as
+	// assignee, team or tag (default per report). Wide CJK characters count
+	// as two columns.
+	SummaryWidth int `json:"summaryWidth"`
+	NameWidth    int `json:"nameWidth"`
+	// Overflow is how report lines wider than the terminal are fitted to it:
+	// "wrap" (default) or "truncate". Output to files and sinks is kept whole.
+	Overflow string `json:"overflow"`
+
+	// Redactions hide sensitive text such as customer names in summaries
+	// shown in reports:
+	Redactions []RedactRule `json:"redactions"`
+
+	// TagRules derive tags from issue summaries for grouping and filtering:
+	TagRules []TagRule `json:"tags"`
+}
+
+func (c *Config) isStoryPointsField(field string) bool {
+	if c.StoryPointsField != "" {
+		return strings.EqualFold(field, c.StoryPointsField)
+	}
+	return strings.EqualFold(field, "Story Points") || strings.EqualFold(field, "Story point estimate")
+}
+
+var defaultPercentiles = []float64{50, 85, 95}
+
+// ReportPercentiles returns the percentiles to report, from $JIRA_PERCENTILES
+// (comma-separated), the config file, or the defaults, in that order.
+func (c *Config) ReportPercentiles() ([]float64, error) {
+	if env := os.Getenv("JIRA_PERCENTILES"); env != "" {
+		var percentiles []float64
+		for _, part := range strings.Split(env, ",") {
+			p, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return nil, fmt.Errorf("JIRA_PERCENTILES: %v", err)
+			}
+			percentiles = append(percentiles, p)
+		}
+		return validPercentiles(percentiles)
+	}
+
+	if len(c.Percentiles) > 0 {
+		return validPercentiles(c.Percentiles)
+	}
+
+	return defaultPercentiles, nil
+}
+
+func validPercentiles(percentiles []float64) ([]float64, error) {
+	for _, p := range percentiles {
+		if p <= 0 || p > 100 {
+			return nil, fmt.Errorf("percentile %v out of range (0, 100]", p)
+		}
+	}
+	sorted := make([]float64, len(percentiles))
+	copy(sorted, percentiles)
+	sort.Float64s(sorted)
+	return sorted, nil
+}
+
+// configFilename locates the config file: $JIRA_CONFIG, then ./jira-analysis.json,
+// then the user config directory.
+func configFilename() string {
+	if filename := os.Getenv("JIRA_CONFIG"); filename != "" {
+		return filename
+	}
+
+	if _, err := os.Stat("jira-analysis.json"); err == nil {
+		return "jira-analysis.json"
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "jira-analysis", "config.json")
+}
+
+// loadConfig reads the config file; a missing file yields an empty config.
+func loadConfig() (*Config, error) {
+	config := &Config{}
+
+	filename := configFilename()
+	if filename == "" {
+		return config, nil
+	}
+
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(b, config)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	err = config.compileTagRules()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	err = config.compileRedactRules()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	err = config.validateStatusAliases()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	switch config.WorkStarted {
+	case "", "status", "category":
+	default:
+		return nil, fmt.Errorf("%s: workStarted '%s' should be status or category", filename, config.WorkStarted)
+	}
+
+	return config, nil
+}
+
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile looks up a profile by name, falling back to the default profile when
+// name is empty.
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile '%s'", name)
+	}
+	return profile, nil
+}
+
+// reportJQL resolves the JQL filter for a report: an explicit -jql flag value,
+// then per-report JQL from the active profile and the config file, then the
+// report's own default.
+func reportJQL(report string, flagValue string, fallback string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if activeProfile != nil {
+		if jql, ok := activeProfile.ReportJQL[report]; ok {
+			return jql
+		}
+	}
+	if jql, ok := config.ReportJQL[report]; ok {
+		return jql
+	}
+	return fallback
+}
+
+// env maps the profile's settings to the JIRA_* environment variables the
+// rest of the tool reads, with the settings it leaves blank empty:
+func (profile *Profile) env() map[string]string {
+	env := map[string]string{
+		"JIRA_URL":         profile.URL,
+		"JIRA_USERNAME":    profile.Username,
+		"JIRA_PASSWORD":    profile.Password,
+		"JIRA_JQL":         profile.JQL,
+		"JIRA_BACKEND":     profile.Backend,
+		"JIRA_AUTH":        profile.Auth,
+		"JIRA_CLIENT_CERT": profile.ClientCert,
+		"JIRA_CLIENT_KEY":  profile.ClientKey,
+		"JIRA_VIEW_AS":     profile.ViewAs,
+		"JIRA_BOARDID":     "",
+		"JIRA_FILTER":      "",
+	}
+	if profile.BoardId != 0 {
+		env["JIRA_BOARDID"] = strconv.Itoa(profile.BoardId)
+	}
+	if profile.Filter != 0 {
+		env["JIRA_FILTER"] = strconv.Itoa(profile.Filter)
+	}
+	return env
+}
+
+// apply exports the profile's settings as the JIRA_* environment variables the
+// rest of the tool reads.
+func (profile *Profile) apply() {
+	for key, value := range profile.env() {
+		if value != "" {
+			os.Setenv(key, value)
+		}
+	}
+}
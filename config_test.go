@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestConfig_ReportPercentiles(t *testing.T) {
+	c := &Config{Percentiles: []float64{95, 70, 50}}
+
+	percentiles, err := c.ReportPercentiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []float64{50, 70, 95}
+	if !reflect.DeepEqual(percentiles, expected) {
+		t.Fatalf("expected %v for percentiles, got %v", expected, percentiles)
+	}
+}
+
+func TestConfig_ReportPercentiles_Env(t *testing.T) {
+	os.Setenv("JIRA_PERCENTILES", "85, 99.9")
+	defer os.Unsetenv("JIRA_PERCENTILES")
+
+	c := &Config{Percentiles: []float64{50}}
+	percentiles, err := c.ReportPercentiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []float64{85, 99.9}
+	if !reflect.DeepEqual(percentiles, expected) {
+		t.Fatalf("expected %v for percentiles, got %v", expected, percentiles)
+	}
+}
+
+func TestConfig_ReportPercentiles_OutOfRange(t *testing.T) {
+	c := &Config{Percentiles: []float64{50, 150}}
+	_, err := c.ReportPercentiles()
+	if err == nil {
+		t.Fatalf("expected error for percentile 150")
+	}
+}
+
+func TestReportJQL_Precedence(t *testing.T) {
+	config = &Config{ReportJQL: map[string]string{"aging": "config aging", "cohorts": "config cohorts"}}
+	activeProfile = &Profile{ReportJQL: map[string]string{"aging": "profile aging"}}
+	defer func() {
+		config = &Config{}
+		activeProfile = nil
+	}()
+
+	cases := []struct {
+		report, flagValue, expected string
+	}{
+		{"aging", "flag", "flag"},
+		{"aging", "", "profile aging"},
+		{"cohorts", "", "config cohorts"},
+		{"priority", "", "default"},
+	}
+	for _, c := range cases {
+		actual := reportJQL(c.report, c.flagValue, "default")
+		if actual != c.expected {
+			t.Fatalf("expected '%s' for %s JQL, got '%s'", c.expected, c.report, actual)
+		}
+	}
+}
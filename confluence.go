@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// confluenceURL is the Confluence base URL for the confluence sink:
+// $CONFLUENCE_URL, else the wiki on the JIRA site as on Atlassian Cloud.
+func confluenceURL() string {
+	if u := os.Getenv("CONFLUENCE_URL"); u != "" {
+		return strings.TrimRight(u, "/")
+	}
+	return os.Getenv("JIRA_URL") + "/wiki"
+}
+
+// voidElement matches HTML elements without an end tag, which XHTML closes:
+var voidElement = regexp.MustCompile(`(?i)<(area|br|col|hr|img|input|meta|wbr)\b[^>]*>`)
+
+// storageBody converts a report to Confluence storage format: the body of an
+// HTML report as XHTML, or any other report preformatted. Storage format is
+// XML, so markup that isn't well-formed even once void elements are closed is
+// refused rather than published broken.
+func storageBody(text string) (string, error) {
+	lower := strings.ToLower(text)
+	start, end := strings.Index(lower, "<body>"), strings.LastIndex(lower, "</body>")
+	if start < 0 || end <= start {
+		return "<pre>" + html.EscapeString(text) + "</pre>", nil
+	}
+
+	body := strings.TrimSpace(text[start+len("<body>") : end])
+	body = voidElement.ReplaceAllStringFunc(body, func(tag string) string {
+		if strings.HasSuffix(tag, "/>") {
+			return tag
+		}
+		return strings.TrimSuffix(tag, ">") + " />"
+	})
+	d := xml.NewDecoder(strings.NewReader("<body>" + body + "</body>"))
+	d.Entity = xml.HTMLEntity
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return body, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("report HTML isn't well-formed XHTML for storage format: %v", err)
+		}
+	}
+}
+
+// weekTitle is the title of a report's page for the ISO week it was
+// generated in, so each week gets its own page and reruns update it:
+func weekTitle(title string, generated time.Time) string {
+	year, week := generated.ISOWeek()
+	return fmt.Sprintf("%s %d-W%02d", title, year, week)
+}
+
+type confluencePage struct {
+	Id      string `json:"id"`
+	Version struct {
+		Number int `json:"number"`
+	} `json:"version"`
+}
+
+// confluenceSink publishes a report as a page in a Confluence space, creating
+// the week's page or adding a version to it. Confluence has credentials of its
+// own, so JIRA's are never sent to another host.
+type confluenceSink struct {
+	base     string
+	space    string
+	title    string
+	parent   string
+	username string
+	token    string
+	cl       *http.Client
+}
+
+// confluenceCredentials are CONFLUENCE_USERNAME and CONFLUENCE_TOKEN, an API
+// token on Cloud; without a username the token is sent as a bearer token, as
+// Data Center personal access tokens are.
+func confluenceCredentials() (username string, token string, err error) {
+	username, token = os.Getenv("CONFLUENCE_USERNAME"), os.Getenv("CONFLUENCE_TOKEN")
+	if token == "" {
+		return "", "", fmt.Errorf("confluence output needs CONFLUENCE_TOKEN (and CONFLUENCE_USERNAME for an API token on Cloud)")
+	}
+	return username, token, nil
+}
+
+// do sends a request to Confluence with its own credentials, decoding the
+// JSON response into v unless nil:
+func (s *confluenceSink) do(method string, url string, body interface{}, v interface{}) error {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		if reqBody, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := s.cl.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode >= 300 {
+		return newAPIError(req, rsp)
+	}
+	if v == nil {
+		return nil
+	}
+	return decodeResponse(rsp.Body, url, v)
+}
+
+func (s *confluenceSink) Write(out *reportOutput) error {
+	if err := s.publish(out); err != nil {
+		return fmt.Errorf("confluence: %w", err)
+	}
+	return nil
+}
+
+func (s *confluenceSink) publish(out *reportOutput) error {
+	title := s.title
+	if title == "" {
+		title = strings.TrimSpace(out.Command+" "+strings.Join(out.Args, " ")) + " report"
+	}
+	title = weekTitle(title, out.Generated)
+	body, err := storageBody(out.Text)
+	if err != nil {
+		return err
+	}
+
+	var found struct {
+		Results []confluencePage `json:"results"`
+	}
+	query := url.Values{"spaceKey": {s.space}, "title": {title}, "type": {"page"}, "expand": {"version"}}
+	if err := s.do(http.MethodGet, s.base+"/rest/api/content?"+query.Encode(), nil, &found); err != nil {
+		return err
+	}
+
+	page := map[string]interface{}{
+		"type":  "page",
+		"title": title,
+		"space": map[string]string{"key": s.space},
+		"body": map[string]interface{}{
+			"storage": map[string]string{"value": body, "representation": "storage"},
+		},
+	}
+	if len(found.Results) > 0 {
+		existing := found.Results[0]
+		page["id"] = existing.Id
+		page["version"] = map[string]interface{}{"number": existing.Version.Number + 1, "message": "updated by " + programName()}
+		return s.do(http.MethodPut, s.base+"/rest/api/content/"+existing.Id, page, nil)
+	}
+	if s.parent != "" {
+		page["ancestors"] = []map[string]string{{"id": s.parent}}
+	}
+	return s.do(http.MethodPost, s.base+"/rest/api/content", page, nil)
+}
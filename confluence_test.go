@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestStorageBody(t *testing.T) {
+	if got, err := storageBody("<!DOCTYPE html>\n<html><head><title>x</title></head>\n<body>\n<h1>Digest</h1>\n</body>\n</html>\n"); got != "<h1>Digest</h1>" || err != nil {
+		t.Fatalf("expected the HTML body, got %q, %v", got, err)
+	}
+	if got, err := storageBody("WIP: 3 < 5\n"); got != "<pre>WIP: 3 &lt; 5\n</pre>" || err != nil {
+		t.Fatalf("expected escaped preformatted text, got %q, %v", got, err)
+	}
+	if got, err := storageBody("<body><p>a<br>b&nbsp;c<hr/></p></body>"); got != "<p>a<br />b&nbsp;c<hr/></p>" || err != nil {
+		t.Fatalf("expected void elements closed, got %q, %v", got, err)
+	}
+	if _, err := storageBody("<body><p>a<b>b</p></body>"); err == nil {
+		t.Fatalf("expected markup that isn't XHTML refused")
+	}
+}
+
+func TestConfluenceSink(t *testing.T) {
+	type page struct {
+		Id      string `json:"id"`
+		Title   string `json:"title"`
+		Version struct {
+			Number int `json:"number"`
+		} `json:"version"`
+		Ancestors []map[string]string `json:"ancestors"`
+	}
+	var pages []*page
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, _ := r.BasicAuth(); user != "wiki-bot" || token != "wiki-token" {
+			http.Error(w, "expected Confluence credentials", http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/rest/api/content":
+			var results []*page
+			for _, p := range pages {
+				if p.Title == r.URL.Query().Get("title") && r.URL.Query().Get("spaceKey") == "FLOW" {
+					results = append(results, p)
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+		case r.Method == http.MethodPost && r.URL.Path == "/wiki/rest/api/content":
+			p := &page{}
+			json.NewDecoder(r.Body).Decode(p)
+			p.Id = "100"
+			p.Version.Number = 1
+			pages = append(pages, p)
+		case r.Method == http.MethodPut && r.URL.Path == "/wiki/rest/api/content/100":
+			json.NewDecoder(r.Body).Decode(pages[0])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	for key, value := range map[string]string{
+		"JIRA_URL":      srv.URL,
+		"JIRA_USERNAME": "jira-bot",
+		"JIRA_PASSWORD": "jira-token",
+	} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+	if _, err := newSink(SinkConfig{Type: "confluence", Space: "FLOW"}); err == nil {
+		t.Fatalf("expected Confluence credentials required")
+	}
+	os.Setenv("CONFLUENCE_USERNAME", "wiki-bot")
+	defer os.Unsetenv("CONFLUENCE_USERNAME")
+	os.Setenv("CONFLUENCE_TOKEN", "wiki-token")
+	defer os.Unsetenv("CONFLUENCE_TOKEN")
+
+	s, err := newSink(SinkConfig{Type: "confluence", Space: "FLOW", Title: "Team digest", Parent: "42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &reportOutput{Command: "digest", Generated: time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC), Text: "<body><p>hi</p></body>"}
+	if err = s.Write(out); err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 || pages[0].Title != "Team digest 2026-W42" || len(pages[0].Ancestors) != 1 {
+		t.Fatalf("expected the week's page created under its parent, got %+v", pages)
+	}
+
+	// A rerun the same week adds a version rather than another page:
+	out.Generated = out.Generated.AddDate(0, 0, 2)
+	if err = s.Write(out); err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 || pages[0].Version.Number != 2 {
+		t.Fatalf("expected the page updated to version 2, got %+v", pages)
+	}
+}
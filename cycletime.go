@@ -0,0 +1,51 @@
+package main
+
+import "time"
+
+// inProgressStatus is the status whose first entry marks the start of work:
+const inProgressStatus = "In Progress"
+
+// StartedTime returns when the issue first entered In Progress:
+func (issue *Issue) StartedTime() (time.Time, bool) {
+	for _, ev := range issue.StatusTransitions() {
+		if issue.isStartTransition(ev.HistoryItem) {
+			return ev.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// CompletedTime returns when the issue was resolved, if it is in a done status:
+func (issue *Issue) CompletedTime() (time.Time, bool) {
+	if issue.Fields.Status.StatusCategory.Key != "done" {
+		return time.Time{}, false
+	}
+	if !issue.Fields.ResolutionDate.IsZero() {
+		return issue.Fields.ResolutionDate.Time, true
+	}
+
+	// Fall back to the last status change:
+	completed := time.Time{}
+	for _, ev := range issue.StatusTransitions() {
+		completed = ev.Time
+	}
+	return completed, !completed.IsZero()
+}
+
+// CycleTime returns business days from start of work to completion; none
+// for migrated issues, whose history has gaps, unless -include-migrated:
+func (issue *Issue) CycleTime() (int, bool) {
+	if !issue.trustedForPercentiles() {
+		return 0, false
+	}
+	started, ok := issue.StartedTime()
+	if !ok {
+		return 0, false
+	}
+	completed, ok := issue.CompletedTime()
+	if !ok {
+		return 0, false
+	}
+
+	return businessDaysUntil(started, completed), true
+}
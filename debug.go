@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// debugDir is where responses that fail to decode are saved for diagnosis;
+// $JIRA_DEBUG_DIR overrides it.
+func debugDir() string {
+	if dir := os.Getenv("JIRA_DEBUG_DIR"); dir != "" {
+		return dir
+	}
+	return "jira-debug"
+}
+
+// dumpResponse saves a response body that failed to decode along with its URL
+// and the error, returning an error naming the saved file.
+func dumpResponse(url string, b []byte, decodeErr error) error {
+	dir := debugDir()
+	h := fnv.New32a()
+	h.Write([]byte(url))
+	name := filepath.Join(dir, fmt.Sprintf("%s.%08x", time.Now().Format("20060102-150405"), h.Sum32()))
+
+	err := os.MkdirAll(dir, 0700)
+	if err == nil {
+		err = writeCacheFile(name+".json", b)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(name+".txt", []byte(fmt.Sprintf("%s\n%v\n", url, decodeErr)), 0600)
+	}
+	if err != nil {
+		log.Printf("debug: %v\n", err)
+		return &DecodeError{URL: url, Err: decodeErr}
+	}
+	return &DecodeError{URL: url, Saved: name + ".json", Err: decodeErr}
+}
+
+// decodeResponse decodes a JSON response body into v, saving the body to the
+// debug directory when it doesn't decode.
+func decodeResponse(body io.Reader, url string, v interface{}) error {
+	buf, err := readBody(body)
+	if err != nil {
+		return err
+	}
+	defer releaseBody(buf)
+	b := buf.Bytes()
+	if err = json.Unmarshal(b, v); err != nil {
+		return dumpResponse(url, b, err)
+	}
+	return nil
+}
+
+// decodeIssuesPage decodes a page of issues, skipping any issue that fails to
+// decode so one odd field shape doesn't lose the whole report. It also returns
+// the number of issues on the page, skipped or not, for paging.
+func decodeIssuesPage(body io.Reader, url string) (*PagedIssues, int, error) {
+	buf, err := readBody(body)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer releaseBody(buf)
+	b := buf.Bytes()
+
+	page := &PagedIssues{}
+	decodeErr := json.Unmarshal(b, page)
+	if decodeErr == nil {
+		return page, len(page.Issues), nil
+	}
+
+	var raw struct {
+		StartAt    int               `json:"startAt"`
+		MaxResults int               `json:"maxResults"`
+		Total      int               `json:"total"`
+		Issues     []json.RawMessage `json:"issues"`
+	}
+	dumpErr := dumpResponse(url, b, decodeErr)
+	if json.Unmarshal(b, &raw) != nil {
+		return nil, 0, dumpErr
+	}
+	log.Printf("warning: %v\n", dumpErr)
+
+	page = &PagedIssues{StartAt: raw.StartAt, MaxResults: raw.MaxResults, Total: raw.Total}
+	for i, rawIssue := range raw.Issues {
+		var issue Issue
+		if err := json.Unmarshal(rawIssue, &issue); err != nil {
+			log.Printf("warning: skipping issue %d of page at %d: %v\n", i, raw.StartAt, err)
+			continue
+		}
+		page.Issues = append(page.Issues, issue)
+	}
+	return page, len(raw.Issues), nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDecodeIssuesPage_SkipsBadIssueAndSavesResponse(t *testing.T) {
+	dir := t.TempDir()
+	os.Setenv("JIRA_DEBUG_DIR", dir)
+	defer os.Unsetenv("JIRA_DEBUG_DIR")
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	body := `{"startAt": 0, "total": 3, "issues": [{"key": "A-1"}, {"key": 2}, {"key": "A-3"}]}`
+	page, count, err := decodeIssuesPage(strings.NewReader(body), "https://jira/rest/agile/1.0/board/1/issue")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 || len(page.Issues) != 2 || page.Issues[1].Key != "A-3" {
+		t.Fatalf("expected A-1 and A-3 of 3 issues, got %d %+v", count, page.Issues)
+	}
+
+	saved, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(saved) != 1 {
+		t.Fatalf("expected the raw response saved, got %v", saved)
+	}
+	if b, _ := ioutil.ReadFile(saved[0]); string(b) != body {
+		t.Fatalf("expected saved body to match response, got %s", b)
+	}
+}
+
+func TestDecodeResponse_ErrorNamesSavedFile(t *testing.T) {
+	os.Setenv("JIRA_DEBUG_DIR", t.TempDir())
+	defer os.Unsetenv("JIRA_DEBUG_DIR")
+
+	var v struct{ Id int }
+	err := decodeResponse(strings.NewReader(`<html>login</html>`), "https://jira/rest/api/2/status", &v)
+	if err == nil || !strings.Contains(err.Error(), "response saved to") {
+		t.Fatalf("expected error naming the saved response, got %v", err)
+	}
+}
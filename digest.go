@@ -0,0 +1,393 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+type stageChange struct {
+	Stage    string
+	Current  float64
+	Baseline float64
+}
+
+// Improvement is the relative drop in time spent, in percent; negative when
+// the stage got slower.
+func (c stageChange) Improvement() int {
+	if c.Baseline == 0 {
+		return 0
+	}
+	return int(math.Round(100 * (c.Baseline - c.Current) / c.Baseline))
+}
+
+type digest struct {
+	From, To      time.Time
+	BaselineWeeks int
+
+	Throughput         int
+	BaselineThroughput float64
+	// CycleTimes are at each configured percentile, lowest first:
+	CycleTimes []cycleTimePercentile
+	WIP        int
+
+	// 95% confidence intervals and sample sizes behind the averages, so a
+	// quiet week isn't over-read:
+	BaselineThroughputCI   [2]float64
+	CycleTimeCount         int
+	BaselineCycleTimeCount int
+
+	SLA     int
+	OverSLA []*Issue
+	// Watchers of the items over SLA by key, with -watchers:
+	Watchers map[string][]string
+	Stages   []stageChange
+
+	Anomalies []anomaly
+	// Trends are WIP, throughput and QA age over the digest and baseline
+	// weeks:
+	Trends []trend
+
+	Highlights []string
+}
+
+// cycleTimePercentile is a cycle time percentile this week and over the
+// baseline weeks, with their 95% confidence intervals:
+type cycleTimePercentile struct {
+	P                     float64
+	Current, Baseline     int
+	CurrentCI, BaselineCI [2]int
+}
+
+func (c cycleTimePercentile) Label() string {
+	return percentileLabel(c.P)
+}
+
+// digestStageThreshold is the smallest stage time change worth a highlight, in
+// percent:
+const digestStageThreshold = 10
+
+// computeDigest compares the week up to now against the average of the
+// baselineWeeks weeks before it, with cycle times at the percentiles.
+func computeDigest(issues []Issue, now time.Time, baselineWeeks int, sla int, percentiles []float64) *digest {
+	d := &digest{
+		From:          now.AddDate(0, 0, -7),
+		To:            now,
+		BaselineWeeks: baselineWeeks,
+		SLA:           sla,
+	}
+	baselineStart := d.From.AddDate(0, 0, -7*baselineWeeks)
+
+	var cycleTimes, baselineCycleTimes []int
+	stageDays := make(map[string][]float64)
+	baselineStageDays := make(map[string][]float64)
+	baselineCompleted := 0
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+
+		completed, done := issue.CompletedTime()
+		if !done {
+			if started, ok := issue.StartedTime(); ok {
+				d.WIP++
+				threshold := slaThreshold(issue, issue.Fields.Status.Name, stageName(issue.Fields.Status.Name), sla)
+				if overSLA(businessDaysUntil(started, now), threshold) {
+					d.OverSLA = append(d.OverSLA, issue)
+				}
+			}
+			continue
+		}
+
+		var cycles *[]int
+		var stages map[string][]float64
+		switch {
+		case completed.After(d.From) && !completed.After(now):
+			d.Throughput++
+			cycles, stages = &cycleTimes, stageDays
+		case completed.After(baselineStart) && !completed.After(d.From):
+			baselineCompleted++
+			cycles, stages = &baselineCycleTimes, baselineStageDays
+		default:
+			continue
+		}
+
+		if days, ok := issue.CycleTime(); ok {
+			*cycles = append(*cycles, days)
+		}
+		for _, in := range issue.statusIntervals() {
+			if !in.End.IsZero() && in.Status != "" {
+				stages[in.Status] = append(stages[in.Status], days(in.Duration(now)))
+			}
+		}
+	}
+
+	if baselineWeeks > 0 {
+		d.BaselineThroughput = float64(baselineCompleted) / float64(baselineWeeks)
+		d.BaselineThroughputCI[0], d.BaselineThroughputCI[1] = rateInterval(baselineCompleted, float64(baselineWeeks))
+	}
+	sorted, baselineSorted := sortedCopy(cycleTimes), sortedCopy(baselineCycleTimes)
+	for _, p := range percentiles {
+		c := cycleTimePercentile{P: p, Current: percentile(sorted, p), Baseline: percentile(baselineSorted, p)}
+		c.CurrentCI[0], c.CurrentCI[1] = percentileInterval(sorted, p)
+		c.BaselineCI[0], c.BaselineCI[1] = percentileInterval(baselineSorted, p)
+		d.CycleTimes = append(d.CycleTimes, c)
+	}
+	d.CycleTimeCount, d.BaselineCycleTimeCount = len(cycleTimes), len(baselineCycleTimes)
+
+	for stage, current := range stageDays {
+		baseline, ok := baselineStageDays[stage]
+		if !ok {
+			continue
+		}
+		d.Stages = append(d.Stages, stageChange{Stage: stage, Current: meanFloat(current), Baseline: meanFloat(baseline)})
+	}
+	sort.Slice(d.Stages, func(i, j int) bool { return d.Stages[i].Stage < d.Stages[j].Stage })
+
+	sort.Slice(d.OverSLA, func(i, j int) bool { return lessIssueKey(d.OverSLA[i].Key, d.OverSLA[j].Key) })
+
+	// Anomalies over the digest week, against the month before:
+	d.Anomalies = detectAnomalies(issues, now, 20, 5, 3)
+
+	d.Trends = weeklyTrends(issues, now, baselineWeeks+1)
+	d.Highlights = d.highlights()
+	return d
+}
+
+func stageName(status string) string {
+	if name, ok := agingStages[status]; ok {
+		return name
+	}
+	return status
+}
+
+func changeWord(improvement int) string {
+	if improvement >= 0 {
+		return fmt.Sprintf("improved %d%%", improvement)
+	}
+	return fmt.Sprintf("worsened %d%%", -improvement)
+}
+
+// highlights writes the notable changes as short sentences, most important
+// first:
+func (d *digest) highlights() []string {
+	var h []string
+
+	h = append(h, fmt.Sprintf("throughput %d vs avg %.1f (95%% CI %s) over the previous %s", d.Throughput, d.BaselineThroughput, d.baselineThroughputCI(), plural(d.BaselineWeeks, "week")))
+
+	// Headlined by the highest percentile, where slow work shows first:
+	if len(d.CycleTimes) > 0 {
+		ct := d.CycleTimes[len(d.CycleTimes)-1]
+		if ct.Current > 0 && ct.Baseline > 0 {
+			c := stageChange{Current: float64(ct.Current), Baseline: float64(ct.Baseline)}
+			if c.Improvement() == 0 {
+				h = append(h, fmt.Sprintf("cycle time %s steady at %d days (%s)", ct.Label(), ct.Current, sampleNote(ct.CurrentCI, d.CycleTimeCount)))
+			} else {
+				h = append(h, fmt.Sprintf("cycle time %s %s (%d vs %d days; %s)", ct.Label(), changeWord(c.Improvement()), ct.Current, ct.Baseline, sampleNote(ct.CurrentCI, d.CycleTimeCount)))
+			}
+		}
+	}
+
+	// Biggest stage changes first:
+	stages := make([]stageChange, len(d.Stages))
+	copy(stages, d.Stages)
+	sort.SliceStable(stages, func(i, j int) bool {
+		a, b := stages[i].Improvement(), stages[j].Improvement()
+		if a < 0 {
+			a = -a
+		}
+		if b < 0 {
+			b = -b
+		}
+		return a > b
+	})
+	for _, c := range stages {
+		improvement := c.Improvement()
+		if improvement > -digestStageThreshold && improvement < digestStageThreshold {
+			continue
+		}
+		h = append(h, fmt.Sprintf("%s time %s", stageName(c.Stage), changeWord(improvement)))
+	}
+
+	for _, a := range d.Anomalies {
+		h = append(h, a.String())
+	}
+
+	if d.SLA > 0 || len(config.SLAPolicies) > 0 {
+		sla := "their SLA"
+		if len(config.SLAPolicies) == 0 {
+			sla = fmt.Sprintf("the %d day SLA", d.SLA)
+		}
+		switch len(d.OverSLA) {
+		case 0:
+			h = append(h, fmt.Sprintf("no items over %s", sla))
+		case 1:
+			h = append(h, fmt.Sprintf("1 item breached %s", sla))
+		default:
+			h = append(h, fmt.Sprintf("%d items breached %s", len(d.OverSLA), sla))
+		}
+	}
+
+	h = append(h, fmt.Sprintf("%d items in progress", d.WIP))
+	return h
+}
+
+func (d *digest) baselineThroughputCI() string {
+	return fmt.Sprintf("%.1f–%.1f", d.BaselineThroughputCI[0], d.BaselineThroughputCI[1])
+}
+
+// sampleNote gives a percentile's 95% confidence interval and sample size,
+// warning when the sample is too small to read a change from:
+func sampleNote(ci [2]int, n int) string {
+	note := fmt.Sprintf("95%% CI %s, n=%d", formatInterval(ci[0], ci[1]), n)
+	if n < smallSample {
+		note += ", small sample"
+	}
+	return note
+}
+
+func (d *digest) title() string {
+	return fmt.Sprintf("Weekly digest: %s to %s", displayTime(d.From).Format("Mon Jan 02"), displayTime(d.To).Format("Mon Jan 02"))
+}
+
+func (d *digest) writeMarkdown(w *strings.Builder) {
+	fmt.Fprintf(w, "# %s\n\n", d.title())
+	for _, line := range d.Highlights {
+		fmt.Fprintf(w, "- %s\n", markdownText(line))
+	}
+
+	fmt.Fprintf(w, "\n| metric | this week | previous %s |\n|---|---:|---:|\n", plural(d.BaselineWeeks, "week"))
+	fmt.Fprintf(w, "| throughput | %d | %.1f/week (%s) |\n", d.Throughput, d.BaselineThroughput, d.baselineThroughputCI())
+	for _, c := range d.CycleTimes {
+		fmt.Fprintf(w, "| cycle time %s | %d (%s, n=%d) | %d (%s, n=%d) |\n", c.Label(),
+			c.Current, formatInterval(c.CurrentCI[0], c.CurrentCI[1]), d.CycleTimeCount,
+			c.Baseline, formatInterval(c.BaselineCI[0], c.BaselineCI[1]), d.BaselineCycleTimeCount)
+	}
+	for _, c := range d.Stages {
+		fmt.Fprintf(w, "| %s (mean days) | %.1f | %.1f |\n", markdownText(stageName(c.Stage)), c.Current, c.Baseline)
+	}
+
+	if len(d.Trends) > 0 {
+		fmt.Fprintf(w, "\n| trend | last %s | now |\n|---|---|---:|\n", plural(d.BaselineWeeks+1, "week"))
+		for _, t := range d.Trends {
+			fmt.Fprintf(w, "| %s | %s | %s |\n", t.Name, t.Sparkline(), t.Last())
+		}
+	}
+
+	if len(d.OverSLA) > 0 {
+		fmt.Fprintf(w, "\n## Over SLA\n\n")
+		for _, issue := range d.OverSLA {
+			watching := ""
+			if names := d.Watchers[issue.Key]; len(names) > 0 {
+				watching = " (watching: " + markdownText(strings.Join(names, ", ")) + ")"
+			}
+			if badges := issueBadges(issue, true, d.To); badges != "" {
+				fmt.Fprintf(w, "- %s %s %s%s\n", issue.Key, badges, markdownText(issue.DisplaySummary()), watching)
+			} else {
+				fmt.Fprintf(w, "- %s %s%s\n", issue.Key, markdownText(issue.DisplaySummary()), watching)
+			}
+		}
+	}
+}
+
+// writeSlack uses Slack's mrkdwn, which has no headings or tables:
+func (d *digest) writeSlack(w *strings.Builder) {
+	fmt.Fprintf(w, "*%s*\n", d.title())
+	for _, line := range d.Highlights {
+		fmt.Fprintf(w, "• %s\n", slackText(line))
+	}
+	for _, t := range d.Trends {
+		fmt.Fprintf(w, "`%s` %s %s\n", t.Sparkline(), t.Name, t.Last())
+	}
+	if len(d.OverSLA) > 0 {
+		fmt.Fprintf(w, "*Over SLA:*")
+		for _, issue := range d.OverSLA {
+			fmt.Fprintf(w, " %s", issue.Key)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+var digestTemplate = assetTemplate("digest.html", template.FuncMap{"stage": stageName, "join": strings.Join})
+
+func runDigest(args []string) error {
+	fs := flag.NewFlagSet("digest", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting issues; default covers the digest and baseline weeks")
+	weeks := fs.Int("weeks", 4, "number of previous weeks to compare against")
+	sla := fs.Int("sla", 10, "business days after which in-flight items breach SLA; 0 to skip")
+	format := fs.String("format", "markdown", "markdown, html or slack")
+	watchers := fs.Bool("watchers", false, "fetch the watchers of items over SLA to list and notify them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *weeks < 1 {
+		return fmt.Errorf("-weeks must be at least 1")
+	}
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	defaultFilter := fmt.Sprintf("resolved >= -%dd OR resolution is EMPTY", 7*(*weeks+1))
+	cl := httpClient()
+	issues, err := fetchBoardIssues(cl, boardArg(fs.Args()), reportJQL("digest", *jql, defaultFilter))
+	if err != nil {
+		return err
+	}
+
+	d := computeDigest(issues, reportNow(), *weeks, *sla, percentiles)
+	recordMetric("throughput", d.Throughput)
+	recordMetric("baselineThroughput", d.BaselineThroughput)
+	for _, c := range d.CycleTimes {
+		recordMetric(percentileMetric("cycleTime", c.P), c.Current)
+		recordMetric(percentileMetric("cycleTime", c.P)+"CI", c.CurrentCI)
+	}
+	recordMetric("cycleTimeCount", d.CycleTimeCount)
+	recordMetric("wip", d.WIP)
+	recordMetric("overSLA", len(d.OverSLA))
+	trends := make(map[string][]float64, len(d.Trends))
+	for _, t := range d.Trends {
+		trends[t.Name] = t.Values
+	}
+	recordMetric("trends", trends)
+	if *watchers {
+		if d.Watchers, err = issueWatchers(cl, d.OverSLA); err != nil {
+			return err
+		}
+		recordMetric("watchers", d.Watchers)
+	}
+	var alerts []ruleAlert
+	for _, issue := range d.OverSLA {
+		alerts = append(alerts, issueAlerts(issue, true, d.To)...)
+	}
+	recordAlerts(alerts, d.Watchers)
+	// Highlights count every item; the list follows the verbosity:
+	d.OverSLA = d.OverSLA[:detailLines(len(d.OverSLA))]
+
+	var out strings.Builder
+	switch *format {
+	case "markdown", "md":
+		d.writeMarkdown(&out)
+	case "slack":
+		d.writeSlack(&out)
+	case "html":
+		err = digestTemplate.Execute(&out, struct {
+			Title string
+			D     *digest
+		}{d.title(), d})
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format '%s'; expected markdown, html or slack", *format)
+	}
+
+	_, err = os.Stdout.WriteString(out.String())
+	return err
+}
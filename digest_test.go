@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeDigest_Highlights(t *testing.T) {
+	now := time.Date(2018, 11, 9, 17, 0, 0, 0, time.UTC)
+	issues := []Issue{
+		// This week, 2 days:
+		completedIssue("A-1", time.Date(2018, 11, 6, 9, 0, 0, 0, time.UTC), time.Date(2018, 11, 8, 9, 0, 0, 0, time.UTC)),
+		completedIssue("A-2", time.Date(2018, 11, 5, 9, 0, 0, 0, time.UTC), time.Date(2018, 11, 7, 9, 0, 0, 0, time.UTC)),
+		// Baseline, 5 days:
+		completedIssue("A-3", time.Date(2018, 10, 22, 9, 0, 0, 0, time.UTC), time.Date(2018, 10, 29, 9, 0, 0, 0, time.UTC)),
+	}
+	inFlight := Issue{Key: "A-4"}
+	inFlight.Changelog.Histories = []History{{
+		Created: zonedTimestamp{time.Date(2018, 10, 1, 9, 0, 0, 0, time.UTC)},
+		Items:   []HistoryItem{{Field: "status", ToString: inProgressStatus}},
+	}}
+	issues = append(issues, inFlight)
+
+	d := computeDigest(issues, now, 2, 10, []float64{50, 85})
+	if d.Throughput != 2 || d.BaselineThroughput != 0.5 || d.WIP != 1 || len(d.OverSLA) != 1 {
+		t.Fatalf("expected throughput 2 vs 0.5, 1 WIP over SLA, got %+v", d)
+	}
+
+	highlights := strings.Join(d.Highlights, "\n")
+	for _, expected := range []string{"throughput 2 vs avg 0.5", "cycle time p85 improved 60% (2 vs 5 days; 95% CI 2–2, n=2, small sample)", "1 item breached the 10 day SLA"} {
+		if !strings.Contains(highlights, expected) {
+			t.Fatalf("expected highlight %q, got:\n%s", expected, highlights)
+		}
+	}
+}
+
+func TestComputeDigest_HeadlinesHighestPercentile(t *testing.T) {
+	now := time.Date(2018, 11, 9, 17, 0, 0, 0, time.UTC)
+	issues := []Issue{
+		completedIssue("A-1", time.Date(2018, 11, 6, 9, 0, 0, 0, time.UTC), time.Date(2018, 11, 8, 9, 0, 0, 0, time.UTC)),
+		completedIssue("A-3", time.Date(2018, 10, 22, 9, 0, 0, 0, time.UTC), time.Date(2018, 10, 29, 9, 0, 0, 0, time.UTC)),
+	}
+
+	d := computeDigest(issues, now, 2, 0, []float64{50, 70})
+	if len(d.CycleTimes) != 2 || d.CycleTimes[0].Label() != "p50" {
+		t.Fatalf("expected cycle times at p50 and p70, got %+v", d.CycleTimes)
+	}
+	var out strings.Builder
+	d.writeMarkdown(&out)
+	for _, expected := range []string{"- cycle time p70 improved 60%", "| cycle time p50 | 2 ", "| cycle time p70 | 2 "} {
+		if !strings.Contains(out.String(), expected) {
+			t.Fatalf("expected %q, got:\n%s", expected, out.String())
+		}
+	}
+}
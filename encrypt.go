@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// encryptedMagic prefixes files encrypted at rest, followed by the salt their
+// key was derived with. legacyMagic files were keyed by a bare SHA-256 of the
+// passphrase; they're still read, but never written.
+var (
+	encryptedMagic = []byte("JAENC2\n")
+	legacyMagic    = []byte("JAENC1\n")
+)
+
+// saltFile holds the random salt keys are derived with, created beside the
+// cache on first use. Each file records the salt it was sealed with, so files
+// stay readable should the salt file be replaced.
+const (
+	saltFile = ".jira-analysis.salt"
+	saltSize = 16
+)
+
+// The scrypt cost: 32 MB and a fraction of a second per key, derived once per
+// salt per run.
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// keyringCommand looks a secret up in the OS keyring: the login keychain on
+// macOS, else the Secret Service (GNOME Keyring or KWallet) with secret-tool.
+var keyringCommand = func(service string) *exec.Cmd {
+	if runtime.GOOS == "darwin" {
+		return exec.Command("security", "find-generic-password", "-s", service, "-w")
+	}
+	return exec.Command("secret-tool", "lookup", "service", service)
+}
+
+// cacheSecret is the passphrase caches are encrypted with: $JIRA_CACHE_KEY,
+// the contents of $JIRA_CACHE_KEY_FILE, or the keyring entry for the service
+// named by $JIRA_CACHE_KEYRING; empty when none is set and caches stay plain.
+func cacheSecret() (string, error) {
+	if secret := os.Getenv("JIRA_CACHE_KEY"); secret != "" {
+		return secret, nil
+	}
+	if filename := os.Getenv("JIRA_CACHE_KEY_FILE"); filename != "" {
+		b, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return "", fmt.Errorf("JIRA_CACHE_KEY_FILE: %v", err)
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+	if service := os.Getenv("JIRA_CACHE_KEYRING"); service != "" {
+		out, err := keyringCommand(service).Output()
+		if err != nil {
+			return "", fmt.Errorf("JIRA_CACHE_KEYRING: looking up %s: %v", service, err)
+		}
+		secret := strings.TrimSpace(string(out))
+		if secret == "" {
+			return "", fmt.Errorf("JIRA_CACHE_KEYRING: no secret stored for %s", service)
+		}
+		return secret, nil
+	}
+	return "", nil
+}
+
+// cacheSalt is the stored salt, created at random on first use:
+func cacheSalt() ([]byte, error) {
+	b, err := ioutil.ReadFile(saltFile)
+	if err == nil {
+		if len(b) != saltSize {
+			return nil, fmt.Errorf("%s: expected a %d byte salt", saltFile, saltSize)
+		}
+		return b, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err = rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, writeFileAtomic(saltFile, salt, 0600)
+}
+
+var (
+	derivedKeysMu sync.Mutex
+	derivedKeys   = make(map[string][]byte)
+)
+
+// deriveKey derives the AES-256 key for a passphrase and salt with scrypt,
+// remembering it for the rest of the run:
+func deriveKey(secret string, salt []byte) ([]byte, error) {
+	id := sha256.Sum256(append([]byte(secret+"\x00"), salt...))
+	derivedKeysMu.Lock()
+	defer derivedKeysMu.Unlock()
+	if key, ok := derivedKeys[string(id[:])]; ok {
+		return key, nil
+	}
+	key, err := scryptKey([]byte(secret), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, err
+	}
+	derivedKeys[string(id[:])] = key
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encryptAtRest seals b with AES-GCM when a cache key is configured:
+func encryptAtRest(b []byte) ([]byte, error) {
+	secret, err := cacheSecret()
+	if err != nil || secret == "" {
+		return b, err
+	}
+	salt, err := cacheSalt()
+	if err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(secret, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed := append([]byte{}, encryptedMagic...)
+	sealed = append(sealed, salt...)
+	sealed = append(sealed, nonce...)
+	return gcm.Seal(sealed, nonce, b, nil), nil
+}
+
+// decryptAtRest opens data written by encryptAtRest; plain data written before
+// a key was configured passes through unchanged.
+func decryptAtRest(b []byte) ([]byte, error) {
+	legacy := bytes.HasPrefix(b, legacyMagic)
+	if !legacy && !bytes.HasPrefix(b, encryptedMagic) {
+		return b, nil
+	}
+
+	secret, err := cacheSecret()
+	if err != nil {
+		return nil, err
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("encrypted, but JIRA_CACHE_KEY is not set")
+	}
+
+	var key []byte
+	if legacy {
+		sum := sha256.Sum256([]byte(secret))
+		key, b = sum[:], b[len(legacyMagic):]
+	} else {
+		b = b[len(encryptedMagic):]
+		if len(b) < saltSize {
+			return nil, fmt.Errorf("encrypted data truncated")
+		}
+		if key, err = deriveKey(secret, b[:saltSize]); err != nil {
+			return nil, err
+		}
+		b = b[saltSize:]
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted data truncated")
+	}
+	plain, err := gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt: wrong JIRA_CACHE_KEY or corrupt data")
+	}
+	return plain, nil
+}
+
+func readCacheFile(filename string) ([]byte, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return decryptAtRest(b)
+}
+
+func writeCacheFile(filename string, b []byte) error {
+	sealed, err := encryptAtRest(b)
+	if err != nil {
+		return err
+	}
+	return writeFileLocked(filename, sealed, 0600)
+}
+
+// encryptLine and decryptLine protect single lines of append-only logs, which
+// can't be sealed as a whole:
+func encryptLine(line []byte) ([]byte, error) {
+	sealed, err := encryptAtRest(line)
+	if err != nil || bytes.Equal(sealed, line) {
+		return line, err
+	}
+	return []byte("enc:" + base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+func decryptLine(line []byte) ([]byte, error) {
+	if !bytes.HasPrefix(line, []byte("enc:")) {
+		return line, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(line[len("enc:"):]))
+	if err != nil {
+		return nil, err
+	}
+	return decryptAtRest(sealed)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestCacheFile_EncryptedAtRest(t *testing.T) {
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	os.Setenv("JIRA_CACHE_KEY", "secret")
+	defer os.Unsetenv("JIRA_CACHE_KEY")
+
+	filename := "board.1.json"
+	body := []byte(`{"summary": "customer: acme"}`)
+	if err := writeCacheFile(filename, body); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := ioutil.ReadFile(filename)
+	if bytes.Contains(raw, []byte("acme")) {
+		t.Fatalf("expected cache file encrypted, got %q", raw)
+	}
+	// The key is derived with the stored random salt, which the file records:
+	salt, err := ioutil.ReadFile(saltFile)
+	if err != nil || len(salt) != saltSize || !bytes.HasPrefix(raw, append(append([]byte{}, encryptedMagic...), salt...)) {
+		t.Fatalf("expected the file sealed with the stored salt, got %q, %v", salt, err)
+	}
+	b, err := readCacheFile(filename)
+	if err != nil || !bytes.Equal(b, body) {
+		t.Fatalf("expected original body back, got %q, %v", b, err)
+	}
+
+	os.Setenv("JIRA_CACHE_KEY", "other")
+	if _, err = readCacheFile(filename); err == nil {
+		t.Fatalf("expected error decrypting with the wrong key")
+	}
+}
+
+func TestDecryptAtRest_PlainPassesThrough(t *testing.T) {
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	os.Setenv("JIRA_CACHE_KEY", "secret")
+	defer os.Unsetenv("JIRA_CACHE_KEY")
+
+	b, err := decryptAtRest([]byte(`{"issues": []}`))
+	if err != nil || string(b) != `{"issues": []}` {
+		t.Fatalf("expected unencrypted cache read as is, got %q, %v", b, err)
+	}
+
+	line, err := encryptLine([]byte(`{"name": "sprint 4"}`))
+	if err != nil || !bytes.HasPrefix(line, []byte("enc:")) {
+		t.Fatalf("expected encrypted log line, got %q, %v", line, err)
+	}
+	if b, err = decryptLine(line); err != nil || string(b) != `{"name": "sprint 4"}` {
+		t.Fatalf("expected log line decrypted, got %q, %v", b, err)
+	}
+}
+
+func TestDecryptAtRest_Legacy(t *testing.T) {
+	os.Setenv("JIRA_CACHE_KEY", "secret")
+	defer os.Unsetenv("JIRA_CACHE_KEY")
+
+	key := sha256.Sum256([]byte("secret"))
+	gcm, _ := newGCM(key[:])
+	nonce := make([]byte, gcm.NonceSize())
+	sealed := gcm.Seal(append(append([]byte{}, legacyMagic...), nonce...), nonce, []byte(`{"issues": []}`), nil)
+	if b, err := decryptAtRest(sealed); err != nil || string(b) != `{"issues": []}` {
+		t.Fatalf("expected files sealed before salted keys still read, got %q, %v", b, err)
+	}
+}
+
+func TestCacheSecret_Keyring(t *testing.T) {
+	lookup := keyringCommand
+	keyringCommand = func(service string) *exec.Cmd {
+		return exec.Command("echo", "from-"+service)
+	}
+	defer func() { keyringCommand = lookup }()
+	os.Setenv("JIRA_CACHE_KEYRING", "jira-analysis")
+	defer os.Unsetenv("JIRA_CACHE_KEYRING")
+
+	if secret, err := cacheSecret(); err != nil || secret != "from-jira-analysis" {
+		t.Fatalf("expected the keyring's secret, got %q, %v", secret, err)
+	}
+	os.Setenv("JIRA_CACHE_KEY", "env")
+	defer os.Unsetenv("JIRA_CACHE_KEY")
+	if secret, _ := cacheSecret(); secret != "env" {
+		t.Fatalf("expected JIRA_CACHE_KEY to take precedence, got %q", secret)
+	}
+}
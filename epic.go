@@ -0,0 +1,275 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// timelineRow is one child issue's status intervals from start of work to
+// completion, for the epic timeline:
+type timelineRow struct {
+	Key       string
+	Summary   string
+	Intervals []statusInterval
+}
+
+// epicChildrenJQL selects the children of epics in classic (epic link) and
+// team-managed (parent) projects:
+func epicChildrenJQL(epicKeys ...string) string {
+	return fmt.Sprintf(`"Epic Link" in (%[1]s) OR parent in (%[1]s)`, strings.Join(epicKeys, ", "))
+}
+
+// epicBurn is an open epic's progress and projected completion:
+type epicBurn struct {
+	Epic  *Issue
+	Total int
+	Done  int
+	// PerWeek is the mean number of children completed per week recently:
+	PerWeek float64
+	// Days holds the business days to completion per reported percentile;
+	// nil when no children were completed recently to project from.
+	Days []int
+}
+
+// computeEpicBurn projects when an epic's remaining children complete by
+// sampling their daily completions over the past weeks.
+func computeEpicBurn(epic *Issue, children []*Issue, now time.Time, weeks int, percentiles []float64) epicBurn {
+	b := epicBurn{Epic: epic}
+	var done []Issue
+	for _, child := range children {
+		b.Total++
+		if _, ok := child.CompletedTime(); ok {
+			b.Done++
+			done = append(done, *child)
+		}
+	}
+
+	throughput := dailyThroughput(done, now, 7*weeks)
+	completed := 0
+	for _, n := range throughput {
+		completed += n
+	}
+	b.PerWeek = float64(completed) / float64(weeks)
+	if completed == 0 && b.Done < b.Total {
+		return b
+	}
+
+	outcomes := monteCarlo(throughput, b.Total-b.Done, 10000, rand.New(rand.NewSource(1)))
+	for _, p := range percentiles {
+		b.Days = append(b.Days, percentile(outcomes, p))
+	}
+	return b
+}
+
+// epicTimeline lays out each child's status intervals between starting work
+// and completion, ordered by start. Children not yet started are left out.
+func epicTimeline(issues []Issue) []timelineRow {
+	var rows []timelineRow
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		started, ok := issue.StartedTime()
+		if !ok {
+			continue
+		}
+		completed, done := issue.CompletedTime()
+
+		row := timelineRow{Key: issue.Key, Summary: issue.DisplaySummary()}
+		for _, in := range issue.statusIntervals() {
+			if !in.End.IsZero() && !in.End.After(started) {
+				continue
+			}
+			if done && !in.Start.Before(completed) {
+				continue
+			}
+			if in.Start.Before(started) {
+				in.Start = started
+			}
+			row.Intervals = append(row.Intervals, in)
+		}
+		if len(row.Intervals) > 0 {
+			rows = append(rows, row)
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Intervals[0].Start.Before(rows[j].Intervals[0].Start)
+	})
+	return rows
+}
+
+var epicSubcommands = map[string]func(args []string) error{
+	"rollup":   runEpicRollup,
+	"sizing":   runEpicSizing,
+	"timeline": runEpicTimeline,
+}
+
+func runEpic(args []string) error {
+	return runSubcommand("epic", epicSubcommands, args)
+}
+
+func runEpicTimeline(args []string) error {
+	fs := flag.NewFlagSet("epic timeline", flag.ContinueOnError)
+	format := fs.String("format", "", "mermaid, svg, png or vega-lite; default by -o extension, else mermaid")
+	output := fs.String("o", "", "write the timeline to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < 1 {
+		return fmt.Errorf("usage: %s epic timeline [-format mermaid|svg|png|vega-lite] [-o file] epicKey [boardId]", programName())
+	}
+	epicKey := fs.Arg(0)
+
+	var issues []Issue
+	var err error
+	if useEpicAPI() {
+		issues, err = fetchEpicIssues(httpClient(), epicKey, "")
+	} else {
+		issues, err = fetchBoardIssues(httpClient(), boardArg(fs.Args()[1:]), epicChildrenJQL(epicKey))
+	}
+	if err != nil {
+		return err
+	}
+	rows := epicTimeline(issues)
+	if len(rows) == 0 {
+		return fmt.Errorf("no started children found for epic %s", epicKey)
+	}
+
+	backend, err := chartBackendFor(*format, *output)
+	if err != nil {
+		return err
+	}
+	if _, ok := backend.(pngChart); ok && *output == "" && isTerminal(os.Stdout) {
+		return fmt.Errorf("refusing to write a PNG to the terminal; use -o file")
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	chart := &ganttChart{Title: epicKey + " timeline", Rows: rows, Now: reportNow(), Footer: reportFooter()}
+	return backend.RenderGantt(w, chart)
+}
+
+// openEpicsByJQL returns the open epics the JQL selects, ordered by key, each
+// with its children:
+func openEpicsByJQL(cl *http.Client, boardId int, jql string) ([]*Issue, map[string][]*Issue, error) {
+	return epicsByJQL(cl, boardId, jql, false)
+}
+
+// epicsByJQL returns the epics the JQL selects, ordered by key, each with its
+// children; completed epics are left out unless withDone.
+func epicsByJQL(cl *http.Client, boardId int, jql string, withDone bool) ([]*Issue, map[string][]*Issue, error) {
+	fetchedEpics, err := fetchBoardIssues(cl, boardId, jql)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var epics []*Issue
+	var keys []string
+	for i := range fetchedEpics {
+		epic := &fetchedEpics[i]
+		if _, done := epic.CompletedTime(); epic.IsEpic() && (withDone || !done) {
+			epics = append(epics, epic)
+			keys = append(keys, epic.Key)
+		}
+	}
+	if len(epics) == 0 {
+		return nil, nil, nil
+	}
+	sort.Slice(epics, func(i, j int) bool { return lessIssueKey(epics[i].Key, epics[j].Key) })
+
+	issues, err := fetchBoardIssues(cl, boardId, epicChildrenJQL(keys...))
+	if err != nil {
+		return nil, nil, err
+	}
+	children := make(map[string][]*Issue)
+	for i := range issues {
+		child := &issues[i]
+		if !child.IsEpic() {
+			children[child.EpicKey()] = append(children[child.EpicKey()], child)
+		}
+	}
+	return epics, children, nil
+}
+
+func runEpicRollup(args []string) error {
+	fs := flag.NewFlagSet("epic rollup", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting epics; default='issuetype = Epic AND statusCategory != Done'")
+	weeks := fs.Int("weeks", 6, "weeks of child completions to project from")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *weeks < 1 {
+		return fmt.Errorf("-weeks must be at least 1")
+	}
+
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	cl := httpClient()
+	boardId := boardArg(fs.Args())
+	var epics []*Issue
+	var children map[string][]*Issue
+	ok := false
+	// The agile API knows epics without the epic name field, in rank order:
+	if useEpicAPI() && *jql == "" {
+		epics, children, ok, err = openEpicsByAPI(cl, boardId)
+		if err != nil {
+			return err
+		}
+	}
+	if !ok {
+		epics, children, err = openEpicsByJQL(cl, boardId, reportJQL("epic-rollup", *jql, "issuetype = Epic AND statusCategory != Done"))
+		if err != nil {
+			return err
+		}
+	}
+	if len(epics) == 0 {
+		fmt.Printf("No open epics.\n")
+		return nil
+	}
+
+	now := reportNow()
+	fmt.Printf("Open epics, with children completed per week over the last %s and projected completion:\n", plural(*weeks, "week"))
+	fmt.Printf("  %-12s %9s %6s", "epic", "done", "/week")
+	for _, p := range percentiles {
+		fmt.Printf(" %-10s", percentileLabel(p))
+	}
+	fmt.Printf(" summary\n")
+	for _, epic := range epics {
+		b := computeEpicBurn(epic, children[epic.Key], now, *weeks, percentiles)
+		fmt.Printf("  %-12s %9s %6.1f", epic.Key, fmt.Sprintf("%d/%d", b.Done, b.Total), b.PerWeek)
+		for p := range percentiles {
+			projected := "-"
+			if b.Days != nil {
+				projected = addBusinessDays(now, b.Days[p]).Format("Mon Jan 02")
+			}
+			fmt.Printf(" %-10s", projected)
+		}
+		fmt.Printf(" %s\n", epic.DisplaySummary())
+	}
+
+	// The epic command omits the footer for timelines, which embed it:
+	if footer := reportFooter(); footer != "" {
+		fmt.Printf("\n%s\n", footer)
+	}
+	return nil
+}
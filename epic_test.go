@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEpicTimeline_ClipsToStartAndCompletion(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2018, 11, day, hour, 0, 0, 0, time.UTC)
+	}
+	status := func(day int, from, to string) History {
+		return History{Created: zonedTimestamp{at(day, 9)}, Items: []HistoryItem{{Field: "status", FromString: from, ToString: to}}}
+	}
+
+	done := Issue{Key: "A-1"}
+	done.Fields.Created.Time = at(1, 9)
+	done.Fields.Status.StatusCategory.Key = "done"
+	done.Fields.ResolutionDate.Time = at(7, 9)
+	done.Changelog.Histories = []History{
+		status(2, "Open", inProgressStatus),
+		status(5, inProgressStatus, "Code Review"),
+		status(7, "Code Review", "Done"),
+	}
+
+	open := Issue{Key: "A-2"}
+	open.Fields.Created.Time = at(1, 9)
+	open.Changelog.Histories = []History{status(6, "Open", inProgressStatus)}
+
+	rows := epicTimeline([]Issue{open, done, {Key: "A-3"}})
+	if len(rows) != 2 || rows[0].Key != "A-1" || rows[1].Key != "A-2" {
+		t.Fatalf("expected started children A-1, A-2 in start order, got %+v", rows)
+	}
+	if len(rows[0].Intervals) != 2 || rows[0].Intervals[1].Status != "Code Review" || !rows[0].Intervals[1].End.Equal(at(7, 9)) {
+		t.Fatalf("expected In Progress and Code Review intervals ending at completion, got %+v", rows[0].Intervals)
+	}
+
+	var out strings.Builder
+	mermaidChart{}.RenderGantt(&out, &ganttChart{Title: "EPIC-1 timeline", Rows: rows, Now: at(8, 9)})
+	if !strings.Contains(out.String(), "    In Progress :active, 2018-11-06T09:00, 2018-11-08T09:00\n") {
+		t.Fatalf("expected open interval marked active up to now, got:\n%s", out.String())
+	}
+}
+
+func TestComputeEpicBurn_ProjectsRemaining(t *testing.T) {
+	now := time.Date(2018, 11, 6, 12, 0, 0, 0, time.UTC)
+	var children []*Issue
+	// One child done per week over the last four weeks:
+	for w := 1; w <= 4; w++ {
+		resolved := now.AddDate(0, 0, -7*w+1)
+		child := completedIssue(fmt.Sprintf("A-%d", w), resolved.AddDate(0, 0, -2), resolved)
+		children = append(children, &child)
+	}
+	for i := 5; i <= 8; i++ {
+		children = append(children, &Issue{Key: fmt.Sprintf("A-%d", i)})
+	}
+
+	b := computeEpicBurn(&Issue{Key: "EPIC-1"}, children, now, 6, []float64{50, 85})
+	if b.Done != 4 || b.Total != 8 {
+		t.Fatalf("expected 4/8 done, got %d/%d", b.Done, b.Total)
+	}
+	if b.PerWeek < 0.66 || b.PerWeek > 0.67 {
+		t.Fatalf("expected 4 completions over 6 weeks, got %.2f/week", b.PerWeek)
+	}
+	if len(b.Days) != 2 || b.Days[0] < 15 || b.Days[1] < b.Days[0] {
+		t.Fatalf("expected about six weeks to finish 4 more at p50, p85 later, got %v", b.Days)
+	}
+
+	stalled := computeEpicBurn(&Issue{Key: "EPIC-2"}, children[4:], now, 6, []float64{50})
+	if stalled.Days != nil {
+		t.Fatalf("expected no projection without recent completions, got %v", stalled.Days)
+	}
+}
+
+func TestComputeEpicSize_NormalizesByVelocity(t *testing.T) {
+	now := time.Date(2018, 11, 6, 12, 0, 0, 0, time.UTC)
+	estimate := func(issue Issue, points string) Issue {
+		issue.Changelog.Histories = append(issue.Changelog.Histories, History{
+			Created: zonedTimestamp{now.AddDate(0, 0, -60)},
+			Items:   []HistoryItem{{Field: "Story Points", ToString: points}},
+		})
+		return issue
+	}
+
+	// 8 items and 16 points completed in 4 weeks is 4 items, 8 points per
+	// 2-week sprint:
+	var completed []Issue
+	for i := 0; i < 8; i++ {
+		completed = append(completed, estimate(completedIssue(fmt.Sprintf("A-%d", i), now.AddDate(0, 0, -20), now.AddDate(0, 0, -i)), "2"))
+	}
+	v := teamVelocity(completed, now, 4, 2)
+	if v.Items != 4 || v.Points != 8 {
+		t.Fatalf("expected 4 items and 8 points per sprint, got %+v", v)
+	}
+
+	epic := &Issue{Key: "E-1"}
+	done := estimate(completedIssue("B-1", now.AddDate(0, 0, -42), now.AddDate(0, 0, -7)), "13")
+	open := estimate(Issue{Key: "B-2"}, "11")
+	s := computeEpicSize(epic, []*Issue{&done, &open}, v, 2, now)
+	if s.Done != 1 || s.Total != 2 || s.DonePoints != 13 || s.TotalPoints != 24 {
+		t.Errorf("expected 1/2 done and 13/24 points, got %+v", s)
+	}
+	if s.Sprints != 3 || s.Weeks != 6 || s.ElapsedSprints != 3 {
+		t.Errorf("expected 3 sprints of work over 6 weeks, 3 sprints, got %+v", s)
+	}
+
+	// Unestimated children are sized by count:
+	unestimated := Issue{Key: "B-3"}
+	if s := computeEpicSize(epic, []*Issue{&unestimated}, v, 2, now); s.Estimated || s.Sprints != 0.25 {
+		t.Errorf("expected 1 child as a quarter sprint, got %+v", s)
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+// agileEpic is an epic as the agile API lists them, which knows epics by
+// their hierarchy rather than the epic name custom field:
+type agileEpic struct {
+	Id      int    `json:"id"`
+	Key     string `json:"key"`
+	Name    string `json:"name"`
+	Summary string `json:"summary"`
+	Done    bool   `json:"done"`
+}
+
+type pagedEpics struct {
+	StartAt int         `json:"startAt"`
+	IsLast  bool        `json:"isLast"`
+	Values  []agileEpic `json:"values"`
+}
+
+// useEpicAPI reports whether epics come from the agile API's epic resources,
+// which only JIRA has:
+func useEpicAPI() bool {
+	return backendName() == "jira" && savedFilterId(httpClient()) == 0
+}
+
+// fetchBoardEpics lists the board's open epics in rank order:
+func fetchBoardEpics(cl *http.Client, boardId int) ([]agileEpic, error) {
+	var epics []agileEpic
+	startAt := 0
+	for {
+		url := fmt.Sprintf("%s/rest/agile/1.0/board/%d/epic?done=false&startAt=%d", setting(cl, "JIRA_URL"), boardId, startAt)
+		body, err := cachedGet(fmt.Sprintf("board.%d.epics.%d.json", boardId, startAt), url, cl)
+		if err != nil {
+			return nil, err
+		}
+		page := &pagedEpics{}
+		err = decodeResponse(body, url, page)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, epic := range page.Values {
+			if !epic.Done {
+				epics = append(epics, epic)
+			}
+		}
+		if page.IsLast || len(page.Values) == 0 {
+			return epics, nil
+		}
+		startAt = page.StartAt + len(page.Values)
+	}
+}
+
+// fetchEpicIssues pages through the issues of an epic, with changelogs,
+// whatever links them to it:
+func fetchEpicIssues(cl *http.Client, epicKey string, jql string) ([]Issue, error) {
+	h := fnv.New32a()
+	h.Write([]byte(setting(cl, "JIRA_URL") + " " + jql))
+	jqlHash := h.Sum32()
+
+	query := url.QueryEscape(jql)
+
+	var issues []Issue
+	startAt, total := 0, 1
+	for startAt < total {
+		url := fmt.Sprintf("%s/rest/agile/1.0/epic/%s/issue?expand=changelog&startAt=%d&jql=%s", setting(cl, "JIRA_URL"), epicKey, startAt, query)
+		body, err := cachedGet(fmt.Sprintf("epic.%s.%08x.%d.json", epicKey, jqlHash, startAt), url, cl)
+		if err != nil {
+			return nil, err
+		}
+		page, count, err := decodeIssuesPage(body, url)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			break
+		}
+
+		issues = append(issues, page.Issues...)
+		total = page.Total
+		startAt = page.StartAt + count
+		fetched.Pages++
+		progress.Update("epic "+epicKey, len(issues), total, "issues", plural(fetched.Pages, "page"))
+	}
+	progress.Clear()
+
+	completeChangelogs(cl, issues)
+	resolveStatusCategories(cl, issues)
+	issues = filterIssues(cl, issues)
+	fetched.Issues += len(issues)
+	return issues, nil
+}
+
+// openEpicsByAPI returns the board's open epics in rank order, each with its
+// children, or ok false when the board has no epic support (e.g. team-managed
+// boards) and the caller should fall back to JQL.
+func openEpicsByAPI(cl *http.Client, boardId int) (epics []*Issue, children map[string][]*Issue, ok bool, err error) {
+	agileEpics, err := fetchBoardEpics(cl, boardId)
+	if err != nil {
+		log.Printf("epics of board %d: %v; selecting epics by JQL\n", boardId, err)
+		return nil, nil, false, nil
+	}
+
+	children = make(map[string][]*Issue)
+	for _, e := range agileEpics {
+		epic := &Issue{Key: e.Key}
+		epic.Fields.Summary = e.Summary
+		epics = append(epics, epic)
+
+		issues, err := fetchEpicIssues(cl, e.Key, "")
+		if err != nil {
+			return nil, nil, true, fmt.Errorf("epic %s: %w", e.Key, err)
+		}
+		for i := range issues {
+			children[e.Key] = append(children[e.Key], &issues[i])
+		}
+	}
+	return epics, children, true, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestOpenEpicsByAPI(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/agile/1.0/board/7/epic":
+			w.Write([]byte(`{"startAt": 0, "isLast": true, "values": [
+				{"id": 2, "key": "ABC-20", "summary": "Ranked first", "done": false},
+				{"id": 1, "key": "ABC-10", "summary": "Ranked second", "done": false},
+				{"id": 3, "key": "ABC-30", "summary": "Finished", "done": true}
+			]}`))
+		case "/rest/agile/1.0/epic/ABC-20/issue":
+			w.Write([]byte(`{"startAt": 0, "total": 2, "issues": [
+				{"key": "ABC-21", "fields": {"summary": "One"}},
+				{"key": "ABC-22", "fields": {"summary": "Two"}}
+			]}`))
+		case "/rest/agile/1.0/epic/ABC-10/issue":
+			w.Write([]byte(`{"startAt": 0, "total": 0, "issues": []}`))
+		default:
+			http.Error(w, `{"errorMessages": ["The board does not support epics"]}`, http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+	defer func() { fetched = fetchStats{} }()
+
+	epics, children, ok, err := openEpicsByAPI(srv.Client(), 7)
+	if err != nil || !ok {
+		t.Fatalf("expected epics from the API, got %v, %v", ok, err)
+	}
+	if len(epics) != 2 || epics[0].Key != "ABC-20" || epics[1].Key != "ABC-10" || epics[0].Fields.Summary != "Ranked first" {
+		t.Fatalf("expected the open epics in rank order, got %+v", epics)
+	}
+	if len(children["ABC-20"]) != 2 || len(children["ABC-10"]) != 0 {
+		t.Fatalf("expected ABC-20's 2 children, got %v", children)
+	}
+
+	// Boards without epics fall back to JQL:
+	if _, _, ok, err := openEpicsByAPI(srv.Client(), 8); ok || err != nil {
+		t.Fatalf("expected a fallback, got %v, %v", ok, err)
+	}
+}
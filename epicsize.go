@@ -0,0 +1,180 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// velocity is the board's completed items and points per sprint:
+type velocity struct {
+	Items  float64
+	Points float64
+}
+
+// teamVelocity averages the completions in the weeks to now over sprints of
+// sprintWeeks.
+func teamVelocity(issues []Issue, now time.Time, weeks int, sprintWeeks int) velocity {
+	since := now.AddDate(0, 0, -7*weeks)
+	var v velocity
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		if done, ok := issue.CompletedTime(); !ok || done.Before(since) || done.After(now) {
+			continue
+		}
+		v.Items++
+		if points, ok := issue.storyPoints(); ok {
+			v.Points += points
+		}
+	}
+	perSprint := float64(sprintWeeks) / float64(weeks)
+	return velocity{Items: v.Items * perSprint, Points: v.Points * perSprint}
+}
+
+// epicSize is an epic's size in children and points, done and in all, and
+// the calendar weeks since work on it started, both also in sprints of the
+// team's velocity:
+type epicSize struct {
+	Epic                    *Issue
+	Done, Total             int
+	DonePoints, TotalPoints float64
+	Estimated               bool
+	Weeks                   float64
+	Sprints, ElapsedSprints float64
+}
+
+// computeEpicSize sizes an epic by its children. Sprints of work count points
+// when the epic's children are estimated and the team completed points, else
+// children.
+func computeEpicSize(epic *Issue, children []*Issue, v velocity, sprintWeeks int, now time.Time) epicSize {
+	s := epicSize{Epic: epic}
+	var started time.Time
+	if t, ok := epic.StartedTime(); ok {
+		started = t
+	}
+	for _, child := range children {
+		s.Total++
+		points, estimated := child.storyPoints()
+		if estimated {
+			s.Estimated = true
+			s.TotalPoints += points
+		}
+		if _, ok := child.CompletedTime(); ok {
+			s.Done++
+			s.DonePoints += points
+		}
+		if t, ok := child.StartedTime(); ok && (started.IsZero() || t.Before(started)) {
+			started = t
+		}
+	}
+
+	if !started.IsZero() {
+		end := now
+		if done, ok := epic.CompletedTime(); ok {
+			end = done
+		}
+		s.Weeks = end.Sub(started).Hours() / (24 * 7)
+		s.ElapsedSprints = s.Weeks / float64(sprintWeeks)
+	}
+	switch {
+	case s.Estimated && v.Points > 0:
+		s.Sprints = s.TotalPoints / v.Points
+	case v.Items > 0:
+		s.Sprints = float64(s.Total) / v.Items
+	}
+	return s
+}
+
+func runEpicSizing(args []string) error {
+	fs := flag.NewFlagSet("epic sizing", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting epics; default='issuetype = Epic AND (statusCategory != Done OR resolved >= -90d)'")
+	weeks := fs.Int("weeks", 12, "weeks of completions to measure velocity over")
+	sprintWeeks := fs.Int("sprint-weeks", 2, "weeks per sprint")
+	max := fs.Float64("max", 3, "sprints of work or elapsed beyond which an epic is too large")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *weeks < 1 || *sprintWeeks < 1 {
+		return fmt.Errorf("-weeks and -sprint-weeks must be at least 1")
+	}
+
+	cl := httpClient()
+	boardId := boardArg(fs.Args())
+	completed, err := fetchBoardIssues(cl, boardId, fmt.Sprintf("resolved >= -%dd", 7**weeks))
+	if err != nil {
+		return err
+	}
+	epics, children, err := epicsByJQL(cl, boardId, reportJQL("epic-sizing", *jql, "issuetype = Epic AND (statusCategory != Done OR resolved >= -90d)"), true)
+	if err != nil {
+		return err
+	}
+
+	now := reportNow()
+	v := teamVelocity(completed, now, *weeks, *sprintWeeks)
+	fmt.Printf("Velocity: %.1f items", v.Items)
+	if v.Points > 0 {
+		fmt.Printf(" (%.1f points)", v.Points)
+	}
+	fmt.Printf(" per %d-week sprint over the last %s.\n", *sprintWeeks, plural(*weeks, "week"))
+	recordMetric("velocityItems", v.Items)
+	recordMetric("velocityPoints", v.Points)
+	if len(epics) == 0 {
+		fmt.Printf("No epics.\n")
+		return nil
+	}
+
+	var sizes []epicSize
+	for _, epic := range epics {
+		sizes = append(sizes, computeEpicSize(epic, children[epic.Key], v, *sprintWeeks, now))
+	}
+
+	if reportVerbosity != execView {
+		fmt.Printf("\nEpic sizes, in sprints of work at that velocity and sprints elapsed:\n")
+		fmt.Printf("  %-12s %9s %11s %6s %7s %7s summary\n", "epic", "done", "points", "weeks", "size", "elapsed")
+		for _, s := range sizes {
+			points := "-"
+			if s.Estimated {
+				points = fmt.Sprintf("%g/%g", s.DonePoints, s.TotalPoints)
+			}
+			fmt.Printf(
+				"  %-12s %9s %11s %6.1f %7.1f %7.1f %s\n",
+				s.Epic.Key,
+				fmt.Sprintf("%d/%d", s.Done, s.Total),
+				points,
+				s.Weeks,
+				s.Sprints,
+				s.ElapsedSprints,
+				s.Epic.DisplaySummary(),
+			)
+		}
+	}
+
+	var large []epicSize
+	for _, s := range sizes {
+		if s.Sprints > *max || s.ElapsedSprints > *max {
+			large = append(large, s)
+		}
+	}
+	sort.SliceStable(large, func(i, j int) bool {
+		return large[i].Sprints+large[i].ElapsedSprints > large[j].Sprints+large[j].ElapsedSprints
+	})
+	fmt.Printf("\nEpics larger than %g sprints:\n", *max)
+	shown := detailLines(len(large))
+	for _, s := range large[:shown] {
+		fmt.Printf("  %s: %.1f sprints of work, %.1f elapsed; %s\n", s.Epic.Key, s.Sprints, s.ElapsedSprints, s.Epic.DisplaySummary())
+	}
+	printOmitted("  ", shown, len(large))
+	if len(large) == 0 {
+		fmt.Printf("  none\n")
+	}
+	recordMetric("largeEpics", len(large))
+
+	if footer := reportFooter(); footer != "" {
+		fmt.Printf("\n%s\n", footer)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// APIError is a request the API answered with an error status. Errors for
+// particular failure modes wrap it, so errors.As finds the request context
+// whatever the mode.
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Status     string
+	// Detail is the server's explanation, if it gave one:
+	Detail string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: HTTP response %s", e.Method, e.URL, e.Status)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// AuthError is a 401 (bad or missing credentials) or 403 (no permission):
+type AuthError struct{ *APIError }
+
+func (e *AuthError) Unwrap() error { return e.APIError }
+
+// NotFoundError is a 404, usually a wrong board, filter or issue ID, or a
+// JIRA_URL that isn't the site's base URL:
+type NotFoundError struct{ *APIError }
+
+func (e *NotFoundError) Unwrap() error { return e.APIError }
+
+// RateLimitError is a 429; RetryAfter is how long the server asked to wait,
+// zero if it didn't say.
+type RateLimitError struct {
+	*APIError
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Unwrap() error { return e.APIError }
+
+// DecodeError is a response that didn't decode as expected; Saved is where
+// the body was saved for diagnosis, if anywhere.
+type DecodeError struct {
+	URL   string
+	Saved string
+	Err   error
+}
+
+func (e *DecodeError) Error() string {
+	if e.Saved != "" {
+		return fmt.Sprintf("decode %s: %v (response saved to %s)", e.URL, e.Err, e.Saved)
+	}
+	return fmt.Sprintf("decode %s: %v", e.URL, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// maxErrorDetail bounds the response text kept in an error:
+const maxErrorDetail = 300
+
+// errorDetail extracts the explanation from an error response: JIRA's
+// errorMessages and field errors, else the start of the body.
+func errorDetail(b []byte) string {
+	var jiraErr struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	if json.Unmarshal(b, &jiraErr) == nil && (len(jiraErr.ErrorMessages) > 0 || len(jiraErr.Errors) > 0) {
+		messages := jiraErr.ErrorMessages
+		for field, message := range jiraErr.Errors {
+			messages = append(messages, field+": "+message)
+		}
+		return strings.Join(messages, "; ")
+	}
+	return truncateWidth(strings.Join(strings.Fields(string(b)), " "), maxErrorDetail)
+}
+
+// newAPIError builds the error for a response with an error status, typed by
+// its failure mode:
+func newAPIError(req *http.Request, rsp *http.Response) error {
+	b, _ := ioutil.ReadAll(rsp.Body)
+	apiErr := &APIError{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: rsp.StatusCode,
+		Status:     rsp.Status,
+		Detail:     errorDetail(b),
+	}
+	switch rsp.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &AuthError{apiErr}
+	case http.StatusNotFound:
+		return &NotFoundError{apiErr}
+	case http.StatusTooManyRequests:
+		rateErr := &RateLimitError{APIError: apiErr}
+		if seconds, err := strconv.Atoi(rsp.Header.Get("Retry-After")); err == nil {
+			rateErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		return rateErr
+	}
+	return apiErr
+}
+
+// errorHint suggests what to do about an error, or "" if there's nothing
+// specific to say:
+func errorHint(err error) string {
+	var authErr *AuthError
+	var notFoundErr *NotFoundError
+	var rateErr *RateLimitError
+	var decodeErr *DecodeError
+	switch {
+	case errors.As(err, &authErr) && authErr.StatusCode == http.StatusForbidden:
+		return "the account lacks permission; ask a JIRA admin for browse access to the board's projects"
+	case errors.As(err, &authErr):
+		return "check JIRA_USERNAME and JIRA_PASSWORD (an API token on Cloud); config validate checks them"
+	case errors.As(err, &notFoundErr):
+		return "check the board ID (boards list finds them), filter ID or issue keys, and that JIRA_URL is the site's base URL"
+	case errors.As(err, &rateErr) && rateErr.RetryAfter > 0:
+		return fmt.Sprintf("JIRA is rate limiting requests; retry in %s, or run less often since cached responses are reused", rateErr.RetryAfter)
+	case errors.As(err, &rateErr):
+		return "JIRA is rate limiting requests; retry later, or run less often since cached responses are reused"
+	case errors.As(err, &decodeErr):
+		return "the response wasn't the JSON expected; check JIRA_URL points at the JIRA site and not a login page or proxy"
+	}
+	return ""
+}
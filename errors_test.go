@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIErrorTypes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/agile/1.0/board/1/issue":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/rest/agile/1.0/board/2/issue":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errorMessages": ["The requested board cannot be viewed because it either does not exist or you do not have permission to view it."]}`))
+		case "/rest/agile/1.0/board/3/issue":
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(`<html>login</html>`))
+		}
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+	os.Setenv("JIRA_NOCACHE", "1")
+	defer os.Unsetenv("JIRA_NOCACHE")
+	os.Setenv("JIRA_DEBUG_DIR", t.TempDir())
+	defer os.Unsetenv("JIRA_DEBUG_DIR")
+
+	fetch := func(boardId int) error {
+		_, err := fetchBoardIssues(srv.Client(), boardId, "")
+		// Wrapped as by -group-by, which shouldn't hide the type:
+		return fmt.Errorf("team core: %w", err)
+	}
+
+	var authErr *AuthError
+	if err := fetch(1); !errors.As(err, &authErr) || authErr.Method != http.MethodGet || !strings.Contains(authErr.URL, "/board/1/issue") {
+		t.Fatalf("expected an AuthError with the request, got %v", err)
+	}
+
+	var notFoundErr *NotFoundError
+	err := fetch(2)
+	if !errors.As(err, &notFoundErr) || !strings.HasPrefix(notFoundErr.Detail, "The requested board cannot be viewed") {
+		t.Fatalf("expected a NotFoundError with JIRA's message, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected every API error to be an APIError, got %v", err)
+	}
+	if !strings.Contains(errorHint(err), "boards list") {
+		t.Fatalf("expected a hint about board IDs, got %q", errorHint(err))
+	}
+
+	var rateErr *RateLimitError
+	if err := fetch(3); !errors.As(err, &rateErr) || rateErr.RetryAfter != 30*time.Second {
+		t.Fatalf("expected a RateLimitError asking to wait 30s, got %v", err)
+	}
+
+	var decodeErr *DecodeError
+	if err := fetch(4); !errors.As(err, &decodeErr) || decodeErr.Saved == "" {
+		t.Fatalf("expected a DecodeError naming the saved response, got %v", err)
+	}
+
+	if hint := errorHint(errors.New("plain")); hint != "" {
+		t.Fatalf("expected no hint for other errors, got %q", hint)
+	}
+}
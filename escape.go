@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Text from JIRA, like summaries and status names, is escaped for the format
+// it's written in so it can't inject markup or break the layout around it.
+// HTML goes through html/template, which escapes by context.
+
+// markdownEscaper backslash-escapes the characters Markdown (with GitHub's
+// tables) could read as markup anywhere in a line; line breaks would end a
+// list item or table row, so they become spaces.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "|", `\|`, "~", `\~`, "#", `\#`, "&", `\&`,
+	"\r\n", " ", "\n", " ", "\r", " ",
+)
+
+func markdownText(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// slackEscaper escapes what Slack's mrkdwn reads as links, mentions and
+// entities, in code blocks too.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func slackText(s string) string {
+	return slackEscaper.Replace(s)
+}
+
+// slackCodeBlock escapes text for a ``` block, breaking up any ``` in it
+// with a zero width space so the block can't be closed early.
+func slackCodeBlock(s string) string {
+	s = slackText(s)
+	for strings.Contains(s, "```") {
+		s = strings.Replace(s, "```", "`\u200b``", -1)
+	}
+	return s
+}
+
+// spreadsheetText keeps a string from being read as a formula by spreadsheets
+// it's pasted or imported into:
+func spreadsheetText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// writeMetricsCSV writes a report's metrics as metric,value rows, by name;
+// nested metrics have dotted names, as in spreadsheets.
+func writeMetricsCSV(metrics map[string]interface{}) ([]byte, error) {
+	flat, err := flattenMetrics(metrics)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(flat))
+	for name := range flat {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"metric", "value"})
+	for _, name := range names {
+		value, ok := flat[name].(string)
+		if ok {
+			value = spreadsheetText(value)
+		} else {
+			b, _ := json.Marshal(flat[name])
+			value = string(b)
+		}
+		w.Write([]string{spreadsheetText(name), value})
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+	"unicode/utf8"
+)
+
+func TestMarkdownText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Fix login", "Fix login"},
+		{"Use *bold* and _em_", `Use \*bold\* and \_em\_`},
+		{"[link](http://x) <b>", `\[link\](http://x) \<b\>`},
+		{"a | b\nc", `a \| b c`},
+		{`C:\path`, `C:\\path`},
+	}
+	for _, test := range tests {
+		if got := markdownText(test.in); got != test.want {
+			t.Errorf("markdownText(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestWriteMetricsCSV(t *testing.T) {
+	b, err := writeMetricsCSV(map[string]interface{}{
+		"wip":   3,
+		"delta": -2,
+		"team":  `=HYPERLINK("http://evil")`,
+		"note":  "a, \"quoted\"\nline",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "metric,value\ndelta,-2\nnote,\"a, \"\"quoted\"\"\nline\"\nteam,\"'=HYPERLINK(\"\"http://evil\"\")\"\nwip,3\n"
+	if string(b) != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b, want)
+	}
+}
+
+// summaryEscapeProblem checks a summary can't break out of the markup around
+// it in any output format, describing how it does if it can.
+func summaryEscapeProblem(summary string) error {
+	md := markdownText(summary)
+	if strings.ContainsAny(md, "\r\n") {
+		return fmt.Errorf("markdown %q spans lines", md)
+	}
+	// Every markup character is escaped, so unescaping gives the summary back:
+	var unescaped strings.Builder
+	for i := 0; i < len(md); i++ {
+		if md[i] == '\\' {
+			i++
+			if i == len(md) || !strings.ContainsRune("\\`*_[]<>|~#&", rune(md[i])) {
+				return fmt.Errorf("markdown %q has a stray backslash", md)
+			}
+		} else if strings.ContainsRune("`*_[]<>|~#&", rune(md[i])) {
+			return fmt.Errorf("markdown %q has unescaped %q", md, md[i])
+		}
+		unescaped.WriteByte(md[i])
+	}
+	if want := strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(summary); unescaped.String() != want {
+		return fmt.Errorf("markdown %q reads as %q, want %q", md, unescaped.String(), want)
+	}
+
+	block := slackCodeBlock(summary)
+	if strings.Contains(block, "```") || strings.ContainsAny(block, "<>") {
+		return fmt.Errorf("slack code block %q isn't escaped", block)
+	}
+
+	b, err := writeMetricsCSV(map[string]interface{}{"summary": summary})
+	if err != nil {
+		return err
+	}
+	records, err := csv.NewReader(strings.NewReader(string(b))).ReadAll()
+	if err != nil || len(records) != 2 {
+		return fmt.Errorf("csv %q doesn't read back: %v", b, err)
+	}
+	value := records[1][1]
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return fmt.Errorf("csv value %q reads as a formula", value)
+	}
+	// encoding/csv reads \r\n inside quotes as \n:
+	if value != spreadsheetText(summary) && value != strings.Replace(spreadsheetText(summary), "\r\n", "\n", -1) {
+		return fmt.Errorf("csv value %q, want %q", value, spreadsheetText(summary))
+	}
+
+	var html strings.Builder
+	issue := &Issue{Key: "A-1"}
+	issue.Fields.Summary = summary
+	err = digestTemplate.Execute(&html, struct {
+		Title string
+		D     *digest
+	}{"Digest", &digest{OverSLA: []*Issue{issue}}})
+	if err != nil {
+		return err
+	}
+	if i := strings.Index(html.String(), "<li>A-1 "); i < 0 || strings.ContainsAny(html.String()[i+len("<li>A-1 "):strings.LastIndex(html.String(), "</li>")], "<>") {
+		return fmt.Errorf("html summary isn't escaped: %s", html.String())
+	}
+	return nil
+}
+
+// summaryRunes are what random summaries are made of: markup characters
+// often, as chance alone would rarely pick them, and any other rune too.
+var summaryRunes = []rune("*_`[]()<>|~#&!=+-@\\\"', \t\r\nabc1é😀")
+
+func randomSummary(r *rand.Rand) string {
+	runes := make([]rune, r.Intn(40))
+	for i := range runes {
+		if r.Intn(4) == 0 {
+			runes[i] = rune(r.Intn(utf8.MaxRune))
+			if !utf8.ValidRune(runes[i]) {
+				runes[i] = utf8.RuneError
+			}
+		} else {
+			runes[i] = summaryRunes[r.Intn(len(summaryRunes))]
+		}
+	}
+	return string(runes)
+}
+
+func TestSummaryEscaping(t *testing.T) {
+	for _, summary := range []string{
+		"Fix login",
+		"*bold* _em_ `code` [a](b) <script>alert(1)</script>",
+		"| col | col |\n# heading\n- item",
+		"```\n<!channel> &amp;",
+		"=cmd|' /C calc'!A0",
+		"@SUM(1+1)",
+		"\"quoted\", comma\r\n",
+	} {
+		if err := summaryEscapeProblem(summary); err != nil {
+			t.Error(err)
+		}
+	}
+
+	var problem error
+	err := quick.Check(func(summary string) bool {
+		problem = summaryEscapeProblem(summary)
+		return problem == nil
+	}, &quick.Config{
+		MaxCount: 2000,
+		Values: func(args []reflect.Value, r *rand.Rand) {
+			args[0] = reflect.ValueOf(randomSummary(r))
+		},
+	})
+	if err != nil {
+		t.Fatalf("%v: %v", err, problem)
+	}
+}
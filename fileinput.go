@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// inputFile, when set, replaces the JIRA API with issues read from an export,
+// or from stdin when "-":
+var inputFile string
+
+// stdinInput holds stdin once read, as reports run more than once per
+// invocation with -group-by:
+var stdinInput []byte
+
+func readInputFile(filename string) ([]byte, error) {
+	if filename != "-" {
+		return readSnapshotFile(filename)
+	}
+	if stdinInput == nil {
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		stdinInput = b
+	}
+	return stdinInput, nil
+}
+
+// loadIssueFile reads issues from a JSON export (an API response with an
+// "issues" array, a bare array of issues or a stream of issue objects as jq
+// writes them, ideally with changelogs expanded), or a CSV or XML (RSS)
+// export from the issue navigator.
+func loadIssueFile(filename string) ([]Issue, error) {
+	b, err := readInputFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		return parseIssueCSV(bytes.NewReader(b))
+	}
+
+	trimmed := bytes.TrimSpace(b)
+	if strings.EqualFold(filepath.Ext(filename), ".xml") || (len(trimmed) > 0 && trimmed[0] == '<') {
+		return parseIssueXML(bytes.NewReader(trimmed))
+	}
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var issues []Issue
+		err = json.Unmarshal(trimmed, &issues)
+		return issues, err
+	}
+
+	var first struct {
+		Issues json.RawMessage `json:"issues"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(trimmed))
+	var raw json.RawMessage
+	if err = dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(raw, &first); err == nil && first.Issues != nil {
+		paged := &PagedIssues{}
+		err = json.Unmarshal(trimmed, paged)
+		return paged.Issues, err
+	}
+
+	// A stream of issues:
+	var issues []Issue
+	for {
+		var issue Issue
+		if err = json.Unmarshal(raw, &issue); err != nil {
+			return nil, fmt.Errorf("issue %d: %v", len(issues)+1, err)
+		}
+		issues = append(issues, issue)
+		if err = dec.Decode(&raw); err == io.EOF {
+			return issues, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// parseIssueCSV maps the columns of a CSV export onto issues. CSV exports carry
+// no changelog, so each issue gets one synthetic transition into its current
+// status, dated by "Status Category Changed" where exported; reports that
+// need the full history want a JSON export instead.
+func parseIssueCSV(r io.Reader) ([]Issue, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	// Exports repeat some columns (e.g. Sprint); the first one wins:
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; !ok {
+			columns[name] = i
+		}
+	}
+	if _, ok := columns["issue key"]; !ok {
+		return nil, fmt.Errorf("csv: no 'Issue key' column")
+	}
+
+	issues := make([]Issue, 0, len(records)-1)
+	for line, record := range records[1:] {
+		get := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		timestamp := func(column string) zonedTimestamp {
+			v := get(column)
+			if v == "" {
+				return zonedTimestamp{}
+			}
+			t, err := parseTimestamp(v)
+			if err != nil {
+				log.Printf("warning: csv line %d: unrecognized %s '%s'; treating as unset\n", line+2, column, v)
+			}
+			return zonedTimestamp{t}
+		}
+
+		issue := Issue{Id: get("issue id"), Key: get("issue key")}
+		f := &issue.Fields
+		f.Summary = get("summary")
+		f.Status.Name = get("status")
+		f.IssueType.Name = get("issue type")
+		f.Project.Key = get("project key")
+		f.Project.Name = get("project name")
+		f.Created = timestamp("created")
+		f.ResolutionDate = timestamp("resolved")
+
+		if name := get("assignee"); name != "" {
+			f.Assignee = &User{UserName: name, DisplayName: name, AccountId: get("assignee id")}
+		}
+		if name := get("priority"); name != "" {
+			f.Priority = &Priority{Name: name}
+		}
+
+		switch category := strings.ToLower(get("status category")); {
+		case category == "done" || !f.ResolutionDate.IsZero():
+			f.Status.StatusCategory.Key = "done"
+		case category == "to do":
+			f.Status.StatusCategory.Key = "new"
+		default:
+			f.Status.StatusCategory.Key = "indeterminate"
+		}
+
+		changed := timestamp("status category changed")
+		if changed.IsZero() {
+			changed = f.Created
+		}
+		if f.Status.Name != "" {
+			history := History{Created: changed, Items: []HistoryItem{{Field: "status", ToString: f.Status.Name}}}
+			if f.Assignee != nil {
+				history.Author = *f.Assignee
+			}
+			issue.Changelog.Histories = []History{history}
+		}
+
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// xmlExport is the issue navigator's XML export, an RSS feed with an item per
+// issue:
+type xmlExport struct {
+	Items []struct {
+		Key struct {
+			Id  string `xml:"id,attr"`
+			Key string `xml:",chardata"`
+		} `xml:"key"`
+		Summary string `xml:"summary"`
+		Project struct {
+			Key  string `xml:"key,attr"`
+			Name string `xml:",chardata"`
+		} `xml:"project"`
+		Type     string `xml:"type"`
+		Priority string `xml:"priority"`
+		Status   string `xml:"status"`
+		// Exports from JIRA 7 on carry the status category:
+		StatusCategory struct {
+			Key string `xml:"key,attr"`
+		} `xml:"statusCategory"`
+		Assignee struct {
+			UserName  string `xml:"username,attr"`
+			AccountId string `xml:"accountid,attr"`
+			Name      string `xml:",chardata"`
+		} `xml:"assignee"`
+		Labels   []string `xml:"labels>label"`
+		Created  string   `xml:"created"`
+		Resolved string   `xml:"resolved"`
+	} `xml:"channel>item"`
+}
+
+// parseIssueXML maps the items of an XML export onto issues. Like CSV
+// exports, XML exports carry no changelog, so each issue gets one synthetic
+// transition into its current status, dated when the issue was created.
+func parseIssueXML(r io.Reader) ([]Issue, error) {
+	export := &xmlExport{}
+	dec := xml.NewDecoder(r)
+	// Summaries and descriptions carry HTML entities:
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+	if err := dec.Decode(export); err != nil {
+		return nil, fmt.Errorf("xml: %v", err)
+	}
+
+	issues := make([]Issue, 0, len(export.Items))
+	for _, item := range export.Items {
+		timestamp := func(name string, v string) zonedTimestamp {
+			v = strings.TrimSpace(v)
+			if v == "" {
+				return zonedTimestamp{}
+			}
+			t, err := parseTimestamp(v)
+			if err != nil {
+				log.Printf("warning: xml %s: unrecognized %s '%s'; treating as unset\n", item.Key.Key, name, v)
+			}
+			return zonedTimestamp{t}
+		}
+
+		issue := Issue{Id: item.Key.Id, Key: strings.TrimSpace(item.Key.Key)}
+		if issue.Key == "" {
+			return nil, fmt.Errorf("xml: item %d has no key", len(issues)+1)
+		}
+		f := &issue.Fields
+		f.Summary = strings.TrimSpace(item.Summary)
+		f.Status.Name = strings.TrimSpace(item.Status)
+		f.IssueType.Name = strings.TrimSpace(item.Type)
+		f.Project.Key = item.Project.Key
+		f.Project.Name = strings.TrimSpace(item.Project.Name)
+		f.Labels = item.Labels
+		f.Created = timestamp("created", item.Created)
+		f.ResolutionDate = timestamp("resolved", item.Resolved)
+
+		// Unassigned issues export as "Unassigned", with no user or -1:
+		if (item.Assignee.UserName != "" && item.Assignee.UserName != "-1") || item.Assignee.AccountId != "" {
+			name := strings.TrimSpace(item.Assignee.Name)
+			f.Assignee = &User{UserName: item.Assignee.UserName, DisplayName: name, AccountId: item.Assignee.AccountId}
+			if f.Assignee.UserName == "" {
+				f.Assignee.UserName = name
+			}
+		}
+		if name := strings.TrimSpace(item.Priority); name != "" {
+			f.Priority = &Priority{Name: name}
+		}
+
+		switch category := item.StatusCategory.Key; {
+		case category == "done" || !f.ResolutionDate.IsZero():
+			f.Status.StatusCategory.Key = "done"
+		case category == "new":
+			f.Status.StatusCategory.Key = "new"
+		default:
+			f.Status.StatusCategory.Key = "indeterminate"
+		}
+
+		if f.Status.Name != "" {
+			history := History{Created: f.Created, Items: []HistoryItem{{Field: "status", ToString: f.Status.Name}}}
+			if f.Assignee != nil {
+				history.Author = *f.Assignee
+			}
+			issue.Changelog.Histories = []History{history}
+		}
+
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
+// fetchFileIssues serves fetchPagedIssues from the input file; the backlog is
+// taken to be the issues not yet started.
+func fetchFileIssues(resource string, jql string) ([]Issue, error) {
+	if jql != "" {
+		log.Printf("reading %s; JQL filter not applied: %s\n", inputFile, jql)
+	}
+
+	issues, err := loadIssueFile(inputFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", inputFile, err)
+	}
+
+	if inputFile == "-" {
+		fetched.record(false, reportNow())
+	} else if stat, err := os.Stat(inputFile); err == nil {
+		fetched.record(true, stat.ModTime())
+	}
+	fetched.Pages++
+
+	if resource == "backlog" {
+		backlog := issues[:0]
+		for _, issue := range issues {
+			if issue.Fields.Status.StatusCategory.Key == "new" {
+				backlog = append(backlog, issue)
+			}
+		}
+		issues = backlog
+	}
+	return issues, nil
+}
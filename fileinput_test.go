@@ -0,0 +1,126 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseIssueCSV(t *testing.T) {
+	csv := `Summary,Issue key,Issue id,Issue Type,Status,Project key,Priority,Assignee,Created,Resolved,Status Category,Status Category Changed,Sprint,Sprint
+Fix login,ABC-1,10001,Bug,In Progress,ABC,High,alice,01/Nov/18 9:00 AM,,In Progress,05/Nov/18 10:30 AM,Sprint 1,Sprint 2
+Add export,ABC-2,10002,Story,Done,ABC,Medium,,01/Nov/18 9:00 AM,06/Nov/18 4:00 PM,Done,06/Nov/18 4:00 PM,,
+Plan roadmap,ABC-3,10003,Task,Open,ABC,,,02/Nov/18 9:00 AM,,To Do,,,
+`
+	issues, err := parseIssueCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("expected 3 issues, got %d", len(issues))
+	}
+
+	inProgress := &issues[0]
+	if inProgress.Key != "ABC-1" || inProgress.Fields.Assignee == nil || inProgress.Fields.Assignee.UserName != "alice" {
+		t.Fatalf("expected ABC-1 assigned to alice, got %+v", inProgress)
+	}
+	started, ok := inProgress.StartedTime()
+	if !ok || !started.Equal(time.Date(2018, 11, 5, 10, 30, 0, 0, time.UTC)) {
+		t.Fatalf("expected ABC-1 started at status category change, got %v %v", started, ok)
+	}
+
+	done := &issues[1]
+	if completed, ok := done.CompletedTime(); !ok || completed.Day() != 6 {
+		t.Fatalf("expected ABC-2 completed Nov 6, got %v %v", completed, ok)
+	}
+	if issues[2].Fields.Status.StatusCategory.Key != "new" {
+		t.Fatalf("expected ABC-3 in the to do category, got %q", issues[2].Fields.Status.StatusCategory.Key)
+	}
+}
+
+func TestParseIssueXML(t *testing.T) {
+	export := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="0.92">
+<channel>
+	<title>Issue Navigator</title>
+	<item>
+		<title>[ABC-1] Fix login</title>
+		<project id="10000" key="ABC">Alpha &amp; Beta</project>
+		<key id="10001">ABC-1</key>
+		<summary>Fix login &ndash; SSO</summary>
+		<type id="1">Bug</type>
+		<priority id="2">High</priority>
+		<status id="3">In Progress</status>
+		<statusCategory id="4" key="indeterminate" colorName="yellow"/>
+		<assignee username="alice">Alice Example</assignee>
+		<labels><label>sso</label><label>urgent</label></labels>
+		<created>Thu, 1 Nov 2018 09:00:00 -0600</created>
+	</item>
+	<item>
+		<key id="10002">ABC-2</key>
+		<summary>Add export</summary>
+		<status id="6">Done</status>
+		<assignee username="-1">Unassigned</assignee>
+		<created>Thu, 1 Nov 2018 09:00:00 -0600</created>
+		<resolved>Tue, 6 Nov 2018 16:00:00 -0600</resolved>
+	</item>
+	<item>
+		<key id="10003">ABC-3</key>
+		<status id="1">Open</status>
+		<statusCategory id="2" key="new"/>
+		<created>Fri, 2 Nov 2018 09:00:00 -0600</created>
+	</item>
+</channel>
+</rss>`
+	issues, err := parseIssueXML(strings.NewReader(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 3 {
+		t.Fatalf("expected 3 issues, got %d", len(issues))
+	}
+
+	inProgress := &issues[0]
+	f := &inProgress.Fields
+	if inProgress.Key != "ABC-1" || f.Summary != "Fix login – SSO" || f.Project.Key != "ABC" || f.Project.Name != "Alpha & Beta" {
+		t.Fatalf("expected ABC-1 in project ABC with its summary unescaped, got %+v", inProgress)
+	}
+	if f.Assignee == nil || f.Assignee.UserName != "alice" || f.Assignee.DisplayName != "Alice Example" || len(f.Labels) != 2 {
+		t.Fatalf("expected ABC-1 assigned to alice with 2 labels, got %+v %v", f.Assignee, f.Labels)
+	}
+	started, ok := inProgress.StartedTime()
+	if !ok || !started.Equal(time.Date(2018, 11, 1, 15, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected ABC-1 started when created, got %v %v", started, ok)
+	}
+
+	done := &issues[1]
+	if done.Fields.Assignee != nil {
+		t.Fatalf("expected ABC-2 unassigned, got %+v", done.Fields.Assignee)
+	}
+	if completed, ok := done.CompletedTime(); !ok || completed.Day() != 6 {
+		t.Fatalf("expected ABC-2 completed Nov 6, got %v %v", completed, ok)
+	}
+	if issues[2].Fields.Status.StatusCategory.Key != "new" {
+		t.Fatalf("expected ABC-3 in the to do category, got %q", issues[2].Fields.Status.StatusCategory.Key)
+	}
+}
+
+func TestLoadIssueFile_Stdin(t *testing.T) {
+	stdinInput = []byte(`{"key": "ABC-1", "fields": {"summary": "One"}}
+{"key": "ABC-2", "fields": {"summary": "Two"}}
+`)
+	defer func() { stdinInput = nil }()
+
+	issues, err := loadIssueFile("-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 || issues[0].Key != "ABC-1" || issues[1].Key != "ABC-2" {
+		t.Fatalf("expected a stream of 2 issues, got %+v", issues)
+	}
+
+	stdinInput = []byte(`{"startAt": 0, "total": 1, "issues": [{"key": "ABC-3"}]}`)
+	if issues, err = loadIssueFile("-"); err != nil || len(issues) != 1 || issues[0].Key != "ABC-3" {
+		t.Fatalf("expected a page of 1 issue, got %+v, %v", issues, err)
+	}
+}
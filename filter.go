@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// filterFlag is the -filter saved filter ID, which takes precedence over
+// $JIRA_FILTER and the profile's filter:
+var filterFlag int
+
+// savedFilterId is the saved filter selecting the issues cl fetches instead
+// of a board, or 0:
+func savedFilterId(cl *http.Client) int {
+	if filterFlag != 0 {
+		return filterFlag
+	}
+	return settingInt(cl, "JIRA_FILTER", 0)
+}
+
+type savedFilter struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	JQL  string `json:"jql"`
+}
+
+func fetchSavedFilter(cl *http.Client, id int) (*savedFilter, error) {
+	url := fmt.Sprintf("%s/rest/api/2/filter/%d", setting(cl, "JIRA_URL"), id)
+	body, err := cachedGet(fmt.Sprintf("filter.%d.json", id), url, cl)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	filter := &savedFilter{}
+	if err = decodeResponse(body, url, filter); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+var orderByPattern = regexp.MustCompile(`(?i)\s*\border\s+by\b.*$`)
+
+// combineFilterJQL narrows a saved filter's query by a report's filter; the
+// saved ordering is dropped as it can't be nested.
+func combineFilterJQL(filterJQL string, jql string) string {
+	filterJQL = strings.TrimSpace(orderByPattern.ReplaceAllString(filterJQL, ""))
+	switch {
+	case filterJQL == "":
+		return jql
+	case jql == "":
+		return filterJQL
+	}
+	return fmt.Sprintf("(%s) AND (%s)", filterJQL, jql)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestCombineFilterJQL(t *testing.T) {
+	cases := []struct{ filter, jql, want string }{
+		{"project = ABC ORDER BY Rank ASC", "resolved >= -90d", "(project = ABC) AND (resolved >= -90d)"},
+		{"project = ABC order by created", "", "project = ABC"},
+		{"", "resolved >= -90d", "resolved >= -90d"},
+	}
+	for _, c := range cases {
+		if got := combineFilterJQL(c.filter, c.jql); got != c.want {
+			t.Errorf("combineFilterJQL(%q, %q) = %q, want %q", c.filter, c.jql, got, c.want)
+		}
+	}
+}
+
+func TestFetchJiraIssues_SavedFilter(t *testing.T) {
+	var searched string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/2/filter/42":
+			w.Write([]byte(`{"id": "42", "name": "Escalations", "jql": "labels = escalation ORDER BY created"}`))
+		case "/rest/api/2/search":
+			searched = r.URL.Query().Get("jql")
+			w.Write([]byte(`{"startAt": 0, "maxResults": 50, "total": 1, "issues": [{"key": "ABC-1"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+	filterFlag = 42
+	defer func() { filterFlag = 0 }()
+
+	issues, err := fetchJiraIssues(srv.Client(), 1, "issue", "resolution is EMPTY")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || issues[0].Key != "ABC-1" {
+		t.Fatalf("expected the filter's issue, got %+v", issues)
+	}
+	if searched != "(labels = escalation) AND (resolution is EMPTY)" {
+		t.Fatalf("expected the filter narrowed by the report's JQL, got %q", searched)
+	}
+
+	if backlog, err := fetchJiraIssues(srv.Client(), 1, "backlog", ""); err != nil || backlog != nil {
+		t.Fatalf("expected no backlog for a filter, got %+v, %v", backlog, err)
+	}
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// fixtureURL replaces the instance's URL in captured fixtures:
+const fixtureURL = "https://jira.example.com"
+
+// fixtureTextKeys hold free text, replaced wherever they appear:
+var fixtureTextKeys = map[string]bool{
+	"summary":     true,
+	"description": true,
+	"environment": true,
+	"body":        true,
+	"goal":        true,
+	"comment":     true,
+}
+
+// fixtureUserFields and fixtureTextFields are the changelog fields whose
+// values name people or hold free text:
+var (
+	fixtureUserFields = map[string]bool{"assignee": true, "reporter": true, "creator": true}
+	fixtureTextFields = map[string]bool{"summary": true, "description": true, "environment": true, "Comment": true, "Epic Name": true}
+)
+
+// fixtureStructural are custom field strings kept as they are, since they're
+// dates or numbers rather than text someone wrote:
+var fixtureStructural = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}[T0-9:.+\-Z]*|-?[0-9.]+)$`)
+
+// sanitizer anonymizes JIRA responses, consistently across them: each person
+// becomes "user-N" (displayed "User N") and each free text "text-N", so
+// fixtures still link up. Statuses, issue types, field IDs, issue keys and
+// timestamps are kept, as reports depend on them.
+type sanitizer struct {
+	baseURL string
+	users   map[string]int
+	texts   map[string]int
+}
+
+func newSanitizer(baseURL string) *sanitizer {
+	return &sanitizer{baseURL: strings.TrimSuffix(baseURL, "/"), users: make(map[string]int), texts: make(map[string]int)}
+}
+
+// user numbers a person by any of their identities, new ones joining the
+// number of those already seen:
+func (s *sanitizer) user(identities ...string) int {
+	n := 0
+	for _, id := range identities {
+		if id != "" && s.users[id] != 0 {
+			n = s.users[id]
+			break
+		}
+	}
+	if n == 0 {
+		n = len(s.users) + 1
+		for _, number := range s.users {
+			if number >= n {
+				n = number + 1
+			}
+		}
+	}
+	for _, id := range identities {
+		if id != "" {
+			s.users[id] = n
+		}
+	}
+	return n
+}
+
+func (s *sanitizer) text(original string) string {
+	if original == "" {
+		return ""
+	}
+	n, ok := s.texts[original]
+	if !ok {
+		n = len(s.texts) + 1
+		s.texts[original] = n
+	}
+	return fmt.Sprintf("text-%d", n)
+}
+
+func (s *sanitizer) url(original string) string {
+	if s.baseURL != "" && strings.HasPrefix(original, s.baseURL) {
+		return fixtureURL + original[len(s.baseURL):]
+	}
+	return original
+}
+
+func isUserObject(obj map[string]interface{}) bool {
+	_, display := obj["displayName"]
+	_, account := obj["accountId"]
+	_, email := obj["emailAddress"]
+	return display || account || email
+}
+
+func stringField(obj map[string]interface{}, key string) string {
+	s, _ := obj[key].(string)
+	return s
+}
+
+func (s *sanitizer) sanitizeUser(obj map[string]interface{}) {
+	n := s.user(stringField(obj, "accountId"), stringField(obj, "name"), stringField(obj, "key"), stringField(obj, "emailAddress"), stringField(obj, "displayName"))
+	for _, key := range []string{"accountId", "name", "key"} {
+		if _, ok := obj[key]; ok {
+			obj[key] = fmt.Sprintf("user-%d", n)
+		}
+	}
+	if _, ok := obj["displayName"]; ok {
+		obj["displayName"] = fmt.Sprintf("User %d", n)
+	}
+	if _, ok := obj["emailAddress"]; ok {
+		obj["emailAddress"] = fmt.Sprintf("user-%d@example.com", n)
+	}
+	delete(obj, "avatarUrls")
+	if self, ok := obj["self"].(string); ok {
+		obj["self"] = s.url(self)
+	}
+}
+
+// sanitizeItem anonymizes the values of a changelog item naming a person or
+// holding text:
+func (s *sanitizer) sanitizeItem(item map[string]interface{}) {
+	field := stringField(item, "field")
+	switch {
+	case fixtureUserFields[field]:
+		for _, pair := range [][2]string{{"from", "fromString"}, {"to", "toString"}} {
+			id, display := stringField(item, pair[0]), stringField(item, pair[1])
+			if id == "" && display == "" {
+				continue
+			}
+			n := s.user(id, display)
+			if id != "" {
+				item[pair[0]] = fmt.Sprintf("user-%d", n)
+			}
+			if display != "" {
+				item[pair[1]] = fmt.Sprintf("User %d", n)
+			}
+		}
+	case fixtureTextFields[field]:
+		for _, key := range []string{"fromString", "toString"} {
+			if text := stringField(item, key); text != "" {
+				item[key] = s.text(text)
+			}
+		}
+	}
+}
+
+// sanitize anonymizes a decoded response in place, returning it; key is the
+// one the value is found under.
+func (s *sanitizer) sanitize(v interface{}, key string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if isUserObject(t) {
+			s.sanitizeUser(t)
+			return t
+		}
+		if _, ok := t["field"]; ok {
+			s.sanitizeItem(t)
+		}
+		// Sorted, so pseudonyms number the same each capture:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := t[k]
+			str, isString := child.(string)
+			switch {
+			case k == "avatarUrls":
+				delete(t, k)
+			case isString && fixtureTextKeys[k]:
+				t[k] = s.text(str)
+			case isString && k == "name" && (key == "epic" || key == "project"):
+				t[k] = s.text(str)
+			case isString && strings.HasPrefix(k, "customfield_") && !fixtureStructural.MatchString(str):
+				t[k] = s.text(str)
+			default:
+				t[k] = s.sanitize(child, k)
+			}
+		}
+		return t
+	case []interface{}:
+		for i := range t {
+			t[i] = s.sanitize(t[i], key)
+		}
+		return t
+	case string:
+		return s.url(t)
+	}
+	return v
+}
+
+// sanitizeJSON anonymizes a JSON response, keeping numbers as they were:
+func (s *sanitizer) sanitizeJSON(b []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(s.sanitize(v, ""), "", " ")
+}
+
+// getRaw fetches a response body uncached:
+func getRaw(cl *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err = authenticate(cl, req); err != nil {
+		return nil, err
+	}
+	rsp, err := cl.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode >= 300 {
+		return nil, newAPIError(req, rsp)
+	}
+	return ioutil.ReadAll(rsp.Body)
+}
+
+var fixturesSubcommands = map[string]func(args []string) error{
+	"capture": runFixturesCapture,
+}
+
+func runFixtures(args []string) error {
+	return runSubcommand("fixtures", fixturesSubcommands, args)
+}
+
+func runFixturesCapture(args []string) error {
+	fs := flag.NewFlagSet("fixtures capture", flag.ContinueOnError)
+	dir := fs.String("dir", "jira-fixtures", "directory to write the fixtures to")
+	max := fs.Int("max", 10, "most issues and sprints to capture")
+	jql := fs.String("jql", "", "JQL filter selecting the issues to capture; default the board's")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *max < 1 {
+		return fmt.Errorf("-max must be at least 1")
+	}
+
+	boardId := boardArg(fs.Args())
+	agile := fmt.Sprintf("%s/rest/agile/1.0/board/%d", os.Getenv("JIRA_URL"), boardId)
+	api := os.Getenv("JIRA_URL") + "/rest/api/2/"
+	issueQuery := url.Values{"expand": {"changelog"}, "maxResults": {fmt.Sprint(*max)}}
+	if *jql != "" {
+		issueQuery.Set("jql", *jql)
+	}
+	fixtures := []struct {
+		name     string
+		url      string
+		optional bool
+	}{
+		{"serverInfo.json", api + "serverInfo", false},
+		{fmt.Sprintf("board.%d.json", boardId), agile, false},
+		{fmt.Sprintf("board.%d.configuration.json", boardId), agile + "/configuration", true},
+		{fmt.Sprintf("board.%d.issues.json", boardId), agile + "/issue?" + issueQuery.Encode(), false},
+		// Kanban boards have no sprints:
+		{fmt.Sprintf("board.%d.sprints.json", boardId), fmt.Sprintf("%s/sprint?maxResults=%d", agile, *max), true},
+		{"field.json", api + "field", true},
+		{"status.json", api + "status", true},
+	}
+
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		return err
+	}
+	cl := httpClient()
+	s := newSanitizer(os.Getenv("JIRA_URL"))
+	written := 0
+	for _, f := range fixtures {
+		b, err := getRaw(cl, f.url)
+		if err == nil {
+			b, err = s.sanitizeJSON(b)
+		}
+		if err != nil && f.optional {
+			fmt.Printf("skipped %s: %v\n", f.name, err)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", f.name, err)
+		}
+		filename := filepath.Join(*dir, f.name)
+		if err = writeFileAtomic(filename, append(b, '\n'), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("wrote %s\n", filename)
+		written++
+	}
+	fmt.Printf("\n%s captured, with %s and %s anonymized; review them before sharing.\n",
+		plural(written, "fixture"), plural(len(s.texts), "text"), plural(countUsers(s.users), "person"))
+	return nil
+}
+
+// countUsers counts the people numbered, each seen by one or more identities:
+func countUsers(users map[string]int) int {
+	seen := make(map[int]bool)
+	for _, n := range users {
+		seen[n] = true
+	}
+	return len(seen)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeJSON(t *testing.T) {
+	s := newSanitizer("https://jira.corp.local")
+	raw := `{
+ "self": "https://jira.corp.local/rest/agile/1.0/board/7/issue",
+ "issues": [{
+  "key": "APP-1",
+  "fields": {
+   "summary": "Fix Acme's invoice export",
+   "created": "2018-11-01T09:00:00.000-0500",
+   "status": {"name": "In Progress"},
+   "assignee": {"name": "jdoe", "displayName": "Jane Doe", "emailAddress": "jane@corp.local", "avatarUrls": {"48x48": "https://x"}},
+   "customfield_10002": 3,
+   "customfield_10010": "Acme rollout",
+   "customfield_10020": {"value": "High"},
+   "project": {"key": "APP", "name": "Acme Billing"}
+  },
+  "changelog": {"histories": [{
+   "author": {"name": "jdoe", "displayName": "Jane Doe"},
+   "created": "2018-11-02T10:00:00.000-0500",
+   "items": [
+    {"field": "assignee", "from": null, "to": "jdoe", "toString": "Jane Doe"},
+    {"field": "summary", "fromString": "Fix export", "toString": "Fix Acme's invoice export"},
+    {"field": "status", "fromString": "To Do", "toString": "In Progress"}
+   ]
+  }]}
+ }]
+}`
+	b, err := s.sanitizeJSON([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+	for _, leaked := range []string{"jdoe", "Jane", "corp.local", "Acme", "avatarUrls"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("expected %q anonymized, got:\n%s", leaked, out)
+		}
+	}
+
+	var got struct {
+		Self   string
+		Issues []struct {
+			Key       string
+			Fields    map[string]json.RawMessage
+			Changelog struct {
+				Histories []struct {
+					Author  map[string]string
+					Created string
+					Items   []map[string]*string
+				}
+			}
+		}
+	}
+	if err = json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Self != "https://jira.example.com/rest/agile/1.0/board/7/issue" {
+		t.Errorf("expected the instance URL replaced, got %q", got.Self)
+	}
+	issue := got.Issues[0]
+	fields := issue.Fields
+	for name, want := range map[string]string{
+		"created":           `"2018-11-01T09:00:00.000-0500"`,
+		"customfield_10002": `3`,
+		"customfield_10020": `{"value":"High"}`,
+	} {
+		if compact := strings.Join(strings.Fields(string(fields[name])), ""); compact != want {
+			t.Errorf("expected %s %s, got %s", name, want, compact)
+		}
+	}
+	history := issue.Changelog.Histories[0]
+	if issue.Key != "APP-1" || history.Created != "2018-11-02T10:00:00.000-0500" {
+		t.Errorf("expected keys and timestamps kept, got %s %s", issue.Key, history.Created)
+	}
+	// The same person and text map to the same pseudonyms everywhere:
+	if history.Author["name"] != "user-1" || history.Author["displayName"] != "User 1" {
+		t.Errorf("expected the author as user-1, got %v", history.Author)
+	}
+	items := history.Items
+	if *items[0]["to"] != "user-1" || *items[0]["toString"] != "User 1" || items[0]["from"] != nil {
+		t.Errorf("expected the assignee change to user-1, got %v", items[0])
+	}
+	summary := `"` + *items[1]["toString"] + `"`
+	if string(fields["summary"]) != summary || !strings.HasPrefix(summary, `"text-`) || *items[1]["fromString"] == *items[1]["toString"] {
+		t.Errorf("expected the summary change anonymized consistently, got %v", items[1])
+	}
+	if *items[2]["toString"] != "In Progress" {
+		t.Errorf("expected statuses kept, got %v", items[2])
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// fetchStats records what was fetched to produce a report, so readers can judge
+// how fresh and complete the data behind it is.
+type fetchStats struct {
+	Requests  int
+	CacheHits int
+	Pages     int
+	Issues    int
+
+	// Oldest and Newest bound when the data was fetched from JIRA; cached
+	// responses date from when they were written.
+	Oldest time.Time
+	Newest time.Time
+
+	// Calls, Bytes and Elapsed measure API usage: requests sent over the
+	// network (failed or not), response bytes and time spent waiting.
+	Calls   int
+	Bytes   int64
+	Elapsed time.Duration
+
+	// Inaccessible and HiddenChangelogs list issues permissions hid, whole or
+	// their history; Invisible counts issues the viewAs user couldn't see.
+	Inaccessible     []string
+	HiddenChangelogs []string
+	Invisible        int
+
+	// Truncated lists issues whose changelogs failed to fetch in full; Gaps
+	// describe other data reports went without.
+	Truncated []string
+	Gaps      []string
+	// Migrated lists issues whose history looks migrated, with gaps:
+	Migrated []string
+}
+
+var fetched fetchStats
+
+func (s *fetchStats) record(cacheHit bool, at time.Time) {
+	s.Requests++
+	if cacheHit {
+		s.CacheHits++
+	}
+	if s.Oldest.IsZero() || at.Before(s.Oldest) {
+		s.Oldest = at
+	}
+	if at.After(s.Newest) {
+		s.Newest = at
+	}
+}
+
+// usage summarizes API usage for the run:
+func (s *fetchStats) usage() string {
+	return fmt.Sprintf("%s, %s in %s", plural(s.Calls, "API call"), formatBytes(s.Bytes), s.Elapsed.Round(time.Millisecond))
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
+
+type serverInfo struct {
+	Version        string `json:"version"`
+	DeploymentType string `json:"deploymentType"`
+}
+
+func fetchServerInfo(cl *http.Client) (*serverInfo, error) {
+	url := setting(cl, "JIRA_URL") + "/rest/api/2/serverInfo"
+	body, err := cachedGet("serverInfo.json", url, cl)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	info := &serverInfo{}
+	err = decodeResponse(body, url, info)
+	if err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, word)
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
+
+// source describes where the data came from: the server version, or the
+// export file it was read from.
+func (info *serverInfo) source() string {
+	version := "JIRA version unknown"
+	if info != nil && info.Version != "" {
+		version = "JIRA " + info.Version
+		if info.DeploymentType != "" {
+			version += " " + info.DeploymentType
+		}
+	}
+	return version + ", agile API 1.0"
+}
+
+// footer summarizes the fetch stats and data source in one line:
+func (s *fetchStats) footer(source string) string {
+	hitRatio := 0
+	if s.Requests > 0 {
+		hitRatio = 100 * s.CacheHits / s.Requests
+	}
+
+	const layout = "2006-01-02 15:04 MST"
+	fetchedAt := displayTime(s.Oldest).Format(layout)
+	if !s.Newest.Equal(s.Oldest) {
+		fetchedAt += " to " + displayTime(s.Newest).Format(layout)
+	}
+
+	footer := fmt.Sprintf(
+		"%s analyzed from %s (%d%% of %s cached), fetched %s; %s",
+		plural(s.Issues, "issue"),
+		plural(s.Pages, "page"),
+		hitRatio,
+		plural(s.Requests, "request"),
+		fetchedAt,
+		source,
+	)
+	if !asOf.IsZero() {
+		footer += "; as of " + displayTime(asOf).Format("2006-01-02") + ", rewound from changelogs"
+	}
+	for _, note := range append(s.permissionNotes(), s.partialNotes()...) {
+		footer += "; " + note
+	}
+	return footer
+}
+
+// reportFooter returns the footer for everything fetched so far, or "" if the
+// command fetched nothing:
+func reportFooter() string {
+	if fetched.Pages == 0 {
+		return ""
+	}
+
+	source, err := currentSource()
+	if err != nil {
+		return ""
+	}
+	return fetched.footer(source.Describe(httpClient()))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFetchStats_Footer(t *testing.T) {
+	s := &fetchStats{}
+	s.record(true, time.Date(2018, 11, 6, 9, 0, 0, 0, time.UTC))
+	s.record(false, time.Date(2018, 11, 6, 12, 30, 0, 0, time.UTC))
+	s.record(true, time.Date(2018, 11, 6, 10, 0, 0, 0, time.UTC))
+	s.record(false, time.Date(2018, 11, 6, 12, 30, 0, 0, time.UTC))
+	s.Pages = 3
+	s.Issues = 120
+
+	footer := s.footer((&serverInfo{Version: "7.13.0", DeploymentType: "Server"}).source())
+	expected := "120 issues analyzed from 3 pages (50% of 4 requests cached), fetched 2018-11-06 09:00 UTC to 2018-11-06 12:30 UTC; JIRA 7.13.0 Server, agile API 1.0"
+	if footer != expected {
+		t.Fatalf("expected %q, got %q", expected, footer)
+	}
+}
+
+func TestFetchStats_Footer_UnknownVersion(t *testing.T) {
+	s := &fetchStats{Pages: 1, Issues: 1}
+	s.record(false, time.Date(2018, 11, 6, 12, 0, 0, 0, time.UTC))
+
+	var info *serverInfo
+	footer := s.footer(info.source())
+	expected := "1 issue analyzed from 1 page (0% of 1 request cached), fetched 2018-11-06 12:00 UTC; JIRA version unknown, agile API 1.0"
+	if footer != expected {
+		t.Fatalf("expected %q, got %q", expected, footer)
+	}
+}
+
+func TestFetchStats_CheckBudget(t *testing.T) {
+	s := &fetchStats{Calls: 2, Bytes: 3 << 20}
+	if err := s.checkBudget(APIBudget{}); err != nil {
+		t.Fatalf("expected no limit by default, got %v", err)
+	}
+	if err := s.checkBudget(APIBudget{Requests: 3}); err != nil {
+		t.Fatalf("expected a third call within budget, got %v", err)
+	}
+	if err := s.checkBudget(APIBudget{Requests: 2}); err == nil || err.Error() != "API budget of 2 requests exhausted" {
+		t.Fatalf("expected request budget exhausted, got %v", err)
+	}
+	if err := s.checkBudget(APIBudget{Bytes: 1 << 20}); err == nil || err.Error() != "API budget of 1.0 MB exhausted" {
+		t.Fatalf("expected byte budget exhausted, got %v", err)
+	}
+	if usage := s.usage(); usage != "2 API calls, 3.0 MB in 0s" {
+		t.Fatalf("expected usage summary, got %q", usage)
+	}
+}
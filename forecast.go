@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// forecastRecord is a persisted forecast, kept so it can later be compared
+// with when the work was actually done.
+type forecastRecord struct {
+	Made    time.Time `json:"made"`
+	BoardId int       `json:"boardId"`
+	Name    string    `json:"name"`
+	Keys    []string  `json:"keys"`
+	// Days maps each percentile ("50", "85", ...) to forecast business days:
+	Days map[string]int `json:"days"`
+}
+
+const defaultForecastLog = "jira-forecasts.jsonl"
+
+// addBusinessDays returns the date n weekdays after t:
+func addBusinessDays(t time.Time, n int) time.Time {
+	for n > 0 {
+		t = t.AddDate(0, 0, 1)
+		if t.Weekday() != time.Saturday && t.Weekday() != time.Sunday {
+			n--
+		}
+	}
+	return t
+}
+
+// dailyThroughput counts completions per business day over the history days
+// before now:
+func dailyThroughput(issues []Issue, now time.Time, historyDays int) []int {
+	ends := businessDays(now.AddDate(0, 0, -1), historyDays)
+	counts := make([]int, len(ends))
+	for i := range issues {
+		completed, ok := issues[i].CompletedTime()
+		if !ok || issues[i].IsEpic() {
+			continue
+		}
+		for d, end := range ends {
+			// Weekend completions count toward the Monday after:
+			start := end.Add(-24 * time.Hour)
+			if d > 0 {
+				start = ends[d-1]
+			}
+			if completed.After(start) && !completed.After(end) {
+				counts[d]++
+				break
+			}
+		}
+	}
+	return counts
+}
+
+// monteCarlo simulates how many business days it takes to finish remaining
+// items by sampling past daily throughput, returning the sorted outcomes.
+func monteCarlo(throughput []int, remaining int, trials int, rng *rand.Rand) []int {
+	// Give up on a trial after ten years of business days:
+	const maxDays = 2600
+
+	outcomes := make([]int, 0, trials)
+	for t := 0; t < trials; t++ {
+		done, days := 0, 0
+		for done < remaining && days < maxDays {
+			done += throughput[rng.Intn(len(throughput))]
+			days++
+		}
+		outcomes = append(outcomes, days)
+	}
+	sort.Ints(outcomes)
+	return outcomes
+}
+
+func appendForecast(filename string, record *forecastRecord) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	b, err = encryptLine(b)
+	if err != nil {
+		return err
+	}
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+func loadForecasts(filename string) ([]forecastRecord, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []forecastRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		b, err := decryptLine([]byte(line))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		var record forecastRecord
+		if err := json.Unmarshal(b, &record); err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+func runForecast(args []string) error {
+	fs := flag.NewFlagSet("forecast", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting the remaining work; default='sprint in openSprints() AND statusCategory != Done'")
+	name := fs.String("name", "", "label for the forecast; default is the JQL filter")
+	weeks := fs.Int("weeks", 12, "weeks of throughput history to sample")
+	trials := fs.Int("trials", 10000, "number of Monte Carlo trials")
+	seed := fs.Int64("seed", 1, "random seed, for repeatable forecasts")
+	logFile := fs.String("log", defaultForecastLog, "file forecasts are appended to; empty to not record")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *weeks < 1 {
+		return fmt.Errorf("-weeks must be at least 1")
+	}
+	if *trials < 1 {
+		return fmt.Errorf("-trials must be at least 1")
+	}
+
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	cl := httpClient()
+	boardId := boardArg(fs.Args())
+	filter := reportJQL("forecast", *jql, "sprint in openSprints() AND statusCategory != Done")
+	remaining, err := fetchBoardIssues(cl, boardId, filter)
+	if err != nil {
+		return err
+	}
+	now := reportNow()
+	history, err := fetchCompletedSince(cl, boardId, "forecast-history", now.AddDate(0, 0, -7**weeks))
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	for i := range remaining {
+		issue := &remaining[i]
+		if _, done := issue.CompletedTime(); !done && !issue.IsEpic() {
+			keys = append(keys, issue.Key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return lessIssueKey(keys[i], keys[j]) })
+
+	throughput := dailyThroughput(history, now, 7**weeks)
+	total := 0
+	for _, n := range throughput {
+		total += n
+	}
+	if total == 0 {
+		return fmt.Errorf("no completed issues in the last %s to forecast from", plural(*weeks, "week"))
+	}
+
+	outcomes := monteCarlo(throughput, len(keys), *trials, rand.New(rand.NewSource(*seed)))
+
+	record := &forecastRecord{Made: now, BoardId: boardId, Name: *name, Keys: keys, Days: make(map[string]int)}
+	if record.Name == "" {
+		record.Name = filter
+	}
+
+	fmt.Printf("Forecast for %s remaining (%s), from %.1f items/day over %s:\n", plural(len(keys), "item"), record.Name, float64(total)/float64(len(throughput)), plural(*weeks, "week"))
+	for _, p := range percentiles {
+		days := percentile(outcomes, p)
+		record.Days[strings.TrimPrefix(percentileLabel(p), "p")] = days
+		fmt.Printf("  %4s: %s (%s)\n", percentileLabel(p), addBusinessDays(now, days).Format("Mon Jan 02"), plural(days, "business day"))
+	}
+
+	if *logFile != "" && !rerendering {
+		return appendForecast(*logFile, record)
+	}
+	return nil
+}
+
+func runForecastAccuracy(args []string) error {
+	fs := flag.NewFlagSet("forecast-accuracy", flag.ContinueOnError)
+	logFile := fs.String("log", defaultForecastLog, "file forecasts were recorded in")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	records, err := loadForecasts(*logFile)
+	if err != nil {
+		return err
+	}
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	// Errors are measured from the percentile nearest the median, and
+	// delivery checked against the highest:
+	center, upper := percentiles[0], percentiles[len(percentiles)-1]
+	for _, p := range percentiles {
+		if math.Abs(p-50) < math.Abs(center-50) {
+			center = p
+		}
+	}
+	recorded := func(record *forecastRecord, p float64) (int, bool) {
+		days, ok := record.Days[strings.TrimPrefix(percentileLabel(p), "p")]
+		return days, ok
+	}
+
+	cl := httpClient()
+	fmt.Printf("Forecasts vs actual delivery (error in business days from %s, + is late):\n", percentileLabel(center))
+	fmt.Printf("  %-10s %-24s %5s", "made", "name", "items")
+	for _, p := range percentiles {
+		fmt.Printf(" %10s", percentileLabel(p))
+	}
+	fmt.Printf(" %10s %6s %7s\n", "actual", "error", "in "+percentileLabel(upper))
+
+	var errors []float64
+	within, finished := 0, 0
+	for _, record := range records {
+		actual, done, err := deliveredTime(cl, &record)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("  %-10s %s %5d", record.Made.Format("2006-01-02"), padRight(record.Name, 24), len(record.Keys))
+		for _, p := range percentiles {
+			if days, ok := recorded(&record, p); ok {
+				fmt.Printf(" %10s", addBusinessDays(record.Made, days).Format("Mon Jan 02"))
+			} else {
+				fmt.Printf(" %10s", "-")
+			}
+		}
+		if !done {
+			fmt.Printf(" %10s\n", "pending")
+			continue
+		}
+
+		took := DateOf(record.Made).BusinessDaysUntil(DateOf(actual))
+		expected, hasCenter := recorded(&record, center)
+		bound, hasUpper := recorded(&record, upper)
+		errorText, inRange := "-", "-"
+		if hasCenter {
+			finished++
+			errors = append(errors, float64(took-expected))
+			errorText = fmt.Sprintf("%+d", took-expected)
+		}
+		if hasUpper {
+			inRange = "no"
+			if took <= bound {
+				inRange = "yes"
+				if hasCenter {
+					within++
+				}
+			}
+		}
+		fmt.Printf(" %10s %6s %7s\n", actual.Format("Mon Jan 02"), errorText, inRange)
+	}
+
+	if finished > 0 {
+		absolute := make([]float64, len(errors))
+		for i, e := range errors {
+			if e < 0 {
+				e = -e
+			}
+			absolute[i] = e
+		}
+		fmt.Printf("\n%d of %s delivered within %s; mean %s error %+.1f days (mean absolute %.1f)\n",
+			within, plural(finished, "finished forecast"), percentileLabel(upper), percentileLabel(center), meanFloat(errors), meanFloat(absolute))
+	}
+	return nil
+}
+
+// deliveredTime finds when the last of the forecast items was completed, or
+// false while any is still open.
+func deliveredTime(cl *http.Client, record *forecastRecord) (time.Time, bool, error) {
+	if len(record.Keys) == 0 {
+		return record.Made, true, nil
+	}
+
+	issues, err := fetchBoardIssues(cl, record.BoardId, fmt.Sprintf("key in (%s)", strings.Join(record.Keys, ", ")))
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	wanted := make(map[string]bool, len(record.Keys))
+	for _, key := range record.Keys {
+		wanted[key] = true
+	}
+
+	var last time.Time
+	found := 0
+	for i := range issues {
+		issue := &issues[i]
+		if !wanted[issue.Key] {
+			continue
+		}
+		found++
+		completed, ok := issue.CompletedTime()
+		if !ok {
+			return time.Time{}, false, nil
+		}
+		if completed.After(last) {
+			last = completed
+		}
+	}
+	// Items that left the board can't be judged:
+	if found < len(record.Keys) {
+		return time.Time{}, false, nil
+	}
+	return last, true, nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMonteCarlo_ConstantThroughput(t *testing.T) {
+	outcomes := monteCarlo([]int{2}, 9, 100, rand.New(rand.NewSource(1)))
+	if len(outcomes) != 100 || outcomes[0] != 5 || outcomes[99] != 5 {
+		t.Fatalf("expected every trial to take 5 days at 2/day for 9 items, got %v..%v", outcomes[0], outcomes[len(outcomes)-1])
+	}
+}
+
+func TestAddBusinessDays_SkipsWeekends(t *testing.T) {
+	friday := time.Date(2018, 11, 2, 12, 0, 0, 0, time.UTC)
+	if d := addBusinessDays(friday, 1); d.Weekday() != time.Monday || d.Day() != 5 {
+		t.Fatalf("expected Mon Nov 05 one business day after Fri Nov 02, got %s", d.Format("Mon Jan 02"))
+	}
+}
+
+func TestDailyThroughput(t *testing.T) {
+	now := time.Date(2018, 11, 7, 12, 0, 0, 0, time.UTC)
+	issues := []Issue{
+		completedIssue("A-1", time.Date(2018, 11, 1, 9, 0, 0, 0, time.UTC), time.Date(2018, 11, 5, 9, 0, 0, 0, time.UTC)),
+		completedIssue("A-2", time.Date(2018, 11, 1, 9, 0, 0, 0, time.UTC), time.Date(2018, 11, 5, 15, 0, 0, 0, time.UTC)),
+		// Saturday counts toward Monday:
+		completedIssue("A-3", time.Date(2018, 11, 1, 9, 0, 0, 0, time.UTC), time.Date(2018, 11, 3, 9, 0, 0, 0, time.UTC)),
+	}
+
+	counts := dailyThroughput(issues, now, 7)
+	// Business days Wed Oct 31 .. Tue Nov 06:
+	expected := []int{0, 0, 0, 3, 0}
+	if len(counts) != len(expected) {
+		t.Fatalf("expected %d business days, got %v", len(expected), counts)
+	}
+	for i := range expected {
+		if counts[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, counts)
+		}
+	}
+}
+
+func TestForecastAccuracy_ConfiguredPercentiles(t *testing.T) {
+	dir := t.TempDir()
+	logFile := dir + "/forecasts.jsonl"
+	made := time.Date(2018, 11, 5, 12, 0, 0, 0, time.UTC)
+	name := "Überarbeitung der Zahlungsabwicklung – Phase 2"
+	if err := appendForecast(logFile, &forecastRecord{Made: made, Name: name, Days: map[string]int{"50": 2, "90": 4}}); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("JIRA_PERCENTILES", "50,90")
+	defer os.Unsetenv("JIRA_PERCENTILES")
+	config = &Config{}
+
+	out, err := captureOutput(func() error { return runForecastAccuracy([]string{"-log", logFile}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"p90", "in p90", "Überarbeitung der Zahlu…", "-2", "1 of 1 finished forecast delivered within p90; mean p50 error -2.0"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in output:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out, "p85") {
+		t.Errorf("expected no p85 column without it configured:\n%s", out)
+	}
+}
+
+func TestRunForecast_RejectsNoTrials(t *testing.T) {
+	for _, args := range [][]string{{"-trials", "0"}, {"-trials", "-1"}, {"-weeks", "0"}} {
+		if err := runForecast(append(args, "-log", "")); err == nil {
+			t.Errorf("expected %v rejected before fetching", args)
+		}
+	}
+}
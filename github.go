@@ -0,0 +1,332 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// githubSource reads issues on a GitHub Projects (v2) board. JIRA_URL is the
+// owner (https://github.com/orgs/<org> or https://github.com/<user>), the board
+// ID is the project number and JIRA_PASSWORD a token with read:project scope.
+// Status field changes on the project become transitions.
+type githubSource struct{}
+
+const githubItemsQuery = `query($owner: String!, $number: Int!, $cursor: String) {
+  repositoryOwner(login: $owner) {
+    ... on ProjectV2Owner {
+      projectV2(number: $number) {
+        items(first: 50, after: $cursor) {
+          pageInfo { hasNextPage endCursor }
+          nodes {
+            status: fieldValueByName(name: "Status") { ... on ProjectV2ItemFieldSingleSelectValue { name } }
+            content {
+              ... on Issue {
+                id number title createdAt closedAt
+                repository { name }
+                assignees(first: 1) { nodes { login name email } }
+                timelineItems(first: 100, itemTypes: [PROJECT_V2_ITEM_STATUS_CHANGED_EVENT]) {
+                  pageInfo { hasNextPage endCursor }
+                  nodes { ...statusChange }
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}
+` + githubStatusChangeFragment
+
+// githubTimelineQuery fetches the rest of an issue's status changes, when it
+// has more than fit in the items query:
+const githubTimelineQuery = `query($id: ID!, $cursor: String) {
+  node(id: $id) {
+    ... on Issue {
+      timelineItems(first: 100, after: $cursor, itemTypes: [PROJECT_V2_ITEM_STATUS_CHANGED_EVENT]) {
+        pageInfo { hasNextPage endCursor }
+        nodes { ...statusChange }
+      }
+    }
+  }
+}
+` + githubStatusChangeFragment
+
+const githubStatusChangeFragment = `fragment statusChange on ProjectV2ItemStatusChangedEvent {
+  createdAt previousStatus status
+  actor { login }
+  project { number }
+}`
+
+type githubUser struct {
+	Login string `json:"login"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func (u *githubUser) user() User {
+	return User{UserName: u.Login, DisplayName: u.Name, EmailAddress: u.Email}
+}
+
+type githubStatusEvent struct {
+	CreatedAt      zonedTimestamp `json:"createdAt"`
+	PreviousStatus string         `json:"previousStatus"`
+	Status         string         `json:"status"`
+	Actor          *githubUser    `json:"actor"`
+	Project        *struct {
+		Number int `json:"number"`
+	} `json:"project"`
+}
+
+// githubPageInfo is where a GraphQL connection continues from:
+type githubPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type githubTimeline struct {
+	PageInfo githubPageInfo      `json:"pageInfo"`
+	Nodes    []githubStatusEvent `json:"nodes"`
+}
+
+type githubIssue struct {
+	Id         string         `json:"id"`
+	Number     int            `json:"number"`
+	Title      string         `json:"title"`
+	CreatedAt  zonedTimestamp `json:"createdAt"`
+	ClosedAt   zonedTimestamp `json:"closedAt"`
+	Repository *struct {
+		Name string `json:"name"`
+	} `json:"repository"`
+	Assignees struct {
+		Nodes []githubUser `json:"nodes"`
+	} `json:"assignees"`
+	TimelineItems githubTimeline `json:"timelineItems"`
+}
+
+type githubItem struct {
+	Status *struct {
+		Name string `json:"name"`
+	} `json:"status"`
+	// Content is empty for draft items and pull requests:
+	Content githubIssue `json:"content"`
+}
+
+type githubItemsPage struct {
+	Data struct {
+		RepositoryOwner *struct {
+			ProjectV2 *struct {
+				Items struct {
+					PageInfo githubPageInfo `json:"pageInfo"`
+					Nodes    []githubItem   `json:"nodes"`
+				} `json:"items"`
+			} `json:"projectV2"`
+		} `json:"repositoryOwner"`
+	} `json:"data"`
+	Errors githubErrors `json:"errors"`
+}
+
+type githubTimelinePage struct {
+	Data struct {
+		Node *struct {
+			TimelineItems githubTimeline `json:"timelineItems"`
+		} `json:"node"`
+	} `json:"data"`
+	Errors githubErrors `json:"errors"`
+}
+
+type githubErrors []struct {
+	Message string `json:"message"`
+}
+
+// githubEndpoint derives the GraphQL endpoint and project owner from JIRA_URL:
+func githubEndpoint(cl *http.Client) (endpoint string, owner string, err error) {
+	u, err := url.Parse(setting(cl, "JIRA_URL"))
+	if err != nil {
+		return "", "", err
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) >= 2 && (parts[0] == "orgs" || parts[0] == "users") {
+		parts = parts[1:]
+	}
+	if parts[0] == "" {
+		return "", "", fmt.Errorf("github: JIRA_URL should name the project owner, e.g. https://github.com/orgs/<org>")
+	}
+
+	if u.Host == "github.com" {
+		return "https://api.github.com/graphql", parts[0], nil
+	}
+	// GitHub Enterprise Server:
+	return u.Scheme + "://" + u.Host + "/api/graphql", parts[0], nil
+}
+
+func (githubSource) Describe(cl *http.Client) string {
+	return "GitHub Projects, GraphQL API"
+}
+
+func (githubSource) FetchIssues(cl *http.Client, boardId int, resource string, query string) ([]Issue, error) {
+	endpoint, owner, err := githubEndpoint(cl)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	cursor := ""
+	for {
+		page, err := githubItems(cl, endpoint, owner, boardId, cursor)
+		if err != nil {
+			return nil, err
+		}
+		fetched.Pages++
+
+		if page.Data.RepositoryOwner == nil || page.Data.RepositoryOwner.ProjectV2 == nil {
+			return nil, fmt.Errorf("github: project %d of %s not found", boardId, owner)
+		}
+		items := &page.Data.RepositoryOwner.ProjectV2.Items
+		for i := range items.Nodes {
+			item := &items.Nodes[i]
+			if item.Content.Number == 0 {
+				continue
+			}
+			if err := githubRestOfTimeline(cl, endpoint, owner, boardId, &item.Content); err != nil {
+				return nil, err
+			}
+
+			issue := githubIssueOf(item, boardId)
+			if resource == "backlog" && issue.Fields.Status.StatusCategory.Key != "new" {
+				continue
+			}
+			issues = append(issues, issue)
+		}
+
+		if !items.PageInfo.HasNextPage {
+			break
+		}
+		cursor = items.PageInfo.EndCursor
+	}
+	return issues, nil
+}
+
+// githubIssueOf maps a project item onto an issue; only status changes made on
+// this project count as transitions.
+func githubIssueOf(item *githubItem, project int) Issue {
+	const prefix = "github:"
+	content := &item.Content
+
+	repo := "issue"
+	if content.Repository != nil {
+		repo = content.Repository.Name
+	}
+	issue := Issue{Key: fmt.Sprintf("%s-%d", repo, content.Number)}
+
+	status := ""
+	if item.Status != nil {
+		status = item.Status.Name
+	}
+	category := statusCategoryOf(status)
+	if !content.ClosedAt.IsZero() {
+		category = "done"
+	}
+
+	f := &issue.Fields
+	f.Summary = content.Title
+	f.Status = externalStatus(prefix, status, category)
+	f.Created = content.CreatedAt
+	f.ResolutionDate = content.ClosedAt
+	f.Project = Project{Key: repo, Name: repo, Style: externalStyle}
+	f.IssueType = IssueType{Name: "Issue"}
+	if len(content.Assignees.Nodes) > 0 {
+		assignee := content.Assignees.Nodes[0].user()
+		f.Assignee = &assignee
+	}
+
+	for _, ev := range content.TimelineItems.Nodes {
+		if ev.Status == "" || ev.Project == nil || ev.Project.Number != project {
+			continue
+		}
+		registerStatusCategory(prefix+ev.Status, statusCategoryOf(ev.Status))
+		if ev.PreviousStatus != "" {
+			registerStatusCategory(prefix+ev.PreviousStatus, statusCategoryOf(ev.PreviousStatus))
+		}
+
+		history := History{Created: ev.CreatedAt, Items: []HistoryItem{statusChange(prefix, ev.PreviousStatus, ev.Status)}}
+		if ev.Actor != nil {
+			history.Author = ev.Actor.user()
+		}
+		issue.Changelog.Histories = append(issue.Changelog.Histories, history)
+	}
+	issue.Changelog.Total = len(issue.Changelog.Histories)
+	return issue
+}
+
+func githubItems(cl *http.Client, endpoint string, owner string, number int, cursor string) (*githubItemsPage, error) {
+	variables := map[string]interface{}{"owner": owner, "number": number}
+	if cursor != "" {
+		variables["cursor"] = cursor
+	}
+	page := &githubItemsPage{}
+	if err := githubPost(cl, endpoint, owner, number, githubItemsQuery, variables, page); err != nil {
+		return nil, err
+	}
+	if len(page.Errors) > 0 {
+		return nil, fmt.Errorf("github: %s", page.Errors[0].Message)
+	}
+	return page, nil
+}
+
+// githubRestOfTimeline follows an issue's timeline past the first page, so
+// issues moved often don't lose their later transitions:
+func githubRestOfTimeline(cl *http.Client, endpoint string, owner string, number int, issue *githubIssue) error {
+	timeline := &issue.TimelineItems
+	for timeline.PageInfo.HasNextPage {
+		page := &githubTimelinePage{}
+		variables := map[string]interface{}{"id": issue.Id, "cursor": timeline.PageInfo.EndCursor}
+		if err := githubPost(cl, endpoint, owner, number, githubTimelineQuery, variables, page); err != nil {
+			return err
+		}
+		if len(page.Errors) > 0 {
+			return fmt.Errorf("github: %s", page.Errors[0].Message)
+		}
+		if page.Data.Node == nil {
+			return fmt.Errorf("github: issue %d not found", issue.Number)
+		}
+		fetched.Pages++
+
+		next := &page.Data.Node.TimelineItems
+		timeline.Nodes = append(timeline.Nodes, next.Nodes...)
+		timeline.PageInfo = next.PageInfo
+	}
+	return nil
+}
+
+// githubPost runs a GraphQL query against a project, cached by the query and
+// its variables:
+func githubPost(cl *http.Client, endpoint string, owner string, number int, query string, variables map[string]interface{}, v interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+setting(cl, "JIRA_PASSWORD"))
+
+	h := fnv.New32a()
+	h.Write(body)
+	rsp, err := cachedDo(fmt.Sprintf("github.%s.%d.%08x.json", owner, number, h.Sum32()), req, cl)
+	if err != nil {
+		return err
+	}
+	defer rsp.Close()
+
+	return decodeResponse(rsp, req.URL.String(), v)
+}
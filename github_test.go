@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+const githubPage = `{"data": {"repositoryOwner": {"projectV2": {"items": {
+	"pageInfo": {"hasNextPage": false, "endCursor": "x"},
+	"nodes": [
+		{"status": {"name": "Done"}, "content": {
+			"number": 12, "title": "Add export", "createdAt": "2018-10-29T09:00:00Z", "closedAt": "2018-11-05T09:00:00Z",
+			"repository": {"name": "web"},
+			"assignees": {"nodes": [{"login": "alice", "name": "Alice"}]},
+			"timelineItems": {"nodes": [
+				{"createdAt": "2018-10-30T09:00:00Z", "previousStatus": "Todo", "status": "In Progress", "actor": {"login": "alice"}, "project": {"number": 3}},
+				{"createdAt": "2018-10-31T09:00:00Z", "previousStatus": "Todo", "status": "Doing", "actor": {"login": "bob"}, "project": {"number": 9}},
+				{"createdAt": "2018-11-05T09:00:00Z", "previousStatus": "In Progress", "status": "Done", "actor": {"login": "alice"}, "project": {"number": 3}}
+			]}
+		}},
+		{"status": {"name": "Todo"}, "content": {}}
+	]
+}}}}}`
+
+func TestGithubSource_FetchIssues(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/graphql" || r.Header.Get("Authorization") != "Bearer token" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(githubPage))
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL+"/orgs/acme")
+	os.Setenv("JIRA_PASSWORD", "token")
+	defer os.Unsetenv("JIRA_URL")
+	defer os.Unsetenv("JIRA_PASSWORD")
+	defer func() { statusCategories = nil }()
+
+	issues, err := githubSource{}.FetchIssues(srv.Client(), 3, "issue", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("expected draft item skipped, got %d issues", len(issues))
+	}
+
+	issue := &issues[0]
+	if issue.Key != "web-12" || len(issue.Changelog.Histories) != 2 {
+		t.Fatalf("expected web-12 with 2 transitions on project 3, got %s with %d", issue.Key, len(issue.Changelog.Histories))
+	}
+	if days, ok := issue.CycleTime(); !ok || days != 4 {
+		t.Fatalf("expected cycle time of 4 days, got %d %v", days, ok)
+	}
+}
+
+func TestGithubSource_FollowsTimelinePages(t *testing.T) {
+	const items = `{"data": {"repositoryOwner": {"projectV2": {"items": {
+		"pageInfo": {"hasNextPage": false},
+		"nodes": [{"status": {"name": "Done"}, "content": {
+			"id": "I_12", "number": 12, "title": "Add export", "createdAt": "2018-10-29T09:00:00Z", "closedAt": "2018-11-05T09:00:00Z",
+			"repository": {"name": "web"},
+			"timelineItems": {"pageInfo": {"hasNextPage": true, "endCursor": "c1"}, "nodes": [
+				{"createdAt": "2018-10-30T09:00:00Z", "previousStatus": "Todo", "status": "In Progress", "project": {"number": 3}}
+			]}
+		}}]
+	}}}}}`
+	const timeline = `{"data": {"node": {"timelineItems": {
+		"pageInfo": {"hasNextPage": false, "endCursor": "c2"},
+		"nodes": [{"createdAt": "2018-11-05T09:00:00Z", "previousStatus": "In Progress", "status": "Done", "project": {"number": 3}}]
+	}}}}`
+
+	var cursors []interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if strings.Contains(body.Query, "node(id:") {
+			if body.Variables["id"] != "I_12" {
+				http.NotFound(w, r)
+				return
+			}
+			cursors = append(cursors, body.Variables["cursor"])
+			w.Write([]byte(timeline))
+			return
+		}
+		w.Write([]byte(items))
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL+"/orgs/acme")
+	defer os.Unsetenv("JIRA_URL")
+	defer func() { statusCategories = nil }()
+
+	issues, err := githubSource{}.FetchIssues(srv.Client(), 3, "issue", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cursors) != 1 || cursors[0] != "c1" {
+		t.Fatalf("expected the timeline followed from its end cursor once, got %v", cursors)
+	}
+	if len(issues) != 1 || len(issues[0].Changelog.Histories) != 2 {
+		t.Fatalf("expected both pages of transitions, got %+v", issues)
+	}
+	if days, ok := issues[0].CycleTime(); !ok || days != 4 {
+		t.Fatalf("expected cycle time of 4 days, got %d %v", days, ok)
+	}
+}
+
+func TestStatusCategoryOf(t *testing.T) {
+	config = &Config{StatusCategories: map[string]string{"Ready": "new"}}
+	defer func() { config = &Config{} }()
+
+	for status, expected := range map[string]string{"Ready": "new", "Todo": "new", "In Review": "indeterminate", "Shipped": "done"} {
+		if category := statusCategoryOf(status); category != expected {
+			t.Fatalf("expected %s for %s, got %s", expected, status, category)
+		}
+	}
+}
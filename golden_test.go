@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files from current output")
+
+// fixtureServer serves testdata/issues.json for any board; backlog requests get
+// only the issues not yet started.
+func fixtureServer(t *testing.T) *httptest.Server {
+	body, err := ioutil.ReadFile(filepath.Join("testdata", "issues.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/serverInfo") {
+			w.Write([]byte(`{"version":"7.13.0","deploymentType":"Server"}`))
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/backlog") {
+			// Filter raw JSON to keep the JIRA timestamp format:
+			var page struct {
+				Total  int                      `json:"total"`
+				Issues []map[string]interface{} `json:"issues"`
+			}
+			if err := json.Unmarshal(body, &page); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			backlog := page.Issues[:0]
+			for _, issue := range page.Issues {
+				fields := issue["fields"].(map[string]interface{})
+				category := fields["status"].(map[string]interface{})["statusCategory"].(map[string]interface{})
+				if category["key"] == "new" {
+					backlog = append(backlog, issue)
+				}
+			}
+			page.Issues = backlog
+			page.Total = len(backlog)
+			json.NewEncoder(w).Encode(page)
+			return
+		}
+		w.Write(body)
+	}))
+}
+
+// captureStdout runs f with os.Stdout redirected and returns what it printed:
+func captureStdout(t *testing.T, f func() error) []byte {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		done <- b
+	}()
+
+	err = f()
+	w.Close()
+	out := <-done
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func goldenConfig() *Config {
+	c := &Config{
+		SeverityField: "customfield_10500",
+		StatusClasses: map[string]string{
+			"In Progress":     workState,
+			"In Progress - 1": queueState,
+			"In Progress - 2": queueState,
+			"In Testing":      workState,
+		},
+		Teams: map[string][]string{
+			"core":     {"alice", "bob@example.com"},
+			"platform": {"carol"},
+		},
+		Managers: map[string]string{
+			"alice":           "dana",
+			"carol":           "dana",
+			"bob@example.com": "erin",
+			"dana":            "erin",
+		},
+		TagRules: []TagRule{
+			{Name: "hotfix", Pattern: `(?i)\bhotfix\b`},
+			{Name: "debt", Pattern: `(?i)tech debt`},
+			{Name: "customer", Pattern: `(?i)customer:\s*(\w+)`},
+		},
+	}
+	c.compileTagRules()
+	return c
+}
+
+func TestGolden_Reports(t *testing.T) {
+	srv := fixtureServer(t)
+	defer srv.Close()
+
+	golden, err := filepath.Abs(filepath.Join("testdata", "golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Run in a scratch directory since responses are written to the cache:
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	for key, value := range map[string]string{
+		"JIRA_URL":     srv.URL,
+		"JIRA_NOCACHE": "1",
+		"JIRA_NOW":     "2018-11-06T12:00:00-06:00",
+	} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+
+	config = goldenConfig()
+	defer func() { config = &Config{} }()
+
+	cases := [][]string{
+		{"aging", "1"},
+		{"aging", "-histogram", "1"},
+		{"aging", "-benchmarks", "1"},
+		{"anomalies", "1"},
+		{"backlog", "1"},
+		{"bugs", "1"},
+		{"cohorts", "1"},
+		{"compare-boards", "1", "2"},
+		{"context-switching", "1"},
+		{"digest", "1"},
+		{"forecast", "1"},
+		{"forecast-accuracy"},
+		{"handoffs", "1"},
+		{"heatmap", "1"},
+		{"managers", "1"},
+		{"predictability", "1"},
+		{"priority", "1"},
+		{"reestimates", "1"},
+		{"rolling", "1"},
+		{"resolutions", "-monthly", "1"},
+		{"review-wait", "1"},
+		{"tags", "1"},
+		{"teams", "1"},
+		{"wait-time", "1"},
+	}
+	for _, args := range cases {
+		args := args
+		// A flag picks a variant of the report, golden under its own name:
+		name := args[0]
+		if len(args) > 1 && strings.HasPrefix(args[1], "-") {
+			name += args[1]
+		}
+		t.Run(name, func(t *testing.T) {
+			cmd := findCommand(args[0])
+			fetched = fetchStats{}
+			out := captureStdout(t, func() error { return cmd.Run(args[1:]) })
+
+			filename := filepath.Join(golden, name+".golden")
+			if *update {
+				os.MkdirAll(golden, 0755)
+				if err := ioutil.WriteFile(filename, out, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			expected, err := ioutil.ReadFile(filename)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create)", err)
+			}
+			if !bytes.Equal(out, expected) {
+				t.Fatalf("output differs from %s (run go test -update to accept):\n%s", filename, out)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// historyUser names the person a changelog item refers to by identity and
+// display name, as reports name them:
+func historyUser(identity string, display string) string {
+	if name := users.NameOf(identity); identity != "" && (name != identity || display == "") {
+		return name
+	}
+	return display
+}
+
+// assigneeChain lists the people an issue was assigned to in turn, from its
+// changelog. Unassigning isn't a handoff, so taking the issue back up
+// continues the chain, and bots' reassignments are skipped.
+func (issue *Issue) assigneeChain() []string {
+	var chain []string
+	for _, ev := range issue.Transitions() {
+		if ev.Field != "assignee" || ev.Bot {
+			continue
+		}
+		if from := historyUser(ev.From, ev.FromString); len(chain) == 0 && from != "" {
+			chain = append(chain, from)
+		}
+		if to := historyUser(ev.To, ev.ToString); to != "" && (len(chain) == 0 || chain[len(chain)-1] != to) {
+			chain = append(chain, to)
+		}
+	}
+	return chain
+}
+
+// handoffs counts how many times an issue passed from one person to another:
+func (issue *Issue) handoffs() int {
+	if chain := issue.assigneeChain(); len(chain) > 1 {
+		return len(chain) - 1
+	}
+	return 0
+}
+
+func runHandoffs(args []string) error {
+	fs := flag.NewFlagSet("handoffs", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting issues to examine; default='resolved >= -90d OR resolution is EMPTY'")
+	min := fs.Int("min", 3, "handoffs that make an issue's ownership unclear")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *min < 1 {
+		return fmt.Errorf("-min must be at least 1")
+	}
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("handoffs", *jql, "resolved >= -90d OR resolution is EMPTY"))
+	if err != nil {
+		return err
+	}
+
+	type handedOff struct {
+		issue *Issue
+		chain []string
+	}
+	var excessive []handedOff
+	var completedHandoffs []int
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		chain := issue.assigneeChain()
+		n := issue.handoffs()
+		if _, done := issue.CompletedTime(); done {
+			completedHandoffs = append(completedHandoffs, n)
+		}
+		if n >= *min {
+			excessive = append(excessive, handedOff{issue, chain})
+		}
+	}
+	sort.SliceStable(excessive, func(i, j int) bool {
+		if len(excessive[i].chain) != len(excessive[j].chain) {
+			return len(excessive[i].chain) > len(excessive[j].chain)
+		}
+		return lessIssueKey(excessive[i].issue.Key, excessive[j].issue.Key)
+	})
+
+	if reportVerbosity != execView {
+		fmt.Printf("Issues handed off %d or more times:\n", *min)
+		shown := detailLines(len(excessive))
+		for _, h := range excessive[:shown] {
+			fmt.Printf("  %-10s %2d  %s; %s\n", h.issue.Key, len(h.chain)-1, strings.Join(h.chain, " → "), h.issue.DisplaySummary())
+		}
+		printOmitted("  ", shown, len(excessive))
+		if len(excessive) == 0 {
+			fmt.Printf("  none\n")
+		}
+		fmt.Println()
+	}
+
+	if len(completedHandoffs) == 0 {
+		fmt.Printf("No completed issues.\n")
+		return nil
+	}
+	sorted := sortedCopy(completedHandoffs)
+	over := len(sorted) - sort.SearchInts(sorted, *min)
+	at := make([]string, 0, len(percentiles))
+	for _, p := range percentiles {
+		at = append(at, fmt.Sprintf("%s %d", percentileLabel(p), percentile(sorted, p)))
+	}
+	fmt.Printf(
+		"%s completed, averaging %.1f handoffs (%s); %d (%.0f%%) handed off %d or more times.\n",
+		plural(len(sorted), "issue"),
+		mean(sorted),
+		strings.Join(at, ", "),
+		over,
+		100*share(over, len(sorted)),
+		*min,
+	)
+
+	recordMetric("handoffsPerCompleted", mean(sorted))
+	for _, p := range percentiles {
+		recordMetric(percentileMetric("handoffs", p), percentile(sorted, p))
+	}
+	recordMetric("excessiveHandoffs", len(excessive))
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAssigneeChain(t *testing.T) {
+	users = newUserRegistry()
+	defer func() { users = newUserRegistry() }()
+
+	at := func(day int) zonedTimestamp { return zonedTimestamp{time.Date(2024, 3, day, 9, 0, 0, 0, time.UTC)} }
+	assign := func(day int, from, to string) History {
+		return History{Created: at(day), Items: []HistoryItem{{Field: "assignee", From: from, FromString: from, To: to, ToString: to}}}
+	}
+	issue := &Issue{Key: "A-1"}
+	issue.Changelog.Histories = []History{
+		assign(1, "", "alice"),
+		assign(2, "alice", "bob"),
+		// Unassigned and taken back up by bob isn't a handoff:
+		assign(3, "bob", ""),
+		assign(4, "", "bob"),
+		assign(5, "bob", "carol"),
+		{Created: at(6), Author: User{UserName: "automation", AccountType: "app"}, Items: []HistoryItem{{Field: "assignee", From: "carol", FromString: "carol", To: "dan", ToString: "dan"}}},
+	}
+
+	if chain := strings.Join(issue.assigneeChain(), ","); chain != "alice,bob,carol" {
+		t.Fatalf("expected alice, bob, carol, got %s", chain)
+	}
+	if n := issue.handoffs(); n != 2 {
+		t.Fatalf("expected 2 handoffs, got %d", n)
+	}
+
+	// Assigned at creation, the first change starts the chain with its
+	// previous assignee:
+	created := &Issue{Key: "A-2"}
+	created.Changelog.Histories = []History{assign(2, "alice", "bob")}
+	if n := created.handoffs(); n != 1 {
+		t.Fatalf("expected 1 handoff, got %d", n)
+	}
+	if n := (&Issue{Key: "A-3"}).handoffs(); n != 0 {
+		t.Fatalf("expected no handoffs without history, got %d", n)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+type heatmapCell struct {
+	Count  int
+	MaxAge int
+	Keys   string
+	Color  template.CSS
+}
+
+type heatmapRow struct {
+	Assignee string
+	Cells    []*heatmapCell
+}
+
+type heatmap struct {
+	Generated string
+	Footer    string
+	Statuses  []string
+	Rows      []heatmapRow
+}
+
+var heatmapTemplate = assetTemplate("heatmap.html", nil)
+
+// heatColor scales from green at zero age to red at maxAge:
+func heatColor(age, maxAge int) template.CSS {
+	hue := 120
+	if maxAge > 0 {
+		hue = 120 - 120*age/maxAge
+	}
+	return template.CSS(fmt.Sprintf("hsl(%d, 70%%, 75%%)", hue))
+}
+
+// buildHeatmap tabulates the oldest issue per assignee and status:
+func buildHeatmap(aging map[string][]*Issue, now time.Time) *heatmap {
+	statuses := make([]string, 0, len(aging))
+	for status := range aging {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+
+	cells := make(map[string][]*heatmapCell)
+	overallMax := 0
+	for s, status := range statuses {
+		for _, issue := range aging[status] {
+			assignee := users.Name(issue.Assigned)
+			row, ok := cells[assignee]
+			if !ok {
+				row = make([]*heatmapCell, len(statuses))
+				cells[assignee] = row
+			}
+
+			cell := row[s]
+			if cell == nil {
+				cell = &heatmapCell{}
+				row[s] = cell
+			}
+			cell.Count++
+			if cell.Keys != "" {
+				cell.Keys += ", "
+			}
+			cell.Keys += issue.Key
+			if issue.StatusBusinessDays > cell.MaxAge {
+				cell.MaxAge = issue.StatusBusinessDays
+			}
+			if cell.MaxAge > overallMax {
+				overallMax = cell.MaxAge
+			}
+		}
+	}
+
+	assignees := make([]string, 0, len(cells))
+	for assignee := range cells {
+		assignees = append(assignees, assignee)
+	}
+	sort.Strings(assignees)
+
+	h := &heatmap{
+		Generated: displayTime(now).Format("Mon Jan 02 2006"),
+		Statuses:  statuses,
+	}
+	for _, assignee := range assignees {
+		row := cells[assignee]
+		for _, cell := range row {
+			if cell != nil {
+				cell.Color = heatColor(cell.MaxAge, overallMax)
+			}
+		}
+
+		name := assignee
+		if strings.TrimSpace(name) == "" {
+			name = "(unknown)"
+		}
+		h.Rows = append(h.Rows, heatmapRow{Assignee: name, Cells: row})
+	}
+	return h
+}
+
+func runHeatmap(args []string) error {
+	fs := flag.NewFlagSet("heatmap", flag.ContinueOnError)
+	output := fs.String("o", "", "write HTML to this file instead of stdout")
+	jql := fs.String("jql", "", "JQL filter selecting in-flight issues")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	boardId := boardArg(fs.Args())
+
+	cl := httpClient()
+	issues, err := fetchBoardIssues(cl, boardId, reportJQL("heatmap", *jql, reportJQL("aging", "", defaultJQL())))
+	if err != nil {
+		return err
+	}
+	issues = excludeBacklog(cl, boardId, issues)
+
+	now := reportNow()
+	h := buildHeatmap(computeAging(issues, DateOf(now)), now)
+	h.Footer = reportFooter()
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return heatmapTemplate.Execute(w, h)
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildHeatmap_MaxAgePerCell(t *testing.T) {
+	alice := User{UserName: "alice"}
+	bob := User{UserName: "bob"}
+	aging := map[string][]*Issue{
+		"In Testing": {
+			{Key: "A-1", Assigned: alice, StatusBusinessDays: 3},
+			{Key: "A-2", Assigned: alice, StatusBusinessDays: 7},
+		},
+		"In Progress": {
+			{Key: "A-3", Assigned: bob, StatusBusinessDays: 1},
+		},
+	}
+
+	h := buildHeatmap(aging, time.Date(2018, 11, 6, 0, 0, 0, 0, cst))
+	if len(h.Statuses) != 2 || h.Statuses[0] != "In Progress" {
+		t.Fatalf("expected sorted statuses, got %v", h.Statuses)
+	}
+	if len(h.Rows) != 2 || h.Rows[0].Assignee != "alice" {
+		t.Fatalf("expected alice first of 2 rows, got %v", h.Rows)
+	}
+
+	cell := h.Rows[0].Cells[1]
+	if cell == nil || cell.MaxAge != 7 || cell.Count != 2 || cell.Keys != "A-1, A-2" {
+		t.Fatalf("expected max age 7 of 2 issues, got %+v", cell)
+	}
+	if h.Rows[0].Cells[0] != nil {
+		t.Fatalf("expected empty cell for alice In Progress")
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxHierarchyDepth bounds the rounds of child fetches, Initiative → Epic →
+// Story → Subtask and a level to spare:
+const maxHierarchyDepth = 5
+
+// hierarchyChunk is how many parent keys one children query names:
+const hierarchyChunk = 100
+
+// parentLinkKey reads the Advanced Roadmaps "Parent Link" field, which links
+// epics to initiatives on JIRA Server; it holds a key or {"data": {"key"}}.
+func (issue *Issue) parentLinkKey() string {
+	if config.ParentLinkField == "" {
+		return ""
+	}
+	if key := issue.Fields.CustomString(config.ParentLinkField); key != "" {
+		return key
+	}
+	var link struct {
+		Data struct {
+			Key string `json:"key"`
+		} `json:"data"`
+	}
+	json.Unmarshal(issue.Fields.Custom[config.ParentLinkField], &link)
+	return link.Data.Key
+}
+
+// hierarchyParentKey is the key of the issue's parent at any level: a
+// subtask's parent, a story's epic or an epic's initiative.
+func (issue *Issue) hierarchyParentKey() string {
+	if issue.Fields.Parent != nil {
+		return issue.Fields.Parent.Key
+	}
+	if key := issue.EpicKey(); key != "" {
+		return key
+	}
+	return issue.parentLinkKey()
+}
+
+// hierarchyChildrenJQL selects the children of the issues at any level:
+func hierarchyChildrenJQL(keys []string, epicKeys []string) string {
+	clauses := []string{fmt.Sprintf("parent in (%s)", strings.Join(keys, ", "))}
+	if len(epicKeys) > 0 {
+		clauses = append(clauses, epicChildrenJQL(epicKeys...))
+	}
+	if id := strings.TrimPrefix(config.ParentLinkField, "customfield_"); id != "" {
+		clauses = append(clauses, fmt.Sprintf("cf[%s] in (%s)", id, strings.Join(keys, ", ")))
+	}
+	return strings.Join(clauses, " OR ")
+}
+
+// fetchHierarchy fetches the issues the JQL selects and then, level by level,
+// all their descendants, done or not, so rollups count completed work.
+func fetchHierarchy(cl *http.Client, boardId int, jql string) ([]*Issue, error) {
+	issues, err := fetchBoardIssues(cl, boardId, jql)
+	if err != nil {
+		return nil, err
+	}
+	all := make([]*Issue, 0, len(issues))
+	seen := make(map[string]bool)
+	var level []*Issue
+	for i := range issues {
+		seen[issues[i].Key] = true
+		all = append(all, &issues[i])
+		level = append(level, &issues[i])
+	}
+	// Only JIRA can be queried for children; other trackers' issues are
+	// arranged as fetched.
+	if backendName() != "jira" {
+		return all, nil
+	}
+
+	for depth := 0; depth < maxHierarchyDepth && len(level) > 0; depth++ {
+		var parents []*Issue
+		for _, issue := range level {
+			if !issue.Fields.IssueType.Subtask {
+				parents = append(parents, issue)
+			}
+		}
+		level = nil
+		for start := 0; start < len(parents); start += hierarchyChunk {
+			end := start + hierarchyChunk
+			if end > len(parents) {
+				end = len(parents)
+			}
+			var keys, epicKeys []string
+			for _, parent := range parents[start:end] {
+				keys = append(keys, parent.Key)
+				if parent.IsEpic() {
+					epicKeys = append(epicKeys, parent.Key)
+				}
+			}
+			children, err := fetchBoardIssues(cl, boardId, hierarchyChildrenJQL(keys, epicKeys))
+			if err != nil {
+				return nil, err
+			}
+			for i := range children {
+				if !seen[children[i].Key] {
+					seen[children[i].Key] = true
+					all = append(all, &children[i])
+					level = append(level, &children[i])
+				}
+			}
+		}
+	}
+	return all, nil
+}
+
+// hierarchyNode is an issue in the hierarchy with its rolled-up progress:
+type hierarchyNode struct {
+	Issue    *Issue
+	Children []*hierarchyNode
+	// Level is the issue type's hierarchy level: 2 for initiatives, 1 for
+	// epics, 0 for stories and -1 for subtasks.
+	Level int
+	// Leaves and LeavesDone count the descendants without children of their
+	// own, the work items the completion percentage is of:
+	Leaves     int
+	LeavesDone int
+	// Started is when work on the issue or any descendant first started:
+	Started time.Time
+	Done    bool
+}
+
+// Completion is the share of the node's leaf work done:
+func (n *hierarchyNode) Completion() float64 {
+	return share(n.LeavesDone, n.Leaves)
+}
+
+// Age is business days since work on the node started, or -1 when not
+// started or done:
+func (n *hierarchyNode) Age(today Date) int {
+	if n.Done || n.Started.IsZero() {
+		return -1
+	}
+	return DateOf(n.Started).BusinessDaysUntil(today)
+}
+
+// issueTypeLevel is the hierarchy level of the issue's type; JIRA Server
+// omits it, so epics and subtasks are recognized by other means.
+func issueTypeLevel(issue *Issue) int {
+	switch {
+	case issue.Fields.IssueType.Subtask:
+		return -1
+	case issue.IsEpic() && issue.Fields.IssueType.HierarchyLevel < 1:
+		return 1
+	}
+	return issue.Fields.IssueType.HierarchyLevel
+}
+
+// buildHierarchy arranges issues under their parents, ordered by key, and
+// rolls up leaves, completion and start of work. Issues whose parent wasn't
+// fetched are roots.
+func buildHierarchy(issues []*Issue) []*hierarchyNode {
+	nodes := make(map[string]*hierarchyNode, len(issues))
+	for _, issue := range issues {
+		nodes[issue.Key] = &hierarchyNode{Issue: issue}
+	}
+	var roots []*hierarchyNode
+	for _, issue := range issues {
+		node := nodes[issue.Key]
+		if parent, ok := nodes[issue.hierarchyParentKey()]; ok && parent != node {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+
+	var rollup func(n *hierarchyNode)
+	rollup = func(n *hierarchyNode) {
+		n.Level = issueTypeLevel(n.Issue)
+		_, n.Done = n.Issue.CompletedTime()
+		n.Started, _ = n.Issue.StartedTime()
+		sort.Slice(n.Children, func(i, j int) bool { return lessIssueKey(n.Children[i].Issue.Key, n.Children[j].Issue.Key) })
+		for _, child := range n.Children {
+			rollup(child)
+			// Servers without hierarchy levels report 0 for initiatives too:
+			if n.Level <= child.Level {
+				n.Level = child.Level + 1
+			}
+			n.Leaves += child.Leaves
+			n.LeavesDone += child.LeavesDone
+			if !child.Started.IsZero() && (n.Started.IsZero() || child.Started.Before(n.Started)) {
+				n.Started = child.Started
+			}
+		}
+		if len(n.Children) == 0 {
+			n.Leaves = 1
+			if n.Done {
+				n.LeavesDone = 1
+			}
+		}
+	}
+	for _, root := range roots {
+		rollup(root)
+	}
+	sort.Slice(roots, func(i, j int) bool {
+		if roots[i].Level != roots[j].Level {
+			return roots[i].Level > roots[j].Level
+		}
+		return lessIssueKey(roots[i].Issue.Key, roots[j].Issue.Key)
+	})
+	return roots
+}
+
+// hierarchyLevel aggregates the nodes of one hierarchy level:
+type hierarchyLevel struct {
+	Level      int
+	Types      []string
+	Items      int
+	Done       int
+	Leaves     int
+	LeavesDone int
+	// Ages of the level's items in flight, in business days:
+	Ages []int
+}
+
+// hierarchyLevels aggregates the hierarchy per level, top level first:
+func hierarchyLevels(roots []*hierarchyNode, today Date) []*hierarchyLevel {
+	byLevel := make(map[int]*hierarchyLevel)
+	var visit func(n *hierarchyNode)
+	visit = func(n *hierarchyNode) {
+		l, ok := byLevel[n.Level]
+		if !ok {
+			l = &hierarchyLevel{Level: n.Level}
+			byLevel[n.Level] = l
+		}
+		typeName := n.Issue.Fields.IssueType.Name
+		if typeName != "" && !containsString(l.Types, typeName) {
+			l.Types = append(l.Types, typeName)
+		}
+		l.Items++
+		if n.Done {
+			l.Done++
+		}
+		l.Leaves += n.Leaves
+		l.LeavesDone += n.LeavesDone
+		if age := n.Age(today); age >= 0 {
+			l.Ages = append(l.Ages, age)
+		}
+		for _, child := range n.Children {
+			visit(child)
+		}
+	}
+	for _, root := range roots {
+		visit(root)
+	}
+
+	levels := make([]*hierarchyLevel, 0, len(byLevel))
+	for _, l := range byLevel {
+		sort.Strings(l.Types)
+		sort.Ints(l.Ages)
+		levels = append(levels, l)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].Level > levels[j].Level })
+	return levels
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func runHierarchy(args []string) error {
+	fs := flag.NewFlagSet("hierarchy", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting the top-level issues; default='issuetype = Epic AND statusCategory != Done'")
+	depth := fs.Int("depth", 0, "list this many levels below the top; 0 lists all")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	issues, err := fetchHierarchy(httpClient(), boardArg(fs.Args()), reportJQL("hierarchy", *jql, "issuetype = Epic AND statusCategory != Done"))
+	if err != nil {
+		return err
+	}
+	roots := buildHierarchy(issues)
+	if len(roots) == 0 {
+		fmt.Printf("No issues.\n")
+		return nil
+	}
+
+	today := DateOf(reportNow())
+	levels := hierarchyLevels(roots, today)
+	fmt.Printf("By level:\n")
+	fmt.Printf("  %-24s %5s %5s %6s %7s %7s\n", "level", "items", "done", "leaves", "age p50", "oldest")
+	for _, l := range levels {
+		median, oldest := "-", "-"
+		if len(l.Ages) > 0 {
+			median = fmt.Sprintf("%dd", percentile(l.Ages, 50))
+			oldest = fmt.Sprintf("%dd", l.Ages[len(l.Ages)-1])
+		}
+		fmt.Printf("  %s %5d %4.0f%% %5.0f%% %7s %7s\n", padRight(strings.Join(l.Types, ", "), 24), l.Items,
+			100*share(l.Done, l.Items), 100*share(l.LeavesDone, l.Leaves), median, oldest)
+	}
+
+	fmt.Printf("\nRollup, with leaf items done and days since work started:\n")
+	var printNode func(n *hierarchyNode, indent int)
+	printNode = func(n *hierarchyNode, indent int) {
+		age := "-"
+		if a := n.Age(today); a >= 0 {
+			age = fmt.Sprintf("%dd", a)
+		}
+		progress := ""
+		if len(n.Children) > 0 {
+			progress = fmt.Sprintf("%d/%d %3.0f%%", n.LeavesDone, n.Leaves, 100*n.Completion())
+		}
+		key := strings.Repeat("  ", indent) + n.Issue.Key
+		fmt.Printf("  %s %-12s %-13s %5s %s\n", padRight(key, 20), n.Issue.Fields.Status.Name, progress, age, n.Issue.DisplaySummary())
+		if *depth > 0 && indent >= *depth {
+			return
+		}
+		for _, child := range n.Children {
+			printNode(child, indent+1)
+		}
+	}
+	shown := detailLines(len(roots))
+	for _, root := range roots[:shown] {
+		printNode(root, 0)
+	}
+	printOmitted("  ", shown, len(roots))
+
+	levelMetrics := make(map[string]map[string]float64, len(levels))
+	for _, l := range levels {
+		levelMetrics[strings.Join(l.Types, ", ")] = map[string]float64{
+			"items":      float64(l.Items),
+			"completion": share(l.LeavesDone, l.Leaves),
+		}
+	}
+	recordMetric("levels", levelMetrics)
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestBuildHierarchy(t *testing.T) {
+	config = &Config{ParentLinkField: "customfield_10800"}
+	defer func() { config = &Config{} }()
+
+	// A server-style hierarchy, without hierarchy levels on the issue types:
+	initiative := &Issue{Key: "A-1"}
+	initiative.Fields.IssueType.Name = "Initiative"
+	epic := &Issue{Key: "A-2"}
+	epic.Fields.IssueType.Name = "Epic"
+	epic.Fields.EpicName = "Checkout"
+	epic.Fields.Custom = map[string]json.RawMessage{"customfield_10800": json.RawMessage(`{"data": {"key": "A-1"}}`)}
+	done := completedIssue("A-3", time.Date(2018, 11, 5, 9, 0, 0, 0, cst), time.Date(2018, 11, 7, 9, 0, 0, 0, cst))
+	done.Fields.Epic = &EpicRef{Key: "A-2"}
+	story := &Issue{Key: "A-4"}
+	story.Fields.Epic = &EpicRef{Key: "A-2"}
+	subtask := completedIssue("A-5", time.Date(2018, 11, 12, 9, 0, 0, 0, cst), time.Date(2018, 11, 13, 9, 0, 0, 0, cst))
+	subtask.Fields.IssueType.Subtask = true
+	subtask.Fields.Parent = &ParentIssue{Key: "A-4"}
+	open := &Issue{Key: "A-6"}
+	open.Fields.Status.StatusCategory.Key = "indeterminate"
+	open.Fields.Parent = &ParentIssue{Key: "A-4"}
+	open.Fields.IssueType.Subtask = true
+	orphan := &Issue{Key: "B-1"}
+
+	roots := buildHierarchy([]*Issue{open, &subtask, story, &done, epic, initiative, orphan})
+	if len(roots) != 2 || roots[0].Issue.Key != "A-1" || roots[1].Issue.Key != "B-1" {
+		t.Fatalf("expected roots A-1 and B-1, got %d", len(roots))
+	}
+	top := roots[0]
+	if top.Level != 2 || top.Children[0].Level != 1 || top.Children[0].Children[1].Children[0].Level != -1 {
+		t.Fatalf("expected levels 2, 1 and -1, got %d and %d", top.Level, top.Children[0].Level)
+	}
+	// Leaves: A-3 (done), A-5 (done) and A-6:
+	if top.Leaves != 3 || top.LeavesDone != 2 {
+		t.Fatalf("expected 2 of 3 leaves done, got %d of %d", top.LeavesDone, top.Leaves)
+	}
+	if want := time.Date(2018, 11, 5, 9, 0, 0, 0, cst); !top.Started.Equal(want) {
+		t.Fatalf("expected started %v from A-3, got %v", want, top.Started)
+	}
+
+	levels := hierarchyLevels(roots, DateOf(time.Date(2018, 11, 19, 9, 0, 0, 0, cst)))
+	if len(levels) != 4 || levels[0].Level != 2 || levels[1].Types[0] != "Epic" {
+		t.Fatalf("expected 4 levels, top down, got %d", len(levels))
+	}
+	// In flight: A-1 and A-2 since A-3 started, and A-4 since A-5 started:
+	if ages := levels[0].Ages; len(ages) != 1 || ages[0] != 10 {
+		t.Fatalf("expected the initiative 10 business days old, got %v", ages)
+	}
+	if l := levels[2]; l.Items != 3 || l.Done != 1 || len(l.Ages) != 1 || l.Ages[0] != 5 {
+		t.Fatalf("expected 3 stories, 1 done and 1 five days old, got %+v", l)
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultHistoryStore = "jira-history.jsonl"
+
+// backfillRetries is how many times a window is refetched after the server
+// rate limits it:
+const backfillRetries = 3
+
+// historyRecord is one line of the history store: a completed issue, or a
+// marker that every issue resolved in a month is stored.
+type historyRecord struct {
+	Board      int        `json:"board"`
+	Key        string     `json:"key,omitempty"`
+	Type       string     `json:"type,omitempty"`
+	Created    time.Time  `json:"created"`
+	Started    *time.Time `json:"started,omitempty"`
+	Completed  time.Time  `json:"completed"`
+	Resolution string     `json:"resolution,omitempty"`
+	// Window is set on markers: the month, e.g. "2023-01", backfilled
+	// completely.
+	Window string `json:"window,omitempty"`
+}
+
+// historyStore is a board's stored history:
+type historyStore struct {
+	Issues  map[string]*historyRecord
+	Windows map[string]bool
+}
+
+func newHistoryRecord(boardId int, issue *Issue) (*historyRecord, bool) {
+	completed, ok := issue.CompletedTime()
+	if !ok || issue.IsEpic() {
+		return nil, false
+	}
+	r := &historyRecord{
+		Board:      boardId,
+		Key:        issue.Key,
+		Type:       issue.Fields.IssueType.Name,
+		Created:    issue.Fields.Created.Time,
+		Completed:  completed,
+		Resolution: issue.ResolutionName(),
+	}
+	if started, ok := issue.StartedTime(); ok {
+		r.Started = &started
+	}
+	return r, true
+}
+
+// issue rebuilds enough of the completed issue for throughput and cycle time:
+func (r *historyRecord) issue() Issue {
+	issue := Issue{Key: r.Key, Source: "history"}
+	issue.Fields.IssueType.Name = r.Type
+	issue.Fields.Created.Time = r.Created
+	issue.Fields.ResolutionDate.Time = r.Completed
+	issue.Fields.Status.StatusCategory.Key = "done"
+	issue.Fields.Resolution = &Resolution{Name: r.Resolution}
+	if r.Started != nil {
+		status := inProgressStatus
+		if len(config.StartStatuses) > 0 {
+			status = config.StartStatuses[0]
+		}
+		issue.Changelog.Histories = []History{{
+			Created: zonedTimestamp{*r.Started},
+			Items:   []HistoryItem{{Field: "status", ToString: status}},
+		}}
+		issue.Changelog.Total = 1
+	}
+	return issue
+}
+
+func appendHistory(filename string, records []*historyRecord) error {
+	unlock, err := lockFile(filename)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	for _, r := range records {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		if b, err = encryptLine(b); err != nil {
+			return err
+		}
+		w.Write(append(b, '\n'))
+	}
+	return w.Flush()
+}
+
+// loadHistory reads a board's stored history; a missing store is empty.
+func loadHistory(filename string, boardId int) (*historyStore, error) {
+	store := &historyStore{Issues: make(map[string]*historyRecord), Windows: make(map[string]bool)}
+	b, err := readBeforeWrites(filename, ioutil.ReadFile)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Memoized reports are recomputed once more history is stored:
+	recordSnapshot(filename, b)
+
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		b, err := decryptLine([]byte(line))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		r := &historyRecord{}
+		if err := json.Unmarshal(b, r); err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		if r.Board != boardId {
+			continue
+		}
+		if r.Window != "" {
+			store.Windows[r.Window] = true
+		} else {
+			store.Issues[r.Key] = r
+		}
+	}
+	return store, scanner.Err()
+}
+
+// monthStart is the first of the month t falls in, in the report time zone:
+func monthStart(t time.Time) time.Time {
+	t = displayTime(t)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// storedHistory returns the issues completed since the given time that the
+// store holds, and the time through which it holds all of them; later
+// completions must be fetched.
+func storedHistory(filename string, boardId int, since time.Time) ([]Issue, time.Time, error) {
+	store, err := loadHistory(filename, boardId)
+	if err != nil {
+		return nil, since, err
+	}
+	through := monthStart(since)
+	for store.Windows[through.Format("2006-01")] {
+		through = through.AddDate(0, 1, 0)
+	}
+	if !through.After(since) {
+		return nil, since, nil
+	}
+
+	var issues []Issue
+	for _, r := range store.Issues {
+		if !r.Completed.Before(since) && r.Completed.Before(through) {
+			issues = append(issues, r.issue())
+		}
+	}
+	return issues, through, nil
+}
+
+// fetchCompletedSince fetches the board's issues completed since the given
+// time, taking what the history store holds from it and fetching the rest.
+// The store can't be filtered by JQL, tag or team, so it isn't used with any.
+func fetchCompletedSince(cl *http.Client, boardId int, report string, since time.Time) ([]Issue, error) {
+	if jql := reportJQL(report, "", ""); jql != "" {
+		return fetchBoardIssues(cl, boardId, jql)
+	}
+	// Stored history is as of now, not -as-of:
+	if len(includeTags) > 0 || len(excludeTags) > 0 || teamFilter != "" || !asOf.IsZero() {
+		return fetchBoardIssues(cl, boardId, fmt.Sprintf(`statusCategory = Done AND resolved >= "%s"`, displayTime(since).Format("2006-01-02")))
+	}
+
+	stored, through, err := storedHistory(defaultHistoryStore, boardId, since)
+	if err != nil {
+		log.Printf("history: %v\n", err)
+		stored, through = nil, since
+	}
+	filter := fmt.Sprintf(`statusCategory = Done AND resolved >= "%s"`, displayTime(through).Format("2006-01-02"))
+	issues, err := fetchBoardIssues(cl, boardId, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	fetchedKeys := make(map[string]bool, len(issues))
+	for i := range issues {
+		fetchedKeys[issues[i].Key] = true
+	}
+	for _, issue := range stored {
+		if !fetchedKeys[issue.Key] {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// fetchWindow fetches the issues resolved in a backfill window, waiting out
+// rate limits.
+func fetchWindow(cl *http.Client, boardId int, jql string) ([]Issue, error) {
+	for attempt := 0; ; attempt++ {
+		issues, err := fetchBoardIssues(cl, boardId, jql)
+		var rateErr *RateLimitError
+		if !errors.As(err, &rateErr) || attempt == backfillRetries {
+			return issues, err
+		}
+		wait := rateErr.RetryAfter
+		if wait == 0 {
+			wait = 30 * time.Second
+		}
+		log.Printf("backfill: rate limited; retrying in %s\n", wait)
+		time.Sleep(wait)
+	}
+}
+
+func runBackfill(args []string) error {
+	fs := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	sinceFlag := fs.String("since", "", "backfill issues resolved since the start of this date's month, e.g. 2023-01-01")
+	store := fs.String("store", defaultHistoryStore, "file the history is appended to")
+	pause := fs.Duration("pause", time.Second, "pause between monthly windows, to go easy on the server")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *sinceFlag == "" {
+		return fmt.Errorf("usage: %s backfill -since yyyy-mm-dd [-store file] [-pause duration] [boardId]", programName())
+	}
+	since, err := time.ParseInLocation("2006-01-02", *sinceFlag, displayTime(reportNow()).Location())
+	if err != nil {
+		return fmt.Errorf("-since: %v", err)
+	}
+
+	cl := httpClient()
+	boardId := boardArg(fs.Args())
+	history, err := loadHistory(*store, boardId)
+	if err != nil {
+		return err
+	}
+
+	now := reportNow()
+	var windows []time.Time
+	for start := monthStart(since); start.Before(now); start = start.AddDate(0, 1, 0) {
+		windows = append(windows, start)
+	}
+
+	skipped := 0
+	for w, start := range windows {
+		window := start.Format("2006-01")
+		if history.Windows[window] {
+			skipped++
+			continue
+		}
+		end := start.AddDate(0, 1, 0)
+		jql := fmt.Sprintf(`statusCategory = Done AND resolved >= "%s" AND resolved < "%s"`, start.Format("2006-01-02"), end.Format("2006-01-02"))
+
+		gaps := len(fetched.Gaps)
+		issues, err := fetchWindow(cl, boardId, jql)
+		if err != nil {
+			return fmt.Errorf("%s: %w; rerun to resume", window, err)
+		}
+
+		var records []*historyRecord
+		for i := range issues {
+			r, ok := newHistoryRecord(boardId, &issues[i])
+			if ok && history.Issues[r.Key] == nil {
+				records = append(records, r)
+				history.Issues[r.Key] = r
+			}
+		}
+		added := len(records)
+		// Only a month that is over and fetched whole is done; the rest are
+		// refetched next time:
+		if len(fetched.Gaps) == gaps && !end.After(now) {
+			records = append(records, &historyRecord{Board: boardId, Window: window})
+		}
+		if !rerendering {
+			if err := appendHistory(*store, records); err != nil {
+				return err
+			}
+		}
+		progress.Clear()
+		fmt.Printf("%s: %s resolved, %d new\n", window, plural(len(issues), "issue"), added)
+
+		if w < len(windows)-1 && *pause > 0 {
+			time.Sleep(*pause)
+		}
+	}
+	if skipped > 0 {
+		fmt.Printf("%s already backfilled.\n", plural(skipped, "month"))
+	}
+	fmt.Printf("%s holds %s for board %d.\n", *store, plural(len(history.Issues), "completed issue"), boardId)
+	return nil
+}
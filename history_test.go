@@ -0,0 +1,96 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStoredHistory(t *testing.T) {
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+
+	started := time.Date(2018, 10, 15, 9, 0, 0, 0, cst)
+	err := appendHistory("history.jsonl", []*historyRecord{
+		{Board: 7, Key: "A-1", Completed: time.Date(2018, 9, 10, 9, 0, 0, 0, cst)},
+		{Board: 7, Key: "A-2", Started: &started, Completed: time.Date(2018, 10, 20, 9, 0, 0, 0, cst)},
+		{Board: 7, Key: "A-3", Completed: time.Date(2018, 11, 5, 9, 0, 0, 0, cst)},
+		{Board: 8, Key: "B-1", Completed: time.Date(2018, 10, 20, 9, 0, 0, 0, cst)},
+		{Board: 7, Window: "2018-09"},
+		{Board: 7, Window: "2018-10"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues, through, err := storedHistory("history.jsonl", 7, time.Date(2018, 9, 15, 0, 0, 0, 0, cst))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// November isn't backfilled, so A-3 must be fetched:
+	if through.Format("2006-01-02") != "2018-11-01" {
+		t.Fatalf("expected history through 2018-11-01, got %v", through)
+	}
+	if len(issues) != 1 || issues[0].Key != "A-2" {
+		t.Fatalf("expected only A-2, got %d issues", len(issues))
+	}
+	if days, ok := issues[0].CycleTime(); !ok || days != 5 {
+		t.Fatalf("expected a 5 day cycle time from the stored start, got %d (%v)", days, ok)
+	}
+
+	// A gap in the windows ends what the store covers:
+	if issues, through, _ := storedHistory("history.jsonl", 7, time.Date(2018, 8, 15, 0, 0, 0, 0, cst)); issues != nil || through.Month() != time.August {
+		t.Fatalf("expected nothing stored from August, got %d through %v", len(issues), through)
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	srv := fixtureServer(t)
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	for key, value := range map[string]string{
+		"JIRA_URL":     srv.URL,
+		"JIRA_NOCACHE": "1",
+		"JIRA_NOW":     "2018-11-06T20:00:00-06:00",
+	} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+	defer func() { fetched = fetchStats{} }()
+
+	args := []string{"-since", "2018-09-12", "-pause", "0", "1"}
+	out, err := captureOutput(func() error { return runBackfill(args) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The fixture answers every window with all its issues:
+	if !strings.Contains(out, "2018-09: 10 issues resolved, 4 new") || !strings.Contains(out, "2018-11: 10 issues resolved, 0 new") {
+		t.Fatalf("expected the done issues stored once, got:\n%s", out)
+	}
+
+	store, err := loadHistory(defaultHistoryStore, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(store.Issues) != 4 || !store.Windows["2018-09"] || !store.Windows["2018-10"] || store.Windows["2018-11"] {
+		t.Fatalf("expected 4 issues and September and October complete, got %d and %v", len(store.Issues), store.Windows)
+	}
+
+	// Resuming skips the completed months:
+	out, err = captureOutput(func() error { return runBackfill(args) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "2018-09:") || !strings.Contains(out, "2 months already backfilled") {
+		t.Fatalf("expected September and October skipped, got:\n%s", out)
+	}
+}
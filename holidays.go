@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JamesDunne/jira-analysis/analysis"
+)
+
+//go:generate go run holidays_gen.go
+
+// holidayCalendar is a bundled national calendar, generated into
+// assets/holidays by holidays_gen.go:
+type holidayCalendar struct {
+	Country string `json:"country"`
+	Name    string `json:"name"`
+	From    int    `json:"from"`
+	To      int    `json:"to"`
+	// Regions are the subdivisions with holidays of their own:
+	Regions  []string `json:"regions"`
+	Holidays []struct {
+		Date    string   `json:"date"`
+		Name    string   `json:"name"`
+		Regions []string `json:"regions"`
+	} `json:"holidays"`
+}
+
+// holidayCalendars lists the bundled calendars, e.g. "DE" and "DE-BY":
+func holidayCalendars() []string {
+	entries, _ := fs.ReadDir(assets, "assets/holidays")
+	var codes []string
+	for _, entry := range entries {
+		cal, err := loadHolidayCalendar(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		codes = append(codes, cal.Country)
+		for _, region := range cal.Regions {
+			codes = append(codes, cal.Country+"-"+region)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func loadHolidayCalendar(country string) (*holidayCalendar, error) {
+	b, err := assets.ReadFile("assets/holidays/" + country + ".json")
+	if err != nil {
+		return nil, err
+	}
+	cal := &holidayCalendar{}
+	if err = json.Unmarshal(b, cal); err != nil {
+		return nil, fmt.Errorf("%s holidays: %v", country, err)
+	}
+	return cal, nil
+}
+
+// calendarHolidays returns the dates ("2006-01-02") a bundled calendar has
+// off: a country's, e.g. "US", or a region's, e.g. "DE-BY", which adds the
+// region's own holidays to the nationwide ones.
+func calendarHolidays(code string) (map[string]string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	country, region := code, ""
+	if i := strings.Index(code, "-"); i >= 0 {
+		country, region = code[:i], code[i+1:]
+	}
+	if country == "UK" {
+		country = "GB"
+	}
+	cal, err := loadHolidayCalendar(country)
+	if err != nil {
+		return nil, fmt.Errorf("no holiday calendar for '%s'; bundled are %s", code, strings.Join(holidayCalendars(), ", "))
+	}
+	if region != "" && !containsString(cal.Regions, region) {
+		return nil, fmt.Errorf("no holiday calendar for '%s'; %s regions bundled are %s", code, cal.Name, strings.Join(cal.Regions, ", "))
+	}
+
+	holidays := make(map[string]string)
+	for _, h := range cal.Holidays {
+		if len(h.Regions) == 0 || containsString(h.Regions, region) {
+			holidays[h.Date] = h.Name
+		}
+	}
+	return holidays, nil
+}
+
+var (
+	workCalendarMu     sync.Mutex
+	workCalendarConfig *Config
+	workCalendarDays   analysis.Holidays
+)
+
+// workCalendar is what ages and cycle times count business days in: weekdays,
+// less the configured holidays. A holiday calendar that won't load is logged
+// and left out, as config validate reports it.
+func workCalendar() analysis.Calendar {
+	workCalendarMu.Lock()
+	defer workCalendarMu.Unlock()
+	if workCalendarConfig != config {
+		holidays, err := configHolidays()
+		if err != nil {
+			log.Printf("%v; counting weekdays only\n", err)
+		}
+		workCalendarConfig, workCalendarDays = config, holidays
+	}
+	return workCalendarDays
+}
+
+// businessDaysUntil counts the work calendar's business days from one time
+// until another:
+func businessDaysUntil(from time.Time, until time.Time) int {
+	return workCalendar().BusinessDays(from, until)
+}
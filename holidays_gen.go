@@ -0,0 +1,358 @@
+//go:build ignore
+// +build ignore
+
+// holidays_gen.go writes the bundled holiday calendars, assets/holidays/*.json,
+// from the rules below. Update the rules when a country adds or moves a
+// holiday, then run:
+//
+//	go generate
+//
+// or, for other years, go run holidays_gen.go -from 2024 -to 2040.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// rule is one holiday: its date in a year, where it's observed and since
+// when.
+type rule struct {
+	name string
+	date func(year int) time.Time
+	// regions lists the subdivisions observing it; nil is nationwide:
+	regions []string
+	// from and until bound the years it's observed in, when set:
+	from, until int
+}
+
+// exception changes one year's holidays where the rules can't: a bank
+// holiday moved for an occasion, or a one-off such as a jubilee or a state
+// funeral.
+type exception struct {
+	// date is the day off; for a moved holiday, replaces names the rule's
+	// holiday, which keeps its name unless name says otherwise:
+	date     string
+	replaces string
+	name     string
+	regions  []string
+}
+
+// Weekend holidays move: substitute days go to the next free weekday, observed
+// ones to the nearest weekday.
+const (
+	noShift = iota
+	substitute
+	observed
+)
+
+type country struct {
+	code, name string
+	// regions are the bundled subdivisions, ISO 3166-2 codes without the
+	// country prefix:
+	regions []string
+	shift   int
+	rules   []rule
+	// exceptions are one-offs, applied over the rules in their year:
+	exceptions []exception
+}
+
+func fixed(month time.Month, day int) func(int) time.Time {
+	return func(year int) time.Time { return time.Date(year, month, day, 0, 0, 0, 0, time.UTC) }
+}
+
+// nth is the nth weekday of the month, counting from its end when negative.
+func nth(n int, weekday time.Weekday, month time.Month) func(int) time.Time {
+	return func(year int) time.Time {
+		if n < 0 {
+			last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+			return last.AddDate(0, 0, -((int(last.Weekday())-int(weekday)+7)%7 + 7*(-n-1)))
+		}
+		first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+		return first.AddDate(0, 0, (int(weekday)-int(first.Weekday())+7)%7+7*(n-1))
+	}
+}
+
+// easter is Western Easter Sunday plus days, by the anonymous Gregorian
+// algorithm.
+func easter(days int) func(int) time.Time {
+	return func(year int) time.Time {
+		a, b, c := year%19, year/100, year%100
+		d, e := b/4, b%4
+		f := (b + 8) / 25
+		g := (b - f + 1) / 3
+		h := (19*a + b - d - g + 15) % 30
+		i, k := c/4, c%4
+		l := (32 + 2*e + 2*i - h - k) % 7
+		m := (a + 11*h + 22*l) / 451
+		month := (h + l - 7*m + 114) / 31
+		day := (h+l-7*m+114)%31 + 1
+		return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC).AddDate(0, 0, days)
+	}
+}
+
+func after(date func(int) time.Time, days int) func(int) time.Time {
+	return func(year int) time.Time { return date(year).AddDate(0, 0, days) }
+}
+
+// before is the last weekday before the given day of the month.
+func before(weekday time.Weekday, month time.Month, day int) func(int) time.Time {
+	return func(year int) time.Time {
+		d := time.Date(year, month, day-1, 0, 0, 0, 0, time.UTC)
+		return d.AddDate(0, 0, -((int(d.Weekday()) - int(weekday) + 7) % 7))
+	}
+}
+
+// Lunar and regional festivals that move from year to year (Diwali, Holi, Eid
+// and the like) aren't bundled; teams add them with "holidays".
+var countries = []country{
+	{
+		code: "US", name: "United States", shift: observed,
+		regions: []string{"CA", "MA", "TX"},
+		rules: []rule{
+			{name: "New Year's Day", date: fixed(time.January, 1)},
+			{name: "Martin Luther King Jr. Day", date: nth(3, time.Monday, time.January)},
+			{name: "Washington's Birthday", date: nth(3, time.Monday, time.February)},
+			{name: "Texas Independence Day", date: fixed(time.March, 2), regions: []string{"TX"}},
+			{name: "César Chávez Day", date: fixed(time.March, 31), regions: []string{"CA"}},
+			{name: "Patriots' Day", date: nth(3, time.Monday, time.April), regions: []string{"MA"}},
+			{name: "San Jacinto Day", date: fixed(time.April, 21), regions: []string{"TX"}},
+			{name: "Memorial Day", date: nth(-1, time.Monday, time.May)},
+			{name: "Juneteenth", date: fixed(time.June, 19), from: 2021},
+			{name: "Independence Day", date: fixed(time.July, 4)},
+			{name: "Labor Day", date: nth(1, time.Monday, time.September)},
+			{name: "Columbus Day", date: nth(2, time.Monday, time.October)},
+			{name: "Veterans Day", date: fixed(time.November, 11)},
+			{name: "Thanksgiving Day", date: nth(4, time.Thursday, time.November)},
+			{name: "Day after Thanksgiving", date: after(nth(4, time.Thursday, time.November), 1), regions: []string{"CA", "TX"}},
+			{name: "Christmas Day", date: fixed(time.December, 25)},
+		},
+	},
+	{
+		code: "GB", name: "United Kingdom", shift: substitute,
+		regions: []string{"ENG", "NIR", "SCT", "WLS"},
+		rules: []rule{
+			{name: "New Year's Day", date: fixed(time.January, 1)},
+			{name: "2nd January", date: fixed(time.January, 2), regions: []string{"SCT"}},
+			{name: "St Patrick's Day", date: fixed(time.March, 17), regions: []string{"NIR"}},
+			{name: "Good Friday", date: easter(-2)},
+			{name: "Easter Monday", date: easter(1), regions: []string{"ENG", "NIR", "WLS"}},
+			{name: "Early May bank holiday", date: nth(1, time.Monday, time.May)},
+			{name: "Spring bank holiday", date: nth(-1, time.Monday, time.May)},
+			{name: "Battle of the Boyne", date: fixed(time.July, 12), regions: []string{"NIR"}},
+			{name: "Summer bank holiday", date: nth(1, time.Monday, time.August), regions: []string{"SCT"}},
+			{name: "Summer bank holiday", date: nth(-1, time.Monday, time.August), regions: []string{"ENG", "NIR", "WLS"}},
+			{name: "St Andrew's Day", date: fixed(time.November, 30), regions: []string{"SCT"}},
+			{name: "Christmas Day", date: fixed(time.December, 25)},
+			{name: "Boxing Day", date: fixed(time.December, 26)},
+		},
+		exceptions: []exception{
+			{date: "2020-05-08", replaces: "Early May bank holiday", name: "Early May bank holiday (VE Day)"},
+			{date: "2022-06-02", replaces: "Spring bank holiday"},
+			{date: "2022-06-03", name: "Platinum Jubilee bank holiday"},
+			{date: "2022-09-19", name: "State Funeral of Queen Elizabeth II"},
+			{date: "2023-05-08", name: "Coronation of King Charles III"},
+		},
+	},
+	{
+		code: "DE", name: "Germany",
+		regions: []string{"BB", "BE", "BW", "BY", "HB", "HE", "HH", "MV", "NI", "NW", "RP", "SH", "SL", "SN", "ST", "TH"},
+		rules: []rule{
+			{name: "New Year's Day", date: fixed(time.January, 1)},
+			{name: "Epiphany", date: fixed(time.January, 6), regions: []string{"BW", "BY", "ST"}},
+			{name: "International Women's Day", date: fixed(time.March, 8), regions: []string{"BE"}},
+			{name: "International Women's Day", date: fixed(time.March, 8), regions: []string{"MV"}, from: 2023},
+			{name: "Good Friday", date: easter(-2)},
+			{name: "Easter Monday", date: easter(1)},
+			{name: "Labour Day", date: fixed(time.May, 1)},
+			{name: "Ascension Day", date: easter(39)},
+			{name: "Whit Monday", date: easter(50)},
+			{name: "Corpus Christi", date: easter(60), regions: []string{"BW", "BY", "HE", "NW", "RP", "SL"}},
+			{name: "Assumption Day", date: fixed(time.August, 15), regions: []string{"SL"}},
+			{name: "World Children's Day", date: fixed(time.September, 20), regions: []string{"TH"}},
+			{name: "German Unity Day", date: fixed(time.October, 3)},
+			{name: "Reformation Day", date: fixed(time.October, 31), regions: []string{"BB", "HB", "HH", "MV", "NI", "SH", "SN", "ST", "TH"}},
+			{name: "All Saints' Day", date: fixed(time.November, 1), regions: []string{"BW", "BY", "NW", "RP", "SL"}},
+			{name: "Repentance and Prayer Day", date: before(time.Wednesday, time.November, 23), regions: []string{"SN"}},
+			{name: "Christmas Day", date: fixed(time.December, 25)},
+			{name: "St Stephen's Day", date: fixed(time.December, 26)},
+		},
+	},
+	{
+		code: "PL", name: "Poland",
+		rules: []rule{
+			{name: "New Year's Day", date: fixed(time.January, 1)},
+			{name: "Epiphany", date: fixed(time.January, 6)},
+			{name: "Easter Sunday", date: easter(0)},
+			{name: "Easter Monday", date: easter(1)},
+			{name: "Labour Day", date: fixed(time.May, 1)},
+			{name: "Constitution Day", date: fixed(time.May, 3)},
+			{name: "Pentecost", date: easter(49)},
+			{name: "Corpus Christi", date: easter(60)},
+			{name: "Assumption Day", date: fixed(time.August, 15)},
+			{name: "All Saints' Day", date: fixed(time.November, 1)},
+			{name: "Independence Day", date: fixed(time.November, 11)},
+			{name: "Christmas Eve", date: fixed(time.December, 24), from: 2025},
+			{name: "Christmas Day", date: fixed(time.December, 25)},
+			{name: "Second Day of Christmas", date: fixed(time.December, 26)},
+		},
+	},
+	{
+		code: "BR", name: "Brazil",
+		regions: []string{"BA", "RJ", "RS", "SP"},
+		rules: []rule{
+			{name: "New Year's Day", date: fixed(time.January, 1)},
+			{name: "Carnival Monday", date: easter(-48)},
+			{name: "Carnival Tuesday", date: easter(-47)},
+			{name: "Good Friday", date: easter(-2)},
+			{name: "Tiradentes' Day", date: fixed(time.April, 21)},
+			{name: "St George's Day", date: fixed(time.April, 23), regions: []string{"RJ"}},
+			{name: "Labour Day", date: fixed(time.May, 1)},
+			{name: "Corpus Christi", date: easter(60)},
+			{name: "Bahia Independence Day", date: fixed(time.July, 2), regions: []string{"BA"}},
+			{name: "Constitutionalist Revolution", date: fixed(time.July, 9), regions: []string{"SP"}},
+			{name: "Independence Day", date: fixed(time.September, 7)},
+			{name: "Gaúcho Day", date: fixed(time.September, 20), regions: []string{"RS"}},
+			{name: "Our Lady of Aparecida", date: fixed(time.October, 12)},
+			{name: "All Souls' Day", date: fixed(time.November, 2)},
+			{name: "Republic Proclamation Day", date: fixed(time.November, 15)},
+			{name: "Black Consciousness Day", date: fixed(time.November, 20), from: 2024},
+			{name: "Christmas Day", date: fixed(time.December, 25)},
+		},
+	},
+	{
+		code: "IN", name: "India",
+		regions: []string{"KA", "MH", "TG", "TN"},
+		rules: []rule{
+			{name: "Republic Day", date: fixed(time.January, 26)},
+			{name: "Tamil New Year", date: fixed(time.April, 14), regions: []string{"TN"}},
+			{name: "Maharashtra Day", date: fixed(time.May, 1), regions: []string{"MH"}},
+			{name: "Telangana Formation Day", date: fixed(time.June, 2), regions: []string{"TG"}},
+			{name: "Independence Day", date: fixed(time.August, 15)},
+			{name: "Gandhi Jayanti", date: fixed(time.October, 2)},
+			{name: "Kannada Rajyotsava", date: fixed(time.November, 1), regions: []string{"KA"}},
+			{name: "Christmas Day", date: fixed(time.December, 25)},
+		},
+	},
+}
+
+// holiday is one day off in the generated calendar:
+type holiday struct {
+	Date    string   `json:"date"`
+	Name    string   `json:"name"`
+	Regions []string `json:"regions,omitempty"`
+}
+
+type calendar struct {
+	Country  string    `json:"country"`
+	Name     string    `json:"name"`
+	From     int       `json:"from"`
+	To       int       `json:"to"`
+	Regions  []string  `json:"regions,omitempty"`
+	Holidays []holiday `json:"holidays"`
+}
+
+func generate(c country, from, to int) calendar {
+	cal := calendar{Country: c.code, Name: c.name, From: from, To: to, Regions: c.regions}
+	for year := from; year <= to; year++ {
+		var days []holiday
+		for _, r := range c.rules {
+			if (r.from != 0 && year < r.from) || (r.until != 0 && year > r.until) {
+				continue
+			}
+			days = append(days, holiday{Date: r.date(year).Format("2006-01-02"), Name: r.name, Regions: r.regions})
+		}
+		days = applyExceptions(days, c.exceptions, year)
+		sort.SliceStable(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+
+		// Weekday holidays keep their day; weekend ones then move in date
+		// order, so Christmas and Boxing Day on a weekend take Monday and
+		// Tuesday:
+		taken := make(map[string]bool)
+		for _, h := range days {
+			taken[h.Date] = true
+		}
+		for i := range days {
+			d, _ := time.Parse("2006-01-02", days[i].Date)
+			if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+				continue
+			}
+			switch c.shift {
+			case observed:
+				if d.Weekday() == time.Saturday {
+					d = d.AddDate(0, 0, -1)
+				} else {
+					d = d.AddDate(0, 0, 1)
+				}
+				days[i].Date = d.Format("2006-01-02")
+				days[i].Name += " (observed)"
+			case substitute:
+				for d.Weekday() == time.Saturday || d.Weekday() == time.Sunday || taken[d.Format("2006-01-02")] {
+					d = d.AddDate(0, 0, 1)
+				}
+				taken[d.Format("2006-01-02")] = true
+				days[i].Date = d.Format("2006-01-02")
+				days[i].Name += " (substitute day)"
+			}
+		}
+		sort.SliceStable(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+		cal.Holidays = append(cal.Holidays, days...)
+	}
+	return cal
+}
+
+// applyExceptions moves the holidays a year's exceptions replace and adds the
+// one-offs:
+func applyExceptions(days []holiday, exceptions []exception, year int) []holiday {
+	for _, e := range exceptions {
+		if !strings.HasPrefix(e.date, fmt.Sprintf("%d-", year)) {
+			continue
+		}
+		if e.replaces == "" {
+			days = append(days, holiday{Date: e.date, Name: e.name, Regions: e.regions})
+			continue
+		}
+		found := false
+		for i := range days {
+			if days[i].Name == e.replaces {
+				days[i].Date = e.date
+				if e.name != "" {
+					days[i].Name = e.name
+				}
+				found = true
+			}
+		}
+		if !found {
+			log.Fatalf("exception %s replaces %q, which isn't a holiday that year", e.date, e.replaces)
+		}
+	}
+	sort.SliceStable(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days
+}
+
+func main() {
+	from := flag.Int("from", 2020, "first year to generate")
+	to := flag.Int("to", 2035, "last year to generate")
+	dir := flag.String("dir", filepath.Join("assets", "holidays"), "directory to write the calendars to")
+	flag.Parse()
+
+	for _, c := range countries {
+		cal := generate(c, *from, *to)
+		b, err := json.MarshalIndent(cal, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		filename := filepath.Join(*dir, c.code+".json")
+		if err = ioutil.WriteFile(filename, append(b, '\n'), 0644); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s: %d holidays, %d–%d\n", filename, len(cal.Holidays), *from, *to)
+	}
+}
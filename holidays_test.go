@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JamesDunne/jira-analysis/analysis"
+)
+
+func TestCalendarHolidays(t *testing.T) {
+	us, err := calendarHolidays("US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Thanksgiving, and Independence Day observed on the Friday before:
+	if us["2024-11-28"] != "Thanksgiving Day" || us["2026-07-03"] != "Independence Day (observed)" {
+		t.Errorf("expected Thanksgiving and July 4th observed, got %q and %q", us["2024-11-28"], us["2026-07-03"])
+	}
+	if _, ok := us["2024-11-29"]; ok {
+		t.Errorf("day after Thanksgiving is only a state holiday")
+	}
+
+	// Regions add their own holidays; Easter Monday isn't one in Scotland:
+	by, err := calendarHolidays("de-by")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if by["2024-01-06"] != "Epiphany" || by["2024-04-01"] != "Easter Monday" {
+		t.Errorf("expected Epiphany and Easter Monday in Bavaria, got %v", by)
+	}
+	sct, err := calendarHolidays("UK-SCT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sct["2024-04-01"]; ok || sct["2024-11-29"] != "" || sct["2021-12-28"] != "Boxing Day (substitute day)" {
+		t.Errorf("unexpected Scottish holidays: %v", sct)
+	}
+
+	// One-offs: VE Day moved the early May holiday, the Platinum Jubilee the
+	// spring one, and the state funeral and coronation were extra days off:
+	gb, err := calendarHolidays("GB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, day := range []string{"2020-05-08", "2022-06-02", "2022-06-03", "2022-09-19", "2023-05-08"} {
+		if gb[day] == "" {
+			t.Errorf("expected %s off in the UK", day)
+		}
+	}
+	if gb["2020-05-04"] != "" || gb["2022-05-30"] != "" {
+		t.Errorf("expected the moved bank holidays off their usual days, got %q and %q", gb["2020-05-04"], gb["2022-05-30"])
+	}
+
+	if _, err := calendarHolidays("FR"); err == nil || !strings.Contains(err.Error(), "BR, BR-BA") {
+		t.Errorf("expected an error listing the bundled calendars, got %v", err)
+	}
+	if _, err := calendarHolidays("US-NY"); err == nil || !strings.Contains(err.Error(), "CA, MA, TX") {
+		t.Errorf("expected an error listing the bundled regions, got %v", err)
+	}
+}
+
+func TestConfigHolidays(t *testing.T) {
+	config = &Config{HolidayCalendar: "PL", Holidays: []string{"2025-05-02"}}
+	defer func() { config = &Config{} }()
+
+	holidays, err := configHolidays()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, day := range []string{"2025-05-02", "2025-05-03", "2025-12-24"} {
+		if !holidays[day] {
+			t.Errorf("expected %s off", day)
+		}
+	}
+	if holidays["2024-12-24"] {
+		t.Errorf("Christmas Eve is a holiday only from 2025")
+	}
+}
+
+func TestWorkCalendar_SkipsHolidays(t *testing.T) {
+	// Thanksgiving 2024, Wednesday to Monday:
+	issue := completedIssue("A-1", time.Date(2024, 11, 27, 9, 0, 0, 0, time.UTC), time.Date(2024, 12, 2, 9, 0, 0, 0, time.UTC))
+	config = &Config{}
+	if days, _ := issue.CycleTime(); days != 3 {
+		t.Fatalf("expected 3 weekdays without holidays, got %d", days)
+	}
+
+	config = &Config{HolidayCalendar: "US", Holidays: []string{"2024-11-29"}}
+	defer func() { config = &Config{} }()
+	if days, _ := issue.CycleTime(); days != 1 {
+		t.Fatalf("expected Thanksgiving and the configured Friday off, got %d", days)
+	}
+	aged := newAnalyzer(nil, analysis.WithNow(time.Date(2024, 12, 2, 9, 0, 0, 0, time.UTC))).AgingOf([]analysis.Issue{
+		{Key: "A-2", Status: inProgressStatus, StatusSince: time.Date(2024, 11, 27, 9, 0, 0, 0, time.UTC), Ref: &issue},
+	})
+	if aged[0].Age != 1 {
+		t.Fatalf("expected the analyzer to age in the work calendar too, got %d", aged[0].Age)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// connStats counts the connections behind HTTP requests, showing whether
+// keep-alives work and what TLS costs. Fields are updated atomically, so
+// concurrent requests can share it.
+type connStats struct {
+	New           int64
+	Reused        int64
+	TLSHandshakes int64
+	// TLSTime is the total time spent in TLS handshakes, in nanoseconds:
+	TLSTime int64
+}
+
+var connections connStats
+
+func (s *connStats) gotConn(info httptrace.GotConnInfo) {
+	if info.Reused {
+		atomic.AddInt64(&s.Reused, 1)
+	} else {
+		atomic.AddInt64(&s.New, 1)
+	}
+}
+
+func (s *connStats) handshake(d time.Duration) {
+	atomic.AddInt64(&s.TLSHandshakes, 1)
+	atomic.AddInt64(&s.TLSTime, int64(d))
+}
+
+// Load copies the stats as they stand:
+func (s *connStats) Load() connStats {
+	return connStats{
+		New:           atomic.LoadInt64(&s.New),
+		Reused:        atomic.LoadInt64(&s.Reused),
+		TLSHandshakes: atomic.LoadInt64(&s.TLSHandshakes),
+		TLSTime:       atomic.LoadInt64(&s.TLSTime),
+	}
+}
+
+func (s *connStats) String() string {
+	c := s.Load()
+	str := fmt.Sprintf("%s, %d reused", plural(int(c.New), "new connection"), c.Reused)
+	if c.TLSHandshakes > 0 {
+		str += fmt.Sprintf(", %s in %s", plural(int(c.TLSHandshakes), "TLS handshake"), time.Duration(c.TLSTime).Round(time.Millisecond))
+	}
+	return str
+}
+
+// instrumentedTransport records the connections its requests use:
+type instrumentedTransport struct {
+	base  http.RoundTripper
+	stats *connStats
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A connection is dialed, TLS and all, on one goroutine:
+	var tlsStart time.Time
+	trace := &httptrace.ClientTrace{
+		GotConn:           t.stats.gotConn,
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.stats.handshake(time.Since(tlsStart))
+		},
+	}
+	return t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+}
+
+// settings are the JIRA_* settings requests are made with: a merged
+// profile's own, as from Profile.env, or nil for the environment's. Settings a
+// profile leaves blank are blank, not the environment's; ones profiles have
+// no field for, such as JIRA_TOKEN, come from the environment.
+type settings map[string]string
+
+func (s settings) get(key string) string {
+	if value, ok := s[key]; ok {
+		return value
+	}
+	return os.Getenv(key)
+}
+
+// apiTransport is an API client's transport, carrying the settings its
+// requests are made with and why its TLS couldn't be set up, if it couldn't.
+type apiTransport struct {
+	http.RoundTripper
+	settings settings
+	tlsErr   error
+}
+
+var (
+	apiClientsMu sync.Mutex
+	// apiClients are keyed by the settings they're made with, so merged
+	// profiles each present their own client certificate:
+	apiClients = make(map[string]*http.Client)
+
+	webhookClientOnce sync.Once
+	webhookClient     *http.Client
+)
+
+// apiClientKey identifies the client for s: the TLS settings it's configured
+// from and, for a profile, the rest of its settings.
+func apiClientKey(s settings) string {
+	parts := []string{s.get("JIRA_AUTH"), s.get("JIRA_CLIENT_CERT"), s.get("JIRA_CLIENT_KEY")}
+	keys := make([]string, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts = append(parts, key+"="+s[key])
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// apiClientFor is the API client for requests made with s, created on first
+// use:
+func apiClientFor(s settings) *http.Client {
+	key := apiClientKey(s)
+	apiClientsMu.Lock()
+	defer apiClientsMu.Unlock()
+	if cl, ok := apiClients[key]; ok {
+		return cl
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Disable TLS cert verification:
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	api := &apiTransport{RoundTripper: &instrumentedTransport{base: transport, stats: &connections}, settings: s}
+	if provider, err := authProvider(s); err == nil {
+		if tlsProvider, ok := provider.(TLSAuthProvider); ok {
+			api.tlsErr = tlsProvider.ConfigureTLS(transport.TLSClientConfig, s)
+		}
+	}
+	cl := &http.Client{Transport: api}
+	apiClients[key] = cl
+	return cl
+}
+
+// httpClient is the client for JIRA and the other issue trackers, shared so
+// connections are kept alive across requests and safe for concurrent use.
+func httpClient() *http.Client {
+	return apiClientFor(nil)
+}
+
+// profileClient is the client for a merged profile's requests, carrying its
+// settings. A shared API client is swapped for the profile's own, with its
+// own client certificate; any other, such as a test's, keeps its transport.
+func profileClient(cl *http.Client, s settings) *http.Client {
+	if _, ok := cl.Transport.(*apiTransport); ok {
+		return apiClientFor(s)
+	}
+	transport := cl.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &http.Client{Transport: &apiTransport{RoundTripper: transport, settings: s}, Timeout: cl.Timeout}
+}
+
+// setting is a JIRA_* setting for requests made with cl:
+func setting(cl *http.Client, key string) string {
+	if api, ok := cl.Transport.(*apiTransport); ok {
+		return api.settings.get(key)
+	}
+	return os.Getenv(key)
+}
+
+// settingInt is a numeric JIRA_* setting for requests made with cl, or
+// defaultValue when it isn't set:
+func settingInt(cl *http.Client, key string, defaultValue int) int {
+	value, err := strconv.Atoi(setting(cl, key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// sinkClient is the client for output destinations: Slack, Sheets and
+// Confluence, which verify TLS and mustn't hold up a run for long.
+func sinkClient() *http.Client {
+	webhookClientOnce.Do(func() {
+		webhookClient = &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &instrumentedTransport{base: http.DefaultTransport, stats: &connections},
+		}
+	})
+	return webhookClient
+}
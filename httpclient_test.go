@@ -0,0 +1,55 @@
+package main
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestInstrumentedTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	stats := &connStats{}
+	cl := &http.Client{Transport: &instrumentedTransport{base: srv.Client().Transport, stats: stats}}
+	get := func() {
+		rsp, err := cl.Get(srv.URL)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		io.Copy(ioutil.Discard, rsp.Body)
+		rsp.Body.Close()
+	}
+	get()
+	get()
+	c := stats.Load()
+	if c.New != 1 || c.Reused != 1 || c.TLSHandshakes != 1 {
+		t.Fatalf("expected one new TLS connection reused once, got %+v", c)
+	}
+
+	// Concurrent requests share the client and its stats:
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			get()
+		}()
+	}
+	wg.Wait()
+	if c := stats.Load(); c.New+c.Reused != 10 {
+		t.Fatalf("expected 10 connections used, got %+v", c)
+	}
+}
+
+func TestHTTPClientShared(t *testing.T) {
+	if httpClient() != httpClient() {
+		t.Fatalf("expected one shared API client")
+	}
+}
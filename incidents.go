@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// IncidentRef returns what marks the issue as incident-driven: the external
+// ticket in the incident field, else a link of an incident link type; "" for
+// planned work.
+func (issue *Issue) IncidentRef() string {
+	if config.IncidentField != "" {
+		if ref := issue.Fields.CustomString(config.IncidentField); ref != "" {
+			return ref
+		}
+	}
+
+	for _, link := range issue.Fields.IssueLinks {
+		linked, description := link.OutwardIssue, link.Type.Outward
+		if linked == nil {
+			linked, description = link.InwardIssue, link.Type.Inward
+		}
+		if linked == nil {
+			continue
+		}
+		for _, linkType := range config.IncidentLinkTypes {
+			if strings.EqualFold(linkType, link.Type.Name) || strings.EqualFold(linkType, description) {
+				return description + " " + linked.Key
+			}
+		}
+	}
+	return ""
+}
+
+type workOrigin struct {
+	CycleTimes []int
+	WIP        int
+	Oldest     int
+}
+
+// incidentWork splits issues into incident-driven and planned work:
+func incidentWork(issues []Issue, today Date) (incident *workOrigin, planned *workOrigin, open []*Issue) {
+	incident, planned = &workOrigin{}, &workOrigin{}
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+
+		origin := planned
+		if issue.IncidentRef() != "" {
+			origin = incident
+		}
+		if days, ok := issue.CycleTime(); ok {
+			origin.CycleTimes = append(origin.CycleTimes, days)
+			continue
+		}
+		if _, done := issue.CompletedTime(); done {
+			continue
+		}
+		if started, ok := issue.StartedTime(); ok {
+			origin.WIP++
+			if age := DateOf(started).BusinessDaysUntil(today); age > origin.Oldest {
+				origin.Oldest = age
+			}
+		}
+		if origin == incident {
+			open = append(open, issue)
+		}
+	}
+	return incident, planned, open
+}
+
+func runIncidents(args []string) error {
+	fs := flag.NewFlagSet("incidents", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting issues; default='resolved >= -90d OR resolution is EMPTY'")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if config.IncidentField == "" && len(config.IncidentLinkTypes) == 0 {
+		return fmt.Errorf("no incidentField or incidentLinkTypes configured")
+	}
+
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("incidents", *jql, "resolved >= -90d OR resolution is EMPTY"))
+	if err != nil {
+		return err
+	}
+
+	incident, planned, open := incidentWork(issues, DateOf(reportNow()))
+	completed := len(incident.CycleTimes) + len(planned.CycleTimes)
+
+	fmt.Printf("Incident-driven vs planned work (ages and cycle time in business days):\n")
+	fmt.Printf("  %-10s %9s %6s %4s %6s %6s", "origin", "completed", "share", "WIP", "oldest", "ct avg")
+	for _, p := range percentiles {
+		fmt.Printf(" %6s", "ct "+percentileLabel(p))
+	}
+	fmt.Println()
+	for _, row := range []struct {
+		Name   string
+		Origin *workOrigin
+	}{{"incident", incident}, {"planned", planned}} {
+		sorted := sortedCopy(row.Origin.CycleTimes)
+		share := 0
+		if completed > 0 {
+			share = 100 * len(sorted) / completed
+		}
+		fmt.Printf("  %-10s %9d %5d%% %4d %6d %6.1f", row.Name, len(sorted), share, row.Origin.WIP, row.Origin.Oldest, mean(sorted))
+		for _, p := range percentiles {
+			fmt.Printf(" %6d", percentile(sorted, p))
+		}
+		fmt.Println()
+	}
+
+	if shown := detailLines(len(open)); shown > 0 {
+		fmt.Printf("\nOpen incident-driven issues:\n")
+		for _, issue := range open[:shown] {
+			fmt.Printf("  %-10s %-20s %s\n", issue.Key, issue.IncidentRef(), issue.DisplaySummary())
+		}
+		printOmitted("  ", shown, len(open))
+	}
+	return nil
+}
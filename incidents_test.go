@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestIssue_IncidentRef(t *testing.T) {
+	config = &Config{IncidentField: "customfield_10600", IncidentLinkTypes: []string{"is caused by"}}
+	defer func() { config = &Config{} }()
+
+	byField := &Issue{}
+	byField.Fields.Custom = map[string]json.RawMessage{"customfield_10600": json.RawMessage(`"ZD-4411"`)}
+	if ref := byField.IncidentRef(); ref != "ZD-4411" {
+		t.Fatalf("expected ticket from incident field, got %q", ref)
+	}
+
+	byLink := &Issue{}
+	byLink.Fields.IssueLinks = []IssueLink{
+		{Type: IssueLinkType{Name: "Relates", Inward: "relates to", Outward: "relates to"}, OutwardIssue: &ParentIssue{Key: "A-9"}},
+		{Type: IssueLinkType{Name: "Problem/Incident", Inward: "is caused by", Outward: "causes"}, InwardIssue: &ParentIssue{Key: "INC-12"}},
+	}
+	if ref := byLink.IncidentRef(); ref != "is caused by INC-12" {
+		t.Fatalf("expected incident link, got %q", ref)
+	}
+
+	if ref := (&Issue{}).IncidentRef(); ref != "" {
+		t.Fatalf("expected planned work, got %q", ref)
+	}
+}
+
+func TestIncidentWork_SplitsCycleTimes(t *testing.T) {
+	config = &Config{IncidentField: "customfield_10600"}
+	defer func() { config = &Config{} }()
+
+	day := func(d int) time.Time { return time.Date(2018, 11, d, 9, 0, 0, 0, time.UTC) }
+	hotfix := completedIssue("A-1", day(5), day(6))
+	hotfix.Fields.Custom = map[string]json.RawMessage{"customfield_10600": json.RawMessage(`"ZD-1"`)}
+	feature := completedIssue("A-2", day(1), day(8))
+
+	incident, planned, open := incidentWork([]Issue{hotfix, feature}, DateOf(day(9)))
+	if len(incident.CycleTimes) != 1 || incident.CycleTimes[0] != 1 {
+		t.Fatalf("expected incident cycle time [1], got %v", incident.CycleTimes)
+	}
+	if len(planned.CycleTimes) != 1 || planned.CycleTimes[0] != 5 {
+		t.Fatalf("expected planned cycle time [5], got %v", planned.CycleTimes)
+	}
+	if len(open) != 0 {
+		t.Fatalf("expected no open incident work, got %v", open)
+	}
+}
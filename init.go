@@ -0,0 +1,443 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// initInput is where init reads answers; tests replace it:
+var initInput io.Reader = os.Stdin
+
+// initBoardList is how many boards init lists before asking to narrow them:
+const initBoardList = 20
+
+// starterConfig is the part of Config init writes; everything else keeps its
+// default until edited:
+type starterConfig struct {
+	DefaultProfile string                     `json:"defaultProfile"`
+	Profiles       map[string]*starterProfile `json:"profiles"`
+	Percentiles    []float64                  `json:"percentiles"`
+
+	StoryPointsFieldId string `json:"storyPointsFieldId,omitempty"`
+	SeverityField      string `json:"severityField,omitempty"`
+	RankField          string `json:"rankField,omitempty"`
+	ParentLinkField    string `json:"parentLinkField,omitempty"`
+	CostOfDelayField   string `json:"costOfDelayField,omitempty"`
+
+	WorkStarted    string            `json:"workStarted,omitempty"`
+	ReviewStatuses []string          `json:"reviewStatuses,omitempty"`
+	QAStatuses     []string          `json:"qaStatuses,omitempty"`
+	StatusClasses  map[string]string `json:"statusClasses,omitempty"`
+	SLAPolicies    []SLAPolicy       `json:"slaPolicies"`
+	Badges         *starterBadges    `json:"badges,omitempty"`
+}
+
+type starterProfile struct {
+	URL      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	BoardId  int    `json:"boardId"`
+}
+
+type starterBadges struct {
+	BlockedStatuses []string `json:"blockedStatuses,omitempty"`
+	FlaggedField    string   `json:"flaggedField,omitempty"`
+}
+
+// starterSLAs are the SLA policies a new config starts with: ten business
+// days in any status, three for the most urgent priorities.
+var starterSLAs = []SLAPolicy{
+	{Days: 10},
+	{Priorities: []string{"Highest", "Blocker", "Critical"}, Days: 3},
+}
+
+// prompter asks questions on the terminal, offering a default in brackets:
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (p *prompter) ask(question string, fallback string) (string, error) {
+	if fallback != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, fallback)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF && fallback != "" {
+			return fallback, nil
+		}
+		return "", fmt.Errorf("no answer to %q", question)
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return fallback, nil
+}
+
+// askSecret asks without echoing the answer, where stty can turn echo off:
+func (p *prompter) askSecret(question string, fallback string) (string, error) {
+	if f, ok := initInput.(*os.File); ok && isTerminal(f) && stty(f, "-echo") == nil {
+		defer func() {
+			stty(f, "echo")
+			fmt.Fprintln(p.out)
+		}()
+	}
+	hint := question
+	if fallback != "" {
+		hint += " (blank keeps $JIRA_PASSWORD)"
+	}
+	answer, err := p.ask(hint, "")
+	if answer == "" && err == nil {
+		answer = fallback
+	}
+	return answer, err
+}
+
+func (p *prompter) confirm(question string, fallback bool) (bool, error) {
+	choices := "y/N"
+	if fallback {
+		choices = "Y/n"
+	}
+	answer, err := p.ask(question+" ("+choices+")", "")
+	if err != nil || answer == "" {
+		return fallback, err
+	}
+	return strings.HasPrefix(strings.ToLower(answer), "y"), nil
+}
+
+func stty(f *os.File, arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = f
+	return cmd.Run()
+}
+
+// runInit asks for the instance, credentials and board, discovers custom
+// fields and statuses, and writes a starter config with sensible SLA and
+// status mapping defaults.
+func runInit(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	output := fs.String("o", "", "write the config here; default $JIRA_CONFIG, else ./jira-analysis.json")
+	name := fs.String("name", "default", "name of the profile to create")
+	force := fs.Bool("force", false, "replace an existing config file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if backend := backendName(); backend != "jira" {
+		return fmt.Errorf("init sets up a JIRA instance, not the %s backend", backend)
+	}
+
+	filename := *output
+	if filename == "" {
+		filename = os.Getenv("JIRA_CONFIG")
+	}
+	if filename == "" {
+		filename = "jira-analysis.json"
+	}
+	if _, err := os.Stat(filename); err == nil && !*force {
+		return fmt.Errorf("%s already exists; use -force to replace it", filename)
+	}
+
+	p := &prompter{in: bufio.NewReader(initInput), out: os.Stdout}
+	profile := &starterProfile{}
+	var err error
+
+	if profile.URL, err = p.ask("JIRA URL", os.Getenv("JIRA_URL")); err != nil {
+		return err
+	}
+	profile.URL = strings.TrimRight(profile.URL, "/")
+	if profile.Username, err = p.ask("Username (your email on Cloud)", os.Getenv("JIRA_USERNAME")); err != nil {
+		return err
+	}
+	password, err := p.askSecret("Password (an API token on Cloud)", os.Getenv("JIRA_PASSWORD"))
+	if err != nil {
+		return err
+	}
+	os.Setenv("JIRA_URL", profile.URL)
+	os.Setenv("JIRA_USERNAME", profile.Username)
+	os.Setenv("JIRA_PASSWORD", password)
+
+	cl := httpClient()
+	api := profile.URL + "/rest/api/2/"
+	var myself struct {
+		DisplayName string `json:"displayName"`
+	}
+	if err := doJSON(cl, http.MethodGet, api+"myself", nil, &myself); err != nil {
+		return fmt.Errorf("signing in: %v; check the URL, username and password", err)
+	}
+	fmt.Printf("Signed in as %s.\n\n", myself.DisplayName)
+
+	if profile.BoardId, err = askBoard(p, cl); err != nil {
+		return err
+	}
+
+	starter := &starterConfig{
+		DefaultProfile: *name,
+		Profiles:       map[string]*starterProfile{*name: profile},
+		Percentiles:    defaultPercentiles,
+		SLAPolicies:    starterSLAs,
+	}
+
+	var fields []fieldDetail
+	if err := doJSON(cl, http.MethodGet, api+"field", nil, &fields); err != nil {
+		return fmt.Errorf("discovering fields: %v", err)
+	}
+	discoverFields(starter, fields)
+
+	var statuses []statusDetail
+	if err := doJSON(cl, http.MethodGet, api+"status", nil, &statuses); err != nil {
+		return fmt.Errorf("discovering statuses: %v", err)
+	}
+	if onBoard, err := boardStatusIds(cl, profile.BoardId); err == nil && len(onBoard) > 0 {
+		statuses = statusesIn(statuses, onBoard)
+	} else if err != nil {
+		fmt.Printf("Board columns unavailable (%v); mapping all statuses.\n", err)
+	}
+	mapStatuses(starter, statuses)
+	printStarter(starter)
+
+	if password != "" {
+		save, err := p.confirm("Save the password in "+filename+"?", true)
+		if err != nil {
+			return err
+		}
+		if save {
+			profile.Password = password
+		} else {
+			fmt.Println("Set JIRA_PASSWORD before running reports.")
+		}
+	}
+
+	b, err := json.MarshalIndent(starter, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(filename); dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+	}
+	// Readable only by its owner, as it may hold the password:
+	if err := writeFileAtomic(filename, append(b, '\n'), 0600); err != nil {
+		return err
+	}
+	fmt.Printf("\nWrote %s. Next, `%s config validate` checks it against the instance.\n", filename, programName())
+	return nil
+}
+
+// askBoard lists the boards visible to the user, narrowed by name or project
+// while there are too many to list, and asks for one:
+func askBoard(p *prompter, cl *http.Client) (int, error) {
+	boards, err := fetchBoards(cl, "", "", "")
+	if err != nil {
+		return 0, fmt.Errorf("listing boards: %v", err)
+	}
+	if len(boards) == 0 {
+		return 0, fmt.Errorf("no boards visible to this user")
+	}
+
+	matches := boards
+	for len(matches) > initBoardList {
+		filter, err := p.ask(fmt.Sprintf("%d boards; part of a board name or project key (blank lists all)", len(matches)), "")
+		if err != nil {
+			return 0, err
+		}
+		if filter == "" {
+			break
+		}
+		matches = nil
+		for _, b := range boards {
+			if strings.Contains(strings.ToLower(b.Name), strings.ToLower(filter)) || strings.EqualFold(b.Location.ProjectKey, filter) {
+				matches = append(matches, b)
+			}
+		}
+		if len(matches) == 0 {
+			fmt.Printf("No boards match %q.\n", filter)
+			matches = boards
+		}
+	}
+
+	fmt.Printf("%-8s %-8s %-40s %s\n", "id", "type", "name", "project")
+	for _, b := range matches {
+		fmt.Printf("%-8d %-8s %s %s\n", b.Id, b.Type, padRight(b.Name, 40), b.Location.ProjectKey)
+	}
+	for {
+		answer, err := p.ask("Board ID", strconv.Itoa(matches[0].Id))
+		if err != nil {
+			return 0, err
+		}
+		if id, err := strconv.Atoi(answer); err == nil && id > 0 {
+			return id, nil
+		}
+		fmt.Printf("%q is not a board ID.\n", answer)
+	}
+}
+
+// discoverFields sets the custom field IDs reports read by their usual names:
+func discoverFields(starter *starterConfig, fields []fieldDetail) {
+	byName := make(map[string]string)
+	for _, f := range fields {
+		if _, ok := byName[strings.ToLower(f.Name)]; !ok {
+			byName[strings.ToLower(f.Name)] = f.Id
+		}
+	}
+	find := func(names ...string) string {
+		for _, name := range names {
+			if id, ok := byName[strings.ToLower(name)]; ok {
+				return id
+			}
+		}
+		return ""
+	}
+
+	starter.StoryPointsFieldId = find("Story Points", "Story point estimate")
+	starter.SeverityField = find("Severity")
+	starter.ParentLinkField = find("Parent Link")
+	starter.CostOfDelayField = find("Cost of Delay")
+	// Cloud's Rank is the default:
+	if rank := find("Rank"); rank != "customfield_10019" {
+		starter.RankField = rank
+	}
+	if flagged := find("Flagged"); flagged != "" {
+		starter.Badges = &starterBadges{FlaggedField: flagged}
+	}
+}
+
+// boardStatusIds lists the IDs of the statuses mapped to the board's columns:
+func boardStatusIds(cl *http.Client, boardId int) (map[string]bool, error) {
+	var configuration struct {
+		ColumnConfig struct {
+			Columns []struct {
+				Statuses []struct {
+					Id string `json:"id"`
+				} `json:"statuses"`
+			} `json:"columns"`
+		} `json:"columnConfig"`
+	}
+	url := fmt.Sprintf("%s/rest/agile/1.0/board/%d/configuration", os.Getenv("JIRA_URL"), boardId)
+	if err := doJSON(cl, http.MethodGet, url, nil, &configuration); err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool)
+	for _, column := range configuration.ColumnConfig.Columns {
+		for _, s := range column.Statuses {
+			ids[s.Id] = true
+		}
+	}
+	return ids, nil
+}
+
+func statusesIn(statuses []statusDetail, ids map[string]bool) []statusDetail {
+	var in []statusDetail
+	for _, s := range statuses {
+		if ids[s.Id] {
+			in = append(in, s)
+		}
+	}
+	return in
+}
+
+// mapStatuses guesses the status settings from status names and categories,
+// leaving a setting at its default where no status fits:
+func mapStatuses(starter *starterConfig, statuses []statusDetail) {
+	contains := func(name string, words ...string) bool {
+		name = strings.ToLower(name)
+		for _, word := range words {
+			if strings.Contains(name, word) {
+				return true
+			}
+		}
+		return false
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return strings.ToLower(statuses[i].Name) < strings.ToLower(statuses[j].Name) })
+	seen := make(map[string]bool)
+	inProgress := false
+	var blocked []string
+	for _, s := range statuses {
+		if seen[strings.ToLower(s.Name)] {
+			continue
+		}
+		seen[strings.ToLower(s.Name)] = true
+		if strings.EqualFold(s.Name, "In Progress") {
+			inProgress = true
+		}
+		if s.StatusCategory.Key != "indeterminate" {
+			continue
+		}
+
+		switch {
+		case contains(s.Name, "review"):
+			starter.ReviewStatuses = append(starter.ReviewStatuses, s.Name)
+		case contains(s.Name, "test", "qa", "verif"):
+			starter.QAStatuses = append(starter.QAStatuses, s.Name)
+		}
+		if contains(s.Name, "block", "imped", "hold") {
+			blocked = append(blocked, s.Name)
+		}
+
+		if starter.StatusClasses == nil {
+			starter.StatusClasses = make(map[string]string)
+		}
+		if contains(s.Name, "ready", "wait", "queue", "pending", "block", "imped", "hold") {
+			starter.StatusClasses[s.Name] = "queue"
+		} else {
+			starter.StatusClasses[s.Name] = "work"
+		}
+	}
+
+	// Without an "In Progress" status work starts on leaving To Do,
+	// whatever the workflow calls it:
+	if !inProgress {
+		starter.WorkStarted = "category"
+	}
+	if len(blocked) > 0 {
+		if starter.Badges == nil {
+			starter.Badges = &starterBadges{}
+		}
+		starter.Badges.BlockedStatuses = blocked
+	}
+}
+
+// printStarter shows what init found before it's written:
+func printStarter(starter *starterConfig) {
+	fmt.Println("\nStarter settings:")
+	show := func(setting string, value string, unset string) {
+		if value == "" {
+			value = unset
+		}
+		fmt.Printf("  %s %s\n", padRight(setting, 24), value)
+	}
+	show("storyPointsFieldId", starter.StoryPointsFieldId, "(not found)")
+	show("severityField", starter.SeverityField, "(not found)")
+	show("rankField", starter.RankField, "(default)")
+	show("parentLinkField", starter.ParentLinkField, "(not found)")
+	show("costOfDelayField", starter.CostOfDelayField, "(not found)")
+	show("workStarted", starter.WorkStarted, "(default)")
+	show("reviewStatuses", strings.Join(starter.ReviewStatuses, ", "), "(default)")
+	show("qaStatuses", strings.Join(starter.QAStatuses, ", "), "(default)")
+	if starter.Badges != nil {
+		show("badges.blockedStatuses", strings.Join(starter.Badges.BlockedStatuses, ", "), "(none)")
+		show("badges.flaggedField", starter.Badges.FlaggedField, "(not found)")
+	}
+	var queues []string
+	for name, class := range starter.StatusClasses {
+		if class == "queue" {
+			queues = append(queues, name)
+		}
+	}
+	sort.Strings(queues)
+	show("statusClasses (queues)", strings.Join(queues, ", "), "(none)")
+	fmt.Println()
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunInit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/2/myself":
+			if user, password, _ := r.BasicAuth(); user != "ann@example.com" || password != "secret" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"displayName": "Ann"}`))
+		case "/rest/agile/1.0/board":
+			w.Write([]byte(`{"isLast": true, "values": [{"id": 7, "name": "Team board", "type": "kanban", "location": {"projectKey": "ABC"}}]}`))
+		case "/rest/agile/1.0/board/7/configuration":
+			w.Write([]byte(`{"columnConfig": {"columns": [{"statuses": [{"id": "1"}, {"id": "2"}, {"id": "3"}, {"id": "4"}, {"id": "6"}]}]}}`))
+		case "/rest/api/2/field":
+			w.Write([]byte(`[{"id": "customfield_10016", "name": "Story Points"}, {"id": "customfield_10021", "name": "Flagged"}, {"id": "customfield_10019", "name": "Rank"}]`))
+		case "/rest/api/2/status":
+			w.Write([]byte(`[
+				{"id": "1", "name": "To Do", "statusCategory": {"key": "new"}},
+				{"id": "2", "name": "Doing", "statusCategory": {"key": "indeterminate"}},
+				{"id": "3", "name": "Ready for Review", "statusCategory": {"key": "indeterminate"}},
+				{"id": "4", "name": "Blocked", "statusCategory": {"key": "indeterminate"}},
+				{"id": "5", "name": "In Testing", "statusCategory": {"key": "indeterminate"}},
+				{"id": "6", "name": "Done", "statusCategory": {"key": "done"}}
+			]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	for _, key := range []string{"JIRA_URL", "JIRA_USERNAME", "JIRA_PASSWORD"} {
+		defer os.Setenv(key, os.Getenv(key))
+	}
+	os.Unsetenv("JIRA_PASSWORD")
+
+	initInput = strings.NewReader(srv.URL + "/\nann@example.com\nsecret\n\nn\n")
+	defer func() { initInput = os.Stdin }()
+
+	out, err := captureOutput(func() error { return runInit([]string{"-o", "jira-analysis.json"}) })
+	if err != nil {
+		t.Fatalf("init: %v\n%s", err, out)
+	}
+	if !strings.Contains(out, "Signed in as Ann.") || !strings.Contains(out, "Wrote jira-analysis.json.") {
+		t.Fatalf("expected sign-in and write notes, got:\n%s", out)
+	}
+
+	os.Setenv("JIRA_CONFIG", "jira-analysis.json")
+	defer os.Unsetenv("JIRA_CONFIG")
+	written, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loading the written config: %v", err)
+	}
+	profile, err := written.Profile("")
+	if err != nil || profile == nil || profile.URL != srv.URL || profile.BoardId != 7 || profile.Password != "" {
+		t.Fatalf("expected profile for board 7 without the password, got %+v, %v", profile, err)
+	}
+	if written.StoryPointsFieldId != "customfield_10016" || written.RankField != "" || written.Badges.FlaggedField != "customfield_10021" {
+		t.Errorf("expected story points and flagged fields found, Cloud rank left default; got %q, %q, %q",
+			written.StoryPointsFieldId, written.RankField, written.Badges.FlaggedField)
+	}
+	// In Testing isn't on the board; Doing means work starts by category:
+	if written.WorkStarted != "category" || len(written.QAStatuses) != 0 ||
+		strings.Join(written.ReviewStatuses, ",") != "Ready for Review" ||
+		strings.Join(written.Badges.BlockedStatuses, ",") != "Blocked" {
+		t.Errorf("unexpected status mappings: %+v", written)
+	}
+	if written.StatusClasses["Doing"] != "work" || written.StatusClasses["Ready for Review"] != "queue" {
+		t.Errorf("unexpected status classes: %v", written.StatusClasses)
+	}
+	if len(written.SLAPolicies) != len(starterSLAs) {
+		t.Errorf("expected starter SLA policies, got %+v", written.SLAPolicies)
+	}
+
+	if _, err := captureOutput(func() error { return runInit(nil) }); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected refusal to replace the config, got %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import "time"
+
+// statusInterval is a span of time an issue spent in one status; End is zero
+// while the issue is still in that status.
+type statusInterval struct {
+	Status string
+	Start  time.Time
+	End    time.Time
+}
+
+func (in statusInterval) Duration(now time.Time) time.Duration {
+	end := in.End
+	if end.IsZero() {
+		end = now
+	}
+	return end.Sub(in.Start)
+}
+
+// statusIntervals walks the changelog into consecutive status intervals,
+// starting from creation in the first transition's "from" status.
+func (issue *Issue) statusIntervals() []statusInterval {
+	var intervals []statusInterval
+	for _, ev := range issue.StatusTransitions() {
+		if len(intervals) == 0 {
+			intervals = append(intervals, statusInterval{
+				Status: ev.FromString,
+				Start:  issue.Fields.Created.Time,
+			})
+		}
+		intervals[len(intervals)-1].End = ev.Time
+		intervals = append(intervals, statusInterval{Status: ev.ToString, Start: ev.Time})
+	}
+	return intervals
+}
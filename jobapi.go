@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	jobQueued    = "queued"
+	jobRunning   = "running"
+	jobSucceeded = "succeeded"
+	jobFailed    = "failed"
+	jobCanceled  = "canceled"
+)
+
+// jobOutputLimit is how much of a job's latest output is kept, and
+// keptJobs how many finished jobs are remembered:
+const (
+	jobOutputLimit = 64 << 10
+	keptJobs       = 100
+)
+
+// serverJob is a report run in the background for a REST API client, such as
+// an org-wide backfill that would otherwise hold a request open for minutes.
+type serverJob struct {
+	Id       string     `json:"id"`
+	Job      string     `json:"job,omitempty"`
+	Args     []string   `json:"args"`
+	Status   string     `json:"status"`
+	Created  time.Time  `json:"created"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+	// Progress is the report's latest progress, e.g. "backfill: 3/12 months":
+	Progress string `json:"progress,omitempty"`
+	Error    string `json:"error,omitempty"`
+	// Output is the tail of what the report printed:
+	Output string `json:"output,omitempty"`
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	output bytes.Buffer
+}
+
+// jobQueue runs server jobs one at a time, in the order submitted, so
+// concurrent backfills don't compete for the API's rate limit.
+type jobQueue struct {
+	mu      sync.Mutex
+	jobs    map[string]*serverJob
+	order   []string
+	pending chan *serverJob
+}
+
+func newJobQueue() *jobQueue {
+	q := &jobQueue{jobs: make(map[string]*serverJob), pending: make(chan *serverJob, keptJobs)}
+	go q.work()
+	return q
+}
+
+// startJobProcess runs a job's command line in a fresh process, like `run`,
+// with its progress written as lines; killed when ctx is canceled.
+var startJobProcess = func(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, exe, args...)
+	cmd.Env = append(os.Environ(), "JIRA_PROGRESS=lines")
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+	return cmd.Run()
+}
+
+func newJobId() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// submit queues a job's command line, returning it queued:
+func (q *jobQueue) submit(name string, args []string) (*serverJob, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job := &serverJob{Id: newJobId(), Job: name, Args: args, Status: jobQueued, Created: time.Now()}
+	job.ctx, job.cancel = context.WithCancel(context.Background())
+	select {
+	case q.pending <- job:
+	default:
+		return nil, fmt.Errorf("%s already queued; try again later", plural(cap(q.pending), "job"))
+	}
+	q.jobs[job.Id] = job
+	q.order = append(q.order, job.Id)
+	q.forget()
+	return job, nil
+}
+
+// forget drops the oldest finished jobs beyond those kept:
+func (q *jobQueue) forget() {
+	for i := 0; len(q.order) > keptJobs && i < len(q.order); {
+		job := q.jobs[q.order[i]]
+		if job.Status == jobQueued || job.Status == jobRunning {
+			i++
+			continue
+		}
+		delete(q.jobs, job.Id)
+		q.order = append(q.order[:i], q.order[i+1:]...)
+	}
+}
+
+func (q *jobQueue) work() {
+	for job := range q.pending {
+		q.mu.Lock()
+		if job.Status != jobQueued {
+			q.mu.Unlock()
+			continue
+		}
+		started := time.Now()
+		job.Status, job.Started = jobRunning, &started
+		q.mu.Unlock()
+
+		err := startJobProcess(job.ctx, job.Args, jobWriter{q, job, false}, jobWriter{q, job, true})
+
+		q.mu.Lock()
+		finished := time.Now()
+		job.Finished = &finished
+		switch {
+		case job.ctx.Err() != nil:
+			job.Status = jobCanceled
+		case err != nil:
+			job.Status, job.Error = jobFailed, err.Error()
+		default:
+			job.Status = jobSucceeded
+		}
+		job.cancel()
+		log.Printf("serve: job %s %s\n", job.Id, job.Status)
+		q.mu.Unlock()
+	}
+}
+
+// jobWriter collects a job's output, taking its stderr's progress lines as
+// its progress:
+type jobWriter struct {
+	q      *jobQueue
+	job    *serverJob
+	stderr bool
+}
+
+func (w jobWriter) Write(b []byte) (int, error) {
+	w.q.mu.Lock()
+	defer w.q.mu.Unlock()
+	job := w.job
+	for _, line := range strings.Split(string(b), "\n") {
+		if w.stderr && strings.HasPrefix(line, "progress: ") {
+			job.Progress = strings.TrimPrefix(line, "progress: ")
+		} else if line != "" {
+			job.output.WriteString(line + "\n")
+		}
+	}
+	if over := job.output.Len() - jobOutputLimit; over > 0 {
+		job.output.Next(over)
+	}
+	return len(b), nil
+}
+
+// snapshot copies a job for encoding:
+func (q *jobQueue) snapshot(job *serverJob) serverJob {
+	return serverJob{
+		Id:       job.Id,
+		Job:      job.Job,
+		Args:     job.Args,
+		Status:   job.Status,
+		Created:  job.Created,
+		Started:  job.Started,
+		Finished: job.Finished,
+		Progress: job.Progress,
+		Error:    job.Error,
+		Output:   job.output.String(),
+	}
+}
+
+func (q *jobQueue) get(id string) (serverJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return serverJob{}, false
+	}
+	return q.snapshot(job), true
+}
+
+// cancel stops a queued or running job:
+func (q *jobQueue) cancel(id string) (serverJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return serverJob{}, false
+	}
+	if job.Status == jobQueued {
+		finished := time.Now()
+		job.Status, job.Finished = jobCanceled, &finished
+	}
+	job.cancel()
+	return q.snapshot(job), true
+}
+
+// list is every job kept, newest first, without their output:
+func (q *jobQueue) list() []serverJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]serverJob, 0, len(q.order))
+	for i := len(q.order) - 1; i >= 0; i-- {
+		job := q.snapshot(q.jobs[q.order[i]])
+		job.Output = ""
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// jobRequest names a configured job. Clients can't give a command line,
+// arguments or outputs of their own, which could write files, post to any
+// URL or write to JIRA with the service account's credentials.
+type jobRequest struct {
+	Name string `json:"job"`
+}
+
+// unservedCommands can't run as server jobs: they serve, prompt or run other
+// jobs themselves.
+var unservedCommands = map[string]bool{"serve": true, "run": true, "help": true, "init": true}
+
+func (r *jobRequest) commandLine() (string, []string, error) {
+	job, ok := config.Jobs[r.Name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown job '%s'; only jobs in the config can be run", r.Name)
+	}
+	if cmd := findCommand(job.Command); cmd == nil || unservedCommands[cmd.Name] {
+		return "", nil, fmt.Errorf("command '%s' can't run as a job", job.Command)
+	}
+	return r.Name, job.commandLine(), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// serveJobs submits jobs with POST /jobs and lists them with GET; a job's
+// status and progress is at /jobs/{id}, which DELETE cancels. Only clients
+// with a token can use it.
+func serveJobs(q *jobQueue) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !authOf(r).Token {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="jira-analysis"`)
+			http.Error(w, "jobs need a token from server.tokens", http.StatusUnauthorized)
+			return
+		}
+		id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/jobs"), "/")
+		switch {
+		case id == "" && r.Method == http.MethodGet:
+			writeJSON(w, http.StatusOK, q.list())
+
+		case id == "" && r.Method == http.MethodPost:
+			var req jobRequest
+			decoder := json.NewDecoder(r.Body)
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(&req); err != nil {
+				http.Error(w, "expected a configured job's name: {\"job\": name}", http.StatusBadRequest)
+				return
+			}
+			serveMu.Lock()
+			name, args, err := req.commandLine()
+			serveMu.Unlock()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			job, err := q.submit(name, args)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+			log.Printf("serve: job %s queued by %s: %s\n", job.Id, requestIdentity(r), strings.Join(args, " "))
+			w.Header().Set("Location", "/jobs/"+job.Id)
+			snapshot, _ := q.get(job.Id)
+			writeJSON(w, http.StatusAccepted, snapshot)
+
+		case id != "" && r.Method == http.MethodGet:
+			job, ok := q.get(id)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			writeJSON(w, http.StatusOK, job)
+
+		case id != "" && r.Method == http.MethodDelete:
+			job, ok := q.cancel(id)
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			writeJSON(w, http.StatusOK, job)
+
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
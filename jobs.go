@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Job is a named report run by `run`: a command and its arguments, with the
+// profile, board, filters and outputs to run it with.
+type Job struct {
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+	Profile string   `json:"profile"`
+	// Board is passed as the command's trailing board ID argument:
+	Board     int    `json:"board"`
+	Team      string `json:"team"`
+	Tags      string `json:"tags"`
+	Verbosity string `json:"verbosity"`
+	// Outputs are -output destinations, e.g. "slack:<webhook url>" or
+	// "file:reports/aging.md"; default the terminal.
+	Outputs []string `json:"outputs"`
+}
+
+// commandLine is the full command line running the job:
+func (job *Job) commandLine() []string {
+	var args []string
+	setFlag := func(name, value string) {
+		if value != "" {
+			args = append(args, "-"+name, value)
+		}
+	}
+	setFlag("profile", job.Profile)
+	setFlag("team", job.Team)
+	setFlag("tag", job.Tags)
+	setFlag("verbosity", job.Verbosity)
+	for _, output := range job.Outputs {
+		setFlag("output", output)
+	}
+
+	args = append(args, job.Command)
+	args = append(args, job.Args...)
+	if job.Board != 0 {
+		args = append(args, strconv.Itoa(job.Board))
+	}
+	return args
+}
+
+func (c *Config) JobNames() []string {
+	names := make([]string, 0, len(c.Jobs))
+	for name := range c.Jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// runJobCommand runs a job's command line in a fresh process, so each job gets
+// its own profile environment and flags:
+var runJobCommand = func(args []string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func runJobs(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	list := fs.Bool("list", false, "list the configured jobs instead of running them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(config.Jobs) == 0 {
+		return fmt.Errorf("no jobs configured")
+	}
+
+	names := fs.Args()
+	if len(names) == 0 {
+		names = config.JobNames()
+	}
+	for _, name := range names {
+		if _, ok := config.Jobs[name]; !ok {
+			return fmt.Errorf("unknown job '%s'", name)
+		}
+	}
+	if *list {
+		for _, name := range names {
+			fmt.Printf("%-20s %s\n", name, strings.Join(config.Jobs[name].commandLine(), " "))
+		}
+		return nil
+	}
+
+	// Every job runs even if an earlier one fails, so one broken job doesn't
+	// hold up the rest of the schedule:
+	var failed []string
+	for _, name := range names {
+		job := config.Jobs[name]
+		err := fmt.Errorf("unknown command '%s'", job.Command)
+		if cmd := findCommand(job.Command); cmd != nil && cmd.Name != "run" {
+			log.Printf("run: %s\n", name)
+			err = runJobCommand(job.commandLine())
+		}
+		if err != nil {
+			log.Printf("run: %s: %v\n", name, err)
+			failed = append(failed, name)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%s failed: %s", plural(len(failed), "job"), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func completeJobs(args []string) []string {
+	return config.JobNames()
+}
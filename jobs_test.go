@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestJobCommandLine(t *testing.T) {
+	job := &Job{
+		Command: "sprint",
+		Args:    []string{"health", "-weeks", "8"},
+		Profile: "work",
+		Board:   42,
+		Team:    "platform",
+		Outputs: []string{"slack:https://hooks.example.com/x", "file:health.md"},
+	}
+	want := []string{"-profile", "work", "-team", "platform", "-output", "slack:https://hooks.example.com/x", "-output", "file:health.md", "sprint", "health", "-weeks", "8", "42"}
+	if got := job.commandLine(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestRunJobs(t *testing.T) {
+	config = &Config{Jobs: map[string]*Job{
+		"aging":  {Command: "aging", Board: 1},
+		"broken": {Command: "aging", Board: 2},
+		"typo":   {Command: "agin"},
+	}}
+	defer func() { config = &Config{} }()
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	var ran [][]string
+	saved := runJobCommand
+	runJobCommand = func(args []string) error {
+		ran = append(ran, args)
+		if args[len(args)-1] == "2" {
+			return fmt.Errorf("exit status 1")
+		}
+		return nil
+	}
+	defer func() { runJobCommand = saved }()
+
+	err := runJobs(nil)
+	if err == nil || err.Error() != "2 jobs failed: broken, typo" {
+		t.Fatalf("expected broken and typo to fail, got %v", err)
+	}
+	if len(ran) != 2 {
+		t.Fatalf("expected both valid jobs run despite the failure, got %q", ran)
+	}
+
+	ran = nil
+	if err = runJobs([]string{"aging"}); err != nil || len(ran) != 1 {
+		t.Fatalf("expected just the named job run, got %v %q", err, ran)
+	}
+	if err = runJobs([]string{"nope"}); err == nil {
+		t.Fatalf("expected an unknown job to fail before running anything")
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// jqlDateLayout is the date format JQL accepts in comparisons:
+const jqlDateLayout = "2006-01-02"
+
+// jqlVars are the variables available to JQL templates, e.g.
+// `sprint = {{.Sprint}} AND resolved >= {{.DaysAgo 90}}`.
+type jqlVars struct {
+	cl      *http.Client
+	now     time.Time
+	BoardId int
+	Today   string
+}
+
+func (v *jqlVars) DaysAgo(days int) string {
+	return v.now.AddDate(0, 0, -days).Format(jqlDateLayout)
+}
+
+func (v *jqlVars) WeeksAgo(weeks int) string {
+	return v.DaysAgo(7 * weeks)
+}
+
+// StartOfMonth is the first day of the current month:
+func (v *jqlVars) StartOfMonth() string {
+	y, m, _ := v.now.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, v.now.Location()).Format(jqlDateLayout)
+}
+
+// Sprint is the ID of the board's active sprint:
+func (v *jqlVars) Sprint() (string, error) {
+	sprint, err := activeSprint(v.cl, v.BoardId)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Itoa(sprint.Id), nil
+}
+
+// SprintName is the quoted name of the board's active sprint:
+func (v *jqlVars) SprintName() (string, error) {
+	sprint, err := activeSprint(v.cl, v.BoardId)
+	if err != nil {
+		return "", err
+	}
+	return strconv.Quote(sprint.Name), nil
+}
+
+// expandJQL expands template variables in a JQL filter; plain JQL passes
+// through untouched.
+func expandJQL(cl *http.Client, boardId int, jql string, now time.Time) (string, error) {
+	if !strings.Contains(jql, "{{") {
+		return jql, nil
+	}
+
+	t, err := template.New("jql").Parse(jql)
+	if err != nil {
+		return "", err
+	}
+
+	vars := &jqlVars{
+		cl:      cl,
+		now:     now,
+		BoardId: boardId,
+		Today:   now.Format(jqlDateLayout),
+	}
+
+	var sb strings.Builder
+	err = t.Execute(&sb, vars)
+	if err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
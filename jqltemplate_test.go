@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpandJQL_Dates(t *testing.T) {
+	now := time.Date(2018, 11, 6, 15, 0, 0, 0, cst)
+
+	jql, err := expandJQL(nil, 1, "resolved >= {{.DaysAgo 90}} AND updated <= {{.Today}} AND created >= {{.StartOfMonth}}", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "resolved >= 2018-08-08 AND updated <= 2018-11-06 AND created >= 2018-11-01"
+	if jql != expected {
+		t.Fatalf("expected '%s' for jql, got '%s'", expected, jql)
+	}
+}
+
+func TestExpandJQL_Plain(t *testing.T) {
+	jql, err := expandJQL(nil, 1, `status = "In Progress"`, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jql != `status = "In Progress"` {
+		t.Fatalf("expected jql unchanged, got '%s'", jql)
+	}
+}
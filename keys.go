@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// keyList is the -keys flag: issue keys separated by commas or whitespace,
+// or "-" to read them from stdin.
+type keyList []string
+
+func (l *keyList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *keyList) Set(value string) error {
+	if value == "-" {
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		value = string(b)
+	}
+	keys, err := parseIssueKeys(value)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, keys...)
+	return nil
+}
+
+var issueKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*-[0-9]+$`)
+
+// parseIssueKeys splits a list of issue keys, dropping duplicates:
+func parseIssueKeys(s string) ([]string, error) {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r' }) {
+		if !issueKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("'%s' is not an issue key", key)
+		}
+		key = strings.ToUpper(key)
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// issueKeys, when set, replaces the board and JQL filter with just these
+// issues:
+var issueKeys keyList
+
+// keysSource fetches each listed issue with its changelog from the JIRA API.
+type keysSource struct{}
+
+func (keysSource) FetchIssues(cl *http.Client, boardId int, resource string, jql string) ([]Issue, error) {
+	// An explicit list has no backlog to exclude:
+	if resource == "backlog" {
+		return nil, nil
+	}
+	if jql != "" {
+		log.Printf("fetching %s; JQL filter not applied: %s\n", plural(len(issueKeys), "listed issue"), jql)
+	}
+
+	issues := make([]Issue, 0, len(issueKeys))
+	for i, key := range issueKeys {
+		issue, err := fetchIssue(cl, key)
+		if isPermissionError(err) {
+			fetched.hideIssue(key)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		issues = append(issues, *issue)
+		progress.Update("issues", i+1, len(issueKeys), "issues", "")
+	}
+	resolveStatusCategories(cl, issues)
+	return issues, nil
+}
+
+func (keysSource) Describe(cl *http.Client) string {
+	return jiraSource{}.Describe(cl)
+}
+
+// fetchIssue fetches one issue with its complete changelog, paging through
+// the changelog resource when it is longer than the issue embeds.
+func fetchIssue(cl *http.Client, key string) (*Issue, error) {
+	url := setting(cl, "JIRA_URL") + "/rest/api/2/issue/" + key + "?expand=changelog"
+	body, err := cachedGet(fmt.Sprintf("issue.%s.json", key), url, cl)
+	if err != nil {
+		return nil, err
+	}
+	issue := &Issue{}
+	err = decodeResponse(body, url, issue)
+	body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	pageChangelog(cl, issue)
+	return issue, nil
+}
+
+type changelogPage struct {
+	StartAt int       `json:"startAt"`
+	Total   int       `json:"total"`
+	Values  []History `json:"values"`
+}
+
+func fetchChangelogPage(cl *http.Client, cacheFilename string, url string) (*changelogPage, error) {
+	body, err := cachedGet(cacheFilename, url, cl)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	page := &changelogPage{}
+	if err = decodeResponse(body, url, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
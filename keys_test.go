@@ -0,0 +1,80 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestParseIssueKeys(t *testing.T) {
+	keys, err := parseIssueKeys("abc-1, ABC-2\nABC-1\tdef-30\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 || keys[0] != "ABC-1" || keys[1] != "ABC-2" || keys[2] != "DEF-30" {
+		t.Fatalf("expected 3 keys without duplicates, got %q", keys)
+	}
+
+	if _, err := parseIssueKeys("ABC-1 summary"); err == nil {
+		t.Fatalf("expected an error for a word that isn't a key")
+	}
+}
+
+func TestKeysSource_FetchIssues(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/2/issue/ABC-1":
+			w.Write([]byte(`{"key": "ABC-1", "fields": {"summary": "Escalation"}, "changelog": {"startAt": 0, "maxResults": 1, "total": 2, "histories": [
+				{"created": "2018-11-01T09:00:00.000-0500", "items": [{"field": "status", "fromString": "Open", "toString": "In Progress"}]}
+			]}}`))
+		case "/rest/api/2/issue/ABC-1/changelog":
+			if r.URL.Query().Get("startAt") != "1" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(`{"startAt": 1, "total": 2, "values": [
+				{"created": "2018-11-05T09:00:00.000-0600", "items": [{"field": "status", "fromString": "In Progress", "toString": "Done"}]}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+	issueKeys = keyList{"ABC-1"}
+	defer func() { issueKeys = nil }()
+
+	if name := backendName(); name != "keys" {
+		t.Fatalf("expected the keys backend, got %s", name)
+	}
+	issues, err := keysSource{}.FetchIssues(srv.Client(), 0, "issue", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 || len(issues[0].Changelog.Histories) != 2 {
+		t.Fatalf("expected ABC-1 with its whole changelog, got %+v", issues)
+	}
+
+	// JIRA can't say whether a missing issue exists but is hidden, so it is
+	// left out and reported rather than failing the run:
+	fetched = fetchStats{}
+	defer func() { fetched = fetchStats{} }()
+	issueKeys = keyList{"ABC-1", "ABC-2"}
+	issues, err = keysSource{}.FetchIssues(srv.Client(), 0, "issue", "")
+	if err != nil || len(issues) != 1 {
+		t.Fatalf("expected ABC-2 left out, got %v, %v", issues, err)
+	}
+	if len(fetched.Inaccessible) != 1 || fetched.Inaccessible[0] != "ABC-2" {
+		t.Fatalf("expected ABC-2 recorded as inaccessible, got %q", fetched.Inaccessible)
+	}
+}
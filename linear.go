@@ -0,0 +1,328 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// linearSource reads a Linear team's issues. JIRA_URL is the team's URL
+// (https://linear.app/<workspace>/team/<KEY>) and JIRA_PASSWORD an API key;
+// workflow state history becomes transitions.
+type linearSource struct{}
+
+const linearEndpoint = "https://api.linear.app/graphql"
+
+const linearIssuesQuery = `query($team: String!, $cursor: String) {
+  issues(first: 50, after: $cursor, filter: {team: {key: {eq: $team}}}) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      id identifier title createdAt completedAt canceledAt priority priorityLabel
+      state { name type }
+      assignee { name displayName email }
+      parent { identifier }
+      team { key name }
+      history(first: 100) {
+        pageInfo { hasNextPage endCursor }
+        nodes { ...historyEntry }
+      }
+    }
+  }
+}
+` + linearHistoryFragment
+
+// linearHistoryQuery fetches the rest of an issue's history, when it has more
+// than fit in the issues query:
+const linearHistoryQuery = `query($id: String!, $cursor: String) {
+  issue(id: $id) {
+    history(first: 100, after: $cursor) {
+      pageInfo { hasNextPage endCursor }
+      nodes { ...historyEntry }
+    }
+  }
+}
+` + linearHistoryFragment
+
+const linearHistoryFragment = `fragment historyEntry on IssueHistory {
+  createdAt
+  actor { name displayName email }
+  fromState { name type }
+  toState { name type }
+  fromEstimate toEstimate
+}`
+
+type linearUser struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
+}
+
+func (u *linearUser) user() User {
+	return User{UserName: u.DisplayName, DisplayName: u.Name, EmailAddress: u.Email}
+}
+
+type linearState struct {
+	Name string `json:"name"`
+	// triage, backlog, unstarted, started, completed or canceled:
+	Type string `json:"type"`
+}
+
+// linearCategories maps Linear state types onto JIRA status categories:
+var linearCategories = map[string]string{
+	"triage":    "new",
+	"backlog":   "new",
+	"unstarted": "new",
+	"started":   "indeterminate",
+	"completed": "done",
+	"canceled":  "done",
+}
+
+type linearHistory struct {
+	CreatedAt    zonedTimestamp `json:"createdAt"`
+	Actor        *linearUser    `json:"actor"`
+	FromState    *linearState   `json:"fromState"`
+	ToState      *linearState   `json:"toState"`
+	FromEstimate *float64       `json:"fromEstimate"`
+	ToEstimate   *float64       `json:"toEstimate"`
+}
+
+// linearPageInfo is where a GraphQL connection continues from:
+type linearPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+type linearHistoryConnection struct {
+	PageInfo linearPageInfo  `json:"pageInfo"`
+	Nodes    []linearHistory `json:"nodes"`
+}
+
+type linearIssue struct {
+	Id            string         `json:"id"`
+	Identifier    string         `json:"identifier"`
+	Title         string         `json:"title"`
+	CreatedAt     zonedTimestamp `json:"createdAt"`
+	CompletedAt   zonedTimestamp `json:"completedAt"`
+	CanceledAt    zonedTimestamp `json:"canceledAt"`
+	Priority      int            `json:"priority"`
+	PriorityLabel string         `json:"priorityLabel"`
+	State         linearState    `json:"state"`
+	Assignee      *linearUser    `json:"assignee"`
+	Parent        *struct {
+		Identifier string `json:"identifier"`
+	} `json:"parent"`
+	Team struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"team"`
+	History linearHistoryConnection `json:"history"`
+}
+
+type linearIssuesPage struct {
+	Data struct {
+		Issues struct {
+			PageInfo linearPageInfo `json:"pageInfo"`
+			Nodes    []linearIssue  `json:"nodes"`
+		} `json:"issues"`
+	} `json:"data"`
+	Errors linearErrors `json:"errors"`
+}
+
+type linearHistoryPage struct {
+	Data struct {
+		Issue *struct {
+			History linearHistoryConnection `json:"history"`
+		} `json:"issue"`
+	} `json:"data"`
+	Errors linearErrors `json:"errors"`
+}
+
+type linearErrors []struct {
+	Message string `json:"message"`
+}
+
+// linearTeam takes the team key from JIRA_URL:
+func linearTeam(cl *http.Client) (string, error) {
+	parts := strings.Split(strings.Trim(setting(cl, "JIRA_URL"), "/"), "/")
+	for i := range parts[:len(parts)-1] {
+		if parts[i] == "team" {
+			return parts[i+1], nil
+		}
+	}
+	return "", fmt.Errorf("linear: JIRA_URL should be a team URL, e.g. https://linear.app/<workspace>/team/<KEY>")
+}
+
+func (linearSource) Describe(cl *http.Client) string {
+	return "Linear, GraphQL API"
+}
+
+func (linearSource) FetchIssues(cl *http.Client, boardId int, resource string, query string) ([]Issue, error) {
+	team, err := linearTeam(cl)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	cursor := ""
+	for {
+		page, err := linearIssues(cl, team, cursor)
+		if err != nil {
+			return nil, err
+		}
+		fetched.Pages++
+
+		for i := range page.Data.Issues.Nodes {
+			li := &page.Data.Issues.Nodes[i]
+			if err := linearRestOfHistory(cl, team, li); err != nil {
+				return nil, err
+			}
+			issue := linearIssueOf(li)
+			if resource == "backlog" && issue.Fields.Status.StatusCategory.Key != "new" {
+				continue
+			}
+			issues = append(issues, issue)
+		}
+
+		if !page.Data.Issues.PageInfo.HasNextPage {
+			break
+		}
+		cursor = page.Data.Issues.PageInfo.EndCursor
+	}
+	return issues, nil
+}
+
+func formatEstimate(estimate *float64) string {
+	if estimate == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*estimate, 'g', -1, 64)
+}
+
+// linearIssueOf maps a Linear issue and its history onto an issue:
+func linearIssueOf(li *linearIssue) Issue {
+	const prefix = "linear:"
+	issue := Issue{Key: li.Identifier}
+
+	f := &issue.Fields
+	f.Summary = li.Title
+	f.Status = externalStatus(prefix, li.State.Name, linearCategories[li.State.Type])
+	f.Created = li.CreatedAt
+	f.ResolutionDate = li.CompletedAt
+	if f.ResolutionDate.IsZero() {
+		f.ResolutionDate = li.CanceledAt
+	}
+	f.Project = Project{Key: li.Team.Key, Name: li.Team.Name, Style: externalStyle}
+	f.IssueType = IssueType{Name: "Issue"}
+	if li.Assignee != nil {
+		assignee := li.Assignee.user()
+		f.Assignee = &assignee
+	}
+	// Linear priorities run 1 (urgent) to 4 (low), with 0 for none:
+	if li.Priority != 0 {
+		f.Priority = &Priority{Id: strconv.Itoa(li.Priority), Name: li.PriorityLabel}
+	}
+	if li.Parent != nil {
+		f.Parent = &ParentIssue{Key: li.Parent.Identifier}
+	}
+
+	for _, h := range li.History.Nodes {
+		history := History{Created: h.CreatedAt}
+		if h.Actor != nil {
+			history.Author = h.Actor.user()
+		}
+
+		if h.ToState != nil {
+			registerStatusCategory(prefix+h.ToState.Name, linearCategories[h.ToState.Type])
+			from := ""
+			if h.FromState != nil {
+				from = h.FromState.Name
+				registerStatusCategory(prefix+from, linearCategories[h.FromState.Type])
+			}
+			history.Items = append(history.Items, statusChange(prefix, from, h.ToState.Name))
+		}
+		if h.FromEstimate != nil || h.ToEstimate != nil {
+			history.Items = append(history.Items, HistoryItem{
+				Field:      "Story Points",
+				FromString: formatEstimate(h.FromEstimate),
+				ToString:   formatEstimate(h.ToEstimate),
+			})
+		}
+
+		if len(history.Items) > 0 {
+			issue.Changelog.Histories = append(issue.Changelog.Histories, history)
+		}
+	}
+	issue.Changelog.Total = len(issue.Changelog.Histories)
+	return issue
+}
+
+func linearIssues(cl *http.Client, team string, cursor string) (*linearIssuesPage, error) {
+	variables := map[string]interface{}{"team": team}
+	if cursor != "" {
+		variables["cursor"] = cursor
+	}
+	page := &linearIssuesPage{}
+	if err := linearPost(cl, team, linearIssuesQuery, variables, page); err != nil {
+		return nil, err
+	}
+	if len(page.Errors) > 0 {
+		return nil, fmt.Errorf("linear: %s", page.Errors[0].Message)
+	}
+	return page, nil
+}
+
+// linearRestOfHistory follows an issue's history until hasNextPage is false,
+// so long-lived issues keep their earliest transitions:
+func linearRestOfHistory(cl *http.Client, team string, li *linearIssue) error {
+	history := &li.History
+	for history.PageInfo.HasNextPage {
+		page := &linearHistoryPage{}
+		variables := map[string]interface{}{"id": li.Id, "cursor": history.PageInfo.EndCursor}
+		if err := linearPost(cl, team, linearHistoryQuery, variables, page); err != nil {
+			return err
+		}
+		if len(page.Errors) > 0 {
+			return fmt.Errorf("linear: %s", page.Errors[0].Message)
+		}
+		if page.Data.Issue == nil {
+			return fmt.Errorf("linear: issue %s not found", li.Identifier)
+		}
+		fetched.Pages++
+
+		next := &page.Data.Issue.History
+		history.Nodes = append(history.Nodes, next.Nodes...)
+		history.PageInfo = next.PageInfo
+	}
+	return nil
+}
+
+// linearPost runs a GraphQL query for a team, cached by the query and its
+// variables:
+func linearPost(cl *http.Client, team string, query string, variables map[string]interface{}, v interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, linearEndpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Personal API keys are sent as is, without a Bearer prefix:
+	req.Header.Set("Authorization", setting(cl, "JIRA_PASSWORD"))
+
+	h := fnv.New32a()
+	h.Write(body)
+	rsp, err := cachedDo(fmt.Sprintf("linear.%s.%08x.json", team, h.Sum32()), req, cl)
+	if err != nil {
+		return err
+	}
+	defer rsp.Close()
+
+	return decodeResponse(rsp, req.URL.String(), v)
+}
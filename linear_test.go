@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinearIssueOf(t *testing.T) {
+	defer func() { statusCategories = nil }()
+
+	three, five := 3.0, 5.0
+	li := &linearIssue{
+		Identifier:  "ENG-42",
+		Title:       "Rate limit API",
+		CreatedAt:   zonedTimestamp{time.Date(2018, 10, 29, 9, 0, 0, 0, time.UTC)},
+		CompletedAt: zonedTimestamp{time.Date(2018, 11, 5, 9, 0, 0, 0, time.UTC)},
+		State:       linearState{Name: "Shipped", Type: "completed"},
+	}
+	li.History.Nodes = []linearHistory{
+		// Linear lists history newest first:
+		{CreatedAt: zonedTimestamp{time.Date(2018, 11, 5, 9, 0, 0, 0, time.UTC)}, FromState: &linearState{"Building", "started"}, ToState: &linearState{"Shipped", "completed"}},
+		{CreatedAt: zonedTimestamp{time.Date(2018, 11, 1, 9, 0, 0, 0, time.UTC)}, FromEstimate: &three, ToEstimate: &five},
+		{CreatedAt: zonedTimestamp{time.Date(2018, 10, 31, 9, 0, 0, 0, time.UTC)}, FromState: &linearState{"Todo", "unstarted"}, ToState: &linearState{"Building", "started"}},
+	}
+
+	issue := linearIssueOf(li)
+	if issue.Key != "ENG-42" || issue.Fields.Status.StatusCategory.Key != "done" {
+		t.Fatalf("expected ENG-42 done, got %s %+v", issue.Key, issue.Fields.Status)
+	}
+	if days, ok := issue.CycleTime(); !ok || days != 3 {
+		t.Fatalf("expected cycle time of 3 days from Building to Shipped, got %d %v", days, ok)
+	}
+	if changes := issue.reestimates(); len(changes) != 1 || changes[0].Delta != 2 {
+		t.Fatalf("expected one +2 estimate change, got %+v", changes)
+	}
+}
+
+// redirectTransport sends every request to a test server instead:
+type redirectTransport struct {
+	to *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.to.Scheme, t.to.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestLinearSource_FollowsHistoryPages(t *testing.T) {
+	const issues = `{"data": {"issues": {
+		"pageInfo": {"hasNextPage": false},
+		"nodes": [{
+			"id": "uuid-42", "identifier": "ENG-42", "title": "Rate limit API",
+			"createdAt": "2018-10-29T09:00:00Z", "completedAt": "2018-11-05T09:00:00Z",
+			"state": {"name": "Shipped", "type": "completed"},
+			"history": {"pageInfo": {"hasNextPage": true, "endCursor": "h1"}, "nodes": [
+				{"createdAt": "2018-11-05T09:00:00Z", "fromState": {"name": "Building", "type": "started"}, "toState": {"name": "Shipped", "type": "completed"}}
+			]}
+		}]
+	}}}`
+	const history = `{"data": {"issue": {"history": {
+		"pageInfo": {"hasNextPage": false, "endCursor": "h2"},
+		"nodes": [{"createdAt": "2018-10-31T09:00:00Z", "fromState": {"name": "Todo", "type": "unstarted"}, "toState": {"name": "Building", "type": "started"}}]
+	}}}}`
+
+	var cursors []interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if strings.Contains(body.Query, "issue(id:") {
+			if body.Variables["id"] != "uuid-42" {
+				http.NotFound(w, r)
+				return
+			}
+			cursors = append(cursors, body.Variables["cursor"])
+			w.Write([]byte(history))
+			return
+		}
+		w.Write([]byte(issues))
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", "https://linear.app/acme/team/ENG")
+	defer os.Unsetenv("JIRA_URL")
+	defer func() { statusCategories = nil }()
+
+	to, _ := url.Parse(srv.URL)
+	result, err := linearSource{}.FetchIssues(&http.Client{Transport: redirectTransport{to}}, 0, "issue", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cursors) != 1 || cursors[0] != "h1" {
+		t.Fatalf("expected the history followed from its end cursor once, got %v", cursors)
+	}
+	if len(result) != 1 || len(result[0].Changelog.Histories) != 2 {
+		t.Fatalf("expected both pages of history, got %+v", result)
+	}
+	if days, ok := result[0].CycleTime(); !ok || days != 3 {
+		t.Fatalf("expected cycle time of 3 days from Building to Shipped, got %d %v", days, ok)
+	}
+}
@@ -2,19 +2,20 @@ package main
 
 import (
 	"bytes"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/JamesDunne/jira-analysis/analysis"
 )
 
 func getEnvInt(key string, defaultValue int) int {
@@ -30,10 +31,50 @@ type zonedTimestamp struct {
 	time.Time
 }
 
+// timestampLayouts are the formats seen from JIRA Server, Cloud and plugins,
+// tried in order:
+var timestampLayouts = []string{
+	"2006-01-02T15:04:05.999-0700",
+	"2006-01-02T15:04:05.999Z07:00",
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05.999",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	// CSV exports:
+	"02/Jan/06 3:04 PM",
+	// XML exports:
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+}
+
+func parseTimestamp(s string) (time.Time, error) {
+	var err error
+	for _, layout := range timestampLayouts {
+		var t time.Time
+		t, err = time.Parse(layout, s)
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	// Epoch milliseconds:
+	if ms, msErr := strconv.ParseInt(s, 10, 64); msErr == nil {
+		return time.Unix(0, ms*int64(time.Millisecond)), nil
+	}
+	return time.Time{}, err
+}
+
 func (t *zonedTimestamp) UnmarshalJSON(buf []byte) error {
-	tt, err := time.Parse("2006-01-02T15:04:05.999-0700", strings.Trim(string(buf), `"`))
+	// Unset timestamps (e.g. resolutiondate of open issues) stay zero:
+	if string(buf) == "null" {
+		return nil
+	}
+
+	tt, err := parseTimestamp(strings.Trim(string(buf), `"`))
 	if err != nil {
-		return err
+		// Don't fail the whole page over one odd timestamp:
+		log.Printf("warning: unrecognized timestamp %s; treating as unset\n", buf)
+		return nil
 	}
 	t.Time = tt
 	return nil
@@ -41,9 +82,12 @@ func (t *zonedTimestamp) UnmarshalJSON(buf []byte) error {
 
 type User struct {
 	UserName     string `json:"name"`
+	AccountId    string `json:"accountId"`
 	EmailAddress string `json:"emailAddress"`
 	DisplayName  string `json:"displayName"`
 	TimeZone     string `json:"timeZone"`
+	// "atlassian", "app" (bots) or "customer" on Cloud:
+	AccountType string `json:"accountType"`
 }
 
 type HistoryItem struct {
@@ -68,18 +112,168 @@ type PagedChangelog struct {
 	Histories  []History `json:"histories"`
 }
 
-//type IssueStatus struct {
-//	Name string `json:"name"`
-//}
+type StatusCategory struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type IssueStatus struct {
+	Name           string         `json:"name"`
+	StatusCategory StatusCategory `json:"statusCategory"`
+}
+
+type Project struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	// "classic" or "next-gen" (team-managed):
+	Style      string `json:"style"`
+	Simplified bool   `json:"simplified"`
+}
+
+type IssueType struct {
+	Name           string `json:"name"`
+	Subtask        bool   `json:"subtask"`
+	HierarchyLevel int    `json:"hierarchyLevel"`
+}
+
+type ParentIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary   string      `json:"summary"`
+		Status    IssueStatus `json:"status"`
+		IssueType IssueType   `json:"issuetype"`
+	} `json:"fields"`
+}
+
+type EpicRef struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+type Priority struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Version is a project release, as bugs name the versions they affect:
+type Version struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Released    bool   `json:"released"`
+	ReleaseDate string `json:"releaseDate"`
+}
+
+type Resolution struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type IssueLinkType struct {
+	Name    string `json:"name"`
+	Inward  string `json:"inward"`
+	Outward string `json:"outward"`
+}
+
+type IssueLink struct {
+	Type         IssueLinkType `json:"type"`
+	InwardIssue  *ParentIssue  `json:"inwardIssue"`
+	OutwardIssue *ParentIssue  `json:"outwardIssue"`
+}
+
+type Comment struct {
+	Author  User           `json:"author"`
+	Created zonedTimestamp `json:"created"`
+}
+
+// CommentPage is the comments embedded in an issue, the first page of them:
+type CommentPage struct {
+	Comments []Comment `json:"comments"`
+	Total    int       `json:"total"`
+}
 
 type IssueFields struct {
-	Summary string `json:"summary"`
-	//Status   IssueStatus    `json:"status"`
+	Summary        string         `json:"summary"`
+	Status         IssueStatus    `json:"status"`
+	Created        zonedTimestamp `json:"created"`
+	ResolutionDate zonedTimestamp `json:"resolutiondate"`
+	Resolution     *Resolution    `json:"resolution"`
 	//Updated  zonedTimestamp `json:"updated"`
-	//Assignee User           `json:"assignee"`
+	Assignee *User `json:"assignee"`
+
+	Priority  *Priority      `json:"priority"`
+	Project   Project        `json:"project"`
+	Security  *SecurityLevel `json:"security"`
+	IssueType IssueType      `json:"issuetype"`
+	Parent    *ParentIssue   `json:"parent"`
+	// Epic link of classic projects, provided by the agile API:
+	Epic *EpicRef `json:"epic"`
+
+	IssueLinks []IssueLink  `json:"issuelinks"`
+	Labels     []string     `json:"labels"`
+	Comment    *CommentPage `json:"comment"`
+
+	// Affects versions and environment, as reported on bugs:
+	Versions    []Version `json:"versions"`
+	Environment string    `json:"environment"`
 
 	// NOTE: this custom field name might vary by deployment?
 	EpicName string `json:"customfield_12024"`
+
+	// Custom holds the raw customfield_* values for configurable lookups:
+	Custom map[string]json.RawMessage `json:"-"`
+}
+
+func (f *IssueFields) UnmarshalJSON(buf []byte) error {
+	type plainFields IssueFields
+	err := json.Unmarshal(buf, (*plainFields)(f))
+	if err != nil {
+		return err
+	}
+
+	var all map[string]json.RawMessage
+	err = json.Unmarshal(buf, &all)
+	if err != nil {
+		return err
+	}
+	for key, value := range all {
+		if !strings.HasPrefix(key, "customfield_") || string(value) == "null" {
+			continue
+		}
+		if f.Custom == nil {
+			f.Custom = make(map[string]json.RawMessage)
+		}
+		f.Custom[key] = value
+	}
+	return nil
+}
+
+// CustomString returns a custom field's display value, whether it is a plain
+// string or number or a select option ({"value": ...} or {"name": ...}).
+func (f *IssueFields) CustomString(id string) string {
+	raw, ok := f.Custom[id]
+	if !ok {
+		return ""
+	}
+
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var n json.Number
+	if json.Unmarshal(raw, &n) == nil {
+		return n.String()
+	}
+	var option struct {
+		Value string `json:"value"`
+		Name  string `json:"name"`
+	}
+	if json.Unmarshal(raw, &option) == nil {
+		if option.Value != "" {
+			return option.Value
+		}
+		return option.Name
+	}
+	return ""
 }
 
 type Issue struct {
@@ -89,10 +283,14 @@ type Issue struct {
 	Changelog PagedChangelog `json:"changelog"`
 
 	// computed:
+	Source             string
 	Status             string
 	StatusTime         time.Time
 	Assigned           User
 	StatusBusinessDays int
+
+	transitions       []TransitionEvent
+	statusTransitions []TransitionEvent
 }
 
 type PagedIssues struct {
@@ -111,7 +309,16 @@ func (issues IssueList) Len() int {
 // Less reports whether the element with
 // index i should sort before the element with index j.
 func (issues IssueList) Less(i, j int) bool {
-	return issues[i].StatusBusinessDays > issues[j].StatusBusinessDays
+	if issues[i].StatusBusinessDays != issues[j].StatusBusinessDays {
+		return issues[i].StatusBusinessDays > issues[j].StatusBusinessDays
+	}
+	return lessIssueKey(issues[i].Key, issues[j].Key)
+}
+
+// lessIssueKey orders issue keys by project then by number, so ABC-9 sorts
+// before ABC-10:
+func lessIssueKey(a, b string) bool {
+	return analysis.LessKey(a, b)
 }
 
 // Swap swaps the elements with indexes i and j.
@@ -119,8 +326,22 @@ func (issues IssueList) Swap(i, j int) {
 	issues[i], issues[j] = issues[j], issues[i]
 }
 
-func cachedGet(cacheFilename string, url string, cl *http.Client) (issuesJsonBody io.ReadCloser, err error) {
-	log.Printf("GET '%s'\n", url)
+func cachedGet(cacheFilename string, url string, cl *http.Client) (io.ReadCloser, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err = authenticate(cl, req); err != nil {
+		return nil, err
+	}
+
+	return cachedDo(cacheFilename, req, cl)
+}
+
+// cachedDo sends the request unless a fresh response is cached in
+// cacheFilename, falling back to a stale cached response on failure.
+func cachedDo(cacheFilename string, req *http.Request, cl *http.Client) (issuesJsonBody io.ReadCloser, err error) {
+	log.Printf("%s '%s'\n", req.Method, req.URL)
 
 	cacheHit := false
 	cacheAvailable := false
@@ -130,7 +351,7 @@ func cachedGet(cacheFilename string, url string, cl *http.Client) (issuesJsonBod
 		cacheHit = statErr == nil || !os.IsNotExist(statErr)
 		cacheAvailable = cacheHit
 		if cacheHit && stat != nil {
-			if stat.ModTime().Before(time.Now().Add(-time.Hour)) {
+			if stat.ModTime().Before(time.Now().Add(-cacheTTL)) {
 				cacheHit = false
 			}
 		}
@@ -141,12 +362,19 @@ func cachedGet(cacheFilename string, url string, cl *http.Client) (issuesJsonBod
 			return nil
 		}
 
-		b, err := ioutil.ReadFile(cacheFilename)
+		b, err := readCacheFile(cacheFilename)
 		if err != nil {
+			log.Printf("cache: %s: %v\n", cacheFilename, err)
 			return nil
 		}
 
 		log.Printf("cached response\n")
+		modTime := time.Now()
+		if stat, err := os.Stat(cacheFilename); err == nil {
+			modTime = stat.ModTime()
+		}
+		fetched.record(true, modTime)
+		recordSnapshot(cacheFilename, b)
 		issuesJsonBody = ioutil.NopCloser(bytes.NewReader(b))
 		return issuesJsonBody
 	}
@@ -160,40 +388,8 @@ func cachedGet(cacheFilename string, url string, cl *http.Client) (issuesJsonBod
 	}
 
 	if !cacheHit {
-		var req *http.Request
-		req, err = http.NewRequest(http.MethodGet, url, nil)
-		if err != nil {
-			log.Fatal(err)
-		}
-		req.SetBasicAuth(os.Getenv("JIRA_USERNAME"), os.Getenv("JIRA_PASSWORD"))
-
-		var rsp *http.Response
-		rsp, err = cl.Do(req)
-		if err != nil {
-			issuesJsonBody = respondCache()
-			if issuesJsonBody != nil {
-				return issuesJsonBody, nil
-			}
-
-			log.Printf("http: %v\n", err)
-			return nil, err
-		}
-		defer rsp.Body.Close()
-
-		if rsp.StatusCode >= 300 {
-			log.Printf("http: status %s\n", rsp.Status)
-
-			issuesJsonBody = respondCache()
-			if issuesJsonBody != nil {
-				return issuesJsonBody, nil
-			}
-
-			httpErr := fmt.Errorf("HTTP response %s", rsp.Status)
-			return nil, httpErr
-		}
-
 		var b []byte
-		b, err = ioutil.ReadAll(rsp.Body)
+		b, err = doAccounted(req, cl)
 		if err != nil {
 			issuesJsonBody = respondCache()
 			if issuesJsonBody != nil {
@@ -204,7 +400,14 @@ func cachedGet(cacheFilename string, url string, cl *http.Client) (issuesJsonBod
 		}
 
 		// cache response in file:
-		ioutil.WriteFile(cacheFilename, b, 0600)
+		if err := writeCacheFile(cacheFilename, b); err != nil {
+			log.Printf("cache: %s: %v\n", cacheFilename, err)
+		} else {
+			indexCacheFile(cacheFilename, req.URL.String(), len(b))
+		}
+		// Stamped with when it was fetched, not the time reports are as of:
+		fetched.record(false, wallNow())
+		recordSnapshot(cacheFilename, b)
 
 		issuesJsonBody = ioutil.NopCloser(bytes.NewReader(b))
 
@@ -214,80 +417,201 @@ func cachedGet(cacheFilename string, url string, cl *http.Client) (issuesJsonBod
 	return
 }
 
-func main() {
-	fmt.Println(`environment variables:
-JIRA_URL      = base URL of JIRA website without trailing slash
-JIRA_USERNAME = username to authenticate with
-JIRA_PASSWORD = password to authenticate with
-
-JIRA_BOARDID  = board ID to query status of
-JIRA_JQL      = custom JQL filter to apply; default='status not in (closed, canceled, open, reopened, Analysis, "Analysis - 1")'
-`)
-	args := os.Args[1:]
-
-	//boardId := 2924
-	//boardId := 3612
-	//boardId := 3581
-	//boardId := 4085
-	//boardId := 4454
-	boardId := getEnvInt("JIRA_BOARDID", 4454)
-
-	if len(args) >= 1 {
-		intValue, err := strconv.Atoi(args[0])
+// doAccounted sends an API request within the run's budget, counting it and
+// its response toward the API usage the footer reports, and reads the
+// response. Cached and uncached requests alike go through here.
+func doAccounted(req *http.Request, cl *http.Client) ([]byte, error) {
+	if err := fetched.checkBudget(apiBudget()); err != nil {
+		return nil, err
+	}
+	fetched.Calls++
+	began := time.Now()
+	defer func() { fetched.Elapsed += time.Since(began) }()
+
+	rsp, err := cl.Do(req)
+	if err != nil {
+		log.Printf("http: %v\n", err)
+		return nil, err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode >= 300 {
+		log.Printf("http: status %s\n", rsp.Status)
+		return nil, newAPIError(req, rsp)
+	}
+
+	b, err := ioutil.ReadAll(rsp.Body)
+	fetched.Bytes += int64(len(b))
+	return b, err
+}
+
+// reportNow is the time reports are computed as of: -as-of's, else now.
+func reportNow() time.Time {
+	if !asOf.IsZero() {
+		return asOf
+	}
+	return wallNow()
+}
+
+// wallNow is the time now; $JIRA_NOW (RFC 3339) pins it so runs against the
+// same cached data produce identical output.
+func wallNow() time.Time {
+	if env := os.Getenv("JIRA_NOW"); env != "" {
+		now, err := time.Parse(time.RFC3339, env)
 		if err == nil {
-			boardId = intValue
+			return now
 		}
+		log.Printf("JIRA_NOW: %v\n", err)
 	}
+	return time.Now()
+}
 
-	if os.Getenv("JIRA_URL") == "" {
-		os.Setenv("JIRA_URL", "https://ultidev")
-	}
+func defaultBoardId() int {
+	return getEnvInt("JIRA_BOARDID", 4454)
+}
 
+func defaultJQL() string {
 	jql := os.Getenv("JIRA_JQL")
+	if jql == "" && !asOf.IsZero() {
+		// The statuses issues were in then:
+		return fmt.Sprintf(`status was not in (closed, canceled, open, reopened, Analysis, "Analysis - 1") on "%s"`, asOf.Format("2006-01-02"))
+	}
 	if jql == "" {
 		jql = `status not in (closed, canceled, open, reopened, Analysis, "Analysis - 1")`
 	}
+	return jql
+}
 
-	cl := &http.Client{
-		// Disable TLS cert verification:
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
+// fetchBoardIssues pages through all issues on the board matching the JQL filter:
+func fetchBoardIssues(cl *http.Client, boardId int, jql string) ([]Issue, error) {
+	return fetchPagedIssues(cl, boardId, "issue", jql)
+}
+
+// fetchPagedIssues pages through a board issue list resource ("issue" or
+// "backlog"):
+func fetchPagedIssues(cl *http.Client, boardId int, resource string, jql string) ([]Issue, error) {
+	source, err := currentSource()
+	if err != nil {
+		return nil, err
 	}
 
-	var issues []Issue
+	issues, err := source.FetchIssues(cl, boardId, resource, jql)
+	progress.Clear()
+	if err != nil {
+		return nil, err
+	}
+	if !asOf.IsZero() {
+		issues = rewindIssues(issues, asOf)
+	}
+	if user := viewAs(); user != "" && resource != "backlog" {
+		if backend := backendName(); backend == "jira" || backend == "keys" {
+			checkVisibility(cl, issues, user)
+		}
+	}
+	issues = filterIssues(cl, issues)
+	// The footer counts the issues analyzed, not backlogs read for context:
+	if resource != "backlog" {
+		fetched.Issues += len(issues)
+	}
+	return issues, nil
+}
+
+// fetchJiraIssues pages through the JIRA agile API's board issues or backlog,
+// or searches the issues of a saved filter instead when one is selected:
+func fetchJiraIssues(cl *http.Client, boardId int, resource string, jql string) ([]Issue, error) {
+	jql, err := expandJQL(cl, boardId, jql, reportNow())
+	if err != nil {
+		return nil, fmt.Errorf("jql: %v", err)
+	}
+
+	filterId := savedFilterId(cl)
+	if filterId != 0 {
+		// A filter has no backlog of its own:
+		if resource == "backlog" {
+			return nil, nil
+		}
+		filter, err := fetchSavedFilter(cl, filterId)
+		if err != nil {
+			return nil, fmt.Errorf("filter %d: %w", filterId, err)
+		}
+		jql = combineFilterJQL(filter.JQL, jql)
+	}
+
+	if !asOf.IsZero() {
+		jql = combineFilterJQL(jql, asOfJQL())
+	}
+
+	issues, capped, err := pageJiraIssues(cl, boardId, resource, jql, filterId, 0)
+	if err != nil {
+		return nil, err
+	}
+	// Past what the server returns for one query, refetch in windows:
+	if capped > 0 {
+		return fetchWindowedIssues(cl, boardId, resource, jql, filterId, capped)
+	}
+	return issues, nil
+}
+
+// pageJiraIssues pages through the results of one query. When the server
+// stops short of the total, or the total is over the configured result cap,
+// it also returns how many results one query can be trusted to return. With
+// upTo, it instead returns the first upTo results of a larger query.
+func pageJiraIssues(cl *http.Client, boardId int, resource string, jql string, filterId int, upTo int) (issues []Issue, capped int, err error) {
 	startAt := 0
 	total := 1
 
-	for startAt < total {
-		cacheFilename := fmt.Sprintf("board.%d.issue.%d.json", boardId, startAt)
+	// Key cached pages by server and query so different reports (or merged
+	// instances) don't share results:
+	h := fnv.New32a()
+	h.Write([]byte(setting(cl, "JIRA_URL") + " " + jql))
+	jqlHash := h.Sum32()
+	query := url.QueryEscape(jql)
+
+	for startAt < total && (upTo == 0 || len(issues) < upTo) {
+		cacheFilename := fmt.Sprintf("board.%d.%08x.%s.%d.json", boardId, jqlHash, resource, startAt)
 
-		jiraUrl := os.ExpandEnv("$JIRA_URL/rest/agile/1.0/board")
+		jiraUrl := setting(cl, "JIRA_URL") + "/rest/agile/1.0/board"
 		url := fmt.Sprintf(
-			"%s/%d/issue?expand=changelog&startAt=%d&jql=%s",
+			"%s/%d/%s?expand=changelog&startAt=%d&jql=%s",
 			jiraUrl,
 			boardId,
+			resource,
 			startAt,
-			url.QueryEscape(jql),
+			query,
 		)
+		if filterId != 0 {
+			cacheFilename = fmt.Sprintf("filter.%d.%08x.%d.json", filterId, jqlHash, startAt)
+			url = fmt.Sprintf("%s/rest/api/2/search?expand=changelog&startAt=%d&jql=%s", setting(cl, "JIRA_URL"), startAt, query)
+		}
 
-		// Fetch from cache or network:
+		// Fetch from cache or network; a later page failing leaves a report
+		// on the issues fetched so far:
 		issuesJsonBody, err := cachedGet(cacheFilename, url, cl)
+		if err != nil && len(issues) > 0 {
+			fetched.gap("%d of %s not fetched (%v)", total-len(issues), plural(total, "issue"), err)
+			break
+		}
 		if err != nil {
-			log.Fatal(err)
+			return nil, 0, err
 		}
 
 		// Decode list of issues:
-		pagedIssues := &PagedIssues{}
-		dec := json.NewDecoder(issuesJsonBody)
-		err = dec.Decode(pagedIssues)
+		pagedIssues, count, err := decodeIssuesPage(issuesJsonBody, url)
+		issuesJsonBody.Close()
 		if err != nil {
-			log.Fatal(err)
+			return nil, 0, err
+		}
+		if limit := config.ResultCap; limit > 0 && pagedIssues.Total > limit && upTo == 0 {
+			return nil, limit, nil
+		}
+		// An empty page short of the total is the server's result cap:
+		if count == 0 && pagedIssues.StartAt < pagedIssues.Total {
+			return issues, len(issues), nil
 		}
 
 		// Advance to next page:
 		total = pagedIssues.Total
-		startAt = pagedIssues.StartAt + len(pagedIssues.Issues)
+		startAt = pagedIssues.StartAt + count
 
 		if issues == nil {
 			issues = make([]Issue, 0, pagedIssues.Total)
@@ -295,97 +619,47 @@ JIRA_JQL      = custom JQL filter to apply; default='status not in (closed, canc
 
 		// Append page:
 		issues = append(issues, pagedIssues.Issues...)
-	}
-
-	now := time.Now()
-	today := DateOf(now)
-
-	// Discover latest status per issue:
-	aging := make(map[string][]*Issue)
-	for i := range issues {
-		issue := &issues[i]
-
-		if issue.Fields.EpicName != "" {
-			continue
+		fetched.Pages++
+		what := fmt.Sprintf("board %d %s", boardId, resource)
+		if filterId != 0 {
+			what = fmt.Sprintf("filter %d", filterId)
 		}
+		progress.Update(what, len(issues), total, "issues", plural(fetched.Pages, "page"))
+	}
 
-		issue.StatusTime = time.Unix(0, 0)
+	if upTo > 0 && len(issues) > upTo {
+		issues = issues[:upTo]
+	}
+	return issues, 0, nil
+}
 
-		for _, history := range issue.Changelog.Histories {
-			for _, item := range history.Items {
-				// Ignore any fields except status changes:
-				if item.Field != "status" {
-					continue
-				}
+// filterIssues applies the global tag and team filters and resolves what the
+// reports need to know about the fetched issues:
+func filterIssues(cl *http.Client, issues []Issue) []Issue {
+	issues = filterTags(issues)
+	users.registerUsers(issues)
+	issues = filterTeam(issues)
 
-				issue.Status = item.ToString
-				if item.ToString == "In Progress" {
-					issue.StatusTime = history.Created.Time
-				}
-				issue.Assigned = history.Author
-			}
+	// Walk the changelogs up front, where progress can be shown:
+	for i := range issues {
+		issues[i].Fields.Status.Name = canonicalStatus(issues[i].Fields.Project.Key, issues[i].Fields.Status.Name)
+		issues[i].Transitions()
+		if issues[i].migration() != "" {
+			fetched.migrated(issues[i].Key)
 		}
+		progress.Update("analyzing changelogs", i+1, len(issues), "issues", "")
+	}
+	progress.Clear()
+	return issues
+}
 
-		if issue.Status == "" {
-			continue
-		}
-		//if issue.Status == "Open" || issue.Status == "Reopened" || issue.Status == "Closed" {
-		//	continue
-		//}
-
-		// Determine age in business days:
-		issue.StatusBusinessDays = DateOf(issue.StatusTime).BusinessDaysUntil(today)
-
-		// Add to status map:
-		aging[issue.Status] = append(aging[issue.Status], issue)
-	}
-
-	//keys := []string{
-	//	"In Progress",     // In Development
-	//	"In Progress - 1", // PR
-	//	"In Progress - 2", // Ready for QA
-	//	"In Testing",      // In Testing
-	//	//"Approved",
-	//}
-	names := map[string]string{
-		"In Progress":     "In Development",
-		"In Progress - 1": "PR",
-		"In Progress - 2": "Ready for QA",
-		"In Testing":      "In Testing",
-	}
-
-	keys := make([]string, 0, len(aging))
-	for key := range aging {
-		keys = append(keys, key)
-	}
-	sort.Strings(keys)
-
-	timeLayout := "Mon Jan 02"
-	fmt.Printf("Now: %s\n", now.Format(timeLayout))
-	for _, status := range keys {
-		// sort issues by time descending:
-		statusIssues := IssueList(aging[status])
-		sort.Sort(statusIssues)
-
-		friendlyName, ok := names[status]
-		if ok {
-			friendlyName = fmt.Sprintf(" (%s)", friendlyName)
-		}
-		fmt.Printf("%s%s: [\n", status, friendlyName)
-		for _, issue := range statusIssues {
-			//jb, _ := json.Marshal(issue)
-			//fmt.Printf("%s\n", string(jb))
-
-			time.Now().Sub(issue.StatusTime)
-			fmt.Printf(
-				"  %20s: %s (%2d days old since %s); %s\n",
-				issue.Assigned.UserName,
-				issue.Key,
-				issue.StatusBusinessDays,
-				issue.StatusTime.Format(timeLayout),
-				issue.Fields.Summary,
-			)
+func main() {
+	err := runCLI(os.Args[1:])
+	if err != nil {
+		log.Print(err)
+		if hint := errorHint(err); hint != "" {
+			log.Printf("hint: %s\n", hint)
 		}
-		fmt.Printf("]\n")
+		os.Exit(1)
 	}
 }
@@ -163,4 +163,48 @@ func TestDate_BusinessDaysUntil_Now(t *testing.T) {
 	if days != 1 {
 		t.Fatalf("expected 1 for days, got %d", days)
 	}
-}
\ No newline at end of file
+}
+
+func TestLessIssueKey_Numeric(t *testing.T) {
+	if !lessIssueKey("ABC-9", "ABC-10") {
+		t.Fatalf("expected ABC-9 before ABC-10")
+	}
+	if lessIssueKey("ABC-10", "ABC-9") {
+		t.Fatalf("expected ABC-10 after ABC-9")
+	}
+	if !lessIssueKey("ABC-10", "XYZ-1") {
+		t.Fatalf("expected ABC project before XYZ")
+	}
+}
+
+func TestParseTimestamp_Layouts(t *testing.T) {
+	expected := time.Date(2018, 11, 5, 19, 10, 25, 0, time.UTC)
+
+	for _, s := range []string{
+		"2018-11-05T14:10:25.000-0500",
+		"2018-11-05T14:10:25-0500",
+		"2018-11-05T19:10:25.000Z",
+		"2018-11-05T19:10:25Z",
+		"2018-11-05T14:10:25.000-05:00",
+		"1541445025000",
+	} {
+		actual, err := parseTimestamp(s)
+		if err != nil {
+			t.Fatalf("expected '%s' to parse, got %v", s, err)
+		}
+		if !actual.Equal(expected) {
+			t.Fatalf("expected %v for '%s', got %v", expected, s, actual)
+		}
+	}
+}
+
+func TestZonedTimestamp_UnmarshalJSON_Invalid(t *testing.T) {
+	var ts zonedTimestamp
+	err := ts.UnmarshalJSON([]byte(`"yesterday"`))
+	if err != nil {
+		t.Fatalf("expected no error for unparseable timestamp, got %v", err)
+	}
+	if !ts.IsZero() {
+		t.Fatalf("expected zero time for unparseable timestamp, got %v", ts.Time)
+	}
+}
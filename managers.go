@@ -0,0 +1,144 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// managerOf maps each person to their manager, both by reported name:
+func managerOf() map[string]string {
+	managers := make(map[string]string, len(config.Managers))
+	for person, manager := range config.Managers {
+		managers[users.NameOf(person)] = users.NameOf(manager)
+	}
+	return managers
+}
+
+// managerChain lists a person's managers, nearest first:
+func managerChain(managers map[string]string, name string) []string {
+	var chain []string
+	seen := map[string]bool{name: true}
+	for {
+		manager, ok := managers[name]
+		if !ok || seen[manager] {
+			return chain
+		}
+		chain = append(chain, manager)
+		seen[manager] = true
+		name = manager
+	}
+}
+
+type managerStats struct {
+	People    map[string]bool
+	WIP       int
+	Oldest    int
+	Completed int
+}
+
+// rollupByManager aggregates issues to every manager above whoever is
+// responsible for them, so directors see all their reports' work:
+func rollupByManager(issues []Issue, managers map[string]string, today Date, since Date) map[string]*managerStats {
+	byManager := make(map[string]*managerStats)
+	get := func(manager string) *managerStats {
+		stats, ok := byManager[manager]
+		if !ok {
+			stats = &managerStats{People: make(map[string]bool)}
+			byManager[manager] = stats
+		}
+		return stats
+	}
+
+	// Every configured person counts toward their managers, with or without
+	// issues:
+	for person := range managers {
+		for _, manager := range managerChain(managers, person) {
+			get(manager).People[person] = true
+		}
+	}
+
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		chain := managerChain(managers, users.Name(issue.responsible()))
+		if len(chain) == 0 {
+			continue
+		}
+
+		completed, done := issue.CompletedTime()
+		started, ok := issue.StartedTime()
+		for _, manager := range chain {
+			stats := get(manager)
+			if done {
+				if !completed.Before(since.Time) {
+					stats.Completed++
+				}
+			} else if ok {
+				stats.WIP++
+				if age := DateOf(started).BusinessDaysUntil(today); age > stats.Oldest {
+					stats.Oldest = age
+				}
+			}
+		}
+	}
+	return byManager
+}
+
+func runManagers(args []string) error {
+	fs := flag.NewFlagSet("managers", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting issues; default covers open issues and those resolved in the last -weeks")
+	weeks := fs.Int("weeks", 12, "weeks of completed work to measure throughput over")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(config.Managers) == 0 {
+		return fmt.Errorf("no managers configured")
+	}
+	if *weeks < 1 {
+		return fmt.Errorf("-weeks must be at least 1")
+	}
+
+	defaultFilter := fmt.Sprintf("resolved >= -%dd OR resolution is EMPTY", 7**weeks)
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("managers", *jql, defaultFilter))
+	if err != nil {
+		return err
+	}
+
+	now := reportNow()
+	managers := managerOf()
+	byManager := rollupByManager(issues, managers, DateOf(now), DateOf(now.AddDate(0, 0, -7**weeks)))
+
+	// Print the org chart top down, each manager's rollup including everyone
+	// below them:
+	reports := make(map[string][]string)
+	var roots []string
+	for manager := range byManager {
+		if above, ok := managers[manager]; ok && byManager[above] != nil {
+			reports[above] = append(reports[above], manager)
+		} else {
+			roots = append(roots, manager)
+		}
+	}
+
+	fmt.Printf("By manager, including all reports (ages in business days, throughput over %s):\n", plural(*weeks, "week"))
+	fmt.Printf("  %s %6s %4s %6s %9s\n", padRight("manager", nameWidth(24)), "people", "WIP", "oldest", "done/week")
+	var printManager func(manager string, depth int)
+	printManager = func(manager string, depth int) {
+		stats := byManager[manager]
+		fmt.Printf("  %s %6d %4d %6d %9.1f\n", padRight(strings.Repeat("  ", depth)+manager, nameWidth(24)), len(stats.People), stats.WIP, stats.Oldest, float64(stats.Completed)/float64(*weeks))
+
+		sort.Strings(reports[manager])
+		for _, report := range reports[manager] {
+			printManager(report, depth+1)
+		}
+	}
+	sort.Strings(roots)
+	for _, root := range roots {
+		printManager(root, 0)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRollupByManager_IncludesIndirectReports(t *testing.T) {
+	config = &Config{Managers: map[string]string{
+		"alice":           "dana",
+		"bob@example.com": "dana",
+		"dana":            "erin",
+		"carol":           "frank",
+	}}
+	users = newUserRegistry()
+	defer func() {
+		config = &Config{}
+		users = newUserRegistry()
+	}()
+
+	now := time.Date(2018, 11, 6, 12, 0, 0, 0, time.UTC)
+	done := completedIssue("A-1", now.AddDate(0, 0, -10), now.AddDate(0, 0, -3))
+	done.Fields.Assignee = &User{UserName: "alice"}
+	wip := Issue{Key: "A-2"}
+	wip.Fields.Assignee = &User{UserName: "bob", EmailAddress: "bob@example.com"}
+	wip.Changelog.Histories = []History{
+		{Created: zonedTimestamp{now.AddDate(0, 0, -7)}, Items: []HistoryItem{{Field: "status", ToString: inProgressStatus}}},
+	}
+	issues := []Issue{done, wip}
+	users.registerUsers(issues)
+
+	byManager := rollupByManager(issues, managerOf(), DateOf(now), DateOf(now.AddDate(0, 0, -28)))
+	dana, erin, frank := byManager["dana"], byManager["erin"], byManager["frank"]
+	if dana == nil || dana.WIP != 1 || dana.Oldest != 5 || dana.Completed != 1 || len(dana.People) != 2 {
+		t.Fatalf("expected dana's rollup of alice and bob, got %+v", dana)
+	}
+	if erin == nil || erin.WIP != 1 || erin.Completed != 1 || len(erin.People) != 3 {
+		t.Fatalf("expected erin to include dana's reports, got %+v", erin)
+	}
+	if frank == nil || frank.WIP != 0 || len(frank.People) != 1 {
+		t.Fatalf("expected frank listed with carol and no work, got %+v", frank)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// memoizedReport is a rendered report together with the snapshot of data it
+// was computed from: the SHA-256 of every cached response or input file read.
+type memoizedReport struct {
+	Snapshot map[string]string      `json:"snapshot"`
+	Output   string                 `json:"output"`
+	Metrics  map[string]interface{} `json:"metrics,omitempty"`
+}
+
+// snapshotReads collects the data read while a memoized report runs; nil
+// otherwise.
+var snapshotReads map[string]string
+
+func recordSnapshot(filename string, b []byte) {
+	if snapshotReads == nil {
+		return
+	}
+	sum := sha256.Sum256(b)
+	snapshotReads[filename] = hex.EncodeToString(sum[:])
+}
+
+// memoFilename keys a report by everything besides the data that shapes its
+// output: command, arguments, global flags and the settings they resolve to,
+// config, environment and the day it is computed as of.
+func memoFilename(cmd *command, args []string) string {
+	h := sha256.New()
+	configJSON, _ := json.Marshal(config)
+	fmt.Fprintf(h, "%s %q\n%s\n", cmd.Name, args, configJSON)
+	fmt.Fprintf(h, "%s %q %q %s %s %s %q %d %d %d %t\n", profileName, includeTags, excludeTags, teamFilter, groupBy, inputFile, issueKeys, filterFlag, reportVerbosity, detailLimit, includeMigrated)
+	// Every global flag, so one added later can't replay another's output:
+	if globalFlagSet != nil {
+		globalFlagSet.VisitAll(func(f *flag.Flag) {
+			fmt.Fprintf(h, "-%s=%s\n", f.Name, f.Value)
+		})
+	}
+	for _, key := range []string{"JIRA_URL", "JIRA_BOARDID", "JIRA_JQL", "JIRA_PERCENTILES", "JIRA_BACKEND", "JIRA_FILTER", "JIRA_VIEW_AS"} {
+		fmt.Fprintf(h, "%s=%s\n", key, os.Getenv(key))
+	}
+	fmt.Fprintf(h, "%s\n", DateOf(reportNow()).Format("2006-01-02"))
+	if !asOf.IsZero() {
+		fmt.Fprintf(h, "asOf=%s\n", asOf.Format(time.RFC3339))
+	}
+	if reportLocation != nil {
+		fmt.Fprintf(h, "tz=%s\n", reportLocation)
+	}
+	return fmt.Sprintf("report.%x.json", h.Sum(nil)[:8])
+}
+
+// loadMemo returns the memoized report if it is no older than the response
+// cache and every file it was computed from is unchanged.
+func loadMemo(filename string) (*memoizedReport, bool) {
+	stat, err := os.Stat(filename)
+	if err != nil || stat.ModTime().Before(time.Now().Add(-cacheTTL)) {
+		return nil, false
+	}
+
+	b, err := readCacheFile(filename)
+	if err != nil {
+		return nil, false
+	}
+	memo := &memoizedReport{}
+	if err = json.Unmarshal(b, memo); err != nil {
+		return nil, false
+	}
+
+	for snapshotFile, hash := range memo.Snapshot {
+		b, err := readCacheFile(snapshotFile)
+		if err != nil {
+			return nil, false
+		}
+		sum := sha256.Sum256(b)
+		if hex.EncodeToString(sum[:]) != hash {
+			return nil, false
+		}
+	}
+	return memo, true
+}
+
+// memoized wraps a report run so that repeating it on unchanged data replays
+// the previous output instead of recomputing it.
+func memoized(cmd *command, args []string, run func() error) func() error {
+	if !cmd.Memoize || getEnvInt("JIRA_NOCACHE", 0) != 0 {
+		return run
+	}
+
+	return func() error {
+		filename := memoFilename(cmd, args)
+		if memo, ok := loadMemo(filename); ok {
+			log.Printf("memo: data unchanged, replaying %s\n", filename)
+			for name, value := range memo.Metrics {
+				if reportMetrics != nil {
+					reportMetrics[name] = value
+				}
+			}
+			_, err := os.Stdout.WriteString(memo.Output)
+			return err
+		}
+
+		snapshotReads = make(map[string]string)
+		defer func() { snapshotReads = nil }()
+		// Metrics are memoized even when not wanted this run:
+		if reportMetrics == nil {
+			reportMetrics = make(map[string]interface{})
+			defer func() { reportMetrics = nil }()
+		}
+
+		out, err := captureOutput(run)
+		os.Stdout.WriteString(out)
+		if err != nil {
+			return err
+		}
+
+		b, err := json.Marshal(&memoizedReport{Snapshot: snapshotReads, Output: out, Metrics: reportMetrics})
+		if err == nil {
+			err = writeCacheFile(filename, b)
+		}
+		if err != nil {
+			log.Printf("memo: %s: %v\n", filename, err)
+		} else {
+			indexCacheFile(filename, strings.TrimSpace(cmd.Name+" "+strings.Join(args, " ")), len(b))
+		}
+		return nil
+	}
+}
+
+// readSnapshotFile reads an input file, recording it in the snapshot:
+func readSnapshotFile(filename string) ([]byte, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err == nil {
+		recordSnapshot(filename, b)
+	}
+	return b, err
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestMemoized_ReplaysUntilDataChanges(t *testing.T) {
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+
+	ioutil.WriteFile("board.1.json", []byte(`{"issues":[]}`), 0644)
+	runs := 0
+	cmd := &command{Name: "test", Memoize: true}
+	report := func() error {
+		runs++
+		b, err := readSnapshotFile("board.1.json")
+		fmt.Printf("run %d: %d bytes\n", runs, len(b))
+		return err
+	}
+
+	for i, expected := range []string{"run 1: 13 bytes\n", "run 1: 13 bytes\n"} {
+		out, err := captureOutput(memoized(cmd, nil, report))
+		if err != nil || out != expected {
+			t.Fatalf("expected run %d to print %q, got %q %v", i+1, expected, out, err)
+		}
+	}
+	if runs != 1 {
+		t.Fatalf("expected the second run replayed from the memo, ran %d times", runs)
+	}
+
+	ioutil.WriteFile("board.1.json", []byte(`{"issues":[{}]}`), 0644)
+	out, _ := captureOutput(memoized(cmd, nil, report))
+	if runs != 2 || out != "run 2: 15 bytes\n" {
+		t.Fatalf("expected changed data to recompute the report, got %q", out)
+	}
+}
+
+func TestMemoFilename_KeysOnOutputSettings(t *testing.T) {
+	cmd := &command{Name: "backlog", Memoize: true}
+	unlimited := memoFilename(cmd, nil)
+
+	detailLimit = 1
+	limited := memoFilename(cmd, nil)
+	detailLimit = 0
+	if limited == unlimited {
+		t.Errorf("expected -limit to key the memo")
+	}
+
+	includeMigrated = true
+	migrated := memoFilename(cmd, nil)
+	includeMigrated = false
+	if migrated == unlimited {
+		t.Errorf("expected -include-migrated to key the memo")
+	}
+
+	saved := globalFlagSet
+	defer func() { globalFlagSet = saved }()
+	globalFlagSet = flag.NewFlagSet("test", flag.ContinueOnError)
+	width := globalFlagSet.Int("width", 0, "")
+	before := memoFilename(cmd, nil)
+	*width = 40
+	if memoFilename(cmd, nil) == before {
+		t.Errorf("expected every global flag to key the memo")
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// mergedProfile looks up a profile merged into the active one. Its settings
+// are passed explicitly, on the client its issues are fetched with, so the
+// environment and active profile stay as they are for concurrent reports.
+func mergedProfile(name string) (*Profile, error) {
+	profile, err := config.Profile(name)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("unknown profile '%s'", name)
+	}
+	if len(profile.Merge) > 0 {
+		return nil, fmt.Errorf("profile '%s': merged profiles cannot be nested", name)
+	}
+	return profile, nil
+}
+
+// profileSource is the tracker a merged profile reads from, and the client,
+// carrying the profile's settings, to read it with:
+func profileSource(cl *http.Client, name string) (issueSource, *http.Client, error) {
+	profile, err := mergedProfile(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	cl = profileClient(cl, profile.env())
+	backend := strings.ToLower(setting(cl, "JIRA_BACKEND"))
+	if backend == "" {
+		backend = "jira"
+	}
+	source, ok := sources[backend]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown backend '%s'", backend)
+	}
+	return source, cl, nil
+}
+
+// mergedSource combines the issues of several profiles, each read from its own
+// tracker and board, tagging each issue with the profile it came from.
+type mergedSource struct {
+	profiles []string
+}
+
+func (m mergedSource) FetchIssues(cl *http.Client, boardId int, resource string, query string) ([]Issue, error) {
+	var merged []Issue
+	for _, name := range m.profiles {
+		// Each profile authenticates with its own provider and client
+		// certificate:
+		source, cl, err := profileSource(cl, name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		// Each profile reads its own board; the report's filter applies to all
+		// of them:
+		issues, err := source.FetchIssues(cl, settingInt(cl, "JIRA_BOARDID", 4454), resource, query)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		// Visibility is checked against this profile's viewAs user, on its
+		// own instance:
+		_, jira := source.(jiraSource)
+		if user := setting(cl, "JIRA_VIEW_AS"); user != "" && jira {
+			checkVisibility(cl, issues, user)
+		}
+		for i := range issues {
+			issues[i].Source = name
+		}
+		merged = append(merged, issues...)
+	}
+	return merged, nil
+}
+
+func (m mergedSource) Describe(cl *http.Client) string {
+	descriptions := make([]string, 0, len(m.profiles))
+	for _, name := range m.profiles {
+		if source, cl, err := profileSource(cl, name); err == nil {
+			descriptions = append(descriptions, name+": "+source.Describe(cl))
+		}
+	}
+	return "merged from " + strings.Join(descriptions, "; ")
+}
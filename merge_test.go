@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMergedSource_FetchIssues(t *testing.T) {
+	first := fixtureServer(t)
+	defer first.Close()
+	second := fixtureServer(t)
+	defer second.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	config = &Config{Profiles: map[string]*Profile{
+		"web":    {URL: first.URL, BoardId: 1},
+		"mobile": {URL: second.URL, BoardId: 2},
+		"org":    {Merge: []string{"web", "mobile"}},
+	}}
+	activeProfile = config.Profiles["org"]
+	os.Setenv("JIRA_URL", first.URL)
+	defer func() {
+		config = &Config{}
+		activeProfile = nil
+		os.Unsetenv("JIRA_URL")
+	}()
+
+	single, err := jiraSource{}.FetchIssues(first.Client(), 1, "issue", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issues, err := fetchBoardIssues(first.Client(), 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2*len(single) {
+		t.Fatalf("expected %d issues from both profiles, got %d", 2*len(single), len(issues))
+	}
+	if !issues[0].hasTag("source:web") || !issues[len(issues)-1].hasTag("source:mobile") {
+		t.Fatalf("expected issues tagged by source, got %v and %v", issues[0].Tags(), issues[len(issues)-1].Tags())
+	}
+	if os.Getenv("JIRA_URL") != first.URL || activeProfile != config.Profiles["org"] {
+		t.Fatalf("expected merged profile settings restored, got %s", os.Getenv("JIRA_URL"))
+	}
+}
+
+func TestProfileSource_IsolatesEverySetting(t *testing.T) {
+	config = &Config{Profiles: map[string]*Profile{
+		"web":    {URL: "https://web.example", Filter: 10100, ViewAs: "ann"},
+		"mobile": {URL: "https://mobile.example", Backend: "linear"},
+	}}
+	defer func() { config = &Config{} }()
+	os.Setenv("JIRA_URL", "https://org.example")
+	defer os.Unsetenv("JIRA_URL")
+
+	var got []string
+	for _, name := range []string{"web", "mobile"} {
+		source, cl, err := profileSource(httpClient(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, fmt.Sprintf("%T %s %d %q", source, setting(cl, "JIRA_URL"), savedFilterId(cl), setting(cl, "JIRA_VIEW_AS")))
+	}
+	expected := []string{
+		`main.jiraSource https://web.example 10100 "ann"`,
+		`main.linearSource https://mobile.example 0 ""`,
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("expected each profile's own settings only, got %q", got)
+	}
+	// The environment is left alone for reports running alongside:
+	if _, set := os.LookupEnv("JIRA_FILTER"); set || os.Getenv("JIRA_URL") != "https://org.example" || viewAs() != "" {
+		t.Fatalf("expected the environment untouched by the merged profiles")
+	}
+}
+
+func TestProfileSource_OwnClientPerAuth(t *testing.T) {
+	config = &Config{Profiles: map[string]*Profile{
+		"web":    {URL: "https://web.example", Auth: "mtls", ClientCert: "web.crt", ClientKey: "web.key"},
+		"mobile": {URL: "https://mobile.example", Auth: "mtls", ClientCert: "mobile.crt", ClientKey: "mobile.key"},
+		"basic":  {URL: "https://basic.example"},
+	}}
+	defer func() { config = &Config{} }()
+	os.Setenv("JIRA_AUTH", "token")
+	defer os.Unsetenv("JIRA_AUTH")
+
+	clients := map[*http.Client]string{}
+	var errs []string
+	for _, name := range []string{"web", "mobile", "basic"} {
+		_, cl, err := profileSource(httpClient(), name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		clients[cl] = name
+		req, _ := http.NewRequest(http.MethodGet, "https://example.invalid", nil)
+		errs = append(errs, fmt.Sprint(authenticate(cl, req)))
+	}
+	if len(clients) != 3 {
+		t.Fatalf("expected a client per profile's settings, got %v", clients)
+	}
+	// Each profile's certificate is loaded, and fails, on its own:
+	if !strings.Contains(errs[0], "web.crt") || !strings.Contains(errs[1], "mobile.crt") || errs[2] != "<nil>" {
+		t.Fatalf("expected mtls profiles to fail on their own certificates and basic to authenticate, got %q", errs)
+	}
+	if os.Getenv("JIRA_AUTH") != "token" {
+		t.Fatalf("expected JIRA_AUTH untouched by the merged profiles, got %q", os.Getenv("JIRA_AUTH"))
+	}
+}
+
+// instanceServer serves one team-managed issue moving from status 1 to 3, on
+// an instance with the given statuses:
+func instanceServer(t *testing.T, key string, statuses string) *httptest.Server {
+	issue := fmt.Sprintf(`{"total": 1, "issues": [{"id": "1", "key": %q, "fields": {
+		"summary": "Sync", "status": {"name": "Doing", "statusCategory": {"key": "indeterminate"}},
+		"created": "2018-10-01T09:00:00.000-0500", "issuetype": {"name": "Story"},
+		"project": {"key": "NG", "name": "NG", "style": "next-gen"}},
+		"changelog": {"total": 1, "histories": [{"id": "1", "created": "2018-10-29T09:00:00.000-0500",
+			"items": [{"field": "status", "from": "1", "fromString": "To Do", "to": "3", "toString": "Doing"}]}]}}]}`, key)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/rest/api/2/status" {
+			w.Write([]byte(statuses))
+			return
+		}
+		w.Write([]byte(issue))
+	}))
+}
+
+func TestMergedSource_StatusIdsPerInstance(t *testing.T) {
+	// Status 3 is in progress on the first instance, the second's 3 an
+	// earlier backlog status:
+	first := instanceServer(t, "WEB-1", `[{"id": "1", "name": "To Do", "statusCategory": {"key": "new"}},
+		{"id": "3", "name": "Doing", "statusCategory": {"key": "indeterminate"}}]`)
+	defer first.Close()
+	second := instanceServer(t, "APP-1", `[{"id": "1", "name": "Triage", "statusCategory": {"key": "new"}},
+		{"id": "3", "name": "Ready", "statusCategory": {"key": "new"}}]`)
+	defer second.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	config = &Config{Profiles: map[string]*Profile{
+		"web":    {URL: first.URL, BoardId: 1},
+		"mobile": {URL: second.URL, BoardId: 2},
+		"org":    {Merge: []string{"web", "mobile"}},
+	}}
+	activeProfile = config.Profiles["org"]
+	os.Setenv("JIRA_URL", first.URL)
+	defer func() {
+		config = &Config{}
+		activeProfile = nil
+		statusCategories = nil
+		jiraStatusesLoaded = make(map[string]bool)
+		os.Unsetenv("JIRA_URL")
+	}()
+
+	issues, err := fetchBoardIssues(first.Client(), 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected an issue from each instance, got %d", len(issues))
+	}
+	if _, started := issues[0].StartedTime(); !started {
+		t.Errorf("expected %s started by moving to its instance's in-progress status 3", issues[0].Key)
+	}
+	if _, started := issues[1].StartedTime(); started {
+		t.Errorf("expected %s not started by moving to its instance's backlog status 3", issues[1].Key)
+	}
+	if !jiraStatusesLoaded[first.URL] || !jiraStatusesLoaded[second.URL] {
+		t.Errorf("expected both instances' statuses loaded, got %v", jiraStatusesLoaded)
+	}
+}
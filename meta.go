@@ -0,0 +1,142 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+type fieldDetail struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	Custom bool   `json:"custom"`
+	Schema struct {
+		Type   string `json:"type"`
+		Items  string `json:"items"`
+		Custom string `json:"custom"`
+	} `json:"schema"`
+}
+
+type issueTypeDetail struct {
+	Id             string     `json:"id"`
+	Name           string     `json:"name"`
+	Subtask        bool       `json:"subtask"`
+	HierarchyLevel int        `json:"hierarchyLevel"`
+	Scope          *metaScope `json:"scope"`
+}
+
+// instanceCacheName keys the cache of instance-wide metadata by server, so
+// merged instances don't share it:
+func instanceCacheName(cl *http.Client, name string) string {
+	h := fnv.New32a()
+	h.Write([]byte(setting(cl, "JIRA_URL")))
+	return fmt.Sprintf("%s.%08x.json", name, h.Sum32())
+}
+
+// fetchMeta decodes one of the instance-wide metadata lists:
+func fetchMeta(cl *http.Client, resource string, v interface{}) error {
+	url := setting(cl, "JIRA_URL") + "/rest/api/2/" + resource
+	body, err := cachedGet(instanceCacheName(cl, resource), url, cl)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return decodeResponse(body, url, v)
+}
+
+// scopeText names where team-managed metadata applies:
+func scopeText(scope *metaScope) string {
+	if scope == nil {
+		return ""
+	}
+	return "project " + scope.Project.Id
+}
+
+var metaSubcommands = map[string]func(args []string) error{
+	"fields":     runMetaFields,
+	"issuetypes": runMetaIssueTypes,
+	"statuses":   runMetaStatuses,
+}
+
+func runMeta(args []string) error {
+	return runSubcommand("meta", metaSubcommands, args)
+}
+
+func runMetaStatuses(args []string) error {
+	fs := flag.NewFlagSet("meta statuses", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	statuses, err := fetchStatuses(httpClient())
+	if err != nil {
+		return err
+	}
+
+	// Grouped by category in workflow order, for statusCategories and SLAs:
+	categoryOrder := map[string]int{"new": 0, "indeterminate": 1, "done": 2}
+	sort.SliceStable(statuses, func(i, j int) bool {
+		a, b := statuses[i], statuses[j]
+		if a.StatusCategory.Key != b.StatusCategory.Key {
+			return categoryOrder[a.StatusCategory.Key] < categoryOrder[b.StatusCategory.Key]
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	fmt.Printf("%-8s %-14s %-30s %s\n", "id", "category", "name", "scope")
+	for _, s := range statuses {
+		fmt.Println(strings.TrimRight(fmt.Sprintf("%-8s %-14s %s %s", s.Id, s.StatusCategory.Key, padRight(s.Name, 30), scopeText(s.Scope)), " "))
+	}
+	return nil
+}
+
+func runMetaFields(args []string) error {
+	fs := flag.NewFlagSet("meta fields", flag.ContinueOnError)
+	all := fs.Bool("all", false, "include system fields, not just custom fields")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var fields []fieldDetail
+	if err := fetchMeta(httpClient(), "field", &fields); err != nil {
+		return err
+	}
+
+	sort.Slice(fields, func(i, j int) bool { return strings.ToLower(fields[i].Name) < strings.ToLower(fields[j].Name) })
+	fmt.Printf("%-20s %-30s %s\n", "id", "name", "type")
+	for _, f := range fields {
+		if !f.Custom && !*all {
+			continue
+		}
+		fieldType := f.Schema.Type
+		if f.Schema.Items != "" {
+			fieldType += " of " + f.Schema.Items
+		}
+		fmt.Printf("%-20s %s %s\n", f.Id, padRight(f.Name, 30), fieldType)
+	}
+	return nil
+}
+
+func runMetaIssueTypes(args []string) error {
+	fs := flag.NewFlagSet("meta issuetypes", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var types []issueTypeDetail
+	if err := fetchMeta(httpClient(), "issuetype", &types); err != nil {
+		return err
+	}
+
+	// Epics first, subtasks last:
+	sort.SliceStable(types, func(i, j int) bool {
+		if types[i].HierarchyLevel != types[j].HierarchyLevel {
+			return types[i].HierarchyLevel > types[j].HierarchyLevel
+		}
+		return strings.ToLower(types[i].Name) < strings.ToLower(types[j].Name)
+	})
+	fmt.Printf("%-8s %-24s %5s %s\n", "id", "name", "level", "scope")
+	for _, t := range types {
+		fmt.Println(strings.TrimRight(fmt.Sprintf("%-8s %s %5d %s", t.Id, padRight(t.Name, 24), t.HierarchyLevel, scopeText(t.Scope)), " "))
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunMetaStatuses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/status" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`[
+			{"id": "6", "name": "Closed", "statusCategory": {"key": "done"}},
+			{"id": "3", "name": "In Progress", "statusCategory": {"key": "indeterminate"}},
+			{"id": "10010", "name": "Doing", "statusCategory": {"key": "indeterminate"}, "scope": {"project": {"id": "10200"}}},
+			{"id": "1", "name": "Open", "statusCategory": {"key": "new"}}
+		]`))
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+
+	out, err := captureOutput(func() error { return runMeta([]string{"statuses"}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("expected a header and 4 statuses, got %q", out)
+	}
+	for i, want := range []string{"Open", "Doing", "In Progress", "Closed"} {
+		if !strings.Contains(lines[i+1], want) {
+			t.Fatalf("expected %s on line %d in category order, got %q", want, i+1, lines[i+1])
+		}
+	}
+	if !strings.HasSuffix(lines[2], "project 10200") {
+		t.Fatalf("expected the team-managed status scoped to its project, got %q", lines[2])
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+// reportMetrics collects the headline numbers a report records while its
+// output goes to sinks, for JSON consumers to read without parsing text; nil
+// otherwise.
+var reportMetrics map[string]interface{}
+
+// metricPrefix scopes metrics to the current group of a -group-by run:
+var metricPrefix string
+
+// recordMetric records a report's metric under name:
+func recordMetric(name string, value interface{}) {
+	if reportMetrics == nil {
+		return
+	}
+	reportMetrics[metricPrefix+name] = value
+}
+
+// pipeMode is set when reading issues from stdin or writing JSON to stdout,
+// where the tool runs inside a pipeline and logging would only be noise.
+func pipeMode() bool {
+	if inputFile == "-" {
+		return true
+	}
+	for _, spec := range outputFlags {
+		if spec == "json" {
+			return true
+		}
+	}
+	return false
+}
+
+// quietLogs discards logging until the returned func restores it, so errors
+// returned from the run are still reported:
+func quietLogs() (restore func()) {
+	log.SetOutput(ioutil.Discard)
+	return func() { log.SetOutput(os.Stderr) }
+}
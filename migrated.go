@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// includeMigrated is the -include-migrated flag: count migrated issues in
+// percentiles despite their gaps.
+var includeMigrated bool
+
+// migration says why an issue's history looks migrated, moved between
+// projects or imported from another instance, leaving its metrics partially
+// trusted; "" when it doesn't.
+func (issue *Issue) migration() string {
+	transitions := issue.Transitions()
+	for _, ev := range transitions {
+		switch {
+		case strings.EqualFold(ev.Field, "Key") && ev.FromString != "":
+			return "moved from " + ev.FromString
+		case strings.EqualFold(ev.Field, "project") && ev.FromString != "":
+			return "moved from project " + ev.FromString
+		}
+	}
+	if histories := issue.Changelog.Histories; len(histories) > 0 && histories[0].Created.Before(issue.Fields.Created.Time) {
+		return "history predates creation"
+	}
+
+	statuses := issue.StatusTransitions()
+	if len(statuses) == 0 {
+		if _, done := issue.CompletedTime(); done && !issue.Fields.ResolutionDate.IsZero() {
+			return "completed without a status history"
+		}
+		return ""
+	}
+	if first := statuses[0]; categoryOfStatus(first.From, first.FromString) == "done" {
+		return fmt.Sprintf("history starts in %s", first.FromString)
+	}
+	return ""
+}
+
+// trustedForPercentiles is whether an issue's durations count in percentiles:
+// migrated issues' don't, unless -include-migrated.
+func (issue *Issue) trustedForPercentiles() bool {
+	return includeMigrated || issue.migration() == ""
+}
+
+// migrated records an issue whose history looks migrated:
+func (s *fetchStats) migrated(key string) {
+	s.Migrated = append(s.Migrated, key)
+}
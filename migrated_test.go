@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMigration(t *testing.T) {
+	started := time.Date(2018, 11, 1, 9, 0, 0, 0, time.UTC)
+	resolved := time.Date(2018, 11, 6, 9, 0, 0, 0, time.UTC)
+	created := time.Date(2018, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	plain := completedIssue("A-1", started, resolved)
+	plain.Fields.Created.Time = created
+
+	moved := completedIssue("A-2", started, resolved)
+	moved.Fields.Created.Time = created
+	moved.Changelog.Histories = append(moved.Changelog.Histories, History{
+		Created: zonedTimestamp{started.Add(time.Hour)},
+		Items:   []HistoryItem{{Field: "Key", FromString: "OLD-7", ToString: "A-2"}},
+	})
+
+	// Imported with its original dates, after creation here:
+	predates := completedIssue("A-3", started, resolved)
+	predates.Fields.Created.Time = resolved
+
+	imported := completedIssue("A-4", started, resolved)
+	imported.Fields.Created.Time = created
+	imported.Changelog.Histories = nil
+
+	reopened := completedIssue("A-5", started, resolved)
+	reopened.Fields.Created.Time = created
+	reopened.Changelog.Histories[0].Items[0].FromString = "Done"
+
+	for _, c := range []struct {
+		issue Issue
+		want  string
+	}{
+		{plain, ""},
+		{moved, "moved from OLD-7"},
+		{predates, "history predates creation"},
+		{imported, "completed without a status history"},
+		{reopened, "history starts in Done"},
+	} {
+		if got := c.issue.migration(); got != c.want {
+			t.Errorf("%s: expected %q, got %q", c.issue.Key, c.want, got)
+		}
+	}
+
+	if _, ok := moved.CycleTime(); ok {
+		t.Errorf("expected a migrated issue's cycle time left out of percentiles")
+	}
+	includeMigrated = true
+	defer func() { includeMigrated = false }()
+	if days, ok := moved.CycleTime(); !ok || days != 3 {
+		t.Errorf("expected -include-migrated to count its cycle time, got %d %v", days, ok)
+	}
+}
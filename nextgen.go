@@ -0,0 +1,150 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"strings"
+)
+
+// statusCategories maps status IDs to their category key ("new",
+// "indeterminate", "done"); loaded only when team-managed issues are present.
+var statusCategories map[string]string
+
+// jiraStatusesLoaded records the instances, by URL, whose status categories
+// are loaded:
+var jiraStatusesLoaded = make(map[string]bool)
+
+type statusDetail struct {
+	Id             string         `json:"id"`
+	Name           string         `json:"name"`
+	StatusCategory StatusCategory `json:"statusCategory"`
+	// Scope is set for statuses of a team-managed project:
+	Scope *metaScope `json:"scope"`
+}
+
+// metaScope is the project a team-managed status or issue type belongs to:
+type metaScope struct {
+	Project struct {
+		Id string `json:"id"`
+	} `json:"project"`
+}
+
+// fetchStatuses lists every status on the instance:
+func fetchStatuses(cl *http.Client) ([]statusDetail, error) {
+	url := setting(cl, "JIRA_URL") + "/rest/api/2/status"
+	body, err := cachedGet(instanceCacheName(cl, "statuses"), url, cl)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var statuses []statusDetail
+	err = decodeResponse(body, url, &statuses)
+	return statuses, err
+}
+
+func loadStatusCategories(cl *http.Client) error {
+	if jiraStatusesLoaded[setting(cl, "JIRA_URL")] {
+		return nil
+	}
+
+	statuses, err := fetchStatuses(cl)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		registerStatusCategory(instanceStatusId(cl, status.Id), status.StatusCategory.Key)
+	}
+	jiraStatusesLoaded[setting(cl, "JIRA_URL")] = true
+	return nil
+}
+
+// instanceStatusId qualifies a status ID of a merged instance by its URL, as
+// the IDs of different instances collide; the run's own instance's are kept.
+func instanceStatusId(cl *http.Client, id string) string {
+	if id == "" || setting(cl, "JIRA_URL") == os.Getenv("JIRA_URL") {
+		return id
+	}
+	return setting(cl, "JIRA_URL") + "#" + id
+}
+
+// qualifyStatusIds qualifies the status IDs in the issues' changelogs the
+// way loadStatusCategories registers them:
+func qualifyStatusIds(cl *http.Client, issues []Issue) {
+	for i := range issues {
+		for _, history := range issues[i].Changelog.Histories {
+			for j := range history.Items {
+				item := &history.Items[j]
+				if item.Field == "status" {
+					item.From = instanceStatusId(cl, item.From)
+					item.To = instanceStatusId(cl, item.To)
+				}
+			}
+		}
+	}
+}
+
+// TeamManaged reports whether the issue belongs to a team-managed (next-gen)
+// project, which has its own status and epic conventions. Issues from other
+// trackers follow the same conventions.
+func (issue *Issue) TeamManaged() bool {
+	return issue.Fields.Project.Style == "next-gen" || issue.Fields.Project.Simplified || issue.Fields.Project.Style == externalStyle
+}
+
+// IsEpic reports whether the issue is an epic; team-managed epics have no epic
+// name field.
+func (issue *Issue) IsEpic() bool {
+	if issue.Fields.EpicName != "" {
+		return true
+	}
+	return issue.TeamManaged() && (issue.Fields.IssueType.Name == "Epic" || issue.Fields.IssueType.HierarchyLevel == 1)
+}
+
+// EpicKey returns the key of the epic the issue belongs to, via the parent
+// link for team-managed projects or the epic link for classic ones.
+func (issue *Issue) EpicKey() string {
+	if issue.Fields.Epic != nil {
+		return issue.Fields.Epic.Key
+	}
+
+	parent := issue.Fields.Parent
+	if parent != nil && (parent.Fields.IssueType.Name == "Epic" || parent.Fields.IssueType.HierarchyLevel == 1) {
+		return parent.Key
+	}
+	return ""
+}
+
+// categoryOfStatus looks up a status's category by ID, falling back to its
+// name where categories weren't loaded:
+func categoryOfStatus(id string, name string) string {
+	if category, ok := statusCategories[id]; ok && id != "" {
+		return category
+	}
+	return statusCategoryOf(name)
+}
+
+// isStartTransition reports whether a status change marks the start of work.
+// Team-managed projects name statuses per project, so go by status category.
+func (issue *Issue) isStartTransition(item HistoryItem) bool {
+	if config.WorkStarted == "category" {
+		// The first move out of To Do, whatever the workflow calls it:
+		return categoryOfStatus(item.From, item.FromString) == "new" && categoryOfStatus(item.To, item.ToString) != "new"
+	}
+
+	if issue.TeamManaged() && statusCategories != nil {
+		if category, ok := statusCategories[item.To]; ok {
+			// Moves between in-progress statuses don't restart work:
+			return category == "indeterminate" && statusCategories[item.From] != "indeterminate"
+		}
+	}
+	if len(config.StartStatuses) > 0 {
+		for _, status := range config.StartStatuses {
+			if strings.EqualFold(item.ToString, status) {
+				return true
+			}
+		}
+		return false
+	}
+	return item.ToString == inProgressStatus
+}
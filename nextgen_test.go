@@ -0,0 +1,72 @@
+package main
+
+import "testing"
+
+func TestIssue_EpicKey_TeamManagedParent(t *testing.T) {
+	issue := &Issue{Key: "NG-2"}
+	issue.Fields.Project.Style = "next-gen"
+	issue.Fields.Parent = &ParentIssue{Key: "NG-1"}
+	issue.Fields.Parent.Fields.IssueType.Name = "Epic"
+
+	if key := issue.EpicKey(); key != "NG-1" {
+		t.Fatalf("expected NG-1 for epic key, got '%s'", key)
+	}
+	if issue.IsEpic() {
+		t.Fatalf("expected story not to be an epic")
+	}
+}
+
+func TestIssue_IsEpic_TeamManaged(t *testing.T) {
+	issue := &Issue{Key: "NG-1"}
+	issue.Fields.Project.Simplified = true
+	issue.Fields.IssueType.Name = "Epic"
+
+	if !issue.IsEpic() {
+		t.Fatalf("expected team-managed epic to be an epic")
+	}
+}
+
+func TestIssue_IsStartTransition_TeamManaged(t *testing.T) {
+	statusCategories = map[string]string{"1": "new", "2": "indeterminate", "3": "indeterminate"}
+	defer func() { statusCategories = nil }()
+
+	issue := &Issue{}
+	issue.Fields.Project.Style = "next-gen"
+
+	if !issue.isStartTransition(HistoryItem{Field: "status", From: "1", To: "2", ToString: "Doing"}) {
+		t.Fatalf("expected To Do -> Doing to start work")
+	}
+	if issue.isStartTransition(HistoryItem{Field: "status", From: "2", To: "3", ToString: "In Review"}) {
+		t.Fatalf("expected Doing -> In Review not to restart work")
+	}
+}
+
+func TestIssue_IsStartTransition_ByCategory(t *testing.T) {
+	config = &Config{WorkStarted: "category"}
+	statusCategories = map[string]string{"1": "new", "3": "indeterminate", "10": "indeterminate"}
+	defer func() {
+		config = &Config{}
+		statusCategories = nil
+	}()
+
+	issue := &Issue{}
+	if !issue.isStartTransition(HistoryItem{Field: "status", From: "1", FromString: "Backlog", To: "10", ToString: "Building"}) {
+		t.Fatalf("expected move out of To Do category to start work")
+	}
+	if issue.isStartTransition(HistoryItem{Field: "status", From: "10", FromString: "Building", To: "3", ToString: "In Progress"}) {
+		t.Fatalf("expected move within in-progress category not to start work")
+	}
+}
+
+func TestIssue_IsStartTransition_StartStatuses(t *testing.T) {
+	config = &Config{StartStatuses: []string{"In Development"}}
+	defer func() { config = &Config{} }()
+
+	issue := &Issue{}
+	if !issue.isStartTransition(HistoryItem{Field: "status", ToString: "In Development"}) {
+		t.Fatalf("expected configured start status to start work")
+	}
+	if issue.isStartTransition(HistoryItem{Field: "status", ToString: inProgressStatus}) {
+		t.Fatalf("expected In Progress not to start work when start statuses are configured")
+	}
+}
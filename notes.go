@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultNotesFile keeps the notes attached to issues locally, for context a
+// team doesn't want to put in JIRA itself:
+const defaultNotesFile = "jira-notes.json"
+
+// issueNote is a local annotation on an issue, e.g. "waiting on vendor":
+type issueNote struct {
+	Time time.Time `json:"time"`
+	User string    `json:"user,omitempty"`
+	Text string    `json:"text"`
+}
+
+func notesFilename() string {
+	if config.NotesFile != "" {
+		return config.NotesFile
+	}
+	return defaultNotesFile
+}
+
+// loadNotes reads the notes by issue key, oldest first; a missing file has
+// none. Reading notes is part of a report's snapshot, so editing them reruns
+// memoized reports.
+func loadNotes(filename string) (map[string][]issueNote, error) {
+	notes := make(map[string][]issueNote)
+	b, err := readCacheFile(filename)
+	if os.IsNotExist(err) {
+		return notes, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	recordSnapshot(filename, b)
+	if err = json.Unmarshal(b, &notes); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return notes, nil
+}
+
+func saveNotes(filename string, notes map[string][]issueNote) error {
+	b, err := json.MarshalIndent(notes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeCacheFile(filename, b)
+}
+
+// reportNotes are the notes loaded for the report running, nil until a
+// report asks for them:
+var reportNotes map[string][]issueNote
+
+func notesOf(key string) []issueNote {
+	if reportNotes == nil {
+		notes, err := loadNotes(notesFilename())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: notes not shown: %v\n", err)
+			notes = make(map[string][]issueNote)
+		}
+		reportNotes = notes
+	}
+	return reportNotes[key]
+}
+
+// noteSuffix shows an issue's latest note after its summary, counting any
+// earlier ones:
+func noteSuffix(issue *Issue) string {
+	notes := notesOf(issue.Key)
+	if len(notes) == 0 {
+		return ""
+	}
+	latest := notes[len(notes)-1]
+	s := fmt.Sprintf(" (note %s: %s", displayTime(latest.Time).Format("Jan 02"), redact(latest.Text))
+	if len(notes) > 1 {
+		s += fmt.Sprintf("; %d earlier", len(notes)-1)
+	}
+	return s + ")"
+}
+
+var notesSubcommands = map[string]func(args []string) error{
+	"add":    runNotesAdd,
+	"list":   runNotesList,
+	"remove": runNotesRemove,
+}
+
+func runNotes(args []string) error {
+	return runSubcommand("notes", notesSubcommands, args)
+}
+
+// noteKey checks the issue key a notes subcommand is given:
+func noteKey(fs *flag.FlagSet) (string, error) {
+	if fs.NArg() < 1 || !issueKeyPattern.MatchString(fs.Arg(0)) {
+		return "", fmt.Errorf("expected an issue key, e.g. ABC-123")
+	}
+	return strings.ToUpper(fs.Arg(0)), nil
+}
+
+func runNotesAdd(args []string) error {
+	fs := flag.NewFlagSet("notes add", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	key, err := noteKey(fs)
+	if err != nil {
+		return err
+	}
+	text := strings.TrimSpace(strings.Join(fs.Args()[1:], " "))
+	if text == "" {
+		return fmt.Errorf("expected the note's text after %s", key)
+	}
+
+	filename := notesFilename()
+	notes, err := loadNotes(filename)
+	if err != nil {
+		return err
+	}
+	notes[key] = append(notes[key], issueNote{Time: time.Now(), User: os.Getenv("JIRA_USERNAME"), Text: text})
+	if err = saveNotes(filename, notes); err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	fmt.Printf("noted on %s (%s)\n", key, plural(len(notes[key]), "note"))
+	return nil
+}
+
+func runNotesList(args []string) error {
+	fs := flag.NewFlagSet("notes list", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	notes, err := loadNotes(notesFilename())
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	if fs.NArg() > 0 {
+		key, err := noteKey(fs)
+		if err != nil {
+			return err
+		}
+		keys = []string{key}
+	} else {
+		for key := range notes {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return lessIssueKey(keys[i], keys[j]) })
+	}
+
+	listed := 0
+	for _, key := range keys {
+		for i, note := range notes[key] {
+			by := ""
+			if note.User != "" {
+				by = " by " + users.NameOf(note.User)
+			}
+			fmt.Printf("%-10s %d  %s%s: %s\n", key, i+1, displayTime(note.Time).Format("2006-01-02 15:04"), by, note.Text)
+			listed++
+		}
+	}
+	if listed == 0 {
+		fmt.Printf("No notes.\n")
+	}
+	return nil
+}
+
+func runNotesRemove(args []string) error {
+	fs := flag.NewFlagSet("notes remove", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	key, err := noteKey(fs)
+	if err != nil {
+		return err
+	}
+
+	filename := notesFilename()
+	notes, err := loadNotes(filename)
+	if err != nil {
+		return err
+	}
+	n := len(notes[key])
+	if n == 0 {
+		return fmt.Errorf("%s has no notes", key)
+	}
+	// Without a number, every note on the issue goes:
+	if fs.NArg() > 1 {
+		i, err := strconv.Atoi(fs.Arg(1))
+		if err != nil || i < 1 || i > n {
+			return fmt.Errorf("expected a note number from 1 to %d, as notes list numbers them", n)
+		}
+		notes[key] = append(notes[key][:i-1], notes[key][i:]...)
+	} else {
+		notes[key] = nil
+	}
+	removed := n - len(notes[key])
+	if len(notes[key]) == 0 {
+		delete(notes, key)
+	}
+	if err = saveNotes(filename, notes); err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	fmt.Printf("removed %s from %s\n", plural(removed, "note"), key)
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestNotes(t *testing.T) {
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	defer func() { reportNotes = nil }()
+
+	for _, args := range [][]string{
+		{"add", "abc-1", "waiting", "on", "vendor"},
+		{"add", "ABC-1", "vendor replied"},
+		{"add", "ABC-2", "reviewed"},
+		{"remove", "ABC-2"},
+	} {
+		if _, err := captureOutput(func() error { return runNotes(args) }); err != nil {
+			t.Fatalf("notes %v: %v", args, err)
+		}
+	}
+	if err := runNotes([]string{"remove", "ABC-1", "3"}); err == nil {
+		t.Fatalf("expected removing a note that doesn't exist to fail")
+	}
+
+	out, err := captureOutput(func() error { return runNotes([]string{"list"}) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 || !strings.HasSuffix(lines[0], ": waiting on vendor") {
+		t.Fatalf("expected the two ABC-1 notes, got:\n%s", out)
+	}
+
+	reportNotes = nil
+	suffix := noteSuffix(&Issue{Key: "ABC-1"})
+	if !strings.Contains(suffix, ": vendor replied; 1 earlier)") {
+		t.Fatalf("expected the latest note and a count of earlier ones, got %q", suffix)
+	}
+	if suffix := noteSuffix(&Issue{Key: "ABC-2"}); suffix != "" {
+		t.Fatalf("expected no suffix once notes are removed, got %q", suffix)
+	}
+
+	ioutil.WriteFile(defaultNotesFile, []byte("not json"), 0600)
+	if _, err := loadNotes(defaultNotesFile); err == nil {
+		t.Fatalf("expected a corrupt notes file to fail")
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/JamesDunne/jira-analysis/analysis"
+)
+
+// NudgeConfig configures the comments the nudge command posts on items that
+// have sat in a status too long.
+type NudgeConfig struct {
+	// Template is a text/template over the item's Key, Summary, Status,
+	// Days and Assignee:
+	Template string `json:"template"`
+	// Thresholds sets business days per status, overriding -days; SLA
+	// policies matching an item override both:
+	Thresholds map[string]int `json:"thresholds"`
+	// EveryDays is how many days to wait before nudging the same item
+	// again; default 5.
+	EveryDays int `json:"everyDays"`
+}
+
+const defaultNudgeTemplate = "This item has been in {{.Status}} {{.Days}} business days — please update it or move it along."
+
+// nudgesFilename records when each issue was last nudged, so reruns don't
+// repeat comments:
+const nudgesFilename = "nudges.json"
+
+// nudgeItem is what a nudge template can refer to:
+type nudgeItem struct {
+	Key      string
+	Summary  string
+	Status   string
+	Days     int
+	Assignee string
+}
+
+func nudgeThreshold(item AgingItem, days int) int {
+	if threshold, ok := config.Nudge.Thresholds[item.Status]; ok {
+		days = threshold
+	}
+	return slaThreshold(item.Issue, item.Status, stageName(item.Status), days)
+}
+
+func loadNudges() (map[string]time.Time, error) {
+	nudged := make(map[string]time.Time)
+	b, err := readBeforeWrites(nudgesFilename, readCacheFile)
+	if os.IsNotExist(err) {
+		return nudged, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return nudged, json.Unmarshal(b, &nudged)
+}
+
+// dueNudges picks the in-flight items over their status threshold that
+// weren't nudged in the last everyDays:
+func dueNudges(items []AgingItem, days int, nudged map[string]time.Time, now time.Time, everyDays int) []nudgeItem {
+	var due []nudgeItem
+	for _, item := range items {
+		if _, done := item.Issue.CompletedTime(); done {
+			continue
+		}
+		threshold := nudgeThreshold(item, days)
+		if threshold <= 0 || item.Age <= threshold {
+			continue
+		}
+		if last, ok := nudged[item.Key]; ok && now.Sub(last) < time.Duration(everyDays)*24*time.Hour {
+			continue
+		}
+		due = append(due, nudgeItem{
+			Key:      item.Key,
+			Summary:  item.Issue.Fields.Summary,
+			Status:   item.Status,
+			Days:     item.Age,
+			Assignee: item.Assignee,
+		})
+	}
+	return due
+}
+
+func runNudge(args []string) error {
+	fs := flag.NewFlagSet("nudge", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting in-flight issues")
+	days := fs.Int("days", 10, "business days in a status after which to nudge, unless configured per status")
+	post := fs.Bool("post", false, "post the comments and write-back changes; without it, only preview them")
+	labels := fs.Bool("labels", false, "add the labels configured in writeBack to items matching their rule")
+	transitions := fs.Bool("transitions", false, "make the transitions configured in writeBack on items matching their rule")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *post || *labels || *transitions {
+		if err := liveWrites(); err != nil {
+			return fmt.Errorf("nudge: %v", err)
+		}
+	}
+
+	text := config.Nudge.Template
+	if text == "" {
+		text = defaultNudgeTemplate
+	}
+	tmpl, err := template.New("nudge").Parse(text)
+	if err != nil {
+		return fmt.Errorf("nudge template: %v", err)
+	}
+	everyDays := config.Nudge.EveryDays
+	if everyDays <= 0 {
+		everyDays = 5
+	}
+
+	cl := httpClient()
+	now := reportNow()
+	a := newAnalyzer(cl, analysis.WithNow(now))
+	items, err := agingItems(a, boardArg(fs.Args()), reportJQL("nudge", *jql, defaultJQL()))
+	if err != nil {
+		return err
+	}
+	nudged, err := loadNudges()
+	if err != nil {
+		return fmt.Errorf("%s: %v", nudgesFilename, err)
+	}
+
+	due := dueNudges(items, *days, nudged, now, everyDays)
+	if !*post {
+		fmt.Printf("Dry run; %s would be commented on (rerun with -post to send):\n", plural(len(due), "issue"))
+	}
+	var failures []string
+	for _, item := range due {
+		var comment strings.Builder
+		if err := tmpl.Execute(&comment, item); err != nil {
+			return fmt.Errorf("nudge template: %v", err)
+		}
+		if !*post {
+			fmt.Printf("  %s: %s\n", item.Key, comment.String())
+			continue
+		}
+
+		entry := auditEntry{Action: auditComment, Key: item.Key, Rule: "nudge", Comment: comment.String()}
+		if err := audit(entry, postComment(cl, item.Key, comment.String())); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", item.Key, err))
+			continue
+		}
+		fmt.Printf("  commented on %s\n", item.Key)
+		nudged[item.Key] = now
+	}
+
+	if *post && !rerendering && len(due) > len(failures) {
+		b, err := json.Marshal(nudged)
+		if err == nil {
+			err = writeCacheFile(nudgesFilename, b)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", nudgesFilename, err)
+		}
+	}
+
+	changes := writeBackChanges(items, *days, now, *labels, *transitions)
+	if len(changes) > 0 && !*post {
+		fmt.Printf("\nDry run; %s would be made (rerun with -post to send):\n", plural(len(changes), "change"))
+	}
+	for _, change := range changes {
+		if !*post {
+			fmt.Printf("  %s\n", change)
+			continue
+		}
+		if err := change.apply(cl); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", change.Key, err))
+			continue
+		}
+		fmt.Printf("  %s\n", change)
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("nudge: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JamesDunne/jira-analysis/analysis"
+)
+
+func TestDueNudges(t *testing.T) {
+	config = &Config{Nudge: NudgeConfig{Thresholds: map[string]int{"In Testing": 3}}}
+	defer func() { config = &Config{} }()
+
+	now := time.Date(2018, 11, 30, 9, 0, 0, 0, time.UTC)
+	item := func(key, status string, age int) AgingItem {
+		return AgingItem{AgingItem: analysis.AgingItem{Key: key, Status: status, Age: age, Assignee: "alice"}, Issue: &Issue{Key: key}}
+	}
+	done := completedIssue("A-5", now.AddDate(0, 0, -30), now.AddDate(0, 0, -20))
+	items := []AgingItem{
+		item("A-1", "In Progress", 12),
+		item("A-2", "In Progress", 8),
+		item("A-3", "In Testing", 4),
+		item("A-4", "In Progress", 15),
+		{AgingItem: analysis.AgingItem{Key: "A-5", Status: "Closed", Age: 20}, Issue: &done},
+	}
+	nudged := map[string]time.Time{"A-4": now.AddDate(0, 0, -2)}
+
+	due := dueNudges(items, 10, nudged, now, 5)
+	if len(due) != 2 || due[0].Key != "A-1" || due[1].Key != "A-3" {
+		t.Fatalf("expected A-1 over -days and A-3 over its status threshold, got %+v", due)
+	}
+}
+
+func TestPostComment(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/rest/api/2/issue/ABC-1/comment" {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+
+	if err := postComment(srv.Client(), "ABC-1", "please update"); err != nil {
+		t.Fatal(err)
+	}
+	if got["body"] != "please update" {
+		t.Fatalf("expected the comment posted, got %v", got)
+	}
+	if err := postComment(srv.Client(), "ABC-2", "x"); err == nil {
+		t.Fatalf("expected an error for a missing issue")
+	}
+}
+
+func TestRunNudge_RefusesWritesFromPastOrOfflineState(t *testing.T) {
+	asOf = time.Date(2018, 10, 20, 23, 59, 0, 0, time.UTC)
+	defer func() { asOf = time.Time{} }()
+	for _, args := range [][]string{{"-post"}, {"-labels"}, {"-transitions"}} {
+		if err := runNudge(args); err == nil || !strings.Contains(err.Error(), "-as-of") {
+			t.Errorf("nudge %v: expected writes refused under -as-of, got %v", args, err)
+		}
+	}
+	if err := (writeBackChange{Key: "ABC-6", Rule: "breach", Transition: "Close"}).apply(nil); err == nil {
+		t.Errorf("expected write-back refused under -as-of")
+	}
+
+	asOf = time.Time{}
+	inputFile = "export.json"
+	defer func() { inputFile = "" }()
+	if err := runNudge([]string{"-post"}); err == nil || !strings.Contains(err.Error(), "-file") {
+		t.Errorf("expected writes refused from an export, got %v", err)
+	}
+}
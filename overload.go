@@ -0,0 +1,219 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// OverloadConfig enables and tunes the overload report. It is off unless
+// enabled, and only ever shows team aggregates, never individuals.
+type OverloadConfig struct {
+	Enabled bool `json:"enabled"`
+	// WorkdayStart and WorkdayEnd bound working hours (default 8 to 18) in
+	// the timezone of JIRA's timestamps:
+	WorkdayStart int `json:"workdayStart"`
+	WorkdayEnd   int `json:"workdayEnd"`
+	// WIPLimit is the in-flight items per person beyond which they count as
+	// overloaded; default 3.
+	WIPLimit int `json:"wipLimit"`
+	// MinGroup is the fewest people a group needs for its signals to be
+	// shown, so no one can be singled out; default 3.
+	MinGroup int `json:"minGroup"`
+}
+
+func (c *OverloadConfig) workday() (start, end int) {
+	start, end = c.WorkdayStart, c.WorkdayEnd
+	if start == 0 && end == 0 {
+		start, end = 8, 18
+	}
+	return start, end
+}
+
+func (c *OverloadConfig) wipLimit() int {
+	if c.WIPLimit > 0 {
+		return c.WIPLimit
+	}
+	return 3
+}
+
+func (c *OverloadConfig) minGroup() int {
+	if c.MinGroup > 0 {
+		return c.MinGroup
+	}
+	return 3
+}
+
+// Thresholds at which a group's signal is flagged:
+const (
+	overloadAfterHoursShare = 0.2
+	overloadWeekendShare    = 0.1
+	overloadOverWIPShare    = 0.5
+)
+
+// overloadStats aggregates one team's overload signals:
+type overloadStats struct {
+	Team   string
+	People int
+	// OverWIP counts people over the WIP limit:
+	OverWIP int
+	// Changes, AfterHours and Weekend count the team's changelog activity:
+	Changes    int
+	AfterHours int
+	Weekend    int
+	// WeekendDays counts person-days with weekend activity:
+	WeekendDays int
+}
+
+func share(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+// Flags lists the signals at or over their thresholds:
+func (s *overloadStats) Flags() []string {
+	var flags []string
+	if share(s.OverWIP, s.People) >= overloadOverWIPShare {
+		flags = append(flags, "WIP")
+	}
+	if share(s.AfterHours, s.Changes) >= overloadAfterHoursShare {
+		flags = append(flags, "after hours")
+	}
+	if share(s.Weekend, s.Changes) >= overloadWeekendShare {
+		flags = append(flags, "weekends")
+	}
+	return flags
+}
+
+// overloadSignals aggregates per team, people outside any team together, the
+// WIP and out-of-hours activity of everyone active since the given time.
+func overloadSignals(issues []Issue, since time.Time, c *OverloadConfig) []*overloadStats {
+	start, end := c.workday()
+	type person struct {
+		wip        int
+		weekendDay map[string]bool
+	}
+	people := make(map[string]*person)
+	byTeam := make(map[string]*overloadStats)
+	get := func(u User) (*person, *overloadStats) {
+		name := users.Name(u)
+		team := teamOf(u)
+		if team == "" {
+			team = "(no team)"
+		}
+		s, ok := byTeam[team]
+		if !ok {
+			s = &overloadStats{Team: team}
+			byTeam[team] = s
+		}
+		p, ok := people[name]
+		if !ok {
+			p = &person{weekendDay: make(map[string]bool)}
+			people[name] = p
+			s.People++
+		}
+		return p, s
+	}
+
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		for _, h := range issue.Changelog.Histories {
+			if h.Created.Before(since) || isBot(h.Author) || users.Name(h.Author) == "" {
+				continue
+			}
+			p, s := get(h.Author)
+			s.Changes++
+			switch h.Created.Weekday() {
+			case time.Saturday, time.Sunday:
+				s.Weekend++
+				day := h.Created.Format("2006-01-02")
+				if !p.weekendDay[day] {
+					p.weekendDay[day] = true
+					s.WeekendDays++
+				}
+			default:
+				if hour := h.Created.Hour(); hour < start || hour >= end {
+					s.AfterHours++
+				}
+			}
+		}
+
+		if _, done := issue.CompletedTime(); done {
+			continue
+		}
+		if _, ok := issue.StartedTime(); ok {
+			if u := issue.responsible(); users.Name(u) != "" {
+				p, s := get(u)
+				p.wip++
+				if p.wip == c.wipLimit()+1 {
+					s.OverWIP++
+				}
+			}
+		}
+	}
+
+	stats := make([]*overloadStats, 0, len(byTeam))
+	for _, s := range byTeam {
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Team < stats[j].Team })
+	return stats
+}
+
+func runOverload(args []string) error {
+	fs := flag.NewFlagSet("overload", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting issues; default covers issues updated in the last -weeks")
+	weeks := fs.Int("weeks", 4, "weeks of changelog activity to measure")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !config.Overload.Enabled {
+		return fmt.Errorf("overload signals are opt-in; agree their use with the teams, then set overload.enabled in the config")
+	}
+	if *weeks < 1 {
+		return fmt.Errorf("-weeks must be at least 1")
+	}
+
+	defaultFilter := fmt.Sprintf("updated >= -%dd OR resolution is EMPTY", 7**weeks)
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("overload", *jql, defaultFilter))
+	if err != nil {
+		return err
+	}
+
+	c := &config.Overload
+	start, end := c.workday()
+	stats := overloadSignals(issues, reportNow().AddDate(0, 0, -7**weeks), c)
+
+	fmt.Printf("Overload signals by team over the last %s (teams of under %d people not shown):\n", plural(*weeks, "week"), c.minGroup())
+	fmt.Printf("  %s %6s %13s %11s %8s %12s\n", padRight("team", nameWidth(20)), "people", fmt.Sprintf("over %d WIP", c.wipLimit()), "after hours", "weekend", "weekend days")
+	hidden := 0
+	for _, s := range stats {
+		if s.People < c.minGroup() {
+			hidden++
+			continue
+		}
+		flags := ""
+		for i, signal := range s.Flags() {
+			if i == 0 {
+				flags = "  ⚠ "
+			} else {
+				flags += ", "
+			}
+			flags += signal
+		}
+		fmt.Printf("  %s %6d %13d %10.0f%% %7.0f%% %12d%s\n",
+			padRight(s.Team, nameWidth(20)), s.People, s.OverWIP,
+			100*share(s.AfterHours, s.Changes), 100*share(s.Weekend, s.Changes), s.WeekendDays, flags)
+	}
+	if hidden > 0 {
+		fmt.Printf("  (%s too small to show)\n", plural(hidden, "team"))
+	}
+	fmt.Printf("After hours is before %d:00 or from %d:00 on weekdays; shares are of all changes the team made.\n", start, end)
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOverloadSignals(t *testing.T) {
+	config = &Config{Teams: map[string][]string{"core": {"alice", "bob", "carol"}}}
+	users = newUserRegistry()
+	defer func() {
+		config = &Config{}
+		users = newUserRegistry()
+	}()
+
+	alice := User{UserName: "alice"}
+	bob := User{UserName: "bob"}
+	dave := User{UserName: "dave"}
+	change := func(author User, at time.Time) History {
+		return History{Created: zonedTimestamp{at}, Author: author, Items: []HistoryItem{{Field: "status", ToString: inProgressStatus}}}
+	}
+
+	var issues []Issue
+	// alice has two items in flight against a limit of one:
+	for _, key := range []string{"A-1", "A-2"} {
+		issue := Issue{Key: key}
+		issue.Fields.Assignee = &alice
+		issue.Changelog.Histories = []History{change(alice, time.Date(2018, 11, 5, 9, 0, 0, 0, cst))}
+		issues = append(issues, issue)
+	}
+	// bob works a Saturday twice and a weekday evening; dave isn't in a team:
+	issue := Issue{Key: "A-3"}
+	issue.Fields.Status.StatusCategory.Key = "done"
+	issue.Fields.ResolutionDate.Time = time.Date(2018, 11, 12, 9, 0, 0, 0, cst)
+	issue.Changelog.Histories = []History{
+		change(bob, time.Date(2018, 11, 10, 10, 0, 0, 0, cst)),
+		change(bob, time.Date(2018, 11, 10, 11, 0, 0, 0, cst)),
+		change(bob, time.Date(2018, 11, 7, 21, 0, 0, 0, cst)),
+		change(dave, time.Date(2018, 11, 8, 9, 0, 0, 0, cst)),
+		// before the window:
+		change(bob, time.Date(2018, 10, 6, 10, 0, 0, 0, cst)),
+	}
+	issues = append(issues, issue)
+	users.registerUsers(issues)
+
+	stats := overloadSignals(issues, time.Date(2018, 11, 1, 0, 0, 0, 0, cst), &OverloadConfig{WIPLimit: 1})
+	if len(stats) != 2 || stats[0].Team != "(no team)" || stats[1].Team != "core" {
+		t.Fatalf("expected (no team) and core, got %v", stats)
+	}
+	if s := stats[0]; s.People != 1 || s.Changes != 1 || s.AfterHours != 0 || s.Weekend != 0 {
+		t.Fatalf("expected dave's one working-hours change, got %+v", s)
+	}
+	core := stats[1]
+	if core.People != 2 || core.OverWIP != 1 {
+		t.Fatalf("expected 2 people with 1 over WIP, got %+v", core)
+	}
+	if core.Changes != 5 || core.AfterHours != 1 || core.Weekend != 2 || core.WeekendDays != 1 {
+		t.Fatalf("expected 5 changes, 1 after hours, 2 on 1 weekend day, got %+v", core)
+	}
+	if flags := strings.Join(core.Flags(), ","); flags != "WIP,after hours,weekends" {
+		t.Fatalf("expected every signal flagged, got %s", flags)
+	}
+}
+
+func TestRunOverload_OptIn(t *testing.T) {
+	config = &Config{}
+	err := runOverload(nil)
+	if err == nil || !strings.Contains(err.Error(), "opt-in") {
+		t.Fatalf("expected opt-in error, got %v", err)
+	}
+}
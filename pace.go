@@ -0,0 +1,189 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kinds of off-hours activity, a holiday taking precedence over a weekend:
+const (
+	offHoliday    = "holiday"
+	offWeekend    = "weekend"
+	offAfterHours = "after hours"
+)
+
+// offHours classifies when activity happened: on a holiday, on a weekend,
+// outside the workday, or "" during working hours.
+func offHours(t time.Time, start, end int, holidays map[string]bool) string {
+	switch {
+	case holidays[t.Format("2006-01-02")]:
+		return offHoliday
+	case t.Weekday() == time.Saturday || t.Weekday() == time.Sunday:
+		return offWeekend
+	case t.Hour() < start || t.Hour() >= end:
+		return offAfterHours
+	}
+	return ""
+}
+
+func configHolidays() (map[string]bool, error) {
+	holidays := make(map[string]bool, len(config.Holidays))
+	if config.HolidayCalendar != "" {
+		calendar, err := calendarHolidays(config.HolidayCalendar)
+		if err != nil {
+			return nil, fmt.Errorf("holidayCalendar: %v", err)
+		}
+		for day := range calendar {
+			holidays[day] = true
+		}
+	}
+	for _, day := range config.Holidays {
+		holidays[day] = true
+	}
+	return holidays, nil
+}
+
+// paceStats aggregates one team's activity by when it happened:
+type paceStats struct {
+	Team   string
+	People int
+	// Activity counts status transitions and comments; the rest count those
+	// made off hours:
+	Activity   int
+	AfterHours int
+	Weekend    int
+	Holiday    int
+}
+
+// OffHours is all activity outside working days and hours:
+func (s *paceStats) OffHours() int {
+	return s.AfterHours + s.Weekend + s.Holiday
+}
+
+// paceByTeam aggregates per team, people outside any team together, the
+// status transitions and comments people made since the given time by when
+// they made them.
+func paceByTeam(issues []Issue, since time.Time, start, end int, holidays map[string]bool) []*paceStats {
+	people := make(map[string]bool)
+	byTeam := make(map[string]*paceStats)
+	count := func(u User, at time.Time) {
+		name := users.Name(u)
+		if at.Before(since) || name == "" || isBot(u) {
+			return
+		}
+		team := teamOf(u)
+		if team == "" {
+			team = "(no team)"
+		}
+		s, ok := byTeam[team]
+		if !ok {
+			s = &paceStats{Team: team}
+			byTeam[team] = s
+		}
+		if !people[name] {
+			people[name] = true
+			s.People++
+		}
+		s.Activity++
+		switch offHours(at, start, end, holidays) {
+		case offHoliday:
+			s.Holiday++
+		case offWeekend:
+			s.Weekend++
+		case offAfterHours:
+			s.AfterHours++
+		}
+	}
+
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		for _, ev := range issue.StatusTransitions() {
+			count(ev.Author, ev.Time)
+		}
+		if issue.Fields.Comment != nil {
+			for _, c := range issue.Fields.Comment.Comments {
+				count(c.Author, c.Created.Time)
+			}
+		}
+	}
+
+	stats := make([]*paceStats, 0, len(byTeam))
+	for _, s := range byTeam {
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Team < stats[j].Team })
+	return stats
+}
+
+func runPace(args []string) error {
+	fs := flag.NewFlagSet("pace", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting issues; default covers issues updated in the last -weeks")
+	weeks := fs.Int("weeks", 4, "weeks of activity to measure")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *weeks < 1 {
+		return fmt.Errorf("-weeks must be at least 1")
+	}
+
+	holidays, err := configHolidays()
+	if err != nil {
+		return err
+	}
+
+	defaultFilter := fmt.Sprintf("updated >= -%dd", 7**weeks)
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("pace", *jql, defaultFilter))
+	if err != nil {
+		return err
+	}
+
+	c := &config.Overload
+	start, end := c.workday()
+	stats := paceByTeam(issues, reportNow().AddDate(0, 0, -7**weeks), start, end, holidays)
+
+	fmt.Printf("Transitions and comments made off hours by team over the last %s (teams of under %d people not shown):\n", plural(*weeks, "week"), c.minGroup())
+	fmt.Printf("  %s %6s %8s %11s %8s %8s %9s\n", padRight("team", nameWidth(20)), "people", "activity", "after hours", "weekend", "holiday", "off hours")
+	hidden := 0
+	total := &paceStats{}
+	for _, s := range stats {
+		total.People += s.People
+		total.Activity += s.Activity
+		total.AfterHours += s.AfterHours
+		total.Weekend += s.Weekend
+		total.Holiday += s.Holiday
+		if s.People < c.minGroup() {
+			hidden++
+			continue
+		}
+		fmt.Printf("  %s %6d %8d %10.0f%% %7.0f%% %7.0f%% %8.0f%%\n",
+			padRight(s.Team, nameWidth(20)), s.People, s.Activity,
+			100*share(s.AfterHours, s.Activity), 100*share(s.Weekend, s.Activity),
+			100*share(s.Holiday, s.Activity), 100*share(s.OffHours(), s.Activity))
+	}
+	if hidden > 0 {
+		fmt.Printf("  (%s too small to show)\n", plural(hidden, "team"))
+	}
+	if total.People >= c.minGroup() {
+		fmt.Printf("Off hours overall: %.0f%% of %s. ", 100*share(total.OffHours(), total.Activity), plural(total.Activity, "change"))
+	}
+	fmt.Printf("After hours is before %d:00 or from %d:00 on weekdays", start, end)
+	if config.HolidayCalendar != "" {
+		fmt.Printf("; holidays are %s's", strings.ToUpper(config.HolidayCalendar))
+		if len(config.Holidays) > 0 {
+			fmt.Printf(" and %s more", plural(len(config.Holidays), "day"))
+		}
+	} else if len(config.Holidays) == 0 {
+		fmt.Printf("; no holidays configured")
+	}
+	fmt.Printf(".\n")
+
+	recordMetric("activity", total.Activity)
+	recordMetric("offHoursShare", share(total.OffHours(), total.Activity))
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPaceByTeam(t *testing.T) {
+	config = &Config{Teams: map[string][]string{"core": {"alice", "bob"}}}
+	users = newUserRegistry()
+	defer func() {
+		config = &Config{}
+		users = newUserRegistry()
+	}()
+
+	alice := User{UserName: "alice"}
+	bob := User{UserName: "bob"}
+	robot := User{UserName: "robot", AccountType: "app"}
+	change := func(author User, at time.Time) History {
+		return History{Created: zonedTimestamp{at}, Author: author, Items: []HistoryItem{{Field: "status", ToString: inProgressStatus}}}
+	}
+
+	issue := Issue{Key: "A-1"}
+	issue.Changelog.Histories = []History{
+		change(alice, time.Date(2018, 11, 5, 9, 0, 0, 0, cst)),
+		// Thanksgiving:
+		change(bob, time.Date(2018, 11, 22, 10, 0, 0, 0, cst)),
+		// before the period:
+		change(bob, time.Date(2018, 10, 1, 22, 0, 0, 0, cst)),
+		change(robot, time.Date(2018, 11, 10, 3, 0, 0, 0, cst)),
+	}
+	issue.Fields.Comment = &CommentPage{Comments: []Comment{
+		{Author: alice, Created: zonedTimestamp{time.Date(2018, 11, 7, 21, 0, 0, 0, cst)}},
+		{Author: bob, Created: zonedTimestamp{time.Date(2018, 11, 10, 12, 0, 0, 0, cst)}},
+	}}
+
+	holidays := map[string]bool{"2018-11-22": true}
+	stats := paceByTeam([]Issue{issue}, time.Date(2018, 11, 1, 0, 0, 0, 0, cst), 8, 18, holidays)
+	if len(stats) != 1 || stats[0].Team != "core" {
+		t.Fatalf("expected only core, got %d teams", len(stats))
+	}
+	s := stats[0]
+	if s.People != 2 || s.Activity != 4 || s.AfterHours != 1 || s.Weekend != 1 || s.Holiday != 1 || s.OffHours() != 3 {
+		t.Fatalf("expected 3 of 4 changes off hours, one of each kind, got %+v", *s)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// ChangelogTruncated reports whether the issue is analyzed with only part of
+// its history because the rest couldn't be fetched:
+func (issue *Issue) ChangelogTruncated() bool {
+	return len(issue.Changelog.Histories) < issue.Changelog.Total
+}
+
+// pageChangelog fetches the rest of a changelog longer than the issue embeds.
+// A page that fails leaves the changelog truncated rather than failing the
+// report.
+func pageChangelog(cl *http.Client, issue *Issue) {
+	changelog := &issue.Changelog
+	// Size for the whole history up front rather than regrowing per page:
+	if cap(changelog.Histories) < changelog.Total {
+		histories := make([]History, len(changelog.Histories), changelog.Total)
+		copy(histories, changelog.Histories)
+		changelog.Histories = histories
+	}
+	for len(changelog.Histories) < changelog.Total {
+		startAt := len(changelog.Histories)
+		url := fmt.Sprintf("%s/rest/api/2/issue/%s/changelog?startAt=%d", setting(cl, "JIRA_URL"), issue.Key, startAt)
+		page, err := fetchChangelogPage(cl, fmt.Sprintf("issue.%s.changelog.%d.json", issue.Key, startAt), url)
+		if isPermissionError(err) {
+			fetched.hideChangelog(issue.Key)
+			return
+		}
+		if err != nil {
+			fetched.truncateChangelog(issue.Key, err)
+			return
+		}
+		if len(page.Values) == 0 {
+			return
+		}
+		changelog.Histories = append(changelog.Histories, page.Values...)
+		changelog.Total = page.Total
+	}
+}
+
+// completeChangelogs pages the changelogs searches cut short; they embed only
+// the first 100 changes of each issue.
+func completeChangelogs(cl *http.Client, issues []Issue) {
+	for i := range issues {
+		if issues[i].ChangelogTruncated() {
+			pageChangelog(cl, &issues[i])
+			progress.Update("changelogs", i+1, len(issues), "issues", "")
+		}
+	}
+}
+
+// truncateChangelog records an issue analyzed with only part of its history:
+func (s *fetchStats) truncateChangelog(key string, err error) {
+	log.Printf("warning: %s changelog truncated: %v\n", key, err)
+	s.Truncated = append(s.Truncated, key)
+}
+
+// gap records data a report goes without, so it is produced anyway and says
+// what's missing:
+func (s *fetchStats) gap(format string, args ...interface{}) {
+	note := fmt.Sprintf(format, args...)
+	log.Printf("warning: %s\n", note)
+	s.Gaps = append(s.Gaps, note)
+}
+
+// partialNotes describes what failed fetches left out, for the footer:
+func (s *fetchStats) partialNotes() []string {
+	var notes []string
+	if len(s.Truncated) > 0 {
+		notes = append(notes, "changelogs of "+plural(len(s.Truncated), "issue")+" truncated")
+	}
+	if len(s.Migrated) > 0 {
+		use := "excluded from percentiles"
+		if includeMigrated {
+			use = "included in percentiles"
+		}
+		notes = append(notes, plural(len(s.Migrated), "migrated issue")+" partially trusted, "+use)
+	}
+	return append(notes, s.Gaps...)
+}
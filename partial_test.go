@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJiraSource_PartialData(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/rest/agile/1.0/board/7/issue") && r.URL.Query().Get("startAt") == "0":
+			w.Write([]byte(`{"startAt": 0, "maxResults": 2, "total": 3, "issues": [
+				{"key": "ABC-1", "fields": {"summary": "Long history"}, "changelog": {"startAt": 0, "maxResults": 1, "total": 2, "histories": [
+					{"created": "2018-11-01T09:00:00.000-0500", "items": [{"field": "status", "fromString": "Open", "toString": "In Progress"}]}
+				]}},
+				{"key": "ABC-2", "fields": {"summary": "Short history"}, "changelog": {"startAt": 0, "maxResults": 100, "total": 0, "histories": []}}
+			]}`))
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+	fetched = fetchStats{}
+	defer func() { fetched = fetchStats{} }()
+
+	issues, err := jiraSource{}.FetchIssues(srv.Client(), 7, "issue", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected the 2 issues of the first page, got %d", len(issues))
+	}
+	if !issues[0].ChangelogTruncated() || issues[1].ChangelogTruncated() {
+		t.Fatalf("expected only ABC-1 truncated")
+	}
+	if len(fetched.Truncated) != 1 || fetched.Truncated[0] != "ABC-1" || len(fetched.Gaps) != 1 {
+		t.Fatalf("expected ABC-1 truncated and the last page missing, got %q %q", fetched.Truncated, fetched.Gaps)
+	}
+
+	footer := fetched.footer("JIRA 7.13.0")
+	if !strings.Contains(footer, "changelogs of 1 issue truncated") || !strings.Contains(footer, "1 of 3 issues not fetched") {
+		t.Fatalf("expected the gaps in the footer, got %s", footer)
+	}
+	if badges := issueBadges(&issues[0], false, time.Date(2018, 11, 2, 9, 0, 0, 0, cst)); !strings.Contains(badges, "✂️") {
+		t.Fatalf("expected the truncated badge, got %q", badges)
+	}
+}
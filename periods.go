@@ -0,0 +1,134 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// period is a range of days, From through To inclusive:
+type period struct {
+	From, To time.Time
+}
+
+// parsePeriod parses "2024-01-01..2024-03-31" in the report time zone:
+func parsePeriod(name, s string) (period, error) {
+	parts := strings.Split(s, "..")
+	if len(parts) != 2 {
+		return period{}, fmt.Errorf("-%s: expected yyyy-mm-dd..yyyy-mm-dd, got '%s'", name, s)
+	}
+	loc := displayTime(reportNow()).Location()
+	from, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(parts[0]), loc)
+	if err != nil {
+		return period{}, fmt.Errorf("-%s: %v", name, err)
+	}
+	to, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(parts[1]), loc)
+	if err != nil {
+		return period{}, fmt.Errorf("-%s: %v", name, err)
+	}
+	if to.Before(from) {
+		return period{}, fmt.Errorf("-%s: %s is before %s", name, parts[1], parts[0])
+	}
+	return period{From: from, To: to}, nil
+}
+
+func (p period) contains(t time.Time) bool {
+	return !t.Before(p.From) && t.Before(p.To.AddDate(0, 0, 1))
+}
+
+func (p period) String() string {
+	return p.From.Format("Jan 02 2006") + "–" + p.To.Format("Jan 02 2006")
+}
+
+// periodCycleTimes are the cycle times of the work completed in the period,
+// sorted:
+func periodCycleTimes(issues []Issue, p period) []int {
+	var cycleTimes []int
+	for i := range issues {
+		issue := &issues[i]
+		completed, ok := issue.CompletedTime()
+		if !ok || issue.IsEpic() || !p.contains(displayTime(completed)) {
+			continue
+		}
+		if days, ok := issue.CycleTime(); ok {
+			cycleTimes = append(cycleTimes, days)
+		}
+	}
+	return sortedCopy(cycleTimes)
+}
+
+func runComparePeriods(args []string) error {
+	fs := flag.NewFlagSet("compare-periods", flag.ContinueOnError)
+	beforeFlag := fs.String("before", "", "the period before the change, e.g. 2024-01-01..2024-03-31")
+	afterFlag := fs.String("after", "", "the period after the change, e.g. 2024-04-01..2024-06-30")
+	alpha := fs.Float64("alpha", 0.05, "significance level")
+	jql := fs.String("jql", "", "JQL filter selecting completed issues; default covers both periods")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *beforeFlag == "" || *afterFlag == "" {
+		return fmt.Errorf("usage: %s compare-periods -before from..to -after from..to [-alpha level] [-jql filter] [boardId]", programName())
+	}
+	before, err := parsePeriod("before", *beforeFlag)
+	if err != nil {
+		return err
+	}
+	after, err := parsePeriod("after", *afterFlag)
+	if err != nil {
+		return err
+	}
+	if *alpha <= 0 || *alpha >= 1 {
+		return fmt.Errorf("-alpha must be between 0 and 1")
+	}
+
+	cl := httpClient()
+	boardId := boardArg(fs.Args())
+	since := before.From
+	if after.From.Before(since) {
+		since = after.From
+	}
+	var issues []Issue
+	if *jql != "" {
+		issues, err = fetchBoardIssues(cl, boardId, *jql)
+	} else {
+		issues, err = fetchCompletedSince(cl, boardId, "compare-periods", since)
+	}
+	if err != nil {
+		return err
+	}
+
+	a, b := periodCycleTimes(issues, before), periodCycleTimes(issues, after)
+	fmt.Printf("Cycle times in business days of the work completed before (%s) and after (%s):\n", before, after)
+	fmt.Printf("  %-10s %7s %7s\n", "", "before", "after")
+	fmt.Printf("  %-10s %7s %7s\n", "items", sampleSize(len(a)), sampleSize(len(b)))
+	if len(a) == 0 || len(b) == 0 {
+		fmt.Printf("Nothing to compare: both periods need completed items with cycle times.\n")
+		return nil
+	}
+	for _, p := range []float64{50, 85} {
+		fmt.Printf("  %-10s %6dd %6dd\n", percentileLabel(p), percentile(a, p), percentile(b, p))
+	}
+	fmt.Printf("  %-10s %6.1fd %6.1fd\n", "mean", mean(a), mean(b))
+
+	// below is the chance an item before took less time than one after:
+	below, pValue := mannWhitney(a, b)
+	faster := 1 - below
+	fmt.Printf("\nMann-Whitney U test: p = %.3f. An item after took less time than one before %.0f%% of the time (ties counting half).\n", pValue, 100*faster)
+	switch {
+	case pValue >= *alpha:
+		fmt.Printf("The difference is not statistically significant at the %g level; it may be chance.\n", *alpha)
+	case faster > 0.5:
+		fmt.Printf("Cycle times after are significantly shorter at the %g level.\n", *alpha)
+	default:
+		fmt.Printf("Cycle times after are significantly longer at the %g level.\n", *alpha)
+	}
+	if len(a) < smallSample || len(b) < smallSample {
+		fmt.Printf("%s\n", smallSampleNote())
+	}
+
+	recordMetric("pValue", pValue)
+	recordMetric("probabilityFaster", faster)
+	recordMetric("cycleTimeP50", map[string]int{"before": percentile(a, 50), "after": percentile(b, 50)})
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodCycleTimes(t *testing.T) {
+	before, err := parsePeriod("before", "2018-10-01..2018-10-31")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parsePeriod("after", "2018-11-30..2018-11-01"); err == nil {
+		t.Fatalf("expected an error for a period ending before it starts")
+	}
+	if _, err := parsePeriod("after", "2018-11-01"); err == nil {
+		t.Fatalf("expected an error for a period without an end")
+	}
+
+	day := func(m time.Month, d int) time.Time { return time.Date(2018, m, d, 9, 0, 0, 0, time.UTC) }
+	issues := []Issue{
+		completedIssue("A-1", day(10, 1), day(10, 8)),
+		// Completed on the period's last day:
+		completedIssue("A-2", day(10, 29), day(10, 31)),
+		completedIssue("A-3", day(10, 29), day(11, 1)),
+	}
+	if got := periodCycleTimes(issues, before); len(got) != 2 || got[0] > got[1] {
+		t.Fatalf("expected the two cycle times completed in October, sorted, got %v", got)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SecurityLevel is an issue security level restricting who can see an issue:
+type SecurityLevel struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// isPermissionError reports whether err means permissions hide the resource:
+// JIRA answers 403, or 404 for issues "not found or not permitted".
+func isPermissionError(err error) bool {
+	var authErr *AuthError
+	var notFoundErr *NotFoundError
+	return errors.As(err, &notFoundErr) || errors.As(err, &authErr) && authErr.StatusCode == http.StatusForbidden
+}
+
+// hideIssue records an issue permissions kept out of the run:
+func (s *fetchStats) hideIssue(key string) {
+	log.Printf("warning: %s is not accessible; leaving it out\n", key)
+	s.Inaccessible = append(s.Inaccessible, key)
+}
+
+// hideChangelog records an issue analyzed without its full history:
+func (s *fetchStats) hideChangelog(key string) {
+	log.Printf("warning: %s changelog is not accessible; analyzing the history visible\n", key)
+	s.HiddenChangelogs = append(s.HiddenChangelogs, key)
+}
+
+// permissionNotes describes what permissions hid, for the footer:
+func (s *fetchStats) permissionNotes() []string {
+	var notes []string
+	if len(s.Inaccessible) > 0 {
+		notes = append(notes, plural(len(s.Inaccessible), "issue")+" not accessible")
+	}
+	if len(s.HiddenChangelogs) > 0 {
+		notes = append(notes, "changelogs of "+plural(len(s.HiddenChangelogs), "issue")+" not accessible")
+	}
+	if s.Invisible > 0 {
+		notes = append(notes, fmt.Sprintf("%s not visible to %s", plural(s.Invisible, "issue"), viewAs()))
+	}
+	return notes
+}
+
+// viewAs is the user whose visibility reports should match when they run as a
+// service account: $JIRA_VIEW_AS, or the profile's viewAs.
+func viewAs() string {
+	return os.Getenv("JIRA_VIEW_AS")
+}
+
+// canView asks JIRA whether user can see the issue:
+func canView(cl *http.Client, user string, key string) (bool, error) {
+	// Server takes a username, Cloud a query for an account ID or email:
+	query := url.Values{"issueKey": {key}, "username": {user}, "query": {user}}
+	url := setting(cl, "JIRA_URL") + "/rest/api/2/user/viewissue/search?" + query.Encode()
+	h := fnv.New32a()
+	h.Write([]byte(user))
+	body, err := cachedGet(fmt.Sprintf("viewissue.%08x.%s.json", h.Sum32(), key), url, cl)
+	if err != nil {
+		return false, err
+	}
+	defer body.Close()
+
+	var viewers []User
+	if err = decodeResponse(body, url, &viewers); err != nil {
+		return false, err
+	}
+	for _, viewer := range viewers {
+		for _, id := range identityKeys(viewer) {
+			if strings.EqualFold(id[strings.Index(id, ":")+1:], user) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// checkVisibility warns when the service account running a report sees issues
+// that the viewAs user can't, checking one issue per project and security
+// level since visibility is granted at those levels.
+func checkVisibility(cl *http.Client, issues []Issue, user string) {
+	groups := make(map[string][]*Issue)
+	for i := range issues {
+		issue := &issues[i]
+		group := issue.Fields.Project.Key
+		if issue.Fields.Security != nil {
+			group += " (security level " + issue.Fields.Security.Name + ")"
+		}
+		groups[group] = append(groups[group], issue)
+	}
+
+	var names []string
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+	for _, group := range names {
+		ok, err := canView(cl, user, groups[group][0].Key)
+		if err != nil {
+			log.Printf("warning: checking what %s can see: %v\n", user, err)
+			return
+		}
+		if !ok {
+			log.Printf("warning: %s can't see the %s issues of %s; this report shows more than they could\n", user, plural(len(groups[group]), "issue"), group)
+			fetched.Invisible += len(groups[group])
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCheckVisibility(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/user/viewissue/search" || r.URL.Query().Get("username") != "ann" {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("issueKey") {
+		case "ABC-1":
+			w.Write([]byte(`[{"name": "ann2"}, {"name": "Ann", "displayName": "Ann"}]`))
+		default:
+			w.Write([]byte(`[{"name": "ann2"}]`))
+		}
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+	os.Setenv("JIRA_VIEW_AS", "ann")
+	defer os.Unsetenv("JIRA_VIEW_AS")
+	fetched = fetchStats{}
+	defer func() { fetched = fetchStats{} }()
+
+	issues := []Issue{{Key: "ABC-1"}, {Key: "ABC-2"}, {Key: "ABC-3"}, {Key: "XYZ-1"}}
+	for i := range issues {
+		issues[i].Fields.Project.Key = strings.Split(issues[i].Key, "-")[0]
+	}
+	// ABC-3 is restricted by a security level Ann lacks:
+	issues[2].Fields.Security = &SecurityLevel{Id: "1", Name: "Legal"}
+
+	checkVisibility(srv.Client(), issues, "ann")
+	if fetched.Invisible != 2 {
+		t.Fatalf("expected ABC-3 and XYZ-1 invisible to ann, got %d", fetched.Invisible)
+	}
+
+	fetched.Inaccessible = []string{"ABC-9"}
+	notes := strings.Join(fetched.permissionNotes(), "; ")
+	if notes != "1 issue not accessible; 2 issues not visible to ann" {
+		t.Fatalf("unexpected footer notes %q", notes)
+	}
+}
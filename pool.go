@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// maxPooledBuffer bounds the buffers kept for reuse, so one huge response
+// doesn't pin its memory for the life of a server:
+const maxPooledBuffer = 8 << 20
+
+// bodyBuffers holds the buffers response bodies are read into for decoding;
+// server mode decodes the same pages over and over, and reusing them spares
+// the collector a body-sized allocation (and its regrowth) per page.
+var bodyBuffers = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// readBody reads a response body into a pooled buffer; pass it to
+// releaseBody once decoded. Decoding copies what it keeps, so nothing decoded
+// refers to the buffer.
+func readBody(r io.Reader) (*bytes.Buffer, error) {
+	buf := bodyBuffers.Get().(*bytes.Buffer)
+	buf.Reset()
+	if _, err := buf.ReadFrom(r); err != nil {
+		releaseBody(buf)
+		return nil, err
+	}
+	return buf, nil
+}
+
+func releaseBody(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	bodyBuffers.Put(buf)
+}
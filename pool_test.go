@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadBody(t *testing.T) {
+	buf, err := readBody(strings.NewReader(`{"key": "A-1"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != `{"key": "A-1"}` {
+		t.Fatalf("expected the body read, got %q", buf.String())
+	}
+	releaseBody(buf)
+
+	// A reused buffer holds only the new body:
+	buf, err = readBody(strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != `{}` {
+		t.Fatalf("expected a reset buffer, got %q", buf.String())
+	}
+	releaseBody(buf)
+}
+
+func TestDecodeResponse_PooledBufferNotRetained(t *testing.T) {
+	var first Issue
+	if err := decodeResponse(strings.NewReader(`{"key": "A-1", "fields": {"customfield_1": {"value": "x"}}}`), "test", &first); err != nil {
+		t.Fatal(err)
+	}
+	// Overwrite whatever buffer the pool hands out next:
+	buf, _ := readBody(bytes.NewReader(bytes.Repeat([]byte("#"), 64)))
+	releaseBody(buf)
+
+	if first.Key != "A-1" || first.Fields.CustomString("customfield_1") != "x" {
+		t.Fatalf("expected decoded values intact after buffer reuse, got %q, %q", first.Key, first.Fields.CustomString("customfield_1"))
+	}
+}
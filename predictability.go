@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type predictability struct {
+	Week    time.Time
+	Count   int
+	CV      float64
+	Within  float64
+	Median  int
+	Percent int
+}
+
+// measurePredictability scores cycle times: coefficient of variation and the
+// share of items completing within twice the median. The headline score is
+// that share as a percentage.
+func measurePredictability(cycleTimes []int) predictability {
+	sorted := sortedCopy(cycleTimes)
+	p := predictability{Count: len(sorted)}
+	if len(sorted) == 0 {
+		return p
+	}
+
+	p.Median = percentile(sorted, 50)
+	p.CV = coefficientOfVariation(sorted)
+
+	within := 0
+	for _, days := range sorted {
+		if days <= 2*p.Median {
+			within++
+		}
+	}
+	p.Within = float64(within) / float64(len(sorted))
+	p.Percent = int(100*p.Within + 0.5)
+	return p
+}
+
+type completedItem struct {
+	Completed time.Time
+	CycleTime int
+}
+
+// rollingPredictability scores each of the last weeks over a trailing window
+// of windowWeeks weeks of completions:
+func rollingPredictability(items []completedItem, now time.Time, weeks int, windowWeeks int) []predictability {
+	thisWeek := weekOf(now)
+
+	var trend []predictability
+	for w := weeks - 1; w >= 0; w-- {
+		weekStart := thisWeek.AddDate(0, 0, -7*w)
+		windowEnd := weekStart.AddDate(0, 0, 7)
+		windowStart := windowEnd.AddDate(0, 0, -7*windowWeeks)
+
+		var cycleTimes []int
+		for _, item := range items {
+			if !item.Completed.Before(windowStart) && item.Completed.Before(windowEnd) {
+				cycleTimes = append(cycleTimes, item.CycleTime)
+			}
+		}
+
+		p := measurePredictability(cycleTimes)
+		p.Week = weekStart
+		trend = append(trend, p)
+	}
+	return trend
+}
+
+func runPredictability(args []string) error {
+	fs := flag.NewFlagSet("predictability", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting completed issues; default those resolved within the weeks and their windows")
+	weeks := fs.Int("weeks", 12, "number of weeks of trend to show")
+	window := fs.Int("window", 8, "rolling window in weeks")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *weeks < 1 {
+		return fmt.Errorf("-weeks must be at least 1")
+	}
+	if *window < 1 {
+		return fmt.Errorf("-window must be at least 1")
+	}
+
+	cl := httpClient()
+	boardId := boardArg(fs.Args())
+	var issues []Issue
+	var err error
+	if *jql != "" {
+		issues, err = fetchBoardIssues(cl, boardId, *jql)
+	} else {
+		// The earliest week's window reaches back this far:
+		issues, err = fetchCompletedSince(cl, boardId, "predictability", reportNow().AddDate(0, 0, -7*(*weeks+*window)))
+	}
+	if err != nil {
+		return err
+	}
+
+	var items []completedItem
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		days, ok := issue.CycleTime()
+		if !ok {
+			continue
+		}
+		completed, _ := issue.CompletedTime()
+		items = append(items, completedItem{Completed: completed, CycleTime: days})
+	}
+
+	trend := rollingPredictability(items, reportNow(), *weeks, *window)
+	current := trend[len(trend)-1]
+	recordMetric("score", current.Percent)
+	recordMetric("medianCycleTime", current.Median)
+	recordMetric("cv", current.CV)
+	recordMetric("items", current.Count)
+
+	fmt.Printf(
+		"Predictability score: %d%% of items completed within 2x the median cycle time (%d days); CV %.2f over the last %d weeks (%d items)\n\n",
+		current.Percent,
+		2*current.Median,
+		current.CV,
+		*window,
+		current.Count,
+	)
+	fmt.Printf("Trend over rolling %d-week windows:\n", *window)
+	fmt.Printf("  %-10s %5s %6s %5s %5s\n", "week", "count", "median", "CV", "score")
+	changes := detectWorkflowChanges(issues)
+	for _, p := range trend {
+		mark := ""
+		if workflowChangedDuring(changes, p.Week, p.Week.AddDate(0, 0, 7)) {
+			mark = workflowChangeMark
+		}
+		fmt.Printf(
+			"  %-10s %5d %6d %5.2f %4d%% %s%s\n",
+			p.Week.Format("2006-01-02"),
+			p.Count,
+			p.Median,
+			p.CV,
+			p.Percent,
+			strings.Repeat("#", p.Percent/5),
+			mark,
+		)
+	}
+	printWorkflowChanges(changes)
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMeasurePredictability(t *testing.T) {
+	p := measurePredictability([]int{2, 2, 3, 4, 10})
+	if p.Median != 3 {
+		t.Fatalf("expected 3 for median, got %d", p.Median)
+	}
+	if p.Percent != 80 {
+		t.Fatalf("expected 80 for score, got %d", p.Percent)
+	}
+	if math.Abs(p.CV-0.7127) > 0.001 {
+		t.Fatalf("expected 0.713 for CV, got %v", p.CV)
+	}
+}
+
+func TestRollingPredictability_Window(t *testing.T) {
+	now := time.Date(2018, 11, 7, 12, 0, 0, 0, time.UTC)
+	items := []completedItem{
+		{Completed: time.Date(2018, 11, 6, 0, 0, 0, 0, time.UTC), CycleTime: 1},
+		{Completed: time.Date(2018, 10, 30, 0, 0, 0, 0, time.UTC), CycleTime: 2},
+		{Completed: time.Date(2018, 10, 1, 0, 0, 0, 0, time.UTC), CycleTime: 3},
+	}
+
+	trend := rollingPredictability(items, now, 2, 2)
+	if len(trend) != 2 {
+		t.Fatalf("expected 2 for weeks, got %d", len(trend))
+	}
+	if trend[0].Count != 1 || trend[1].Count != 2 {
+		t.Fatalf("expected 1 then 2 items per window, got %d and %d", trend[0].Count, trend[1].Count)
+	}
+}
+
+func TestRunPredictability_RejectsEmptyRanges(t *testing.T) {
+	for _, args := range [][]string{{"-weeks", "0"}, {"-window", "-1"}} {
+		if err := runPredictability(args); err == nil {
+			t.Errorf("expected %v rejected before fetching", args)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// priorityRank orders priorities by ID; JIRA's default scheme numbers them from
+// the highest priority (1) down. Issues without a priority sort last.
+func (issue *Issue) priorityRank() int {
+	if issue.Fields.Priority == nil {
+		return 1 << 30
+	}
+	rank, err := strconv.Atoi(issue.Fields.Priority.Id)
+	if err != nil {
+		return 1 << 30
+	}
+	return rank
+}
+
+func (issue *Issue) priorityName() string {
+	if issue.Fields.Priority == nil {
+		return "(none)"
+	}
+	return issue.Fields.Priority.Name
+}
+
+type overtake struct {
+	Overtaken *Issue
+	By        []*Issue
+}
+
+// findOvertakes finds issues that lower-priority issues overtook: the lower
+// priority issue was started after the higher priority issue was created and
+// completed before it (or before now, if the higher one is still open).
+func findOvertakes(issues []*Issue, now time.Time) []*overtake {
+	var overtakes []*overtake
+	for _, high := range issues {
+		highDone, ok := high.CompletedTime()
+		if !ok {
+			highDone = now
+		}
+
+		o := &overtake{Overtaken: high}
+		for _, low := range issues {
+			if low.priorityRank() <= high.priorityRank() {
+				continue
+			}
+			lowStarted, ok := low.StartedTime()
+			if !ok || !lowStarted.After(high.Fields.Created.Time) {
+				continue
+			}
+			lowDone, ok := low.CompletedTime()
+			if !ok || !lowDone.Before(highDone) {
+				continue
+			}
+			o.By = append(o.By, low)
+		}
+
+		if len(o.By) > 0 {
+			overtakes = append(overtakes, o)
+		}
+	}
+
+	sort.SliceStable(overtakes, func(i, j int) bool {
+		ri, rj := overtakes[i].Overtaken.priorityRank(), overtakes[j].Overtaken.priorityRank()
+		if ri != rj {
+			return ri < rj
+		}
+		return len(overtakes[i].By) > len(overtakes[j].By)
+	})
+	return overtakes
+}
+
+func runPriority(args []string) error {
+	fs := flag.NewFlagSet("priority", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting recently completed and open issues; default='resolved >= -90d OR resolution is EMPTY'")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("priority", *jql, "resolved >= -90d OR resolution is EMPTY"))
+	if err != nil {
+		return err
+	}
+
+	// Group cycle times by priority:
+	var candidates []*Issue
+	cycleTimes := make(map[int][]int)
+	names := make(map[int]string)
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		candidates = append(candidates, issue)
+
+		days, ok := issue.CycleTime()
+		if !ok {
+			continue
+		}
+		rank := issue.priorityRank()
+		cycleTimes[rank] = append(cycleTimes[rank], days)
+		names[rank] = issue.priorityName()
+	}
+
+	ranks := make([]int, 0, len(cycleTimes))
+	for rank := range cycleTimes {
+		ranks = append(ranks, rank)
+	}
+	sort.Ints(ranks)
+
+	fmt.Printf("Cycle time in business days by priority:\n")
+	fmt.Printf("  %-12s %5s", "priority", "count")
+	for _, p := range percentiles {
+		fmt.Printf(" %5s", percentileLabel(p))
+	}
+	fmt.Printf(" %6s\n", "mean")
+	for _, rank := range ranks {
+		sorted := sortedCopy(cycleTimes[rank])
+		fmt.Printf("  %-12s %5d", names[rank], len(sorted))
+		for _, p := range percentiles {
+			fmt.Printf(" %5d", percentile(sorted, p))
+		}
+		fmt.Printf(" %6.1f\n", mean(sorted))
+	}
+
+	overtakes := findOvertakes(candidates, reportNow())
+	fmt.Printf("\nOvertaken by lower-priority work: %d issues\n", len(overtakes))
+	shown := detailLines(len(overtakes))
+	for _, o := range overtakes[:shown] {
+		keys := make([]string, 0, len(o.By))
+		for _, by := range o.By {
+			keys = append(keys, fmt.Sprintf("%s (%s)", by.Key, by.priorityName()))
+		}
+		fmt.Printf(
+			"  %s (%s): overtaken by %d: %s; %s\n",
+			o.Overtaken.Key,
+			o.Overtaken.priorityName(),
+			len(o.By),
+			strings.Join(keys, ", "),
+			o.Overtaken.DisplaySummary(),
+		)
+	}
+	printOmitted("  ", shown, len(overtakes))
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindOvertakes_LowerPriorityFinishedFirst(t *testing.T) {
+	blocker := completedIssue("A-1", time.Date(2018, 11, 12, 9, 0, 0, 0, cst), time.Date(2018, 11, 16, 9, 0, 0, 0, cst))
+	blocker.Fields.Priority = &Priority{Id: "1", Name: "Blocker"}
+	blocker.Fields.Created.Time = time.Date(2018, 11, 1, 9, 0, 0, 0, cst)
+
+	minor := completedIssue("A-2", time.Date(2018, 11, 5, 9, 0, 0, 0, cst), time.Date(2018, 11, 7, 9, 0, 0, 0, cst))
+	minor.Fields.Priority = &Priority{Id: "4", Name: "Minor"}
+
+	// started before the blocker was created:
+	early := completedIssue("A-3", time.Date(2018, 10, 29, 9, 0, 0, 0, cst), time.Date(2018, 11, 2, 9, 0, 0, 0, cst))
+	early.Fields.Priority = &Priority{Id: "4", Name: "Minor"}
+
+	overtakes := findOvertakes([]*Issue{&blocker, &minor, &early}, time.Date(2018, 11, 20, 0, 0, 0, 0, cst))
+	if len(overtakes) != 1 {
+		t.Fatalf("expected 1 for overtakes, got %d", len(overtakes))
+	}
+	if overtakes[0].Overtaken.Key != "A-1" || len(overtakes[0].By) != 1 || overtakes[0].By[0].Key != "A-2" {
+		t.Fatalf("expected A-1 overtaken by A-2, got %s by %d", overtakes[0].Overtaken.Key, len(overtakes[0].By))
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progressLine keeps a single updating status line on a terminal, e.g.
+// "board 4454: 300/1200 issues, page 6, ETA 12s". Other writes to the
+// terminal, such as log output, clear it first and redraw it after.
+type progressLine struct {
+	mu      sync.Mutex
+	out     *os.File
+	enabled bool
+	// lines writes each update as a "progress: " line instead, for a server
+	// running the report as a job to follow:
+	lines bool
+
+	label   string
+	done    int
+	total   int
+	unit    string
+	detail  string
+	started time.Time
+	drawn   time.Time
+	shown   int
+}
+
+// progressRedraw limits how often the line is redrawn, and
+// progressLinesEvery how often progress lines are written:
+const (
+	progressRedraw     = 100 * time.Millisecond
+	progressLinesEvery = time.Second
+)
+
+var progress = &progressLine{out: os.Stderr}
+
+func isTerminal(f *os.File) bool {
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// enableProgress shows progress when stderr is a terminal, routing log output
+// through the progress line:
+func enableProgress() {
+	if os.Getenv("JIRA_PROGRESS") == "lines" {
+		progress.enabled, progress.lines = true, true
+		return
+	}
+	if !isTerminal(progress.out) {
+		return
+	}
+	progress.enabled = true
+	log.SetOutput(progress)
+}
+
+// Update reports done of total units for the labelled phase; detail is
+// appended as is. Starting a new label restarts the ETA.
+func (p *progressLine) Update(label string, done, total int, unit string, detail string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.enabled {
+		return
+	}
+
+	if label != p.label {
+		p.label = label
+		p.started = time.Now()
+	}
+	p.done, p.total, p.unit, p.detail = done, total, unit, detail
+
+	if done < total && time.Since(p.drawn) < progressRedraw {
+		return
+	}
+	p.draw()
+}
+
+func (p *progressLine) text() string {
+	s := fmt.Sprintf("%s: %d/%d %s", p.label, p.done, p.total, p.unit)
+	if p.detail != "" {
+		s += ", " + p.detail
+	}
+	if p.done > 0 && p.done < p.total {
+		elapsed := time.Since(p.started)
+		eta := time.Duration(float64(elapsed) * float64(p.total-p.done) / float64(p.done))
+		s += ", ETA " + eta.Round(time.Second).String()
+	}
+	return s
+}
+
+func (p *progressLine) draw() {
+	if p.lines {
+		if time.Since(p.drawn) >= progressLinesEvery || p.done >= p.total {
+			fmt.Fprintf(p.out, "progress: %s\n", p.text())
+			p.drawn = time.Now()
+		}
+		return
+	}
+	p.erase()
+	s := p.text()
+	fmt.Fprint(p.out, s)
+	p.shown = len(s)
+	p.drawn = time.Now()
+}
+
+func (p *progressLine) erase() {
+	if p.shown > 0 {
+		fmt.Fprintf(p.out, "\r%s\r", strings.Repeat(" ", p.shown))
+		p.shown = 0
+	}
+}
+
+// Clear removes the line, ending the current phase:
+func (p *progressLine) Clear() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.erase()
+	p.label = ""
+}
+
+func (p *progressLine) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	shown := p.shown > 0
+	p.erase()
+	n, err := p.out.Write(b)
+	if shown {
+		p.draw()
+	}
+	return n, err
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProgressLine_RedrawsAroundLogOutput(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	p := &progressLine{out: f, enabled: true}
+	p.Update("board 1 issue", 50, 100, "issues", "1 page")
+	p.Write([]byte("GET page 2\n"))
+	p.Clear()
+
+	b, _ := ioutil.ReadFile(f.Name())
+	line := "board 1 issue: 50/100 issues, 1 page, ETA 0s"
+	blank := "\r" + strings.Repeat(" ", len(line)) + "\r"
+	expected := line + blank + "GET page 2\n" + line + blank
+	if string(b) != expected {
+		t.Fatalf("expected %q, got %q", expected, b)
+	}
+}
+
+func TestProgressLine_DisabledWritesNothing(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	p := &progressLine{out: f}
+	p.Update("board 1 issue", 50, 100, "issues", "")
+	p.Clear()
+
+	if b, _ := ioutil.ReadFile(f.Name()); len(b) != 0 {
+		t.Fatalf("expected no progress off a terminal, got %q", b)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultRankField is JIRA Cloud's Rank field; server instances number it
+// differently, see meta fields.
+const defaultRankField = "customfield_10019"
+
+func rankField() string {
+	if config.RankField != "" {
+		return config.RankField
+	}
+	return defaultRankField
+}
+
+// lexoRank is the issue's board rank, a LexoRank string (e.g. "0|i0003b:")
+// ordered by plain string comparison, or "" when the field isn't fetched:
+func (issue *Issue) lexoRank() string {
+	return issue.Fields.CustomString(rankField())
+}
+
+// rankOrder orders issues top of the board first, by LexoRank when every
+// issue has one, or else as fetched: the agile API lists board issues in rank
+// order. It reports which it used.
+func rankOrder(issues []*Issue) ([]*Issue, bool) {
+	ranked := make([]*Issue, len(issues))
+	copy(ranked, issues)
+	for _, issue := range ranked {
+		if issue.lexoRank() == "" {
+			return ranked, false
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].lexoRank() < ranked[j].lexoRank() })
+	return ranked, true
+}
+
+// rankSkip is in-flight work started ahead of un-started work ranked above it:
+type rankSkip struct {
+	Issue *Issue
+	// Position is the issue's place in rank order, from 1:
+	Position int
+	Skipped  []*Issue
+}
+
+// findRankSkips finds in-flight issues ranked below at least minSkipped
+// un-started issues, most skipped first; a team working the board top-down
+// has none.
+func findRankSkips(ranked []*Issue, minSkipped int) []*rankSkip {
+	var skips []*rankSkip
+	var unstarted []*Issue
+	for i, issue := range ranked {
+		switch issue.Fields.Status.StatusCategory.Key {
+		case "new":
+			unstarted = append(unstarted, issue)
+		case "indeterminate":
+			if len(unstarted) >= minSkipped {
+				skipped := make([]*Issue, len(unstarted))
+				copy(skipped, unstarted)
+				skips = append(skips, &rankSkip{Issue: issue, Position: i + 1, Skipped: skipped})
+			}
+		}
+	}
+	sort.SliceStable(skips, func(i, j int) bool { return len(skips[i].Skipped) > len(skips[j].Skipped) })
+	return skips
+}
+
+// maxSkippedKeys bounds the un-started keys listed per skip:
+const maxSkippedKeys = 5
+
+func runRank(args []string) error {
+	fs := flag.NewFlagSet("rank", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting the ranked work; default='statusCategory != Done'")
+	minSkipped := fs.Int("min", 3, "list in-flight items ranked below at least this many un-started items")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *minSkipped < 1 {
+		return fmt.Errorf("-min must be at least 1")
+	}
+
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("rank", *jql, "statusCategory != Done"))
+	if err != nil {
+		return err
+	}
+
+	var candidates []*Issue
+	inFlight := 0
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		candidates = append(candidates, issue)
+		if issue.Fields.Status.StatusCategory.Key == "indeterminate" {
+			inFlight++
+		}
+	}
+
+	ranked, byField := rankOrder(candidates)
+	source := "board order"
+	if byField {
+		source = "rank field " + rankField()
+	}
+	skips := findRankSkips(ranked, *minSkipped)
+
+	fmt.Printf("Ranked %s by %s; %d in flight.\n", plural(len(ranked), "item"), source, inFlight)
+	fmt.Printf("In flight below %d or more un-started higher-ranked items: %d\n", *minSkipped, len(skips))
+	shown := detailLines(len(skips))
+	for _, s := range skips[:shown] {
+		keys := make([]string, 0, maxSkippedKeys)
+		for _, skipped := range s.Skipped {
+			if len(keys) == maxSkippedKeys {
+				keys = append(keys, "…")
+				break
+			}
+			keys = append(keys, skipped.Key)
+		}
+		fmt.Printf(
+			"  #%-4d %s (%s): below %d un-started: %s; %s\n",
+			s.Position,
+			s.Issue.Key,
+			s.Issue.Fields.Status.Name,
+			len(s.Skipped),
+			strings.Join(keys, ", "),
+			s.Issue.DisplaySummary(),
+		)
+	}
+	printOmitted("  ", shown, len(skips))
+	if inFlight > 0 {
+		fmt.Printf("%.0f%% of in-flight work is taken from the top of the board.\n", 100*(1-share(len(skips), inFlight)))
+	}
+
+	recordMetric("inFlight", inFlight)
+	recordMetric("rankSkips", len(skips))
+	return nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func rankedIssue(key string, category string, rank string) *Issue {
+	issue := &Issue{Key: key}
+	issue.Fields.Status.StatusCategory.Key = category
+	if rank != "" {
+		issue.Fields.Custom = map[string]json.RawMessage{defaultRankField: json.RawMessage(`"` + rank + `"`)}
+	}
+	return issue
+}
+
+func TestRankOrder(t *testing.T) {
+	issues := []*Issue{
+		rankedIssue("A-1", "new", "0|i0000c:"),
+		rankedIssue("A-2", "new", "0|i0000a:"),
+		rankedIssue("A-3", "new", "0|i0000b:"),
+	}
+	ranked, byField := rankOrder(issues)
+	if !byField || ranked[0].Key != "A-2" || ranked[1].Key != "A-3" || ranked[2].Key != "A-1" {
+		t.Fatalf("expected A-2, A-3, A-1 by rank field, got %s, %s, %s (%v)", ranked[0].Key, ranked[1].Key, ranked[2].Key, byField)
+	}
+
+	// Without a rank on every issue, board order stands:
+	issues = append(issues, rankedIssue("A-4", "new", ""))
+	ranked, byField = rankOrder(issues)
+	if byField || ranked[0].Key != "A-1" {
+		t.Fatalf("expected fetch order kept, got %s first (%v)", ranked[0].Key, byField)
+	}
+}
+
+func TestFindRankSkips(t *testing.T) {
+	ranked := []*Issue{
+		rankedIssue("A-1", "indeterminate", ""),
+		rankedIssue("A-2", "new", ""),
+		rankedIssue("A-3", "indeterminate", ""),
+		rankedIssue("A-4", "new", ""),
+		rankedIssue("A-5", "new", ""),
+		rankedIssue("A-6", "indeterminate", ""),
+		rankedIssue("A-7", "done", ""),
+	}
+	skips := findRankSkips(ranked, 2)
+	if len(skips) != 1 {
+		t.Fatalf("expected 1 skip, got %d", len(skips))
+	}
+	if s := skips[0]; s.Issue.Key != "A-6" || s.Position != 6 || len(s.Skipped) != 3 || s.Skipped[0].Key != "A-2" {
+		t.Fatalf("expected A-6 at #6 below A-2, A-4 and A-5, got %s at #%d below %d", s.Issue.Key, s.Position, len(s.Skipped))
+	}
+	if skips := findRankSkips(ranked, 1); len(skips) != 2 || skips[1].Issue.Key != "A-3" {
+		t.Fatalf("expected A-6 then A-3, got %d skips", len(skips))
+	}
+}
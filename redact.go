@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// RedactRule hides text matching Pattern in issue summaries before they are
+// shown; Replacement may refer to capture groups as $1 and defaults to
+// "[redacted]".
+type RedactRule struct {
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement"`
+
+	re *regexp.Regexp
+}
+
+func (c *Config) compileRedactRules() error {
+	for i := range c.Redactions {
+		rule := &c.Redactions[i]
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("redaction '%s': %v", rule.Pattern, err)
+		}
+		rule.re = re
+		if rule.Replacement == "" {
+			rule.Replacement = "[redacted]"
+		}
+	}
+	return nil
+}
+
+// redact applies every redaction rule to s in order:
+func redact(s string) string {
+	for _, rule := range config.Redactions {
+		if rule.re != nil {
+			s = rule.re.ReplaceAllString(s, rule.Replacement)
+		}
+	}
+	return s
+}
+
+// DisplaySummary is the summary as it may appear in reports, redacted and
+// truncated to the summary width; tag rules still match the original.
+func (issue *Issue) DisplaySummary() string {
+	return truncateWidth(redact(issue.Fields.Summary), summaryWidth())
+}
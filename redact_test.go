@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestIssue_DisplaySummary(t *testing.T) {
+	config = &Config{Redactions: []RedactRule{
+		{Pattern: `(?i)(customer:\s*)\w+`, Replacement: "${1}[customer]"},
+		{Pattern: `SF-\d+`},
+	}}
+	defer func() { config = &Config{} }()
+	if err := config.compileRedactRules(); err != nil {
+		t.Fatal(err)
+	}
+
+	issue := &Issue{}
+	issue.Fields.Summary = "Timeout for customer: acme (see SF-1234)"
+	summary := issue.DisplaySummary()
+	if summary != "Timeout for customer: [customer] (see [redacted])" {
+		t.Fatalf("expected customer and ticket redacted, got %q", summary)
+	}
+	if issue.Fields.Summary != "Timeout for customer: acme (see SF-1234)" {
+		t.Fatalf("expected original summary kept for tag rules, got %q", issue.Fields.Summary)
+	}
+}
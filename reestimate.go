@@ -0,0 +1,189 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+type reestimate struct {
+	Time     time.Time
+	From, To string
+	// Delta is To-From in points; zero when either side is blank:
+	Delta float64
+}
+
+// reestimates lists story point changes made after work on the issue started:
+func (issue *Issue) reestimates() []reestimate {
+	started, ok := issue.StartedTime()
+	if !ok {
+		return nil
+	}
+
+	var changes []reestimate
+	for _, ev := range issue.Transitions() {
+		if ev.Time.Before(started) || !config.isStoryPointsField(ev.Field) {
+			continue
+		}
+
+		change := reestimate{Time: ev.Time, From: ev.FromString, To: ev.ToString}
+		from, errFrom := strconv.ParseFloat(ev.FromString, 64)
+		to, errTo := strconv.ParseFloat(ev.ToString, 64)
+		if errFrom == nil && errTo == nil {
+			change.Delta = to - from
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// storyPoints is an issue's estimate: its storyPointsFieldId value, else the
+// last estimate recorded in its changelog.
+func (issue *Issue) storyPoints() (float64, bool) {
+	if config.StoryPointsFieldId != "" {
+		if points, err := strconv.ParseFloat(issue.Fields.CustomString(config.StoryPointsFieldId), 64); err == nil {
+			return points, true
+		}
+	}
+	points, ok := 0.0, false
+	for _, ev := range issue.Transitions() {
+		if !config.isStoryPointsField(ev.Field) {
+			continue
+		}
+		var err error
+		points, err = strconv.ParseFloat(ev.ToString, 64)
+		ok = err == nil
+	}
+	return points, ok
+}
+
+type reestimateGroup struct {
+	Name          string
+	Started       int
+	Reestimated   int
+	Changes       int
+	AbsoluteDelta float64
+}
+
+func (g *reestimateGroup) add(changes []reestimate) {
+	g.Started++
+	if len(changes) == 0 {
+		return
+	}
+	g.Reestimated++
+	g.Changes += len(changes)
+	for _, change := range changes {
+		g.AbsoluteDelta += math.Abs(change.Delta)
+	}
+}
+
+func printReestimateGroups(title string, groups map[string]*reestimateGroup) {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("%s:\n", title)
+	fmt.Printf("  %s %7s %11s %7s %9s\n", padRight("", nameWidth(20)), "started", "reestimated", "changes", "avg delta")
+	for _, name := range names {
+		g := groups[name]
+		avg := 0.0
+		if g.Changes > 0 {
+			avg = g.AbsoluteDelta / float64(g.Changes)
+		}
+		fmt.Printf(
+			"  %s %7d %4d (%3.0f%%) %7d %9.1f\n",
+			padRight(name, nameWidth(20)),
+			g.Started,
+			g.Reestimated,
+			100*float64(g.Reestimated)/float64(g.Started),
+			g.Changes,
+			avg,
+		)
+	}
+}
+
+func runReestimates(args []string) error {
+	fs := flag.NewFlagSet("reestimates", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting issues to examine; default='resolved >= -90d OR resolution is EMPTY'")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("reestimates", *jql, "resolved >= -90d OR resolution is EMPTY"))
+	if err != nil {
+		return err
+	}
+
+	total := &reestimateGroup{}
+	byAssignee := make(map[string]*reestimateGroup)
+	byEpic := make(map[string]*reestimateGroup)
+	group := func(groups map[string]*reestimateGroup, name string) *reestimateGroup {
+		g, ok := groups[name]
+		if !ok {
+			g = &reestimateGroup{Name: name}
+			groups[name] = g
+		}
+		return g
+	}
+
+	var lines []string
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		if _, ok := issue.StartedTime(); !ok {
+			continue
+		}
+
+		changes := issue.reestimates()
+		total.add(changes)
+
+		assignee := "(unassigned)"
+		if issue.Fields.Assignee != nil {
+			assignee = users.Name(*issue.Fields.Assignee)
+		}
+		group(byAssignee, assignee).add(changes)
+
+		epic := issue.EpicKey()
+		if epic == "" {
+			epic = "(no epic)"
+		}
+		group(byEpic, epic).add(changes)
+
+		for _, change := range changes {
+			lines = append(lines, fmt.Sprintf("  %s: %s -> %s on %s; %s\n", issue.Key, change.From, change.To, displayTime(change.Time).Format("Mon Jan 02"), issue.DisplaySummary()))
+		}
+	}
+
+	if shown := detailLines(len(lines)); reportVerbosity != execView {
+		fmt.Printf("Story point changes after work started:\n")
+		for _, line := range lines[:shown] {
+			fmt.Print(line)
+		}
+		printOmitted("  ", shown, len(lines))
+	}
+
+	if total.Started == 0 {
+		fmt.Printf("no started issues\n")
+		return nil
+	}
+
+	fmt.Printf(
+		"\n%d of %d started issues (%.0f%%) re-estimated, %d changes\n\n",
+		total.Reestimated,
+		total.Started,
+		100*float64(total.Reestimated)/float64(total.Started),
+		total.Changes,
+	)
+	printReestimateGroups("By assignee", byAssignee)
+	fmt.Println()
+	printReestimateGroups("By epic", byEpic)
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIssue_Reestimates_AfterStartOnly(t *testing.T) {
+	issue := &Issue{Key: "A-1"}
+	issue.Changelog.Histories = []History{
+		{
+			Created: zonedTimestamp{time.Date(2018, 11, 1, 9, 0, 0, 0, cst)},
+			Items:   []HistoryItem{{Field: "Story Points", FromString: "", ToString: "3"}},
+		},
+		{
+			Created: zonedTimestamp{time.Date(2018, 11, 2, 9, 0, 0, 0, cst)},
+			Items:   []HistoryItem{{Field: "status", ToString: inProgressStatus}},
+		},
+		{
+			Created: zonedTimestamp{time.Date(2018, 11, 5, 9, 0, 0, 0, cst)},
+			Items:   []HistoryItem{{Field: "Story Points", FromString: "3", ToString: "8"}},
+		},
+	}
+
+	changes := issue.reestimates()
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 for changes, got %d", len(changes))
+	}
+	if changes[0].Delta != 5 {
+		t.Fatalf("expected 5 for delta, got %v", changes[0].Delta)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+)
+
+// configWatcher notices changes to the config file by polling its size and
+// modification time, which editors and deploys both update.
+type configWatcher struct {
+	filename string
+	modTime  time.Time
+	size     int64
+	exists   bool
+}
+
+func newConfigWatcher(filename string) *configWatcher {
+	w := &configWatcher{filename: filename}
+	w.changed()
+	return w
+}
+
+// changed reports whether the file was written, created or removed since the
+// last call:
+func (w *configWatcher) changed() bool {
+	stat, err := os.Stat(w.filename)
+	exists := err == nil
+	var modTime time.Time
+	var size int64
+	if exists {
+		modTime, size = stat.ModTime(), stat.Size()
+	}
+	changed := exists != w.exists || !modTime.Equal(w.modTime) || size != w.size
+	w.modTime, w.size, w.exists = modTime, size, exists
+	return changed
+}
+
+// reloadConfig replaces the running config and profile with the config
+// file's. A config that doesn't load leaves the running one in place.
+func reloadConfig() error {
+	c, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	profile, err := c.Profile(profileName)
+	if err != nil {
+		return err
+	}
+
+	serveMu.Lock()
+	defer serveMu.Unlock()
+	previous, previousProfile := config, activeProfile
+	config, activeProfile = c, profile
+	v, err := resolveVerbosity()
+	if err != nil {
+		config, activeProfile = previous, previousProfile
+		return err
+	}
+	reportVerbosity = v
+	users = newUserRegistry()
+	users.AddAliases(config.UserAliases)
+	if profile != nil {
+		profile.apply()
+	}
+	return nil
+}
+
+// watchConfig reloads the config whenever its file changes, until stop is
+// closed.
+func watchConfig(w *configWatcher, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !w.changed() {
+				continue
+			}
+			if err := reloadConfig(); err != nil {
+				log.Printf("serve: keeping the running config: %v\n", err)
+				continue
+			}
+			log.Printf("serve: reloaded %s\n", w.filename)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchConfig(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	filename := filepath.Join(t.TempDir(), "config.json")
+	os.Setenv("JIRA_CONFIG", filename)
+	defer os.Unsetenv("JIRA_CONFIG")
+	config = &Config{}
+	defer func() { config = &Config{} }()
+
+	w := newConfigWatcher(filename)
+	stop := make(chan struct{})
+	defer close(stop)
+	go watchConfig(w, 10*time.Millisecond, stop)
+
+	current := func() *Config {
+		serveMu.Lock()
+		defer serveMu.Unlock()
+		return config
+	}
+	waitFor := func(what string, ok func(c *Config) bool) {
+		for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if ok(current()) {
+				return
+			}
+		}
+		t.Fatalf("timed out waiting for %s", what)
+	}
+
+	ioutil.WriteFile(filename, []byte(`{"holidayCalendar": "US", "teams": {"core": ["alice"]}}`), 0644)
+	waitFor("the new config", func(c *Config) bool { return c.HolidayCalendar == "US" && len(c.Teams["core"]) == 1 })
+
+	// A broken config leaves the running one in place:
+	ioutil.WriteFile(filename, []byte(`{"holidayCalendar": `), 0644)
+	time.Sleep(50 * time.Millisecond)
+	if c := current(); c.HolidayCalendar != "US" {
+		t.Fatalf("expected the running config kept, got %+v", c)
+	}
+
+	ioutil.WriteFile(filename, []byte(`{"holidayCalendar": "DE-BY"}`), 0644)
+	waitFor("the fixed config", func(c *Config) bool { return c.HolidayCalendar == "DE-BY" })
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var defaultUndeliveredResolutions = []string{"Won't Do", "Won't Fix", "Duplicate", "Cannot Reproduce", "Incomplete"}
+
+// ResolutionName is the issue's resolution, or "(none)" for done issues
+// closed without one:
+func (issue *Issue) ResolutionName() string {
+	if issue.Fields.Resolution != nil && issue.Fields.Resolution.Name != "" {
+		return issue.Fields.Resolution.Name
+	}
+	return "(none)"
+}
+
+func isUndeliveredResolution(resolution string) bool {
+	undelivered := config.UndeliveredResolutions
+	if len(undelivered) == 0 {
+		undelivered = defaultUndeliveredResolutions
+	}
+	for _, r := range undelivered {
+		if strings.EqualFold(r, resolution) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolutionPeriod counts the work completed in one period by resolution:
+type resolutionPeriod struct {
+	Start       time.Time
+	Counts      map[string]int
+	Total       int
+	Undelivered int
+}
+
+// Delivered is the work completed by delivering it, the throughput that
+// counts:
+func (p *resolutionPeriod) Delivered() int {
+	return p.Total - p.Undelivered
+}
+
+// resolutionsByPeriod groups completed issues by the week or month they were
+// completed in, ordered by period, with every resolution seen ordered by how
+// often it was.
+func resolutionsByPeriod(issues []Issue, monthly bool) ([]*resolutionPeriod, []string) {
+	byPeriod := make(map[string]*resolutionPeriod)
+	totals := make(map[string]int)
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		completed, ok := issue.CompletedTime()
+		if !ok {
+			continue
+		}
+
+		completed = displayTime(completed)
+		start := weekOf(completed)
+		if monthly {
+			start = time.Date(completed.Year(), completed.Month(), 1, 0, 0, 0, 0, completed.Location())
+		}
+		key := start.Format("2006-01-02")
+		p, ok := byPeriod[key]
+		if !ok {
+			p = &resolutionPeriod{Start: start, Counts: make(map[string]int)}
+			byPeriod[key] = p
+		}
+
+		resolution := issue.ResolutionName()
+		p.Counts[resolution]++
+		p.Total++
+		if isUndeliveredResolution(resolution) {
+			p.Undelivered++
+		}
+		totals[resolution]++
+	}
+
+	periods := make([]*resolutionPeriod, 0, len(byPeriod))
+	for _, p := range byPeriod {
+		periods = append(periods, p)
+	}
+	sort.Slice(periods, func(i, j int) bool { return periods[i].Start.Before(periods[j].Start) })
+
+	resolutions := make([]string, 0, len(totals))
+	for resolution := range totals {
+		resolutions = append(resolutions, resolution)
+	}
+	sort.Slice(resolutions, func(i, j int) bool {
+		if totals[resolutions[i]] != totals[resolutions[j]] {
+			return totals[resolutions[i]] > totals[resolutions[j]]
+		}
+		return resolutions[i] < resolutions[j]
+	})
+	return periods, resolutions
+}
+
+func runResolutions(args []string) error {
+	fs := flag.NewFlagSet("resolutions", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting completed issues; default covers the last -weeks")
+	weeks := fs.Int("weeks", 12, "weeks of completed work to break down")
+	monthly := fs.Bool("monthly", false, "group by month completed instead of week")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *weeks < 1 {
+		return fmt.Errorf("-weeks must be at least 1")
+	}
+
+	defaultFilter := fmt.Sprintf("statusCategory = Done AND resolved >= -%dd", 7**weeks)
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("resolutions", *jql, defaultFilter))
+	if err != nil {
+		return err
+	}
+
+	periods, resolutions := resolutionsByPeriod(issues, *monthly)
+	layout, heading := "Mon Jan 02", "week of"
+	if *monthly {
+		layout, heading = "2006-01", "month"
+	}
+
+	widths := make([]int, len(resolutions))
+	fmt.Printf("Completed work by resolution:\n")
+	fmt.Printf("  %-10s", heading)
+	for i, resolution := range resolutions {
+		widths[i] = displayWidth(resolution)
+		if widths[i] < 5 {
+			widths[i] = 5
+		}
+		fmt.Printf(" %s", padLeft(resolution, widths[i]))
+	}
+	fmt.Printf(" %5s %9s\n", "total", "delivered")
+
+	total, delivered := 0, 0
+	for _, p := range periods {
+		fmt.Printf("  %-10s", p.Start.Format(layout))
+		for i, resolution := range resolutions {
+			fmt.Printf(" %*d", widths[i], p.Counts[resolution])
+		}
+		fmt.Printf(" %5d %4d %3.0f%%\n", p.Total, p.Delivered(), 100*share(p.Delivered(), p.Total))
+		total += p.Total
+		delivered += p.Delivered()
+	}
+	if total > 0 {
+		fmt.Printf("%s delivered of %s completed; the rest were resolved as not done.\n", plural(delivered, "item"), plural(total, "item"))
+	}
+
+	byResolution := make(map[string]int, len(resolutions))
+	for _, p := range periods {
+		for resolution, n := range p.Counts {
+			byResolution[resolution] += n
+		}
+	}
+	recordMetric("completed", total)
+	recordMetric("delivered", delivered)
+	recordMetric("resolutions", byResolution)
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolutionsByPeriod(t *testing.T) {
+	config = &Config{UndeliveredResolutions: []string{"Duplicate"}}
+	defer func() { config = &Config{} }()
+
+	resolved := func(key string, at time.Time, resolution string) Issue {
+		issue := completedIssue(key, at.AddDate(0, 0, -3), at)
+		if resolution != "" {
+			issue.Fields.Resolution = &Resolution{Name: resolution}
+		}
+		return issue
+	}
+	issues := []Issue{
+		resolved("A-1", time.Date(2018, 11, 5, 9, 0, 0, 0, cst), "Fixed"),
+		resolved("A-2", time.Date(2018, 11, 6, 9, 0, 0, 0, cst), "Duplicate"),
+		resolved("A-3", time.Date(2018, 11, 7, 9, 0, 0, 0, cst), "Duplicate"),
+		resolved("A-4", time.Date(2018, 11, 14, 9, 0, 0, 0, cst), ""),
+	}
+
+	periods, resolutions := resolutionsByPeriod(issues, false)
+	if len(resolutions) != 3 || resolutions[0] != "Duplicate" || resolutions[1] != "(none)" || resolutions[2] != "Fixed" {
+		t.Fatalf("expected resolutions by count, got %q", resolutions)
+	}
+	if len(periods) != 2 {
+		t.Fatalf("expected 2 weeks, got %d", len(periods))
+	}
+	if p := periods[0]; p.Total != 3 || p.Delivered() != 1 || p.Counts["Duplicate"] != 2 {
+		t.Fatalf("expected 1 of 3 delivered the first week, got %+v", p)
+	}
+	if p := periods[1]; p.Total != 1 || p.Delivered() != 1 {
+		t.Fatalf("expected an unresolved completion counted as delivered, got %+v", p)
+	}
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// retroTopItems is how many of the longest and most blocked items a retro
+// pack lists:
+const retroTopItems = 5
+
+// retroItem is an issue with the business days it's listed for:
+type retroItem struct {
+	Issue *Issue
+	Days  int
+}
+
+// retroPack bundles what a sprint retrospective looks back on:
+type retroPack struct {
+	Sprint *Sprint
+	// Committed is the items in the sprint when it started; Done those
+	// completed by its end, of every item in it:
+	Committed, Total, Done int
+	Added, Removed         []*Issue
+	CarryOver              []*Issue
+
+	// CycleTimes are the completed items' cycle times at each of the
+	// Percentiles:
+	CycleTimes     map[float64]int
+	Percentiles    []float64
+	CycleTimeCount int
+	Longest        []retroItem
+	Blocked        []retroItem
+}
+
+// CycleTimeLabels heads the cycle times, e.g. "p50 / p85":
+func (r *retroPack) CycleTimeLabels() string {
+	labels := make([]string, len(r.Percentiles))
+	for i, p := range r.Percentiles {
+		labels[i] = percentileLabel(p)
+	}
+	return strings.Join(labels, " / ")
+}
+
+// CycleTimeDays lists the cycle times under CycleTimeLabels, e.g. "3 / 7":
+func (r *retroPack) CycleTimeDays() string {
+	days := make([]string, len(r.Percentiles))
+	for i, p := range r.Percentiles {
+		days[i] = strconv.Itoa(r.CycleTimes[p])
+	}
+	return strings.Join(days, " / ")
+}
+
+// removedFrom reports whether the issue left the sprint after it started,
+// by its changelog:
+func (issue *Issue) removedFrom(sprint *Sprint) bool {
+	id := strconv.Itoa(sprint.Id)
+	removed := false
+	for _, h := range issue.Changelog.Histories {
+		if !h.Created.After(sprint.StartDate.Time) {
+			continue
+		}
+		for _, item := range h.Items {
+			if item.Field == "Sprint" && sprintIds(item.From)[id] != sprintIds(item.To)[id] {
+				removed = sprintIds(item.From)[id]
+			}
+		}
+	}
+	return removed
+}
+
+// blockedSpans are the times an issue was blocked by its history: in one of
+// the blocked statuses, or flagged as an impediment. Spans are merged where
+// they overlap and End is zero while still blocked.
+func (issue *Issue) blockedSpans() []statusInterval {
+	var spans []statusInterval
+	for _, in := range issue.statusIntervals() {
+		for _, status := range config.Badges.BlockedStatuses {
+			if strings.EqualFold(status, in.Status) {
+				spans = append(spans, in)
+				break
+			}
+		}
+	}
+	var flagged *statusInterval
+	for _, ev := range issue.Transitions() {
+		if ev.Field != "Flagged" {
+			continue
+		}
+		if ev.ToString != "" && flagged == nil {
+			flagged = &statusInterval{Status: ev.ToString, Start: ev.Time}
+		} else if ev.ToString == "" && flagged != nil {
+			flagged.End = ev.Time
+			spans = append(spans, *flagged)
+			flagged = nil
+		}
+	}
+	if flagged != nil {
+		spans = append(spans, *flagged)
+	}
+
+	sort.Slice(spans, func(i, j int) bool { return spans[i].Start.Before(spans[j].Start) })
+	merged := spans[:0]
+	for _, span := range spans {
+		if n := len(merged); n > 0 && (merged[n-1].End.IsZero() || !span.Start.After(merged[n-1].End)) {
+			if !merged[n-1].End.IsZero() && (span.End.IsZero() || span.End.After(merged[n-1].End)) {
+				merged[n-1].End = span.End
+			}
+			continue
+		}
+		merged = append(merged, span)
+	}
+	return merged
+}
+
+// blockedDays counts the business days an issue was blocked between from and
+// to:
+func (issue *Issue) blockedDays(from time.Time, to time.Time) int {
+	days := 0
+	for _, span := range issue.blockedSpans() {
+		start, end := span.Start, span.End
+		if end.IsZero() || end.After(to) {
+			end = to
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(start) {
+			days += DateOf(start).BusinessDaysUntil(DateOf(end))
+		}
+	}
+	return days
+}
+
+// computeRetro looks back on the sprint's issues as of now, with cycle times
+// at the percentiles.
+func computeRetro(sprint *Sprint, issues []Issue, now time.Time, percentiles []float64) *retroPack {
+	end := sprintEnd(sprint)
+	if now.Before(end) {
+		end = now
+	}
+	r := &retroPack{Sprint: sprint, CycleTimes: make(map[float64]int, len(percentiles)), Percentiles: percentiles}
+
+	var cycleTimes []int
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		if issue.removedFrom(sprint) {
+			r.Removed = append(r.Removed, issue)
+			continue
+		}
+		r.Total++
+		if issue.addedAfter(sprint) {
+			r.Added = append(r.Added, issue)
+		} else {
+			r.Committed++
+		}
+
+		if days := issue.blockedDays(sprint.StartDate.Time, end); days > 0 {
+			r.Blocked = append(r.Blocked, retroItem{issue, days})
+		}
+		if completed, done := issue.CompletedTime(); !done || completed.After(end) {
+			r.CarryOver = append(r.CarryOver, issue)
+			continue
+		}
+		r.Done++
+		if days, ok := issue.CycleTime(); ok {
+			cycleTimes = append(cycleTimes, days)
+			r.Longest = append(r.Longest, retroItem{issue, days})
+		}
+	}
+
+	if len(cycleTimes) > 0 {
+		sorted := sortedCopy(cycleTimes)
+		for _, p := range percentiles {
+			r.CycleTimes[p] = percentile(sorted, p)
+		}
+		r.CycleTimeCount = len(sorted)
+	}
+	for _, items := range []*[]retroItem{&r.Longest, &r.Blocked} {
+		list := *items
+		sort.SliceStable(list, func(i, j int) bool {
+			if list[i].Days != list[j].Days {
+				return list[i].Days > list[j].Days
+			}
+			return lessIssueKey(list[i].Issue.Key, list[j].Issue.Key)
+		})
+		if len(list) > retroTopItems {
+			*items = list[:retroTopItems]
+		}
+	}
+	return r
+}
+
+func (r *retroPack) title() string {
+	return fmt.Sprintf("Retro: %s, %s to %s", r.Sprint.Name, displayTime(r.Sprint.StartDate.Time).Format("Mon Jan 02"), displayTime(sprintEnd(r.Sprint)).Format("Mon Jan 02"))
+}
+
+// Churn is the items added to and removed from the sprint after it started:
+func (r *retroPack) Churn() int {
+	return len(r.Added) + len(r.Removed)
+}
+
+// Goal is the sprint goal on one line, or "":
+func (r *retroPack) Goal() string {
+	return redact(strings.Join(strings.Fields(r.Sprint.Goal), " "))
+}
+
+func (r *retroPack) writeMarkdown(w *strings.Builder) {
+	fmt.Fprintf(w, "# %s\n\n", markdownText(r.title()))
+	if goal := r.Goal(); goal != "" {
+		fmt.Fprintf(w, "Goal: %s\n\n", markdownText(goal))
+	}
+	fmt.Fprintf(w, "| metric | value |\n|---|---:|\n")
+	fmt.Fprintf(w, "| committed at start | %d |\n", r.Committed)
+	fmt.Fprintf(w, "| completed | %d of %d (%d%%) |\n", r.Done, r.Total, percentOf(r.Done, r.Total))
+	fmt.Fprintf(w, "| added after start | %d |\n", len(r.Added))
+	fmt.Fprintf(w, "| removed after start | %d |\n", len(r.Removed))
+	fmt.Fprintf(w, "| carried over | %d |\n", len(r.CarryOver))
+	if r.CycleTimeCount > 0 {
+		fmt.Fprintf(w, "| cycle time %s | %s days (n=%d) |\n", r.CycleTimeLabels(), r.CycleTimeDays(), r.CycleTimeCount)
+	}
+
+	issues := func(heading string, list []*Issue) {
+		if len(list) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "\n## %s (%d)\n\n", heading, len(list))
+		shown := detailLines(len(list))
+		for _, issue := range list[:shown] {
+			fmt.Fprintf(w, "- %s %s (%s)\n", issue.Key, markdownText(issue.DisplaySummary()), markdownText(issue.Fields.Status.Name))
+		}
+		if shown < len(list) {
+			fmt.Fprintf(w, "- ... and %d more\n", len(list)-shown)
+		}
+	}
+	items := func(heading string, list []retroItem) {
+		if len(list) == 0 {
+			return
+		}
+		fmt.Fprintf(w, "\n## %s\n\n", heading)
+		for _, item := range list[:detailLines(len(list))] {
+			fmt.Fprintf(w, "- %s %s: %s\n", item.Issue.Key, markdownText(item.Issue.DisplaySummary()), plural(item.Days, "business day"))
+		}
+	}
+	issues("Added after the sprint started", r.Added)
+	issues("Removed after the sprint started", r.Removed)
+	issues("Carried over", r.CarryOver)
+	items("Longest cycle times", r.Longest)
+	items("Most time blocked", r.Blocked)
+}
+
+var retroTemplate = assetTemplate("retro.html", template.FuncMap{"plural": plural, "percentOf": percentOf})
+
+func runRetro(args []string) error {
+	fs := flag.NewFlagSet("retro", flag.ContinueOnError)
+	sprintId := fs.Int("sprint", 0, "sprint ID to look back on; default the active sprint, else the last closed")
+	format := fs.String("format", "markdown", "markdown or html")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != "markdown" && *format != "md" && *format != "html" {
+		return fmt.Errorf("unknown format '%s'; expected markdown or html", *format)
+	}
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	cl := httpClient()
+	boardId := boardArg(fs.Args())
+	sprints, err := fetchSprints(cl, boardId, "")
+	if err != nil {
+		return err
+	}
+	var sprint *Sprint
+	for i := range sprints {
+		s := &sprints[i]
+		switch {
+		case *sprintId != 0:
+			if s.Id == *sprintId {
+				sprint = s
+			}
+		case s.State == "active":
+			sprint = s
+		case s.State == "closed" && (sprint == nil || sprint.State == "closed" && sprintEnd(s).After(sprintEnd(sprint))):
+			sprint = s
+		}
+	}
+	if sprint == nil {
+		if *sprintId != 0 {
+			return fmt.Errorf("board %d has no sprint %d", boardId, *sprintId)
+		}
+		return fmt.Errorf("board %d has no active or closed sprint", boardId)
+	}
+
+	issues, err := fetchBoardIssues(cl, boardId, fmt.Sprintf("sprint = %d", sprint.Id))
+	if err != nil {
+		return err
+	}
+	r := computeRetro(sprint, issues, reportNow(), percentiles)
+	recordMetric("committed", r.Committed)
+	recordMetric("completed", r.Done)
+	recordMetric("churn", r.Churn())
+	recordMetric("carryOver", len(r.CarryOver))
+	if r.CycleTimeCount > 0 {
+		for _, p := range percentiles {
+			recordMetric(percentileMetric("cycleTime", p), r.CycleTimes[p])
+		}
+	}
+
+	var out strings.Builder
+	if *format == "html" {
+		for _, list := range []*[]*Issue{&r.Added, &r.Removed, &r.CarryOver} {
+			*list = (*list)[:detailLines(len(*list))]
+		}
+		err = retroTemplate.Execute(&out, struct {
+			Title string
+			R     *retroPack
+		}{r.title(), r})
+		if err != nil {
+			return err
+		}
+	} else {
+		r.writeMarkdown(&out)
+	}
+	_, err = os.Stdout.WriteString(out.String())
+	return err
+}
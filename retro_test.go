@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeRetro(t *testing.T) {
+	config = &Config{Badges: BadgeConfig{BlockedStatuses: []string{"Blocked"}}}
+	defer func() { config = &Config{} }()
+
+	day := func(d int) time.Time { return time.Date(2018, 11, d, 9, 0, 0, 0, time.UTC) }
+	sprint := &Sprint{Id: 7, Name: "Sprint 7", State: "closed", StartDate: zonedTimestamp{day(5)}, EndDate: zonedTimestamp{day(16)}, CompleteDate: zonedTimestamp{day(16)}}
+
+	quick := completedIssue("A-1", day(5), day(6))
+	slow := completedIssue("A-2", day(5), day(14))
+	// Blocked Tuesday to Friday, and flagged over part of it:
+	slow.Changelog.Histories = append(slow.Changelog.Histories,
+		History{Created: zonedTimestamp{day(6)}, Items: []HistoryItem{{Field: "status", FromString: inProgressStatus, ToString: "Blocked"}}},
+		History{Created: zonedTimestamp{day(7)}, Items: []HistoryItem{{Field: "Flagged", ToString: "Impediment"}}},
+		History{Created: zonedTimestamp{day(8)}, Items: []HistoryItem{{Field: "Flagged", FromString: "Impediment"}}},
+		History{Created: zonedTimestamp{day(9)}, Items: []HistoryItem{{Field: "status", FromString: "Blocked", ToString: inProgressStatus}}},
+	)
+	slow.Fields.Summary = "Payment retries"
+	carried := Issue{Key: "A-3"}
+	added := completedIssue("A-4", day(7), day(8))
+	added.Changelog.Histories = append([]History{{Created: zonedTimestamp{day(6)}, Items: []HistoryItem{{Field: "Sprint", To: "7"}}}},
+		added.Changelog.Histories...)
+	removed := Issue{Key: "A-5"}
+	removed.Changelog.Histories = []History{{Created: zonedTimestamp{day(8)}, Items: []HistoryItem{{Field: "Sprint", From: "7"}}}}
+
+	r := computeRetro(sprint, []Issue{quick, slow, carried, added, removed}, day(20), []float64{50, 90})
+	if r.Committed != 3 || r.Total != 4 || r.Done != 3 || r.Churn() != 2 {
+		t.Fatalf("expected 3 of 4 done, 3 committed and 2 churned, got %+v", r)
+	}
+	if len(r.CarryOver) != 1 || r.CarryOver[0].Key != "A-3" {
+		t.Fatalf("expected A-3 carried over, got %v", r.CarryOver)
+	}
+	if r.Longest[0].Issue.Key != "A-2" || r.Longest[0].Days != 7 {
+		t.Fatalf("expected A-2 longest at 7 days, got %+v", r.Longest[0])
+	}
+	if len(r.Blocked) != 1 || r.Blocked[0].Days != 3 {
+		t.Fatalf("expected A-2 blocked 3 business days, got %+v", r.Blocked)
+	}
+
+	var out strings.Builder
+	r.writeMarkdown(&out)
+	for _, want := range []string{"# Retro: Sprint 7", "| completed | 3 of 4 (75%) |", "## Carried over (1)", "| cycle time p50 / p90 | 1 / 7 days (n=3) |", "- A-2 Payment retries: 3 business days"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := retroTemplate.Execute(&out, struct {
+		Title string
+		R     *retroPack
+	}{r.title(), r}); err != nil || !strings.Contains(out.String(), "<h2>Most time blocked</h2>") || !strings.Contains(out.String(), "<td>cycle time p50 / p90</td><td>1 / 7 days") {
+		t.Fatalf("expected the HTML retro pack, got %v:\n%s", err, out.String())
+	}
+}
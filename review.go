@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var defaultReviewStatuses = []string{"In Progress - 1", "Code Review", "In Review"}
+
+func isReviewStatus(status string) bool {
+	statuses := config.ReviewStatuses
+	if len(statuses) == 0 {
+		statuses = defaultReviewStatuses
+	}
+	for _, s := range statuses {
+		if strings.EqualFold(s, status) {
+			return true
+		}
+	}
+	return false
+}
+
+// weekOf returns the Monday starting the week containing t:
+func weekOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+type reviewWait struct {
+	Issue   *Issue
+	Entered time.Time
+	Wait    time.Duration
+	Pending bool
+}
+
+// reviewWaits measures how long each entry into a review status waited for the
+// next transition; reviews still waiting are measured up to now.
+func reviewWaits(issues []Issue, now time.Time) []reviewWait {
+	var waits []reviewWait
+	for i := range issues {
+		issue := &issues[i]
+		for _, in := range issue.statusIntervals() {
+			if !isReviewStatus(in.Status) {
+				continue
+			}
+			waits = append(waits, reviewWait{
+				Issue:   issue,
+				Entered: in.Start,
+				Wait:    in.Duration(now),
+				Pending: in.End.IsZero(),
+			})
+		}
+	}
+	return waits
+}
+
+func runReviewWait(args []string) error {
+	fs := flag.NewFlagSet("review-wait", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting issues; default='updated >= -90d'")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("review-wait", *jql, "updated >= -90d"))
+	if err != nil {
+		return err
+	}
+
+	now := reportNow()
+	waits := reviewWaits(issues, now)
+
+	// Group wait times, in minutes, by week entering review:
+	byWeek := make(map[time.Time][]int)
+	var pending []reviewWait
+	for _, w := range waits {
+		if w.Pending {
+			pending = append(pending, w)
+			continue
+		}
+		week := weekOf(w.Entered)
+		byWeek[week] = append(byWeek[week], int(w.Wait.Minutes()))
+	}
+
+	weeks := make([]time.Time, 0, len(byWeek))
+	for week := range byWeek {
+		weeks = append(weeks, week)
+	}
+	sort.Slice(weeks, func(i, j int) bool { return weeks[i].Before(weeks[j]) })
+
+	fmt.Printf("Review wait in hours, by week entering review:\n")
+	fmt.Printf("  %-10s %5s", "week", "count")
+	for _, p := range percentiles {
+		fmt.Printf(" %6s", percentileLabel(p))
+	}
+	fmt.Printf(" %6s\n", "max")
+	for _, week := range weeks {
+		sorted := sortedCopy(byWeek[week])
+		fmt.Printf("  %-10s %5d", week.Format("2006-01-02"), len(sorted))
+		for _, p := range percentiles {
+			fmt.Printf(" %6.1f", float64(percentile(sorted, p))/60)
+		}
+		fmt.Printf(" %6.1f\n", float64(sorted[len(sorted)-1])/60)
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		if pending[i].Wait != pending[j].Wait {
+			return pending[i].Wait > pending[j].Wait
+		}
+		return lessIssueKey(pending[i].Issue.Key, pending[j].Issue.Key)
+	})
+	fmt.Printf("\nWaiting for review now: %d\n", len(pending))
+	shown := detailLines(len(pending))
+	for _, w := range pending[:shown] {
+		fmt.Printf("  %s (%.1f hours since %s); %s\n", w.Issue.Key, w.Wait.Hours(), displayTime(w.Entered).Format("Mon Jan 02 15:04"), w.Issue.DisplaySummary())
+	}
+	printOmitted("  ", shown, len(pending))
+
+	return nil
+}
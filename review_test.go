@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReviewWaits(t *testing.T) {
+	issue := Issue{Key: "A-1"}
+	issue.Fields.Created.Time = time.Date(2018, 11, 1, 9, 0, 0, 0, cst)
+	issue.Changelog.Histories = []History{
+		{
+			Created: zonedTimestamp{time.Date(2018, 11, 5, 9, 0, 0, 0, cst)},
+			Items:   []HistoryItem{{Field: "status", FromString: "In Progress", ToString: "Code Review"}},
+		},
+		{
+			Created: zonedTimestamp{time.Date(2018, 11, 5, 15, 0, 0, 0, cst)},
+			Items:   []HistoryItem{{Field: "status", FromString: "Code Review", ToString: "In Progress"}},
+		},
+		{
+			Created: zonedTimestamp{time.Date(2018, 11, 6, 10, 0, 0, 0, cst)},
+			Items:   []HistoryItem{{Field: "status", FromString: "In Progress", ToString: "Code Review"}},
+		},
+	}
+
+	waits := reviewWaits([]Issue{issue}, time.Date(2018, 11, 6, 12, 0, 0, 0, cst))
+	if len(waits) != 2 {
+		t.Fatalf("expected 2 for waits, got %d", len(waits))
+	}
+	if waits[0].Wait != 6*time.Hour || waits[0].Pending {
+		t.Fatalf("expected completed 6h wait, got %v pending=%v", waits[0].Wait, waits[0].Pending)
+	}
+	if waits[1].Wait != 2*time.Hour || !waits[1].Pending {
+		t.Fatalf("expected pending 2h wait, got %v pending=%v", waits[1].Wait, waits[1].Pending)
+	}
+}
+
+func TestWeekOf_Monday(t *testing.T) {
+	week := weekOf(time.Date(2018, 11, 4, 12, 0, 0, 0, cst))
+	if week.Format("2006-01-02") != "2018-10-29" {
+		t.Fatalf("expected 2018-10-29 for week, got %s", week.Format("2006-01-02"))
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rollingWindow is the flow of the work completed in the last Days days:
+type rollingWindow struct {
+	Days       int
+	Throughput int
+	Bugs       int
+	// CycleTimes are in business days, sorted:
+	CycleTimes []int
+}
+
+// PerWeek is the window's throughput as a weekly rate:
+func (w *rollingWindow) PerWeek() float64 {
+	return float64(w.Throughput) * 7 / float64(w.Days)
+}
+
+// BugRatio is the share of completed work that was bugs:
+func (w *rollingWindow) BugRatio() float64 {
+	return share(w.Bugs, w.Throughput)
+}
+
+func (w *rollingWindow) Label() string {
+	return fmt.Sprintf("%dd", w.Days)
+}
+
+// rollingWindows measures each window of days ending now from the same
+// completed issues:
+func rollingWindows(issues []Issue, now time.Time, days []int) []*rollingWindow {
+	windows := make([]*rollingWindow, len(days))
+	for i, d := range days {
+		windows[i] = &rollingWindow{Days: d}
+	}
+	for i := range issues {
+		issue := &issues[i]
+		completed, ok := issue.CompletedTime()
+		if !ok || issue.IsEpic() || completed.After(now) {
+			continue
+		}
+		cycleTime, started := issue.CycleTime()
+		for _, w := range windows {
+			if completed.Before(now.AddDate(0, 0, -w.Days)) {
+				continue
+			}
+			w.Throughput++
+			if issue.IsBug() {
+				w.Bugs++
+			}
+			if started {
+				w.CycleTimes = append(w.CycleTimes, cycleTime)
+			}
+		}
+	}
+	for _, w := range windows {
+		sort.Ints(w.CycleTimes)
+	}
+	return windows
+}
+
+// parseWindowDays parses a comma-separated list of window lengths in days,
+// shortest first:
+func parseWindowDays(s string) ([]int, error) {
+	var days []int
+	for _, field := range strings.Split(s, ",") {
+		d, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || d < 1 {
+			return nil, fmt.Errorf("-windows: '%s' is not a number of days", strings.TrimSpace(field))
+		}
+		days = append(days, d)
+	}
+	sort.Ints(days)
+	return days, nil
+}
+
+func runRolling(args []string) error {
+	fs := flag.NewFlagSet("rolling", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting completed issues; default covers the longest window")
+	windowsFlag := fs.String("windows", "7,30,90", "comma-separated window lengths in days")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	days, err := parseWindowDays(*windowsFlag)
+	if err != nil {
+		return err
+	}
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	now := reportNow()
+	cl := httpClient()
+	boardId := boardArg(fs.Args())
+	var issues []Issue
+	if *jql != "" {
+		issues, err = fetchBoardIssues(cl, boardId, *jql)
+	} else {
+		issues, err = fetchCompletedSince(cl, boardId, "rolling", now.AddDate(0, 0, -days[len(days)-1]))
+	}
+	if err != nil {
+		return err
+	}
+	windows := rollingWindows(issues, now, days)
+
+	fmt.Printf("Completed work over the last %s, to %s:\n", plural(days[len(days)-1], "day"), displayTime(now).Format("Mon Jan 02"))
+	row := func(metric string, value func(w *rollingWindow) string) {
+		fmt.Printf("  %-16s", metric)
+		for _, w := range windows {
+			fmt.Printf(" %7s", value(w))
+		}
+		fmt.Println()
+	}
+	row("", (*rollingWindow).Label)
+	row("throughput", func(w *rollingWindow) string { return strconv.Itoa(w.Throughput) })
+	row("per week", func(w *rollingWindow) string { return fmt.Sprintf("%.1f", w.PerWeek()) })
+	cycleTime := func(p float64) func(w *rollingWindow) string {
+		return func(w *rollingWindow) string {
+			if len(w.CycleTimes) == 0 {
+				return "-"
+			}
+			return fmt.Sprintf("%dd", percentile(w.CycleTimes, p))
+		}
+	}
+	for _, p := range percentiles {
+		row("cycle time "+percentileLabel(p), cycleTime(p))
+	}
+	row("bug ratio", func(w *rollingWindow) string {
+		if w.Throughput == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.0f%%", 100*w.BugRatio())
+	})
+	fmt.Printf("Cycle times in business days; bug ratio is bugs' share of items completed.\n")
+
+	metrics := make(map[string]map[string]float64, len(windows))
+	for _, w := range windows {
+		m := map[string]float64{
+			"throughput": float64(w.Throughput),
+			"bugRatio":   w.BugRatio(),
+		}
+		if len(w.CycleTimes) > 0 {
+			for _, p := range percentiles {
+				m[percentileMetric("cycleTime", p)] = float64(percentile(w.CycleTimes, p))
+			}
+		}
+		metrics[w.Label()] = m
+	}
+	recordMetric("windows", metrics)
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRollingWindows(t *testing.T) {
+	now := time.Date(2018, 11, 30, 9, 0, 0, 0, cst)
+	daysAgo := func(d int) time.Time { return now.AddDate(0, 0, -d) }
+
+	bug := completedIssue("A-1", daysAgo(5), daysAgo(2))
+	bug.Fields.IssueType.Name = "Bug"
+	issues := []Issue{
+		bug,
+		completedIssue("A-2", daysAgo(20), daysAgo(10)),
+		completedIssue("A-3", daysAgo(70), daysAgo(60)),
+		// Outside every window:
+		completedIssue("A-4", daysAgo(120), daysAgo(100)),
+	}
+	windows := rollingWindows(issues, now, []int{7, 30, 90})
+
+	for i, want := range []struct{ throughput, bugs, cycleTimes int }{{1, 1, 1}, {2, 1, 2}, {3, 1, 3}} {
+		w := windows[i]
+		if w.Throughput != want.throughput || w.Bugs != want.bugs || len(w.CycleTimes) != want.cycleTimes {
+			t.Errorf("%s: expected %+v, got %d completed, %d bugs, %d cycle times", w.Label(), want, w.Throughput, w.Bugs, len(w.CycleTimes))
+		}
+	}
+	if r := windows[1].BugRatio(); r != 0.5 {
+		t.Errorf("expected half the last 30 days' work to be bugs, got %v", r)
+	}
+	if p := windows[0].PerWeek(); p != 1 {
+		t.Errorf("expected 1 a week, got %v", p)
+	}
+}
+
+func TestParseWindowDays(t *testing.T) {
+	days, err := parseWindowDays("30, 7,90")
+	if err != nil || len(days) != 3 || days[0] != 7 || days[2] != 90 {
+		t.Fatalf("expected 7, 30, 90, got %v %v", days, err)
+	}
+	if _, err := parseWindowDays("7,month"); err == nil {
+		t.Fatalf("expected an error for a window that isn't a number")
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// Test vectors from RFC 7914, sections 11 and 12:
+func TestScryptKey(t *testing.T) {
+	for _, c := range []struct {
+		password, salt string
+		N, r, p        int
+		expected       string
+	}{
+		{"", "", 16, 1, 1, "77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906"},
+		{"password", "NaCl", 1024, 8, 16, "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640"},
+	} {
+		key, err := scryptKey([]byte(c.password), []byte(c.salt), c.N, c.r, c.p, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(key); got != c.expected {
+			t.Errorf("scrypt(%q, %q, %d, %d, %d) = %s, want %s", c.password, c.salt, c.N, c.r, c.p, got, c.expected)
+		}
+	}
+	if _, err := scryptKey([]byte("x"), nil, 1000, 8, 1, 32); err == nil {
+		t.Fatalf("expected N that isn't a power of two refused")
+	}
+}
+
+func TestPBKDF2SHA256(t *testing.T) {
+	// RFC 7914 section 11:
+	key := pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1, 64)
+	expected := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if got := hex.EncodeToString(key); got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+	key = pbkdf2SHA256([]byte("Password"), []byte("NaCl"), 80000, 64)
+	expected = "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"
+	if got := hex.EncodeToString(key); got != expected {
+		t.Fatalf("expected %s after 80000 iterations, got %s", expected, got)
+	}
+}
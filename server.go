@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/JamesDunne/jira-analysis/analysis"
+)
+
+// serveMu serializes requests: reports read global state such as the config
+// and metrics, which isn't safe to share between concurrent runs.
+var serveMu sync.Mutex
+
+// gadgetItem is one aging item as served to a Jira dashboard gadget:
+type gadgetItem struct {
+	Key      string    `json:"key"`
+	URL      string    `json:"url"`
+	Summary  string    `json:"summary"`
+	Status   string    `json:"status"`
+	Stage    string    `json:"stage,omitempty"`
+	Assignee string    `json:"assignee"`
+	Since    time.Time `json:"since"`
+	Age      int       `json:"age"`
+	OverSLA  bool      `json:"overSla"`
+	Badges   string    `json:"badges,omitempty"`
+	// Ack is the acknowledgment of the item's breach, while it holds:
+	Ack *alertAck `json:"ack,omitempty"`
+}
+
+type gadgetFeed struct {
+	Generated time.Time    `json:"generated"`
+	Board     int          `json:"board"`
+	Items     []gadgetItem `json:"items"`
+
+	// AckURL and Return let the HTML gadget acknowledge breaches and come
+	// back to itself:
+	AckURL string `json:"-"`
+	Return string `json:"-"`
+}
+
+// agingFeed computes the aging report for a gadget:
+func agingFeed(cl *http.Client, boardId int, sla int) (*gadgetFeed, error) {
+	now := reportNow()
+	reportAcks = nil
+	a := newAnalyzer(cl, analysis.WithStatusMap(agingStages), analysis.WithSLA(sla), analysis.WithNow(now))
+	items, err := agingItems(a, boardId, reportJQL("aging", "", defaultJQL()))
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &gadgetFeed{Generated: now, Board: boardId, Items: []gadgetItem{}}
+	for _, item := range items {
+		var ack *alertAck
+		if item.OverSLA {
+			ack, _ = acknowledged(item.Issue)
+		}
+		feed.Items = append(feed.Items, gadgetItem{
+			Key:      item.Key,
+			URL:      setting(cl, "JIRA_URL") + "/browse/" + item.Key,
+			Summary:  item.Issue.DisplaySummary(),
+			Status:   item.Status,
+			Stage:    item.Stage,
+			Assignee: item.Assignee,
+			Since:    item.Since,
+			Age:      item.Age,
+			OverSLA:  item.OverSLA,
+			Badges:   issueBadges(item.Issue, item.OverSLA, now),
+			Ack:      ack,
+		})
+	}
+	return feed, nil
+}
+
+// gadgetTemplate renders an HTML fragment for an external content gadget;
+// links open in Jira rather than inside the gadget's frame.
+var gadgetTemplate = assetTemplate("gadget.html", nil)
+
+// allowJiraOrigin lets gadgets on the JIRA site fetch the feed cross-origin:
+func allowJiraOrigin(w http.ResponseWriter) {
+	if u, err := url.Parse(os.Getenv("JIRA_URL")); err == nil && u.Host != "" {
+		w.Header().Set("Access-Control-Allow-Origin", u.Scheme+"://"+u.Host)
+	}
+}
+
+func serveAgingGadget(cl *http.Client, format string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		boardId := defaultBoardId()
+		if board := r.URL.Query().Get("board"); board != "" {
+			id, err := strconv.Atoi(board)
+			if err != nil {
+				http.Error(w, "board must be a board ID", http.StatusBadRequest)
+				return
+			}
+			boardId = id
+		}
+		sla, _ := strconv.Atoi(r.URL.Query().Get("sla"))
+
+		serveMu.Lock()
+		feed, err := agingFeed(cl, boardId, sla)
+		flushCacheIndex()
+		serveMu.Unlock()
+		log.Printf("serve: %s: http: %s\n", r.URL, &connections)
+		if err != nil {
+			log.Printf("serve: %s: %v\n", r.URL, err)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		allowJiraOrigin(w)
+		if format == "html" {
+			scheme := "http"
+			if r.TLS != nil {
+				scheme = "https"
+			}
+			feed.AckURL = scheme + "://" + r.Host + "/alerts/ack"
+			feed.Return = r.URL.RequestURI()
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			err = gadgetTemplate.Execute(w, feed)
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+			err = json.NewEncoder(w).Encode(feed)
+		}
+		if err != nil {
+			log.Printf("serve: %s: %v\n", r.URL, err)
+		}
+	}
+}
+
+func newServeMux(cl *http.Client) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/gadget/aging.json", serveAgingGadget(cl, "json"))
+	mux.HandleFunc("/gadget/aging.html", serveAgingGadget(cl, "html"))
+	mux.HandleFunc("/alerts/ack", serveAck(cl))
+	mux.HandleFunc("/alerts/acks", serveAcks)
+	return mux
+}
+
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", defaultServeAddr, "address to listen on; this machine only by default")
+	reload := fs.Duration("reload", 2*time.Second, "how often to check the config file for changes to reload; 0 disables")
+	jobs := fs.Bool("jobs", false, "accept reports to run in the background, such as backfills, at /jobs")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := config.Server.validate(); err != nil {
+		return err
+	}
+	if filename := configFilename(); filename != "" && *reload > 0 {
+		go watchConfig(newConfigWatcher(filename), *reload, nil)
+	}
+
+	mux := newServeMux(httpClient())
+	if *jobs {
+		handler := serveJobs(newJobQueue())
+		mux.HandleFunc("/jobs", handler)
+		mux.HandleFunc("/jobs/", handler)
+	}
+	log.Printf("serve: listening on %s\n", *addr)
+	return http.ListenAndServe(*addr, requireIdentity(mux))
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestServeAgingGadget(t *testing.T) {
+	srv := fixtureServer(t)
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	for key, value := range map[string]string{
+		"JIRA_URL":     srv.URL,
+		"JIRA_NOCACHE": "1",
+		"JIRA_NOW":     "2018-11-06T12:00:00-06:00",
+	} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+	config = &Config{}
+
+	gadget := httptest.NewServer(newServeMux(srv.Client()))
+	defer gadget.Close()
+
+	rsp, err := http.Get(gadget.URL + "/gadget/aging.json?board=1&sla=5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rsp.Body.Close()
+	if got := rsp.Header.Get("Access-Control-Allow-Origin"); got != srv.URL {
+		t.Fatalf("expected the JIRA site allowed to fetch the feed, got %q", got)
+	}
+	var feed gadgetFeed
+	if err = json.NewDecoder(rsp.Body).Decode(&feed); err != nil {
+		t.Fatal(err)
+	}
+	if feed.Board != 1 || len(feed.Items) == 0 {
+		t.Fatalf("expected aging items for board 1, got %+v", feed)
+	}
+	item := feed.Items[0]
+	if item.URL != srv.URL+"/browse/"+item.Key || item.Status == "" {
+		t.Fatalf("expected items linking to their issues, got %+v", item)
+	}
+
+	rsp, err = http.Get(gadget.URL + "/gadget/aging.html?board=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rsp.Body.Close()
+	b, _ := ioutil.ReadAll(rsp.Body)
+	if !strings.HasPrefix(string(b), "<table") || !strings.Contains(string(b), `target="_top">`+item.Key+"</a>") {
+		t.Fatalf("expected an HTML table fragment, got %s", b)
+	}
+
+	if rsp, err = http.Get(gadget.URL + "/gadget/aging.json?board=x"); err != nil || rsp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a bad board ID rejected, got %v %v", rsp.StatusCode, err)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ServerConfig authenticates requests in server mode, which serves issues
+// fetched with the service account's credentials. Every request needs a
+// bearer token or, behind a reverse proxy that signs users in, the identity
+// header it sets.
+type ServerConfig struct {
+	// Tokens maps each bearer token accepted to who it identifies, e.g.
+	// {"<random token>": "dashboards"}:
+	Tokens map[string]string `json:"tokens"`
+	// IdentityHeader is the header a reverse proxy sets to the signed-in
+	// user, e.g. "X-Forwarded-User". It's trusted only from the loopback
+	// interface and TrustedProxies (IP addresses or CIDR ranges):
+	IdentityHeader string   `json:"identityHeader"`
+	TrustedProxies []string `json:"trustedProxies"`
+}
+
+// defaultServeAddr keeps the server to this machine unless -addr says
+// otherwise:
+const defaultServeAddr = "127.0.0.1:8080"
+
+func (s *ServerConfig) validate() error {
+	if len(s.Tokens) == 0 && s.IdentityHeader == "" {
+		return fmt.Errorf("serve: set server.tokens or server.identityHeader in the config to authenticate requests")
+	}
+	for token := range s.Tokens {
+		if len(token) < 16 {
+			return fmt.Errorf("server.tokens: tokens must be at least 16 characters")
+		}
+	}
+	for _, proxy := range s.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				return fmt.Errorf("server.trustedProxies: %q is not an IP address or CIDR range", proxy)
+			}
+		}
+	}
+	return nil
+}
+
+// trustedProxy reports whether a request came straight from a proxy allowed
+// to set the identity header:
+func (s *ServerConfig) trustedProxy(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	if ip.IsLoopback() {
+		return true
+	}
+	for _, proxy := range s.TrustedProxies {
+		if _, network, err := net.ParseCIDR(proxy); err == nil && network.Contains(ip) {
+			return true
+		}
+		if ip.Equal(net.ParseIP(proxy)) {
+			return true
+		}
+	}
+	return false
+}
+
+// requestAuth is who made a request, and whether they presented a token
+// rather than a proxy's word for it:
+type requestAuth struct {
+	Identity string
+	Token    bool
+}
+
+// identify returns who made a request, with no identity if it isn't
+// authenticated:
+func (s *ServerConfig) identify(r *http.Request) requestAuth {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		presented := []byte(strings.TrimSpace(strings.TrimPrefix(auth, "Bearer ")))
+		identity := ""
+		// Every token is compared, in constant time, so timing doesn't
+		// reveal how close a guess came:
+		for token, who := range s.Tokens {
+			if subtle.ConstantTimeCompare(presented, []byte(token)) == 1 {
+				identity = who
+			}
+		}
+		return requestAuth{Identity: identity, Token: identity != ""}
+	}
+	if s.IdentityHeader != "" && s.trustedProxy(r.RemoteAddr) {
+		return requestAuth{Identity: strings.TrimSpace(r.Header.Get(s.IdentityHeader))}
+	}
+	return requestAuth{}
+}
+
+type authKey struct{}
+
+// authOf is how a request that requireIdentity let through authenticated:
+func authOf(r *http.Request) requestAuth {
+	auth, _ := r.Context().Value(authKey{}).(requestAuth)
+	return auth
+}
+
+// requestIdentity is who made a request that requireIdentity let through:
+func requestIdentity(r *http.Request) string {
+	return authOf(r).Identity
+}
+
+// requireIdentity serves only authenticated requests, by the config in
+// effect when each arrives:
+func requireIdentity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveMu.Lock()
+		server := config.Server
+		serveMu.Unlock()
+
+		auth := server.identify(r)
+		if auth.Identity == "" {
+			log.Printf("serve: %s %s: not authenticated\n", r.Method, r.URL.Path)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="jira-analysis"`)
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), authKey{}, auth)))
+	})
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestRequireIdentity(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	config = &Config{Server: ServerConfig{
+		Tokens:         map[string]string{"0123456789abcdef": "dashboards"},
+		IdentityHeader: "X-Forwarded-User",
+		TrustedProxies: []string{"10.0.0.0/8"},
+	}}
+	defer func() { config = &Config{} }()
+
+	handler := requireIdentity(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(requestIdentity(r)))
+	}))
+	serve := func(remoteAddr string, header string, value string) (int, string) {
+		req := httptest.NewRequest(http.MethodGet, "/gadget/aging.json", nil)
+		req.RemoteAddr = remoteAddr
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code, rec.Body.String()
+	}
+
+	for _, c := range []struct {
+		name, remoteAddr, header, value string
+		code                            int
+		identity                        string
+	}{
+		{"no credentials", "127.0.0.1:5000", "", "", http.StatusUnauthorized, ""},
+		{"token", "192.0.2.1:5000", "Authorization", "Bearer 0123456789abcdef", http.StatusOK, "dashboards"},
+		{"wrong token", "192.0.2.1:5000", "Authorization", "Bearer 0123456789abcdeX", http.StatusUnauthorized, ""},
+		{"proxy identity", "10.1.2.3:5000", "X-Forwarded-User", "ann", http.StatusOK, "ann"},
+		{"local proxy identity", "[::1]:5000", "X-Forwarded-User", "bob", http.StatusOK, "bob"},
+		{"untrusted identity", "192.0.2.1:5000", "X-Forwarded-User", "ann", http.StatusUnauthorized, ""},
+	} {
+		code, body := serve(c.remoteAddr, c.header, c.value)
+		if code != c.code || (code == http.StatusOK && body != c.identity) {
+			t.Errorf("%s: got %d %q, want %d %q", c.name, code, body, c.code, c.identity)
+		}
+	}
+}
+
+func TestServerConfigValidate(t *testing.T) {
+	for _, c := range []struct {
+		server ServerConfig
+		ok     bool
+	}{
+		{ServerConfig{}, false},
+		{ServerConfig{Tokens: map[string]string{"short": "x"}}, false},
+		{ServerConfig{Tokens: map[string]string{"0123456789abcdef": "x"}}, true},
+		{ServerConfig{IdentityHeader: "X-Forwarded-User", TrustedProxies: []string{"proxy.local"}}, false},
+		{ServerConfig{IdentityHeader: "X-Forwarded-User", TrustedProxies: []string{"10.0.0.1", "fd00::/8"}}, true},
+	} {
+		if err := c.server.validate(); (err == nil) != c.ok {
+			t.Errorf("validate(%+v) = %v, want ok %v", c.server, err, c.ok)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sheetsURL is the Google Sheets API resource for spreadsheets:
+var sheetsURL = "https://sheets.googleapis.com/v4/spreadsheets/"
+
+const sheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+
+// serviceAccountKey is the JSON key file of a Google service account, which
+// the spreadsheet must be shared with:
+type serviceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+func loadServiceAccountKey(filename string) (*serviceAccountKey, error) {
+	if filename == "" {
+		filename = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if filename == "" {
+		return nil, fmt.Errorf("sheets output needs credentials, a service account key file (or GOOGLE_APPLICATION_CREDENTIALS)")
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	key := &serviceAccountKey{}
+	if err = json.Unmarshal(b, key); err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	return key, nil
+}
+
+// accessToken exchanges a signed JWT assertion for an OAuth access token:
+func (key *serviceAccountKey) accessToken(cl *http.Client, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return "", fmt.Errorf("service account %s: no PEM private key", key.ClientEmail)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return "", fmt.Errorf("service account %s: %v", key.ClientEmail, err)
+	}
+	privateKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", fmt.Errorf("service account %s: not an RSA key", key.ClientEmail)
+	}
+
+	encode := func(v interface{}) string {
+		b, _ := json.Marshal(v)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+	unsigned := encode(map[string]string{"alg": "RS256", "typ": "JWT"}) + "." + encode(map[string]interface{}{
+		"iss":   key.ClientEmail,
+		"scope": sheetsScope,
+		"aud":   key.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+
+	rsp, err := cl.PostForm(key.TokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode >= 300 {
+		return "", newAPIError(rsp.Request, rsp)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err = decodeResponse(rsp.Body, key.TokenURI, &token); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// flattenMetrics flattens nested metrics into dotted names, e.g.
+// "statuses.In Progress.items", one spreadsheet column each:
+func flattenMetrics(metrics map[string]interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(metrics)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]interface{}
+	if err = json.Unmarshal(b, &tree); err != nil {
+		return nil, err
+	}
+
+	flat := make(map[string]interface{})
+	var walk func(prefix string, v interface{})
+	walk = func(prefix string, v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for name, child := range v {
+				walk(prefix+"."+name, child)
+			}
+		case []interface{}:
+			b, _ := json.Marshal(v)
+			flat[prefix] = string(b)
+		default:
+			flat[prefix] = v
+		}
+	}
+	for name, v := range tree {
+		walk(name, v)
+	}
+	return flat, nil
+}
+
+// sheetsSink appends each run's metrics as a row of a Google Sheet. The first
+// row holds the column names; metrics without a column get one added, so
+// columns never shift between runs.
+type sheetsSink struct {
+	spreadsheet string
+	sheet       string
+	credentials string
+	columns     []string
+	cl          *http.Client
+}
+
+func (s *sheetsSink) do(token string, method string, resource string, body interface{}, v interface{}) error {
+	var reqBody []byte
+	if body != nil {
+		var err error
+		if reqBody, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, sheetsURL+url.PathEscape(s.spreadsheet)+"/values/"+resource, bytes.NewReader(reqBody))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	rsp, err := s.cl.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode >= 300 {
+		return newAPIError(req, rsp)
+	}
+	if v == nil {
+		return nil
+	}
+	return decodeResponse(rsp.Body, req.URL.String(), v)
+}
+
+func (s *sheetsSink) Write(out *reportOutput) error {
+	if err := s.write(out); err != nil {
+		return fmt.Errorf("sheets: %w", err)
+	}
+	return nil
+}
+
+func (s *sheetsSink) write(out *reportOutput) error {
+	metrics, err := flattenMetrics(out.Metrics)
+	if err != nil {
+		return err
+	}
+	if len(s.columns) > 0 {
+		wanted := make(map[string]interface{})
+		for _, column := range s.columns {
+			if v, ok := metrics[column]; ok {
+				wanted[column] = v
+			}
+		}
+		metrics = wanted
+	}
+
+	key, err := loadServiceAccountKey(s.credentials)
+	if err != nil {
+		return err
+	}
+	token, err := key.accessToken(s.cl, time.Now())
+	if err != nil {
+		return err
+	}
+
+	sheet := "'" + strings.Replace(s.sheet, "'", "''", -1) + "'"
+	var first struct {
+		Values [][]string `json:"values"`
+	}
+	if err = s.do(token, http.MethodGet, url.PathEscape(sheet+"!1:1"), nil, &first); err != nil {
+		return err
+	}
+
+	header := []string{"generated", "report"}
+	if len(first.Values) > 0 && len(first.Values[0]) > 0 {
+		header = first.Values[0]
+	}
+	known := make(map[string]bool, len(header))
+	for _, column := range header {
+		known[column] = true
+	}
+	var added []string
+	for name := range metrics {
+		if !known[name] {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	if len(added) > 0 || len(first.Values) == 0 {
+		header = append(header, added...)
+		update := map[string]interface{}{"values": [][]string{header}}
+		if err = s.do(token, http.MethodPut, url.PathEscape(sheet+"!1:1")+"?valueInputOption=RAW", update, nil); err != nil {
+			return err
+		}
+	}
+
+	row := make([]interface{}, len(header))
+	for i, column := range header {
+		switch column {
+		case "generated":
+			row[i] = out.Generated.Format("2006-01-02 15:04")
+		case "report":
+			row[i] = strings.TrimSpace(out.Command + " " + strings.Join(out.Args, " "))
+		default:
+			if s, ok := metrics[column].(string); ok {
+				// Entered as if typed, so text must not look like a formula:
+				row[i] = spreadsheetText(s)
+			} else if v, ok := metrics[column]; ok {
+				row[i] = v
+			} else {
+				row[i] = ""
+			}
+		}
+	}
+	appendRow := map[string]interface{}{"values": [][]interface{}{row}}
+	return s.do(token, http.MethodPost, url.PathEscape(sheet)+":append?valueInputOption=USER_ENTERED&insertDataOption=INSERT_ROWS", appendRow, nil)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSheetsSink(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+
+	header := []interface{}{"generated", "report", "wip"}
+	var appended [][]interface{}
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" || strings.Count(r.FormValue("assertion"), ".") != 2 {
+				http.Error(w, "bad assertion", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"access_token": "t0ken"}`))
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var body struct {
+			Values [][]interface{} `json:"values"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		switch {
+		case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/values/'Flow'!1:1"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"values": [][]interface{}{header}})
+		case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/values/'Flow'!1:1"):
+			header = body.Values[0]
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/values/'Flow':append"):
+			appended = append(appended, body.Values...)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	saved := sheetsURL
+	sheetsURL = srv.URL + "/v4/spreadsheets/"
+	defer func() { sheetsURL = saved }()
+
+	keyFile := filepath.Join(t.TempDir(), "key.json")
+	b, _ := json.Marshal(map[string]string{
+		"client_email": "reports@example.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    srv.URL + "/token",
+	})
+	ioutil.WriteFile(keyFile, b, 0600)
+
+	s, err := newSink(SinkConfig{Type: "sheets", Spreadsheet: "abc", Sheet: "Flow", Credentials: keyFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Write(&reportOutput{
+		Command:   "aging",
+		Generated: time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC),
+		Metrics: map[string]interface{}{
+			"items":    3,
+			"statuses": map[string]*statusMetric{"In Progress": {Items: 3, OldestDays: 7}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// New metrics get columns after the existing ones, which keep their place:
+	wantHeader := []interface{}{"generated", "report", "wip", "items", "statuses.In Progress.items", "statuses.In Progress.oldestDays"}
+	if !reflect.DeepEqual(header, wantHeader) {
+		t.Fatalf("expected header %v, got %v", wantHeader, header)
+	}
+	wantRow := []interface{}{"2026-03-02 09:30", "aging", "", 3.0, 3.0, 7.0}
+	if len(appended) != 1 || !reflect.DeepEqual(appended[0], wantRow) {
+		t.Fatalf("expected row %v, got %v", wantRow, appended)
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SinkConfig configures one destination for report output.
+type SinkConfig struct {
+	// Type is "terminal", "file", "slack", "sheets" or "confluence":
+	Type string `json:"type"`
+	// Path is the file to write for the file sink:
+	Path string `json:"path"`
+	// URL is the incoming webhook for the slack sink, or the Confluence base
+	// URL for the confluence sink (default $CONFLUENCE_URL):
+	URL string `json:"url"`
+	// Format is "text", "json" or "csv" (the metrics only); default is json
+	// for *.json files, else text:
+	Format string `json:"format"`
+	// Timezone is the IANA timezone of the recipients, e.g. "Europe/Berlin",
+	// to render timestamps in; default as JIRA reports them.
+	Timezone string `json:"timezone"`
+
+	// Spreadsheet and Sheet (default Sheet1) locate the sheets sink's sheet,
+	// which Credentials, a service account key file (default
+	// $GOOGLE_APPLICATION_CREDENTIALS), must be able to edit. Columns limits
+	// the metrics written; default all.
+	Spreadsheet string   `json:"spreadsheet"`
+	Sheet       string   `json:"sheet"`
+	Credentials string   `json:"credentials"`
+	Columns     []string `json:"columns"`
+
+	// Space is the confluence sink's space key; pages are titled Title
+	// (default the command) and the ISO week, under the Parent page ID.
+	Space  string `json:"space"`
+	Title  string `json:"title"`
+	Parent string `json:"parent"`
+}
+
+// reportOutput is one command's rendered output, as handed to each sink:
+type reportOutput struct {
+	Command   string    `json:"command"`
+	Args      []string  `json:"args"`
+	Generated time.Time `json:"generated"`
+	Text      string    `json:"text"`
+	// Metrics are the headline numbers the report recorded, if any:
+	Metrics map[string]interface{} `json:"metrics,omitempty"`
+}
+
+type sink interface {
+	Write(out *reportOutput) error
+}
+
+func formatOutput(out *reportOutput, format string) ([]byte, error) {
+	switch format {
+	case "", "text":
+		return []byte(out.Text), nil
+	case "json":
+		b, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+	case "csv":
+		return writeMetricsCSV(out.Metrics)
+	default:
+		return nil, fmt.Errorf("unknown output format '%s'", format)
+	}
+}
+
+type writerSink struct {
+	w      io.Writer
+	format string
+}
+
+func (s *writerSink) Write(out *reportOutput) error {
+	b, err := formatOutput(out, s.format)
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(b)
+	return err
+}
+
+type fileSink struct {
+	path   string
+	format string
+}
+
+func (s *fileSink) Write(out *reportOutput) error {
+	b, err := formatOutput(out, s.format)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path, b, 0644)
+}
+
+type slackSink struct {
+	url    string
+	format string
+	cl     *http.Client
+}
+
+func (s *slackSink) Write(out *reportOutput) error {
+	b, err := formatOutput(out, s.format)
+	if err != nil {
+		return err
+	}
+
+	// Preformatted so report columns stay aligned:
+	text := fmt.Sprintf("*%s*\n```\n%s```", slackText(strings.TrimSpace(out.Command+" "+strings.Join(out.Args, " "))), slackCodeBlock(string(b)))
+	// Mentions only notify outside the code block:
+	if mentions := slackMentions(out.Metrics); len(mentions) > 0 {
+		text += "\n" + strings.Join(mentions, "\n")
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return err
+	}
+
+	rsp, err := s.cl.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("slack: %v", err)
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode >= 300 {
+		return fmt.Errorf("slack: HTTP response %s", rsp.Status)
+	}
+	return nil
+}
+
+// newSink builds a sink from its config:
+func newSink(c SinkConfig) (sink, error) {
+	switch c.Type {
+	case "terminal":
+		return &writerSink{w: os.Stdout, format: c.Format}, nil
+	case "file":
+		if c.Path == "" {
+			return nil, fmt.Errorf("file output needs a path")
+		}
+		format := c.Format
+		if format == "" && strings.EqualFold(filepath.Ext(c.Path), ".json") {
+			format = "json"
+		}
+		return &fileSink{path: c.Path, format: format}, nil
+	case "slack":
+		if c.URL == "" {
+			return nil, fmt.Errorf("slack output needs a webhook url")
+		}
+		return &slackSink{url: c.URL, format: c.Format, cl: sinkClient()}, nil
+	case "sheets":
+		if c.Spreadsheet == "" {
+			return nil, fmt.Errorf("sheets output needs a spreadsheet id")
+		}
+		sheet := c.Sheet
+		if sheet == "" {
+			sheet = "Sheet1"
+		}
+		return &sheetsSink{spreadsheet: c.Spreadsheet, sheet: sheet, credentials: c.Credentials, columns: c.Columns, cl: sinkClient()}, nil
+	case "confluence":
+		if c.Space == "" {
+			return nil, fmt.Errorf("confluence output needs a space key")
+		}
+		base := strings.TrimRight(c.URL, "/")
+		if base == "" {
+			base = confluenceURL()
+		}
+		username, token, err := confluenceCredentials()
+		if err != nil {
+			return nil, err
+		}
+		return &confluenceSink{base: base, space: c.Space, title: c.Title, parent: c.Parent, username: username, token: token, cl: sinkClient()}, nil
+	default:
+		return nil, fmt.Errorf("unknown output type '%s'", c.Type)
+	}
+}
+
+// parseOutputSpec parses an -output flag value: "terminal", "json" (JSON to
+// stdout), "file:<path>", "slack:<webhook url>" or
+// "sheets:<spreadsheet id>[/<sheet>]" or "confluence:<space key>".
+func parseOutputSpec(spec string) (SinkConfig, error) {
+	i := strings.Index(spec, ":")
+	if i < 0 {
+		switch spec {
+		case "terminal":
+			return SinkConfig{Type: spec}, nil
+		case "json":
+			return SinkConfig{Type: "terminal", Format: "json"}, nil
+		}
+		return SinkConfig{}, fmt.Errorf("output '%s' should be terminal, json, file:<path>, slack:<url>, sheets:<id> or confluence:<space>", spec)
+	}
+
+	c := SinkConfig{Type: spec[:i]}
+	switch c.Type {
+	case "file":
+		c.Path = spec[i+1:]
+	case "slack":
+		c.URL = spec[i+1:]
+	case "sheets":
+		c.Spreadsheet = spec[i+1:]
+		if j := strings.Index(c.Spreadsheet, "/"); j >= 0 {
+			c.Spreadsheet, c.Sheet = c.Spreadsheet[:j], c.Spreadsheet[j+1:]
+		}
+	case "confluence":
+		c.Space = spec[i+1:]
+	default:
+		return SinkConfig{}, fmt.Errorf("unknown output type '%s'", c.Type)
+	}
+	return c, nil
+}
+
+// outputSpecs collects repeated -output flags:
+type outputSpecs []string
+
+func (l *outputSpecs) String() string {
+	return strings.Join(*l, " ")
+}
+
+func (l *outputSpecs) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+var outputFlags outputSpecs
+
+// sinkGroup is the sinks whose recipients share a timezone, which get the
+// report rendered once for them:
+type sinkGroup struct {
+	location *time.Location
+	sinks    []sink
+}
+
+// outputSinks builds the sinks from -output flags, else the config file,
+// grouped by timezone; none means plain terminal output.
+func outputSinks() ([]sinkGroup, error) {
+	configs := config.Outputs
+	if len(outputFlags) > 0 {
+		configs = nil
+		for _, spec := range outputFlags {
+			c, err := parseOutputSpec(spec)
+			if err != nil {
+				return nil, err
+			}
+			configs = append(configs, c)
+		}
+	}
+
+	var groups []sinkGroup
+	byTimezone := make(map[string]int)
+	for _, c := range configs {
+		s, err := newSink(c)
+		if err != nil {
+			return nil, err
+		}
+		i, ok := byTimezone[c.Timezone]
+		if !ok {
+			location, err := loadTimezone(c.Timezone)
+			if err != nil {
+				return nil, err
+			}
+			i = len(groups)
+			byTimezone[c.Timezone] = i
+			groups = append(groups, sinkGroup{location: location})
+		}
+		groups[i].sinks = append(groups[i].sinks, s)
+	}
+	return groups, nil
+}
+
+// captureOutput runs f with os.Stdout redirected and returns what it printed:
+func captureOutput(f func() error) (string, error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return "", err
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+
+	done := make(chan []byte)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		done <- b
+	}()
+
+	err = f()
+	os.Stdout = stdout
+	w.Close()
+	return string(<-done), err
+}
+
+// fanOut sends the output to every sink, reporting all failures:
+func fanOut(sinks []sink, out *reportOutput) error {
+	var failures []string
+	for _, s := range sinks {
+		if err := s.Write(out); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("output: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
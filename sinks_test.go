@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseOutputSpec(t *testing.T) {
+	c, err := parseOutputSpec("file:out/report.json")
+	if err != nil || c.Type != "file" || c.Path != "out/report.json" {
+		t.Fatalf("expected file sink for out/report.json, got %+v, %v", c, err)
+	}
+
+	c, err = parseOutputSpec("slack:https://hooks.slack.com/services/x")
+	if err != nil || c.Type != "slack" || c.URL != "https://hooks.slack.com/services/x" {
+		t.Fatalf("expected slack sink with webhook url, got %+v, %v", c, err)
+	}
+
+	c, err = parseOutputSpec("sheets:1AbC-d_9/Flow metrics")
+	if err != nil || c.Type != "sheets" || c.Spreadsheet != "1AbC-d_9" || c.Sheet != "Flow metrics" {
+		t.Fatalf("expected sheets sink for spreadsheet and tab, got %+v, %v", c, err)
+	}
+
+	c, err = parseOutputSpec("json")
+	if err != nil || c.Type != "terminal" || c.Format != "json" {
+		t.Fatalf("expected JSON to stdout, got %+v, %v", c, err)
+	}
+
+	if _, err = parseOutputSpec("email:me@example.com"); err == nil {
+		t.Fatalf("expected error for unknown output type")
+	}
+}
+
+func TestFanOut_FileAndSlack(t *testing.T) {
+	var posted map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&posted)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	jsonFile, err := newSink(SinkConfig{Type: "file", Path: filepath.Join(dir, "report.json")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	textFile, err := newSink(SinkConfig{Type: "file", Path: filepath.Join(dir, "report.txt")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slack, err := newSink(SinkConfig{Type: "slack", URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &reportOutput{Command: "aging", Args: []string{"1"}, Text: "In Progress:\n  ABC-1 3\n"}
+	if err = fanOut([]sink{jsonFile, textFile, slack}, out); err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ := ioutil.ReadFile(filepath.Join(dir, "report.json"))
+	decoded := &reportOutput{}
+	if err = json.Unmarshal(b, decoded); err != nil || decoded.Text != out.Text {
+		t.Fatalf("expected JSON file with report text, got %s", b)
+	}
+
+	b, _ = ioutil.ReadFile(filepath.Join(dir, "report.txt"))
+	if string(b) != out.Text {
+		t.Fatalf("expected plain text file, got %q", b)
+	}
+
+	if !strings.Contains(posted["text"], "*aging 1*") || !strings.Contains(posted["text"], "ABC-1 3") {
+		t.Fatalf("expected slack message with report, got %q", posted["text"])
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import "strings"
+
+// SLAPolicy is the most business days in-flight issues it matches may age
+// before breaching, e.g. bugs in QA 2 days, or Sev1 issues 1 day anywhere.
+// Each list matches any of its entries, and an empty list matches anything.
+type SLAPolicy struct {
+	IssueTypes []string `json:"issueTypes,omitempty"`
+	Priorities []string `json:"priorities,omitempty"`
+	// Statuses match the issue's status or the stage it's reported under:
+	Statuses []string `json:"statuses,omitempty"`
+	Days     int      `json:"days"`
+}
+
+func matchesAny(names []string, values ...string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	for _, name := range names {
+		for _, value := range values {
+			if value != "" && strings.EqualFold(name, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (p *SLAPolicy) matches(issue *Issue, status string, stage string) bool {
+	priority := ""
+	if issue.Fields.Priority != nil {
+		priority = issue.Fields.Priority.Name
+	}
+	return matchesAny(p.IssueTypes, issue.Fields.IssueType.Name) &&
+		matchesAny(p.Priorities, priority) &&
+		matchesAny(p.Statuses, status, stage)
+}
+
+// slaThreshold is the business days an issue in the status may age: the
+// tightest of the SLA policies matching it, or fallback when none do.
+func slaThreshold(issue *Issue, status string, stage string, fallback int) int {
+	threshold := 0
+	for i := range config.SLAPolicies {
+		p := &config.SLAPolicies[i]
+		if p.Days > 0 && p.matches(issue, status, stage) && (threshold == 0 || p.Days < threshold) {
+			threshold = p.Days
+		}
+	}
+	if threshold == 0 {
+		return fallback
+	}
+	return threshold
+}
+
+// overSLA reports whether an issue aged past its threshold; 0 is no SLA.
+func overSLA(age int, threshold int) bool {
+	return threshold > 0 && age > threshold
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JamesDunne/jira-analysis/analysis"
+)
+
+func TestSLAThreshold(t *testing.T) {
+	config = &Config{SLAPolicies: []SLAPolicy{
+		{IssueTypes: []string{"Bug"}, Statuses: []string{"QA"}, Days: 2},
+		{IssueTypes: []string{"Story"}, Statuses: []string{"QA"}, Days: 5},
+		{Priorities: []string{"Sev1"}, Days: 1},
+	}}
+	defer func() { config = &Config{} }()
+
+	issue := func(issueType, priority string) *Issue {
+		i := &Issue{Key: "A-1"}
+		i.Fields.IssueType.Name = issueType
+		if priority != "" {
+			i.Fields.Priority = &Priority{Name: priority}
+		}
+		return i
+	}
+	for _, c := range []struct {
+		issue         *Issue
+		status, stage string
+		want          int
+	}{
+		{issue("Bug", ""), "In Testing", "QA", 2},
+		{issue("story", ""), "QA", "", 5},
+		{issue("Story", ""), "In Progress", "In Development", 10},
+		{issue("Task", ""), "QA", "", 10},
+		// Sev1 is tighter than any status policy:
+		{issue("Bug", "Sev1"), "QA", "", 1},
+		{issue("Task", "Sev1"), "In Progress", "", 1},
+	} {
+		if got := slaThreshold(c.issue, c.status, c.stage, 10); got != c.want {
+			t.Errorf("%s %v in %s: expected %d days, got %d", c.issue.Fields.IssueType.Name, c.issue.Fields.Priority, c.status, c.want, got)
+		}
+	}
+}
+
+func TestAgingSLAPolicies(t *testing.T) {
+	config = &Config{SLAPolicies: []SLAPolicy{{IssueTypes: []string{"Bug"}, Statuses: []string{"Dev"}, Days: 2}}}
+	defer func() { config = &Config{} }()
+
+	started := History{
+		Created: zonedTimestamp{time.Date(2018, 11, 1, 9, 0, 0, 0, time.UTC)},
+		Items:   []HistoryItem{{Field: "status", ToString: inProgressStatus}},
+	}
+	bug, story := Issue{Key: "A-1"}, Issue{Key: "A-2"}
+	bug.Fields.IssueType.Name = "Bug"
+	story.Fields.IssueType.Name = "Story"
+	bug.Changelog.Histories = []History{started}
+	story.Changelog.Histories = []History{started}
+
+	now := time.Date(2018, 11, 6, 12, 0, 0, 0, time.UTC)
+	a := newAnalyzer(nil, analysis.WithNow(now), analysis.WithStatusMap(map[string]string{inProgressStatus: "Dev"}), analysis.WithSLA(5))
+	for _, item := range a.AgingOf(inFlightIssues([]Issue{bug, story})) {
+		switch {
+		case item.Key == "A-1" && (item.SLA != 2 || !item.OverSLA):
+			t.Errorf("expected the bug over its 2 day SLA, got %+v", item)
+		case item.Key == "A-2" && (item.SLA != 5 || item.OverSLA):
+			t.Errorf("expected the story within the 5 day SLA, got %+v", item)
+		}
+	}
+}
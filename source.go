@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// issueSource fetches a board's issues, or its backlog when resource is
+// "backlog", from one tracker and maps them onto the JIRA issue model the
+// reports work on.
+type issueSource interface {
+	FetchIssues(cl *http.Client, boardId int, resource string, query string) ([]Issue, error)
+
+	// Describe names the tracker and API version for the report footer:
+	Describe(cl *http.Client) string
+}
+
+type jiraSource struct{}
+
+func (jiraSource) FetchIssues(cl *http.Client, boardId int, resource string, jql string) ([]Issue, error) {
+	issues, err := fetchJiraIssues(cl, boardId, resource, jql)
+	if err != nil {
+		return nil, err
+	}
+	completeChangelogs(cl, issues)
+	qualifyStatusIds(cl, issues)
+	resolveStatusCategories(cl, issues)
+	return issues, nil
+}
+
+// resolveStatusCategories loads status categories when the issues need them:
+// team-managed projects scope status names per project, so are resolved by ID.
+func resolveStatusCategories(cl *http.Client, issues []Issue) {
+	for i := range issues {
+		if issues[i].TeamManaged() || config.WorkStarted == "category" {
+			err := loadStatusCategories(cl)
+			if err != nil {
+				fetched.gap("status categories not fetched (%v); team-managed statuses classified by name", err)
+			}
+			return
+		}
+	}
+}
+
+func (jiraSource) Describe(cl *http.Client) string {
+	info, err := fetchServerInfo(cl)
+	if err != nil {
+		log.Printf("server info: %v\n", err)
+	}
+	return info.source()
+}
+
+type fileSource struct{}
+
+func (fileSource) FetchIssues(cl *http.Client, boardId int, resource string, jql string) ([]Issue, error) {
+	return fetchFileIssues(resource, jql)
+}
+
+// Describe says where the export came from; exports don't record the server:
+func (fileSource) Describe(cl *http.Client) string {
+	if inputFile == "-" {
+		return "read from stdin"
+	}
+	return "read from " + inputFile
+}
+
+// sources are the trackers selectable with JIRA_BACKEND or a profile's backend:
+var sources = map[string]issueSource{
+	"jira":   jiraSource{},
+	"azure":  azureSource{},
+	"github": githubSource{},
+	"linear": linearSource{},
+}
+
+// backendName returns the selected tracker, "jira" by default, "file" when
+// reading an export, "keys" for a list of issues or "merge" for a profile
+// merging others:
+func backendName() string {
+	if inputFile != "" {
+		return "file"
+	}
+	if len(issueKeys) > 0 {
+		return "keys"
+	}
+	if activeProfile != nil && len(activeProfile.Merge) > 0 {
+		return "merge"
+	}
+	if name := strings.ToLower(os.Getenv("JIRA_BACKEND")); name != "" {
+		return name
+	}
+	return "jira"
+}
+
+func currentSource() (issueSource, error) {
+	name := backendName()
+	switch name {
+	case "file":
+		return fileSource{}, nil
+	case "keys":
+		return keysSource{}, nil
+	case "merge":
+		return mergedSource{profiles: activeProfile.Merge}, nil
+	}
+	source, ok := sources[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend '%s'", name)
+	}
+	return source, nil
+}
+
+// externalStyle is the Project.Style of issues mapped from other trackers:
+const externalStyle = "external"
+
+// registerStatusCategory records the category of a status from another
+// tracker, keyed the way adapters fill HistoryItem.From and To, so start and
+// completion are found by category as for team-managed projects.
+func registerStatusCategory(id string, category string) {
+	if statusCategories == nil {
+		statusCategories = make(map[string]string)
+	}
+	statusCategories[id] = category
+}
+
+// statusCategoryOf categorizes a status name from a tracker without status
+// categories, by the statusCategories config or else by common names.
+func statusCategoryOf(status string) string {
+	if category, ok := config.StatusCategories[status]; ok {
+		return category
+	}
+	switch strings.ToLower(status) {
+	case "", "todo", "to do", "backlog", "new", "open", "triage":
+		return "new"
+	case "done", "closed", "complete", "completed", "shipped", "released", "canceled", "cancelled":
+		return "done"
+	}
+	return "indeterminate"
+}
+
+// externalStatus builds the status fields of an issue from another tracker:
+func externalStatus(prefix string, name string, category string) IssueStatus {
+	registerStatusCategory(prefix+name, category)
+	return IssueStatus{Name: name, StatusCategory: StatusCategory{Key: category}}
+}
+
+// statusChange builds a changelog entry for a status transition from another
+// tracker:
+func statusChange(prefix string, from string, to string) HistoryItem {
+	item := HistoryItem{Field: "status", FromString: from, ToString: to, To: prefix + to}
+	if from != "" {
+		item.From = prefix + from
+	}
+	return item
+}
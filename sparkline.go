@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// sparkBlocks draw a value's height in a sparkline, lowest first:
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws one block per value, scaled from the smallest value to the
+// largest, so the trend shows in plain text. NaN, for no value, is a gap.
+func sparkline(values []float64) string {
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if !math.IsNaN(v) {
+			low, high = math.Min(low, v), math.Max(high, v)
+		}
+	}
+	var b strings.Builder
+	for _, v := range values {
+		switch {
+		case math.IsNaN(v):
+			b.WriteRune(' ')
+		case high == low:
+			// Flat lines sit mid-height:
+			b.WriteRune(sparkBlocks[len(sparkBlocks)/2-1])
+		default:
+			b.WriteRune(sparkBlocks[int(math.Round((v-low)/(high-low)*float64(len(sparkBlocks)-1)))])
+		}
+	}
+	return b.String()
+}
+
+// trend is a metric's value at the end of each of the last few weeks, oldest
+// first:
+type trend struct {
+	Name   string
+	Values []float64
+	// Layout formats a value, e.g. "%.0f":
+	Layout string
+}
+
+func (t trend) Sparkline() string {
+	return sparkline(t.Values)
+}
+
+// Last is the latest value, formatted, or "-" for none:
+func (t trend) Last() string {
+	if len(t.Values) == 0 || math.IsNaN(t.Values[len(t.Values)-1]) {
+		return "-"
+	}
+	return fmt.Sprintf(t.Layout, t.Values[len(t.Values)-1])
+}
+
+var defaultQAStatuses = []string{"In Progress - 2", "In Testing"}
+
+func isQAStatus(status string) bool {
+	statuses := config.QAStatuses
+	if len(statuses) == 0 {
+		statuses = defaultQAStatuses
+	}
+	for _, s := range statuses {
+		if strings.EqualFold(s, status) {
+			return true
+		}
+	}
+	return false
+}
+
+// weeklyTrends measures WIP, weekly throughput and the mean business days
+// items in QA had been there at the end of each of the last weeks to now,
+// reconstructed from changelogs. The issues must cover everything completed
+// in those weeks as well as what's in progress.
+func weeklyTrends(issues []Issue, now time.Time, weeks int) []trend {
+	wip := trend{Name: "WIP", Layout: "%.0f"}
+	throughput := trend{Name: "throughput", Layout: "%.0f"}
+	qaAge := trend{Name: "QA age (mean days)", Layout: "%.1f"}
+	for w := weeks - 1; w >= 0; w-- {
+		end := now.AddDate(0, 0, -7*w)
+		start := end.AddDate(0, 0, -7)
+		inProgress, completed, inQA, qaDays := 0, 0, 0, 0
+		for i := range issues {
+			issue := &issues[i]
+			if issue.IsEpic() {
+				continue
+			}
+			done, ok := issue.CompletedTime()
+			if ok && !done.After(end) {
+				if done.After(start) {
+					completed++
+				}
+				continue
+			}
+			if started, ok := issue.StartedTime(); !ok || started.After(end) {
+				continue
+			}
+			inProgress++
+			for _, in := range issue.statusIntervals() {
+				if !in.Start.After(end) && (in.End.IsZero() || in.End.After(end)) && isQAStatus(in.Status) {
+					inQA++
+					qaDays += DateOf(in.Start).BusinessDaysUntil(DateOf(end))
+				}
+			}
+		}
+		wip.Values = append(wip.Values, float64(inProgress))
+		throughput.Values = append(throughput.Values, float64(completed))
+		if inQA > 0 {
+			qaAge.Values = append(qaAge.Values, float64(qaDays)/float64(inQA))
+		} else {
+			qaAge.Values = append(qaAge.Values, math.NaN())
+		}
+	}
+	return []trend{wip, throughput, qaAge}
+}
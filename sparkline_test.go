@@ -0,0 +1,61 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSparkline(t *testing.T) {
+	for _, c := range []struct {
+		values []float64
+		want   string
+	}{
+		{[]float64{1, 2, 3, 4, 5, 6, 7, 8}, "▁▂▃▄▅▆▇█"},
+		{[]float64{0, 10, 5}, "▁█▅"},
+		{[]float64{3, 3, 3}, "▄▄▄"},
+		{[]float64{math.NaN(), 2, 4}, " ▁█"},
+		{nil, ""},
+	} {
+		if got := sparkline(c.values); got != c.want {
+			t.Errorf("sparkline(%v): expected %q, got %q", c.values, c.want, got)
+		}
+	}
+}
+
+func TestWeeklyTrends(t *testing.T) {
+	now := time.Date(2018, 11, 30, 12, 0, 0, 0, time.UTC)
+	started := func(key string, daysAgo int, status string) Issue {
+		issue := Issue{Key: key}
+		issue.Fields.Created.Time = now.AddDate(0, 0, -daysAgo-1)
+		issue.Changelog.Histories = []History{{
+			Created: zonedTimestamp{now.AddDate(0, 0, -daysAgo)},
+			Items:   []HistoryItem{{Field: "status", FromString: "Open", ToString: status}},
+		}}
+		return issue
+	}
+	inQA := started("A-2", 6, inProgressStatus)
+	inQA.Changelog.Histories = append(inQA.Changelog.Histories, History{
+		Created: zonedTimestamp{now.AddDate(0, 0, -3)},
+		Items:   []HistoryItem{{Field: "status", FromString: inProgressStatus, ToString: "In Testing"}},
+	})
+	issues := []Issue{
+		started("A-1", 20, inProgressStatus),
+		inQA,
+		completedIssue("A-3", now.AddDate(0, 0, -12), now.AddDate(0, 0, -2)),
+		completedIssue("A-4", now.AddDate(0, 0, -12), now.AddDate(0, 0, -9)),
+	}
+
+	trends := weeklyTrends(issues, now, 2)
+	wip, throughput, qaAge := trends[0], trends[1], trends[2]
+	// A-3 finished as A-2 started:
+	if wip.Values[0] != 2 || wip.Values[1] != 2 {
+		t.Errorf("expected WIP 2 both weeks, got %v", wip.Values)
+	}
+	if throughput.Values[0] != 1 || throughput.Values[1] != 1 {
+		t.Errorf("expected one completed each week, got %v", throughput.Values)
+	}
+	if !math.IsNaN(qaAge.Values[0]) || qaAge.Last() != "3.0" {
+		t.Errorf("expected nothing in QA, then 3 days, got %v", qaAge.Values)
+	}
+}
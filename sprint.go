@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type Sprint struct {
+	Id           int            `json:"id"`
+	Name         string         `json:"name"`
+	State        string         `json:"state"`
+	Goal         string         `json:"goal"`
+	StartDate    zonedTimestamp `json:"startDate"`
+	EndDate      zonedTimestamp `json:"endDate"`
+	CompleteDate zonedTimestamp `json:"completeDate"`
+}
+
+type PagedSprints struct {
+	StartAt    int      `json:"startAt"`
+	MaxResults int      `json:"maxResults"`
+	IsLast     bool     `json:"isLast"`
+	Values     []Sprint `json:"values"`
+}
+
+// fetchSprints lists the board's sprints in the given state ("active",
+// "closed", "future" or "" for all):
+func fetchSprints(cl *http.Client, boardId int, state string) ([]Sprint, error) {
+	var sprints []Sprint
+	startAt := 0
+	for {
+		cacheFilename := fmt.Sprintf("board.%d.sprints.%s.%d.json", boardId, state, startAt)
+		url := fmt.Sprintf(
+			"%s/rest/agile/1.0/board/%d/sprint?state=%s&startAt=%d",
+			setting(cl, "JIRA_URL"),
+			boardId,
+			state,
+			startAt,
+		)
+
+		body, err := cachedGet(cacheFilename, url, cl)
+		if err != nil {
+			return nil, err
+		}
+
+		paged := &PagedSprints{}
+		err = decodeResponse(body, url, paged)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		sprints = append(sprints, paged.Values...)
+		if paged.IsLast || len(paged.Values) == 0 {
+			break
+		}
+		startAt = paged.StartAt + len(paged.Values)
+	}
+
+	return sprints, nil
+}
+
+// activeSprint returns the board's current sprint:
+func activeSprint(cl *http.Client, boardId int) (*Sprint, error) {
+	sprints, err := fetchSprints(cl, boardId, "active")
+	if err != nil {
+		return nil, err
+	}
+	if len(sprints) == 0 {
+		return nil, fmt.Errorf("board %d has no active sprint", boardId)
+	}
+	return &sprints[0], nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultGoalLabel marks the issues a sprint goal covers, without
+// SprintGoalLabels:
+const defaultGoalLabel = "sprint-goal"
+
+// goalKeyPattern finds issue keys mentioned in a sprint goal, e.g. "Ship
+// ABC-12 to beta":
+var goalKeyPattern = regexp.MustCompile(`\b[A-Z][A-Z0-9_]*-[0-9]+\b`)
+
+func goalLabels() []string {
+	if len(config.SprintGoalLabels) > 0 {
+		return config.SprintGoalLabels
+	}
+	return []string{defaultGoalLabel}
+}
+
+// goalKeys are the issue keys a sprint goal names:
+func goalKeys(goal string) map[string]bool {
+	keys := make(map[string]bool)
+	for _, key := range goalKeyPattern.FindAllString(goal, -1) {
+		keys[key] = true
+	}
+	return keys
+}
+
+// goalRelated reports whether the issue works toward the sprint goal: it
+// carries a goal label, or is, belongs to or is linked with an issue the
+// goal names.
+func (issue *Issue) goalRelated(keys map[string]bool) bool {
+	for _, label := range issue.Fields.Labels {
+		for _, goalLabel := range goalLabels() {
+			if strings.EqualFold(label, goalLabel) {
+				return true
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return false
+	}
+	if keys[issue.Key] || keys[issue.hierarchyParentKey()] {
+		return true
+	}
+	for _, link := range issue.Fields.IssueLinks {
+		if link.InwardIssue != nil && keys[link.InwardIssue.Key] {
+			return true
+		}
+		if link.OutwardIssue != nil && keys[link.OutwardIssue.Key] {
+			return true
+		}
+	}
+	return false
+}
+
+// sprintGoalProgress is how much of a sprint's goal work was done by a time:
+type sprintGoalProgress struct {
+	Sprint      *Sprint
+	Items, Done int
+	// GoalItems and GoalDone count the goal-related items only:
+	GoalItems, GoalDone int
+}
+
+// Completion is the share of goal-related items done, or -1 when nothing is
+// known to relate to the goal:
+func (p *sprintGoalProgress) Completion() float64 {
+	if p.GoalItems == 0 {
+		return -1
+	}
+	return share(p.GoalDone, p.GoalItems)
+}
+
+func computeGoalProgress(sprint *Sprint, issues []Issue, by time.Time) *sprintGoalProgress {
+	p := &sprintGoalProgress{Sprint: sprint}
+	keys := goalKeys(sprint.Goal)
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() && !keys[issue.Key] {
+			continue
+		}
+		completed, ok := issue.CompletedTime()
+		done := ok && !completed.After(by)
+		p.Items++
+		if done {
+			p.Done++
+		}
+		if issue.goalRelated(keys) {
+			p.GoalItems++
+			if done {
+				p.GoalDone++
+			}
+		}
+	}
+	return p
+}
+
+// goalSummary describes goal completion in a line:
+func (p *sprintGoalProgress) goalSummary() string {
+	if p.Completion() < 0 {
+		return fmt.Sprintf("no items labeled %s or named in the goal", strings.Join(goalLabels(), " or "))
+	}
+	return fmt.Sprintf("%3.0f%% (%d of %s)", 100*p.Completion(), p.GoalDone, plural(p.GoalItems, "goal item"))
+}
+
+// sprintEnd is when a sprint's work counts as done by: when it was closed,
+// else its planned end.
+func sprintEnd(sprint *Sprint) time.Time {
+	if !sprint.CompleteDate.IsZero() {
+		return sprint.CompleteDate.Time
+	}
+	return sprint.EndDate.Time
+}
+
+func runSprintGoals(args []string) error {
+	fs := flag.NewFlagSet("sprint goals", flag.ContinueOnError)
+	count := fs.Int("sprints", 6, "number of most recent closed sprints to show, with the active one")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cl := httpClient()
+	boardId := boardArg(fs.Args())
+	closed, err := fetchSprints(cl, boardId, "closed")
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(closed, func(i, j int) bool { return sprintEnd(&closed[i]).Before(sprintEnd(&closed[j])) })
+	if len(closed) > *count {
+		closed = closed[len(closed)-*count:]
+	}
+	active, err := fetchSprints(cl, boardId, "active")
+	if err != nil {
+		return err
+	}
+	sprints := append(closed, active...)
+	if len(sprints) == 0 {
+		fmt.Printf("No sprints.\n")
+		return nil
+	}
+
+	now := reportNow()
+	met, withGoal := 0, 0
+	for i := range sprints {
+		sprint := &sprints[i]
+		issues, err := fetchBoardIssues(cl, boardId, fmt.Sprintf("sprint = %d", sprint.Id))
+		if err != nil {
+			return err
+		}
+		by := now
+		if sprint.State == "closed" {
+			by = sprintEnd(sprint)
+		}
+		p := computeGoalProgress(sprint, issues, by)
+
+		state := ""
+		if sprint.State == "active" {
+			state = " (active)"
+		}
+		fmt.Printf("%s%s, %s to %s\n", sprint.Name, state, displayTime(sprint.StartDate.Time).Format("Mon Jan 02"), displayTime(sprintEnd(sprint)).Format("Mon Jan 02"))
+		if sprint.Goal == "" {
+			fmt.Printf("  goal: (none)\n")
+		} else {
+			fmt.Printf("  goal: %s\n", redact(strings.Join(strings.Fields(sprint.Goal), " ")))
+			fmt.Printf("  goal complete: %s\n", p.goalSummary())
+		}
+		fmt.Printf("  all items:     %3d%% (%d of %s)\n", percentOf(p.Done, p.Items), p.Done, plural(p.Items, "item"))
+
+		if sprint.State == "closed" && p.Completion() >= 0 {
+			withGoal++
+			if p.GoalDone == p.GoalItems {
+				met++
+			}
+		}
+	}
+	if withGoal > 0 {
+		fmt.Printf("\nGoals met in full: %d of %s.\n", met, plural(withGoal, "closed sprint"))
+		recordMetric("goalsMet", share(met, withGoal))
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestComputeGoalProgress(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2018, 11, d, 9, 0, 0, 0, time.UTC) }
+	sprint := &Sprint{Id: 7, Goal: "Ship A-10 to beta", StartDate: zonedTimestamp{day(5)}, EndDate: zonedTimestamp{day(16)}}
+
+	labeled := completedIssue("A-1", day(5), day(6))
+	labeled.Fields.Labels = []string{"Sprint-Goal"}
+	child := completedIssue("A-2", day(5), day(20))
+	child.Fields.Parent = &ParentIssue{Key: "A-10"}
+	linked := Issue{Key: "A-3"}
+	linked.Fields.IssueLinks = []IssueLink{{OutwardIssue: &ParentIssue{Key: "A-10"}}}
+	other := completedIssue("A-4", day(5), day(7))
+
+	issues := []Issue{labeled, child, linked, other}
+	p := computeGoalProgress(sprint, issues, day(16))
+	if p.Items != 4 || p.Done != 2 || p.GoalItems != 3 || p.GoalDone != 1 {
+		t.Fatalf("expected 1 of 3 goal items and 2 of 4 items done by the end, got %+v", *p)
+	}
+	if got := p.goalSummary(); got != " 33% (1 of 3 goal items)" {
+		t.Errorf("unexpected summary %q", got)
+	}
+	// Done after the sprint ended:
+	if p := computeGoalProgress(sprint, issues, day(21)); p.GoalDone != 2 {
+		t.Errorf("expected 2 goal items done by day 21, got %d", p.GoalDone)
+	}
+
+	sprint.Goal = "Polish"
+	if p := computeGoalProgress(sprint, []Issue{other}, day(16)); p.Completion() >= 0 || !strings.Contains(p.goalSummary(), "sprint-goal") {
+		t.Errorf("expected no goal items, got %+v: %s", *p, p.goalSummary())
+	}
+}
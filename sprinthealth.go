@@ -0,0 +1,237 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// atRiskItem is an unfinished sprint item unlikely to finish by the sprint end
+// at past cycle times:
+type atRiskItem struct {
+	Issue  *Issue
+	Age    int
+	Chance float64
+}
+
+type sprintHealth struct {
+	Sprint *Sprint
+
+	Total, Done     int
+	Elapsed, Length int
+
+	NotStarted []*Issue
+	Added      []*Issue
+	AtRisk     []atRiskItem
+	Goal       *sprintGoalProgress
+
+	// FinishChance is the simulated chance of finishing all remaining items by
+	// the sprint end; negative without recent throughput.
+	FinishChance float64
+}
+
+// sprintAtRiskChance is the likelihood of finishing in time below which an
+// item is at risk:
+const sprintAtRiskChance = 0.5
+
+// sprintIds parses a Sprint changelog value, a comma-separated list of IDs:
+func sprintIds(value string) map[string]bool {
+	ids := make(map[string]bool)
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids[id] = true
+		}
+	}
+	return ids
+}
+
+// addedAfter reports whether the issue joined the sprint after it started,
+// either created then or moved in from the backlog or another sprint:
+func (issue *Issue) addedAfter(sprint *Sprint) bool {
+	start := sprint.StartDate.Time
+	if issue.Fields.Created.After(start) {
+		return true
+	}
+
+	id := strconv.Itoa(sprint.Id)
+	for _, h := range issue.Changelog.Histories {
+		if !h.Created.After(start) {
+			continue
+		}
+		for _, item := range h.Items {
+			if item.Field == "Sprint" && sprintIds(item.To)[id] && !sprintIds(item.From)[id] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// finishChance is the share of past cycle times at least age business days
+// long that ended within left more days:
+func finishChance(cycleTimes []int, age int, left int) (float64, bool) {
+	longer, finished := 0, 0
+	for _, ct := range cycleTimes {
+		if ct < age {
+			continue
+		}
+		longer++
+		if ct <= age+left {
+			finished++
+		}
+	}
+	if longer == 0 {
+		// Older than anything finished before:
+		return 0, len(cycleTimes) > 0
+	}
+	return float64(finished) / float64(longer), true
+}
+
+// computeSprintHealth evaluates the sprint's items against the time left,
+// forecasting from the cycle times and throughput of history.
+func computeSprintHealth(sprint *Sprint, issues []Issue, history []Issue, now time.Time, historyDays int) *sprintHealth {
+	today := DateOf(now)
+	end := DateOf(sprint.EndDate.Time)
+	h := &sprintHealth{
+		Sprint:       sprint,
+		Elapsed:      DateOf(sprint.StartDate.Time).BusinessDaysUntil(today),
+		Length:       DateOf(sprint.StartDate.Time).BusinessDaysUntil(end),
+		FinishChance: -1,
+		Goal:         computeGoalProgress(sprint, issues, now),
+	}
+	if h.Elapsed > h.Length {
+		h.Elapsed = h.Length
+	}
+	left := h.Length - h.Elapsed
+
+	var cycleTimes []int
+	for i := range history {
+		if days, ok := history[i].CycleTime(); ok && !history[i].IsEpic() {
+			cycleTimes = append(cycleTimes, days)
+		}
+	}
+
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		h.Total++
+		if issue.addedAfter(sprint) {
+			h.Added = append(h.Added, issue)
+		}
+		if _, done := issue.CompletedTime(); done {
+			h.Done++
+			continue
+		}
+
+		age := 0
+		if started, ok := issue.StartedTime(); ok {
+			age = DateOf(started).BusinessDaysUntil(today)
+		} else {
+			h.NotStarted = append(h.NotStarted, issue)
+		}
+		if chance, ok := finishChance(cycleTimes, age, left); ok && chance < sprintAtRiskChance {
+			h.AtRisk = append(h.AtRisk, atRiskItem{Issue: issue, Age: age, Chance: chance})
+		}
+	}
+	sort.SliceStable(h.AtRisk, func(i, j int) bool { return h.AtRisk[i].Chance < h.AtRisk[j].Chance })
+
+	throughput := dailyThroughput(history, now, historyDays)
+	for _, n := range throughput {
+		if n > 0 {
+			outcomes := monteCarlo(throughput, h.Total-h.Done, 10000, rand.New(rand.NewSource(1)))
+			inTime := sort.SearchInts(outcomes, left+1)
+			h.FinishChance = float64(inTime) / float64(len(outcomes))
+			break
+		}
+	}
+	return h
+}
+
+func percentOf(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return 100 * n / total
+}
+
+func (h *sprintHealth) print() {
+	s := h.Sprint
+	fmt.Printf("Sprint %s (%s to %s)\n", s.Name, displayTime(s.StartDate.Time).Format("Mon Jan 02"), displayTime(s.EndDate.Time).Format("Mon Jan 02"))
+	if s.Goal != "" {
+		fmt.Printf("  goal: %s\n", redact(s.Goal))
+		fmt.Printf("  goal complete: %s\n", h.Goal.goalSummary())
+	}
+	fmt.Printf("  time elapsed: %3d%% (%d of %s)\n", percentOf(h.Elapsed, h.Length), h.Elapsed, plural(h.Length, "business day"))
+	fmt.Printf("  complete:     %3d%% (%d of %s)\n", percentOf(h.Done, h.Total), h.Done, plural(h.Total, "item"))
+	if h.FinishChance >= 0 {
+		fmt.Printf("  chance of finishing all items by the end: %.0f%%\n", 100*h.FinishChance)
+	}
+
+	printIssues := func(issues []*Issue) {
+		shown := detailLines(len(issues))
+		for _, issue := range issues[:shown] {
+			fmt.Printf("  %-10s %s%s\n", issue.Key, issue.DisplaySummary(), noteSuffix(issue))
+		}
+		printOmitted("  ", shown, len(issues))
+	}
+
+	fmt.Printf("\nNot started (%d):\n", len(h.NotStarted))
+	printIssues(h.NotStarted)
+
+	fmt.Printf("\nAdded after the sprint started (%d):\n", len(h.Added))
+	printIssues(h.Added)
+
+	fmt.Printf("\nAt risk, under %.0f%% likely to finish in time at past cycle times (%d):\n", 100*sprintAtRiskChance, len(h.AtRisk))
+	shown := detailLines(len(h.AtRisk))
+	for _, item := range h.AtRisk[:shown] {
+		fmt.Printf("  %-10s age %3d %4.0f%% %s%s\n", item.Issue.Key, item.Age, 100*item.Chance, item.Issue.DisplaySummary(), noteSuffix(item.Issue))
+	}
+	printOmitted("  ", shown, len(h.AtRisk))
+}
+
+var sprintSubcommands = map[string]func(args []string) error{
+	"health": runSprintHealth,
+	"goals":  runSprintGoals,
+	"starts": runSprintStarts,
+}
+
+func runSprint(args []string) error {
+	return runSubcommand("sprint", sprintSubcommands, args)
+}
+
+func runSprintHealth(args []string) error {
+	fs := flag.NewFlagSet("sprint health", flag.ContinueOnError)
+	weeks := fs.Int("weeks", 12, "weeks of completed work to forecast from")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cl := httpClient()
+	boardId := boardArg(fs.Args())
+	sprint, err := activeSprint(cl, boardId)
+	if err != nil {
+		return err
+	}
+
+	issues, err := fetchBoardIssues(cl, boardId, fmt.Sprintf("sprint = %d", sprint.Id))
+	if err != nil {
+		return err
+	}
+	history, err := fetchBoardIssues(cl, boardId, reportJQL("forecast-history", "", fmt.Sprintf("statusCategory = Done AND resolved >= -%dd", 7**weeks)))
+	if err != nil {
+		return err
+	}
+
+	h := computeSprintHealth(sprint, issues, history, reportNow(), 7**weeks)
+	h.print()
+	if h.Goal.Completion() >= 0 {
+		recordMetric("goalCompletion", h.Goal.Completion())
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFinishChance_ConditionalOnAge(t *testing.T) {
+	cycleTimes := []int{1, 2, 3, 5, 8, 13}
+	if chance, ok := finishChance(cycleTimes, 0, 3); !ok || chance != 0.5 {
+		t.Fatalf("expected 3 of 6 within 3 days, got %v %v", chance, ok)
+	}
+	if chance, _ := finishChance(cycleTimes, 5, 3); chance != 2.0/3 {
+		t.Fatalf("expected 2 of the 3 taking 5+ days to end by day 8, got %v", chance)
+	}
+	if chance, ok := finishChance(cycleTimes, 20, 3); !ok || chance != 0 {
+		t.Fatalf("expected no chance for items older than all history, got %v %v", chance, ok)
+	}
+	if _, ok := finishChance(nil, 0, 3); ok {
+		t.Fatalf("expected no estimate without history")
+	}
+}
+
+func TestComputeSprintHealth(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2018, 11, d, 9, 0, 0, 0, time.UTC) }
+	sprint := &Sprint{Id: 7, Name: "Sprint 7", StartDate: zonedTimestamp{day(5)}, EndDate: zonedTimestamp{day(16)}}
+
+	done := completedIssue("A-1", day(5), day(6))
+	inFlight := completedIssue("A-2", day(5), day(6))
+	inFlight.Fields.Status.StatusCategory.Key = "indeterminate"
+	added := Issue{Key: "A-3"}
+	added.Changelog.Histories = []History{
+		{Created: zonedTimestamp{day(7)}, Items: []HistoryItem{{Field: "Sprint", From: "6", To: "6, 7"}}},
+	}
+
+	var history []Issue
+	for d := 1; d <= 4; d++ {
+		// Two business day cycle times:
+		history = append(history, completedIssue("H-1", day(d).AddDate(0, 0, -7), day(d).AddDate(0, 0, -5)))
+	}
+
+	h := computeSprintHealth(sprint, []Issue{done, inFlight, added}, history, day(8), 14)
+	if h.Total != 3 || h.Done != 1 {
+		t.Fatalf("expected 1 of 3 done, got %d of %d", h.Done, h.Total)
+	}
+	if h.Elapsed != 3 || h.Length != 9 {
+		t.Fatalf("expected 3 of 9 business days elapsed, got %d of %d", h.Elapsed, h.Length)
+	}
+	if len(h.NotStarted) != 1 || h.NotStarted[0].Key != "A-3" || len(h.Added) != 1 || h.Added[0].Key != "A-3" {
+		t.Fatalf("expected A-3 added and not started, got %v %v", h.NotStarted, h.Added)
+	}
+	if len(h.AtRisk) != 1 || h.AtRisk[0].Issue.Key != "A-2" {
+		t.Fatalf("expected A-2, already older than past cycle times, at risk, got %+v", h.AtRisk)
+	}
+	if h.FinishChance < 0 {
+		t.Fatalf("expected a finish forecast from history")
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// sprintStart is how many business days into a sprint a committed item was
+// first picked up; items not yet started count the days they've sat so far.
+type sprintStart struct {
+	Issue   *Issue
+	Delay   int
+	Started bool
+}
+
+// sprintStartDelays measures, for each item committed when the sprint began,
+// how far into the sprint it actually entered progress. Items already in
+// progress beforehand started on day 0; items added later aren't measured,
+// since they weren't waiting from the start.
+func sprintStartDelays(sprint *Sprint, issues []Issue, now time.Time) []sprintStart {
+	start := DateOf(sprint.StartDate.Time)
+	until := sprintEnd(sprint)
+	if now.Before(until) {
+		until = now
+	}
+
+	var starts []sprintStart
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() || issue.addedAfter(sprint) {
+			continue
+		}
+		s := sprintStart{Issue: issue}
+		if started, ok := issue.StartedTime(); ok && !started.After(until) {
+			s.Started = true
+			if started.After(sprint.StartDate.Time) {
+				s.Delay = start.BusinessDaysUntil(DateOf(started))
+			}
+		} else {
+			s.Delay = start.BusinessDaysUntil(DateOf(until))
+		}
+		starts = append(starts, s)
+	}
+	sort.SliceStable(starts, func(i, j int) bool {
+		if starts[i].Delay != starts[j].Delay {
+			return starts[i].Delay > starts[j].Delay
+		}
+		return lessIssueKey(starts[i].Issue.Key, starts[j].Issue.Key)
+	})
+	return starts
+}
+
+func runSprintStarts(args []string) error {
+	fs := flag.NewFlagSet("sprint starts", flag.ContinueOnError)
+	count := fs.Int("sprints", 3, "number of most recent closed sprints to show, with the active one")
+	days := fs.Int("days", 2, "business days into the sprint that a committed item starting is late")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *days < 1 {
+		return fmt.Errorf("-days must be at least 1")
+	}
+
+	cl := httpClient()
+	boardId := boardArg(fs.Args())
+	closed, err := fetchSprints(cl, boardId, "closed")
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(closed, func(i, j int) bool { return sprintEnd(&closed[i]).Before(sprintEnd(&closed[j])) })
+	if len(closed) > *count {
+		closed = closed[len(closed)-*count:]
+	}
+	active, err := fetchSprints(cl, boardId, "active")
+	if err != nil {
+		return err
+	}
+	sprints := append(closed, active...)
+	if len(sprints) == 0 {
+		fmt.Printf("No sprints.\n")
+		return nil
+	}
+
+	now := reportNow()
+	var delays []int
+	late := 0
+	for i := range sprints {
+		sprint := &sprints[i]
+		issues, err := fetchBoardIssues(cl, boardId, fmt.Sprintf("sprint = %d", sprint.Id))
+		if err != nil {
+			return err
+		}
+		starts := sprintStartDelays(sprint, issues, now)
+
+		state := ""
+		if sprint.State == "active" {
+			state = " (active)"
+		}
+		fmt.Printf("%s%s, %s to %s\n", sprint.Name, state, displayTime(sprint.StartDate.Time).Format("Mon Jan 02"), displayTime(sprintEnd(sprint)).Format("Mon Jan 02"))
+		if len(starts) == 0 {
+			fmt.Printf("  no items committed at the start\n\n")
+			continue
+		}
+
+		var sprintDelays []int
+		var lateStarts []sprintStart
+		notStarted := 0
+		for _, s := range starts {
+			if !s.Started {
+				notStarted++
+			} else {
+				sprintDelays = append(sprintDelays, s.Delay)
+			}
+			if s.Delay >= *days {
+				lateStarts = append(lateStarts, s)
+			}
+		}
+		delays = append(delays, sprintDelays...)
+		late += len(lateStarts)
+
+		median := "-"
+		if len(sprintDelays) > 0 {
+			median = fmt.Sprintf("day %d", percentile(sortedCopy(sprintDelays), 50))
+		}
+		fmt.Printf(
+			"  %s committed, median start %s; %d (%.0f%%) untouched %d or more business days in, %d not started\n",
+			plural(len(starts), "item"),
+			median,
+			len(lateStarts),
+			100*share(len(lateStarts), len(starts)),
+			*days,
+			notStarted,
+		)
+		if reportVerbosity != execView {
+			shown := detailLines(len(lateStarts))
+			for _, s := range lateStarts[:shown] {
+				when := fmt.Sprintf("day %d", s.Delay)
+				if !s.Started {
+					when = fmt.Sprintf("not started, %d days", s.Delay)
+				}
+				fmt.Printf("    %-10s %-20s %s\n", s.Issue.Key, when, s.Issue.DisplaySummary())
+			}
+			printOmitted("    ", shown, len(lateStarts))
+		}
+		fmt.Println()
+	}
+
+	if len(delays) > 0 {
+		recordMetric("medianStartDelay", percentile(sortedCopy(delays), 50))
+	}
+	recordMetric("lateStarts", late)
+	return nil
+}
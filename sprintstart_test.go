@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSprintStartDelays(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2018, 11, d, 9, 0, 0, 0, time.UTC) }
+	sprint := &Sprint{Id: 7, Name: "Sprint 7", StartDate: zonedTimestamp{day(5)}, EndDate: zonedTimestamp{day(16)}}
+
+	carried := completedIssue("A-1", day(1), day(6))
+	prompt := completedIssue("A-2", day(6), day(7))
+	late := completedIssue("A-3", day(8), day(9))
+	untouched := Issue{Key: "A-4"}
+	added := Issue{Key: "A-5"}
+	added.Fields.Created = zonedTimestamp{day(6)}
+
+	starts := sprintStartDelays(sprint, []Issue{carried, prompt, late, untouched, added}, day(12))
+	expected := []struct {
+		key     string
+		delay   int
+		started bool
+	}{
+		{"A-4", 5, false},
+		{"A-3", 3, true},
+		{"A-2", 1, true},
+		{"A-1", 0, true},
+	}
+	if len(starts) != len(expected) {
+		t.Fatalf("expected %d committed items, got %+v", len(expected), starts)
+	}
+	for i, e := range expected {
+		if s := starts[i]; s.Issue.Key != e.key || s.Delay != e.delay || s.Started != e.started {
+			t.Errorf("expected %s on day %d (started %v), got %s on day %d (started %v)", e.key, e.delay, e.started, s.Issue.Key, s.Delay, s.Started)
+		}
+	}
+}
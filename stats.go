@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/JamesDunne/jira-analysis/analysis"
+)
+
+// percentile returns the nearest-rank p-th percentile of sorted values:
+func percentile(sorted []int, p float64) int {
+	return analysis.Percentile(sorted, p)
+}
+
+// percentileLabel formats a percentile as a column heading, e.g. p85:
+func percentileLabel(p float64) string {
+	return "p" + strconv.FormatFloat(p, 'g', -1, 64)
+}
+
+// percentileMetric names a metric at a percentile, e.g. cycleTimeP85:
+func percentileMetric(name string, p float64) string {
+	return name + "P" + strconv.FormatFloat(p, 'g', -1, 64)
+}
+
+func mean(values []int) float64 {
+	return analysis.Mean(values)
+}
+
+func meanFloat(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// sortedCopy returns values sorted ascending without modifying the input:
+func sortedCopy(values []int) []int {
+	sorted := make([]int, len(values))
+	copy(sorted, values)
+	sort.Ints(sorted)
+	return sorted
+}
+
+// coefficientOfVariation is the standard deviation relative to the mean:
+func coefficientOfVariation(values []int) float64 {
+	m := mean(values)
+	if m == 0 {
+		return 0
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		d := float64(v) - m
+		sum += d * d
+	}
+	return math.Sqrt(sum/float64(len(values))) / m
+}
+
+// zScore is how many standard deviations x lies from the mean of values; zero
+// when the values don't vary.
+func zScore(values []float64, x float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	m := meanFloat(values)
+	sum := 0.0
+	for _, v := range values {
+		d := v - m
+		sum += d * d
+	}
+	sd := math.Sqrt(sum / float64(len(values)))
+	if sd == 0 {
+		return 0
+	}
+	return (x - m) / sd
+}
+
+// confidenceZ is the normal quantile of the 95% confidence intervals reported:
+const confidenceZ = 1.96
+
+// smallSample is the sample size below which intervals are too wide to read
+// a trend from:
+const smallSample = 10
+
+// percentileInterval is the distribution-free 95% confidence interval of the
+// p-th percentile of sorted values: the order statistics whose ranks bound it,
+// by the normal approximation to the binomial.
+func percentileInterval(sorted []int, p float64) (lo, hi int) {
+	if len(sorted) == 0 {
+		return 0, 0
+	}
+
+	n := float64(len(sorted))
+	q := p / 100
+	spread := confidenceZ * math.Sqrt(n*q*(1-q))
+	clamp := func(rank int) int {
+		if rank < 1 {
+			return 1
+		}
+		if rank > len(sorted) {
+			return len(sorted)
+		}
+		return rank
+	}
+	return sorted[clamp(int(math.Floor(n*q-spread)))-1], sorted[clamp(int(math.Ceil(n*q+spread)))-1]
+}
+
+// rateInterval is the 95% confidence interval of a rate of count events over
+// periods, treating the count as Poisson; none seen bounds it by the rule of
+// three.
+func rateInterval(count int, periods float64) (lo, hi float64) {
+	if periods <= 0 {
+		return 0, 0
+	}
+	if count == 0 {
+		return 0, 3 / periods
+	}
+	spread := confidenceZ * math.Sqrt(float64(count))
+	return math.Max(0, float64(count)-spread) / periods, (float64(count) + spread) / periods
+}
+
+// sampleSize formats a sample size, marked † when small; see smallSampleNote.
+func sampleSize(n int) string {
+	if n > 0 && n < smallSample {
+		return strconv.Itoa(n) + "†"
+	}
+	return strconv.Itoa(n)
+}
+
+// smallSampleNote is the legend for the † of small samples:
+func smallSampleNote() string {
+	return fmt.Sprintf("† fewer than %d items: the interval is too wide to read a change from", smallSample)
+}
+
+// formatInterval formats a confidence interval, e.g. "6–12":
+func formatInterval(lo, hi int) string {
+	return strconv.Itoa(lo) + "–" + strconv.Itoa(hi)
+}
+
+// mannWhitney compares two samples with the Mann-Whitney U test, which makes no
+// assumption about the shape of the distributions. It returns the share of
+// pairs in which a's value is below b's, ties counting half, and the two-sided
+// p-value of the samples coming from the same distribution, by the normal
+// approximation with tie and continuity corrections.
+func mannWhitney(a, b []int) (below float64, p float64) {
+	n1, n2 := float64(len(a)), float64(len(b))
+	if n1 == 0 || n2 == 0 {
+		return 0.5, 1
+	}
+
+	type value struct {
+		v     int
+		fromA bool
+	}
+	all := make([]value, 0, len(a)+len(b))
+	for _, v := range a {
+		all = append(all, value{v, true})
+	}
+	for _, v := range b {
+		all = append(all, value{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// Tied values share the mean of their ranks:
+	rankSumA, ties := 0.0, 0.0
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].fromA {
+				rankSumA += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	// uA counts the pairs where a's value is above b's:
+	uA := rankSumA - n1*(n1+1)/2
+	below = 1 - uA/(n1*n2)
+
+	n := n1 + n2
+	sd := math.Sqrt(n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sd == 0 {
+		return below, 1
+	}
+	z := math.Max(0, math.Abs(uA-n1*n2/2)-0.5) / sd
+	return below, math.Erfc(z / math.Sqrt2)
+}
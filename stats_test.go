@@ -0,0 +1,74 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPercentile_NearestRank(t *testing.T) {
+	values := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	cases := map[float64]int{
+		0:   1,
+		50:  5,
+		85:  9,
+		95:  10,
+		100: 10,
+	}
+	for p, expected := range cases {
+		actual := percentile(values, p)
+		if actual != expected {
+			t.Fatalf("expected %d for p%v, got %d", expected, p, actual)
+		}
+	}
+}
+
+func TestPercentile_Empty(t *testing.T) {
+	actual := percentile(nil, 50)
+	if actual != 0 {
+		t.Fatalf("expected 0 for p50, got %d", actual)
+	}
+}
+
+func TestPercentileInterval(t *testing.T) {
+	var values []int
+	for v := 1; v <= 100; v++ {
+		values = append(values, v)
+	}
+	// The median of 100 values lies between the 40th and 60th 95% of the time:
+	if lo, hi := percentileInterval(values, 50); lo != 40 || hi != 60 {
+		t.Fatalf("expected 40–60, got %d–%d", lo, hi)
+	}
+	if lo, hi := percentileInterval([]int{3, 8, 13}, 50); lo != 3 || hi != 13 {
+		t.Fatalf("expected a small sample to span it all, got %d–%d", lo, hi)
+	}
+	if lo, hi := percentileInterval(nil, 50); lo != 0 || hi != 0 {
+		t.Fatalf("expected 0–0 for no values, got %d–%d", lo, hi)
+	}
+}
+
+func TestRateInterval(t *testing.T) {
+	lo, hi := rateInterval(100, 10)
+	if math.Abs(lo-8.04) > 0.01 || math.Abs(hi-11.96) > 0.01 {
+		t.Fatalf("expected 8.04–11.96 per period, got %.2f–%.2f", lo, hi)
+	}
+	if lo, hi := rateInterval(0, 4); lo != 0 || hi != 0.75 {
+		t.Fatalf("expected 0–0.75 by the rule of three, got %.2f–%.2f", lo, hi)
+	}
+}
+
+func TestMannWhitney(t *testing.T) {
+	below, p := mannWhitney([]int{1, 2, 3, 4, 5}, []int{6, 7, 8, 9, 10})
+	if below != 1 || math.Abs(p-0.0122) > 0.0005 {
+		t.Fatalf("expected every pair below and p ≈ 0.0122, got %v and %v", below, p)
+	}
+	// Ties count half:
+	below, p = mannWhitney([]int{3, 3, 3}, []int{3, 3, 3})
+	if below != 0.5 || p != 1 {
+		t.Fatalf("expected identical samples to be indistinguishable, got %v and %v", below, p)
+	}
+	below, _ = mannWhitney([]int{2, 4}, []int{3, 4})
+	if below != 0.625 {
+		t.Fatalf("expected 2.5 of 4 pairs below, counting the tie half, got %v", below)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// canonicalStatus maps a status name of a project's issue through the
+// configured aliases and buckets, so translated, renamed and equivalent
+// statuses aggregate under one name:
+func canonicalStatus(project string, name string) string {
+	// A project's own mapping comes before those for every project:
+	if bucket, ok := statusBucket(project + ":" + name); ok {
+		return bucket
+	}
+	name = statusAlias(name)
+	if bucket, ok := statusBucket(project + ":" + name); ok {
+		return bucket
+	}
+	if bucket, ok := statusBucket(name); ok {
+		return bucket
+	}
+	return name
+}
+
+func statusAlias(name string) string {
+	if canonical, ok := config.StatusAliases[name]; ok {
+		return canonical
+	}
+	for alias, canonical := range config.StatusAliases {
+		if strings.EqualFold(alias, name) {
+			return canonical
+		}
+	}
+	return name
+}
+
+// statusBucket finds the bucket listing a status, or a "PROJECT:status":
+func statusBucket(name string) (string, bool) {
+	for bucket, statuses := range config.StatusBuckets {
+		for _, status := range statuses {
+			if strings.EqualFold(status, name) {
+				return bucket, true
+			}
+		}
+	}
+	return "", false
+}
+
+// validateStatusAliases rejects aliases of aliases, which would map a status
+// differently depending on which name history recorded, and statuses put in
+// more than one bucket:
+func (c *Config) validateStatusAliases() error {
+	for alias, canonical := range c.StatusAliases {
+		for other := range c.StatusAliases {
+			if strings.EqualFold(other, canonical) && !strings.EqualFold(other, alias) {
+				return fmt.Errorf("statusAliases: '%s' maps to '%s', which is itself an alias", alias, canonical)
+			}
+		}
+	}
+
+	buckets := make(map[string]string)
+	for bucket, statuses := range c.StatusBuckets {
+		for _, status := range statuses {
+			if other, ok := buckets[strings.ToLower(status)]; ok && other != bucket {
+				return fmt.Errorf("statusBuckets: '%s' is in both '%s' and '%s'", status, other, bucket)
+			}
+			buckets[strings.ToLower(status)] = bucket
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// switchingStats counts how many distinct issues a person worked on per day
+// and per week they were active:
+type switchingStats struct {
+	Name        string
+	ActiveDays  int
+	DayIssues   int
+	ActiveWeeks int
+	WeekIssues  int
+	WIP         int
+}
+
+// PerDay is the context switching index: distinct issues touched per active
+// day.
+func (s *switchingStats) PerDay() float64 {
+	if s.ActiveDays == 0 {
+		return 0
+	}
+	return float64(s.DayIssues) / float64(s.ActiveDays)
+}
+
+func (s *switchingStats) PerWeek() float64 {
+	if s.ActiveWeeks == 0 {
+		return 0
+	}
+	return float64(s.WeekIssues) / float64(s.ActiveWeeks)
+}
+
+// contextSwitching estimates from the changelogs since the given time which
+// issues each person actively touched each day and week, ordered by the most
+// switching first, along with their current WIP.
+func contextSwitching(issues []Issue, since time.Time) []*switchingStats {
+	type touch struct {
+		Name, Period, Key string
+	}
+	days := make(map[touch]bool)
+	weeks := make(map[touch]bool)
+	byName := make(map[string]*switchingStats)
+	get := func(name string) *switchingStats {
+		s, ok := byName[name]
+		if !ok {
+			s = &switchingStats{Name: name}
+			byName[name] = s
+		}
+		return s
+	}
+
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+		for _, ev := range issue.Transitions() {
+			if ev.Bot || ev.Time.Before(since) {
+				continue
+			}
+			name := users.Name(ev.Author)
+			if name == "" {
+				continue
+			}
+			get(name)
+			days[touch{name, ev.Time.Format("2006-01-02"), issue.Key}] = true
+			weeks[touch{name, weekOf(ev.Time).Format("2006-01-02"), issue.Key}] = true
+		}
+
+		if _, done := issue.CompletedTime(); done {
+			continue
+		}
+		if _, ok := issue.StartedTime(); ok {
+			if name := users.Name(issue.responsible()); name != "" {
+				get(name).WIP++
+			}
+		}
+	}
+
+	activeDays := make(map[touch]bool)
+	for t := range days {
+		byName[t.Name].DayIssues++
+		activeDays[touch{Name: t.Name, Period: t.Period}] = true
+	}
+	for t := range activeDays {
+		byName[t.Name].ActiveDays++
+	}
+	activeWeeks := make(map[touch]bool)
+	for t := range weeks {
+		byName[t.Name].WeekIssues++
+		activeWeeks[touch{Name: t.Name, Period: t.Period}] = true
+	}
+	for t := range activeWeeks {
+		byName[t.Name].ActiveWeeks++
+	}
+
+	stats := make([]*switchingStats, 0, len(byName))
+	for _, s := range byName {
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].PerDay() != stats[j].PerDay() {
+			return stats[i].PerDay() > stats[j].PerDay()
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+func runContextSwitching(args []string) error {
+	fs := flag.NewFlagSet("context-switching", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting issues; default covers issues updated in the last -weeks")
+	weeks := fs.Int("weeks", 4, "weeks of changelog activity to measure")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *weeks < 1 {
+		return fmt.Errorf("-weeks must be at least 1")
+	}
+
+	defaultFilter := fmt.Sprintf("updated >= -%dd OR resolution is EMPTY", 7**weeks)
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("context-switching", *jql, defaultFilter))
+	if err != nil {
+		return err
+	}
+
+	stats := contextSwitching(issues, reportNow().AddDate(0, 0, -7**weeks))
+
+	fmt.Printf("Context switching over the last %s (distinct issues touched per active day and week):\n", plural(*weeks, "week"))
+	fmt.Printf("  %s %7s %8s %11s %4s\n", padRight("", nameWidth(20)), "per day", "per week", "active days", "WIP")
+	shown := detailLines(len(stats))
+	for _, s := range stats[:shown] {
+		fmt.Printf("  %s %7.1f %8.1f %11d %4d\n", padRight(s.Name, nameWidth(20)), s.PerDay(), s.PerWeek(), s.ActiveDays, s.WIP)
+	}
+	printOmitted("  ", shown, len(stats))
+	return nil
+}
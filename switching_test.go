@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContextSwitching(t *testing.T) {
+	users = newUserRegistry()
+	defer func() { users = newUserRegistry() }()
+
+	alice := User{UserName: "alice"}
+	bob := User{UserName: "bob"}
+	at := func(day, hour int) zonedTimestamp {
+		return zonedTimestamp{time.Date(2018, 11, day, hour, 0, 0, 0, time.UTC)}
+	}
+	touched := func(key string, by User, times ...zonedTimestamp) Issue {
+		issue := Issue{Key: key}
+		for i, created := range times {
+			issue.Changelog.Histories = append(issue.Changelog.Histories, History{
+				Author:  by,
+				Created: created,
+				Items:   []HistoryItem{{Field: "labels", ToString: string(rune('a' + i))}},
+			})
+		}
+		return issue
+	}
+
+	// Alice works three issues on Monday Nov 5 and one on Tuesday; Bob one
+	// issue both days:
+	issues := []Issue{
+		touched("A-1", alice, at(5, 9), at(5, 10), at(6, 9)),
+		touched("A-2", alice, at(5, 11)),
+		touched("A-3", alice, at(5, 14)),
+		touched("A-4", bob, at(5, 9), at(6, 9)),
+		// Before the window:
+		touched("A-5", bob, at(1, 9)),
+	}
+
+	stats := contextSwitching(issues, time.Date(2018, 11, 5, 0, 0, 0, 0, time.UTC))
+	if len(stats) != 2 || stats[0].Name != "alice" {
+		t.Fatalf("expected alice switching most, got %+v", stats)
+	}
+	if a := stats[0]; a.ActiveDays != 2 || a.PerDay() != 2 || a.PerWeek() != 3 {
+		t.Fatalf("expected alice at 2 issues a day and 3 a week, got %+v", a)
+	}
+	if b := stats[1]; b.ActiveDays != 2 || b.PerDay() != 1 || b.PerWeek() != 1 {
+		t.Fatalf("expected bob at 1 issue a day and week, got %+v", b)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TagRule derives a tag from issue summaries matching Pattern. When the pattern
+// has a capture group, the captured text is appended, e.g. "customer:acme".
+type TagRule struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+
+	re *regexp.Regexp
+}
+
+// compileTagRules validates the configured tag rules' patterns:
+func (c *Config) compileTagRules() error {
+	for i := range c.TagRules {
+		rule := &c.TagRules[i]
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return fmt.Errorf("tag '%s': %v", rule.Name, err)
+		}
+		rule.re = re
+	}
+	return nil
+}
+
+// Tags returns the tags derived from the issue's summary, plus the source it
+// came from in a merged run, sorted:
+func (issue *Issue) Tags() []string {
+	var tags []string
+	if issue.Source != "" {
+		tags = append(tags, "source:"+issue.Source)
+	}
+	for _, rule := range config.TagRules {
+		if rule.re == nil {
+			continue
+		}
+
+		match := rule.re.FindStringSubmatch(issue.Fields.Summary)
+		if match == nil {
+			continue
+		}
+
+		tag := rule.Name
+		if len(match) > 1 && match[1] != "" {
+			tag += ":" + strings.ToLower(match[1])
+		}
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// hasTag matches a tag exactly or by rule name, so "customer" matches
+// "customer:acme":
+func (issue *Issue) hasTag(name string) bool {
+	for _, tag := range issue.Tags() {
+		if tag == name || strings.HasPrefix(tag, name+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// tagSuffix formats an issue's tags for appending to a report line:
+func tagSuffix(issue *Issue) string {
+	tags := issue.Tags()
+	if len(tags) == 0 {
+		return ""
+	}
+	return " [" + redact(strings.Join(tags, ", ")) + "]"
+}
+
+// tagList is a comma-separated list flag:
+type tagList []string
+
+func (l *tagList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *tagList) Set(value string) error {
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			*l = append(*l, tag)
+		}
+	}
+	return nil
+}
+
+// Global -tag/-exclude-tag filters applied to every report:
+var includeTags, excludeTags tagList
+
+// filterTags keeps issues carrying all included tags and none of the excluded:
+func filterTags(issues []Issue) []Issue {
+	if len(includeTags) == 0 && len(excludeTags) == 0 {
+		return issues
+	}
+
+	filtered := make([]Issue, 0, len(issues))
+next:
+	for i := range issues {
+		issue := &issues[i]
+		for _, tag := range includeTags {
+			if !issue.hasTag(tag) {
+				continue next
+			}
+		}
+		for _, tag := range excludeTags {
+			if issue.hasTag(tag) {
+				continue next
+			}
+		}
+		filtered = append(filtered, *issue)
+	}
+	return filtered
+}
+
+func runTags(args []string) error {
+	fs := flag.NewFlagSet("tags", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting issues; default='resolved >= -90d OR resolution is EMPTY'")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("tags", *jql, "resolved >= -90d OR resolution is EMPTY"))
+	if err != nil {
+		return err
+	}
+
+	type tagStats struct {
+		Open       int
+		Ages       []int
+		CycleTimes []int
+	}
+
+	today := DateOf(reportNow())
+	byTag := make(map[string]*tagStats)
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+
+		tags := issue.Tags()
+		if len(tags) == 0 {
+			tags = []string{"(untagged)"}
+		}
+		for _, tag := range tags {
+			stats, ok := byTag[tag]
+			if !ok {
+				stats = &tagStats{}
+				byTag[tag] = stats
+			}
+
+			if days, ok := issue.CycleTime(); ok {
+				stats.CycleTimes = append(stats.CycleTimes, days)
+			} else if _, done := issue.CompletedTime(); !done {
+				stats.Open++
+				stats.Ages = append(stats.Ages, DateOf(issue.Fields.Created.Time).BusinessDaysUntil(today))
+			}
+		}
+	}
+
+	tags := make([]string, 0, len(byTag))
+	for tag := range byTag {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	fmt.Printf("Issues by tag (age since created and cycle time in business days):\n")
+	fmt.Printf("  %s %5s %7s %9s", padRight("tag", nameWidth(24)), "open", "max age", "completed")
+	for _, p := range percentiles {
+		fmt.Printf(" %5s", "ct "+percentileLabel(p))
+	}
+	fmt.Println()
+	for _, tag := range tags {
+		stats := byTag[tag]
+		maxAge := 0
+		for _, age := range stats.Ages {
+			if age > maxAge {
+				maxAge = age
+			}
+		}
+
+		sorted := sortedCopy(stats.CycleTimes)
+		fmt.Printf("  %s %5d %7d %9d", padRight(redact(tag), nameWidth(24)), stats.Open, maxAge, len(sorted))
+		for _, p := range percentiles {
+			fmt.Printf(" %5d", percentile(sorted, p))
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
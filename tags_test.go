@@ -0,0 +1,49 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIssue_Tags(t *testing.T) {
+	config = &Config{TagRules: []TagRule{
+		{Name: "hotfix", Pattern: `(?i)\bhotfix\b`},
+		{Name: "customer", Pattern: `(?i)customer:\s*(\w+)`},
+	}}
+	defer func() { config = &Config{} }()
+	if err := config.compileTagRules(); err != nil {
+		t.Fatal(err)
+	}
+
+	issue := &Issue{}
+	issue.Fields.Summary = "HOTFIX: login fails for Customer: ACME"
+
+	expected := []string{"customer:acme", "hotfix"}
+	if tags := issue.Tags(); !reflect.DeepEqual(tags, expected) {
+		t.Fatalf("expected %v for tags, got %v", expected, tags)
+	}
+	if !issue.hasTag("customer") {
+		t.Fatalf("expected issue to have customer tag")
+	}
+}
+
+func TestFilterTags(t *testing.T) {
+	config = &Config{TagRules: []TagRule{{Name: "debt", Pattern: `(?i)tech debt`}}}
+	includeTags, excludeTags = nil, tagList{"debt"}
+	defer func() {
+		config = &Config{}
+		excludeTags = nil
+	}()
+	if err := config.compileTagRules(); err != nil {
+		t.Fatal(err)
+	}
+
+	issues := []Issue{{Key: "A-1"}, {Key: "A-2"}}
+	issues[0].Fields.Summary = "Tech debt: remove old API"
+	issues[1].Fields.Summary = "Add feature"
+
+	filtered := filterTags(issues)
+	if len(filtered) != 1 || filtered[0].Key != "A-2" {
+		t.Fatalf("expected only A-2, got %v", filtered)
+	}
+}
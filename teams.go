@@ -0,0 +1,158 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+)
+
+func (c *Config) TeamNames() []string {
+	names := make([]string, 0, len(c.Teams))
+	for name := range c.Teams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// responsible returns who the issue is attributed to: its assignee, else whoever
+// last changed its status other than a bot.
+func (issue *Issue) responsible() User {
+	if issue.Fields.Assignee != nil {
+		return *issue.Fields.Assignee
+	}
+	statuses := issue.StatusTransitions()
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if !statuses[i].Bot {
+			return statuses[i].Author
+		}
+	}
+	return User{}
+}
+
+// teamOf finds the configured team the user belongs to, or "":
+func teamOf(u User) string {
+	name := users.Name(u)
+	if name == "" {
+		return ""
+	}
+	for _, team := range config.TeamNames() {
+		for _, member := range config.Teams[team] {
+			if users.NameOf(member) == name {
+				return team
+			}
+		}
+	}
+	return ""
+}
+
+// teamFilter is the global -team filter applied to every report:
+var teamFilter string
+
+func filterTeam(issues []Issue) []Issue {
+	if teamFilter == "" {
+		return issues
+	}
+
+	filtered := make([]Issue, 0, len(issues))
+	for i := range issues {
+		if teamOf(issues[i].responsible()) == teamFilter {
+			filtered = append(filtered, issues[i])
+		}
+	}
+	return filtered
+}
+
+func runTeams(args []string) error {
+	fs := flag.NewFlagSet("teams", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting issues; default='resolved >= -90d OR resolution is EMPTY'")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(config.Teams) == 0 {
+		return fmt.Errorf("no teams configured")
+	}
+
+	percentiles, err := config.ReportPercentiles()
+	if err != nil {
+		return err
+	}
+
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("teams", *jql, "resolved >= -90d OR resolution is EMPTY"))
+	if err != nil {
+		return err
+	}
+
+	type teamStats struct {
+		WIP        int
+		Oldest     int
+		CycleTimes []int
+	}
+
+	today := DateOf(reportNow())
+	byTeam := make(map[string]*teamStats)
+	for i := range issues {
+		issue := &issues[i]
+		if issue.IsEpic() {
+			continue
+		}
+
+		team := teamOf(issue.responsible())
+		if team == "" {
+			team = "(no team)"
+		}
+		stats, ok := byTeam[team]
+		if !ok {
+			stats = &teamStats{}
+			byTeam[team] = stats
+		}
+
+		if days, ok := issue.CycleTime(); ok {
+			stats.CycleTimes = append(stats.CycleTimes, days)
+			continue
+		}
+		if started, ok := issue.StartedTime(); ok {
+			if _, done := issue.CompletedTime(); !done {
+				stats.WIP++
+				if age := DateOf(started).BusinessDaysUntil(today); age > stats.Oldest {
+					stats.Oldest = age
+				}
+			}
+		}
+	}
+
+	teams := make([]string, 0, len(byTeam))
+	for team := range byTeam {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+
+	fmt.Printf("By team (ages and cycle time in business days):\n")
+	fmt.Printf("  %s %4s %6s %9s", padRight("team", nameWidth(16)), "WIP", "oldest", "completed")
+	for _, p := range percentiles {
+		fmt.Printf(" %6s", "ct "+percentileLabel(p))
+	}
+	fmt.Printf(" %10s\n", "p50 95% CI")
+	small := false
+	for _, team := range teams {
+		stats := byTeam[team]
+		sorted := sortedCopy(stats.CycleTimes)
+		if len(sorted) > 0 && len(sorted) < smallSample {
+			small = true
+		}
+		fmt.Printf("  %s %4d %6d %9s", padRight(team, nameWidth(16)), stats.WIP, stats.Oldest, sampleSize(len(sorted)))
+		for _, p := range percentiles {
+			fmt.Printf(" %6d", percentile(sorted, p))
+		}
+		ci := ""
+		if len(sorted) > 0 {
+			ci = formatInterval(percentileInterval(sorted, 50))
+		}
+		fmt.Printf(" %10s\n", ci)
+	}
+	if small {
+		fmt.Println(smallSampleNote())
+	}
+
+	return nil
+}
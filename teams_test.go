@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestFilterTeam(t *testing.T) {
+	config = &Config{Teams: map[string][]string{"core": {"alice", "bob@example.com"}}}
+	users = newUserRegistry()
+	defer func() {
+		config = &Config{}
+		users = newUserRegistry()
+		teamFilter = ""
+	}()
+
+	alice := &User{UserName: "alice"}
+	bob := &User{UserName: "bob", EmailAddress: "bob@example.com"}
+	carol := &User{UserName: "carol"}
+	issues := []Issue{{Key: "A-1"}, {Key: "A-2"}, {Key: "A-3"}}
+	issues[0].Fields.Assignee = alice
+	issues[1].Fields.Assignee = bob
+	issues[2].Fields.Assignee = carol
+	users.registerUsers(issues)
+
+	teamFilter = "core"
+	filtered := filterTeam(issues)
+	if len(filtered) != 2 || filtered[0].Key != "A-1" || filtered[1].Key != "A-2" {
+		t.Fatalf("expected A-1 and A-2 in core team, got %v", filtered)
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// widthFlag is the -width flag: the columns to fit report lines to, 0 to
+// detect the terminal's, or negative to leave lines whole.
+var widthFlag int
+
+// outputWidth is the columns report lines are fitted to, 0 for no limit:
+// -width, else $COLUMNS or the terminal's width when stdout is a terminal.
+func outputWidth() int {
+	if widthFlag != 0 {
+		if widthFlag < 0 {
+			return 0
+		}
+		return widthFlag
+	}
+	if !isTerminal(os.Stdout) {
+		return 0
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return terminalWidth(os.Stdout)
+}
+
+// validateOverflow checks the overflow setting, returning whether lines are
+// truncated rather than wrapped:
+func validateOverflow() (bool, error) {
+	switch config.Overflow {
+	case "", "wrap":
+		return false, nil
+	case "truncate":
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown overflow '%s'; expected wrap or truncate", config.Overflow)
+	}
+}
+
+// fitLines fits each line of text to width columns, truncating it or
+// wrapping it at spaces with its continuation indented past the line's own.
+func fitLines(text string, width int, truncate bool) string {
+	lines := strings.SplitAfter(text, "\n")
+	var b strings.Builder
+	for _, line := range lines {
+		content := strings.TrimSuffix(line, "\n")
+		newline := line[len(content):]
+		if displayWidth(content) <= width {
+			b.WriteString(line)
+			continue
+		}
+		if truncate {
+			b.WriteString(truncateWidth(content, width) + newline)
+			continue
+		}
+		b.WriteString(strings.Join(wrapLine(content, width), "\n") + newline)
+	}
+	return b.String()
+}
+
+// wrapLine breaks a line longer than width at the last space that fits, or
+// mid-word when there is none:
+func wrapLine(line string, width int) []string {
+	indent := len(line) - len(strings.TrimLeft(line, " ")) + 2
+	if indent > width/2 {
+		indent = width / 2
+	}
+	var parts []string
+	prefix := ""
+	for rest := line; rest != ""; {
+		room := width - displayWidth(prefix)
+		if displayWidth(rest) <= room {
+			parts = append(parts, prefix+rest)
+			break
+		}
+		cut, brk, used := 0, -1, 0
+		for i, r := range rest {
+			w := runeWidth(r)
+			if used+w > room {
+				break
+			}
+			used += w
+			cut = i + utf8.RuneLen(r)
+			if r == ' ' && strings.TrimSpace(rest[:i]) != "" {
+				brk = i
+			}
+		}
+		if cut == 0 {
+			// A character wider than the room left:
+			_, cut = utf8.DecodeRuneInString(rest)
+		}
+		if brk > 0 && rest[cut] != ' ' {
+			cut = brk
+		}
+		parts = append(parts, prefix+strings.TrimRight(rest[:cut], " "))
+		rest = strings.TrimLeft(rest[cut:], " ")
+		prefix = strings.Repeat(" ", indent)
+	}
+	return parts
+}
+
+// fitted runs a report fitting its lines to the output width; commands
+// without the footer, such as the server and interactive ones, print as they
+// go instead.
+func fitted(cmd *command, run func() error) (func() error, error) {
+	truncate, err := validateOverflow()
+	if err != nil {
+		return nil, err
+	}
+	width := outputWidth()
+	if cmd.NoFooter || width <= 0 {
+		return run, nil
+	}
+	return func() error {
+		out, err := captureOutput(run)
+		os.Stdout.WriteString(fitLines(out, width, truncate))
+		return err
+	}, nil
+}
@@ -0,0 +1,11 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package main
+
+import "os"
+
+// terminalWidth is unknown here; $COLUMNS or -width set it instead.
+func terminalWidth(f *os.File) int {
+	return 0
+}
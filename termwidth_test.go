@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestFitLines(t *testing.T) {
+	text := "Status: [\n  bob: ABC-1 (3 days old) an overly long summary here\n]\npartial"
+	wrapped := "Status: [\n  bob: ABC-1 (3 days\n    old) an overly\n    long summary\n    here\n]\npartial"
+	if got := fitLines(text, 20, false); got != wrapped {
+		t.Errorf("expected wrapped:\n%s\ngot:\n%s", wrapped, got)
+	}
+
+	truncated := "Status: [\n  bob: ABC-1 (3 day…\n]\npartial"
+	if got := fitLines(text, 20, true); got != truncated {
+		t.Errorf("expected truncated:\n%s\ngot:\n%s", truncated, got)
+	}
+
+	// Words longer than the width are broken, counting wide characters:
+	if got := fitLines("ab漢字漢字", 5, false); got != "ab漢\n  字\n  漢\n  字" {
+		t.Errorf("expected a broken word, got %q", got)
+	}
+}
+
+func TestOutputWidth(t *testing.T) {
+	defer func() { widthFlag = 0 }()
+	widthFlag = 60
+	if got := outputWidth(); got != 60 {
+		t.Errorf("expected -width 60, got %d", got)
+	}
+	widthFlag = -1
+	if got := outputWidth(); got != 0 {
+		t.Errorf("expected no limit, got %d", got)
+	}
+}
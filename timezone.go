@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"time"
+	// Windows machines may lack a timezone database:
+	_ "time/tzdata"
+)
+
+// reportLocation is the timezone timestamps are rendered in for the
+// recipients a report is being rendered for; nil leaves them as JIRA gave
+// them.
+var reportLocation *time.Location
+
+// displayTime converts a timestamp to the recipients' timezone for display;
+// business day arithmetic always uses the original.
+func displayTime(t time.Time) time.Time {
+	if reportLocation == nil {
+		return t
+	}
+	return t.In(reportLocation)
+}
+
+// loadTimezone loads an IANA timezone name; "" is no timezone.
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("timezone '%s': %v", name, err)
+	}
+	return location, nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunCLI_RendersPerTimezone(t *testing.T) {
+	srv := fixtureServer(t)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	ioutil.WriteFile("config.json", []byte(`{"outputs": [
+		{"type": "file", "path": "chicago.txt"},
+		{"type": "file", "path": "tokyo.txt", "timezone": "Asia/Tokyo"},
+		{"type": "file", "path": "tokyo2.txt", "timezone": "Asia/Tokyo"}
+	]}`), 0600)
+	for key, value := range map[string]string{
+		"JIRA_URL":     srv.URL,
+		"JIRA_CONFIG":  filepath.Join(dir, "config.json"),
+		"JIRA_NOCACHE": "1",
+		"JIRA_NOW":     "2018-11-06T20:00:00-06:00",
+	} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+	defer func() { config = &Config{}; fetched = fetchStats{} }()
+
+	if err := runCLI([]string{"aging", "1"}); err != nil {
+		t.Fatal(err)
+	}
+	read := func(name string) string {
+		b, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	chicago, tokyo := read("chicago.txt"), read("tokyo.txt")
+	if !strings.HasPrefix(chicago, "Now: Tue Nov 06\n") {
+		t.Fatalf("expected the default rendering in JIRA's timezone, got %q", chicago[:40])
+	}
+	if !strings.HasPrefix(tokyo, "Now: Wed Nov 07\n") || !strings.Contains(tokyo, " JST") {
+		t.Fatalf("expected the Tokyo rendering a day ahead, got %q", tokyo)
+	}
+	if read("tokyo2.txt") != tokyo {
+		t.Fatalf("expected recipients sharing a timezone to get the same rendering")
+	}
+	footer := func(s string) string { return s[strings.Index(s, "issues analyzed"):strings.Index(s, ", fetched")] }
+	if footer(tokyo) != footer(chicago) {
+		t.Fatalf("expected each rendering's footer to describe one fetch:\n%s", tokyo)
+	}
+}
+
+func TestRunCLI_WritesOncePerRun(t *testing.T) {
+	srv := fixtureServer(t)
+	defer srv.Close()
+	posts := 0
+	fixtures := srv.Config.Handler
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			posts++
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		fixtures.ServeHTTP(w, r)
+	})
+
+	dir := t.TempDir()
+	wd, _ := os.Getwd()
+	os.Chdir(dir)
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	ioutil.WriteFile("config.json", []byte(`{"outputs": [
+		{"type": "file", "path": "chicago.txt"},
+		{"type": "file", "path": "tokyo.txt", "timezone": "Asia/Tokyo"}
+	]}`), 0600)
+	for key, value := range map[string]string{
+		"JIRA_URL":     srv.URL,
+		"JIRA_CONFIG":  filepath.Join(dir, "config.json"),
+		"JIRA_NOCACHE": "1",
+		"JIRA_NOW":     "2018-11-06T20:00:00-06:00",
+	} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+	defer func() { config = &Config{}; fetched = fetchStats{} }()
+
+	if err := runCLI([]string{"nudge", "-post", "-days", "1", "1"}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := loadAudit(auditLogFilename())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posts == 0 || posts != len(entries) {
+		t.Fatalf("expected each comment posted and audited once across both timezones, got %d posts and %d audit entries", posts, len(entries))
+	}
+
+	chicago, _ := ioutil.ReadFile("chicago.txt")
+	tokyo, _ := ioutil.ReadFile("tokyo.txt")
+	commented := func(b []byte) int { return strings.Count(string(b), "commented on ") }
+	if commented(chicago) != posts || commented(tokyo) != posts {
+		t.Fatalf("expected both renderings to list the %d comments made, got:\n%s\n%s", posts, chicago, tokyo)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+// TransitionEvent is one field change from an issue's changelog, normalized so
+// reports need not walk raw histories: events are in time order, same-second
+// duplicates and no-op changes are dropped, and bot changes are flagged.
+type TransitionEvent struct {
+	HistoryItem
+	Time   time.Time
+	Author User
+
+	// Bot is set for changes made by automation; they count toward status
+	// timing but not toward who worked on the issue.
+	Bot bool
+}
+
+func (ev *TransitionEvent) IsStatus() bool {
+	return ev.Field == "status"
+}
+
+// isBot reports whether the user is an automation account, either by its Cloud
+// account type or by being listed in the bots config.
+func isBot(u User) bool {
+	if u.AccountType == "app" {
+		return true
+	}
+	if len(config.Bots) == 0 {
+		return false
+	}
+	name := users.Name(u)
+	for _, bot := range config.Bots {
+		if users.NameOf(bot) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Transitions returns the issue's normalized changelog, computed once:
+func (issue *Issue) Transitions() []TransitionEvent {
+	if issue.transitions == nil {
+		issue.transitions = normalizeChangelog(issue.Fields.Project.Key, issue.Changelog.Histories)
+	}
+	return issue.transitions
+}
+
+// StatusTransitions returns only the status changes, also computed once since
+// reports ask for them per issue many times over:
+func (issue *Issue) StatusTransitions() []TransitionEvent {
+	if issue.statusTransitions == nil {
+		events := issue.Transitions()
+		n := 0
+		for i := range events {
+			if events[i].IsStatus() {
+				n++
+			}
+		}
+		statuses := make([]TransitionEvent, 0, n)
+		for i := range events {
+			if events[i].IsStatus() {
+				statuses = append(statuses, events[i])
+			}
+		}
+		issue.statusTransitions = statuses
+	}
+	return issue.statusTransitions
+}
+
+func normalizeChangelog(project string, histories []History) []TransitionEvent {
+	items := 0
+	for i := range histories {
+		items += len(histories[i].Items)
+	}
+	events := make([]TransitionEvent, 0, items)
+	for _, history := range histories {
+		bot := isBot(history.Author)
+		for _, item := range history.Items {
+			if item.Field == "status" {
+				item.FromString = canonicalStatus(project, item.FromString)
+				item.ToString = canonicalStatus(project, item.ToString)
+				// Between aliases of the same status, or statuses in a bucket:
+				if item.FromString == item.ToString {
+					continue
+				}
+			}
+			// No-op changes are noise from integrations re-saving fields:
+			if item.From == item.To && item.FromString == item.ToString {
+				continue
+			}
+			// Bots only matter where they move work along:
+			if bot && item.Field != "status" {
+				continue
+			}
+			events = append(events, TransitionEvent{
+				HistoryItem: item,
+				Time:        history.Created.Time,
+				Author:      history.Author,
+				Bot:         bot,
+			})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	// Drop the same change recorded twice within a second, e.g. by a retried
+	// webhook or a double-submitted transition:
+	deduped := events[:0]
+	for _, ev := range events {
+		duplicate := false
+		for k := len(deduped) - 1; k >= 0; k-- {
+			prev := &deduped[k]
+			if !prev.Time.Truncate(time.Second).Equal(ev.Time.Truncate(time.Second)) {
+				break
+			}
+			if prev.HistoryItem == ev.HistoryItem {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			deduped = append(deduped, ev)
+		}
+	}
+	return deduped
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIssue_Transitions_Normalized(t *testing.T) {
+	config = &Config{Bots: []string{"jira-bot"}}
+	users = newUserRegistry()
+	defer func() {
+		config = &Config{}
+		users = newUserRegistry()
+	}()
+
+	alice := User{UserName: "alice"}
+	bot := User{UserName: "jira-bot"}
+	users.Add(alice)
+	users.Add(bot)
+
+	at := func(hour, min, sec, ms int) zonedTimestamp {
+		return zonedTimestamp{time.Date(2018, 11, 5, hour, min, sec, ms*int(time.Millisecond), time.UTC)}
+	}
+	toProgress := HistoryItem{Field: "status", From: "1", FromString: "Open", To: "3", ToString: "In Progress"}
+
+	issue := &Issue{}
+	issue.Changelog.Histories = []History{
+		{Author: bot, Created: at(11, 0, 0, 0), Items: []HistoryItem{{Field: "status", From: "3", FromString: "In Progress", To: "5", ToString: "Done"}}},
+		{Author: alice, Created: at(9, 0, 0, 100), Items: []HistoryItem{toProgress}},
+		{Author: alice, Created: at(9, 0, 0, 900), Items: []HistoryItem{toProgress}},
+		{Author: alice, Created: at(10, 0, 0, 0), Items: []HistoryItem{{Field: "labels", FromString: "a", ToString: "a"}}},
+		{Author: bot, Created: at(10, 30, 0, 0), Items: []HistoryItem{{Field: "labels", FromString: "", ToString: "synced"}}},
+	}
+
+	events := issue.Transitions()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events after dedup and noise filtering, got %+v", events)
+	}
+	if events[0].ToString != "In Progress" || events[0].Bot {
+		t.Fatalf("expected first event to be alice starting work, got %+v", events[0])
+	}
+	if events[1].ToString != "Done" || !events[1].Bot {
+		t.Fatalf("expected last event to be the bot completing, got %+v", events[1])
+	}
+
+	if u := issue.responsible(); u.UserName != "alice" {
+		t.Fatalf("expected bot transitions not to attribute work, got %+v", u)
+	}
+}
+
+func TestIssue_Transitions_StatusAliases(t *testing.T) {
+	config = &Config{StatusAliases: map[string]string{"In Arbeit": "In Progress", "In Progress - 1": "Code Review"}}
+	defer func() { config = &Config{} }()
+
+	at := func(day int) zonedTimestamp { return zonedTimestamp{time.Date(2018, 11, day, 9, 0, 0, 0, time.UTC)} }
+	issue := &Issue{}
+	issue.Changelog.Histories = []History{
+		{Created: at(1), Items: []HistoryItem{{Field: "status", From: "1", FromString: "Open", To: "7", ToString: "in arbeit"}}},
+		{Created: at(2), Items: []HistoryItem{{Field: "status", From: "7", FromString: "In Arbeit", To: "3", ToString: "In Progress"}}},
+		{Created: at(5), Items: []HistoryItem{{Field: "status", From: "3", FromString: "In Progress", To: "4", ToString: "In Progress - 1"}}},
+	}
+
+	events := issue.StatusTransitions()
+	if len(events) != 2 {
+		t.Fatalf("expected the move between aliases dropped, got %+v", events)
+	}
+	if events[0].ToString != "In Progress" || events[1].FromString != "In Progress" || events[1].ToString != "Code Review" {
+		t.Fatalf("expected statuses under their canonical names, got %+v", events)
+	}
+}
+
+func TestCanonicalStatus_Buckets(t *testing.T) {
+	config = &Config{
+		StatusAliases: map[string]string{"In Arbeit": "In Progress"},
+		StatusBuckets: map[string][]string{
+			"Development": {"In Progress", "Doing"},
+			"Code Review": {"PR", "OPS:Review"},
+			"QA":          {"Review"},
+		},
+	}
+	defer func() { config = &Config{} }()
+
+	for _, c := range []struct {
+		project, status, expected string
+	}{
+		{"ABC", "doing", "Development"},
+		{"ABC", "In Arbeit", "Development"},
+		{"ABC", "PR", "Code Review"},
+		{"OPS", "Review", "Code Review"},
+		{"ABC", "Review", "QA"},
+		{"ABC", "Done", "Done"},
+	} {
+		if got := canonicalStatus(c.project, c.status); got != c.expected {
+			t.Errorf("expected %s %s reported as %s, got %s", c.project, c.status, c.expected, got)
+		}
+	}
+
+	// Moves within a bucket aren't transitions:
+	issue := &Issue{}
+	issue.Fields.Project.Key = "ABC"
+	issue.Changelog.Histories = []History{
+		{Items: []HistoryItem{{Field: "status", FromString: "Open", ToString: "Doing"}}},
+		{Items: []HistoryItem{{Field: "status", FromString: "Doing", ToString: "In Progress"}}},
+	}
+	if events := issue.StatusTransitions(); len(events) != 1 || events[0].ToString != "Development" {
+		t.Fatalf("expected one move into Development, got %+v", events)
+	}
+}
+
+func TestConfig_ValidateStatusAliases(t *testing.T) {
+	c := &Config{StatusAliases: map[string]string{"In Arbeit": "In Progress", "In Progress": "Doing"}}
+	if err := c.validateStatusAliases(); err == nil {
+		t.Fatalf("expected an alias of an alias to be rejected")
+	}
+	c.StatusAliases = map[string]string{"In Arbeit": "In Progress", "En cours": "In Progress"}
+	if err := c.validateStatusAliases(); err != nil {
+		t.Fatalf("expected aliases to validate, got %v", err)
+	}
+	c.StatusBuckets = map[string][]string{"Development": {"Doing"}, "Code Review": {"PR", "doing"}}
+	if err := c.validateStatusAliases(); err == nil {
+		t.Fatalf("expected a status in two buckets to be rejected")
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// userRegistry links the identities a person appears under (Server username,
+// Cloud accountId, email) so per-person metrics aren't split across them.
+// Identities are linked when one User record carries several of them, or by the
+// configured userAliases.
+type userRegistry struct {
+	parent       map[string]string
+	usernames    map[string][]string
+	displayNames map[string][]string
+}
+
+var users = newUserRegistry()
+
+func newUserRegistry() *userRegistry {
+	return &userRegistry{
+		parent:       make(map[string]string),
+		usernames:    make(map[string][]string),
+		displayNames: make(map[string][]string),
+	}
+}
+
+func identityKeys(u User) []string {
+	var keys []string
+	if u.UserName != "" {
+		keys = append(keys, "name:"+strings.ToLower(u.UserName))
+	}
+	if u.AccountId != "" {
+		keys = append(keys, "account:"+u.AccountId)
+	}
+	if u.EmailAddress != "" {
+		keys = append(keys, "email:"+strings.ToLower(u.EmailAddress))
+	}
+	return keys
+}
+
+func (r *userRegistry) find(key string) string {
+	for {
+		p, ok := r.parent[key]
+		if !ok || p == key {
+			return key
+		}
+		// Path halving:
+		if pp, ok := r.parent[p]; ok {
+			r.parent[key] = pp
+		}
+		key = p
+	}
+}
+
+func (r *userRegistry) union(a, b string) {
+	ra, rb := r.find(a), r.find(b)
+	if ra == rb {
+		return
+	}
+	// Aliases stay at the root; otherwise keep the smaller key as root so
+	// grouping is deterministic:
+	aliasA, aliasB := strings.HasPrefix(ra, "alias:"), strings.HasPrefix(rb, "alias:")
+	if aliasB && !aliasA || aliasA == aliasB && rb < ra {
+		ra, rb = rb, ra
+	}
+	r.parent[rb] = ra
+	r.usernames[ra] = append(r.usernames[ra], r.usernames[rb]...)
+	r.displayNames[ra] = append(r.displayNames[ra], r.displayNames[rb]...)
+	delete(r.usernames, rb)
+	delete(r.displayNames, rb)
+}
+
+// Add records a user and links the identities it carries.
+func (r *userRegistry) Add(u User) {
+	keys := identityKeys(u)
+	if len(keys) == 0 {
+		return
+	}
+	for _, key := range keys {
+		if _, ok := r.parent[key]; !ok {
+			r.parent[key] = key
+		}
+	}
+	for _, key := range keys[1:] {
+		r.union(keys[0], key)
+	}
+
+	root := r.find(keys[0])
+	if u.UserName != "" {
+		r.usernames[root] = append(r.usernames[root], u.UserName)
+	}
+	if u.DisplayName != "" {
+		r.displayNames[root] = append(r.displayNames[root], u.DisplayName)
+	}
+}
+
+// AddAliases links each configured identity (username, accountId or email)
+// to the person named by its alias.
+func (r *userRegistry) AddAliases(aliases map[string]string) {
+	identities := make([]string, 0, len(aliases))
+	for identity := range aliases {
+		identities = append(identities, identity)
+	}
+	sort.Strings(identities)
+
+	for _, identity := range identities {
+		alias := "alias:" + aliases[identity]
+		if _, ok := r.parent[alias]; !ok {
+			r.parent[alias] = alias
+		}
+		for _, key := range []string{"name:" + strings.ToLower(identity), "account:" + identity, "email:" + strings.ToLower(identity)} {
+			if _, ok := r.parent[key]; !ok {
+				r.parent[key] = key
+			}
+			r.union(alias, key)
+		}
+	}
+}
+
+// Name returns the single name a person is reported under: their alias, else
+// their Server username, else display name, else email or accountId.
+func (r *userRegistry) Name(u User) string {
+	keys := identityKeys(u)
+	if len(keys) == 0 {
+		return u.DisplayName
+	}
+	root := r.find(keys[0])
+	if strings.HasPrefix(root, "alias:") {
+		return strings.TrimPrefix(root, "alias:")
+	}
+	if len(r.usernames[root]) > 0 {
+		return r.usernames[root][0]
+	}
+	if len(r.displayNames[root]) > 0 {
+		return r.displayNames[root][0]
+	}
+	if u.UserName != "" {
+		return u.UserName
+	}
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	if u.EmailAddress != "" {
+		return u.EmailAddress
+	}
+	return u.AccountId
+}
+
+// NameOf returns the reported name for a bare identity string, which may be a
+// username, accountId or email:
+func (r *userRegistry) NameOf(identity string) string {
+	for _, key := range []string{"name:" + strings.ToLower(identity), "account:" + identity, "email:" + strings.ToLower(identity)} {
+		if _, ok := r.parent[key]; !ok {
+			continue
+		}
+		root := r.find(key)
+		if strings.HasPrefix(root, "alias:") {
+			return strings.TrimPrefix(root, "alias:")
+		}
+		if len(r.usernames[root]) > 0 {
+			return r.usernames[root][0]
+		}
+		if len(r.displayNames[root]) > 0 {
+			return r.displayNames[root][0]
+		}
+	}
+	return identity
+}
+
+// registerUsers adds every user referenced by the issues:
+func (r *userRegistry) registerUsers(issues []Issue) {
+	for i := range issues {
+		issue := &issues[i]
+		if issue.Fields.Assignee != nil {
+			r.Add(*issue.Fields.Assignee)
+		}
+		for _, history := range issue.Changelog.Histories {
+			r.Add(history.Author)
+		}
+	}
+}
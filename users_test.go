@@ -0,0 +1,37 @@
+package main
+
+import "testing"
+
+func TestUserRegistry_LinksServerAndCloudIdentities(t *testing.T) {
+	r := newUserRegistry()
+	r.Add(User{UserName: "jdoe", EmailAddress: "John.Doe@example.com", DisplayName: "John Doe"})
+	r.Add(User{AccountId: "557058:abc", EmailAddress: "john.doe@example.com", DisplayName: "John Doe"})
+
+	name := r.Name(User{AccountId: "557058:abc"})
+	if name != "jdoe" {
+		t.Fatalf("expected jdoe for Cloud account, got '%s'", name)
+	}
+}
+
+func TestUserRegistry_Aliases(t *testing.T) {
+	r := newUserRegistry()
+	r.AddAliases(map[string]string{
+		"jdoe":       "john",
+		"557058:abc": "john",
+	})
+	r.Add(User{UserName: "jdoe", DisplayName: "John Doe"})
+	r.Add(User{AccountId: "557058:abc", DisplayName: "John D."})
+
+	for _, u := range []User{{UserName: "jdoe"}, {AccountId: "557058:abc"}} {
+		if name := r.Name(u); name != "john" {
+			t.Fatalf("expected john for %+v, got '%s'", u, name)
+		}
+	}
+}
+
+func TestUserRegistry_UnknownUser(t *testing.T) {
+	r := newUserRegistry()
+	if name := r.Name(User{DisplayName: "Bot"}); name != "Bot" {
+		t.Fatalf("expected Bot for display-only user, got '%s'", name)
+	}
+}
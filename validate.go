@@ -0,0 +1,253 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// validation tallies the problems config validate finds, printing each check
+// as it goes:
+type validation struct {
+	problems int
+}
+
+func (v *validation) check(name string, problems ...string) {
+	if len(problems) == 0 {
+		fmt.Printf("ok    %s\n", name)
+		return
+	}
+	v.problems += len(problems)
+	fmt.Printf("FAIL  %s\n", name)
+	for _, problem := range problems {
+		fmt.Printf("      %s\n", problem)
+	}
+}
+
+// configuredJQL lists every JQL filter the config and environment set, by
+// where each is set:
+func configuredJQL() map[string]string {
+	queries := map[string]string{"JIRA_JQL": defaultJQL()}
+	for report, jql := range config.ReportJQL {
+		queries["reportJql."+report] = jql
+	}
+	if activeProfile != nil {
+		for report, jql := range activeProfile.ReportJQL {
+			queries["profiles."+profileName+".reportJql."+report] = jql
+		}
+	}
+	return queries
+}
+
+// configuredStatuses lists every status name the config refers to, by where
+// each is set. Alias keys are left out since they name statuses that may no
+// longer exist.
+func configuredStatuses() map[string][]string {
+	statuses := make(map[string][]string)
+	add := func(setting string, names ...string) {
+		for _, name := range names {
+			statuses[setting] = append(statuses[setting], name)
+		}
+	}
+	add("startStatuses", config.StartStatuses...)
+	add("reviewStatuses", config.ReviewStatuses...)
+	add("qaStatuses", config.QAStatuses...)
+	add("badges.blockedStatuses", config.Badges.BlockedStatuses...)
+	for _, name := range config.StatusAliases {
+		add("statusAliases", name)
+	}
+	for _, names := range config.StatusBuckets {
+		for _, name := range names {
+			// Less the project a status is qualified by:
+			add("statusBuckets", name[strings.Index(name, ":")+1:])
+		}
+	}
+	for name := range config.StatusClasses {
+		add("statusClasses", name)
+	}
+	for name := range config.Nudge.Thresholds {
+		add("nudge.thresholds", name)
+	}
+	for _, names := range statuses {
+		sort.Strings(names)
+	}
+	return statuses
+}
+
+var configSubcommands = map[string]func(args []string) error{
+	"validate": runConfigValidate,
+}
+
+func runConfigCommand(args []string) error {
+	return runSubcommand("config", configSubcommands, args)
+}
+
+// runConfigValidate checks the config against the live instance, uncached, so
+// a scheduled run's mistakes surface before it fails silently.
+func runConfigValidate(args []string) error {
+	fs := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if backend := backendName(); backend != "jira" {
+		return fmt.Errorf("config validate checks a JIRA instance, not the %s backend", backend)
+	}
+
+	cl := httpClient()
+	api := setting(cl, "JIRA_URL") + "/rest/api/2/"
+	v := &validation{}
+
+	var myself struct {
+		Name        string `json:"name"`
+		AccountId   string `json:"accountId"`
+		DisplayName string `json:"displayName"`
+	}
+	if err := doJSON(cl, http.MethodGet, api+"myself", nil, &myself); err != nil {
+		v.check("credentials", fmt.Sprintf("%v; check JIRA_URL, JIRA_USERNAME and JIRA_PASSWORD (an API token on Cloud)", err))
+		return fmt.Errorf("%s found", plural(v.problems, "problem"))
+	}
+	v.check("credentials: logged in as " + myself.DisplayName)
+
+	boardId := boardArg(fs.Args())
+	if id := savedFilterId(cl); id != 0 {
+		var filter savedFilter
+		if err := doJSON(cl, http.MethodGet, fmt.Sprintf("%sfilter/%d", api, id), nil, &filter); err != nil {
+			v.check(fmt.Sprintf("filter %d", id), fmt.Sprintf("%v; set -filter or JIRA_FILTER to a saved filter shared with you", err))
+		} else {
+			v.check(fmt.Sprintf("filter %d: %s", id, filter.Name))
+		}
+	} else {
+		var board Board
+		if err := doJSON(cl, http.MethodGet, fmt.Sprintf("%s/rest/agile/1.0/board/%d", setting(cl, "JIRA_URL"), boardId), nil, &board); err != nil {
+			v.check(fmt.Sprintf("board %d", boardId), fmt.Sprintf("%v; set JIRA_BOARDID to a board you can view (see boards list)", err))
+		} else {
+			v.check(fmt.Sprintf("board %d: %s", boardId, board.Name))
+		}
+	}
+
+	// JQL is checked strictly, so unknown statuses and fields are errors
+	// rather than silently matching nothing:
+	queries := configuredJQL()
+	var settings []string
+	for setting := range queries {
+		settings = append(settings, setting)
+	}
+	sort.Strings(settings)
+	var expanded []string
+	var parsed []string
+	for _, setting := range settings {
+		jql, err := expandJQL(cl, boardId, queries[setting], reportNow())
+		if err != nil {
+			v.check("JQL "+setting, err.Error())
+			continue
+		}
+		expanded = append(expanded, jql)
+		parsed = append(parsed, setting)
+	}
+	if len(expanded) > 0 {
+		var result struct {
+			Queries []struct {
+				Errors []string `json:"errors"`
+			} `json:"queries"`
+		}
+		err := doJSON(cl, http.MethodPost, api+"jql/parse?validation=strict", map[string]interface{}{"queries": expanded}, &result)
+		if err != nil {
+			v.check("JQL", err.Error())
+		} else {
+			for i, setting := range parsed {
+				var problems []string
+				if i < len(result.Queries) {
+					problems = result.Queries[i].Errors
+				}
+				v.check("JQL "+setting, problems...)
+			}
+		}
+	}
+
+	var fields []fieldDetail
+	if err := doJSON(cl, http.MethodGet, api+"field", nil, &fields); err != nil {
+		v.check("fields", err.Error())
+	} else {
+		ids := make(map[string]bool)
+		names := make(map[string]bool)
+		for _, f := range fields {
+			ids[f.Id] = true
+			names[strings.ToLower(f.Name)] = true
+		}
+		for _, field := range []struct{ setting, id string }{
+			{"severityField", config.SeverityField},
+			{"storyPointsFieldId", config.StoryPointsFieldId},
+			{"incidentField", config.IncidentField},
+			{"rankField", config.RankField},
+			{"parentLinkField", config.ParentLinkField},
+			{"costOfDelayField", config.CostOfDelayField},
+			{"badges.flaggedField", config.Badges.FlaggedField},
+		} {
+			if field.id == "" {
+				continue
+			}
+			if ids[field.id] {
+				v.check(field.setting + " " + field.id)
+			} else {
+				v.check(field.setting+" "+field.id, "no such field; see meta fields for custom field IDs")
+			}
+		}
+		if config.StoryPointsField != "" {
+			if names[strings.ToLower(config.StoryPointsField)] {
+				v.check("storyPointsField " + config.StoryPointsField)
+			} else {
+				v.check("storyPointsField "+config.StoryPointsField, "no field by that name; it takes the field's name, not its ID")
+			}
+		}
+	}
+
+	var statuses []statusDetail
+	if err := doJSON(cl, http.MethodGet, api+"status", nil, &statuses); err != nil {
+		v.check("statuses", err.Error())
+	} else {
+		known := make(map[string]bool)
+		for _, s := range statuses {
+			known[strings.ToLower(s.Name)] = true
+		}
+		// Settings refer to bucketed statuses by their bucket:
+		for bucket := range config.StatusBuckets {
+			known[strings.ToLower(bucket)] = true
+		}
+		byStatus := configuredStatuses()
+		var configured []string
+		for setting := range byStatus {
+			configured = append(configured, setting)
+		}
+		sort.Strings(configured)
+		for _, setting := range configured {
+			var problems []string
+			for _, name := range byStatus[setting] {
+				if !known[strings.ToLower(name)] {
+					problems = append(problems, fmt.Sprintf("no status %q; see meta statuses for this instance's names", name))
+				}
+			}
+			v.check("statuses in "+setting, problems...)
+		}
+	}
+
+	if config.HolidayCalendar != "" {
+		if _, err := calendarHolidays(config.HolidayCalendar); err != nil {
+			v.check("holidayCalendar "+config.HolidayCalendar, err.Error())
+		} else {
+			v.check("holidayCalendar " + config.HolidayCalendar)
+		}
+	}
+
+	for i, p := range config.SLAPolicies {
+		if p.Days <= 0 {
+			v.check(fmt.Sprintf("slaPolicies[%d]", i), "days must be at least 1")
+		}
+	}
+
+	if v.problems > 0 {
+		return fmt.Errorf("%s found", plural(v.problems, "problem"))
+	}
+	return nil
+}
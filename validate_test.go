@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunConfigValidate(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/2/myself":
+			w.Write([]byte(`{"name": "ann", "displayName": "Ann"}`))
+		case "/rest/agile/1.0/board/7":
+			w.Write([]byte(`{"id": 7, "name": "Team board"}`))
+		case "/rest/api/2/jql/parse":
+			var req struct {
+				Queries []string `json:"queries"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			var rsp struct {
+				Queries []map[string]interface{} `json:"queries"`
+			}
+			for _, q := range req.Queries {
+				errors := []string{}
+				if strings.Contains(q, "Nope") {
+					errors = append(errors, "The value 'Nope' does not exist for the field 'status'.")
+				}
+				rsp.Queries = append(rsp.Queries, map[string]interface{}{"query": q, "errors": errors})
+			}
+			json.NewEncoder(w).Encode(&rsp)
+		case "/rest/api/2/field":
+			w.Write([]byte(`[{"id": "customfield_10500", "name": "Severity"}, {"id": "customfield_10016", "name": "Story Points"}]`))
+		case "/rest/api/2/status":
+			w.Write([]byte(`[{"name": "In Progress"}, {"name": "Done"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+	os.Setenv("JIRA_JQL", "project = X")
+	defer os.Unsetenv("JIRA_JQL")
+
+	config = &Config{
+		ReportJQL:        map[string]string{"aging": "status = Nope"},
+		SeverityField:    "customfield_10500",
+		IncidentField:    "customfield_99999",
+		StoryPointsField: "story points",
+		StartStatuses:    []string{"in progress", "Doing"},
+	}
+	defer func() { config = &Config{} }()
+
+	out, err := captureOutput(func() error { return runConfigCommand([]string{"validate", "7"}) })
+	if err == nil || err.Error() != "3 problems found" {
+		t.Fatalf("expected 3 problems, got %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"ok    credentials: logged in as Ann",
+		"ok    board 7: Team board",
+		"ok    JQL JIRA_JQL",
+		"FAIL  JQL reportJql.aging\n      The value 'Nope' does not exist",
+		"ok    severityField customfield_10500",
+		"FAIL  incidentField customfield_99999",
+		"ok    storyPointsField story points",
+		"FAIL  statuses in startStatuses\n      no status \"Doing\"",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
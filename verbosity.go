@@ -0,0 +1,95 @@
+package main
+
+import "fmt"
+
+// verbosity selects how much per-issue detail reports print: everything for
+// developers, the top few items for leads, aggregated metrics only for execs.
+type verbosity int
+
+const (
+	execView verbosity = iota
+	leadView
+	developerView
+)
+
+var verbosityNames = map[string]verbosity{
+	"exec":      execView,
+	"lead":      leadView,
+	"developer": developerView,
+}
+
+// leadDetailLines is how many items per list the lead view shows:
+const leadDetailLines = 5
+
+var verbosityFlag string
+
+var reportVerbosity = developerView
+
+// limitFlag and detailLimit cap the per-issue lines of each list at any
+// verbosity, so large boards' reports stay short enough to read and post; 0
+// is no limit.
+var (
+	limitFlag   int
+	detailLimit int
+)
+
+// resolveVerbosity picks the -verbosity flag, else the profile's, else the
+// config file's, defaulting to developer:
+func resolveVerbosity() (verbosity, error) {
+	name := verbosityFlag
+	if name == "" && activeProfile != nil {
+		name = activeProfile.Verbosity
+	}
+	if name == "" {
+		name = config.Verbosity
+	}
+	if name == "" {
+		return developerView, nil
+	}
+
+	v, ok := verbosityNames[name]
+	if !ok {
+		return developerView, fmt.Errorf("unknown verbosity '%s'; expected developer, lead or exec", name)
+	}
+	return v, nil
+}
+
+// resolveLimit picks the -limit flag, else the profile's, else the config
+// file's:
+func resolveLimit() (int, error) {
+	limit := limitFlag
+	if limit == 0 && activeProfile != nil {
+		limit = activeProfile.Limit
+	}
+	if limit == 0 {
+		limit = config.Limit
+	}
+	if limit < 0 {
+		return 0, fmt.Errorf("limit must be at least 1, or 0 for no limit")
+	}
+	return limit, nil
+}
+
+// detailLines returns how many of n per-issue lines to print:
+func detailLines(n int) int {
+	switch reportVerbosity {
+	case execView:
+		return 0
+	case leadView:
+		if n > leadDetailLines {
+			n = leadDetailLines
+		}
+	}
+	if detailLimit > 0 && n > detailLimit {
+		return detailLimit
+	}
+	return n
+}
+
+// printOmitted notes per-issue lines left out by the lead view or -limit; the
+// exec view omits them silently.
+func printOmitted(indent string, shown int, total int) {
+	if reportVerbosity != execView && shown < total {
+		fmt.Printf("%s... and %d more\n", indent, total-shown)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import "testing"
+
+func TestResolveVerbosity_FlagOverProfileOverConfig(t *testing.T) {
+	defer func() {
+		config = &Config{}
+		activeProfile = nil
+		verbosityFlag = ""
+	}()
+
+	config = &Config{Verbosity: "exec"}
+	if v, _ := resolveVerbosity(); v != execView {
+		t.Fatalf("expected config verbosity, got %v", v)
+	}
+	activeProfile = &Profile{Verbosity: "lead"}
+	if v, _ := resolveVerbosity(); v != leadView {
+		t.Fatalf("expected profile verbosity, got %v", v)
+	}
+	verbosityFlag = "developer"
+	if v, _ := resolveVerbosity(); v != developerView {
+		t.Fatalf("expected -verbosity flag, got %v", v)
+	}
+	verbosityFlag = "ceo"
+	if _, err := resolveVerbosity(); err == nil {
+		t.Fatalf("expected unknown verbosity to fail")
+	}
+}
+
+func TestDetailLines(t *testing.T) {
+	defer func() { reportVerbosity = developerView }()
+
+	for _, c := range []struct {
+		v        verbosity
+		n        int
+		expected int
+	}{
+		{developerView, 40, 40},
+		{leadView, 40, leadDetailLines},
+		{leadView, 2, 2},
+		{execView, 40, 0},
+	} {
+		reportVerbosity = c.v
+		if shown := detailLines(c.n); shown != c.expected {
+			t.Fatalf("expected %d of %d lines at verbosity %v, got %d", c.expected, c.n, c.v, shown)
+		}
+	}
+}
+
+func TestDetailLines_Limit(t *testing.T) {
+	defer func() {
+		reportVerbosity = developerView
+		detailLimit = 0
+	}()
+
+	detailLimit = 10
+	for _, c := range []struct {
+		v        verbosity
+		n        int
+		expected int
+	}{
+		{developerView, 40, 10},
+		{developerView, 4, 4},
+		{leadView, 40, leadDetailLines},
+		{execView, 40, 0},
+	} {
+		reportVerbosity = c.v
+		if shown := detailLines(c.n); shown != c.expected {
+			t.Fatalf("expected %d of %d lines at verbosity %v with limit 10, got %d", c.expected, c.n, c.v, shown)
+		}
+	}
+
+	reportVerbosity = developerView
+	out, _ := captureOutput(func() error {
+		printOmitted("  ", 10, 40)
+		return nil
+	})
+	if out != "  ... and 30 more\n" {
+		t.Fatalf("expected the omitted items noted, got %q", out)
+	}
+}
+
+func TestResolveLimit_FlagOverProfileOverConfig(t *testing.T) {
+	defer func() {
+		config = &Config{}
+		activeProfile = nil
+		limitFlag = 0
+	}()
+
+	config = &Config{Limit: 20}
+	if limit, _ := resolveLimit(); limit != 20 {
+		t.Fatalf("expected config limit, got %d", limit)
+	}
+	activeProfile = &Profile{Limit: 10}
+	if limit, _ := resolveLimit(); limit != 10 {
+		t.Fatalf("expected profile limit, got %d", limit)
+	}
+	limitFlag = 5
+	if limit, _ := resolveLimit(); limit != 5 {
+		t.Fatalf("expected -limit flag, got %d", limit)
+	}
+	limitFlag = -1
+	if _, err := resolveLimit(); err == nil {
+		t.Fatalf("expected a negative limit to fail")
+	}
+}
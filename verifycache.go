@@ -0,0 +1,221 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// clockSkew is how far in the future a timestamp may be before it's suspect:
+const clockSkew = 24 * time.Hour
+
+// verifyTimestamps checks an issue's timestamps parsed and are in order; JIRA
+// timestamps that don't parse are left unset rather than failing a report.
+func verifyTimestamps(issue *Issue, now time.Time) []string {
+	var problems []string
+	created := issue.Fields.Created.Time
+	future := now.Add(clockSkew)
+	switch {
+	case created.IsZero():
+		problems = append(problems, issue.Key+": created time missing or unrecognized")
+	case created.After(future):
+		problems = append(problems, fmt.Sprintf("%s: created in the future, %s", issue.Key, created.Format(time.RFC3339)))
+	}
+	if resolved := issue.Fields.ResolutionDate.Time; !resolved.IsZero() {
+		if resolved.After(future) {
+			problems = append(problems, fmt.Sprintf("%s: resolved in the future, %s", issue.Key, resolved.Format(time.RFC3339)))
+		} else if !created.IsZero() && resolved.Before(created) {
+			problems = append(problems, fmt.Sprintf("%s: resolved before it was created", issue.Key))
+		}
+	}
+	return append(problems, verifyHistories(issue.Key, issue.Changelog.Histories, now)...)
+}
+
+func verifyHistories(key string, histories []History, now time.Time) []string {
+	missing, future := 0, 0
+	for _, h := range histories {
+		switch {
+		case h.Created.IsZero():
+			missing++
+		case h.Created.After(now.Add(clockSkew)):
+			future++
+		}
+	}
+	var problems []string
+	if missing > 0 {
+		problems = append(problems, fmt.Sprintf("%s: %s without a recognized time", key, plural(missing, "change")))
+	}
+	if future > 0 {
+		problems = append(problems, fmt.Sprintf("%s: %s in the future", key, plural(future, "change")))
+	}
+	return problems
+}
+
+// cachedChangelogLength follows the cached pages of an issue's changelog past
+// the changes its search result embeds, returning how many are cached.
+func cachedChangelogLength(issue *Issue) int {
+	n := len(issue.Changelog.Histories)
+	for n < issue.Changelog.Total {
+		b, err := readCacheFile(fmt.Sprintf("issue.%s.changelog.%d.json", issue.Key, n))
+		if err != nil {
+			break
+		}
+		page := &changelogPage{}
+		if json.Unmarshal(b, page) != nil || len(page.Values) == 0 {
+			break
+		}
+		n += len(page.Values)
+	}
+	return n
+}
+
+// verifyCacheFile checks a cached response decodes as what it holds, issue
+// pages having sane timestamps and complete changelogs.
+func verifyCacheFile(filename string, entry *cacheEntry, now time.Time) []string {
+	b, err := readCacheFile(filename)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	if !json.Valid(b) {
+		return []string{"not valid JSON"}
+	}
+
+	var v interface{}
+	switch entry.Kind {
+	case "report":
+		v = &memoizedReport{}
+	case "changelog":
+		page := &changelogPage{}
+		if err := json.Unmarshal(b, page); err != nil {
+			return []string{err.Error()}
+		}
+		return verifyHistories(filename, page.Values, now)
+	case "issue":
+		if strings.HasSuffix(filename, ".watchers.json") {
+			v = &watcherList{}
+			break
+		}
+		issue := &Issue{}
+		if err := json.Unmarshal(b, issue); err != nil {
+			return []string{err.Error()}
+		}
+		return verifyTimestamps(issue, now)
+	case "board", "filter":
+		var shape struct {
+			Issues json.RawMessage `json:"issues"`
+		}
+		if json.Unmarshal(b, &shape) != nil || shape.Issues == nil {
+			// Counts, sprints and epics:
+			return nil
+		}
+		page := &PagedIssues{}
+		if err := json.Unmarshal(b, page); err != nil {
+			return []string{err.Error()}
+		}
+		var problems []string
+		for i := range page.Issues {
+			issue := &page.Issues[i]
+			problems = append(problems, verifyTimestamps(issue, now)...)
+			if n := cachedChangelogLength(issue); n < issue.Changelog.Total {
+				problems = append(problems, fmt.Sprintf("%s: changelog incomplete, %d of %d changes cached", issue.Key, n, issue.Changelog.Total))
+			}
+		}
+		return problems
+	default:
+		return nil
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return []string{err.Error()}
+	}
+	return nil
+}
+
+// verifyStores checks the files kept across runs decode, each as its command
+// reads it:
+func verifyStores() map[string]error {
+	failed := make(map[string]error)
+	if _, err := loadCacheIndex(); err != nil {
+		failed[cacheIndexFilename] = err
+	}
+	if _, err := loadNudges(); err != nil {
+		failed[nudgesFilename] = err
+	}
+	if _, err := loadHistory(defaultHistoryStore, 0); err != nil {
+		failed[defaultHistoryStore] = err
+	}
+	if _, err := loadForecasts(defaultForecastLog); err != nil && !os.IsNotExist(err) {
+		failed[defaultForecastLog] = err
+	}
+	if _, err := loadAudit(auditLogFilename()); err != nil {
+		failed[auditLogFilename()] = err
+	}
+	if _, err := loadNotes(notesFilename()); err != nil {
+		failed[notesFilename()] = err
+	}
+	if _, err := loadAcks(acksFilename()); err != nil {
+		failed[acksFilename()] = err
+	}
+	return failed
+}
+
+func runVerifyCache(args []string) error {
+	fs := flag.NewFlagSet("verify-cache", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	files, err := cachedFiles()
+	if err != nil {
+		// The index is reported with the stores; the files are checked anyway:
+		files = make(map[string]*cacheEntry)
+		for _, pattern := range cacheFilePatterns {
+			matches, _ := filepath.Glob(pattern)
+			for _, filename := range matches {
+				files[filename] = parseCacheFilename(filename)
+			}
+		}
+	}
+	names := make([]string, 0, len(files))
+	for filename := range files {
+		names = append(names, filename)
+	}
+	sort.Strings(names)
+
+	now := time.Now()
+	bad := 0
+	for i, filename := range names {
+		progress.Update("verifying cache", i+1, len(names), "files", "")
+		problems := verifyCacheFile(filename, files[filename], now)
+		if len(problems) == 0 {
+			continue
+		}
+		progress.Clear()
+		bad++
+		fmt.Printf("%s:\n", filename)
+		for _, p := range problems {
+			fmt.Printf("  %s\n", p)
+		}
+	}
+	progress.Clear()
+
+	failed := verifyStores()
+	stores := make([]string, 0, len(failed))
+	for filename := range failed {
+		stores = append(stores, filename)
+	}
+	sort.Strings(stores)
+	for _, filename := range stores {
+		fmt.Printf("%s:\n  %v\n", filename, failed[filename])
+	}
+
+	fmt.Printf("Verified %s: %d with problems; %s unreadable.\n", plural(len(names), "cached file"), bad, plural(len(stores), "store"))
+	if bad+len(stores) > 0 {
+		return fmt.Errorf("verify-cache: %s with problems", plural(bad+len(stores), "file"))
+	}
+	return nil
+}
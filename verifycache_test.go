@@ -0,0 +1,62 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestVerifyCache(t *testing.T) {
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	page := `{"startAt": 0, "maxResults": 50, "total": 3, "issues": [
+		{"key": "A-1", "fields": {"created": "2024-01-02T10:00:00.000+0000", "resolutiondate": "2024-01-05T10:00:00.000+0000"},
+		 "changelog": {"total": 1, "histories": [{"created": "2024-01-03T10:00:00.000+0000"}]}},
+		{"key": "A-2", "fields": {"created": "yesterday", "resolutiondate": "2023-12-01T10:00:00.000+0000"}},
+		{"key": "A-3", "fields": {"created": "2024-01-02T10:00:00.000+0000"},
+		 "changelog": {"total": 3, "histories": [{"created": "2024-01-03T10:00:00.000+0000"}]}}]}`
+	files := map[string]string{
+		"board.1.0000abcd.issue.0.json": page,
+		"issue.A-3.changelog.1.json":    `{"startAt": 1, "total": 3, "values": [{"created": "2024-01-04T10:00:00.000+0000"}]}`,
+		"report.0011223344556677.json":  `{"output": 42}`,
+		"board.1.sprints.active.0.json": `{"values": []}`,
+	}
+	for filename, body := range files {
+		if err := writeCacheFile(filename, []byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var err error
+	out := string(captureStdout(t, func() error {
+		err = runVerifyCache(nil)
+		return nil
+	}))
+	if err == nil {
+		t.Fatalf("expected problems to fail the check")
+	}
+	for _, want := range []string{
+		"A-2: created time missing or unrecognized",
+		"A-3: changelog incomplete, 2 of 3 changes cached",
+		"report.0011223344556677.json:\n  json: cannot unmarshal",
+		"Verified 4 cached files: 2 with problems",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "A-1") || strings.Contains(out, "sprints") {
+		t.Errorf("expected sound entries not reported:\n%s", out)
+	}
+
+	// Without a created time there is no order to check:
+	if strings.Contains(out, "resolved before") {
+		t.Errorf("expected no ordering problem without a created time:\n%s", out)
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// version is set by release builds with -ldflags "-X main.version=v1.2.3";
+// otherwise the module version recorded by go install is used.
+var version string
+
+// releaseKey is the base64 Ed25519 public key release checksums are signed
+// with, set by release builds with -ldflags "-X main.releaseKey=<key>". Builds
+// without it can't verify releases, so don't update themselves.
+var releaseKey string
+
+// releasesURL is the GitHub API resource describing the latest release:
+var releasesURL = "https://api.github.com/repos/JamesDunne/jira-analysis/releases/latest"
+
+func currentVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "devel"
+}
+
+func runVersion(args []string) error {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	fmt.Printf("%s %s\n", programName(), currentVersion())
+	fmt.Printf("  built with %s for %s/%s\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Sum != "" {
+		fmt.Printf("  module %s %s\n", info.Main.Path, info.Main.Sum)
+	}
+	return nil
+}
+
+type releaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+type githubRelease struct {
+	TagName string         `json:"tag_name"`
+	Assets  []releaseAsset `json:"assets"`
+}
+
+func (r *githubRelease) asset(name string) *releaseAsset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// semver is a version's numeric core and pre-release identifiers:
+type semver struct {
+	core       [3]int
+	prerelease []string
+}
+
+// parseVersion reads a semantic version such as v1.2.3 or v1.3.0-rc.1,
+// ignoring build metadata:
+func parseVersion(s string) (semver, bool) {
+	var v semver
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		v.prerelease = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v.core[i] = n
+	}
+	return v, true
+}
+
+// compare orders versions by semantic versioning's precedence: -1 if v is
+// older than w, 0 if they're the same release and 1 if v is newer.
+func (v semver) compare(w semver) int {
+	for i := range v.core {
+		if v.core[i] != w.core[i] {
+			return sign(v.core[i] - w.core[i])
+		}
+	}
+	// A pre-release precedes its release:
+	switch {
+	case len(v.prerelease) == 0 && len(w.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(w.prerelease) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.prerelease) && i < len(w.prerelease); i++ {
+		a, b := v.prerelease[i], w.prerelease[i]
+		if a == b {
+			continue
+		}
+		// Numeric identifiers compare as numbers, and before others:
+		n, aErr := strconv.Atoi(a)
+		m, bErr := strconv.Atoi(b)
+		switch {
+		case aErr == nil && bErr == nil:
+			return sign(n - m)
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		case a < b:
+			return -1
+		default:
+			return 1
+		}
+	}
+	return sign(len(v.prerelease) - len(w.prerelease))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+// verifyChecksums checks the release's signature over checksums.txt with the
+// built-in release key; the signature is raw or base64.
+func verifyChecksums(checksums []byte, signature []byte) error {
+	if releaseKey == "" {
+		return fmt.Errorf("this build has no release key to verify releases with; download the release by hand")
+	}
+	key, err := base64.StdEncoding.DecodeString(releaseKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("this build's release key is not a base64 Ed25519 public key")
+	}
+	if len(signature) != ed25519.SignatureSize {
+		if signature, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err != nil {
+			return fmt.Errorf("checksums.txt.sig is neither a raw nor a base64 signature")
+		}
+	}
+	if !ed25519.Verify(ed25519.PublicKey(key), checksums, signature) {
+		return fmt.Errorf("checksums.txt signature doesn't verify against this build's release key")
+	}
+	return nil
+}
+
+// releaseAssetName is the name of this platform's binary in a release:
+func releaseAssetName() string {
+	name := fmt.Sprintf("jira-analysis_%s_%s", runtime.GOOS, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// download fetches a release URL in full; releases are never cached.
+func download(cl *http.Client, url string) ([]byte, error) {
+	rsp, err := cl.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode != http.StatusOK {
+		return nil, newAPIError(rsp.Request, rsp)
+	}
+	return ioutil.ReadAll(rsp.Body)
+}
+
+// releaseChecksum finds a file's SHA-256 in a release's checksums.txt, lines
+// of "<hex>  <name>":
+func releaseChecksum(checksums []byte, name string) (string, bool) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), true
+		}
+	}
+	return "", false
+}
+
+// selfUpdate replaces the binary at exe with the latest release's when newer,
+// verified against the release checksums and their signature, or only reports
+// what's available if check.
+func selfUpdate(cl *http.Client, exe string, check bool) error {
+	b, err := download(cl, releasesURL)
+	if err != nil {
+		return err
+	}
+	release := &githubRelease{}
+	if err = json.Unmarshal(b, release); err != nil {
+		return &DecodeError{URL: releasesURL, Err: err}
+	}
+
+	current := currentVersion()
+	latest, ok := parseVersion(release.TagName)
+	if !ok {
+		return fmt.Errorf("latest release %q is not a semantic version", release.TagName)
+	}
+	running, ok := parseVersion(current)
+	if !ok {
+		// A development build may be ahead of any release:
+		if check {
+			fmt.Printf("%s is the latest release (running %s)\n", release.TagName, current)
+			return nil
+		}
+		return fmt.Errorf("running a %s build, which may be newer than release %s; install the release by hand", current, release.TagName)
+	}
+	if latest.compare(running) <= 0 {
+		fmt.Printf("%s is the latest release\n", current)
+		return nil
+	}
+	if check {
+		fmt.Printf("%s is available (running %s); run self-update to install it\n", release.TagName, current)
+		return nil
+	}
+
+	name := releaseAssetName()
+	binary := release.asset(name)
+	if binary == nil {
+		return fmt.Errorf("release %s has no binary for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+	checksums := release.asset("checksums.txt")
+	if checksums == nil {
+		return fmt.Errorf("release %s has no checksums.txt to verify %s against", release.TagName, name)
+	}
+	signature := release.asset("checksums.txt.sig")
+	if signature == nil {
+		return fmt.Errorf("release %s has no checksums.txt.sig to verify its checksums with", release.TagName)
+	}
+
+	sums, err := download(cl, checksums.URL)
+	if err != nil {
+		return err
+	}
+	sig, err := download(cl, signature.URL)
+	if err != nil {
+		return err
+	}
+	// The checksums prove the binary intact; their signature that it's ours:
+	if err = verifyChecksums(sums, sig); err != nil {
+		return fmt.Errorf("release %s: %v", release.TagName, err)
+	}
+	want, ok := releaseChecksum(sums, name)
+	if !ok {
+		return fmt.Errorf("release %s checksums.txt doesn't list %s", release.TagName, name)
+	}
+	b, err = download(cl, binary.URL)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(b)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return fmt.Errorf("%s checksum %s doesn't match the release's %s", name, got, want)
+	}
+
+	// Windows can't replace a running binary but can rename it aside:
+	if runtime.GOOS == "windows" {
+		os.Remove(exe + ".old")
+		if err = os.Rename(exe, exe+".old"); err != nil {
+			return err
+		}
+	}
+	if err = writeFileAtomic(exe, b, 0755); err != nil {
+		return err
+	}
+	fmt.Printf("updated %s from %s to %s\n", exe, current, release.TagName)
+	return nil
+}
+
+func runSelfUpdate(args []string) error {
+	fs := flag.NewFlagSet("self-update", flag.ContinueOnError)
+	check := fs.Bool("check", false, "only report whether a newer release is available")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		return err
+	}
+	// Unlike API calls, downloads of the binary always verify TLS:
+	return selfUpdate(&http.Client{Timeout: 5 * time.Minute}, exe, *check)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSelfUpdate(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary := []byte("new binary")
+	sum := sha256.Sum256(binary)
+	checksums := hex.EncodeToString(sum[:]) + "  " + releaseAssetName() + "\n"
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte(checksums)))
+	tag := "v2.0.0"
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			fmt.Fprintf(w, `{"tag_name": %q, "assets": [
+				{"name": %q, "browser_download_url": "%s/bin"},
+				{"name": "checksums.txt", "browser_download_url": "%s/checksums.txt"},
+				{"name": "checksums.txt.sig", "browser_download_url": "%s/checksums.txt.sig"}
+			]}`, tag, releaseAssetName(), srv.URL, srv.URL, srv.URL)
+		case "/bin":
+			w.Write(binary)
+		case "/checksums.txt":
+			w.Write([]byte(checksums))
+		case "/checksums.txt.sig":
+			w.Write([]byte(signature))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	savedURL, savedVersion, savedKey := releasesURL, version, releaseKey
+	releasesURL, version, releaseKey = srv.URL+"/latest", "v1.0.0", base64.StdEncoding.EncodeToString(public)
+	defer func() { releasesURL, version, releaseKey = savedURL, savedVersion, savedKey }()
+
+	exe := filepath.Join(t.TempDir(), "jira-analysis")
+	ioutil.WriteFile(exe, []byte("old binary"), 0755)
+
+	out, err := captureOutput(func() error { return selfUpdate(srv.Client(), exe, true) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "v2.0.0 is available") {
+		t.Fatalf("expected -check to report the new release, got %q", out)
+	}
+	if b, _ := ioutil.ReadFile(exe); string(b) != "old binary" {
+		t.Fatalf("expected -check to leave the binary alone")
+	}
+
+	if _, err = captureOutput(func() error { return selfUpdate(srv.Client(), exe, false) }); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := ioutil.ReadFile(exe); string(b) != "new binary" {
+		t.Fatalf("expected the binary replaced, got %q", b)
+	}
+
+	// A binary not matching its checksum is never installed:
+	binary = []byte("tampered")
+	ioutil.WriteFile(exe, []byte("old binary"), 0755)
+	if _, err = captureOutput(func() error { return selfUpdate(srv.Client(), exe, false) }); err == nil || !strings.Contains(err.Error(), "doesn't match") {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	if b, _ := ioutil.ReadFile(exe); string(b) != "old binary" {
+		t.Fatalf("expected the binary left alone, got %q", b)
+	}
+
+	// Nor is one whose checksums someone else signed:
+	binary = []byte("new binary")
+	_, other, _ := ed25519.GenerateKey(nil)
+	signature = base64.StdEncoding.EncodeToString(ed25519.Sign(other, []byte(checksums)))
+	if _, err = captureOutput(func() error { return selfUpdate(srv.Client(), exe, false) }); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Fatalf("expected a signature mismatch, got %v", err)
+	}
+	signature = base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte(checksums)))
+
+	// Builds without a release key can't verify one:
+	releaseKey = ""
+	if _, err = captureOutput(func() error { return selfUpdate(srv.Client(), exe, false) }); err == nil || !strings.Contains(err.Error(), "no release key") {
+		t.Fatalf("expected the update refused without a release key, got %v", err)
+	}
+	releaseKey = base64.StdEncoding.EncodeToString(public)
+	if b, _ := ioutil.ReadFile(exe); string(b) != "old binary" {
+		t.Fatalf("expected the binary left alone, got %q", b)
+	}
+
+	// An older latest release is never installed over a newer build:
+	version, tag = "v2.1.0", "v2.0.0"
+	out, err = captureOutput(func() error { return selfUpdate(srv.Client(), exe, false) })
+	if err != nil || !strings.Contains(out, "v2.1.0 is the latest release") {
+		t.Fatalf("expected no downgrade from v2.1.0 to v2.0.0, got %q %v", out, err)
+	}
+	if b, _ := ioutil.ReadFile(exe); string(b) != "old binary" {
+		t.Fatalf("expected the binary left alone, got %q", b)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	ordered := []string{"v0.9.0", "v1.0.0-alpha", "v1.0.0-alpha.1", "v1.0.0-alpha.beta", "v1.0.0-beta.2", "v1.0.0-beta.11", "v1.0.0-rc.1", "v1.0.0", "v1.2.0", "v1.10.0", "v2.0.0"}
+	for i := range ordered {
+		for j := range ordered {
+			v, _ := parseVersion(ordered[i])
+			w, _ := parseVersion(ordered[j])
+			if got, want := v.compare(w), sign(i-j); got != want {
+				t.Errorf("compare(%s, %s) = %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+	if v, _ := parseVersion("v1.0.0+build.5"); v.compare(semver{core: [3]int{1, 0, 0}}) != 0 {
+		t.Errorf("expected build metadata ignored")
+	}
+	for _, s := range []string{"devel", "v1.2", "v1.x.0", ""} {
+		if _, ok := parseVersion(s); ok {
+			t.Errorf("expected %q not a semantic version", s)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	workState         = "work"
+	queueState        = "queue"
+	unclassifiedState = "unclassified"
+)
+
+// stateClass classifies a status as "work" or "queue" per config:
+func stateClass(status string) string {
+	switch config.StatusClasses[status] {
+	case workState:
+		return workState
+	case queueState:
+		return queueState
+	default:
+		return unclassifiedState
+	}
+}
+
+type issueWait struct {
+	Issue    *Issue
+	Lead     time.Duration
+	ByClass  map[string]time.Duration
+	ByStatus map[string]time.Duration
+}
+
+func (w *issueWait) WaitShare() float64 {
+	if w.Lead == 0 {
+		return 0
+	}
+	return float64(w.ByClass[queueState]) / float64(w.Lead)
+}
+
+// measureWait splits a completed issue's lead time, from start of work to
+// completion, into time per status and per work/queue class.
+func measureWait(issue *Issue) (*issueWait, bool) {
+	started, ok := issue.StartedTime()
+	if !ok {
+		return nil, false
+	}
+	completed, ok := issue.CompletedTime()
+	if !ok {
+		return nil, false
+	}
+
+	w := &issueWait{
+		Issue:    issue,
+		Lead:     completed.Sub(started),
+		ByClass:  make(map[string]time.Duration),
+		ByStatus: make(map[string]time.Duration),
+	}
+	for _, in := range issue.statusIntervals() {
+		// Clip intervals to the lead time window:
+		start, end := in.Start, in.End
+		if end.IsZero() || end.After(completed) {
+			end = completed
+		}
+		if start.Before(started) {
+			start = started
+		}
+		if !end.After(start) {
+			continue
+		}
+
+		d := end.Sub(start)
+		w.ByStatus[in.Status] += d
+		w.ByClass[stateClass(in.Status)] += d
+	}
+	return w, true
+}
+
+func days(d time.Duration) float64 {
+	return d.Hours() / 24
+}
+
+func runWaitTime(args []string) error {
+	fs := flag.NewFlagSet("wait-time", flag.ContinueOnError)
+	jql := fs.String("jql", "", "JQL filter selecting completed issues; default='statusCategory = Done AND resolved >= -90d'")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(config.StatusClasses) == 0 {
+		fmt.Printf("note: statusClasses is not configured; all time is unclassified\n")
+	}
+
+	issues, err := fetchBoardIssues(httpClient(), boardArg(fs.Args()), reportJQL("wait-time", *jql, "statusCategory = Done AND resolved >= -90d"))
+	if err != nil {
+		return err
+	}
+
+	var waits []*issueWait
+	var totalLead time.Duration
+	totalByClass := make(map[string]time.Duration)
+	totalByStatus := make(map[string]time.Duration)
+	for i := range issues {
+		if issues[i].IsEpic() {
+			continue
+		}
+		w, ok := measureWait(&issues[i])
+		if !ok {
+			continue
+		}
+		waits = append(waits, w)
+		totalLead += w.Lead
+		for class, d := range w.ByClass {
+			totalByClass[class] += d
+		}
+		for status, d := range w.ByStatus {
+			totalByStatus[status] += d
+		}
+	}
+
+	if totalLead == 0 {
+		fmt.Printf("no completed issues with a start of work\n")
+		return nil
+	}
+
+	statuses := make([]string, 0, len(totalByStatus))
+	for status := range totalByStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if totalByStatus[statuses[i]] != totalByStatus[statuses[j]] {
+			return totalByStatus[statuses[i]] > totalByStatus[statuses[j]]
+		}
+		return statuses[i] < statuses[j]
+	})
+
+	fmt.Printf("Share of lead time (start of work to done) across %d issues:\n", len(waits))
+	for _, class := range []string{workState, queueState, unclassifiedState} {
+		fmt.Printf("  %-12s %8.1f days %5.1f%%\n", class, days(totalByClass[class]), 100*float64(totalByClass[class])/float64(totalLead))
+	}
+
+	fmt.Printf("\nBy stage:\n")
+	for _, status := range statuses {
+		fmt.Printf(
+			"  %s %-12s %8.1f days %5.1f%%\n",
+			padRight(status, nameWidth(20)),
+			stateClass(status),
+			days(totalByStatus[status]),
+			100*float64(totalByStatus[status])/float64(totalLead),
+		)
+	}
+
+	sort.Slice(waits, func(i, j int) bool {
+		if waits[i].ByClass[queueState] != waits[j].ByClass[queueState] {
+			return waits[i].ByClass[queueState] > waits[j].ByClass[queueState]
+		}
+		return lessIssueKey(waits[i].Issue.Key, waits[j].Issue.Key)
+	})
+	shown := detailLines(len(waits))
+	if shown > 0 {
+		fmt.Printf("\nBy issue, most queue time first:\n")
+	}
+	for _, w := range waits[:shown] {
+		fmt.Printf(
+			"  %s: lead %5.1f days, queued %5.1f days (%3.0f%%); %s\n",
+			w.Issue.Key,
+			days(w.Lead),
+			days(w.ByClass[queueState]),
+			100*w.WaitShare(),
+			w.Issue.DisplaySummary(),
+		)
+	}
+	printOmitted("  ", shown, len(waits))
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMeasureWait(t *testing.T) {
+	config = &Config{StatusClasses: map[string]string{
+		inProgressStatus: workState,
+		"Ready for QA":   queueState,
+		"In Testing":     workState,
+	}}
+	defer func() { config = &Config{} }()
+
+	at := func(day, hour int) zonedTimestamp {
+		return zonedTimestamp{time.Date(2018, 11, day, hour, 0, 0, 0, time.UTC)}
+	}
+	status := func(from, to string) []HistoryItem {
+		return []HistoryItem{{Field: "status", FromString: from, ToString: to}}
+	}
+
+	issue := &Issue{Key: "A-1"}
+	issue.Fields.Created = at(1, 9)
+	issue.Fields.Status.StatusCategory.Key = "done"
+	issue.Fields.ResolutionDate = at(8, 9)
+	issue.Changelog.Histories = []History{
+		{Created: at(2, 9), Items: status("Open", inProgressStatus)},
+		{Created: at(4, 9), Items: status(inProgressStatus, "Ready for QA")},
+		{Created: at(7, 9), Items: status("Ready for QA", "In Testing")},
+		{Created: at(8, 9), Items: status("In Testing", "Closed")},
+	}
+
+	w, ok := measureWait(issue)
+	if !ok {
+		t.Fatalf("expected completed issue to be measured")
+	}
+	if w.Lead != 6*24*time.Hour {
+		t.Fatalf("expected 6 days lead time, got %v", w.Lead)
+	}
+	if w.ByClass[queueState] != 3*24*time.Hour || w.ByClass[workState] != 3*24*time.Hour {
+		t.Fatalf("expected 3 days each of work and queue, got %v", w.ByClass)
+	}
+	if w.WaitShare() != 0.5 {
+		t.Fatalf("expected 0.5 for wait share, got %v", w.WaitShare())
+	}
+}
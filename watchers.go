@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// watcherList is an issue's watchers resource; listing them takes the "View
+// Voters and Watchers" permission, without which only the count is given.
+type watcherList struct {
+	WatchCount int    `json:"watchCount"`
+	Watchers   []User `json:"watchers"`
+}
+
+func fetchWatchers(cl *http.Client, key string) ([]User, error) {
+	cacheFilename := fmt.Sprintf("issue.%s.watchers.json", key)
+	url := setting(cl, "JIRA_URL") + "/rest/api/2/issue/" + key + "/watchers"
+	body, err := cachedGet(cacheFilename, url, cl)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	list := &watcherList{}
+	if err = decodeResponse(body, url, list); err != nil {
+		return nil, err
+	}
+	return list.Watchers, nil
+}
+
+// issueWatchers fetches the names of the people watching each issue, leaving
+// out bots and the person responsible, who is addressed already. Issues whose
+// watchers can't be seen have none.
+func issueWatchers(cl *http.Client, issues []*Issue) (map[string][]string, error) {
+	watchers := make(map[string][]string)
+	// Only JIRA has watchers:
+	if backend := backendName(); backend != "jira" && backend != "keys" {
+		return watchers, nil
+	}
+	for _, issue := range issues {
+		list, err := fetchWatchers(cl, issue.Key)
+		if isPermissionError(err) {
+			log.Printf("warning: %s watchers are not accessible\n", issue.Key)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		responsible := users.Name(issue.responsible())
+		var names []string
+		for _, u := range list {
+			if name := users.Name(u); name != "" && name != responsible && !isBot(u) {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			sort.Strings(names)
+			watchers[issue.Key] = names
+		}
+	}
+	return watchers, nil
+}
+
+// printWatchers lists the watchers of the issues that have any:
+func printWatchers(issues []*Issue, watchers map[string][]string) {
+	var lines []string
+	for _, issue := range issues {
+		if names := watchers[issue.Key]; len(names) > 0 {
+			lines = append(lines, fmt.Sprintf("  %-10s %s", issue.Key, strings.Join(names, ", ")))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Printf("Watching items over SLA:\n")
+	shown := detailLines(len(lines))
+	for _, line := range lines[:shown] {
+		fmt.Println(line)
+	}
+	printOmitted("  ", shown, len(lines))
+}
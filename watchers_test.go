@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestIssueWatchers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/2/issue/A-1/watchers":
+			json.NewEncoder(w).Encode(watcherList{WatchCount: 4, Watchers: []User{
+				{UserName: "carol"}, {UserName: "alice"}, {UserName: "bob"}, {UserName: "ci", AccountType: "app"},
+			}})
+		default:
+			http.Error(w, `{"errorMessages": ["Issue Does Not Exist"]}`, http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	for key, value := range map[string]string{"JIRA_URL": srv.URL, "JIRA_NOCACHE": "1"} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+	config = &Config{Slack: SlackConfig{Users: map[string]string{"carol": "U0CAROL"}, Mention: []string{"breach"}}}
+	users = newUserRegistry()
+	defer func() {
+		config = &Config{}
+		users = newUserRegistry()
+		fetched = fetchStats{}
+	}()
+
+	watched := &Issue{Key: "A-1"}
+	watched.Fields.Assignee = &User{UserName: "alice"}
+	hidden := &Issue{Key: "A-2"}
+	watchers, err := issueWatchers(http.DefaultClient, []*Issue{watched, hidden})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The assignee and bots are left out; hidden watchers are none:
+	if want := map[string][]string{"A-1": {"bob", "carol"}}; !reflect.DeepEqual(watchers, want) {
+		t.Fatalf("expected %v, got %v", want, watchers)
+	}
+
+	alerts := issueAlerts(watched, true, reportNow())
+	for i := range alerts {
+		alerts[i].Watchers = watchers[alerts[i].Key]
+	}
+	mentions := slackMentions(map[string]interface{}{"alerts": alerts})
+	if want := []string{"alice: A-1 is over its SLA (cc bob, <@U0CAROL>)"}; !reflect.DeepEqual(mentions, want) {
+		t.Fatalf("expected %q, got %q", want, mentions)
+	}
+}
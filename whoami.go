@@ -0,0 +1,176 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// readPermissions are what reports need of an account in each project;
+// writePermissions are only needed to post nudges, label issues and move them
+// through transitions, so a read-only service account can go without them.
+var (
+	readPermissions  = []string{"BROWSE_PROJECTS"}
+	writePermissions = []string{"EDIT_ISSUES", "ADD_COMMENTS", "TRANSITION_ISSUES"}
+)
+
+// permissionNames are how JIRA's admin screens name permission keys:
+var permissionNames = map[string]string{
+	"BROWSE_PROJECTS":   "Browse Projects",
+	"EDIT_ISSUES":       "Edit Issues",
+	"ADD_COMMENTS":      "Add Comments",
+	"TRANSITION_ISSUES": "Transition Issues",
+}
+
+// myPermissions asks which of the permissions the account has in a project:
+func myPermissions(cl *http.Client, project string, keys []string) (map[string]bool, error) {
+	query := url.Values{"projectKey": {project}, "permissions": {strings.Join(keys, ",")}}
+	var rsp struct {
+		Permissions map[string]struct {
+			HavePermission bool `json:"havePermission"`
+		} `json:"permissions"`
+	}
+	if err := doJSON(cl, http.MethodGet, setting(cl, "JIRA_URL")+"/rest/api/2/mypermissions?"+query.Encode(), nil, &rsp); err != nil {
+		return nil, err
+	}
+	have := make(map[string]bool)
+	for _, key := range keys {
+		have[key] = rsp.Permissions[key].HavePermission
+	}
+	return have, nil
+}
+
+// sampleIssues finds the projects a report's issues come from, with one issue
+// key to test changelog access on: the board's projects through the Agile API,
+// or the projects of a saved filter's first page of issues.
+func sampleIssues(cl *http.Client, boardId int, filterId int) (projects []string, key string, err error) {
+	seen := make(map[string]bool)
+	add := func(project string) {
+		if project != "" && !seen[project] {
+			seen[project] = true
+			projects = append(projects, project)
+		}
+	}
+
+	var page struct {
+		Issues []Issue `json:"issues"`
+	}
+	if filterId != 0 {
+		query := url.Values{"jql": {fmt.Sprintf("filter = %d", filterId)}, "fields": {"project"}, "maxResults": {"100"}}
+		if err = doJSON(cl, http.MethodGet, setting(cl, "JIRA_URL")+"/rest/api/2/search?"+query.Encode(), nil, &page); err != nil {
+			return nil, "", err
+		}
+	} else {
+		var boardProjects struct {
+			Values []Project `json:"values"`
+		}
+		if err = doJSON(cl, http.MethodGet, fmt.Sprintf("%s/rest/agile/1.0/board/%d/project", setting(cl, "JIRA_URL"), boardId), nil, &boardProjects); err != nil {
+			return nil, "", err
+		}
+		for _, p := range boardProjects.Values {
+			add(p.Key)
+		}
+		if err = doJSON(cl, http.MethodGet, fmt.Sprintf("%s/rest/agile/1.0/board/%d/issue?fields=project&maxResults=1", setting(cl, "JIRA_URL"), boardId), nil, &page); err != nil {
+			return nil, "", err
+		}
+	}
+	for _, issue := range page.Issues {
+		add(issue.Fields.Project.Key)
+		if key == "" {
+			key = issue.Key
+		}
+	}
+	sort.Strings(projects)
+	return projects, key, nil
+}
+
+// runWhoami checks, uncached, that the account reports run as can see what
+// they need, naming the permission missing where it can't: otherwise missing
+// access only shows as empty or partial reports.
+func runWhoami(args []string) error {
+	fs := flag.NewFlagSet("whoami", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	cl := httpClient()
+	v := &validation{}
+	var myself struct {
+		Name         string `json:"name"`
+		AccountId    string `json:"accountId"`
+		DisplayName  string `json:"displayName"`
+		EmailAddress string `json:"emailAddress"`
+	}
+	if err := doJSON(cl, http.MethodGet, setting(cl, "JIRA_URL")+"/rest/api/2/myself", nil, &myself); err != nil {
+		v.check("credentials", fmt.Sprintf("%v; check JIRA_URL, JIRA_USERNAME and JIRA_PASSWORD (an API token on Cloud)", err))
+		return fmt.Errorf("%s found", plural(v.problems, "problem"))
+	}
+	id := myself.Name
+	if id == "" {
+		id = myself.AccountId
+	}
+	v.check(fmt.Sprintf("credentials: logged in as %s (%s)", myself.DisplayName, id))
+
+	boardId := boardArg(fs.Args())
+	filterId := savedFilterId(cl)
+	if filterId == 0 {
+		var board Board
+		if err := doJSON(cl, http.MethodGet, fmt.Sprintf("%s/rest/agile/1.0/board/%d", setting(cl, "JIRA_URL"), boardId), nil, &board); err != nil {
+			v.check(fmt.Sprintf("Agile API: board %d", boardId), fmt.Sprintf("%v; the account needs to see the board's saved filter, and JIRA Software for the Agile API", err))
+			return fmt.Errorf("%s found", plural(v.problems, "problem"))
+		}
+		v.check(fmt.Sprintf("Agile API: board %d: %s", boardId, board.Name))
+	}
+
+	projects, key, err := sampleIssues(cl, boardId, filterId)
+	if err != nil {
+		v.check("issues", fmt.Sprintf("%v; %s", err, errorHint(err)))
+		return fmt.Errorf("%s found", plural(v.problems, "problem"))
+	}
+	if len(projects) == 0 {
+		v.check("issues", "no issues or projects visible; the account needs Browse Projects in the board's projects")
+	}
+
+	canWrite := false
+	for _, project := range projects {
+		have, err := myPermissions(cl, project, append(readPermissions, writePermissions...))
+		if err != nil {
+			v.check("project "+project, err.Error())
+			continue
+		}
+		var missing []string
+		for _, perm := range readPermissions {
+			if !have[perm] {
+				missing = append(missing, fmt.Sprintf("missing %s (%s); ask a JIRA admin to grant it to the account", permissionNames[perm], perm))
+			}
+		}
+		for _, perm := range writePermissions {
+			canWrite = canWrite || have[perm]
+		}
+		v.check("project "+project+": browse", missing...)
+	}
+
+	if key != "" {
+		var issue Issue
+		changelogURL := fmt.Sprintf("%s/rest/api/2/issue/%s?fields=summary&expand=changelog", setting(cl, "JIRA_URL"), key)
+		if err := doJSON(cl, http.MethodGet, changelogURL, nil, &issue); err != nil {
+			v.check("changelogs: "+key, fmt.Sprintf("%v; without changelogs, cycle times and aging can't be measured", err))
+		} else {
+			v.check(fmt.Sprintf("changelogs: %s (%s)", key, plural(issue.Changelog.Total, "change")))
+		}
+	}
+
+	if user := viewAs(); user != "" {
+		fmt.Printf("note  reports are checked against what %s can see\n", user)
+	}
+	if canWrite {
+		fmt.Printf("note  the account can edit, comment on or transition issues; a read-only account is enough unless nudge, write-back or audit revert-labels posts changes\n")
+	}
+	if v.problems > 0 {
+		return fmt.Errorf("%s found", plural(v.problems, "problem"))
+	}
+	return nil
+}
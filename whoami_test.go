@@ -0,0 +1,70 @@
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunWhoami(t *testing.T) {
+	transitions := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/2/myself":
+			w.Write([]byte(`{"name": "svc-reports", "displayName": "Reports"}`))
+		case "/rest/agile/1.0/board/7":
+			w.Write([]byte(`{"id": 7, "name": "Team board"}`))
+		case "/rest/agile/1.0/board/7/project":
+			w.Write([]byte(`{"values": [{"key": "ABC"}, {"key": "OPS"}]}`))
+		case "/rest/agile/1.0/board/7/issue":
+			w.Write([]byte(`{"issues": [{"key": "ABC-1", "fields": {"project": {"key": "ABC"}}}]}`))
+		case "/rest/api/2/mypermissions":
+			have := r.URL.Query().Get("projectKey") == "ABC"
+			w.Write([]byte(`{"permissions": {"BROWSE_PROJECTS": {"havePermission": ` + map[bool]string{true: "true", false: "false"}[have] + `},` +
+				` "TRANSITION_ISSUES": {"havePermission": ` + map[bool]string{true: "true", false: "false"}[transitions] + `}}}`))
+		case "/rest/api/2/issue/ABC-1":
+			w.Write([]byte(`{"key": "ABC-1", "changelog": {"total": 3, "histories": []}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+
+	out, err := captureOutput(func() error { return runWhoami([]string{"7"}) })
+	if err == nil || err.Error() != "1 problem found" {
+		t.Fatalf("expected 1 problem, got %v\n%s", err, out)
+	}
+	for _, want := range []string{
+		"ok    credentials: logged in as Reports (svc-reports)",
+		"ok    Agile API: board 7: Team board",
+		"ok    project ABC: browse",
+		"FAIL  project OPS: browse\n      missing Browse Projects (BROWSE_PROJECTS)",
+		"ok    changelogs: ABC-1 (3 changes)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "read-only") {
+		t.Errorf("expected no write access note without write permissions:\n%s", out)
+	}
+
+	// Transitioning issues is a write too:
+	transitions = true
+	out, _ = captureOutput(func() error { return runWhoami([]string{"7"}) })
+	if !strings.Contains(out, "can edit, comment on or transition issues") {
+		t.Errorf("expected a write access note for transition permission:\n%s", out)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// defaultSummaryWidth is how many terminal columns summaries may take before
+// being truncated:
+const defaultSummaryWidth = 100
+
+// wideRanges are the East Asian wide and fullwidth ranges, plus emoji, that
+// take two terminal columns:
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},
+	{0x2E80, 0x303E},
+	{0x3041, 0x33FF},
+	{0x3400, 0x4DBF},
+	{0x4E00, 0x9FFF},
+	{0xA000, 0xA4CF},
+	{0xAC00, 0xD7A3},
+	{0xF900, 0xFAFF},
+	{0xFE30, 0xFE4F},
+	{0xFF00, 0xFF60},
+	{0xFFE0, 0xFFE6},
+	{0x1F300, 0x1F64F},
+	{0x1F900, 0x1F9FF},
+	{0x20000, 0x3FFFD},
+}
+
+// runeWidth is the number of terminal columns r takes:
+func runeWidth(r rune) int {
+	if r == 0x200D || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	for _, wide := range wideRanges {
+		if r >= wide[0] && r <= wide[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// displayWidth is the number of terminal columns s takes:
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+// truncateWidth shortens s to at most width terminal columns, ending in an
+// ellipsis when cut; a negative width leaves s whole.
+func truncateWidth(s string, width int) string {
+	if width < 0 || displayWidth(s) <= width {
+		return s
+	}
+	if width == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		w := runeWidth(r)
+		if used+w > width-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += w
+	}
+	return b.String() + "…"
+}
+
+// padRight and padLeft fit s to exactly width terminal columns, truncating
+// it if longer, where fmt's %-20s and %20s would count runes:
+func padRight(s string, width int) string {
+	s = truncateWidth(s, width)
+	return s + strings.Repeat(" ", width-displayWidth(s))
+}
+
+func padLeft(s string, width int) string {
+	s = truncateWidth(s, width)
+	return strings.Repeat(" ", width-displayWidth(s)) + s
+}
+
+// summaryWidth is the configured summary width; 0 means the default and a
+// negative width no limit:
+func summaryWidth() int {
+	if config.SummaryWidth == 0 {
+		return defaultSummaryWidth
+	}
+	return config.SummaryWidth
+}
+
+// nameWidth is the width of a report's name column, fallback unless
+// configured:
+func nameWidth(fallback int) int {
+	if config.NameWidth > 0 {
+		return config.NameWidth
+	}
+	return fallback
+}
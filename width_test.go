@@ -0,0 +1,56 @@
+package main
+
+import "testing"
+
+func TestDisplayWidth(t *testing.T) {
+	cases := map[string]int{
+		"":           0,
+		"abc":        3,
+		"日本語":        6,
+		"café":       4,
+		"cafe\u0301": 4,
+		"한국어 text":   11,
+	}
+	for s, want := range cases {
+		if got := displayWidth(s); got != want {
+			t.Errorf("displayWidth(%q) = %d, want %d", s, got, want)
+		}
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	cases := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"a long summary", 8, "a long …"},
+		{"日本語のテキスト", 7, "日本語…"},
+		{"日本語のテキスト", 8, "日本語…"},
+		{"anything", 0, ""},
+		{"no limit at all", -1, "no limit at all"},
+	}
+	for _, c := range cases {
+		got := truncateWidth(c.s, c.width)
+		if got != c.want {
+			t.Errorf("truncateWidth(%q, %d) = %q, want %q", c.s, c.width, got, c.want)
+		}
+		if c.width >= 0 && displayWidth(got) > c.width {
+			t.Errorf("truncateWidth(%q, %d) is %d columns wide", c.s, c.width, displayWidth(got))
+		}
+	}
+}
+
+func TestPad(t *testing.T) {
+	if got := padRight("日本", 6); got != "日本  " {
+		t.Errorf("padRight = %q", got)
+	}
+	if got := padLeft("日本", 6); got != "  日本" {
+		t.Errorf("padLeft = %q", got)
+	}
+	if got := padRight("日本語です", 6); displayWidth(got) != 6 {
+		t.Errorf("padRight(long) = %q, %d columns", got, displayWidth(got))
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// windowFloor is the earliest creation date windowed queries cover; JIRA
+// dates from 2002.
+var windowFloor = time.Date(2002, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// createdWindowJQL narrows a query to the issues created in [from, to), to
+// the minute for windows within a day:
+func createdWindowJQL(jql string, from, to time.Time) string {
+	format := "2006-01-02"
+	if !from.Equal(from.Truncate(24*time.Hour)) || !to.Equal(to.Truncate(24*time.Hour)) {
+		format = "2006-01-02 15:04"
+	}
+	window := fmt.Sprintf(`created >= "%s" AND created < "%s"`, from.Format(format), to.Format(format))
+	jql = strings.TrimSpace(orderByPattern.ReplaceAllString(jql, ""))
+	if jql == "" {
+		return window
+	}
+	return fmt.Sprintf("(%s) AND %s", jql, window)
+}
+
+// countJiraIssues asks for a query's total without fetching any issues:
+func countJiraIssues(cl *http.Client, boardId int, resource string, jql string, filterId int) (int, error) {
+	h := fnv.New32a()
+	h.Write([]byte(setting(cl, "JIRA_URL") + " " + jql))
+	jqlHash := h.Sum32()
+	query := url.QueryEscape(jql)
+
+	cacheFilename := fmt.Sprintf("board.%d.%08x.%s.count.json", boardId, jqlHash, resource)
+	url := fmt.Sprintf("%s/rest/agile/1.0/board/%d/%s?maxResults=0&jql=%s", setting(cl, "JIRA_URL"), boardId, resource, query)
+	if filterId != 0 {
+		cacheFilename = fmt.Sprintf("filter.%d.%08x.count.json", filterId, jqlHash)
+		url = fmt.Sprintf("%s/rest/api/2/search?maxResults=0&jql=%s", setting(cl, "JIRA_URL"), query)
+	}
+	body, err := cachedGet(cacheFilename, url, cl)
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+
+	var page struct {
+		Total int `json:"total"`
+	}
+	if err = decodeResponse(body, url, &page); err != nil {
+		return 0, err
+	}
+	return page.Total, nil
+}
+
+// fetchWindowedIssues fetches a query with more results than the server
+// returns for one query by splitting it into creation date windows, halving
+// each window until it fits under the limit: by days, then within a day down
+// to the minute, JQL's finest. Issues are deduplicated by key; a minute still
+// over the limit is fetched up to it, and what that leaves out is noted.
+func fetchWindowedIssues(cl *http.Client, boardId int, resource string, jql string, filterId int, limit int) ([]Issue, error) {
+	var issues []Issue
+	seen := make(map[string]bool)
+
+	var fetchWindow func(from, to time.Time) error
+	fetchWindow = func(from, to time.Time) error {
+		windowJQL := createdWindowJQL(jql, from, to)
+		total, err := countJiraIssues(cl, boardId, resource, windowJQL, filterId)
+		if err != nil {
+			return err
+		}
+		if total == 0 {
+			return nil
+		}
+		span := to.Sub(from)
+		if total > limit && span > time.Minute {
+			middle := from.Add(span / 2).Truncate(time.Minute)
+			if days := int(span.Hours() / 24); days > 1 {
+				middle = from.AddDate(0, 0, days/2)
+			}
+			if err := fetchWindow(from, middle); err != nil {
+				return err
+			}
+			return fetchWindow(middle, to)
+		}
+
+		upTo := 0
+		if total > limit {
+			upTo = limit
+		}
+		page, _, err := pageJiraIssues(cl, boardId, resource, windowJQL, filterId, upTo)
+		if err != nil {
+			return err
+		}
+		if len(page) < total {
+			fetched.gap("%d of %s created %s not fetched; over the server's result cap", total-len(page), plural(total, "issue"), from.Format("2006-01-02 15:04"))
+		}
+		for _, issue := range page {
+			if !seen[issue.Key] {
+				seen[issue.Key] = true
+				issues = append(issues, issue)
+			}
+		}
+		return nil
+	}
+
+	now := reportNow().UTC()
+	tomorrow := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	if err := fetchWindow(windowFloor, tomorrow); err != nil {
+		return nil, err
+	}
+	progress.Clear()
+	return issues, nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cappedServer serves issues A-1 through A-n created at the given times
+// ("2006-01-02 15:04"), two per page, but pages through no more than cap
+// results of any query, like JIRA's search limits.
+func cappedServer(created []string, cap int) *httptest.Server {
+	window := regexp.MustCompile(`created >= "([0-9-: ]+)" AND created < "([0-9-: ]+)"`)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		type issue struct {
+			Key    string            `json:"key"`
+			Fields map[string]string `json:"fields"`
+		}
+		var matched []issue
+		for i, at := range created {
+			if m := window.FindStringSubmatch(r.URL.Query().Get("jql")); m != nil && (at < m[1] || at >= m[2]) {
+				continue
+			}
+			timestamp := strings.Replace(at, " ", "T", 1) + ":00.000-0600"
+			matched = append(matched, issue{Key: fmt.Sprintf("A-%d", i+1), Fields: map[string]string{"created": timestamp}})
+		}
+
+		startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+		page := []issue{}
+		if r.URL.Query().Get("maxResults") != "0" {
+			for i := startAt; i < startAt+2 && i < len(matched) && i < cap; i++ {
+				page = append(page, matched[i])
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"startAt": startAt, "total": len(matched), "issues": page})
+	}))
+}
+
+// createdDaily is n creation times a day apart from 2018-11-01:
+func createdDaily(n int) []string {
+	var created []string
+	for i := 1; i <= n; i++ {
+		created = append(created, fmt.Sprintf("2018-11-%02d 09:00", i))
+	}
+	return created
+}
+
+func TestFetchJiraIssues_WindowsPastResultCap(t *testing.T) {
+	srv := cappedServer(createdDaily(9), 4)
+	defer srv.Close()
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	for key, value := range map[string]string{"JIRA_URL": srv.URL, "JIRA_NOCACHE": "1", "JIRA_NOW": "2018-11-20T09:00:00-06:00"} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+	defer func() { fetched = fetchStats{} }()
+
+	issues, err := fetchJiraIssues(http.DefaultClient, 1, "issue", "project = A ORDER BY created")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 9 || len(fetched.Gaps) != 0 {
+		t.Fatalf("expected all 9 issues in windows without gaps, got %d and %v", len(issues), fetched.Gaps)
+	}
+	seen := make(map[string]bool)
+	for _, issue := range issues {
+		if seen[issue.Key] {
+			t.Fatalf("expected each issue once, got %s twice", issue.Key)
+		}
+		seen[issue.Key] = true
+	}
+}
+
+func TestFetchJiraIssues_SplitsBusyDaysByTime(t *testing.T) {
+	wd, _ := os.Getwd()
+	os.Chdir(t.TempDir())
+	defer os.Chdir(wd)
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	for key, value := range map[string]string{"JIRA_NOCACHE": "1", "JIRA_NOW": "2018-11-20T09:00:00-06:00"} {
+		os.Setenv(key, value)
+		defer os.Unsetenv(key)
+	}
+	defer os.Unsetenv("JIRA_URL")
+	defer func() { fetched = fetchStats{} }()
+
+	// Six issues in one day over a cap of four are fetched in windows of
+	// the day:
+	srv := cappedServer([]string{"2018-11-01 08:00", "2018-11-01 09:30", "2018-11-01 11:00", "2018-11-01 13:15", "2018-11-01 15:00", "2018-11-01 17:45"}, 4)
+	defer srv.Close()
+	os.Setenv("JIRA_URL", srv.URL)
+	issues, err := fetchJiraIssues(http.DefaultClient, 1, "issue", "project = A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 6 || len(fetched.Gaps) != 0 {
+		t.Fatalf("expected all 6 issues created the same day without gaps, got %d and %v", len(issues), fetched.Gaps)
+	}
+
+	// Six created the same minute can't be split; the cap's worth are kept
+	// and the rest noted:
+	fetched = fetchStats{}
+	sameMinute := cappedServer([]string{"2018-11-01 08:00", "2018-11-01 08:00", "2018-11-01 08:00", "2018-11-01 08:00", "2018-11-01 08:00", "2018-11-01 08:00"}, 4)
+	defer sameMinute.Close()
+	os.Setenv("JIRA_URL", sameMinute.URL)
+	issues, err = fetchJiraIssues(http.DefaultClient, 1, "issue", "project = A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 4 || len(fetched.Gaps) != 1 || !strings.Contains(fetched.Gaps[0], "2 of 6 issues created 2018-11-01 08:00 not fetched") {
+		t.Fatalf("expected 4 issues and a gap noting the other 2, got %d and %v", len(issues), fetched.Gaps)
+	}
+}
+
+func TestCreatedWindowJQL(t *testing.T) {
+	from, to := windowFloor, windowFloor.AddDate(0, 0, 7)
+	if got := createdWindowJQL("project = A ORDER BY Rank", from, to); got != `(project = A) AND created >= "2002-01-01" AND created < "2002-01-08"` {
+		t.Fatalf("unexpected window JQL %s", got)
+	}
+	if got := createdWindowJQL("", from, to); got != `created >= "2002-01-01" AND created < "2002-01-08"` {
+		t.Fatalf("unexpected window JQL %s", got)
+	}
+	if got := createdWindowJQL("", from, from.Add(90*time.Minute)); got != `created >= "2002-01-01 00:00" AND created < "2002-01-01 01:30"` {
+		t.Fatalf("unexpected window JQL within a day %s", got)
+	}
+}
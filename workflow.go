@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// workflowChangeMinIssues is how many issues must have gone through the
+	// workflow without a status, before it appeared or after it disappeared,
+	// to call it a workflow change:
+	workflowChangeMinIssues = 3
+	// workflowChangeMinShare is the share of issues on the other side that
+	// must have used the status, so optional statuses like "Blocked" coming
+	// into use don't count:
+	workflowChangeMinShare = 0.5
+)
+
+// workflowChange is a week in which statuses came into or went out of use:
+type workflowChange struct {
+	Week        time.Time
+	Appeared    []string
+	Disappeared []string
+}
+
+func (c *workflowChange) String() string {
+	var parts []string
+	if len(c.Appeared) > 0 {
+		parts = append(parts, "added "+strings.Join(c.Appeared, ", "))
+	}
+	if len(c.Disappeared) > 0 {
+		parts = append(parts, "retired "+strings.Join(c.Disappeared, ", "))
+	}
+	return fmt.Sprintf("week of %s: %s", c.Week.Format("2006-01-02"), strings.Join(parts, "; "))
+}
+
+// detectWorkflowChanges finds the weeks statuses came into or went out of
+// use across the issues' changelogs, marking where the board's workflow was
+// edited and metrics before and after may not compare. A status appeared when
+// issues that went through the workflow before its first use never needed
+// it, but most issues since have; it disappeared likewise.
+func detectWorkflowChanges(issues []Issue) []workflowChange {
+	type span struct {
+		First, Last time.Time
+		Statuses    map[string]bool
+	}
+	type use struct{ First, Last time.Time }
+
+	var spans []span
+	uses := make(map[string]*use)
+	for i := range issues {
+		transitions := issues[i].StatusTransitions()
+		if len(transitions) == 0 {
+			continue
+		}
+		sp := span{First: transitions[0].Time, Last: transitions[len(transitions)-1].Time, Statuses: make(map[string]bool)}
+		for _, ev := range transitions {
+			for _, status := range []string{ev.FromString, ev.ToString} {
+				if status == "" {
+					continue
+				}
+				sp.Statuses[status] = true
+				u, ok := uses[status]
+				if !ok {
+					uses[status] = &use{First: ev.Time, Last: ev.Time}
+					continue
+				}
+				if ev.Time.Before(u.First) {
+					u.First = ev.Time
+				}
+				if ev.Time.After(u.Last) {
+					u.Last = ev.Time
+				}
+			}
+		}
+		spans = append(spans, sp)
+	}
+
+	// changed reports whether enough issues went through the workflow without
+	// the status while most of those on the other side used it:
+	changed := func(status string, without func(sp span) bool, with func(sp span) bool) bool {
+		withoutCount, withCount, used := 0, 0, 0
+		for _, sp := range spans {
+			if without(sp) {
+				withoutCount++
+			} else if with(sp) {
+				withCount++
+				if sp.Statuses[status] {
+					used++
+				}
+			}
+		}
+		return withoutCount >= workflowChangeMinIssues && withCount > 0 &&
+			float64(used)/float64(withCount) >= workflowChangeMinShare
+	}
+
+	byWeek := make(map[time.Time]*workflowChange)
+	change := func(at time.Time) *workflowChange {
+		week := weekOf(at)
+		c, ok := byWeek[week]
+		if !ok {
+			c = &workflowChange{Week: week}
+			byWeek[week] = c
+		}
+		return c
+	}
+	for status, u := range uses {
+		first, last := u.First, u.Last
+		if changed(status,
+			func(sp span) bool { return sp.Last.Before(first) },
+			func(sp span) bool { return !sp.First.Before(first) }) {
+			c := change(first)
+			c.Appeared = append(c.Appeared, status)
+		}
+		if changed(status,
+			func(sp span) bool { return sp.First.After(last) },
+			func(sp span) bool { return !sp.Last.After(last) }) {
+			c := change(last)
+			c.Disappeared = append(c.Disappeared, status)
+		}
+	}
+
+	changes := make([]workflowChange, 0, len(byWeek))
+	for _, c := range byWeek {
+		sort.Strings(c.Appeared)
+		sort.Strings(c.Disappeared)
+		changes = append(changes, *c)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Week.Before(changes[j].Week) })
+	return changes
+}
+
+// workflowChangedDuring reports whether any change's week starts in
+// [from, to):
+func workflowChangedDuring(changes []workflowChange, from, to time.Time) bool {
+	for _, c := range changes {
+		if !c.Week.Before(from) && c.Week.Before(to) {
+			return true
+		}
+	}
+	return false
+}
+
+// workflowChangeMark marks trend report rows spanning a workflow change:
+const workflowChangeMark = " ‡"
+
+// printWorkflowChanges lists the changes under a trend report's rows:
+func printWorkflowChanges(changes []workflowChange) {
+	if len(changes) == 0 {
+		return
+	}
+	fmt.Printf("\n%s workflow changed; metrics across these points may not be comparable:\n", strings.TrimSpace(workflowChangeMark))
+	for i := range changes {
+		fmt.Printf("  %s\n", changes[i].String())
+	}
+}
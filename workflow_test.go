@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDetectWorkflowChanges_Rename(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2018, 10, d, 9, 0, 0, 0, time.UTC) }
+	through := func(key string, start int, review string) Issue {
+		issue := Issue{Key: key}
+		issue.Changelog.Histories = []History{
+			{Created: zonedTimestamp{day(start)}, Items: []HistoryItem{{Field: "status", FromString: "Open", ToString: "In Progress"}}},
+			{Created: zonedTimestamp{day(start + 1)}, Items: []HistoryItem{{Field: "status", FromString: "In Progress", ToString: review}}},
+			{Created: zonedTimestamp{day(start + 2)}, Items: []HistoryItem{{Field: "status", FromString: review, ToString: "Done"}}},
+		}
+		return issue
+	}
+
+	// "PR" renamed to "Code Review" on Monday Oct 15:
+	issues := []Issue{
+		through("A-1", 1, "PR"),
+		through("A-2", 3, "PR"),
+		through("A-3", 8, "PR"),
+		through("A-4", 15, "Code Review"),
+		through("A-5", 17, "Code Review"),
+		through("A-6", 22, "Code Review"),
+	}
+
+	changes := detectWorkflowChanges(issues)
+	if len(changes) != 2 {
+		t.Fatalf("expected PR retired and Code Review added, got %+v", changes)
+	}
+	if changes[0].Week != weekOf(day(9)) || len(changes[0].Disappeared) != 1 || changes[0].Disappeared[0] != "PR" {
+		t.Fatalf("expected PR retired the week of Oct 8, got %+v", changes[0])
+	}
+	if changes[1].Week != weekOf(day(15)) || len(changes[1].Appeared) != 1 || changes[1].Appeared[0] != "Code Review" {
+		t.Fatalf("expected Code Review added the week of Oct 15, got %+v", changes[1])
+	}
+
+	if !workflowChangedDuring(changes, weekOf(day(15)), weekOf(day(22))) || workflowChangedDuring(changes, weekOf(day(22)), weekOf(day(29))) {
+		t.Fatalf("expected only the week of Oct 15 marked changed")
+	}
+
+	// Too little history on either side to tell:
+	if changes := detectWorkflowChanges(issues[2:4]); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", changes)
+	}
+}
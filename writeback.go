@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// doJSON sends an uncached request to the JIRA API with an optional JSON
+// body, decoding the response into v unless nil. Writes, and reads whose
+// answer must be current, go through here rather than the response cache,
+// though still within the API budget.
+func doJSON(cl *http.Client, method string, url string, body interface{}, v interface{}) error {
+	var reqBody io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return err
+	}
+	if err = authenticate(cl, req); err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	log.Printf("%s '%s'\n", method, url)
+	b, err := doAccounted(req, cl)
+	if err != nil || v == nil {
+		return err
+	}
+	return decodeResponse(bytes.NewReader(b), url, v)
+}
+
+// sendJSON sends a write request to the JIRA API; writes are never cached or
+// retried, and made only by a report's first rendering.
+func sendJSON(cl *http.Client, method string, url string, body interface{}) error {
+	if rerendering {
+		return nil
+	}
+	return doJSON(cl, method, url, body, nil)
+}
+
+// rerendering is set while a report runs again only to render its output for
+// another timezone. The first run made the report's changes, so writes are
+// skipped and the state they changed is read as the first run found it.
+var rerendering bool
+
+// firstReads is the state read by a report's first rendering, while it is
+// rendered for more than one timezone:
+var firstReads map[string]stateRead
+
+type stateRead struct {
+	b   []byte
+	err error
+}
+
+// readBeforeWrites reads a file a report may change, as the first rendering
+// read it when rerendering:
+func readBeforeWrites(filename string, read func(string) ([]byte, error)) ([]byte, error) {
+	if r, ok := firstReads[filename]; ok && rerendering {
+		return r.b, r.err
+	}
+	b, err := read(filename)
+	if firstReads != nil && !rerendering {
+		firstReads[filename] = stateRead{b, err}
+	}
+	return b, err
+}
+
+// WriteBackAction is what to do in JIRA to issues matching a badge rule:
+// add Label, and/or move the issue through the transition named Transition.
+type WriteBackAction struct {
+	Label      string `json:"label"`
+	Transition string `json:"transition"`
+}
+
+// postComment adds a comment to an issue:
+func postComment(cl *http.Client, key string, text string) error {
+	url := setting(cl, "JIRA_URL") + "/rest/api/2/issue/" + key + "/comment"
+	return sendJSON(cl, http.MethodPost, url, map[string]string{"body": text})
+}
+
+func addLabel(cl *http.Client, key string, label string) error {
+	url := setting(cl, "JIRA_URL") + "/rest/api/2/issue/" + key
+	update := map[string]interface{}{
+		"update": map[string]interface{}{
+			"labels": []map[string]string{{"add": label}},
+		},
+	}
+	return sendJSON(cl, http.MethodPut, url, update)
+}
+
+// removeLabel takes a label off an issue, reverting addLabel:
+func removeLabel(cl *http.Client, key string, label string) error {
+	url := setting(cl, "JIRA_URL") + "/rest/api/2/issue/" + key
+	update := map[string]interface{}{
+		"update": map[string]interface{}{
+			"labels": []map[string]string{{"remove": label}},
+		},
+	}
+	return sendJSON(cl, http.MethodPut, url, update)
+}
+
+type issueTransition struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// transitionIssue moves an issue through the transition of the given name,
+// failing when the issue's workflow doesn't offer it from its status.
+func transitionIssue(cl *http.Client, key string, name string) error {
+	url := setting(cl, "JIRA_URL") + "/rest/api/2/issue/" + key + "/transitions"
+
+	// Not cached: the transitions on offer change as the issue moves.
+	var available struct {
+		Transitions []issueTransition `json:"transitions"`
+	}
+	if err := doJSON(cl, http.MethodGet, url, nil, &available); err != nil {
+		return err
+	}
+
+	for _, t := range available.Transitions {
+		if strings.EqualFold(t.Name, name) {
+			return sendJSON(cl, http.MethodPost, url, map[string]interface{}{"transition": map[string]string{"id": t.Id}})
+		}
+	}
+	return fmt.Errorf("no transition '%s' from its current status", name)
+}
+
+// writeBackChange is one label or transition to apply to an issue:
+type writeBackChange struct {
+	Key        string
+	Rule       string
+	Label      string
+	Transition string
+}
+
+func (c writeBackChange) String() string {
+	if c.Label != "" {
+		return fmt.Sprintf("%s (%s): add label %s", c.Key, c.Rule, c.Label)
+	}
+	return fmt.Sprintf("%s (%s): transition '%s'", c.Key, c.Rule, c.Transition)
+}
+
+// liveWrites refuses changes to JIRA decided from anything but its issues as
+// they stand: rewound by -as-of, read from an export, or listed by key.
+func liveWrites() error {
+	switch {
+	case !asOf.IsZero():
+		return fmt.Errorf("-as-of rewinds issues to %s; refusing to change JIRA from a past state", asOf.Format("2006-01-02"))
+	case inputFile != "":
+		return fmt.Errorf("-file reads an export; refusing to change JIRA from it")
+	case len(issueKeys) > 0:
+		return fmt.Errorf("-keys lists issues outside the board; refusing to change JIRA from them")
+	}
+	return nil
+}
+
+// apply makes the change, recording it in the audit log:
+func (c writeBackChange) apply(cl *http.Client) error {
+	if err := liveWrites(); err != nil {
+		return err
+	}
+	if c.Label != "" {
+		entry := auditEntry{Action: auditLabel, Key: c.Key, Rule: c.Rule, Label: c.Label}
+		return audit(entry, addLabel(cl, c.Key, c.Label))
+	}
+	entry := auditEntry{Action: auditTransition, Key: c.Key, Rule: c.Rule, Transition: c.Transition}
+	return audit(entry, transitionIssue(cl, c.Key, c.Transition))
+}
+
+func hasLabel(issue *Issue, label string) bool {
+	for _, l := range issue.Fields.Labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+// writeBackChanges lists the enabled write-back changes for items matching
+// configured rules, an item breaching when over its nudge threshold. Labels
+// already present are skipped, and at most one transition is made per item.
+func writeBackChanges(items []AgingItem, days int, now time.Time, labels bool, transitions bool) []writeBackChange {
+	var changes []writeBackChange
+	for _, item := range items {
+		threshold := nudgeThreshold(item, days)
+		transitioned := false
+		for _, rule := range issueRules(item.Issue, overSLA(item.Age, threshold), now) {
+			action, ok := config.WriteBack[rule]
+			if !ok {
+				continue
+			}
+			if labels && action.Label != "" && !hasLabel(item.Issue, action.Label) {
+				changes = append(changes, writeBackChange{Key: item.Key, Rule: rule, Label: action.Label})
+			}
+			if transitions && action.Transition != "" && !transitioned {
+				changes = append(changes, writeBackChange{Key: item.Key, Rule: rule, Transition: action.Transition})
+				transitioned = true
+			}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/JamesDunne/jira-analysis/analysis"
+)
+
+func TestWriteBackChanges(t *testing.T) {
+	config = &Config{WriteBack: map[string]WriteBackAction{
+		"breach":     {Label: "flow-risk"},
+		"unassigned": {Transition: "Back to To Do"},
+	}}
+	defer func() { config = &Config{} }()
+
+	now := time.Date(2018, 11, 30, 9, 0, 0, 0, time.UTC)
+	item := func(key string, age int, labels ...string) AgingItem {
+		issue := &Issue{Key: key}
+		issue.Fields.Labels = labels
+		issue.Fields.Created.Time = now
+		issue.Fields.Assignee = &User{UserName: "alice"}
+		return AgingItem{AgingItem: analysis.AgingItem{Key: key, Status: "In Progress", Age: age}, Issue: issue}
+	}
+	unassigned := item("A-3", 1)
+	unassigned.Issue.Fields.Assignee = nil
+	items := []AgingItem{item("A-1", 12), item("A-2", 12, "flow-risk"), unassigned, item("A-4", 2)}
+
+	changes := writeBackChanges(items, 10, now, true, false)
+	if len(changes) != 1 || changes[0].Key != "A-1" || changes[0].Label != "flow-risk" {
+		t.Fatalf("expected only A-1 labeled, got %+v", changes)
+	}
+
+	changes = writeBackChanges(items, 10, now, false, true)
+	if len(changes) != 1 || changes[0].Key != "A-3" || changes[0].Transition != "Back to To Do" {
+		t.Fatalf("expected only A-3 transitioned, got %+v", changes)
+	}
+}
+
+func TestTransitionIssue(t *testing.T) {
+	var made map[string]map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue/ABC-1/transitions" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"transitions": [{"id": "11", "name": "Start"}, {"id": "31", "name": "Back to To Do"}]}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&made)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	os.Setenv("JIRA_URL", srv.URL)
+	defer os.Unsetenv("JIRA_URL")
+
+	if err := transitionIssue(srv.Client(), "ABC-1", "back to to do"); err != nil {
+		t.Fatal(err)
+	}
+	if made["transition"]["id"] != "31" {
+		t.Fatalf("expected transition 31 made, got %v", made)
+	}
+	if err := transitionIssue(srv.Client(), "ABC-1", "Close"); err == nil {
+		t.Fatalf("expected an error for a transition not on offer")
+	}
+}
+
+func TestDoJSON_CountsTowardBudget(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "ann"}`))
+	}))
+	defer srv.Close()
+	log.SetOutput(ioutil.Discard)
+	defer log.SetOutput(os.Stderr)
+	config = &Config{Budget: &APIBudget{Requests: 1}}
+	fetched = fetchStats{}
+	defer func() { config, fetched = &Config{}, fetchStats{} }()
+
+	var user struct{ Name string }
+	if err := doJSON(srv.Client(), http.MethodGet, srv.URL+"/rest/api/2/myself", nil, &user); err != nil || user.Name != "ann" {
+		t.Fatalf("expected the response decoded, got %+v, %v", user, err)
+	}
+	if fetched.Calls != 1 || fetched.Bytes != 15 {
+		t.Fatalf("expected the call and its bytes counted, got %+v", fetched)
+	}
+	if err := sendJSON(srv.Client(), http.MethodPut, srv.URL+"/rest/api/2/issue/ABC-1", nil); err == nil {
+		t.Fatalf("expected writes refused once the budget is exhausted")
+	}
+}